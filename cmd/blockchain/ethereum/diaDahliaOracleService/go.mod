@@ -1,8 +0,0 @@
-module github.com/diadata-org/diadata/blockchain/diaDahliaOracleService
-
-go 1.14
-
-require (
-	github.com/diadata-org/diadata v1.4.1-rc-21
-	github.com/ethereum/go-ethereum v1.10.10
-)
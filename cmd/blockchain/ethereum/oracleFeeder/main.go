@@ -0,0 +1,136 @@
+package main
+
+import (
+	"context"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/diadata-org/diadata/internal/pkg/oracleFeeder"
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	diaOracleServiceV3 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV3"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	log "github.com/sirupsen/logrus"
+)
+
+// oracleFeeder runs any number of oracle feeds against DIAOracleV2 compatible contracts,
+// or DIAOracleV3 compatible contracts for feeds with batch updates.
+// Feeds are read from the YAML file FEEDS_FILE, or from the oraclefeed table in postgres if FEEDS_SOURCE is postgres.
+// Values are read from the DIA API at DIA_API_URL, or directly from the datastores if DATA_SOURCE is datastore.
+// Each update is logged to the oracleupdate table in postgres, unless LOG_UPDATES is false.
+// If SIMULATION is true, each feed runs against a DIAOracleV2 contract on its own simulated chain
+// and the updates it would send are logged, such that feeds can be tried without nodes and keys.
+// The keystore of a feed's updater is read from the environment variable given by the feed's PrivateKeyEnv
+// and its password from the same variable suffixed with _PASSWORD.
+
+func main() {
+	feedsSource := utils.Getenv("FEEDS_SOURCE", "file")
+	dataSource := utils.Getenv("DATA_SOURCE", "api")
+	simulation := utils.Getenv("SIMULATION", "false") == "true"
+	logUpdates := !simulation && utils.Getenv("LOG_UPDATES", "true") == "true"
+
+	var relDB *models.RelDB
+	if feedsSource == "postgres" || dataSource == "datastore" || logUpdates {
+		var err error
+		relDB, err = models.NewRelDataStore()
+		if err != nil {
+			log.Fatal("connect to postgres: ", err)
+		}
+	}
+	feeds, err := readFeeds(feedsSource, relDB)
+	if err != nil {
+		log.Fatal("read feeds: ", err)
+	}
+	source, err := newDataSource(dataSource, relDB)
+	if err != nil {
+		log.Fatal("create data source: ", err)
+	}
+	var updateLog models.RelDatastore
+	if logUpdates {
+		updateLog = relDB
+	}
+
+	clients := make(map[string]*ethclient.Client)
+	// Feeds sharing an updater on the same node share a transaction manager, such that nonces do not collide.
+	txManagers := make(map[string]*ethhelper.TxManager)
+	var wg sync.WaitGroup
+	for i := range feeds {
+		feed := feeds[i]
+		if simulation {
+			sim, err := oracleFeeder.NewSimulation(feed, source)
+			if err != nil {
+				log.Fatalf("feed %s: create simulation: %v", feed.Name, err)
+			}
+			log.Infof("simulate feed %s with %d assets", feed.Name, len(feed.Assets))
+			wg.Add(1)
+			go func(sim *oracleFeeder.Simulation) {
+				defer wg.Done()
+				sim.Run(context.Background())
+			}(sim)
+			continue
+		}
+		if err := oracleFeeder.ValidateFeed(&feed); err != nil {
+			log.Fatal(err)
+		}
+		client, ok := clients[feed.NodeURL]
+		if !ok {
+			client, err = ethclient.Dial(feed.NodeURL)
+			if err != nil {
+				log.Fatalf("feed %s: connect to node: %v", feed.Name, err)
+			}
+			clients[feed.NodeURL] = client
+		}
+		auth, err := bind.NewTransactorWithChainID(strings.NewReader(os.Getenv(feed.PrivateKeyEnv)), os.Getenv(feed.PrivateKeyEnv+"_PASSWORD"), big.NewInt(feed.ChainID))
+		if err != nil {
+			log.Fatalf("feed %s: create transactor: %v", feed.Name, err)
+		}
+		txManager, ok := txManagers[feed.NodeURL+auth.From.Hex()]
+		if !ok {
+			txManager = ethhelper.NewTxManager(client, auth, oracleFeeder.TxConfig(feed))
+			txManagers[feed.NodeURL+auth.From.Hex()] = txManager
+		}
+		var contract oracleFeeder.OracleContract
+		if feed.BatchUpdates {
+			contract, err = diaOracleServiceV3.NewDIAOracleV3(common.HexToAddress(feed.Contract), client)
+		} else {
+			contract, err = diaOracleServiceV2.NewDIAOracleV2(common.HexToAddress(feed.Contract), client)
+		}
+		if err != nil {
+			log.Fatalf("feed %s: bind contract: %v", feed.Name, err)
+		}
+
+		log.Infof("run feed %s with %d assets on %s", feed.Name, len(feed.Assets), feed.Blockchain)
+		wg.Add(1)
+		go func(feeder *oracleFeeder.Feeder) {
+			defer wg.Done()
+			feeder.Run(context.Background())
+		}(oracleFeeder.NewFeeder(feed, contract, txManager, source, updateLog))
+	}
+	wg.Wait()
+}
+
+func readFeeds(source string, relDB *models.RelDB) ([]dia.OracleFeed, error) {
+	if source == "postgres" {
+		return relDB.GetOracleFeeds()
+	}
+	return oracleFeeder.ReadFeedsFromFile(utils.Getenv("FEEDS_FILE", configCollectors.ConfigFileConnectors("oracleFeeder/feeds", ".yaml")))
+}
+
+func newDataSource(source string, relDB *models.RelDB) (oracleFeeder.DataSource, error) {
+	if source == "datastore" {
+		datastore, err := models.NewDataStore()
+		if err != nil {
+			return nil, err
+		}
+		return oracleFeeder.NewDatastoreSource(datastore, relDB), nil
+	}
+	return oracleFeeder.NewHTTPSource(utils.Getenv("DIA_API_URL", oracleFeeder.DefaultAPIURL)), nil
+}
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
+	bookscrapers "github.com/diadata-org/diadata/pkg/dia/scraper/book-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/segmentio/kafka-go"
+	"github.com/sirupsen/logrus"
+)
+
+var log *logrus.Logger
+
+const (
+	watchdogDelay = 10 * 60
+)
+
+var (
+	exchange = flag.String("exchange", "", "which exchange")
+	// pairs is a comma separated list of foreign names. If empty, all pairs of the exchange are scraped.
+	pairs = flag.String("pairs", "", "comma separated foreign names of the pairs")
+	// Number of levels per side of the order books stored in influx.
+	bookDepth int
+	// Order book snapshots are stored in influx at most once per pair and bookSaveInterval.
+	bookSaveInterval time.Duration
+)
+
+// errFrozen is returned by handleBooks if no order book was received within watchdogDelay.
+var errFrozen = errors.New("no order book received within watchdog delay")
+
+func init() {
+	log = logrus.New()
+	flag.Parse()
+	if *exchange == "" {
+		flag.Usage()
+		log.Fatal("exchange is required")
+	}
+	var err error
+	bookDepth, err = strconv.Atoi(utils.Getenv("ORDERBOOK_DEPTH", "20"))
+	if err != nil {
+		log.Fatal("parse ORDERBOOK_DEPTH: ", err)
+	}
+	saveSeconds, err := strconv.Atoi(utils.Getenv("ORDERBOOK_SAVE_SECONDS", "10"))
+	if err != nil {
+		log.Fatal("parse ORDERBOOK_SAVE_SECONDS: ", err)
+	}
+	bookSaveInterval = time.Duration(saveSeconds) * time.Second
+}
+
+// handleBooks writes all snapshots and diffs to kafka, keeps the local order books up to date
+// and stores their best levels in influx. It returns errFrozen if the book scraper stops delivering.
+func handleBooks(c chan *dia.OrderBook, w *kafka.Writer, ds models.Datastore) error {
+	books := make(map[string]*dia.OrderBook)
+	lastSaved := make(map[string]time.Time)
+	lastBookTime := time.Now()
+	t := time.NewTicker(time.Duration(watchdogDelay) * time.Second)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			duration := time.Since(lastBookTime)
+			if duration > time.Duration(watchdogDelay)*time.Second {
+				log.Errorf("handleBooks: no order book received for %v", duration)
+				return errFrozen
+			}
+		case update, ok := <-c:
+			if !ok {
+				log.Info("handleBooks: book channel closed")
+				return nil
+			}
+			lastBookTime = time.Now()
+			err := kafkaHelper.WriteMessage(w, update)
+			if err != nil {
+				log.Error("write order book to kafka: ", err)
+			}
+
+			book, ok := books[update.ForeignName]
+			if !ok {
+				// Diffs are only applied once a snapshot is received.
+				if !update.Snapshot {
+					continue
+				}
+				book = &dia.OrderBook{}
+				books[update.ForeignName] = book
+			}
+			book.Apply(update)
+			if time.Since(lastSaved[update.ForeignName]) < bookSaveInterval {
+				continue
+			}
+			lastSaved[update.ForeignName] = time.Now()
+			err = ds.SaveOrderBookInflux(book.Top(bookDepth))
+			if err != nil {
+				log.Error("save order book: ", err)
+			}
+		}
+	}
+}
+
+func main() {
+	ds, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("datastore: ", err)
+	}
+
+	var exchangePairs []dia.ExchangePair
+	if *pairs != "" {
+		for _, foreignName := range strings.Split(*pairs, ",") {
+			exchangePairs = append(exchangePairs, dia.ExchangePair{Exchange: *exchange, ForeignName: strings.TrimSpace(foreignName)})
+		}
+	} else {
+		relDB, err := models.NewRelDataStore()
+		if err != nil {
+			log.Fatal("relational datastore: ", err)
+		}
+		exchangePairs, err = relDB.GetExchangePairSymbols(*exchange)
+		if err != nil {
+			log.Fatal("get exchange pairs: ", err)
+		}
+	}
+	log.Infof("scrape %d order books on %s", len(exchangePairs), *exchange)
+
+	w := kafkaHelper.NewWriter(kafkaHelper.TopicOrderBooks)
+	defer func() {
+		err := w.Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+
+	for {
+		bs, err := scrapeBooks(exchangePairs)
+		if err != nil {
+			log.Fatal("new book scraper: ", err)
+		}
+		if err := handleBooks(bs.Channel(), w, ds); err == errFrozen {
+			// Replace the frozen scraper by a new one, which receives fresh snapshots of all pairs.
+			log.Warnf("reconnect book scraper on %s", *exchange)
+			if err := bs.Close(); err != nil {
+				log.Error("close book scraper: ", err)
+			}
+			continue
+		}
+		if err := bs.Error(); err != nil {
+			log.Fatal("book scraper stopped: ", err)
+		}
+		log.Info("book scraper closed")
+		return
+	}
+}
+
+// scrapeBooks returns a book scraper subscribed to the order books of @exchangePairs.
+func scrapeBooks(exchangePairs []dia.ExchangePair) (bookscrapers.BookScraper, error) {
+	bs, err := bookscrapers.New(*exchange)
+	if err != nil {
+		return nil, err
+	}
+	for _, pair := range exchangePairs {
+		err = bs.ScrapeBook(pair)
+		if err != nil {
+			log.Errorf("subscribe to order book of %s: %v", pair.ForeignName, err)
+		}
+	}
+	return bs, nil
+}
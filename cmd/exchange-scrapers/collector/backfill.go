@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	scrapers "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/segmentio/kafka-go"
+)
+
+// backfillTrades fetches the trades of all @pairs in [@starttime, @endtime) through the REST API of
+// the exchange and returns them on a channel, which is closed once all pairs are done.
+func backfillTrades(hs scrapers.HistoricalScraper, relDB *models.RelDB, pairs []dia.ExchangePair, starttime time.Time, endtime time.Time) chan *dia.Trade {
+	chanTrades := make(chan *dia.Trade)
+	go func() {
+		defer close(chanTrades)
+		for _, pair := range pairs {
+			log.Infof("backfill trades of %s on %s from %v to %v", pair.ForeignName, *exchange, starttime, endtime)
+			err := scrapers.BackfillTrades(context.Background(), hs, *exchange, pair, starttime, endtime, relDB, chanTrades)
+			if err != nil {
+				log.Errorf("backfill trades of %s: %v", pair.ForeignName, err)
+			}
+		}
+		log.Info("backfill done")
+	}()
+	return chanTrades
+}
+
+// handleBackfilledTrades forwards the trades received on @c until it is closed. Unlike handleTrades it has
+// no watchdog, as pairs without historical trades and retries of the REST API may pause the backfill arbitrarily.
+func handleBackfilledTrades(c chan *dia.Trade, w *kafka.Writer, equivalences *dia.AssetEquivalenceTable) {
+	for t := range c {
+		forwardTrade(w, t, equivalences)
+	}
+}
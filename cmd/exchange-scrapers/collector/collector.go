@@ -2,7 +2,7 @@ package main
 
 import (
 	"flag"
-	"fmt"
+	"strconv"
 	"sync"
 	"time"
 
@@ -17,30 +17,66 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	// Influx table of trades written by SaveTradeInflux.
+	influxTradesTable = "trades"
+	// Retracted trades are deleted from influx in the time range of +-retractionWindow around the retraction.
+	retractionWindow = time.Hour
+)
+
 var (
-	log                  *logrus.Logger
-	swapTradesOnExchange = []string{
-		dia.CurveFIExchange,
-		dia.OmniDexExchange,
-		dia.DiffusionExchange,
-		dia.BalancerV2Exchange,
-		dia.BeetsExchange,
-		dia.PanCakeSwap,
-		dia.SolarbeamExchange,
-		dia.AnyswapExchange,
-		dia.HermesExchange,
-		dia.HuckleberryExchange,
-		dia.NetswapExchange,
-		dia.PangolinExchange,
-		dia.ArthswapExchange,
-	}
+	log *logrus.Logger
 )
 
 func init() {
 	log = logrus.New()
 }
 
-func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, ds *models.DB, exchange string, mode string) {
+// retractTrade deletes the stored trade retracted by @t, looking for it in the time range of
+// +-retractionWindow around the retraction.
+func retractTrade(ds *models.DB, t *dia.Trade) {
+	// The retracted trade might still be in the influx batch.
+	err := ds.Flush()
+	if err != nil {
+		log.Error("flush influx batch: ", err)
+	}
+	deleted, err := ds.DeleteTradesInflux(influxTradesTable, t.Source, t.ForeignTradeID, t.Time.Add(-retractionWindow), t.Time.Add(retractionWindow))
+	if err != nil {
+		log.Errorf("delete retracted trade %s on %s: %v", t.ForeignTradeID, t.Source, err)
+		return
+	}
+	log.Infof("deleted %d trades retracted by %s on %s", deleted, t.ForeignTradeID, t.Source)
+}
+
+// writeTrade writes @t to @w. If trades are partitioned by asset, they are keyed by the canonical asset
+// of their quote token, such that trades of bridged assets are processed with those of their canonical asset.
+func writeTrade(w *kafka.Writer, t *dia.Trade, equivalences *dia.AssetEquivalenceTable) error {
+	if partitionByAsset == "true" {
+		return kafkaHelper.WriteMessageWithKey(w, t, t.CanonicalKafkaKey(equivalences))
+	}
+	return kafkaHelper.WriteMessage(w, t)
+}
+
+// forwardTrade writes @t to @w, along with the reversed trade for exchanges configured in the exchange table.
+func forwardTrade(w *kafka.Writer, t *dia.Trade, equivalences *dia.AssetEquivalenceTable) {
+	err := writeTrade(w, t, equivalences)
+	if err != nil {
+		log.Error(err)
+	}
+	if scrapers.Exchanges[t.Source].SwapTrades {
+		tSwapped, err := dia.SwapTrade(*t)
+		if err != nil {
+			log.Error("swap trade: ", err)
+			return
+		}
+		err = writeTrade(w, &tSwapped, equivalences)
+		if err != nil {
+			log.Error(err)
+		}
+	}
+}
+
+func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, ds *models.DB, exchange string, mode string, equivalences *dia.AssetEquivalenceTable) {
 	lastTradeTime := time.Now()
 	watchdogDelay := scrapers.Exchanges[exchange].WatchdogDelay
 	t := time.NewTicker(time.Duration(watchdogDelay) * time.Second)
@@ -63,27 +99,14 @@ func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, ds *mo
 			// or historical trades topic.
 			if mode == "current" || mode == "historical" || mode == "estimation" {
 
-				// Write trade to Kafka.
-				err := kafkaHelper.WriteMessage(w, t)
-				if err != nil {
-					log.Error(err)
-				}
-
-				// Write reversed trade to Kafka as well for some exchanges.
-				if utils.Contains(&swapTradesOnExchange, t.Source) {
-					tSwapped, err := dia.SwapTrade(*t)
-					if err != nil {
-						log.Error("swap trade: ", err)
-					} else {
-						err := kafkaHelper.WriteMessage(w, &tSwapped)
-						if err != nil {
-							log.Error(err)
-						}
-					}
-				}
+				forwardTrade(w, t, equivalences)
 			}
 			// Trades are just saved in influx - not sent to the tradesblockservice through a kafka channel.
 			if mode == "storeTrades" {
+				if t.Retracted {
+					retractTrade(ds, t)
+					continue
+				}
 				err := ds.SaveTradeInflux(t)
 				if err != nil {
 					log.Error(err)
@@ -92,10 +115,21 @@ func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, ds *mo
 				}
 			}
 
+			// Bridged assets are mapped to their canonical assets before the trade is forwarded.
 			if mode == "assetmap" {
-
-				fmt.Println("recieved trade", t)
-
+				quotetoken, quoteMapped := equivalences.Canonical(t.QuoteToken, t.Source)
+				basetoken, baseMapped := equivalences.Canonical(t.BaseToken, t.Source)
+				if quoteMapped || baseMapped {
+					t.QuoteToken = quotetoken
+					t.BaseToken = basetoken
+					t.Symbol = quotetoken.Symbol
+					t.Pair = quotetoken.Symbol + "-" + basetoken.Symbol
+					log.Infof("mapped trade on %s to %s", t.Source, t.Pair)
+				}
+				err := kafkaHelper.WriteMessage(w, t)
+				if err != nil {
+					log.Error(err)
+				}
 			}
 		}
 	}
@@ -104,14 +138,22 @@ func handleTrades(c chan *dia.Trade, wg *sync.WaitGroup, w *kafka.Writer, ds *mo
 var (
 	exchange         = flag.String("exchange", "", "which exchange")
 	onePairPerSymbol = flag.Bool("onePairPerSymbol", false, "one Pair max Per Symbol ?")
+	verifiedOnly     = flag.Bool("verifiedOnly", false, "scrape verified pairs only ?")
 	// mode==current:		default mode. Trades are forwarded to TBS and FBS.
 	// mode==storeTrades:	trades are not forwarded to TBS and FBS and stored as raw trades in influx.
 	// mode==estimation:	trades are forwarded to tradesEstimationService, i.e. same as storeTrades mode
 	//						but estimatedUSDPrice is filled by tradesEstimationService.
 	// mode==historical:	trades are sent through kafka to TBS in tradesHistorical topic.
-	// mode==assetmap:   	Bridged Trades, asstes are mapped an d trades are not saved
+	// mode==assetmap:		assets of bridged trades are mapped to their canonical assets and
+	//						trades are forwarded to tradesEstimationService.
 
 	mode = flag.String("mode", "current", "either storeTrades, current, historical or estimation")
+	// In historical mode, exchanges implementing scrapers.HistoricalScraper backfill the trades
+	// in [historyStart, historyEnd) through their REST API instead of scraping live trades.
+	historyStart = flag.String("historyStart", "", "start of the backfill in historical mode (RFC3339)")
+	historyEnd   = flag.String("historyEnd", "", "end of the backfill in historical mode (RFC3339)")
+	// If KAFKA_PARTITION_BY_ASSET is true, trades are written to the partition of the canonical asset of their quote token.
+	partitionByAsset = utils.Getenv("KAFKA_PARTITION_BY_ASSET", "false")
 )
 
 func isValidExchange(estring string) bool {
@@ -156,7 +198,12 @@ func main() {
 		log.Fatal("datastore: ", err)
 	}
 
-	pairsExchange, err := relDB.GetExchangePairSymbols(*exchange)
+	var pairsExchange []dia.ExchangePair
+	if *verifiedOnly {
+		pairsExchange, err = relDB.GetVerifiedExchangePairSymbols(*exchange)
+	} else {
+		pairsExchange, err = relDB.GetExchangePairSymbols(*exchange)
+	}
 	log.Info("available exchangePairs:", len(pairsExchange))
 
 	if err != nil || len(pairsExchange) == 0 {
@@ -169,14 +216,23 @@ func main() {
 	if err != nil {
 		log.Warning("no config for exchange's api ", err)
 	}
-	es := scrapers.NewAPIScraper(*exchange, true, configApi.ApiKey, configApi.SecretKey, relDB)
+	backfill := *mode == "historical" && *historyStart != ""
+	es := scrapers.NewAPIScraper(*exchange, !backfill, configApi.ApiKey, configApi.SecretKey, relDB)
 
 	var w *kafka.Writer
 	switch *mode {
 	case "current":
-		w = kafkaHelper.NewWriter(kafkaHelper.TopicTrades)
+		if partitionByAsset == "true" {
+			w = kafkaHelper.NewPartitionedWriter(kafkaHelper.TopicTrades, true)
+		} else {
+			w = kafkaHelper.NewWriter(kafkaHelper.TopicTrades)
+		}
 	case "historical":
-		w = kafkaHelper.NewWriter(kafkaHelper.TopicTradesHistorical)
+		if partitionByAsset == "true" {
+			w = kafkaHelper.NewPartitionedWriter(kafkaHelper.TopicTradesHistorical, true)
+		} else {
+			w = kafkaHelper.NewWriter(kafkaHelper.TopicTradesHistorical)
+		}
 	case "estimation":
 		w = kafkaHelper.NewWriter(kafkaHelper.TopicTradesEstimation)
 	case "assetmap":
@@ -190,32 +246,49 @@ func main() {
 		}
 	}()
 
-	wg := sync.WaitGroup{}
-
-	exchangePairs := make(map[string]string)
+	var equivalences *dia.AssetEquivalenceTable
+	if *mode == "assetmap" || partitionByAsset == "true" {
+		assetEquivalences, err := relDB.GetAllAssetEquivalences()
+		if err != nil {
+			log.Fatal("get asset equivalences: ", err)
+		}
+		equivalences = dia.NewAssetEquivalenceTable(assetEquivalences)
+	}
 
-	// TO DO: Add check for new pairs. i.e. put a ticker around the following loop
-	// and add a control whether new pairs are there.
-	for _, configPair := range pairsExchange {
-		dontAddPair := false
-		if *onePairPerSymbol {
-			_, dontAddPair = exchangePairs[configPair.Symbol]
-			exchangePairs[configPair.Symbol] = configPair.Symbol
+	if backfill {
+		hs, ok := es.(scrapers.HistoricalScraper)
+		if !ok {
+			log.Fatalf("%s does not support backfilling trades", *exchange)
 		}
-		if dontAddPair {
-			log.Println("Skipping pair:", configPair.Symbol, configPair.ForeignName, "on exchange", *exchange)
-		} else {
-			log.Println("Adding pair:", configPair.Symbol, configPair.ForeignName, "on exchange", *exchange)
-			_, err := es.ScrapePair(dia.ExchangePair{
-				Symbol:      configPair.Symbol,
-				ForeignName: configPair.ForeignName})
-			if err != nil {
-				log.Println(err)
-			} else {
-				wg.Add(1)
-			}
+		starttime, err := time.Parse(time.RFC3339, *historyStart)
+		if err != nil {
+			log.Fatal("parse historyStart: ", err)
 		}
-		defer wg.Wait()
+		// A fixed end is needed, as interrupted backfills are only resumed for the same time range.
+		endtime, err := time.Parse(time.RFC3339, *historyEnd)
+		if err != nil {
+			log.Fatal("parse historyEnd: ", err)
+		}
+		handleBackfilledTrades(backfillTrades(hs, relDB, pairsExchange, starttime, endtime), w, equivalences)
+		return
+	}
+
+	pm := newPairManager(es, relDB, *exchange, *onePairPerSymbol, *verifiedOnly)
+	pm.update(pairsExchange)
+
+	// Pairs are diffed against postgres periodically and whenever requested through the REST API.
+	reloadSeconds, err := strconv.Atoi(utils.Getenv("PAIRS_RELOAD_SECONDS", "600"))
+	if err != nil {
+		log.Fatal("parse PAIRS_RELOAD_SECONDS: ", err)
 	}
-	go handleTrades(es.Channel(), &wg, w, ds, *exchange, *mode)
+	reloadRequests, err := ds.SubscribePairsReload(*exchange)
+	if err != nil {
+		log.Warn("subscribe to pairs reload requests: ", err)
+	}
+	go pm.run(time.Duration(reloadSeconds)*time.Second, reloadRequests)
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go handleTrades(es.Channel(), &wg, w, ds, *exchange, *mode, equivalences)
+	wg.Wait()
 }
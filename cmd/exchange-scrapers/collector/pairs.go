@@ -0,0 +1,134 @@
+package main
+
+import (
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	scrapers "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// pairManager keeps the PairScrapers of the collector in line with the exchange's pairs in postgres.
+// It must only be used from a single goroutine.
+type pairManager struct {
+	es               scrapers.APIScraper
+	relDB            *models.RelDB
+	exchange         string
+	onePairPerSymbol bool
+	// If verifiedOnly is true, only verified pairs are scraped and pairs are closed once unverified.
+	verifiedOnly bool
+	// running maps the foreign names of all scraped pairs to their PairScrapers.
+	running map[string]scrapers.PairScraper
+	// kept contains the foreign names of running pairs which should be removed but cannot be unsubscribed.
+	kept map[string]struct{}
+}
+
+func newPairManager(es scrapers.APIScraper, relDB *models.RelDB, exchange string, onePairPerSymbol bool, verifiedOnly bool) *pairManager {
+	return &pairManager{
+		es:               es,
+		relDB:            relDB,
+		exchange:         exchange,
+		onePairPerSymbol: onePairPerSymbol,
+		verifiedOnly:     verifiedOnly,
+		running:          make(map[string]scrapers.PairScraper),
+		kept:             make(map[string]struct{}),
+	}
+}
+
+// fetchPairs returns all pairs of the exchange which should be scraped.
+func (pm *pairManager) fetchPairs() ([]dia.ExchangePair, error) {
+	if pm.verifiedOnly {
+		return pm.relDB.GetVerifiedExchangePairSymbols(pm.exchange)
+	}
+	return pm.relDB.GetExchangePairSymbols(pm.exchange)
+}
+
+// update starts PairScrapers for all pairs in @pairs which are not scraped yet and unsubscribes
+// the PairScrapers of all pairs which are not contained in @pairs anymore.
+// PairScrapers which cannot unsubscribe their pair keep running until the collector restarts.
+func (pm *pairManager) update(pairs []dia.ExchangePair) {
+	wanted := make(map[string]dia.ExchangePair)
+	for _, pair := range pairs {
+		wanted[pair.ForeignName] = pair
+	}
+
+	symbols := make(map[string]struct{})
+	for foreignName, ps := range pm.running {
+		if _, ok := wanted[foreignName]; ok {
+			delete(pm.kept, foreignName)
+			symbols[ps.Pair().Symbol] = struct{}{}
+			continue
+		}
+		unsubscriber, ok := ps.(scrapers.PairUnsubscriber)
+		if !ok {
+			if _, warned := pm.kept[foreignName]; !warned {
+				log.Warnf("cannot remove pair %s %s on exchange %s: scraper cannot unsubscribe, pair is scraped until restart", ps.Pair().Symbol, foreignName, pm.exchange)
+				pm.kept[foreignName] = struct{}{}
+			}
+			symbols[ps.Pair().Symbol] = struct{}{}
+			continue
+		}
+		log.Println("Removing pair:", ps.Pair().Symbol, foreignName, "on exchange", pm.exchange)
+		if err := unsubscriber.Unsubscribe(); err != nil {
+			log.Errorf("unsubscribe pair scraper for %s: %v", foreignName, err)
+		}
+		delete(pm.running, foreignName)
+	}
+
+	for _, pair := range pairs {
+		if _, ok := pm.running[pair.ForeignName]; ok {
+			continue
+		}
+		if pm.onePairPerSymbol {
+			if _, ok := symbols[pair.Symbol]; ok {
+				log.Println("Skipping pair:", pair.Symbol, pair.ForeignName, "on exchange", pm.exchange)
+				continue
+			}
+		}
+		log.Println("Adding pair:", pair.Symbol, pair.ForeignName, "on exchange", pm.exchange)
+		ps, err := pm.es.ScrapePair(dia.ExchangePair{
+			Symbol:      pair.Symbol,
+			ForeignName: pair.ForeignName})
+		if err != nil {
+			log.Println(err)
+			continue
+		}
+		pm.running[pair.ForeignName] = ps
+		symbols[pair.Symbol] = struct{}{}
+	}
+}
+
+// reload fetches the exchange's pairs from postgres and updates the running PairScrapers.
+// The running PairScrapers are kept if no pairs can be fetched.
+func (pm *pairManager) reload() {
+	pairs, err := pm.fetchPairs()
+	if err != nil {
+		log.Error("reload pairs: ", err)
+		return
+	}
+	if len(pairs) == 0 {
+		log.Warn("reload pairs: no pairs found for ", pm.exchange)
+		return
+	}
+	pm.update(pairs)
+	log.Infof("reloaded pairs: scraping %d pairs on %s", len(pm.running), pm.exchange)
+}
+
+// run reloads the pairs every @interval and whenever a reload is requested through @requests.
+func (pm *pairManager) run(interval time.Duration, requests <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pm.reload()
+		case _, ok := <-requests:
+			if !ok {
+				requests = nil
+				continue
+			}
+			log.Info("reload of pairs requested")
+			pm.reload()
+		}
+	}
+}
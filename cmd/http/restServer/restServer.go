@@ -182,17 +182,17 @@ func main() {
 
 	memoryStore := persistence.NewInMemoryStore(time.Second)
 
-	store, err := models.NewDataStore()
+	store, err := models.NewDataStoreFromEnv()
 	if err != nil {
 		log.Errorln("NewDataStore", err)
 	}
-	relStore, err := models.NewRelDataStore()
+	relStore, err := models.NewRelDataStoreFromEnv()
 	if err != nil {
 		log.Errorln("NewRelDataStore", err)
 	}
 	diaApiEnv := &diaApi.Env{
 		DataStore: store,
-		RelDB:     *relStore,
+		RelDB:     relStore,
 	}
 
 	diaAuth := r.Group("/v1")
@@ -201,6 +201,7 @@ func main() {
 		diaAuth.POST("/supply", diaApiEnv.PostSupply)
 		diaAuth.POST("/indexRebalance/:symbol", diaApiEnv.PostIndexRebalance)
 		diaAuth.POST("/quotation", diaApiEnv.SetQuotation)
+		diaAuth.POST("/pairsReload/:exchange", diaApiEnv.PostPairsReload)
 	}
 
 	diaGroup := r.Group("/v1")
@@ -237,6 +238,8 @@ func main() {
 		diaGroup.GET("/tokenexchanges/:symbol", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetAssetExchanges))
 
 		diaGroup.GET("/blockchains", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetAllBlockchains))
+		diaGroup.GET("/assetEquivalences", cache.CachePageAtomic(memoryStore, cachingTimeLong, diaApiEnv.GetAssetEquivalences))
+		diaGroup.GET("/orderBook/:exchange/:pair", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetOrderBookMetrics))
 
 		diaGroup.GET("CryptoDerivatives/:type/:name", cache.CachePageAtomic(memoryStore, cachingTimeShort, diaApiEnv.GetCryptoDerivative))
 
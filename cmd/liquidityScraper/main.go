@@ -43,7 +43,7 @@ func main() {
 
 func runLiquiditySource(relDB *models.RelDB, datastore *models.DB, source string) {
 	log.Info("Fetching pools from ", source)
-	scraper := liquidityscraper.NewLiquidityScraper(source)
+	scraper := liquidityscraper.NewLiquidityScraper(source, relDB)
 
 	for {
 		select {
@@ -13,6 +13,7 @@ const (
 	configFileBlockchains = "blockchains/blockchains"
 	configFileExchanges   = "exchanges/exchanges"
 	configFileChains      = "chainconfig/chainconfig"
+	configFileEquivalence = "assetequivalences/assetequivalences"
 )
 
 type chainConfigs struct {
@@ -23,6 +24,10 @@ type blockchainsConfig struct {
 	Blockchains []dia.BlockChain `json:"Blockchains"`
 }
 
+type assetEquivalencesConfig struct {
+	AssetEquivalences []dia.AssetEquivalence `json:"AssetEquivalences"`
+}
+
 type exchangesConfig struct {
 	Exchanges []dia.Exchange `json:"Exchanges"`
 }
@@ -71,6 +76,17 @@ func main() {
 		}
 	}
 
+	equivalences, err := fetchAssetEquivalencesFromConfig()
+	if err != nil {
+		log.Fatal("fetch asset equivalences from config file: ", err)
+	}
+	for _, equivalence := range equivalences {
+		err = rdb.SetAssetEquivalence(equivalence)
+		if err != nil {
+			log.Error("set asset equivalence to postgres: ", err)
+		}
+	}
+
 }
 
 func fetchBlockchainsFromConfig() (blockchains []dia.BlockChain, err error) {
@@ -106,3 +122,14 @@ func fetchExchangesFromConfig() (exchanges []dia.Exchange, err error) {
 	exchanges = exchangeList.Exchanges
 	return
 }
+
+func fetchAssetEquivalencesFromConfig() (equivalences []dia.AssetEquivalence, err error) {
+	content, err := configCollectors.ReadJSONFromConfig(configFileEquivalence)
+	if err != nil {
+		return
+	}
+	var equivalenceList assetEquivalencesConfig
+	err = json.Unmarshal(content, &equivalenceList)
+	equivalences = equivalenceList.AssetEquivalences
+	return
+}
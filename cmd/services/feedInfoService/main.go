@@ -81,19 +81,16 @@ func updateStatsPerAsset(asset dia.Asset, tFinal time.Time, numRanges int, datas
 
 	log.Infof("start processing data for %s -- %s....", asset.Blockchain, asset.Address)
 
-	tInit := tFinal.Add(-time.Duration(LOOKBACK_SECONDS * time.Second))
-	// Make time ranges for batching the trades getter.
-	starttimes, endtimes := utils.MakeTimeRanges(tInit, tFinal, numRanges)
-
 	var (
 		pairMap            = make(map[string]struct{})
 		pairExchangeMap    = make(map[string]struct{})
 		pairExchangeVolMap = make(map[dia.Pair]map[string]float64)
 		aggVolumes         []dia.AggregatedVolume
 		tradesDistribution dia.TradesDistribution
-		tradesCount        []int
-		binMap             = make(map[timeRange]int)
-		binDuration        = time.Duration(BIN_DURATION_SECONDS * time.Second)
+		tInit              = tFinal.Add(-time.Duration(LOOKBACK_SECONDS * time.Second))
+		binMap             = newBinMap(tInit, tFinal)
+		// Bin maps of the trades on each exchange, from which the exchange trust of the filters is derived.
+		exchangeBinMaps = make(map[string]map[timeRange]int)
 	)
 
 	// Constant trades distribution params.
@@ -103,16 +100,8 @@ func updateStatsPerAsset(asset dia.Asset, tFinal time.Time, numRanges int, datas
 	tradesDistribution.SizeBinSeconds = int64(BIN_DURATION_SECONDS)
 	tradesDistribution.TimeRangeSeconds = int64(LOOKBACK_SECONDS)
 
-	// Make bin map, mapping a time bin of size BIN_DURATION_SECONDS to the number of trades in this bin.
-	leftTime := tInit
-	for leftTime.Before(tFinal) {
-		tr := timeRange{
-			tLeft:  leftTime,
-			tRight: leftTime.Add(binDuration),
-		}
-		binMap[tr] = 0
-		leftTime = leftTime.Add(binDuration)
-	}
+	// Make time ranges for batching the trades getter.
+	starttimes, endtimes := utils.MakeTimeRanges(tInit, tFinal, numRanges)
 
 	for i := range starttimes {
 
@@ -127,24 +116,27 @@ func updateStatsPerAsset(asset dia.Asset, tFinal time.Time, numRanges int, datas
 
 		// 3. Get statistics on trades' frequency and distribution
 		binMap = computeBinMap(trades, binMap)
-
-	}
-
-	// Get trades statistics from binMap.
-	for _, value := range binMap {
-		tradesCount = append(tradesCount, value)
-		tradesDistribution.NumTradesTotal += value
-		if value < BIN_THRESHOLD {
-			tradesDistribution.NumLowBins += 1
+		for _, trade := range trades {
+			if _, ok := exchangeBinMaps[trade.Source]; !ok {
+				exchangeBinMaps[trade.Source] = newBinMap(tInit, tFinal)
+			}
+			exchangeBinMaps[trade.Source] = mapTradeToBin(trade, exchangeBinMaps[trade.Source])
 		}
+
 	}
-	tradesDistribution.AvgNumPerBin = average(tradesCount)
-	tradesDistribution.StdDeviation = math.Sqrt(variance(tradesCount))
 
-	err := relDB.SetTradesDistribution(tradesDistribution)
+	err := relDB.SetTradesDistribution(computeTradesDistribution(tradesDistribution, binMap))
 	if err != nil {
 		log.Errorf("set trades distributionfor %s - %s: %v", asset.Address, asset.Blockchain, err)
 	}
+	for exchange, exchangeBinMap := range exchangeBinMaps {
+		exchangeDistribution := tradesDistribution
+		exchangeDistribution.Exchange = exchange
+		err = relDB.SetTradesDistribution(computeTradesDistribution(exchangeDistribution, exchangeBinMap))
+		if err != nil {
+			log.Errorf("set trades distribution for %s - %s on %s: %v", asset.Address, asset.Blockchain, exchange, err)
+		}
+	}
 
 	// Fill pairVolumes slice, i.e. sort by exchange and pair.
 	for pair, exchangeMap := range pairExchangeVolMap {
@@ -170,6 +162,37 @@ func updateStatsPerAsset(asset dia.Asset, tFinal time.Time, numRanges int, datas
 
 }
 
+// newBinMap returns a bin map with empty bins of size BIN_DURATION_SECONDS covering @tInit - @tFinal.
+func newBinMap(tInit time.Time, tFinal time.Time) map[timeRange]int {
+	binMap := make(map[timeRange]int)
+	binDuration := time.Duration(BIN_DURATION_SECONDS * time.Second)
+	leftTime := tInit
+	for leftTime.Before(tFinal) {
+		tr := timeRange{
+			tLeft:  leftTime,
+			tRight: leftTime.Add(binDuration),
+		}
+		binMap[tr] = 0
+		leftTime = leftTime.Add(binDuration)
+	}
+	return binMap
+}
+
+// computeTradesDistribution returns @tradesDistribution with the trades statistics of @binMap.
+func computeTradesDistribution(tradesDistribution dia.TradesDistribution, binMap map[timeRange]int) dia.TradesDistribution {
+	var tradesCount []int
+	for _, value := range binMap {
+		tradesCount = append(tradesCount, value)
+		tradesDistribution.NumTradesTotal += value
+		if value < BIN_THRESHOLD {
+			tradesDistribution.NumLowBins += 1
+		}
+	}
+	tradesDistribution.AvgNumPerBin = average(tradesCount)
+	tradesDistribution.StdDeviation = math.Sqrt(variance(tradesCount))
+	return tradesDistribution
+}
+
 // computeBinMap returns the mapping of a time bin of size BIN_DURATION_SECONDS to the number of trades in this bin.
 func computeBinMap(trades []dia.Trade, binMap map[timeRange]int) map[timeRange]int {
 	for _, trade := range trades {
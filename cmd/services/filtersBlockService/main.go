@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"flag"
+	"io"
 	"sync"
 	"time"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
 )
@@ -21,6 +23,16 @@ var (
 	tradesBlockTopic      int
 	filtersblockDoneTopic int
 	fbsDoneWriter         *kafka.Writer
+	// filterConfigSource is one of "config", "postgres" or empty for the default filters.
+	filterConfigSource = utils.Getenv("FILTER_CONFIG_SOURCE", "")
+	// Exchange trust scores for trust weighted filters are read from the config file if EXCHANGE_TRUST is true.
+	// If it is distribution, the scores are further scaled by the activity of the exchanges in each asset
+	// according to the trades distributions of the feedInfoService in postgres.
+	exchangeTrust = utils.Getenv("EXCHANGE_TRUST", "false")
+	// If KAFKA_GROUP_ID is set, tradesBlocks are consumed as member of this consumer group and their
+	// offsets are committed once the resulting filtersBlock is written. The filters of each partition of
+	// the tradesBlock topic are computed separately and written to the same partition of the filtersBlock topic.
+	kafkaGroupID = utils.Getenv("KAFKA_GROUP_ID", "")
 )
 
 func init() {
@@ -46,13 +58,17 @@ func main() {
 		f := filters.NewFiltersBlockService(nil, s, nil)
 		createTradeBlockFromInflux(s, f)
 	} else {
-		s, err := models.NewDataStore()
+		if kafkaGroupID != "" {
+			runConsumerGroup()
+			return
+		}
+		s, err := models.NewDataStoreFromEnv()
 		if err != nil {
 			log.Errorln("NewDataStore", err)
 		}
 		channel := make(chan *dia.FiltersBlock)
 
-		f := filters.NewFiltersBlockService(loadFilterPointsFromPreviousBlock(), s, channel)
+		f := filters.NewFiltersBlockServiceWithTrust(loadFilterPointsFromPreviousBlock(), s, channel, loadFilterConfigs(), loadExchangeTrust())
 
 		w := kafkaHelper.NewSyncWriter(filtersBlockTopic)
 
@@ -65,9 +81,10 @@ func main() {
 
 		wg := sync.WaitGroup{}
 
+		r := kafkaHelper.NewReaderNextMessage(tradesBlockTopic)
+
 		go handler(channel, &wg, w)
 
-		r := kafkaHelper.NewReaderNextMessage(tradesBlockTopic)
 		defer func() {
 			err := r.Close()
 			if err != nil {
@@ -133,6 +150,223 @@ func handler(channel chan *dia.FiltersBlock, wg *sync.WaitGroup, w *kafka.Writer
 	}
 }
 
+// runConsumerGroup consumes the tradesBlocks of the partitions assigned to this instance in the consumer group
+// KAFKA_GROUP_ID. Each partition of the tradesBlock topic is processed by its own partitionFilters.
+func runConsumerGroup() {
+	log.Infof("consume tradesBlocks in consumer group %s", kafkaGroupID)
+	checkPartitions()
+	r := kafkaHelper.NewGroupReader(tradesBlockTopic, kafkaGroupID)
+	defer func() {
+		err := r.Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+	w := kafkaHelper.NewPartitionWriter(filtersBlockTopic)
+	defer func() {
+		err := w.Close()
+		if err != nil {
+			log.Error(err)
+		}
+	}()
+	if *historical {
+		fbsDoneWriter = kafkaHelper.NewSyncWriter(filtersblockDoneTopic)
+	}
+
+	filterConfigs := loadFilterConfigs()
+	trust := loadExchangeTrust()
+	partitions := make(map[int]*partitionFilters)
+	for {
+		m, err := r.FetchMessage(context.Background())
+		if err != nil {
+			log.Printf(err.Error())
+			continue
+		}
+		p, ok := partitions[m.Partition]
+		if !ok {
+			log.Infof("start filters of partition %d", m.Partition)
+			// Filters of different partitions run concurrently and need their own batches of datastore writes.
+			ds, err := models.NewDataStoreFromEnv()
+			if err != nil {
+				log.Errorln("NewDataStore", err)
+			}
+			p = &partitionFilters{partition: m.Partition, datastore: ds, reader: r, writer: w, filterConfigs: filterConfigs, trust: trust}
+			p.restart()
+			partitions[m.Partition] = p
+		} else if p.isStale() {
+			log.Warnf("filtersBlock partition %d was written by another instance, restart its filters", m.Partition)
+			p.restart()
+		}
+		// The offset is added once the block's filtersBlock, if any, is handed to the
+		// handler, so it is committed once that filtersBlock or a later one is written.
+		committer := p.currentCommitter()
+		ack := func() { committer.Add(m) }
+
+		var tb dia.TradesBlock
+		err = tb.UnmarshalBinary(m.Value)
+		if err != nil {
+			log.Error("error unmarshalling trades block")
+			// Malformed messages are acknowledged behind the blocks still being processed.
+			p.service.ProcessTradesBlockWithAck(nil, ack)
+			continue
+		}
+		t0 := time.Now()
+		log.Infof("number of trades in received tradesblock of partition %d: %d", m.Partition, len(tb.TradesBlockData.Trades))
+		p.service.ProcessTradesBlockWithAck(&tb, ack)
+		log.Info("time spent by filtersblockservice for processing tradesblock: ", time.Since(t0))
+		if *historical {
+			lastTimestamp := tb.TradesBlockData.EndTime
+			err := kafkaHelper.WriteMessage(fbsDoneWriter, &lastTimestamp)
+			if err != nil {
+				log.Error("kafka: fbs-done feedback: ", err)
+			}
+		}
+	}
+}
+
+// checkPartitions exits unless each partition of the tradesBlock topic has a partition of the filtersBlock topic.
+func checkPartitions() {
+	tradesBlockPartitions, err := kafkaHelper.Partitions(tradesBlockTopic)
+	if err != nil {
+		log.Fatal("read tradesBlock partitions: ", err)
+	}
+	filtersBlockPartitions, err := kafkaHelper.Partitions(filtersBlockTopic)
+	if err != nil {
+		log.Fatal("read filtersBlock partitions: ", err)
+	}
+	if len(filtersBlockPartitions) < len(tradesBlockPartitions) {
+		log.Fatalf("filtersBlock topic has %d partitions, tradesBlock topic has %d", len(filtersBlockPartitions), len(tradesBlockPartitions))
+	}
+}
+
+// partitionFilters computes the filters of the assets in a single partition of the tradesBlock topic and writes
+// the filtersBlocks to the same partition of the filtersBlock topic. As a partition is consumed by a single member
+// of the consumer group, each partition of the filtersBlock topic has a single writer per block.
+type partitionFilters struct {
+	partition     int
+	datastore     models.Datastore
+	reader        *kafka.Reader
+	writer        *kafka.Writer
+	filterConfigs []dia.FilterConfig
+	trust         filters.ExchangeTrust
+
+	service *filters.FiltersBlockService
+	// lock guards the fields below, which are shared with the handler.
+	lock      sync.Mutex
+	channel   chan *dia.FiltersBlock
+	committer *kafkaHelper.Committer
+	// lastHash is the hash of the last filtersBlock in the partition known to this instance.
+	lastHash string
+}
+
+// restart replaces the filters of the partition by filters starting at the last filtersBlock in the partition.
+// filtersBlocks of the previous filters which are not written yet are dropped and their offsets are not committed.
+func (p *partitionFilters) restart() {
+	if p.service != nil {
+		if err := p.service.Close(); err != nil {
+			log.Error("close filters: ", err)
+		}
+	}
+	p.lock.Lock()
+	if p.channel != nil {
+		close(p.channel)
+	}
+	var previousPoints []dia.FilterPoint
+	p.lastHash = ""
+	lastBlock, err := kafkaHelper.GetLastElementOfPartition(filtersBlockTopic, p.partition)
+	if err == nil {
+		fb := lastBlock.(dia.FiltersBlock)
+		previousPoints = fb.FiltersBlockData.FilterPoints
+		p.lastHash = fb.BlockHash
+	} else if err != io.EOF {
+		log.Errorf("read last filtersBlock of partition %d: %v", p.partition, err)
+	}
+	p.channel = make(chan *dia.FiltersBlock)
+	p.committer = kafkaHelper.NewCommitter(p.reader)
+	p.lock.Unlock()
+
+	p.service = filters.NewFiltersBlockServiceWithTrust(previousPoints, p.datastore, p.channel, p.filterConfigs, p.trust)
+	go p.handle(p.channel)
+}
+
+// handle writes the filtersBlocks received from @channel to the partition and commits the offsets of their tradesBlocks.
+func (p *partitionFilters) handle(channel chan *dia.FiltersBlock) {
+	var block int
+	for filtersblock := range channel {
+		p.lock.Lock()
+		if p.channel != channel {
+			// The filters were restarted, as another instance wrote to the partition in the meantime.
+			p.lock.Unlock()
+			continue
+		}
+		block++
+		log.Infof("kafka: generated %d blocks in partition %d", block, p.partition)
+		kafkaHelper.WriteMessageToPartitionWithRetryOnError(p.writer, filtersblock, p.partition)
+		p.lastHash = filtersblock.BlockHash
+		err := p.committer.Commit()
+		if err != nil {
+			log.Errorln("kafka: commit offsets", err)
+		}
+		p.lock.Unlock()
+	}
+	log.Infof("handler of partition %d: finishing channel", p.partition)
+}
+
+// isStale returns true if the last filtersBlock in the partition was not written by this instance,
+// i.e. the partition was assigned to another member of the consumer group in the meantime.
+func (p *partitionFilters) isStale() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	lastBlock, err := kafkaHelper.GetLastElementOfPartition(filtersBlockTopic, p.partition)
+	if err == io.EOF {
+		return false
+	}
+	if err != nil {
+		log.Errorf("read last filtersBlock of partition %d: %v", p.partition, err)
+		return false
+	}
+	return lastBlock.(dia.FiltersBlock).BlockHash != p.lastHash
+}
+
+// currentCommitter returns the committer of the current filters of the partition.
+func (p *partitionFilters) currentCommitter() *kafkaHelper.Committer {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	return p.committer
+}
+
+// loadFilterConfigs returns the filters to be run according to FILTER_CONFIG_SOURCE.
+func loadFilterConfigs() []dia.FilterConfig {
+	filterConfigs, err := filters.GetFilterConfigs(filterConfigSource)
+	if err != nil {
+		log.Fatalf("load filter configs from %s: %v", filterConfigSource, err)
+	}
+	if len(filterConfigs) == 0 {
+		log.Fatalf("no filter configs found in %s", filterConfigSource)
+	}
+	log.Infof("loaded %d filter configs", len(filterConfigs))
+	return filterConfigs
+}
+
+func loadExchangeTrust() filters.ExchangeTrust {
+	if exchangeTrust != "true" && exchangeTrust != "distribution" {
+		return nil
+	}
+	trust, err := filters.GetExchangeTrustFromConfig()
+	if err != nil {
+		log.Fatal("load exchange trust scores: ", err)
+	}
+	log.Infof("loaded trust scores for %d exchanges", len(trust.ExchangeTrust))
+	if exchangeTrust == "distribution" {
+		relDB, err := models.NewRelDataStoreFromEnv()
+		if err != nil {
+			log.Fatal("connect to relational datastore: ", err)
+		}
+		return filters.NewDistributionExchangeTrust(trust, relDB)
+	}
+	return trust
+}
+
 func loadFilterPointsFromPreviousBlock() []dia.FilterPoint {
 	// load the previous block points so that we have a value even if
 	// there is no trades
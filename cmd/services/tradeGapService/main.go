@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/internal/pkg/tradeGapService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	scrapers "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
+	log "github.com/sirupsen/logrus"
+)
+
+// tradeGapService detects gaps in the trades of the exchanges given by the comma separated
+// environment variable EXCHANGES and backfills them through the exchanges' historical scrapers.
+// Backfilled trades are written to the measurement tradesTmp and merged into trades afterwards.
+
+func main() {
+	datastore, err := models.NewDataStore()
+	if err != nil {
+		log.Fatal("NewDataStore: ", err)
+	}
+	relDB, err := models.NewRelDataStore()
+	if err != nil {
+		log.Fatal("NewRelDataStore: ", err)
+	}
+
+	config := tradeGapService.Config{
+		Lookback:          time.Duration(getenvInt("LOOKBACK_HOURS", 24)) * time.Hour,
+		BinSize:           time.Duration(getenvInt("BIN_SIZE_SECONDS", 300)) * time.Second,
+		Delay:             time.Duration(getenvInt("DELAY_SECONDS", 300)) * time.Second,
+		MinExpectedTrades: float64(getenvInt("MIN_EXPECTED_TRADES", 20)),
+		InfluxDB:          utils.Getenv("INFLUX_DB", "dia"),
+		TradesTable:       utils.Getenv("INFLUX_MEASUREMENT_TRADES", "trades"),
+		TmpTable:          utils.Getenv("INFLUX_MEASUREMENT_TMP", "tradesTmp"),
+	}
+	interval := time.Duration(getenvInt("INTERVAL_SECONDS", 3600)) * time.Second
+
+	backfillers := make(map[string]tradeGapService.Backfiller)
+	for _, exchange := range strings.Split(utils.Getenv("EXCHANGES", ""), ",") {
+		if exchange == "" {
+			continue
+		}
+		hs, err := newHistoricalScraper(exchange, relDB)
+		if err != nil {
+			log.Fatal(err)
+		}
+		backfillers[exchange] = newBackfiller(hs, exchange, relDB)
+	}
+	if len(backfillers) == 0 {
+		log.Fatal("no exchanges given")
+	}
+
+	tradeGapService.NewTradeGapService(datastore, relDB, config, backfillers).Run(context.Background(), interval)
+}
+
+// newHistoricalScraper returns the historical scraper of @exchange. UniswapV2 forks are backfilled
+// through the UniswapHistoryScraper, all other exchanges through their APIScraper.
+func newHistoricalScraper(exchange string, relDB *models.RelDB) (scrapers.HistoricalScraper, error) {
+	if ex, ok := scrapers.Exchanges[exchange]; ok && ex.ScraperType == dia.ScraperTypeUniswapV2 {
+		return scrapers.NewUniswapHistoryScraper(ex, false, relDB), nil
+	}
+	hs, ok := scrapers.NewAPIScraper(exchange, false, "", "", relDB).(scrapers.HistoricalScraper)
+	if !ok {
+		return nil, fmt.Errorf("exchange %s does not support historical scraping", exchange)
+	}
+	return hs, nil
+}
+
+// newBackfiller returns a backfiller fetching the trades of a gap through @hs.
+func newBackfiller(hs scrapers.HistoricalScraper, exchange string, relDB *models.RelDB) tradeGapService.Backfiller {
+	return func(ctx context.Context, gap dia.TradeGap, chanTrades chan<- *dia.Trade) error {
+		pair := dia.ExchangePair{ForeignName: gap.Pair, Exchange: exchange}
+		if exchangepair, err := relDB.GetExchangePairCache(exchange, gap.Pair); err == nil {
+			pair.Symbol = exchangepair.Symbol
+		}
+		log.Infof("backfill trades of %s on %s from %v to %v", gap.Pair, exchange, gap.StartTime, gap.EndTime)
+		return scrapers.BackfillTrades(ctx, hs, exchange, pair, gap.StartTime, gap.EndTime, relDB, chanTrades)
+	}
+}
+
+func getenvInt(key string, fallback int) int {
+	value, err := strconv.Atoi(utils.Getenv(key, strconv.Itoa(fallback)))
+	if err != nil {
+		log.Fatalf("parse %s: %v", key, err)
+	}
+	return value
+}
@@ -4,16 +4,18 @@ import (
 	"context"
 	"flag"
 	"sync"
+	"time"
 
 	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers/kafkaHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/segmentio/kafka-go"
 	log "github.com/sirupsen/logrus"
 )
 
-func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.WaitGroup, w *kafka.Writer) {
+func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.WaitGroup, w *kafka.Writer, committer *kafkaHelper.Committer, equivalences *dia.AssetEquivalenceTable) {
 	for {
 		t, ok := <-blockMaker.Channel()
 		if !ok {
@@ -21,9 +23,47 @@ func handleBlocks(blockMaker *tradesBlockService.TradesBlockService, wg *sync.Wa
 			wg.Done()
 			return
 		}
-		err := kafkaHelper.WriteMessage(w, t)
+		if partitions != nil {
+			// Bridged assets are partitioned with their canonical assets, such that all trades of an asset
+			// in a window end up in a single tradesBlock.
+			partitionOf := func(trade dia.Trade) int {
+				return kafkaHelper.PartitionForKey(trade.CanonicalKafkaKey(equivalences), partitions)
+			}
+			for _, block := range tradesBlockService.SplitTradesBlock(t, partitionOf) {
+				partition := partitionOf(block.TradesBlockData.Trades[0])
+				if committer == nil {
+					err := kafkaHelper.WriteMessageToPartition(w, block, partition)
+					if err != nil {
+						log.Errorln("handleBlocks", err)
+					}
+				} else {
+					kafkaHelper.WriteMessageToPartitionWithRetryOnError(w, block, partition)
+				}
+			}
+		} else if committer == nil {
+			err := kafkaHelper.WriteMessage(w, t)
+			if err != nil {
+				log.Errorln("handleBlocks", err)
+			}
+		} else {
+			kafkaHelper.WriteMessageWithRetryOnError(w, t)
+		}
+		if committer == nil {
+			continue
+		}
+		// Offsets of the block's trades are only committed once the block and its trades are written.
+		for {
+			err := blockMaker.Flush()
+			if err == nil {
+				break
+			}
+			log.Errorln("handleBlocks: flush trades, retrying...", err)
+			time.Sleep(flushRetryDelay)
+		}
+		blockMaker.Acknowledge(t)
+		err := committer.Commit()
 		if err != nil {
-			log.Errorln("handleBlocks", err)
+			log.Errorln("handleBlocks: commit offsets", err)
 		}
 	}
 }
@@ -43,14 +83,55 @@ var (
 	historical       = flag.Bool("historical", false, "digest current or historical trades")
 	tradesBlockTopic int
 	tradesTopic      int
+	// Trades are checked against a reference price if PRICE_SANITY_CHECK is true.
+	priceSanityCheck = utils.Getenv("PRICE_SANITY_CHECK", "false")
+	// If KAFKA_GROUP_ID is set, trades are consumed as member of this consumer group and their
+	// offsets are committed once the tradesBlock containing them is written.
+	kafkaGroupID = utils.Getenv("KAFKA_GROUP_ID", "")
+	// If KAFKA_PARTITION_BY_ASSET is true, tradesBlocks are split by the tradesBlock partition of the canonical asset
+	// of their trades' quote token.
+	partitionByAsset = utils.Getenv("KAFKA_PARTITION_BY_ASSET", "false")
+	partitions       []int
+	// If PRICE_GRAPH is true, base tokens without quotation are priced along the most liquid path from a USD anchor.
+	priceGraph = utils.Getenv("PRICE_GRAPH", "false")
+)
+
+const (
+	poolRefreshInterval = 10 * time.Minute
+	flushRetryDelay     = 5 * time.Second
 )
 
+// refreshPools periodically updates the pool liquidity of the price graph @pg.
+func refreshPools(pg *tradesBlockService.PriceGraph, rdb models.RelDatastore) {
+	for {
+		pools, err := rdb.GetAllPools()
+		if err != nil {
+			log.Error("get pools for price graph: ", err)
+		} else {
+			pg.SetPools(pools)
+			log.Infof("updated price graph with %d pools", len(pools))
+		}
+		time.Sleep(poolRefreshInterval)
+	}
+}
+
 func main() {
 	if *historical {
 		log.Info("run tradesblock service in historical mode")
 	}
 
-	kafkaWriter := kafkaHelper.NewSyncWriter(tradesBlockTopic)
+	var kafkaWriter *kafka.Writer
+	if partitionByAsset == "true" {
+		var err error
+		partitions, err = kafkaHelper.Partitions(tradesBlockTopic)
+		if err != nil {
+			log.Fatal("read tradesBlock partitions: ", err)
+		}
+		log.Infof("split tradesBlocks into %d partitions", len(partitions))
+		kafkaWriter = kafkaHelper.NewPartitionWriter(tradesBlockTopic)
+	} else {
+		kafkaWriter = kafkaHelper.NewSyncWriter(tradesBlockTopic)
+	}
 	defer func() {
 		err := kafkaWriter.Close()
 		if err != nil {
@@ -58,7 +139,15 @@ func main() {
 		}
 	}()
 
-	kafkaReader := kafkaHelper.NewReaderNextMessage(tradesTopic)
+	var kafkaReader *kafka.Reader
+	var committer *kafkaHelper.Committer
+	if kafkaGroupID != "" {
+		log.Infof("consume trades in consumer group %s", kafkaGroupID)
+		kafkaReader = kafkaHelper.NewGroupReader(tradesTopic, kafkaGroupID)
+		committer = kafkaHelper.NewCommitter(kafkaReader)
+	} else {
+		kafkaReader = kafkaHelper.NewReaderNextMessage(tradesTopic)
+	}
 	defer func() {
 		err := kafkaReader.Close()
 		if err != nil {
@@ -66,29 +155,78 @@ func main() {
 		}
 	}()
 
-	s, err := models.NewDataStore()
+	s, err := models.NewDataStoreFromEnv()
 	if err != nil {
 		log.Errorln("NewDataStore", err)
 	}
 
-	service := tradesBlockService.NewTradesBlockService(s, dia.BlockSizeSeconds, *historical)
+	var sanityCheck *tradesBlockService.PriceSanityCheck
+	if priceSanityCheck == "true" {
+		sanityConfig, err := tradesBlockService.GetPriceSanityConfigFromConfig()
+		if err != nil {
+			log.Fatal("read price sanity config: ", err)
+		}
+		sanityCheck, err = tradesBlockService.NewPriceSanityCheckFromConfig(s, sanityConfig, *historical)
+		if err != nil {
+			log.Fatal("new price sanity check: ", err)
+		}
+		log.Infof("check trades against %s reference with tolerance %v", sanityConfig.Reference, sanityConfig.Tolerance)
+	}
+
+	rdb, err := models.NewRelDataStoreFromEnv()
+	if err != nil {
+		log.Fatal("new relational datastore: ", err)
+	}
+	equivalences, err := rdb.GetAllAssetEquivalences()
+	if err != nil {
+		log.Fatal("get asset equivalences: ", err)
+	}
+	log.Infof("loaded %d asset equivalences", len(equivalences))
+	assetEquivalences := dia.NewAssetEquivalenceTable(equivalences)
+
+	var pg *tradesBlockService.PriceGraph
+	if priceGraph == "true" {
+		pg = tradesBlockService.NewPriceGraph(tradesBlockService.DefaultPriceGraphConfig())
+		go refreshPools(pg, rdb)
+	}
+
+	service := tradesBlockService.NewTradesBlockServiceWithOptions(s, dia.BlockSizeSeconds, *historical, tradesBlockService.TradesBlockServiceOptions{
+		SanityCheck:       sanityCheck,
+		AssetEquivalences: assetEquivalences,
+		PriceGraph:        pg,
+	})
 
 	wg := sync.WaitGroup{}
-	go handleBlocks(service, &wg, kafkaWriter)
+	go handleBlocks(service, &wg, kafkaWriter, committer, assetEquivalences)
 
 	log.Printf("starting...")
 
 	for {
-		m, err := kafkaReader.ReadMessage(context.Background())
+		var m kafka.Message
+		var err error
+		if committer != nil {
+			m, err = kafkaReader.FetchMessage(context.Background())
+		} else {
+			m, err = kafkaReader.ReadMessage(context.Background())
+		}
 		if err != nil {
 			log.Printf(err.Error())
 		} else {
 			var t dia.Trade
 			err := t.UnmarshalBinary(m.Value)
 			if err == nil {
-				service.ProcessTrade(&t)
+				if committer != nil {
+					service.ProcessTradeWithAck(&t, func() { committer.Add(m) })
+				} else {
+					service.ProcessTrade(&t)
+				}
 			} else {
 				log.Printf("ignored message at offset %d: %s = %s\n", m.Offset, string(m.Key), string(m.Value))
+				if committer != nil {
+					// Queue the ack behind the trades still pending, as the commit of an offset
+					// commits all lower offsets of the partition.
+					service.ProcessTradeWithAck(nil, func() { committer.Add(m) })
+				}
 			}
 		}
 	}
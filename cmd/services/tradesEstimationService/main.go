@@ -25,7 +25,17 @@ func main() {
 		log.Errorln("NewDataStore", err)
 	}
 
-	service := tradesEstimationService.NewTradesEstimationService(s)
+	rdb, err := models.NewRelDataStore()
+	if err != nil {
+		log.Fatal("new relational datastore: ", err)
+	}
+	equivalences, err := rdb.GetAllAssetEquivalences()
+	if err != nil {
+		log.Fatal("get asset equivalences: ", err)
+	}
+	log.Infof("loaded %d asset equivalences", len(equivalences))
+
+	service := tradesEstimationService.NewTradesEstimationServiceWithAssetEquivalences(s, dia.NewAssetEquivalenceTable(equivalences))
 
 	log.Printf("starting...")
 
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"os"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/internal/pkg/replay"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// tradesReplay re-runs recorded trades through the tradesBlockService and the filtersBlockService
+// and writes the resulting filtersBlocks as JSON lines to stdout. Example:
+// tradesReplay -source=jsonl -file=trades.jsonl -start=2022-05-01T12:00:00Z -end=2022-05-01T13:00:00Z
+
+var (
+	source             = flag.String("source", "influx", "source of the trades: influx or jsonl")
+	file               = flag.String("file", "", "JSON lines file with trades if source is jsonl")
+	quotationsFile     = flag.String("quotations", "", "optional JSON lines file with asset quotations used for base token prices")
+	exchange           = flag.String("exchange", "", "only replay trades from this exchange (influx only)")
+	start              = flag.String("start", "", "begin of the time range in RFC3339 format")
+	end                = flag.String("end", "", "end of the time range in RFC3339 format")
+	filterConfigSource = flag.String("filterConfig", "", "source of the filter configs: config, postgres or empty for the default filters")
+)
+
+func main() {
+	flag.Parse()
+
+	starttime, err := time.Parse(time.RFC3339, *start)
+	if err != nil {
+		log.Fatal("parse start time: ", err)
+	}
+	endtime, err := time.Parse(time.RFC3339, *end)
+	if err != nil {
+		log.Fatal("parse end time: ", err)
+	}
+
+	filterConfigs, err := filters.GetFilterConfigs(*filterConfigSource)
+	if err != nil {
+		log.Fatal("load filter configs: ", err)
+	}
+
+	var (
+		trades []dia.Trade
+		ds     *replay.Datastore
+	)
+	switch *source {
+	case "influx":
+		influxDatastore, err := models.NewInfluxDataStore()
+		if err != nil {
+			log.Fatal("new influx datastore: ", err)
+		}
+		trades, err = replay.ReadTradesInflux(influxDatastore, *exchange, starttime, endtime)
+		if err != nil {
+			log.Fatal("read trades from influx: ", err)
+		}
+		// Base token prices not computed during the replay are taken from influx.
+		ds = replay.NewDatastore(influxDatastore)
+	case "jsonl":
+		trades, err = replay.ReadTradesJSONLFile(*file, starttime, endtime)
+		if err != nil {
+			log.Fatal("read trades from file: ", err)
+		}
+		// Nothing is read from or written to redis and influx.
+		ds = replay.NewDatastore(models.NewMemoryDataStore())
+	default:
+		log.Fatalf("unknown source %s", *source)
+	}
+	log.Infof("replay %d trades in [%v, %v)", len(trades), starttime, endtime)
+
+	if *quotationsFile != "" {
+		quotations, err := replay.ReadQuotationsJSONLFile(*quotationsFile)
+		if err != nil {
+			log.Fatal("read quotations from file: ", err)
+		}
+		for i := range quotations {
+			err = ds.SetAssetQuotation(&quotations[i])
+			if err != nil {
+				log.Fatal("set quotation: ", err)
+			}
+		}
+	}
+
+	blocks := replay.Replay(trades, ds, filterConfigs)
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, block := range blocks {
+		err = encoder.Encode(block)
+		if err != nil {
+			log.Fatal("encode block: ", err)
+		}
+	}
+	log.Infof("replayed %d filtersBlocks", len(blocks))
+}
@@ -0,0 +1,6 @@
+-- Adds the scraper configuration of UniswapV2 forks to the exchange table of databases
+-- created before it was part of pginit.sql. The exchange scrapers fail to load the
+-- exchange table without these columns, so the migration has to be run before deploying them.
+ALTER TABLE exchange ADD COLUMN IF NOT EXISTS scraper_type text;
+ALTER TABLE exchange ADD COLUMN IF NOT EXISTS init_code_hash text;
+ALTER TABLE exchange ADD COLUMN IF NOT EXISTS wait_time numeric default 0;
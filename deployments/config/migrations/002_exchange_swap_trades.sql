@@ -0,0 +1,5 @@
+-- Adds the flag for exchanges whose trades are also sent with base and quote token swapped,
+-- which replaces the list of such exchanges in the collector. The exchange scrapers fail to
+-- load the exchange table without this column, so the migration has to be run before deploying them.
+ALTER TABLE exchange ADD COLUMN IF NOT EXISTS swap_trades boolean default false;
+UPDATE exchange SET swap_trades=true WHERE name IN ('Curvefi','OmniDex','Diffusion','BalancerV2','Beets','PanCakeSwap','Solarbeam','Anyswap','Hermes','Huckleberry','Netswap','Pangolin','Arthswap');
@@ -0,0 +1,3 @@
+-- Adds the exchange of trades distributions, which are also computed per exchange for the exchange
+-- trust of the filters. Existing rows are distributions of the trades on all exchanges.
+ALTER TABLE tradesdistribution ADD COLUMN IF NOT EXISTS exchange text NOT NULL DEFAULT '';
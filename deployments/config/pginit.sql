@@ -52,6 +52,14 @@ CREATE TABLE exchange (
     ws_api text,
     pairs_api text,
     watchdog_delay numeric NOT NULL,
+    -- scraper_type selects a generic scraper such as UniswapV2 for DEX forks.
+    -- Existing databases get the following columns through migrations/001_exchange_scraper_config.sql
+    -- and migrations/002_exchange_swap_trades.sql.
+    scraper_type text,
+    init_code_hash text,
+    wait_time numeric default 0,
+    -- swap_trades is true for exchanges whose trades are also sent with base and quote token swapped.
+    swap_trades boolean default false,
     UNIQUE(exchange_id),
     UNIQUE (name)
 );
@@ -82,6 +90,39 @@ CREATE TABLE chainconfig (
     UNIQUE (chainID)
 );
 
+-- filterconfig holds the filters run by the filtersBlockService.
+-- Empty blockchain/address resp. exchange fields apply to all assets resp. exchanges.
+CREATE TABLE filterconfig (
+    filterconfig_id UUID DEFAULT gen_random_uuid(),
+    name text NOT NULL,
+    blockchain text NOT NULL DEFAULT '',
+    address text NOT NULL DEFAULT '',
+    exchange text NOT NULL DEFAULT '',
+    memory integer NOT NULL,
+    outlier_scale numeric NOT NULL DEFAULT 0,
+    min_trades integer NOT NULL DEFAULT 0,
+    trust_weighted boolean NOT NULL DEFAULT false,
+    max_volume_share numeric NOT NULL DEFAULT 0,
+    min_pool_liquidity_usd numeric NOT NULL DEFAULT 0,
+    discount_illiquid boolean NOT NULL DEFAULT false,
+    UNIQUE (filterconfig_id),
+    UNIQUE (name,blockchain,address,exchange)
+);
+
+-- assetequivalence maps assets such as bridged tokens to their canonical asset.
+-- An empty exchange field applies to trades on all exchanges.
+CREATE TABLE assetequivalence (
+    assetequivalence_id UUID DEFAULT gen_random_uuid(),
+    blockchain text NOT NULL,
+    address text NOT NULL,
+    exchange text NOT NULL DEFAULT '',
+    canonical_symbol text NOT NULL,
+    canonical_blockchain text NOT NULL,
+    canonical_address text NOT NULL,
+    UNIQUE (assetequivalence_id),
+    UNIQUE (blockchain,address,exchange)
+);
+
 -- blockchain table stores all blockchains available in our databases
 CREATE TABLE blockchain (
     blockchain_id UUID DEFAULT gen_random_uuid(),
@@ -201,6 +242,33 @@ CREATE TABLE IF NOT EXISTS scrapers (
     CONSTRAINT pk_scrapers PRIMARY KEY(name)
 );
 
+-- Table oraclefeed holds the specifications of the feeds run by the oracleFeeder.
+CREATE TABLE oraclefeed (
+    name text NOT NULL,
+    -- JSON encoded dia.OracleFeed
+    config jsonb NOT NULL,
+    active boolean NOT NULL DEFAULT true,
+    UNIQUE(name)
+);
+
+-- Table oracleupdate logs the values pushed by the oracleFeeder along with their transactions.
+CREATE TABLE oracleupdate (
+    oracleupdate_id integer primary key generated always as identity,
+    feed text NOT NULL,
+    blockchain text,
+    contract text NOT NULL,
+    oracle_key text NOT NULL,
+    value numeric NOT NULL,
+    timestamp timestamp NOT NULL,
+    tx_hash text,
+    nonce numeric,
+    gas_used numeric,
+    -- one of confirmed, reverted, failed
+    status text NOT NULL,
+    error text,
+    update_time timestamp
+);
+
 CREATE TABLE blockdata (
     blockdata_id UUID DEFAULT gen_random_uuid(),
     blockchain text NOT NULL,
@@ -232,6 +300,9 @@ CREATE TABLE aggregatedvolume (
 CREATE TABLE tradesdistribution (
     tradesdistribution_id UUID DEFAULT gen_random_uuid(),
     asset_id uuid REFERENCES asset(asset_id),
+    -- exchange whose trades are counted, empty for the trades on all exchanges.
+    -- Existing databases get this column through migrations/003_tradesdistribution_exchange.sql.
+    exchange text NOT NULL DEFAULT '',
     -- total number of trades in [compute_time-time_range_seconds, compute_time]
 	num_trades_total numeric,
     -- number of bins with less than @threshold trades
@@ -245,4 +316,20 @@ CREATE TABLE tradesdistribution (
     compute_time timestamp
 );
 
+-- Table tradegap holds time ranges in which no trades of a pair were stored
+-- although trades were expected. Gaps are backfilled by the tradeGapService.
+CREATE TABLE tradegap (
+    tradegap_id integer primary key generated always as identity,
+    exchange text NOT NULL,
+    pair text NOT NULL,
+    starttime timestamp NOT NULL,
+    endtime timestamp NOT NULL,
+    expected_num_trades numeric,
+    -- one of open, backfilled, merged, failed
+    status text NOT NULL,
+    error text,
+    update_time timestamp,
+    UNIQUE(exchange, pair, starttime)
+);
+
 
@@ -42,37 +42,43 @@ func NewFilterEMA(asset dia.Asset, exchange string, currentTime time.Time, block
 }
 
 func (s *FilterEMA) Compute(trade dia.FilterPoint) {
-	s.compute(trade)
+	s.computeFilterPoint(trade)
 }
 
-func (s *FilterEMA) compute(trade dia.FilterPoint) {
+// compute feeds the trade's estimated USD price into the filter.
+func (s *FilterEMA) compute(trade dia.Trade) {
+	s.computeFilterPoint(dia.FilterPoint{
+		Asset: trade.QuoteToken,
+		Value: trade.EstimatedUSDPrice,
+		Time:  trade.Time,
+	})
+}
+
+func (s *FilterEMA) computeFilterPoint(trade dia.FilterPoint) {
 	s.modified = true
 	if s.lastTrade != nil {
-		if trade.Time.After(s.currentTime) {
-			log.Errorln("FilterMA: Ignoring Trade out of order ", s.currentTime, trade.Time)
+		if trade.Time.Before(s.currentTime) {
+			log.Errorln("FilterEMA: Ignoring Trade out of order ", s.currentTime, trade.Time)
 			return
 		}
 		s.fill(trade.Time, *s.lastTrade)
 	}
 	s.fill(trade.Time, trade)
-	log.Println("FilterEMA compute: filled order ", s.currentTime, trade)
+	log.Debugln("FilterEMA compute: filled order ", s.currentTime, trade)
 
 	s.lastTrade = &trade
 }
 
 func (e *FilterEMA) fill(t time.Time, trade dia.FilterPoint) {
-	log.Println("FilterEMA fill ", trade)
-	log.Println("FilterEMA e.multiplier ", e.multiplier)
-	log.Println("FilterEMA e.value ", e.value)
+	log.Debugln("FilterEMA fill ", trade)
 	e.currentTime = trade.Time
 	if e.value == 0 { // this is a proxy for "uninitialized"
 		e.value = trade.Value
 	} else {
 		e.value = (trade.Value * float64(e.multiplier)) + (e.value * (1 - float64(e.multiplier)))
-		log.Println("FilterEMA e.value and multiplier ", e.value, e.multiplier)
 
 	}
-	log.Println("FilterEMA e.value ", e.value)
+	log.Debugln("FilterEMA e.value ", e.value)
 
 }
 
@@ -114,7 +120,7 @@ func (s *FilterEMA) save(ds models.Datastore) error {
 		s.modified = false
 		err := ds.SetFilter(s.filterName, s.asset, s.exchange, s.value, s.currentTime)
 		if err != nil {
-			log.Errorln("FilterMA: Error:", err)
+			log.Errorln("FilterEMA: Error:", err)
 		}
 		return err
 	}
@@ -0,0 +1,120 @@
+package filters
+
+import (
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Pool states older than poolLiquidityLookback with respect to a trade are not used.
+	poolLiquidityLookback = 24 * time.Hour
+	// Liquidities are cached per pool and time bucket of size poolLiquidityBucket.
+	poolLiquidityBucket = time.Duration(dia.BlockSizeSeconds) * time.Second
+)
+
+// PoolLiquidity returns the USD liquidity of DEX pools from the pool states saved by
+// the liquidity scrapers and the quotations of the pool assets.
+type PoolLiquidity struct {
+	datastore models.Datastore
+	lookback  time.Duration
+	cache     map[poolLiquidityKey]poolLiquidityValue
+}
+
+type poolLiquidityKey struct {
+	address string
+	bucket  int64
+}
+
+type poolLiquidityValue struct {
+	liquidity float64
+	err       error
+}
+
+// NewPoolLiquidity returns a PoolLiquidity reading pool states and quotations from @datastore.
+func NewPoolLiquidity(datastore models.Datastore) *PoolLiquidity {
+	return &PoolLiquidity{
+		datastore: datastore,
+		lookback:  poolLiquidityLookback,
+		cache:     make(map[poolLiquidityKey]poolLiquidityValue),
+	}
+}
+
+// LiquidityUSD returns the USD liquidity of the pool with @poolAddress at time @t, computed from the
+// latest pool state before @t. If quotations are missing for some of the pool's assets, the liquidity
+// is extrapolated from the priced assets assuming equally weighted pool assets.
+func (pl *PoolLiquidity) LiquidityUSD(poolAddress string, t time.Time) (float64, error) {
+	key := poolLiquidityKey{address: poolAddress, bucket: t.UnixNano() / int64(poolLiquidityBucket)}
+	if value, ok := pl.cache[key]; ok {
+		return value.liquidity, value.err
+	}
+	liquidity, err := pl.liquidityUSD(poolAddress, t)
+	pl.cache[key] = poolLiquidityValue{liquidity: liquidity, err: err}
+	return liquidity, err
+}
+
+func (pl *PoolLiquidity) liquidityUSD(poolAddress string, t time.Time) (float64, error) {
+	pools, err := pl.datastore.GetPoolInflux(poolAddress, t.Add(-pl.lookback), t.Add(time.Second))
+	if err != nil {
+		return 0, err
+	}
+	if len(pools) == 0 || len(pools[0].Assetvolumes) == 0 {
+		return 0, errors.New("no pool state found")
+	}
+	var liquidity float64
+	var numPriced int
+	for _, av := range pools[0].Assetvolumes {
+		price, err := pl.datastore.GetAssetPriceUSD(av.Asset, t)
+		if err != nil || price <= 0 {
+			continue
+		}
+		liquidity += av.Volume * price
+		numPriced++
+	}
+	if numPriced == 0 {
+		return 0, errors.New("no quotation for any pool asset")
+	}
+	return liquidity * float64(len(pools[0].Assetvolumes)) / float64(numPriced), nil
+}
+
+// clearCache removes all cached liquidities.
+func (pl *PoolLiquidity) clearCache() {
+	pl.cache = make(map[poolLiquidityKey]poolLiquidityValue)
+}
+
+// filterLiquidity wraps a filter such that DEX trades from pools with a USD liquidity below
+// @minLiquidity are excluded or, if @discount is true, their volume is scaled down by the ratio
+// of the pool's liquidity to @minLiquidity. Trades without pool address or with unknown pool
+// liquidity are passed unchanged.
+type filterLiquidity struct {
+	Filter
+	liquidity    *PoolLiquidity
+	minLiquidity float64
+	discount     bool
+}
+
+func (f *filterLiquidity) compute(trade dia.Trade) {
+	if trade.PoolAddress == "" {
+		f.Filter.compute(trade)
+		return
+	}
+	liquidity, err := f.liquidity.LiquidityUSD(trade.PoolAddress, trade.Time)
+	if err != nil {
+		log.Debugf("liquidity of pool %s on %s: %v", trade.PoolAddress, trade.Source, err)
+		f.Filter.compute(trade)
+		return
+	}
+	if liquidity >= f.minLiquidity {
+		f.Filter.compute(trade)
+		return
+	}
+	if !f.discount || liquidity <= 0 {
+		log.Debugf("exclude trade from pool %s on %s with liquidity %v", trade.PoolAddress, trade.Source, liquidity)
+		return
+	}
+	trade.Volume *= liquidity / f.minLiquidity
+	f.Filter.compute(trade)
+}
@@ -0,0 +1,70 @@
+package filters
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestFilterLiquidity(t *testing.T) {
+	d := time.Date(2022, time.May, 1, 12, 0, 0, 0, time.UTC)
+	weth := dia.Asset{Symbol: "WETH", Blockchain: dia.ETHEREUM, Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2"}
+	usdc := dia.Asset{Symbol: "USDC", Blockchain: dia.ETHEREUM, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48"}
+	ds := models.NewMemoryDataStore()
+	if err := ds.SetAssetPriceUSD(weth, 2000, d.Add(-time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// 10k USD liquidity in the thin pool, only 2k of it priced.
+	for _, pool := range []struct {
+		address string
+		volumes []float64
+	}{
+		{"0xThin", []float64{1, 8000}},
+		{"0xDeep", []float64{1000, 2000000}},
+	} {
+		p := dia.Pool{Address: pool.address, Time: d.Add(-time.Minute)}
+		for i, asset := range []dia.Asset{weth, usdc} {
+			p.Assetvolumes = append(p.Assetvolumes, struct {
+				Asset  dia.Asset
+				Volume float64
+			}{asset, pool.volumes[i]})
+		}
+		if err := ds.SavePoolInflux(p); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	liquidity, err := NewPoolLiquidity(ds).LiquidityUSD("0xThin", d)
+	if err != nil || liquidity != 4000 {
+		t.Errorf("expected extrapolated liquidity 4000, got %v (%v)", liquidity, err)
+	}
+
+	trades := []dia.Trade{
+		{EstimatedUSDPrice: 2000, Volume: 1, Source: dia.KrakenExchange, Time: d},
+		{EstimatedUSDPrice: 2010, Volume: 1, Source: dia.UniswapExchange, PoolAddress: "0xDeep", Time: d.Add(time.Second)},
+		{EstimatedUSDPrice: 2600, Volume: 1, Source: dia.UniswapExchange, PoolAddress: "0xThin", Time: d.Add(2 * time.Second)},
+		// Unknown pool is passed unchanged.
+		{EstimatedUSDPrice: 2030, Volume: 1, Source: dia.UniswapExchange, PoolAddress: "0xUnknown", Time: d.Add(3 * time.Second)},
+	}
+	cases := []struct {
+		discount bool
+		expected float64
+	}{
+		{false, 2013.3333333333333},
+		// Thin pool volume is discounted by 4000/10000.
+		{true, (2000 + 2010 + 0.4*2600 + 2030) / 3.4},
+	}
+	for i, c := range cases {
+		deps := filterDependencies{poolLiquidity: NewPoolLiquidity(ds)}
+		f := newFilter(dia.FilterConfig{Name: "VWAP", Memory: 120, MinPoolLiquidityUSD: 10000, DiscountIlliquid: c.discount}, weth, "", d, deps)
+		for _, trade := range trades {
+			f.compute(trade)
+		}
+		if value := f.finalCompute(d.Add(time.Minute)); math.Abs(value-c.expected) > 1e-9 {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, value)
+		}
+	}
+}
@@ -23,6 +23,11 @@ type FilterMAIR struct {
 	value       float64
 	filterName  string
 	modified    bool
+	scale       float64
+	// exchanges of the data points, only recorded for weighted cross-exchange filters
+	exchanges      []string
+	trust          ExchangeTrust
+	maxVolumeShare float64
 }
 
 //NewFilterMAIR returns a FilterMAIR
@@ -35,6 +40,7 @@ func NewFilterMAIR(asset dia.Asset, exchange string, currentTime time.Time, memo
 		currentTime: currentTime,
 		memory:      memory,
 		filterName:  "MAIR" + strconv.Itoa(memory),
+		scale:       1.5,
 	}
 	return filter
 }
@@ -71,6 +77,9 @@ func (filter *FilterMAIR) fill(trade dia.Trade) {
 				/// Remove latest data point and update with newer
 				filter.prices = filter.prices[1:]
 				filter.volumes = filter.volumes[1:]
+				if filter.weighted() {
+					filter.exchanges = filter.exchanges[1:]
+				}
 			}
 		}
 		filter.processDataPoint(trade)
@@ -83,9 +92,21 @@ func (filter *FilterMAIR) processDataPoint(trade dia.Trade) {
 	if len(filter.prices) >= filter.memory {
 		filter.prices = filter.prices[0 : filter.memory-1]
 		filter.volumes = filter.volumes[0 : filter.memory-1]
+		if filter.weighted() {
+			filter.exchanges = filter.exchanges[0 : filter.memory-1]
+		}
 	}
 	filter.prices = append([]float64{trade.EstimatedUSDPrice}, filter.prices...)
 	filter.volumes = append([]float64{trade.Volume}, filter.volumes...)
+	if filter.weighted() {
+		filter.exchanges = append([]string{trade.Source}, filter.exchanges...)
+	}
+}
+
+// weighted returns true if the filter weights exchanges by trust and volume share.
+// Only cross-exchange filters are weighted.
+func (filter *FilterMAIR) weighted() bool {
+	return filter.exchange == "" && (filter.trust != nil || filter.maxVolumeShare > 0)
 }
 
 func (filter *FilterMAIR) FinalCompute(t time.Time) float64 {
@@ -99,8 +120,14 @@ func (filter *FilterMAIR) finalCompute(t time.Time) float64 {
 	// Add the last trade again to compensate for the delay since measurement to EOB
 	// adopted behaviour from FilterMA
 	filter.processDataPoint(filter.lastTrade)
-	cleanPrices, bounds := removeOutliers(filter.prices)
-	mean, err := computeMean(cleanPrices, filter.volumes[bounds[0]:bounds[1]])
+	var mean float64
+	var err error
+	if filter.weighted() {
+		mean, err = computeTrustWeightedMean(filter.asset, filter.prices, filter.volumes, filter.exchanges, filter.scale, filter.trust, filter.maxVolumeShare)
+	} else {
+		cleanPrices, bounds := removeOutliersScaled(filter.prices, filter.scale)
+		mean, err = computeMean(cleanPrices, filter.volumes[bounds[0]:bounds[1]])
+	}
 	if err != nil {
 		return 0.0
 	}
@@ -109,6 +136,9 @@ func (filter *FilterMAIR) finalCompute(t time.Time) float64 {
 	if len(filter.prices) > 0 && len(filter.volumes) > 0 {
 		filter.prices = []float64{filter.lastTrade.EstimatedUSDPrice}
 		filter.volumes = []float64{filter.lastTrade.Volume}
+		if filter.weighted() {
+			filter.exchanges = []string{filter.lastTrade.Source}
+		}
 	}
 	return filter.value
 }
@@ -22,6 +22,7 @@ type FilterMEDIR struct {
 	value       float64
 	filterName  string
 	modified    bool
+	scale       float64
 }
 
 //NewFilterMEDIR creates a FilterMEDIR
@@ -33,6 +34,7 @@ func NewFilterMEDIR(asset dia.Asset, exchange string, currentTime time.Time, mem
 		currentTime: currentTime,
 		memory:      memory,
 		filterName:  "MEDIR" + strconv.Itoa(memory),
+		scale:       1.5,
 	}
 	return filter
 }
@@ -70,7 +72,7 @@ func (filter *FilterMEDIR) finalCompute(t time.Time) float64 {
 		log.Info("last trade emtpy")
 		return 0.0
 	}
-	cleanPrices, _ := removeOutliers(filter.prices)
+	cleanPrices, _ := removeOutliersScaled(filter.prices, filter.scale)
 	filter.value = computeMedian(cleanPrices)
 	filter.prices = []float64{filter.lastTrade.EstimatedUSDPrice}
 	return filter.value
@@ -0,0 +1,264 @@
+package filters
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Filter config is read from config/filters/filtersBlockService.json.
+	configFileFilters = "filters/filtersBlockService"
+)
+
+// FilterParams are the parameters a filter is instantiated with.
+// Zero values fall back to the respective filter's defaults.
+type FilterParams struct {
+	Memory       int
+	OutlierScale float64
+	MinTrades    int
+	// Trust and MaxVolumeShare configure the exchange weighting of cross-exchange filters.
+	Trust          ExchangeTrust
+	MaxVolumeShare float64
+}
+
+// FilterConstructor returns a new filter for @asset on @exchange. @currentTime
+// is the begin time of the tradesBlock which triggered the creation of the filter.
+type FilterConstructor func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter
+
+var filterRegistry = make(map[string]FilterConstructor)
+
+func init() {
+	RegisterFilter("MA", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterMA(asset, exchange, currentTime, params.Memory)
+	})
+	RegisterFilter("MAIR", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		f := NewFilterMAIR(asset, exchange, currentTime, params.Memory)
+		if params.OutlierScale > 0 {
+			f.scale = params.OutlierScale
+		}
+		f.trust = params.Trust
+		f.maxVolumeShare = params.MaxVolumeShare
+		return f
+	})
+	RegisterFilter("MEDIR", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		f := NewFilterMEDIR(asset, exchange, currentTime, params.Memory)
+		if params.OutlierScale > 0 {
+			f.scale = params.OutlierScale
+		}
+		return f
+	})
+	RegisterFilter("VOL", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterVOL(asset, exchange, params.Memory)
+	})
+	RegisterFilter("VWAP", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterVWAP(asset, exchange, currentTime, params.Memory)
+	})
+	RegisterFilter("VWAPIR", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		f := NewFilterVWAPIR(asset, exchange, currentTime, params.Memory)
+		if params.OutlierScale > 0 {
+			f.scale = params.OutlierScale
+		}
+		f.trust = params.Trust
+		f.maxVolumeShare = params.MaxVolumeShare
+		return f
+	})
+	RegisterFilter("TWAP", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterTWAP(asset, exchange, currentTime, params.Memory)
+	})
+	RegisterFilter("EMA", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterEMA(asset, exchange, currentTime, params.Memory)
+	})
+	RegisterFilter("TLT", func(asset dia.Asset, exchange string, currentTime time.Time, params FilterParams) Filter {
+		return NewFilterTLT(asset, exchange)
+	})
+}
+
+// RegisterFilter makes the filter returned by @constructor available under @name.
+// A filter registered twice under the same name is overwritten.
+func RegisterFilter(name string, constructor FilterConstructor) {
+	filterRegistry[name] = constructor
+}
+
+// RegisteredFilters returns the names of all registered filters.
+func RegisteredFilters() (names []string) {
+	for name := range filterRegistry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return
+}
+
+// filterDependencies are the components shared by all filters of a FiltersBlockService.
+// Nil components disable the corresponding filter options.
+type filterDependencies struct {
+	trust         ExchangeTrust
+	poolLiquidity *PoolLiquidity
+}
+
+// newFilter returns the registered filter corresponding to @filterConfig. @deps.trust is only passed
+// to filters with trust weighting enabled. It returns nil if no filter is registered under the config's name.
+func newFilter(filterConfig dia.FilterConfig, asset dia.Asset, exchange string, currentTime time.Time, deps filterDependencies) Filter {
+	constructor, ok := filterRegistry[filterConfig.Name]
+	if !ok {
+		log.Errorf("filter %s is not registered", filterConfig.Name)
+		return nil
+	}
+	params := FilterParams{
+		Memory:         filterConfig.Memory,
+		OutlierScale:   filterConfig.OutlierScale,
+		MinTrades:      filterConfig.MinTrades,
+		MaxVolumeShare: filterConfig.MaxVolumeShare,
+	}
+	if filterConfig.TrustWeighted {
+		params.Trust = deps.trust
+	}
+	if params.Memory == 0 {
+		params.Memory = dia.BlockSizeSeconds
+	}
+	f := constructor(asset, exchange, currentTime, params)
+	if filterConfig.MinPoolLiquidityUSD > 0 && deps.poolLiquidity != nil {
+		f = &filterLiquidity{
+			Filter:       f,
+			liquidity:    deps.poolLiquidity,
+			minLiquidity: filterConfig.MinPoolLiquidityUSD,
+			discount:     filterConfig.DiscountIlliquid,
+		}
+	}
+	if params.MinTrades > 0 {
+		return &filterMinTrades{Filter: f, minTrades: params.MinTrades}
+	}
+	return f
+}
+
+// DefaultFilterConfigs returns the filters run by the filtersBlockService
+// if no filter config is given.
+func DefaultFilterConfigs() []dia.FilterConfig {
+	return []dia.FilterConfig{
+		{Name: "MA", Memory: dia.BlockSizeSeconds},
+		{Name: "VOL", Memory: dia.BlockSizeSeconds},
+		{Name: "MAIR", Memory: dia.BlockSizeSeconds},
+		{Name: "MEDIR", Memory: dia.BlockSizeSeconds},
+	}
+}
+
+// GetFilterConfigs returns the filter configs from @source, which is one of "config"
+// or "postgres". The default filter configs are returned for an empty @source.
+func GetFilterConfigs(source string) ([]dia.FilterConfig, error) {
+	switch source {
+	case "":
+		return DefaultFilterConfigs(), nil
+	case "config":
+		return GetFilterConfigsFromConfig()
+	case "postgres":
+		relDB, err := models.NewPostgresDataStore()
+		if err != nil {
+			return []dia.FilterConfig{}, err
+		}
+		return GetFilterConfigsFromPostgres(relDB)
+	default:
+		return []dia.FilterConfig{}, fmt.Errorf("unknown filter config source %s", source)
+	}
+}
+
+// GetFilterConfigsFromConfig returns the filter configs from the config file.
+func GetFilterConfigsFromConfig() ([]dia.FilterConfig, error) {
+	content, err := configCollectors.ReadJSONFromConfig(configFileFilters)
+	if err != nil {
+		return []dia.FilterConfig{}, err
+	}
+	var filterConfigList struct {
+		FilterConfigs []dia.FilterConfig `json:"FilterConfigs"`
+	}
+	err = json.Unmarshal(content, &filterConfigList)
+	return filterConfigList.FilterConfigs, err
+}
+
+// GetFilterConfigsFromPostgres returns the filter configs stored in postgres.
+func GetFilterConfigsFromPostgres(relDB *models.RelDB) ([]dia.FilterConfig, error) {
+	return relDB.GetAllFilterConfigs()
+}
+
+// filterConfigsFor returns the filter configs applying to @asset on @exchange.
+// Scoped configs are merged with the global set, where a config of a more specific scope replaces
+// the config with the same name of a less specific scope. Scopes in increasing specificity are
+// global, exchange, asset, asset and exchange.
+func filterConfigsFor(filterConfigs []dia.FilterConfig, asset dia.Asset, exchange string) []dia.FilterConfig {
+	scoped := make([][]dia.FilterConfig, 4)
+	for _, fc := range filterConfigs {
+		assetScoped := fc.Blockchain != "" || fc.Address != ""
+		if assetScoped && (fc.Blockchain != asset.Blockchain || fc.Address != asset.Address) {
+			continue
+		}
+		if fc.Exchange != "" && fc.Exchange != exchange {
+			continue
+		}
+		switch {
+		case assetScoped && fc.Exchange != "":
+			scoped[3] = append(scoped[3], fc)
+		case assetScoped:
+			scoped[2] = append(scoped[2], fc)
+		case fc.Exchange != "":
+			scoped[1] = append(scoped[1], fc)
+		default:
+			scoped[0] = append(scoped[0], fc)
+		}
+	}
+	merged := []dia.FilterConfig{}
+	index := make(map[string]int)
+	for _, configs := range scoped {
+		for _, fc := range configs {
+			if i, ok := index[fc.Name]; ok {
+				merged[i] = fc
+				continue
+			}
+			index[fc.Name] = len(merged)
+			merged = append(merged, fc)
+		}
+	}
+	return merged
+}
+
+// filterMinTrades wraps a filter such that its value is neither emitted nor saved
+// for blocks in which it received less than @minTrades trades.
+type filterMinTrades struct {
+	Filter
+	minTrades int
+	numTrades int
+	skip      bool
+}
+
+func (f *filterMinTrades) compute(trade dia.Trade) {
+	f.numTrades++
+	f.Filter.compute(trade)
+}
+
+func (f *filterMinTrades) finalCompute(t time.Time) float64 {
+	f.skip = f.numTrades < f.minTrades
+	f.numTrades = 0
+	value := f.Filter.finalCompute(t)
+	if f.skip {
+		return 0.0
+	}
+	return value
+}
+
+func (f *filterMinTrades) filterPointForBlock() *dia.FilterPoint {
+	if f.skip {
+		return nil
+	}
+	return f.Filter.filterPointForBlock()
+}
+
+func (f *filterMinTrades) save(ds models.Datastore) error {
+	if f.skip {
+		return nil
+	}
+	return f.Filter.save(ds)
+}
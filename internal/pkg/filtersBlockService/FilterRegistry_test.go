@@ -0,0 +1,89 @@
+package filters
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestFilterConfigsFor(t *testing.T) {
+	eth := dia.Asset{Symbol: "ETH", Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000"}
+	btc := dia.Asset{Symbol: "BTC", Blockchain: dia.BITCOIN, Address: "0x0000000000000000000000000000000000000000"}
+	filterConfigs := []dia.FilterConfig{
+		{Name: "MAIR", Memory: 120},
+		{Name: "MEDIR", Exchange: dia.KrakenExchange, Memory: 120},
+		{Name: "VWAPIR", Blockchain: eth.Blockchain, Address: eth.Address, Memory: 120},
+		{Name: "VWAP", Blockchain: eth.Blockchain, Address: eth.Address, Exchange: dia.BinanceExchange, Memory: 120},
+	}
+	cases := []struct {
+		asset    dia.Asset
+		exchange string
+		names    []string
+	}{
+		{btc, "", []string{"MAIR"}},
+		{btc, dia.BinanceExchange, []string{"MAIR"}},
+		{btc, dia.KrakenExchange, []string{"MAIR", "MEDIR"}},
+		{eth, "", []string{"MAIR", "VWAPIR"}},
+		{eth, dia.KrakenExchange, []string{"MAIR", "MEDIR", "VWAPIR"}},
+		{eth, dia.BinanceExchange, []string{"MAIR", "VWAPIR", "VWAP"}},
+	}
+	for i, c := range cases {
+		configs := filterConfigsFor(filterConfigs, c.asset, c.exchange)
+		var names []string
+		for _, fc := range configs {
+			names = append(names, fc.Name)
+		}
+		if strings.Join(names, ",") != strings.Join(c.names, ",") {
+			t.Errorf("case %d: expected filters %v, got %v", i, c.names, names)
+		}
+	}
+
+	// A scoped config replaces the global config with the same name.
+	filterConfigs = append(filterConfigs, dia.FilterConfig{Name: "MAIR", Blockchain: eth.Blockchain, Address: eth.Address, Memory: 60})
+	configs := filterConfigsFor(filterConfigs, eth, "")
+	if len(configs) != 2 || configs[0].Name != "MAIR" || configs[0].Memory != 60 {
+		t.Errorf("expected scoped MAIR config to replace the global one, got %v", configs)
+	}
+}
+
+func TestNewFilter(t *testing.T) {
+	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
+	for _, name := range RegisteredFilters() {
+		if f := newFilter(dia.FilterConfig{Name: name}, dia.Asset{}, "", d, filterDependencies{}); f == nil {
+			t.Errorf("registered filter %s could not be instantiated", name)
+		}
+	}
+	if f := newFilter(dia.FilterConfig{Name: "UNKNOWN"}, dia.Asset{}, "", d, filterDependencies{}); f != nil {
+		t.Errorf("expected nil for unregistered filter, got %v", f)
+	}
+	f := newFilter(dia.FilterConfig{Name: "MEDIR", OutlierScale: 3}, dia.Asset{}, "", d, filterDependencies{})
+	if f.(*FilterMEDIR).scale != 3 {
+		t.Errorf("outlier scale not applied")
+	}
+}
+
+func TestFilterMinTrades(t *testing.T) {
+	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
+	f := newFilter(dia.FilterConfig{Name: "VWAP", Memory: 120, MinTrades: 3}, dia.Asset{}, dia.BinanceExchange, d, filterDependencies{})
+
+	for i := 0; i < 2; i++ {
+		f.compute(dia.Trade{EstimatedUSDPrice: 10, Volume: 1, Time: d})
+		d = d.Add(time.Second)
+	}
+	f.finalCompute(d)
+	if fp := f.filterPointForBlock(); fp != nil {
+		t.Errorf("expected no filter point below minimum number of trades, got %v", fp)
+	}
+
+	for i := 0; i < 3; i++ {
+		f.compute(dia.Trade{EstimatedUSDPrice: 10, Volume: 1, Time: d})
+		d = d.Add(time.Second)
+	}
+	f.finalCompute(d)
+	fp := f.filterPointForBlock()
+	if fp == nil || fp.Value != 10 {
+		t.Errorf("expected filter point with value 10, got %v", fp)
+	}
+}
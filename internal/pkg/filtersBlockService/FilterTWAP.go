@@ -0,0 +1,126 @@
+package filters
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+// FilterTWAP implements a time weighted average price.
+// Each trade's price is weighted by the time until the next trade, or until the end
+// of the tradesBlock for the last trade. The last price of a block is carried over
+// and weighted from the beginning of the next block until its first trade.
+type FilterTWAP struct {
+	asset       dia.Asset
+	exchange    string
+	currentTime time.Time
+	// periodStart is the time since which lastPrice is the current price.
+	periodStart   time.Time
+	lastPrice     float64
+	lastTrade     dia.Trade
+	weightedPrice float64
+	totalSeconds  float64
+	param         int
+	value         float64
+	modified      bool
+	filterName    string
+}
+
+// NewFilterTWAP returns a FilterTWAP
+func NewFilterTWAP(asset dia.Asset, exchange string, currentTime time.Time, param int) *FilterTWAP {
+	return &FilterTWAP{
+		asset:       asset,
+		exchange:    exchange,
+		currentTime: currentTime,
+		periodStart: currentTime,
+		param:       param,
+		filterName:  "TWAP" + strconv.Itoa(param),
+	}
+}
+
+// Compute ...
+func (filter *FilterTWAP) Compute(trade dia.Trade) {
+	filter.compute(trade)
+}
+
+func (filter *FilterTWAP) compute(trade dia.Trade) {
+	filter.modified = true
+	if filter.lastTrade != (dia.Trade{}) {
+		if trade.Time.Before(filter.currentTime) {
+			log.Errorln("FilterTWAP: Ignoring Trade out of order ", filter.currentTime, trade.Time)
+			return
+		}
+		filter.addPeriod(trade.Time)
+	}
+	filter.lastPrice = trade.EstimatedUSDPrice
+	filter.periodStart = trade.Time
+	filter.currentTime = trade.Time
+	filter.lastTrade = trade
+}
+
+// addPeriod weights the last price with the time from the start of its period until @t.
+func (filter *FilterTWAP) addPeriod(t time.Time) {
+	seconds := t.Sub(filter.periodStart).Seconds()
+	if seconds <= 0 {
+		return
+	}
+	filter.weightedPrice += filter.lastPrice * seconds
+	filter.totalSeconds += seconds
+}
+
+// FinalCompute ...
+func (filter *FilterTWAP) FinalCompute(t time.Time) float64 {
+	return filter.finalCompute(t)
+}
+
+func (filter *FilterTWAP) finalCompute(t time.Time) float64 {
+	if filter.lastTrade == (dia.Trade{}) {
+		return 0.0
+	}
+	filter.addPeriod(t)
+	if filter.totalSeconds > 0 {
+		filter.value = filter.weightedPrice / filter.totalSeconds
+	} else {
+		filter.value = filter.lastPrice
+	}
+
+	// The last price is weighted from the end of this block on in the next block.
+	filter.weightedPrice = 0
+	filter.totalSeconds = 0
+	if t.After(filter.periodStart) {
+		filter.periodStart = t
+	}
+	return filter.value
+}
+
+// FilterPointForBlock ...
+func (filter *FilterTWAP) FilterPointForBlock() *dia.FilterPoint {
+	return filter.filterPointForBlock()
+}
+
+func (filter *FilterTWAP) filterPointForBlock() *dia.FilterPoint {
+	if filter.exchange != "" {
+		return nil
+	}
+	return &dia.FilterPoint{
+		Asset: filter.asset,
+		Value: filter.value,
+		Name:  filter.filterName,
+		Time:  filter.currentTime,
+	}
+}
+
+func (filter *FilterTWAP) save(ds models.Datastore) error {
+	if filter.modified {
+		filter.modified = false
+		err := ds.SetFilter(filter.filterName, filter.asset, filter.exchange, filter.value, filter.currentTime)
+		if err != nil {
+			log.Errorln("FilterTWAP: Error:", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package filters
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func TestFilterTWAP(t *testing.T) {
+	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
+	f := NewFilterTWAP(dia.Asset{Symbol: "XRP"}, "", d, dia.BlockSizeSeconds)
+
+	// Price 10 for 30s, 20 for 60s, 35 for 20s.
+	for _, trade := range []dia.Trade{
+		{EstimatedUSDPrice: 10, Volume: 100, Time: d.Add(10 * time.Second)},
+		{EstimatedUSDPrice: 20, Volume: 1, Time: d.Add(40 * time.Second)},
+		{EstimatedUSDPrice: 35, Volume: 1, Time: d.Add(100 * time.Second)},
+	} {
+		f.Compute(trade)
+	}
+	if value := f.FinalCompute(d.Add(120 * time.Second)); value != 20 {
+		t.Errorf("expected TWAP 20, got %v", value)
+	}
+	if fp := f.FilterPointForBlock(); fp.Name != "TWAP120" || fp.Value != 20 {
+		t.Errorf("unexpected filter point %v", fp)
+	}
+
+	// The last price is carried over into the next block.
+	f.Compute(dia.Trade{EstimatedUSDPrice: 10, Volume: 1, Time: d.Add(180 * time.Second)})
+	if value := f.FinalCompute(d.Add(240 * time.Second)); value != 22.5 {
+		t.Errorf("expected TWAP 22.5, got %v", value)
+	}
+
+	// Blocks without trades keep the last price.
+	if value := f.FinalCompute(d.Add(360 * time.Second)); value != 10 {
+		t.Errorf("expected TWAP 10, got %v", value)
+	}
+}
@@ -0,0 +1,271 @@
+package filters
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	models "github.com/diadata-org/diadata/pkg/model"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	// Exchange trust scores are read from config/filters/exchangeTrust.json.
+	configFileExchangeTrust = "filters/exchangeTrust"
+	// The activity of the exchanges in an asset is reloaded from the trades distributions after
+	// distributionTrustRefresh. Distributions older than distributionTrustLookback are ignored.
+	distributionTrustRefresh  = time.Hour
+	distributionTrustLookback = 48 * time.Hour
+)
+
+// ExchangeTrust returns a non-negative trust score for trades of @asset on @exchange.
+// Cross-exchange filters weight each exchange's trades by its trust score.
+type ExchangeTrust interface {
+	TrustScore(asset dia.Asset, exchange string) float64
+}
+
+// StaticExchangeTrust assigns fixed trust scores to exchanges.
+type StaticExchangeTrust struct {
+	DefaultTrust  float64              `json:"DefaultTrust"`
+	ExchangeTrust []ExchangeTrustScore `json:"ExchangeTrust"`
+	scores        map[string]float64
+}
+
+// ExchangeTrustScore is the trust score of a single exchange.
+type ExchangeTrustScore struct {
+	Exchange string  `json:"Exchange"`
+	Trust    float64 `json:"Trust"`
+}
+
+// NewStaticExchangeTrust returns trust scores given by @scores. Exchanges not contained
+// in @scores are assigned @defaultTrust.
+func NewStaticExchangeTrust(defaultTrust float64, scores []ExchangeTrustScore) *StaticExchangeTrust {
+	st := &StaticExchangeTrust{
+		DefaultTrust:  defaultTrust,
+		ExchangeTrust: scores,
+		scores:        make(map[string]float64),
+	}
+	for _, score := range scores {
+		st.scores[score.Exchange] = score.Trust
+	}
+	return st
+}
+
+// GetExchangeTrustFromConfig returns the trust scores from the config file.
+func GetExchangeTrustFromConfig() (*StaticExchangeTrust, error) {
+	content, err := configCollectors.ReadJSONFromConfig(configFileExchangeTrust)
+	if err != nil {
+		return nil, err
+	}
+	var trust StaticExchangeTrust
+	err = json.Unmarshal(content, &trust)
+	if err != nil {
+		return nil, err
+	}
+	return NewStaticExchangeTrust(trust.DefaultTrust, trust.ExchangeTrust), nil
+}
+
+// TrustScore implements ExchangeTrust.
+func (st *StaticExchangeTrust) TrustScore(asset dia.Asset, exchange string) float64 {
+	if score, ok := st.scores[exchange]; ok {
+		return score
+	}
+	return st.DefaultTrust
+}
+
+// DistributionExchangeTrust scales the trust scores of a base ExchangeTrust by the activity of each
+// exchange in an asset, which is the share of time bins in its latest trades distribution with at least
+// the distribution's threshold of trades. Exchanges trading an asset only sporadically thereby contribute
+// little to its price. Exchanges without trades distribution for an asset keep their base trust.
+type DistributionExchangeTrust struct {
+	base  ExchangeTrust
+	relDB models.RelDatastore
+
+	mu         sync.Mutex
+	activities map[string]exchangeActivities
+}
+
+// exchangeActivities are the activities of all exchanges in an asset, loaded at time updated.
+type exchangeActivities struct {
+	activity map[string]float64
+	updated  time.Time
+}
+
+// NewDistributionExchangeTrust returns trust scores of @base scaled by the activity of the exchanges
+// according to the trades distributions per exchange in @relDB. A nil @base assigns trust 1 to all exchanges.
+func NewDistributionExchangeTrust(base ExchangeTrust, relDB models.RelDatastore) *DistributionExchangeTrust {
+	if base == nil {
+		base = NewStaticExchangeTrust(1, nil)
+	}
+	return &DistributionExchangeTrust{
+		base:       base,
+		relDB:      relDB,
+		activities: make(map[string]exchangeActivities),
+	}
+}
+
+// TrustScore implements ExchangeTrust.
+func (dt *DistributionExchangeTrust) TrustScore(asset dia.Asset, exchange string) float64 {
+	score := dt.base.TrustScore(asset, exchange)
+	if activity, ok := dt.exchangeActivity(asset)[exchange]; ok {
+		return score * activity
+	}
+	return score
+}
+
+// exchangeActivity returns the activity of all exchanges with a trades distribution for @asset.
+func (dt *DistributionExchangeTrust) exchangeActivity(asset dia.Asset) map[string]float64 {
+	dt.mu.Lock()
+	defer dt.mu.Unlock()
+	key := getIdentifier(asset)
+	if cached, ok := dt.activities[key]; ok && time.Since(cached.updated) < distributionTrustRefresh {
+		return cached.activity
+	}
+
+	// Failed loads are retried after the refresh interval as well, such that postgres is not queried for every trade.
+	now := time.Now()
+	activity := make(map[string]float64)
+	distributions, err := dt.relDB.GetTradesDistributionPerExchange(asset, now.Add(-distributionTrustLookback), now)
+	if err != nil {
+		log.Errorf("get trades distributions of %s: %v", key, err)
+	}
+	// Distributions are ordered by descending time, so the first one of each exchange is the latest.
+	for _, distribution := range distributions {
+		if _, ok := activity[distribution.Exchange]; !ok {
+			activity[distribution.Exchange] = distributionActivity(distribution)
+		}
+	}
+	dt.activities[key] = exchangeActivities{activity: activity, updated: now}
+	return activity
+}
+
+// distributionActivity returns the share of time bins of @distribution with at least its threshold of trades.
+func distributionActivity(distribution dia.TradesDistribution) float64 {
+	if distribution.SizeBinSeconds <= 0 || distribution.TimeRangeSeconds <= 0 {
+		return 1
+	}
+	numBins := math.Ceil(float64(distribution.TimeRangeSeconds) / float64(distribution.SizeBinSeconds))
+	return math.Min(math.Max(1-float64(distribution.NumLowBins)/numBins, 0), 1)
+}
+
+// exchangeSample is a price sample together with its volume and the exchange it was traded on.
+type exchangeSample struct {
+	price    float64
+	volume   float64
+	exchange string
+}
+
+// computeTrustWeightedMean returns the mean of @prices weighted by the absolute value of @volumes times the trust
+// score of the corresponding exchange in @exchanges. Outliers are removed using the interquartile range scaled
+// by @scale. If @maxShare is positive, no single exchange contributes more than @maxShare of the total weight.
+// A nil @trust assigns the same trust to all exchanges.
+func computeTrustWeightedMean(asset dia.Asset, prices []float64, volumes []float64, exchanges []string, scale float64, trust ExchangeTrust, maxShare float64) (float64, error) {
+	if len(prices) != len(volumes) || len(prices) != len(exchanges) {
+		return 0, errors.New("computeTrustWeightedMean: prices, volumes and exchanges not of same size")
+	}
+	samples := make([]exchangeSample, len(prices))
+	for i := range prices {
+		samples[i] = exchangeSample{price: prices[i], volume: math.Abs(volumes[i]), exchange: exchanges[i]}
+	}
+	samples = removeSampleOutliers(samples, scale)
+
+	exchangeWeights := make(map[string]float64)
+	weights := make([]float64, len(samples))
+	for i, sample := range samples {
+		weights[i] = sample.volume
+		if trust != nil {
+			weights[i] *= math.Max(trust.TrustScore(asset, sample.exchange), 0)
+		}
+		exchangeWeights[sample.exchange] += weights[i]
+	}
+	factors := capShares(exchangeWeights, maxShare)
+
+	var total, totalWeight float64
+	for i, sample := range samples {
+		w := weights[i] * factors[sample.exchange]
+		total += sample.price * w
+		totalWeight += w
+	}
+	if totalWeight == 0 {
+		return 0, errors.New("computeTrustWeightedMean: total weight is zero")
+	}
+	return total / totalWeight, nil
+}
+
+// removeSampleOutliers removes samples with prices outside the interquartile range scaled by @scale.
+// In contrast to removeOutliersScaled, volumes and exchanges are kept aligned with their prices.
+func removeSampleOutliers(samples []exchangeSample, scale float64) []exchangeSample {
+	if len(samples) < 2 {
+		return samples
+	}
+	sort.SliceStable(samples, func(i, j int) bool { return samples[i].price < samples[j].price })
+	prices := make([]float64, len(samples))
+	for i, sample := range samples {
+		prices[i] = sample.price
+	}
+	Q1, Q3 := computeQuartiles(prices)
+	IQR := Q3 - Q1
+	lowerBound := Q1 - scale*IQR
+	upperBound := Q3 + scale*IQR
+	cleanSamples := []exchangeSample{}
+	for _, sample := range samples {
+		if sample.price >= lowerBound && sample.price <= upperBound {
+			cleanSamples = append(cleanSamples, sample)
+		}
+	}
+	return cleanSamples
+}
+
+// capShares returns a factor per exchange such that the weight of no exchange in @weights exceeds
+// @maxShare of the total weight after multiplication by its factor. Capped exchanges end up with exactly
+// @maxShare of the total weight. If the cap cannot be met because there are too few exchanges, all exchanges
+// are weighted equally.
+func capShares(weights map[string]float64, maxShare float64) map[string]float64 {
+	factors := make(map[string]float64)
+	exchanges := []string{}
+	for exchange, weight := range weights {
+		factors[exchange] = 1
+		if weight > 0 {
+			exchanges = append(exchanges, exchange)
+		}
+	}
+	if maxShare <= 0 || maxShare >= 1 || len(exchanges) == 0 {
+		return factors
+	}
+	sort.Slice(exchanges, func(i, j int) bool { return weights[exchanges[i]] > weights[exchanges[j]] })
+
+	if float64(len(exchanges))*maxShare <= 1 {
+		return equalShares(factors, weights, exchanges)
+	}
+
+	// Find the number k of capped exchanges. Each capped exchange contributes capWeight such that
+	// capWeight = maxShare * (k*capWeight + uncappedWeight).
+	var uncappedWeight float64
+	for _, exchange := range exchanges {
+		uncappedWeight += weights[exchange]
+	}
+	for k := 0; k < len(exchanges) && float64(k)*maxShare < 1; k++ {
+		if weights[exchanges[k]] <= maxShare*uncappedWeight/(1-float64(k)*maxShare) {
+			capWeight := maxShare * uncappedWeight / (1 - float64(k)*maxShare)
+			for _, exchange := range exchanges[:k] {
+				factors[exchange] = capWeight / weights[exchange]
+			}
+			return factors
+		}
+		uncappedWeight -= weights[exchanges[k]]
+	}
+	return equalShares(factors, weights, exchanges)
+}
+
+// equalShares sets @factors such that all @exchanges have the same weight.
+func equalShares(factors map[string]float64, weights map[string]float64, exchanges []string) map[string]float64 {
+	for _, exchange := range exchanges {
+		factors[exchange] = 1 / weights[exchange]
+	}
+	return factors
+}
@@ -0,0 +1,134 @@
+package filters
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestCapShares(t *testing.T) {
+	cases := []struct {
+		weights  map[string]float64
+		maxShare float64
+		shares   map[string]float64
+	}{
+		{map[string]float64{"A": 80, "B": 10, "C": 10}, 0.5, map[string]float64{"A": 0.5, "B": 0.25, "C": 0.25}},
+		{map[string]float64{"A": 60, "B": 30, "C": 10}, 0.4, map[string]float64{"A": 0.4, "B": 0.4, "C": 0.2}},
+		{map[string]float64{"A": 40, "B": 30, "C": 30}, 0.5, map[string]float64{"A": 0.4, "B": 0.3, "C": 0.3}},
+		// Cap cannot be met with two exchanges.
+		{map[string]float64{"A": 90, "B": 10}, 0.3, map[string]float64{"A": 0.5, "B": 0.5}},
+	}
+	for i, c := range cases {
+		factors := capShares(c.weights, c.maxShare)
+		var total float64
+		for exchange, weight := range c.weights {
+			total += weight * factors[exchange]
+		}
+		for exchange, share := range c.shares {
+			if got := c.weights[exchange] * factors[exchange] / total; math.Abs(got-share) > 1e-9 {
+				t.Errorf("case %d: expected share %v for %s, got %v", i, share, exchange, got)
+			}
+		}
+	}
+}
+
+func TestFilterVWAPIRTrustWeighted(t *testing.T) {
+	d := time.Date(2016, time.August, 15, 0, 0, 0, 0, time.UTC)
+	trust := NewStaticExchangeTrust(0, []ExchangeTrustScore{
+		{Exchange: dia.KrakenExchange, Trust: 1},
+		{Exchange: dia.BinanceExchange, Trust: 1},
+		{Exchange: dia.UniswapExchange, Trust: 0.5},
+	})
+	trades := []dia.Trade{
+		{EstimatedUSDPrice: 100, Volume: 1, Source: dia.KrakenExchange, Time: d},
+		{EstimatedUSDPrice: 102, Volume: 1, Source: dia.BinanceExchange, Time: d.Add(time.Second)},
+		// Thin pool dominating by volume.
+		{EstimatedUSDPrice: 104, Volume: 18, Source: dia.UniswapExchange, Time: d.Add(2 * time.Second)},
+		// Untrusted exchange is ignored.
+		{EstimatedUSDPrice: 103, Volume: 100, Source: dia.BitfinexExchange, Time: d.Add(3 * time.Second)},
+	}
+	cases := []struct {
+		trust    ExchangeTrust
+		maxShare float64
+		expected float64
+	}{
+		// Uniswap has weight 9 out of 11.
+		{trust, 0, 103.45454545454545},
+		// Uniswap is capped at 50%.
+		{trust, 0.5, 102.5},
+	}
+	for i, c := range cases {
+		f := newFilter(dia.FilterConfig{Name: "VWAPIR", OutlierScale: 10, TrustWeighted: true, MaxVolumeShare: c.maxShare}, dia.Asset{}, "", d, filterDependencies{trust: c.trust})
+		for _, trade := range trades {
+			f.compute(trade)
+		}
+		if value := f.finalCompute(d); math.Abs(value-c.expected) > 1e-9 {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, value)
+		}
+	}
+
+	// Exchange filters are not weighted.
+	f := NewFilterVWAPIR(dia.Asset{}, dia.UniswapExchange, d, dia.BlockSizeSeconds)
+	f.trust = trust
+	if f.weighted() {
+		t.Error("exchange filter must not be weighted")
+	}
+}
+
+func TestDistributionExchangeTrust(t *testing.T) {
+	asset := dia.Asset{Symbol: "ETH", Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.ETHEREUM}
+	now := time.Now()
+	distribution := func(exchange string, numLowBins int, timestamp time.Time) dia.TradesDistribution {
+		return dia.TradesDistribution{
+			Asset:            asset,
+			Exchange:         exchange,
+			NumLowBins:       numLowBins,
+			Threshold:        2,
+			SizeBinSeconds:   120,
+			TimeRangeSeconds: 86400,
+			Timestamp:        timestamp,
+		}
+	}
+	relDB := models.NewMemoryRelDataStore()
+	for _, td := range []dia.TradesDistribution{
+		// Distribution of the trades on all exchanges.
+		distribution("", 720, now.Add(-time.Hour)),
+		distribution(dia.KrakenExchange, 0, now.Add(-time.Hour)),
+		distribution(dia.UniswapExchange, 0, now.Add(-25*time.Hour)),
+		// Uniswap had less than 2 trades in three quarters of the 720 bins of the last day.
+		distribution(dia.UniswapExchange, 540, now.Add(-time.Hour)),
+		distribution(dia.BinanceExchange, 0, now.Add(-72*time.Hour)),
+	} {
+		if err := relDB.SetTradesDistribution(td); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	trust := NewDistributionExchangeTrust(NewStaticExchangeTrust(1, []ExchangeTrustScore{{Exchange: dia.UniswapExchange, Trust: 0.8}}), relDB)
+	cases := []struct {
+		exchange string
+		score    float64
+	}{
+		{dia.KrakenExchange, 1},
+		{dia.UniswapExchange, 0.2},
+		// Exchanges without recent distribution keep their base trust.
+		{dia.BinanceExchange, 1},
+		{dia.BitfinexExchange, 1},
+	}
+	for _, c := range cases {
+		if score := trust.TrustScore(asset, c.exchange); math.Abs(score-c.score) > 1e-9 {
+			t.Errorf("expected trust %v for %s, got %v", c.score, c.exchange, score)
+		}
+	}
+
+	// Activities are cached until the refresh.
+	if err := relDB.SetTradesDistribution(distribution(dia.KrakenExchange, 720, now)); err != nil {
+		t.Fatal(err)
+	}
+	if score := trust.TrustScore(asset, dia.KrakenExchange); score != 1 {
+		t.Errorf("expected cached trust 1 for %s, got %v", dia.KrakenExchange, score)
+	}
+}
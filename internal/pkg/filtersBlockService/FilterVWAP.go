@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -96,6 +97,10 @@ func (s *FilterVWAP) finalCompute(t time.Time) float64 {
 		s.value = totalPriceVolume / totalVolume
 	}
 
+	// Trades are only weighted within a tradesBlock.
+	s.prices = []float64{}
+	s.volumes = []float64{}
+
 	return s.value
 }
 
@@ -120,3 +125,15 @@ func (s *FilterVWAP) filterPointForBlock() *dia.FilterPoint {
 		}
 	}
 }
+
+func (s *FilterVWAP) save(ds models.Datastore) error {
+	if s.modified {
+		s.modified = false
+		err := ds.SetFilter(s.filterName, s.asset, s.exchange, s.value, s.currentTime)
+		if err != nil {
+			log.Errorln("FilterVWAP: Error:", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -6,10 +6,12 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
 	log "github.com/sirupsen/logrus"
 )
 
-// FilterVWAP ...
+// FilterVWAPIR implements a volume weighted average price.
+// Outliers are eliminated using interquartile range.
 type FilterVWAPIR struct {
 	exchange    string
 	currentTime time.Time
@@ -21,9 +23,14 @@ type FilterVWAPIR struct {
 	modified    bool
 	filterName  string
 	asset       dia.Asset
+	scale       float64
+	// exchanges of the data points, only recorded for weighted cross-exchange filters
+	exchanges      []string
+	trust          ExchangeTrust
+	maxVolumeShare float64
 }
 
-// NewFilterVWAP ...
+// NewFilterVWAPIR returns a FilterVWAPIR
 func NewFilterVWAPIR(asset dia.Asset, exchange string, currentTime time.Time, param int) *FilterVWAPIR {
 	s := &FilterVWAPIR{
 		asset:       asset,
@@ -33,6 +40,7 @@ func NewFilterVWAPIR(asset dia.Asset, exchange string, currentTime time.Time, pa
 		currentTime: currentTime,
 		param:       param,
 		filterName:  "VWAP" + strconv.Itoa(param),
+		scale:       1.5,
 	}
 	return s
 }
@@ -46,7 +54,7 @@ func (filter *FilterVWAPIR) compute(trade dia.Trade) {
 	filter.modified = true
 	if filter.lastTrade != (dia.Trade{}) {
 		if trade.Time.Before(filter.currentTime) {
-			log.Errorln("FilterVWAP: Ignoring Trade out of order ", filter.currentTime, trade.Time)
+			log.Errorln("FilterVWAPIR: Ignoring Trade out of order ", filter.currentTime, trade.Time)
 			return
 		}
 	}
@@ -64,6 +72,15 @@ func (filter *FilterVWAPIR) fill(trade dia.Trade) {
 func (filter *FilterVWAPIR) processDataPoint(trade dia.Trade) {
 	filter.prices = append([]float64{trade.EstimatedUSDPrice}, filter.prices...)
 	filter.volumes = append([]float64{trade.Volume}, filter.volumes...)
+	if filter.weighted() {
+		filter.exchanges = append([]string{trade.Source}, filter.exchanges...)
+	}
+}
+
+// weighted returns true if the filter weights exchanges by trust and volume share.
+// Only cross-exchange filters are weighted.
+func (filter *FilterVWAPIR) weighted() bool {
+	return filter.exchange == "" && (filter.trust != nil || filter.maxVolumeShare > 0)
 }
 
 // FinalCompute ...
@@ -77,13 +94,24 @@ func (s *FilterVWAPIR) finalCompute(t time.Time) float64 {
 	}
 
 	// s.processDataPoint(*s.lastTrade)
-	cleanPrices, bounds := removeOutliers(s.prices)
+	if s.weighted() {
+		value, err := computeTrustWeightedMean(s.asset, s.prices, s.volumes, s.exchanges, s.scale, s.trust, s.maxVolumeShare)
+		if err == nil {
+			s.value = value
+		}
+		s.prices = []float64{}
+		s.volumes = []float64{}
+		s.exchanges = []string{}
+		return s.value
+	}
+
+	cleanPrices, bounds := removeOutliersScaled(s.prices, s.scale)
 
 	priceVolume := []float64{}
 
-	// TODO handle bounds
+	// No bounds are returned for less than two samples.
 	if len(bounds) == 0 {
-		return 0.0
+		bounds = []int{0, len(cleanPrices)}
 	}
 
 	cleanedVolumes := s.volumes[bounds[0]:bounds[1]]
@@ -103,7 +131,13 @@ func (s *FilterVWAPIR) finalCompute(t time.Time) float64 {
 		total += v
 	}
 
-	s.value = total / totalVolume
+	if totalVolume > 0 {
+		s.value = total / totalVolume
+	}
+
+	// Trades are only weighted within a tradesBlock.
+	s.prices = []float64{}
+	s.volumes = []float64{}
 
 	return s.value
 }
@@ -129,3 +163,15 @@ func (s *FilterVWAPIR) filterPointForBlock() *dia.FilterPoint {
 		}
 	}
 }
+
+func (s *FilterVWAPIR) save(ds models.Datastore) error {
+	if s.modified {
+		s.modified = false
+		err := ds.SetFilter(s.filterName, s.asset, s.exchange, s.value, s.currentTime)
+		if err != nil {
+			log.Errorln("FilterVWAPIR: Error:", err)
+		}
+		return err
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package filters
 
 import (
 	"errors"
+	"sort"
 	"sync"
 	"time"
 
@@ -34,12 +35,18 @@ type filtersAsset struct {
 	Source     string
 }
 
+// ackedTradesBlock is a tradesBlock together with the function acknowledging its processing.
+type ackedTradesBlock struct {
+	block *dia.TradesBlock
+	ack   func()
+}
+
 // FiltersBlockService is the data structure containing all objects
 // necessary for the processing of a tradesBlock.
 type FiltersBlockService struct {
 	shutdown         chan nothing
 	shutdownDone     chan nothing
-	chanTradesBlock  chan *dia.TradesBlock
+	chanTradesBlock  chan ackedTradesBlock
 	chanFiltersBlock chan *dia.FiltersBlock
 	errorLock        sync.RWMutex
 	error            error
@@ -51,15 +58,29 @@ type FiltersBlockService struct {
 	calculationValues    []int
 	previousBlockFilters []dia.FilterPoint
 	datastore            models.Datastore
+	filterConfigs        []dia.FilterConfig
+	filterDependencies   filterDependencies
 }
 
-// NewFiltersBlockService returns a new FiltersBlockService and
+// NewFiltersBlockService returns a new FiltersBlockService running the default filters and
 // runs mainLoop() in a go routine.
 func NewFiltersBlockService(previousBlockFilters []dia.FilterPoint, datastore models.Datastore, chanFiltersBlock chan *dia.FiltersBlock) *FiltersBlockService {
+	return NewFiltersBlockServiceWithConfig(previousBlockFilters, datastore, chanFiltersBlock, DefaultFilterConfigs())
+}
+
+// NewFiltersBlockServiceWithConfig returns a new FiltersBlockService running the filters
+// given by @filterConfigs and runs mainLoop() in a go routine.
+func NewFiltersBlockServiceWithConfig(previousBlockFilters []dia.FilterPoint, datastore models.Datastore, chanFiltersBlock chan *dia.FiltersBlock, filterConfigs []dia.FilterConfig) *FiltersBlockService {
+	return NewFiltersBlockServiceWithTrust(previousBlockFilters, datastore, chanFiltersBlock, filterConfigs, nil)
+}
+
+// NewFiltersBlockServiceWithTrust returns a new FiltersBlockService running the filters given by @filterConfigs.
+// Cross-exchange filters with trust weighting enabled weight exchanges by @exchangeTrust, which may be nil.
+func NewFiltersBlockServiceWithTrust(previousBlockFilters []dia.FilterPoint, datastore models.Datastore, chanFiltersBlock chan *dia.FiltersBlock, filterConfigs []dia.FilterConfig, exchangeTrust ExchangeTrust) *FiltersBlockService {
 	s := &FiltersBlockService{
 		shutdown:             make(chan nothing),
 		shutdownDone:         make(chan nothing),
-		chanTradesBlock:      make(chan *dia.TradesBlock),
+		chanTradesBlock:      make(chan ackedTradesBlock),
 		chanFiltersBlock:     chanFiltersBlock,
 		error:                nil,
 		started:              false,
@@ -68,6 +89,11 @@ func NewFiltersBlockService(previousBlockFilters []dia.FilterPoint, datastore mo
 		calculationValues:    make([]int, 0),
 		previousBlockFilters: previousBlockFilters,
 		datastore:            datastore,
+		filterConfigs:        filterConfigs,
+		filterDependencies: filterDependencies{
+			trust:         exchangeTrust,
+			poolLiquidity: NewPoolLiquidity(datastore),
+		},
 	}
 	s.calculationValues = append(s.calculationValues, dia.BlockSizeSeconds)
 
@@ -86,7 +112,12 @@ func (s *FiltersBlockService) mainLoop() {
 			return
 		case tb, ok := <-s.chanTradesBlock:
 			log.Info("receive tradesBlock for further processing ok: ", ok)
-			s.processTradesBlock(tb)
+			if tb.block != nil {
+				s.processTradesBlock(tb.block)
+			}
+			if tb.ack != nil {
+				tb.ack()
+			}
 		}
 	}
 }
@@ -121,8 +152,10 @@ func (s *FiltersBlockService) processTradesBlock(tb *dia.TradesBlock) {
 		}
 	}
 	log.Info("time spent for final compute: ", time.Since(t0))
+	s.filterDependencies.poolLiquidity.clearCache()
 
-	resultFilters = addMissingPoints(s.previousBlockFilters, resultFilters)
+	resultFilters = addMissingPoints(s.previousBlockFilters, resultFilters, tb.TradesBlockData.EndTime)
+	sortFilterPoints(resultFilters)
 
 	s.previousBlockFilters = resultFilters
 
@@ -176,6 +209,8 @@ func (s *FiltersBlockService) processTradesBlock(tb *dia.TradesBlock) {
 
 }
 
+// createFilters instantiates the filters configured for @asset on @exchange
+// unless they already exist.
 func (s *FiltersBlockService) createFilters(asset dia.Asset, exchange string, BeginTime time.Time) {
 	fa := filtersAsset{
 		Identifier: getIdentifier(asset),
@@ -183,13 +218,14 @@ func (s *FiltersBlockService) createFilters(asset dia.Asset, exchange string, Be
 	}
 	_, ok := s.filters[fa]
 	if !ok {
-		s.filters[fa] = []Filter{
-			NewFilterMA(asset, exchange, BeginTime, dia.BlockSizeSeconds),
-			// NewFilterTLT(asset, exchange),
-			NewFilterVOL(asset, exchange, dia.BlockSizeSeconds),
-			NewFilterMAIR(asset, exchange, BeginTime, dia.BlockSizeSeconds),
-			NewFilterMEDIR(asset, exchange, BeginTime, dia.BlockSizeSeconds),
+		filters := []Filter{}
+		for _, filterConfig := range filterConfigsFor(s.filterConfigs, asset, exchange) {
+			f := newFilter(filterConfig, asset, exchange, BeginTime, s.filterDependencies)
+			if f != nil {
+				filters = append(filters, f)
+			}
 		}
+		s.filters[fa] = filters
 	}
 }
 
@@ -203,7 +239,9 @@ func (s *FiltersBlockService) computeFilters(t dia.Trade, exchange string) {
 	}
 }
 
-func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.FilterPoint) []dia.FilterPoint {
+// addMissingPoints adds filter points from the previous block which are older than 24h
+// with respect to @blockTime and not contained in @newFilters.
+func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.FilterPoint, blockTime time.Time) []dia.FilterPoint {
 	log.Debug("previousBlockFilters", previousBlockFilters)
 	log.Debug("newFilters:", newFilters)
 	missingPoints := 0
@@ -219,7 +257,7 @@ func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.F
 
 	for _, filter := range previousBlockFilters {
 
-		d := time.Since(filter.Time)
+		d := blockTime.Sub(filter.Time)
 		// log.Info("filter:", filter, " age:", d)
 		fa := filtersAsset{
 			Identifier: getIdentifier(filter.Asset),
@@ -243,12 +281,33 @@ func addMissingPoints(previousBlockFilters []dia.FilterPoint, newFilters []dia.F
 	return result
 }
 
+// sortFilterPoints sorts @filterPoints by asset and filter name, such that the hash
+// of a filtersBlock does not depend on the iteration order of the filters map.
+func sortFilterPoints(filterPoints []dia.FilterPoint) {
+	sort.SliceStable(filterPoints, func(i, j int) bool {
+		if filterPoints[i].Asset.Blockchain != filterPoints[j].Asset.Blockchain {
+			return filterPoints[i].Asset.Blockchain < filterPoints[j].Asset.Blockchain
+		}
+		if filterPoints[i].Asset.Address != filterPoints[j].Asset.Address {
+			return filterPoints[i].Asset.Address < filterPoints[j].Asset.Address
+		}
+		return filterPoints[i].Name < filterPoints[j].Name
+	})
+}
+
 // ProcessTradesBlock sends a filled tradesBlock into the filtersBlock channel.
 func (s *FiltersBlockService) ProcessTradesBlock(tradesBlock *dia.TradesBlock) {
-	s.chanTradesBlock <- tradesBlock
+	s.chanTradesBlock <- ackedTradesBlock{block: tradesBlock}
 	log.Info("Processing TradesBlock done.")
 }
 
+// ProcessTradesBlockWithAck processes @tradesBlock like ProcessTradesBlock. @ack is called once its
+// filtersBlock, if any, is received from the filtersBlock channel and its filters are saved. A nil
+// @tradesBlock is not processed, but @ack is still called in order with the blocks sent before.
+func (s *FiltersBlockService) ProcessTradesBlockWithAck(tradesBlock *dia.TradesBlock, ack func()) {
+	s.chanTradesBlock <- ackedTradesBlock{block: tradesBlock, ack: ack}
+}
+
 // Close gracefully closes the Filtersblockservice
 func (s *FiltersBlockService) Close() error {
 	if s.closed {
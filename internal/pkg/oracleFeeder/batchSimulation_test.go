@@ -0,0 +1,379 @@
+package oracleFeeder
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	diaOracleServiceV3 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV3"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/core/vm"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+// evmAssembler assembles EVM bytecode with jump labels.
+type evmAssembler struct {
+	code   []byte
+	labels map[string]int
+	fixups map[int]string
+}
+
+func newEVMAssembler() *evmAssembler {
+	return &evmAssembler{labels: make(map[string]int), fixups: make(map[int]string)}
+}
+
+func (a *evmAssembler) op(ops ...vm.OpCode) {
+	for _, op := range ops {
+		a.code = append(a.code, byte(op))
+	}
+}
+
+// push pushes @data with the smallest PUSH opcode.
+func (a *evmAssembler) push(data []byte) {
+	if len(data) == 0 {
+		data = []byte{0}
+	}
+	a.code = append(a.code, byte(vm.PUSH1)+byte(len(data)-1))
+	a.code = append(a.code, data...)
+}
+
+func (a *evmAssembler) pushInt(v uint64) {
+	a.push(new(big.Int).SetUint64(v).Bytes())
+}
+
+func (a *evmAssembler) pushLabel(label string) {
+	a.code = append(a.code, byte(vm.PUSH2), 0, 0)
+	a.fixups[len(a.code)-2] = label
+}
+
+func (a *evmAssembler) label(label string) {
+	a.labels[label] = len(a.code)
+	a.op(vm.JUMPDEST)
+}
+
+func (a *evmAssembler) bytecode() []byte {
+	for pos, label := range a.fixups {
+		a.code[pos] = byte(a.labels[label] >> 8)
+		a.code[pos+1] = byte(a.labels[label])
+	}
+	return a.code
+}
+
+// batchOracleCode returns the bytecode of a test contract which behaves as DIAOracleV3 by delegating to the
+// DIAOracleV2 implementation at @implementation. setMultipleValues calls setValue of the implementation for each
+// key, all other calls are forwarded. The contract is assembled, as the DIAOracleV3 binding has no bytecode.
+// Memory layout: 0x00 keys offset, 0x20 values offset, 0x40 number of keys, 0x60 index,
+// 0x80 offset of the current key, 0xa0 length of the current key, 0xc0 setValue calldata.
+func batchOracleCode(implementation common.Address) ([]byte, error) {
+	v2ABI, err := abi.JSON(strings.NewReader(diaOracleServiceV2.DIAOracleV2ABI))
+	if err != nil {
+		return nil, err
+	}
+	v3ABI, err := abi.JSON(strings.NewReader(diaOracleServiceV3.DIAOracleV3ABI))
+	if err != nil {
+		return nil, err
+	}
+	a := newEVMAssembler()
+
+	a.pushInt(0)
+	a.op(vm.CALLDATALOAD)
+	a.pushInt(0xe0)
+	a.op(vm.SHR)
+	a.push(v3ABI.Methods["setMultipleValues"].ID)
+	a.op(vm.EQ)
+	a.pushLabel("batch")
+	a.op(vm.JUMPI)
+
+	// Forward all other calls to the implementation.
+	a.op(vm.CALLDATASIZE)
+	a.pushInt(0)
+	a.pushInt(0)
+	a.op(vm.CALLDATACOPY)
+	a.pushInt(0)
+	a.pushInt(0)
+	a.op(vm.CALLDATASIZE)
+	a.pushInt(0)
+	a.push(implementation.Bytes())
+	a.op(vm.GAS, vm.DELEGATECALL)
+	a.op(vm.RETURNDATASIZE)
+	a.pushInt(0)
+	a.pushInt(0)
+	a.op(vm.RETURNDATACOPY)
+	a.pushLabel("forwarded")
+	a.op(vm.JUMPI)
+	a.op(vm.RETURNDATASIZE)
+	a.pushInt(0)
+	a.op(vm.REVERT)
+	a.label("forwarded")
+	a.op(vm.RETURNDATASIZE)
+	a.pushInt(0)
+	a.op(vm.RETURN)
+
+	// setMultipleValues(string[] keys, uint256[] compressedValues)
+	a.label("batch")
+	a.pushInt(4)
+	a.pushInt(4)
+	a.op(vm.CALLDATALOAD, vm.ADD)
+	a.pushInt(0x00)
+	a.op(vm.MSTORE)
+	a.pushInt(4)
+	a.pushInt(0x24)
+	a.op(vm.CALLDATALOAD, vm.ADD)
+	a.pushInt(0x20)
+	a.op(vm.MSTORE)
+	a.pushInt(0x00)
+	a.op(vm.MLOAD, vm.CALLDATALOAD)
+	a.pushInt(0x40)
+	a.op(vm.MSTORE)
+	// require(keys.length == compressedValues.length)
+	a.pushInt(0x20)
+	a.op(vm.MLOAD, vm.CALLDATALOAD)
+	a.pushInt(0x40)
+	a.op(vm.MLOAD, vm.EQ)
+	a.pushLabel("loop")
+	a.op(vm.JUMPI)
+	a.pushInt(0)
+	a.op(vm.DUP1, vm.REVERT)
+
+	a.label("loop")
+	a.pushInt(0x40)
+	a.op(vm.MLOAD)
+	a.pushInt(0x60)
+	a.op(vm.MLOAD, vm.LT, vm.ISZERO)
+	a.pushLabel("done")
+	a.op(vm.JUMPI)
+	// Offsets of the strings are relative to the first word after the length of keys.
+	a.pushInt(0x60)
+	a.op(vm.MLOAD)
+	a.pushInt(5)
+	a.op(vm.SHL)
+	a.pushInt(0x20)
+	a.op(vm.ADD)
+	a.pushInt(0x00)
+	a.op(vm.MLOAD, vm.ADD, vm.CALLDATALOAD)
+	a.pushInt(0x20)
+	a.op(vm.ADD)
+	a.pushInt(0x00)
+	a.op(vm.MLOAD, vm.ADD)
+	a.pushInt(0x80)
+	a.op(vm.MSTORE)
+	a.pushInt(0x80)
+	a.op(vm.MLOAD, vm.CALLDATALOAD)
+	a.pushInt(0xa0)
+	a.op(vm.MSTORE)
+	// compressed value
+	a.pushInt(0x60)
+	a.op(vm.MLOAD)
+	a.pushInt(5)
+	a.op(vm.SHL)
+	a.pushInt(0x20)
+	a.op(vm.ADD)
+	a.pushInt(0x20)
+	a.op(vm.MLOAD, vm.ADD, vm.CALLDATALOAD)
+	// setValue(key, value, timestamp)
+	a.push(v2ABI.Methods["setValue"].ID)
+	a.pushInt(0xe0)
+	a.op(vm.SHL)
+	a.pushInt(0xc0)
+	a.op(vm.MSTORE)
+	a.pushInt(0x60)
+	a.pushInt(0xc4)
+	a.op(vm.MSTORE)
+	a.op(vm.DUP1)
+	a.pushInt(0x80)
+	a.op(vm.SHR)
+	a.pushInt(0xe4)
+	a.op(vm.MSTORE)
+	a.push(common.FromHex("0xffffffffffffffffffffffffffffffff"))
+	a.op(vm.AND)
+	a.pushInt(0x104)
+	a.op(vm.MSTORE)
+	a.pushInt(0xa0)
+	a.op(vm.MLOAD)
+	a.pushInt(0x124)
+	a.op(vm.MSTORE)
+	// Copy the key including its zero padding.
+	a.pushInt(0xa0)
+	a.op(vm.MLOAD)
+	a.pushInt(31)
+	a.op(vm.ADD)
+	a.pushInt(31)
+	a.op(vm.NOT, vm.AND)
+	a.op(vm.DUP1)
+	a.pushInt(0x80)
+	a.op(vm.MLOAD)
+	a.pushInt(0x20)
+	a.op(vm.ADD)
+	a.pushInt(0x144)
+	a.op(vm.CALLDATACOPY)
+	a.pushInt(0x84)
+	a.op(vm.ADD)
+	a.pushInt(0)
+	a.pushInt(0)
+	a.op(vm.DUP3)
+	a.pushInt(0xc0)
+	a.push(implementation.Bytes())
+	a.op(vm.GAS, vm.DELEGATECALL)
+	a.pushLabel("next")
+	a.op(vm.JUMPI)
+	a.op(vm.RETURNDATASIZE)
+	a.pushInt(0)
+	a.pushInt(0)
+	a.op(vm.RETURNDATACOPY)
+	a.op(vm.RETURNDATASIZE)
+	a.pushInt(0)
+	a.op(vm.REVERT)
+	a.label("next")
+	a.op(vm.POP)
+	a.pushInt(0x60)
+	a.op(vm.MLOAD)
+	a.pushInt(1)
+	a.op(vm.ADD)
+	a.pushInt(0x60)
+	a.op(vm.MSTORE)
+	a.pushLabel("loop")
+	a.op(vm.JUMP)
+
+	a.label("done")
+	a.op(vm.STOP)
+	return a.bytecode(), nil
+}
+
+func TestFeederBatchUpdateSimulated(t *testing.T) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, params.AllEthashProtocolChanges.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := crypto.GenerateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherAuth, err := bind.NewKeyedTransactorWithChainID(other, params.AllEthashProtocolChanges.ChainID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The DIAOracleV2 implementation is the first contract deployed by auth.
+	implementation := crypto.CreateAddress(auth.From, 0)
+	code, err := batchOracleCode(implementation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	oracleAddress := common.HexToAddress("0x00000000000000000000000000000000000000d1")
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{
+		auth.From:      {Balance: balance},
+		otherAuth.From: {Balance: balance},
+		// Storage slot 1 holds the oracle updater.
+		oracleAddress: {Balance: new(big.Int), Code: code, Storage: map[common.Hash]common.Hash{common.BigToHash(big.NewInt(1)): common.BytesToHash(auth.From.Bytes())}},
+	}, simulatedGasLimit)
+	defer backend.Close()
+	if _, _, _, err := diaOracleServiceV2.DeployDIAOracleV2(auth, backend); err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+	contract, err := diaOracleServiceV3.NewDIAOracleV3(oracleAddress, backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Unix(1651406400, 0)
+	series := map[string]map[int]float64{
+		"ETH": {0: 2000, 1: 2030, 2: 2030},
+		"BTC": {0: 30000, 1: 29600, 2: 29600},
+		"DIA": {0: 1.5, 1: 1.5, 2: 1.6},
+	}
+	source := SeriesSource(func(asset dia.OracleFeedAsset, timestamp time.Time) float64 {
+		return series[asset.Symbol][int(timestamp.Sub(t0)/time.Minute)]
+	})
+	feed := dia.OracleFeed{
+		Name:              "batch simulation",
+		Source:            dia.OracleSourceAssetQuotation,
+		DeviationPermille: 10,
+		HeartbeatSeconds:  3600,
+		BatchUpdates:      true,
+		Contract:          oracleAddress.Hex(),
+		NodeURL:           "simulated",
+		Assets: []dia.OracleFeedAsset{
+			{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Symbol: "ETH"},
+			{Blockchain: dia.BITCOIN, Address: "0x0000000000000000000000000000000000000000", Symbol: "BTC"},
+			{Blockchain: dia.ETHEREUM, Address: "0x84cA8bc7997272c7CfB4D0Cd3D55cd942B3c9419", Symbol: "DIA"},
+		},
+	}
+	if err := ValidateFeed(&feed); err != nil {
+		t.Fatal(err)
+	}
+	txManager := ethhelper.NewTxManager(newCommittingBackend(backend), auth, TxConfig(feed))
+	feeder := NewFeeder(feed, contract, txManager, source, nil)
+
+	steps := []struct {
+		minute   int
+		expected []string
+	}{
+		{0, []string{"BTC/USD", "DIA/USD", "ETH/USD"}},
+		{1, []string{"BTC/USD", "ETH/USD"}},
+		{2, []string{"DIA/USD"}},
+	}
+	for _, step := range steps {
+		now := t0.Add(time.Duration(step.minute) * time.Minute)
+		start := backend.Blockchain().CurrentBlock().NumberU64() + 1
+		feeder.Update(context.Background(), now)
+		head := backend.Blockchain().CurrentBlock().NumberU64()
+		if head != start {
+			t.Fatalf("minute %d: expected a single transaction, got %d", step.minute, head-start+1)
+		}
+		iterator, err := contract.FilterOracleUpdate(&bind.FilterOpts{Start: start, End: &head})
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for iterator.Next() {
+			event := iterator.Event
+			keys = append(keys, event.Key)
+			symbol := event.Key[:len(event.Key)-len("/USD")]
+			if value := unscaleValue(event.Value, defaultDecimals); value != series[symbol][step.minute] || event.Timestamp.Int64() != now.Unix() {
+				t.Errorf("minute %d: expected %v at %d for %s, got %v at %v", step.minute, series[symbol][step.minute], now.Unix(), event.Key, value, event.Timestamp)
+			}
+		}
+		if err := iterator.Error(); err != nil {
+			t.Fatal(err)
+		}
+		iterator.Close()
+		sort.Strings(keys)
+		if strings.Join(keys, ",") != strings.Join(step.expected, ",") {
+			t.Fatalf("minute %d: expected updates of %v, got %v", step.minute, step.expected, keys)
+		}
+	}
+
+	value, timestamp, err := contract.GetValue(&bind.CallOpts{}, "BTC/USD")
+	if err != nil || unscaleValue(value, defaultDecimals) != 29600 || timestamp.Int64() != t0.Add(time.Minute).Unix() {
+		t.Errorf("unexpected oracle value %v at %v (%v)", value, timestamp, err)
+	}
+
+	// Only the oracle updater may set values.
+	otherAuth.GasLimit = 1000000
+	tx, err := contract.SetMultipleValues(otherAuth, []string{"BTC/USD"}, []*big.Int{big.NewInt(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	backend.Commit()
+	receipt, err := backend.TransactionReceipt(context.Background(), tx.Hash())
+	if err != nil || receipt.Status != types.ReceiptStatusFailed {
+		t.Errorf("expected batch update of another sender to fail, got %v (%v)", receipt, err)
+	}
+}
@@ -0,0 +1,126 @@
+package oracleFeeder
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/params"
+	"github.com/ghodss/yaml"
+)
+
+const (
+	defaultKeyFormat        = "{symbol}/USD"
+	defaultDecimals         = 8
+	defaultFrequencySeconds = 120
+	defaultPrivateKeyEnv    = "PRIVATE_KEY"
+)
+
+// FeedsConfig is the content of a feeds file such as config/oracleFeeder/feeds.yaml.
+type FeedsConfig struct {
+	Feeds []dia.OracleFeed `json:"Feeds"`
+}
+
+// ReadFeedsFromFile returns the feeds of the YAML file at @path. References to environment
+// variables such as ${BLOCKCHAIN_NODE} are expanded before parsing.
+func ReadFeedsFromFile(path string) ([]dia.OracleFeed, error) {
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return []dia.OracleFeed{}, err
+	}
+	return ParseFeeds(content)
+}
+
+// ParseFeeds returns the feeds of the YAML or JSON encoded @content.
+func ParseFeeds(content []byte) ([]dia.OracleFeed, error) {
+	var config FeedsConfig
+	err := yaml.Unmarshal([]byte(os.ExpandEnv(string(content))), &config)
+	if err != nil {
+		return []dia.OracleFeed{}, err
+	}
+	return config.Feeds, nil
+}
+
+// ValidateFeed sets the defaults of all unset optional fields of @feed and checks whether it can be run.
+func ValidateFeed(feed *dia.OracleFeed) error {
+	if feed.Name == "" {
+		return errors.New("feed without name")
+	}
+	if feed.NodeURL == "" {
+		return fmt.Errorf("feed %s: no node url", feed.Name)
+	}
+	if !common.IsHexAddress(feed.Contract) {
+		return fmt.Errorf("feed %s: invalid contract address %q", feed.Name, feed.Contract)
+	}
+	switch feed.Source {
+	case dia.OracleSourceAssetQuotation, dia.OracleSourceNFTFloor, dia.OracleSourceIndex:
+	case dia.OracleSourceFilter:
+		if feed.Filter == "" {
+			return fmt.Errorf("feed %s: no filter for source %s", feed.Name, feed.Source)
+		}
+	default:
+		return fmt.Errorf("feed %s: unknown source %q", feed.Name, feed.Source)
+	}
+	if len(feed.Assets) == 0 {
+		return fmt.Errorf("feed %s: no assets", feed.Name)
+	}
+	if feed.DeviationPermille < 0 || feed.HeartbeatSeconds < 0 || feed.FrequencySeconds < 0 || feed.Decimals < 0 {
+		return fmt.Errorf("feed %s: negative update parameters", feed.Name)
+	}
+	if feed.MaxFeePerGasGwei < 0 || feed.MaxPriorityFeePerGasGwei < 0 || feed.ConfirmationTimeoutSeconds < 0 {
+		return fmt.Errorf("feed %s: negative transaction parameters", feed.Name)
+	}
+
+	if feed.ChainID == 0 {
+		feed.ChainID = 1
+	}
+	if feed.PrivateKeyEnv == "" {
+		feed.PrivateKeyEnv = defaultPrivateKeyEnv
+	}
+	if feed.KeyFormat == "" {
+		feed.KeyFormat = defaultKeyFormat
+	}
+	if feed.Decimals == 0 {
+		feed.Decimals = defaultDecimals
+	}
+	if feed.FrequencySeconds == 0 {
+		feed.FrequencySeconds = defaultFrequencySeconds
+	}
+	return nil
+}
+
+// TxConfig returns the config of the transaction manager sending the updates of @feed.
+func TxConfig(feed dia.OracleFeed) ethhelper.TxConfig {
+	config := ethhelper.DefaultTxConfig()
+	if feed.MaxFeePerGasGwei > 0 {
+		config.MaxFeePerGas = gweiToWei(feed.MaxFeePerGasGwei)
+	}
+	if feed.MaxPriorityFeePerGasGwei > 0 {
+		config.MaxPriorityFeePerGas = gweiToWei(feed.MaxPriorityFeePerGasGwei)
+	}
+	if feed.ConfirmationTimeoutSeconds > 0 {
+		config.ConfirmationTimeout = time.Duration(feed.ConfirmationTimeoutSeconds) * time.Second
+	}
+	return config
+}
+
+func gweiToWei(gwei float64) *big.Int {
+	wei, _ := new(big.Float).Mul(big.NewFloat(gwei), big.NewFloat(params.GWei)).Int(nil)
+	return wei
+}
+
+// oracleKey returns the key under which the value of @asset is stored in the oracle of @feed.
+func oracleKey(feed dia.OracleFeed, asset dia.OracleFeedAsset) string {
+	return strings.NewReplacer(
+		"{symbol}", asset.Symbol,
+		"{address}", asset.Address,
+		"{blockchain}", asset.Blockchain,
+	).Replace(feed.KeyFormat)
+}
@@ -0,0 +1,64 @@
+package oracleFeeder
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const (
+	// Time range before the update in which the latest filter point is looked up.
+	filterLookback = 24 * time.Hour
+	// Time range before the update in which the latest index value is looked up.
+	indexLookback = 7 * 24 * time.Hour
+	// The NFT floor is computed in windows of nftFloorWindow, going back at most nftFloorStepBackLimit windows.
+	nftFloorWindow        = 24 * time.Hour
+	nftFloorStepBackLimit = 40
+)
+
+// DatastoreSource reads values directly from the datastores backing the DIA API, such that feeders
+// do not depend on the public API and its caching layer.
+type DatastoreSource struct {
+	datastore models.Datastore
+	relDB     models.RelDatastore
+}
+
+// NewDatastoreSource returns a source reading values from @datastore and @relDB.
+func NewDatastoreSource(datastore models.Datastore, relDB models.RelDatastore) *DatastoreSource {
+	return &DatastoreSource{
+		datastore: datastore,
+		relDB:     relDB,
+	}
+}
+
+// Value returns the value of @asset at @timestamp according to the source of @feed.
+// Asset quotations are always the latest ones in the cache.
+func (s *DatastoreSource) Value(feed dia.OracleFeed, asset dia.OracleFeedAsset, timestamp time.Time) (float64, error) {
+	switch feed.Source {
+	case dia.OracleSourceAssetQuotation:
+		quotation, err := s.datastore.GetAssetQuotationCache(dia.Asset{Blockchain: asset.Blockchain, Address: asset.Address, Symbol: asset.Symbol})
+		if err != nil {
+			return 0, err
+		}
+		return quotation.Price, nil
+	case dia.OracleSourceFilter:
+		points, err := s.datastore.GetFilterPointsAsset(feed.Filter, "", asset.Address, asset.Blockchain, timestamp.Add(-filterLookback), timestamp)
+		if err != nil {
+			return 0, err
+		}
+		return latestFilterValue(points)
+	case dia.OracleSourceNFTFloor:
+		nftClass := dia.NFTClass{Address: common.HexToAddress(asset.Address).Hex(), Blockchain: asset.Blockchain}
+		return s.relDB.GetNFTFloorRecursive(nftClass, timestamp, nftFloorWindow, nftFloorStepBackLimit)
+	case dia.OracleSourceIndex:
+		indices, err := s.datastore.GetCryptoIndex(timestamp.Add(-indexLookback), timestamp, asset.Symbol, 1)
+		if err != nil {
+			return 0, err
+		}
+		return latestIndexValue(indices, asset.Symbol)
+	}
+	return 0, fmt.Errorf("unknown source %q", feed.Source)
+}
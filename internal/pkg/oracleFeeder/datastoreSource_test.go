@@ -0,0 +1,62 @@
+package oracleFeeder
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestDatastoreSource(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	rdb := models.NewMemoryRelDataStore()
+	t0 := time.Unix(1651406400, 0)
+	eth := dia.Asset{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Symbol: "ETH"}
+	feedAsset := dia.OracleFeedAsset{Blockchain: eth.Blockchain, Address: eth.Address, Symbol: eth.Symbol}
+
+	if _, err := ds.SetAssetQuotationCache(&models.AssetQuotation{Asset: eth, Price: 2000, Time: t0}, false); err != nil {
+		t.Fatal(err)
+	}
+	for i, value := range []float64{1990, 2010} {
+		if err := ds.SaveFilterInflux("MAIR120", eth, "", value, t0.Add(time.Duration(i)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := ds.SetCryptoIndex(&models.CryptoIndex{Asset: dia.Asset{Symbol: "SCIFI"}, Value: 150, CalculationTime: t0}); err != nil {
+		t.Fatal(err)
+	}
+	class := dia.NFTClass{Address: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", Blockchain: dia.ETHEREUM}
+	nft := dia.NFT{NFTClass: class, TokenID: "1"}
+	if err := rdb.SetNFTClass(class); err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.SetNFT(nft); err != nil {
+		t.Fatal(err)
+	}
+	if err := rdb.SetNFTTrade(dia.NFTTrade{NFT: nft, Price: new(big.Int).Mul(big.NewInt(80), big.NewInt(1e18)), Timestamp: t0}); err != nil {
+		t.Fatal(err)
+	}
+
+	source := NewDatastoreSource(ds, rdb)
+	tests := []struct {
+		feed     dia.OracleFeed
+		asset    dia.OracleFeedAsset
+		expected float64
+	}{
+		{dia.OracleFeed{Source: dia.OracleSourceAssetQuotation}, feedAsset, 2000},
+		{dia.OracleFeed{Source: dia.OracleSourceFilter, Filter: "MAIR120"}, feedAsset, 2010},
+		{dia.OracleFeed{Source: dia.OracleSourceIndex}, dia.OracleFeedAsset{Symbol: "SCIFI"}, 150},
+		{dia.OracleFeed{Source: dia.OracleSourceNFTFloor}, dia.OracleFeedAsset{Blockchain: dia.ETHEREUM, Address: "0xbc4ca0eda7647a8ab7c2061c2e118a18a936f13d"}, 80},
+	}
+	for _, test := range tests {
+		value, err := source.Value(test.feed, test.asset, t0.Add(time.Hour))
+		if err != nil || value != test.expected {
+			t.Errorf("%s: expected %v, got %v (%v)", test.feed.Source, test.expected, value, err)
+		}
+	}
+	if _, err := source.Value(dia.OracleFeed{Source: dia.OracleSourceFilter, Filter: "MA120"}, feedAsset, t0.Add(time.Hour)); err == nil {
+		t.Error("expected error for filter without points")
+	}
+}
@@ -0,0 +1,288 @@
+package oracleFeeder
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// OracleContract is the part of a DIAOracleV2 compatible contract binding used by the feeder.
+type OracleContract interface {
+	GetValue(opts *bind.CallOpts, key string) (*big.Int, *big.Int, error)
+	SetValue(opts *bind.TransactOpts, key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error)
+}
+
+// BatchOracleContract is the part of a DIAOracleV3 compatible contract binding used by feeders with batch updates.
+type BatchOracleContract interface {
+	OracleContract
+	SetMultipleValues(opts *bind.TransactOpts, keys []string, compressedValues []*big.Int) (*types.Transaction, error)
+}
+
+// Transactor sends transactions to the oracle contract and waits for their confirmation, such as ethhelper.TxManager.
+type Transactor interface {
+	Transact(ctx context.Context, transact ethhelper.TransactFunc) (*types.Transaction, *types.Receipt, error)
+}
+
+// oracleValue is the latest value of a key in the oracle.
+type oracleValue struct {
+	value float64
+	time  time.Time
+}
+
+// pendingUpdate is a value which needs to be pushed to the oracle under key.
+type pendingUpdate struct {
+	key   string
+	value float64
+}
+
+// Feeder pushes the values of the assets of a feed to an oracle contract. A value is pushed if it deviates
+// from the value in the oracle by more than the feed's deviation or if the oracle value is older than the heartbeat.
+type Feeder struct {
+	feed          dia.OracleFeed
+	contract      OracleContract
+	batchContract BatchOracleContract
+	transactor    Transactor
+	source        DataSource
+	relDB         models.RelDatastore
+
+	mu     sync.Mutex
+	values map[string]oracleValue
+}
+
+// NewFeeder returns a feeder of @feed, which must be validated through ValidateFeed.
+// Values are read from @source and written to @contract through @transactor.
+// Each update is logged to @relDB, unless it is nil.
+// If the feed has batch updates, @contract must implement BatchOracleContract.
+func NewFeeder(feed dia.OracleFeed, contract OracleContract, transactor Transactor, source DataSource, relDB models.RelDatastore) *Feeder {
+	f := &Feeder{
+		feed:       feed,
+		contract:   contract,
+		transactor: transactor,
+		source:     source,
+		relDB:      relDB,
+		values:     make(map[string]oracleValue),
+	}
+	if feed.BatchUpdates {
+		batchContract, ok := contract.(BatchOracleContract)
+		if !ok {
+			log.Warnf("%s: contract does not support batch updates, values are pushed one by one", feed.Name)
+		}
+		f.batchContract = batchContract
+	}
+	return f
+}
+
+// Run updates the assets of the feed every FrequencySeconds until @ctx is done.
+func (f *Feeder) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(f.feed.FrequencySeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		f.Update(ctx, time.Now())
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Update pushes the value of each asset whose deviation or heartbeat rule is triggered at @now
+// and waits for the confirmation of all updates. With batch updates, all values are pushed in a single
+// transaction. Otherwise errors are logged per asset, such that a failing asset does not block the others.
+func (f *Feeder) Update(ctx context.Context, now time.Time) {
+	var updates []pendingUpdate
+	for _, asset := range f.feed.Assets {
+		key := oracleKey(f.feed, asset)
+		value, ok, err := f.checkAsset(key, asset, now)
+		if err != nil {
+			log.Errorf("%s: check %s: %v", f.feed.Name, key, err)
+			continue
+		}
+		if ok {
+			updates = append(updates, pendingUpdate{key: key, value: value})
+		}
+	}
+	if len(updates) == 0 {
+		return
+	}
+
+	if f.batchContract != nil {
+		if err := f.setValues(ctx, updates, now); err != nil {
+			log.Errorf("%s: update %d keys: %v", f.feed.Name, len(updates), err)
+		}
+		return
+	}
+	var wg sync.WaitGroup
+	for _, update := range updates {
+		wg.Add(1)
+		go func(update pendingUpdate) {
+			defer wg.Done()
+			if err := f.setValue(ctx, update, now); err != nil {
+				log.Errorf("%s: update %s: %v", f.feed.Name, update.key, err)
+			}
+		}(update)
+	}
+	wg.Wait()
+}
+
+// checkAsset returns the value of @asset at @now and whether it needs to be pushed to the oracle under @key.
+func (f *Feeder) checkAsset(key string, asset dia.OracleFeedAsset, now time.Time) (float64, bool, error) {
+	value, err := f.source.Value(f.feed, asset, now)
+	if err != nil {
+		return 0, false, err
+	}
+	if value <= 0 {
+		log.Warnf("%s: ignore non-positive value %v of %s", f.feed.Name, value, key)
+		return 0, false, nil
+	}
+
+	f.mu.Lock()
+	last, ok := f.values[key]
+	f.mu.Unlock()
+	if !ok {
+		// The latest value is read from the oracle, such that a restarted feeder does not push all values again.
+		last, err = f.readOracle(key)
+		if err != nil {
+			log.Warnf("%s: read oracle value of %s: %v", f.feed.Name, key, err)
+		}
+		f.mu.Lock()
+		f.values[key] = last
+		f.mu.Unlock()
+	}
+	return value, f.needsUpdate(last, value, now), nil
+}
+
+// setValue pushes @update to the oracle and waits for the confirmation of the transaction.
+func (f *Feeder) setValue(ctx context.Context, update pendingUpdate, now time.Time) error {
+	tx, receipt, err := f.transactor.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return f.contract.SetValue(opts, update.key, scaleValue(update.value, f.feed.Decimals), big.NewInt(now.Unix()))
+	})
+	f.logUpdate(update, now, tx, receipt, err)
+	if err != nil {
+		return err
+	}
+	log.Infof("%s: set %s to %v in tx %s", f.feed.Name, update.key, update.value, tx.Hash().Hex())
+	f.storeValues([]pendingUpdate{update}, now)
+	return nil
+}
+
+// setValues pushes all @updates to the oracle in a single transaction and waits for its confirmation.
+func (f *Feeder) setValues(ctx context.Context, updates []pendingUpdate, now time.Time) error {
+	keys := make([]string, len(updates))
+	compressedValues := make([]*big.Int, len(updates))
+	for i, update := range updates {
+		keys[i] = update.key
+		compressedValues[i] = packValue(scaleValue(update.value, f.feed.Decimals), big.NewInt(now.Unix()))
+	}
+	tx, receipt, err := f.transactor.Transact(ctx, func(opts *bind.TransactOpts) (*types.Transaction, error) {
+		return f.batchContract.SetMultipleValues(opts, keys, compressedValues)
+	})
+	for _, update := range updates {
+		f.logUpdate(update, now, tx, receipt, err)
+	}
+	if err != nil {
+		return err
+	}
+	log.Infof("%s: set %d keys in tx %s", f.feed.Name, len(updates), tx.Hash().Hex())
+	f.storeValues(updates, now)
+	return nil
+}
+
+// storeValues keeps @updates as the latest values in the oracle.
+func (f *Feeder) storeValues(updates []pendingUpdate, now time.Time) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for _, update := range updates {
+		f.values[update.key] = oracleValue{value: update.value, time: now}
+	}
+}
+
+// logUpdate stores @update along with the outcome of its transaction @tx.
+func (f *Feeder) logUpdate(update pendingUpdate, now time.Time, tx *types.Transaction, receipt *types.Receipt, err error) {
+	if f.relDB == nil {
+		return
+	}
+	oracleUpdate := dia.OracleUpdate{
+		Feed:       f.feed.Name,
+		Blockchain: f.feed.Blockchain,
+		Contract:   f.feed.Contract,
+		Key:        update.key,
+		Value:      update.value,
+		Timestamp:  now,
+		Status:     dia.OracleUpdateConfirmed,
+	}
+	if tx != nil {
+		oracleUpdate.TxHash = tx.Hash().Hex()
+		oracleUpdate.Nonce = tx.Nonce()
+	}
+	if receipt != nil {
+		// The gas of batch updates is used by all keys together.
+		oracleUpdate.GasUsed = receipt.GasUsed
+	}
+	if err != nil {
+		oracleUpdate.Status = dia.OracleUpdateFailed
+		if errors.Is(err, ethhelper.ErrTxReverted) {
+			oracleUpdate.Status = dia.OracleUpdateReverted
+		}
+		oracleUpdate.Error = err.Error()
+	}
+	if err := f.relDB.SetOracleUpdate(oracleUpdate); err != nil {
+		log.Errorf("%s: log update of %s: %v", f.feed.Name, update.key, err)
+	}
+}
+
+// needsUpdate returns true if @value deviates from @last by more than the feed's deviation
+// or if @last is older than the feed's heartbeat at @now.
+func (f *Feeder) needsUpdate(last oracleValue, value float64, now time.Time) bool {
+	if last.time.IsZero() || last.value == 0 {
+		return true
+	}
+	if math.Abs(value-last.value) > last.value*float64(f.feed.DeviationPermille)/1000 {
+		return true
+	}
+	return f.feed.HeartbeatSeconds > 0 && now.Sub(last.time) >= time.Duration(f.feed.HeartbeatSeconds)*time.Second
+}
+
+// readOracle returns the value of @key in the oracle. The zero value is returned for unset keys.
+func (f *Feeder) readOracle(key string) (oracleValue, error) {
+	value, timestamp, err := f.contract.GetValue(&bind.CallOpts{}, key)
+	if err != nil || timestamp.Sign() == 0 {
+		return oracleValue{}, err
+	}
+	return oracleValue{value: unscaleValue(value, f.feed.Decimals), time: time.Unix(timestamp.Int64(), 0)}, nil
+}
+
+// scaleValue returns @value as an integer with @decimals decimals.
+func scaleValue(value float64, decimals int) *big.Int {
+	scaled := new(big.Float).Mul(big.NewFloat(value), new(big.Float).SetInt(pow10(decimals)))
+	scaled.Add(scaled, big.NewFloat(0.5))
+	result, _ := scaled.Int(nil)
+	return result
+}
+
+// packValue returns @value and @timestamp packed into a single word as stored by DIAOracleV2 and DIAOracleV3.
+func packValue(value *big.Int, timestamp *big.Int) *big.Int {
+	return new(big.Int).Add(new(big.Int).Lsh(value, 128), timestamp)
+}
+
+// unscaleValue is the inverse of scaleValue.
+func unscaleValue(value *big.Int, decimals int) float64 {
+	result, _ := new(big.Float).Quo(new(big.Float).SetInt(value), new(big.Float).SetInt(pow10(decimals))).Float64()
+	return result
+}
+
+func pow10(exponent int) *big.Int {
+	return new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exponent)), nil)
+}
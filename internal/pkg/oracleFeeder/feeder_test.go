@@ -0,0 +1,280 @@
+package oracleFeeder
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type oracleEntry struct {
+	value     *big.Int
+	timestamp *big.Int
+}
+
+// mockOracle records all values set through SetValue.
+type mockOracle struct {
+	mu      sync.Mutex
+	values  map[string]oracleEntry
+	updates []string
+}
+
+func (m *mockOracle) GetValue(opts *bind.CallOpts, key string) (*big.Int, *big.Int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	entry, ok := m.values[key]
+	if !ok {
+		return big.NewInt(0), big.NewInt(0), nil
+	}
+	return entry.value, entry.timestamp, nil
+}
+
+func (m *mockOracle) SetValue(opts *bind.TransactOpts, key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.values[key] = oracleEntry{value: value, timestamp: timestamp}
+	m.updates = append(m.updates, key)
+	return types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64()}), nil
+}
+
+// mockBatchOracle records each call of SetMultipleValues as a single update.
+type mockBatchOracle struct {
+	mockOracle
+	batches [][]string
+}
+
+func (m *mockBatchOracle) SetMultipleValues(opts *bind.TransactOpts, keys []string, compressedValues []*big.Int) (*types.Transaction, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mask := new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 128), big.NewInt(1))
+	for i, key := range keys {
+		value := new(big.Int).Rsh(compressedValues[i], 128)
+		timestamp := new(big.Int).And(compressedValues[i], mask)
+		m.values[key] = oracleEntry{value: value, timestamp: timestamp}
+	}
+	m.batches = append(m.batches, keys)
+	return types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64()}), nil
+}
+
+// mockTransactor mines all transactions immediately with increasing nonces.
+type mockTransactor struct {
+	mu    sync.Mutex
+	nonce uint64
+}
+
+func (m *mockTransactor) Transact(ctx context.Context, transact ethhelper.TransactFunc) (*types.Transaction, *types.Receipt, error) {
+	m.mu.Lock()
+	nonce := m.nonce
+	m.nonce++
+	m.mu.Unlock()
+	tx, err := transact(&bind.TransactOpts{Nonce: new(big.Int).SetUint64(nonce), NoSend: true})
+	if err != nil {
+		return nil, nil, err
+	}
+	return tx, &types.Receipt{TxHash: tx.Hash(), Status: types.ReceiptStatusSuccessful, GasUsed: 30000}, nil
+}
+
+func TestFeederUpdate(t *testing.T) {
+	price := 2000.0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/assetQuotation/Ethereum/0x0000000000000000000000000000000000000000" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintf(w, `{"Symbol":"ETH","Price":%v}`, price)
+	}))
+	defer server.Close()
+
+	feed := dia.OracleFeed{
+		Name:              "test",
+		NodeURL:           "http://localhost:8545",
+		Contract:          "0x0000000000000000000000000000000000000001",
+		Source:            dia.OracleSourceAssetQuotation,
+		DeviationPermille: 10,
+		HeartbeatSeconds:  3600,
+		Assets: []dia.OracleFeedAsset{
+			{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Symbol: "ETH"},
+			{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000002", Symbol: "MISSING"},
+		},
+	}
+	if err := ValidateFeed(&feed); err != nil {
+		t.Fatal(err)
+	}
+	oracle := &mockOracle{values: map[string]oracleEntry{
+		// ETH was set to 1995 by a previous run of the feeder.
+		"ETH/USD": {value: scaleValue(1995, 8), timestamp: big.NewInt(1651406400)},
+	}}
+	relDB := models.NewMemoryRelDataStore()
+	feeder := NewFeeder(feed, oracle, &mockTransactor{}, NewHTTPSource(server.URL), relDB)
+	t0 := time.Unix(1651406400, 0)
+
+	steps := []struct {
+		price    float64
+		time     time.Time
+		expected int
+	}{
+		// within deviation of the oracle value
+		{2000, t0.Add(time.Minute), 0},
+		{2030, t0.Add(2 * time.Minute), 1},
+		{2040, t0.Add(3 * time.Minute), 1},
+		// heartbeat
+		{2040, t0.Add(2*time.Minute + time.Hour), 2},
+	}
+	for i, step := range steps {
+		price = step.price
+		feeder.Update(context.Background(), step.time)
+		if len(oracle.updates) != step.expected {
+			t.Fatalf("step %d: expected %d updates, got %d", i, step.expected, len(oracle.updates))
+		}
+	}
+	if value := unscaleValue(oracle.values["ETH/USD"].value, 8); value != 2040 {
+		t.Errorf("expected oracle value 2040, got %v", value)
+	}
+
+	updates, err := relDB.GetOracleUpdates(feed.Name, t0, t0.Add(2*time.Hour))
+	if err != nil || len(updates) != 2 {
+		t.Fatalf("expected 2 logged updates, got %v (%v)", updates, err)
+	}
+	if updates[1].Key != "ETH/USD" || updates[1].Value != 2040 || updates[1].Nonce != 1 || updates[1].GasUsed != 30000 || updates[1].Status != dia.OracleUpdateConfirmed {
+		t.Errorf("unexpected update %v", updates[1])
+	}
+}
+
+func TestFeederBatchUpdate(t *testing.T) {
+	prices := map[string]float64{"ETH": 2000, "BTC": 30000, "DIA": 0.5}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// The address of each asset is its symbol.
+		symbol := path.Base(r.URL.Path)
+		fmt.Fprintf(w, `{"Symbol":"%s","Price":%v}`, symbol, prices[symbol])
+	}))
+	defer server.Close()
+
+	feed := dia.OracleFeed{
+		Name:              "batch",
+		NodeURL:           "http://localhost:8545",
+		Contract:          "0x0000000000000000000000000000000000000001",
+		Source:            dia.OracleSourceAssetQuotation,
+		DeviationPermille: 10,
+		BatchUpdates:      true,
+	}
+	for symbol := range prices {
+		feed.Assets = append(feed.Assets, dia.OracleFeedAsset{Blockchain: dia.ETHEREUM, Address: symbol, Symbol: symbol})
+	}
+	if err := ValidateFeed(&feed); err != nil {
+		t.Fatal(err)
+	}
+	oracle := &mockBatchOracle{mockOracle: mockOracle{values: map[string]oracleEntry{
+		"DIA/USD": {value: scaleValue(0.5, 8), timestamp: big.NewInt(1651406400)},
+	}}}
+	relDB := models.NewMemoryRelDataStore()
+	feeder := NewFeeder(feed, oracle, &mockTransactor{}, NewHTTPSource(server.URL), relDB)
+	t0 := time.Unix(1651406400, 0)
+
+	feeder.Update(context.Background(), t0.Add(time.Minute))
+	if len(oracle.batches) != 1 || len(oracle.batches[0]) != 2 || len(oracle.updates) != 0 {
+		t.Fatalf("expected a single batch of ETH and BTC, got %v", oracle.batches)
+	}
+	if entry := oracle.values["BTC/USD"]; unscaleValue(entry.value, 8) != 30000 || entry.timestamp.Int64() != t0.Add(time.Minute).Unix() {
+		t.Errorf("unexpected BTC entry %v", entry)
+	}
+	updates, _ := relDB.GetOracleUpdates(feed.Name, t0, t0.Add(time.Hour))
+	if len(updates) != 2 || updates[0].TxHash != updates[1].TxHash {
+		t.Errorf("expected 2 logged updates of the same transaction, got %v", updates)
+	}
+
+	prices["DIA"] = 0.6
+	feeder.Update(context.Background(), t0.Add(2*time.Minute))
+	if len(oracle.batches) != 2 || len(oracle.batches[1]) != 1 || oracle.batches[1][0] != "DIA/USD" {
+		t.Errorf("expected a batch of DIA, got %v", oracle.batches)
+	}
+}
+
+func TestParseFeeds(t *testing.T) {
+	os.Setenv("TEST_ORACLE_CONTRACT", "0x0000000000000000000000000000000000000001")
+	defer os.Unsetenv("TEST_ORACLE_CONTRACT")
+	feeds, err := ParseFeeds([]byte(`
+Feeds:
+  - Name: test
+    NodeURL: http://localhost:8545
+    Contract: "${TEST_ORACLE_CONTRACT}"
+    KeyFormat: "{symbol}-{blockchain}"
+    Source: filter
+    Filter: MAIR120
+    Assets:
+      - {Blockchain: Ethereum, Address: "0x0000000000000000000000000000000000000000", Symbol: ETH}
+`))
+	if err != nil || len(feeds) != 1 {
+		t.Fatalf("expected a single feed, got %v (%v)", feeds, err)
+	}
+	feed := feeds[0]
+	if err := ValidateFeed(&feed); err != nil {
+		t.Fatal(err)
+	}
+	if feed.Contract != "0x0000000000000000000000000000000000000001" || feed.Decimals != defaultDecimals || feed.PrivateKeyEnv != defaultPrivateKeyEnv {
+		t.Errorf("unexpected feed %v", feed)
+	}
+	if key := oracleKey(feed, feed.Assets[0]); key != "ETH-Ethereum" {
+		t.Errorf("expected key ETH-Ethereum, got %s", key)
+	}
+
+	feed.Filter = ""
+	if err := ValidateFeed(&feed); err == nil {
+		t.Error("expected error for filter source without filter")
+	}
+}
+
+func TestFeedsFile(t *testing.T) {
+	const feedsFile = "../../../config/oracleFeeder/feeds.yaml"
+	content, err := ioutil.ReadFile(feedsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Nodes, contracts and chain ids of the feeds are set in the deployment.
+	for _, match := range regexp.MustCompile(`\$\{(\w+)\}`).FindAllStringSubmatch(string(content), -1) {
+		value := "http://localhost:8545"
+		if strings.HasSuffix(match[1], "_CONTRACT") {
+			value = "0x0000000000000000000000000000000000000001"
+		} else if strings.HasSuffix(match[1], "_CHAIN_ID") {
+			value = "1"
+		}
+		os.Setenv(match[1], value)
+		defer os.Unsetenv(match[1])
+	}
+
+	feeds, err := ReadFeedsFromFile(feedsFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make(map[string]bool)
+	for i := range feeds {
+		feed := feeds[i]
+		if err := ValidateFeed(&feed); err != nil {
+			t.Error(err)
+		}
+		if names[feed.Name] {
+			t.Errorf("duplicate feed %s", feed.Name)
+		}
+		names[feed.Name] = true
+		for _, asset := range feed.Assets {
+			if !common.IsHexAddress(asset.Address) || asset.Blockchain == "" || asset.Symbol == "" {
+				t.Errorf("feed %s: invalid asset %v", feed.Name, asset)
+			}
+		}
+	}
+}
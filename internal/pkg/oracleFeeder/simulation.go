@@ -0,0 +1,166 @@
+package oracleFeeder
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	diaOracleServiceV2 "github.com/diadata-org/diadata/pkg/dia/scraper/blockchain-scrapers/blockchains/ethereum/diaOracleServiceV2"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind/backends"
+	"github.com/ethereum/go-ethereum/core"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/params"
+)
+
+const simulatedGasLimit = 8000000
+
+// SeriesSource is a source whose values are given by a function of asset and time, such as a synthetic price series.
+type SeriesSource func(asset dia.OracleFeedAsset, timestamp time.Time) float64
+
+// Value returns the value of the series of @asset at @timestamp.
+func (s SeriesSource) Value(feed dia.OracleFeed, asset dia.OracleFeedAsset, timestamp time.Time) (float64, error) {
+	return s(asset, timestamp), nil
+}
+
+// committingBackend mines each transaction into its own block as soon as it is sent. As the simulated
+// backend only accepts transactions with the next nonce of their sender, transactions sent concurrently
+// are queued until all transactions with lower nonces are mined.
+type committingBackend struct {
+	*backends.SimulatedBackend
+	mu     sync.Mutex
+	queued map[uint64]*types.Transaction
+}
+
+func newCommittingBackend(backend *backends.SimulatedBackend) *committingBackend {
+	return &committingBackend{
+		SimulatedBackend: backend,
+		queued:           make(map[uint64]*types.Transaction),
+	}
+}
+
+func (b *committingBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	sender, err := types.Sender(types.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil {
+		return err
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	nonce, err := b.PendingNonceAt(ctx, sender)
+	if err != nil {
+		return err
+	}
+	if tx.Nonce() < nonce {
+		return fmt.Errorf("nonce too low: got %d, want %d", tx.Nonce(), nonce)
+	}
+	b.queued[tx.Nonce()] = tx
+	for {
+		next, ok := b.queued[nonce]
+		if !ok {
+			return nil
+		}
+		delete(b.queued, nonce)
+		if err := b.SimulatedBackend.SendTransaction(ctx, next); err != nil {
+			return err
+		}
+		b.Commit()
+		nonce++
+	}
+}
+
+// Simulation runs a feeder against a DIAOracleV2 contract deployed to a simulated chain, such that feeds
+// can be run without a node and a funded key. Updates are sent through the same transaction manager as on
+// a real chain. Feeds with batch updates push their values one by one, as DIAOracleV2 has no setMultipleValues.
+type Simulation struct {
+	Backend  *backends.SimulatedBackend
+	Contract *diaOracleServiceV2.DIAOracleV2
+	Feeder   *Feeder
+	// nextBlock is the first block whose events are not returned by Step yet.
+	nextBlock uint64
+}
+
+// NewSimulation deploys a DIAOracleV2 contract to a new simulated chain and returns a simulation
+// of @feed pushing the values of @source to it. The contract and node url of @feed are ignored.
+func NewSimulation(feed dia.OracleFeed, source DataSource) (*Simulation, error) {
+	key, err := crypto.GenerateKey()
+	if err != nil {
+		return nil, err
+	}
+	auth, err := bind.NewKeyedTransactorWithChainID(key, params.AllEthashProtocolChanges.ChainID)
+	if err != nil {
+		return nil, err
+	}
+	balance := new(big.Int).Mul(big.NewInt(1000), big.NewInt(params.Ether))
+	backend := backends.NewSimulatedBackend(core.GenesisAlloc{auth.From: {Balance: balance}}, simulatedGasLimit)
+	address, _, contract, err := diaOracleServiceV2.DeployDIAOracleV2(auth, backend)
+	if err != nil {
+		backend.Close()
+		return nil, err
+	}
+	backend.Commit()
+
+	feed.Contract = address.Hex()
+	feed.NodeURL = "simulated"
+	if err := ValidateFeed(&feed); err != nil {
+		backend.Close()
+		return nil, err
+	}
+	txManager := ethhelper.NewTxManager(newCommittingBackend(backend), auth, TxConfig(feed))
+	return &Simulation{
+		Backend:   backend,
+		Contract:  contract,
+		Feeder:    NewFeeder(feed, contract, txManager, source, nil),
+		nextBlock: backend.Blockchain().CurrentBlock().NumberU64() + 1,
+	}, nil
+}
+
+// Step runs a single update of the feeder at @now and returns the OracleUpdate events emitted by it.
+func (s *Simulation) Step(ctx context.Context, now time.Time) ([]*diaOracleServiceV2.DIAOracleV2OracleUpdate, error) {
+	s.Feeder.Update(ctx, now)
+	head := s.Backend.Blockchain().CurrentBlock().NumberU64()
+	if head < s.nextBlock {
+		return nil, nil
+	}
+	iterator, err := s.Contract.FilterOracleUpdate(&bind.FilterOpts{Start: s.nextBlock, End: &head, Context: ctx})
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Close()
+	var events []*diaOracleServiceV2.DIAOracleV2OracleUpdate
+	for iterator.Next() {
+		events = append(events, iterator.Event)
+	}
+	s.nextBlock = head + 1
+	return events, iterator.Error()
+}
+
+// Run steps the simulation every FrequencySeconds of the feed until @ctx is done and logs all updates.
+func (s *Simulation) Run(ctx context.Context) {
+	feed := s.Feeder.feed
+	ticker := time.NewTicker(time.Duration(feed.FrequencySeconds) * time.Second)
+	defer ticker.Stop()
+	for {
+		events, err := s.Step(ctx, time.Now())
+		if err != nil {
+			log.Errorf("%s: read simulated updates: %v", feed.Name, err)
+		}
+		for _, event := range events {
+			log.Infof("%s: simulated update of %s to %v at %s", feed.Name, event.Key, unscaleValue(event.Value, feed.Decimals), time.Unix(event.Timestamp.Int64(), 0))
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// Close shuts down the simulated chain.
+func (s *Simulation) Close() error {
+	return s.Backend.Close()
+}
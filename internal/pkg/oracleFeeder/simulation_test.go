@@ -0,0 +1,94 @@
+package oracleFeeder
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+)
+
+func TestSimulation(t *testing.T) {
+	t.Run("single updates", func(t *testing.T) { testSimulation(t, false) })
+	// DIAOracleV3 cannot be deployed to the simulated chain, as its binding has no bytecode yet.
+	// Batch feeds fall back to single updates on DIAOracleV2 and must push the same values.
+	t.Run("batch updates", func(t *testing.T) { testSimulation(t, true) })
+}
+
+func testSimulation(t *testing.T, batchUpdates bool) {
+	t0 := time.Unix(1651406400, 0)
+	// Synthetic price series of ETH and BTC by minutes since t0.
+	series := map[string]map[int]float64{
+		"ETH": {0: 2000, 1: 2010, 2: 2030, 3: 2030, 61: 2030, 62: 2030},
+		"BTC": {0: 30000, 1: 30000, 2: 29800, 3: 29600, 61: 29600, 62: 29600},
+	}
+	source := SeriesSource(func(asset dia.OracleFeedAsset, timestamp time.Time) float64 {
+		return series[asset.Symbol][int(timestamp.Sub(t0)/time.Minute)]
+	})
+	feed := dia.OracleFeed{
+		Name:              "simulation",
+		Source:            dia.OracleSourceAssetQuotation,
+		DeviationPermille: 10,
+		HeartbeatSeconds:  3600,
+		BatchUpdates:      batchUpdates,
+		Assets: []dia.OracleFeedAsset{
+			{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Symbol: "ETH"},
+			{Blockchain: dia.BITCOIN, Address: "0x0000000000000000000000000000000000000000", Symbol: "BTC"},
+		},
+	}
+	simulation, err := NewSimulation(feed, source)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer simulation.Close()
+
+	steps := []struct {
+		minute   int
+		expected []string
+	}{
+		// initial values
+		{0, []string{"BTC/USD", "ETH/USD"}},
+		// within deviation
+		{1, nil},
+		// ETH deviates by 1.5%
+		{2, []string{"ETH/USD"}},
+		// BTC deviates by 1.33% from its last update
+		{3, []string{"BTC/USD"}},
+		// heartbeat of ETH
+		{62, []string{"ETH/USD"}},
+	}
+	for _, step := range steps {
+		now := t0.Add(time.Duration(step.minute) * time.Minute)
+		events, err := simulation.Step(context.Background(), now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var keys []string
+		for _, event := range events {
+			keys = append(keys, event.Key)
+			if event.Timestamp.Int64() != now.Unix() {
+				t.Errorf("minute %d: expected timestamp %d of %s, got %v", step.minute, now.Unix(), event.Key, event.Timestamp)
+			}
+			symbol := event.Key[:len(event.Key)-len("/USD")]
+			if value := unscaleValue(event.Value, defaultDecimals); value != series[symbol][step.minute] {
+				t.Errorf("minute %d: expected value %v of %s, got %v", step.minute, series[symbol][step.minute], event.Key, value)
+			}
+		}
+		sort.Strings(keys)
+		if len(keys) != len(step.expected) {
+			t.Fatalf("minute %d: expected updates of %v, got %v", step.minute, step.expected, keys)
+		}
+		for i := range keys {
+			if keys[i] != step.expected[i] {
+				t.Fatalf("minute %d: expected updates of %v, got %v", step.minute, step.expected, keys)
+			}
+		}
+	}
+
+	value, timestamp, err := simulation.Contract.GetValue(&bind.CallOpts{}, "ETH/USD")
+	if err != nil || unscaleValue(value, defaultDecimals) != 2030 || timestamp.Int64() != t0.Add(62*time.Minute).Unix() {
+		t.Errorf("unexpected oracle value %v at %v (%v)", value, timestamp, err)
+	}
+}
@@ -0,0 +1,111 @@
+package oracleFeeder
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// DefaultAPIURL is the public DIA API from which feed values are read.
+const DefaultAPIURL = "https://api.diadata.org/v1"
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// DataSource provides the values pushed by oracle feeds.
+type DataSource interface {
+	// Value returns the value of @asset at @timestamp according to the source of @feed.
+	Value(feed dia.OracleFeed, asset dia.OracleFeedAsset, timestamp time.Time) (float64, error)
+}
+
+// HTTPSource reads values from a DIA API such as the public one at DefaultAPIURL.
+// Values are always the latest ones available in the API.
+type HTTPSource struct {
+	apiURL string
+}
+
+// NewHTTPSource returns a source reading values from the DIA API at @apiURL.
+func NewHTTPSource(apiURL string) *HTTPSource {
+	return &HTTPSource{apiURL: apiURL}
+}
+
+// Value returns the latest value of @asset according to the source of @feed.
+func (s *HTTPSource) Value(feed dia.OracleFeed, asset dia.OracleFeedAsset, timestamp time.Time) (float64, error) {
+	switch feed.Source {
+	case dia.OracleSourceAssetQuotation:
+		var quotation models.AssetQuotationFull
+		err := getJSON(s.apiURL+"/assetQuotation/"+asset.Blockchain+"/"+asset.Address, &quotation)
+		return quotation.Price, err
+	case dia.OracleSourceFilter:
+		var points models.Points
+		err := getJSON(s.apiURL+"/assetChartPoints/"+feed.Filter+"/"+asset.Blockchain+"/"+asset.Address, &points)
+		if err != nil {
+			return 0, err
+		}
+		return latestFilterValue(&points)
+	case dia.OracleSourceNFTFloor:
+		var floor struct {
+			Floor float64 `json:"Floor_Price"`
+		}
+		err := getJSON(s.apiURL+"/NFTFloor/"+asset.Blockchain+"/"+asset.Address, &floor)
+		return floor.Floor, err
+	case dia.OracleSourceIndex:
+		var indices []models.CryptoIndex
+		err := getJSON(s.apiURL+"/index/"+asset.Symbol, &indices)
+		if err != nil {
+			return 0, err
+		}
+		return latestIndexValue(indices, asset.Symbol)
+	}
+	return 0, fmt.Errorf("unknown source %q", feed.Source)
+}
+
+// latestFilterValue returns the value of the most recent filter point in @points.
+// Filter points are ordered by descending time.
+func latestFilterValue(points *models.Points) (float64, error) {
+	for _, result := range points.DataPoints {
+		for _, row := range result.Series {
+			for i, column := range row.Columns {
+				if column != "value" || len(row.Values) == 0 || len(row.Values[0]) <= i {
+					continue
+				}
+				switch value := row.Values[0][i].(type) {
+				case float64:
+					return value, nil
+				case json.Number:
+					return value.Float64()
+				}
+			}
+		}
+	}
+	return 0, errors.New("no filter points")
+}
+
+// latestIndexValue returns the value of the first index in @indices, which are ordered by descending time.
+func latestIndexValue(indices []models.CryptoIndex, symbol string) (float64, error) {
+	if len(indices) == 0 {
+		return 0, fmt.Errorf("no value for index %s", symbol)
+	}
+	return indices[0].Value, nil
+}
+
+func getJSON(url string, v interface{}) error {
+	response, err := httpClient.Get(url)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		return fmt.Errorf("get %s: status code %d", url, response.StatusCode)
+	}
+	content, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(content, v)
+}
@@ -0,0 +1,163 @@
+package replay
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// Datastore is an in-memory stand-in for models.Datastore covering the methods used by
+// the tradesBlockService and the filtersBlockService. Nothing is written to
+// redis or influx. Asset prices which are not set during the replay are read
+// from the optional underlying datastore.
+// All other methods are delegated to the underlying datastore, which is an in-memory
+// models.MemoryDB if none is given.
+type Datastore struct {
+	models.Datastore
+	mu           sync.RWMutex
+	prices       map[dia.Asset][]models.AssetQuotation
+	filterValues []FilterValue
+}
+
+// FilterValue is a filter value as stored by a filter through SetFilter.
+type FilterValue struct {
+	Name     string
+	Asset    dia.Asset
+	Exchange string
+	Value    float64
+	Time     time.Time
+}
+
+// NewDatastore returns an in-memory datastore. Quotations not found in memory are
+// looked up in @underlying, which may be nil.
+func NewDatastore(underlying models.Datastore) *Datastore {
+	if underlying == nil {
+		underlying = models.NewMemoryDataStore()
+	}
+	return &Datastore{
+		Datastore: underlying,
+		prices:    make(map[dia.Asset][]models.AssetQuotation),
+	}
+}
+
+// assetKey strips all fields but blockchain and address, which uniquely identify an asset.
+func assetKey(asset dia.Asset) dia.Asset {
+	return dia.Asset{Blockchain: asset.Blockchain, Address: asset.Address}
+}
+
+// SetAssetQuotation stores @quotation in memory.
+func (ds *Datastore) SetAssetQuotation(quotation *models.AssetQuotation) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	key := assetKey(quotation.Asset)
+	quotations := ds.prices[key]
+	index := sort.Search(len(quotations), func(i int) bool {
+		return quotations[i].Time.After(quotation.Time)
+	})
+	quotations = append(quotations, models.AssetQuotation{})
+	copy(quotations[index+1:], quotations[index:])
+	quotations[index] = *quotation
+	ds.prices[key] = quotations
+	return nil
+}
+
+// SetAssetPriceUSD stores the price of @asset at @timestamp in memory.
+func (ds *Datastore) SetAssetPriceUSD(asset dia.Asset, price float64, timestamp time.Time) error {
+	return ds.SetAssetQuotation(&models.AssetQuotation{
+		Asset:  asset,
+		Price:  price,
+		Source: dia.Diadata,
+		Time:   timestamp,
+	})
+}
+
+// GetAssetQuotation returns the latest quotation of @asset before @timestamp.
+func (ds *Datastore) GetAssetQuotation(asset dia.Asset, timestamp time.Time) (*models.AssetQuotation, error) {
+	ds.mu.RLock()
+	quotations := ds.prices[assetKey(asset)]
+	index := sort.Search(len(quotations), func(i int) bool {
+		return quotations[i].Time.After(timestamp)
+	})
+	if index > 0 {
+		quotation := quotations[index-1]
+		ds.mu.RUnlock()
+		return &quotation, nil
+	}
+	ds.mu.RUnlock()
+	return ds.Datastore.GetAssetQuotation(asset, timestamp)
+}
+
+// GetAssetPriceUSD returns the latest USD price of @asset before @timestamp.
+func (ds *Datastore) GetAssetPriceUSD(asset dia.Asset, timestamp time.Time) (float64, error) {
+	quotation, err := ds.GetAssetQuotation(asset, timestamp)
+	if err != nil {
+		return 0, err
+	}
+	return quotation.Price, nil
+}
+
+// GetAssetQuotationCache returns the most recent quotation of @asset.
+func (ds *Datastore) GetAssetQuotationCache(asset dia.Asset) (*models.AssetQuotation, error) {
+	return ds.GetAssetQuotation(asset, time.Unix(1<<62, 0))
+}
+
+// SetPricePathCache discards @pricePath.
+func (ds *Datastore) SetPricePathCache(pricePath *models.PricePath) error {
+	return nil
+}
+
+// SetFilter records a filter value.
+func (ds *Datastore) SetFilter(filterName string, asset dia.Asset, exchange string, value float64, t time.Time) error {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.filterValues = append(ds.filterValues, FilterValue{
+		Name:     filterName,
+		Asset:    asset,
+		Exchange: exchange,
+		Value:    value,
+		Time:     t,
+	})
+	return nil
+}
+
+// FilterValues returns and resets the filter values recorded since the last call.
+func (ds *Datastore) FilterValues() []FilterValue {
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	filterValues := ds.filterValues
+	ds.filterValues = nil
+	return filterValues
+}
+
+// SaveTradeInflux discards @t.
+func (ds *Datastore) SaveTradeInflux(t *dia.Trade) error {
+	return nil
+}
+
+// SaveTradeInfluxToTable discards @t.
+func (ds *Datastore) SaveTradeInfluxToTable(t *dia.Trade, table string) error {
+	return nil
+}
+
+// SetLastTradeTimeForExchange is a no-op.
+func (ds *Datastore) SetLastTradeTimeForExchange(asset dia.Asset, exchange string, t time.Time) error {
+	return nil
+}
+
+// Flush is a no-op.
+func (ds *Datastore) Flush() error {
+	return nil
+}
+
+// ExecuteRedisPipe is a no-op.
+func (ds *Datastore) ExecuteRedisPipe() error {
+	return nil
+}
+
+// FlushRedisPipe is a no-op.
+func (ds *Datastore) FlushRedisPipe() error {
+	return nil
+}
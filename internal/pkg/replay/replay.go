@@ -0,0 +1,132 @@
+// Package replay re-runs recorded trades through the tradesBlockService and the
+// filtersBlockService in order to reproduce the filtersBlocks computed at the time.
+package replay
+
+import (
+	"sort"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/internal/pkg/tradesBlockService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	log "github.com/sirupsen/logrus"
+)
+
+// Block is the outcome of the replay for a single filtersBlock.
+type Block struct {
+	BlockHash       string
+	TradesBlockHash string
+	BeginTime       time.Time
+	EndTime         time.Time
+	FilterPoints    []dia.FilterPoint
+	// FilterValues are the values stored by all filters for trades in the block.
+	FilterValues []FilterValue
+}
+
+// Replay feeds @trades in the given order through a tradesBlockService in historical mode
+// and a filtersBlockService running @filterConfigs. All reads and writes go to @ds.
+// A tradesBlock is processed by the filtersBlockService before the next trade is handed
+// to the tradesBlockService, so base token prices saved by the filters are the same in
+// every run. As in production, the trade finalising a block is priced before the filters
+// of that block are saved.
+// The trades of the last tradesBlock are not processed, as the block is only finalised
+// by a trade after its end time.
+func Replay(trades []dia.Trade, ds *Datastore, filterConfigs []dia.FilterConfig) []Block {
+	var (
+		chanFiltersBlock = make(chan *dia.FiltersBlock)
+		filtersBlocks    []dia.FiltersBlock
+		filtersDone      = make(chan nothing)
+		fds              = &filtersDatastore{Datastore: ds, flushed: make(chan nothing)}
+	)
+
+	tbs := tradesBlockService.NewTradesBlockService(ds, dia.BlockSizeSeconds, true)
+	fbs := filters.NewFiltersBlockServiceWithConfig(nil, fds, chanFiltersBlock, filterConfigs)
+
+	go func() {
+		for fb := range chanFiltersBlock {
+			filtersBlocks = append(filtersBlocks, *fb)
+		}
+		close(filtersDone)
+	}()
+
+	// processTradesBlocks hands all tradesBlocks emitted by tbs to fbs until @done is closed.
+	// The trade following a block's finalisation is not received by tbs before @done is closed,
+	// so all blocks finalised by the previous trade are processed at that point.
+	processTradesBlocks := func(done chan nothing) {
+		for {
+			select {
+			case tb := <-tbs.Channel():
+				fbs.ProcessTradesBlock(tb)
+				<-fds.flushed
+			case <-done:
+				return
+			}
+		}
+	}
+
+	for i := range trades {
+		received := make(chan nothing)
+		go func(trade *dia.Trade) {
+			tbs.ProcessTrade(trade)
+			close(received)
+		}(&trades[i])
+		processTradesBlocks(received)
+	}
+
+	closed := make(chan nothing)
+	go func() {
+		if err := tbs.Close(); err != nil {
+			log.Error("close tradesBlockService: ", err)
+		}
+		close(closed)
+	}()
+	processTradesBlocks(closed)
+
+	if err := fbs.Close(); err != nil {
+		log.Error("close filtersBlockService: ", err)
+	}
+	close(chanFiltersBlock)
+	<-filtersDone
+
+	return makeBlocks(filtersBlocks, ds.FilterValues())
+}
+
+type nothing struct{}
+
+// filtersDatastore is the datastore handed to the filtersBlockService. Flush is the last
+// call in the processing of a tradesBlock and signals that all filters have been saved.
+type filtersDatastore struct {
+	*Datastore
+	flushed chan nothing
+}
+
+// Flush signals that the filtersBlockService is done with the current tradesBlock.
+func (fds *filtersDatastore) Flush() error {
+	fds.flushed <- nothing{}
+	return nil
+}
+
+// makeBlocks assigns each filter value to the filtersBlock containing its timestamp.
+func makeBlocks(filtersBlocks []dia.FiltersBlock, filterValues []FilterValue) []Block {
+	blocks := make([]Block, len(filtersBlocks))
+	for i, fb := range filtersBlocks {
+		blocks[i] = Block{
+			BlockHash:       fb.BlockHash,
+			TradesBlockHash: fb.FiltersBlockData.TradesBlockHash,
+			BeginTime:       fb.FiltersBlockData.BeginTime,
+			EndTime:         fb.FiltersBlockData.EndTime,
+			FilterPoints:    fb.FiltersBlockData.FilterPoints,
+		}
+	}
+	for _, fv := range filterValues {
+		index := sort.Search(len(blocks), func(i int) bool {
+			return !blocks[i].EndTime.Before(fv.Time)
+		})
+		if index == len(blocks) || fv.Time.Before(blocks[index].BeginTime) {
+			log.Warnf("filter value %s for %s at %v is not contained in any filtersBlock", fv.Name, fv.Asset.Symbol, fv.Time)
+			continue
+		}
+		blocks[index].FilterValues = append(blocks[index].FilterValues, fv)
+	}
+	return blocks
+}
@@ -0,0 +1,122 @@
+package replay
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	filters "github.com/diadata-org/diadata/internal/pkg/filtersBlockService"
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+var (
+	usd = dia.Asset{Symbol: "USD", Address: "840", Blockchain: dia.FIAT}
+	eth = dia.Asset{Symbol: "ETH", Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.ETHEREUM}
+	btc = dia.Asset{Symbol: "BTC", Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.BITCOIN}
+)
+
+// makeTrades returns ETH-USD and BTC-ETH trades spanning @numBlocks tradesBlocks.
+func makeTrades(begin time.Time, numBlocks int) (trades []dia.Trade) {
+	for i := 0; i < numBlocks*dia.BlockSizeSeconds; i += 10 {
+		trades = append(trades, dia.Trade{
+			Symbol:       "ETH",
+			Pair:         "ETH-USD",
+			QuoteToken:   eth,
+			BaseToken:    usd,
+			Price:        2000 + float64(i%7),
+			Volume:       1,
+			Time:         begin.Add(time.Duration(i) * time.Second),
+			Source:       dia.KrakenExchange,
+			VerifiedPair: true,
+		})
+		trades = append(trades, dia.Trade{
+			Symbol:       "BTC",
+			Pair:         "BTC-ETH",
+			QuoteToken:   btc,
+			BaseToken:    eth,
+			Price:        15 + float64(i%3)/10,
+			Volume:       0.5,
+			Time:         begin.Add(time.Duration(i+5) * time.Second),
+			Source:       dia.BinanceExchange,
+			VerifiedPair: true,
+		})
+	}
+	return
+}
+
+func TestReplayDeterministic(t *testing.T) {
+	begin := time.Unix(1651406400, 0).UTC()
+	trades := makeTrades(begin, 4)
+
+	var runs [][]Block
+	for i := 0; i < 2; i++ {
+		ds := NewDatastore(nil)
+		err := ds.SetAssetPriceUSD(eth, 2000, begin.Add(-time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+		runs = append(runs, Replay(append([]dia.Trade{}, trades...), ds, filters.DefaultFilterConfigs()))
+	}
+
+	// The last tradesBlock is not finalised.
+	if len(runs[0]) != 3 {
+		t.Fatalf("expected 3 filtersBlocks, got %d", len(runs[0]))
+	}
+	for i := range runs[0] {
+		if runs[0][i].BlockHash != runs[1][i].BlockHash {
+			t.Errorf("block %d: hashes differ: %s and %s", i, runs[0][i].BlockHash, runs[1][i].BlockHash)
+		}
+		if len(runs[0][i].FilterValues) == 0 {
+			t.Errorf("block %d: no filter values", i)
+		}
+	}
+}
+
+func TestReadTradesJSONL(t *testing.T) {
+	begin := time.Unix(1651406400, 0).UTC()
+	trades := makeTrades(begin, 1)
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	for _, trade := range trades {
+		if err := encoder.Encode(trade); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	read, err := ReadTradesJSONL(&buf, begin.Add(time.Minute), begin.Add(2*time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trade := range read {
+		if trade.Time.Before(begin.Add(time.Minute)) || !trade.Time.Before(begin.Add(2*time.Minute)) {
+			t.Errorf("trade at %v outside of time range", trade.Time)
+		}
+	}
+	if len(read) != len(trades)/2 {
+		t.Errorf("expected %d trades, got %d", len(trades)/2, len(read))
+	}
+}
+
+func TestDatastoreQuotations(t *testing.T) {
+	ds := NewDatastore(nil)
+	t0 := time.Unix(1651406400, 0)
+	for i, price := range []float64{3, 1, 2} {
+		err := ds.SetAssetQuotation(&models.AssetQuotation{Asset: eth, Price: price, Time: t0.Add(time.Duration(price) * time.Minute)})
+		if err != nil {
+			t.Fatalf("set quotation %d: %v", i, err)
+		}
+	}
+	if _, err := ds.GetAssetPriceUSD(eth, t0); err == nil {
+		t.Error("expected error for timestamp before first quotation")
+	}
+	price, err := ds.GetAssetPriceUSD(eth, t0.Add(150*time.Second))
+	if err != nil || price != 2 {
+		t.Errorf("expected price 2, got %v (%v)", price, err)
+	}
+	quotation, err := ds.GetAssetQuotationCache(eth)
+	if err != nil || quotation.Price != 3 {
+		t.Errorf("expected latest price 3, got %v (%v)", quotation.Price, err)
+	}
+}
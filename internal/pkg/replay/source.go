@@ -0,0 +1,102 @@
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const (
+	influxTradesTable = "trades"
+	// Trades are queried from influx in batches of tradesBatchDuration.
+	tradesBatchDuration = time.Hour
+)
+
+// ReadTradesJSONL returns all trades from the JSON lines in @r with timestamp in [@starttime, @endtime).
+// The order of the trades is preserved.
+func ReadTradesJSONL(r io.Reader, starttime time.Time, endtime time.Time) (trades []dia.Trade, err error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var trade dia.Trade
+		err = json.Unmarshal(scanner.Bytes(), &trade)
+		if err != nil {
+			return
+		}
+		if trade.Time.Before(starttime) || !trade.Time.Before(endtime) {
+			continue
+		}
+		trades = append(trades, trade)
+	}
+	err = scanner.Err()
+	return
+}
+
+// ReadTradesJSONLFile is a wrapper around ReadTradesJSONL reading from the file @filename.
+func ReadTradesJSONLFile(filename string, starttime time.Time, endtime time.Time) (trades []dia.Trade, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer func() {
+		cerr := file.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+	return ReadTradesJSONL(file, starttime, endtime)
+}
+
+// ReadQuotationsJSONLFile returns all asset quotations from the JSON lines in the file @filename.
+func ReadQuotationsJSONLFile(filename string) (quotations []models.AssetQuotation, err error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer func() {
+		cerr := file.Close()
+		if err == nil {
+			err = cerr
+		}
+	}()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var quotation models.AssetQuotation
+		err = json.Unmarshal(scanner.Bytes(), &quotation)
+		if err != nil {
+			return
+		}
+		quotations = append(quotations, quotation)
+	}
+	err = scanner.Err()
+	return
+}
+
+// ReadTradesInflux returns all trades from the influx trades table with timestamp in [@starttime, @endtime),
+// ordered by time. If @exchange is non-empty only trades from @exchange are returned.
+func ReadTradesInflux(ds models.Datastore, exchange string, starttime time.Time, endtime time.Time) (trades []dia.Trade, err error) {
+	for batchStart := starttime; batchStart.Before(endtime); batchStart = batchStart.Add(tradesBatchDuration) {
+		batchEnd := batchStart.Add(tradesBatchDuration)
+		if batchEnd.After(endtime) {
+			batchEnd = endtime
+		}
+		var batch []dia.Trade
+		batch, err = ds.GetOldTradesFromInflux(influxTradesTable, exchange, true, batchStart, batchEnd)
+		if err != nil {
+			return
+		}
+		trades = append(trades, batch...)
+	}
+	return
+}
@@ -0,0 +1,48 @@
+package tradeGapService
+
+import (
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// DetectGaps returns all gaps in the trade counts @counts of a pair, which are given in consecutive bins of size @binSize.
+// The expected number of trades per bin is the average over all bins. A gap is a maximal run of bins
+// without trades in which at least @minExpectedTrades trades were expected.
+// Exchange and pair of the returned gaps are not set.
+func DetectGaps(counts []dia.TradeCount, binSize time.Duration, minExpectedTrades float64) (gaps []dia.TradeGap) {
+	if len(counts) == 0 {
+		return
+	}
+	total := 0
+	for _, tc := range counts {
+		total += tc.Count
+	}
+	expectedPerBin := float64(total) / float64(len(counts))
+	if expectedPerBin == 0 {
+		return
+	}
+
+	runStart := -1
+	for i := 0; i <= len(counts); i++ {
+		if i < len(counts) && counts[i].Count == 0 {
+			if runStart < 0 {
+				runStart = i
+			}
+			continue
+		}
+		if runStart < 0 {
+			continue
+		}
+		expected := expectedPerBin * float64(i-runStart)
+		if expected >= minExpectedTrades {
+			gaps = append(gaps, dia.TradeGap{
+				StartTime:         counts[runStart].Time,
+				EndTime:           counts[i-1].Time.Add(binSize),
+				ExpectedNumTrades: expected,
+			})
+		}
+		runStart = -1
+	}
+	return
+}
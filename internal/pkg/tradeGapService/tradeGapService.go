@@ -0,0 +1,206 @@
+package tradeGapService
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// Backfiller sends all trades of @gap to @chanTrades. It returns once the gap is backfilled.
+type Backfiller func(ctx context.Context, gap dia.TradeGap, chanTrades chan<- *dia.Trade) error
+
+// Config holds the parameters of the gap detection and the tables used for backfilling.
+type Config struct {
+	// Lookback is the time range in which the expected trading rate is computed and gaps are detected.
+	Lookback time.Duration
+	// BinSize is the size of the time bins in which trades are counted.
+	BinSize time.Duration
+	// Delay is the time after which trades are expected to be stored in influx.
+	// More recent bins are not checked for gaps.
+	Delay time.Duration
+	// MinExpectedTrades is the minimal number of trades which must be expected in a gap.
+	MinExpectedTrades float64
+	// InfluxDB is the influx database of both trades tables.
+	InfluxDB string
+	// TradesTable is the table into which backfilled trades are merged.
+	TradesTable string
+	// TmpTable is the table to which backfilled trades are written before they are merged.
+	TmpTable string
+}
+
+// TradeGapService records gaps in the trades of pairs and backfills them.
+// Gaps are processed one after the other, such that the temporary table only holds the trades of a single gap.
+type TradeGapService struct {
+	datastore   models.Datastore
+	relDB       models.RelDatastore
+	config      Config
+	backfillers map[string]Backfiller
+}
+
+// NewTradeGapService returns a service which backfills gaps on the exchanges in @backfillers.
+func NewTradeGapService(datastore models.Datastore, relDB models.RelDatastore, config Config, backfillers map[string]Backfiller) *TradeGapService {
+	return &TradeGapService{
+		datastore:   datastore,
+		relDB:       relDB,
+		config:      config,
+		backfillers: backfillers,
+	}
+}
+
+// Run detects and backfills gaps on all exchanges every @interval until @ctx is done.
+func (s *TradeGapService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		for exchange := range s.backfillers {
+			if err := s.RecordGaps(exchange, time.Now()); err != nil {
+				log.Errorf("detect gaps on %s: %v", exchange, err)
+			}
+		}
+		if err := s.BackfillGaps(ctx); err != nil {
+			log.Error("backfill gaps: ", err)
+		}
+		select {
+		case <-ticker.C:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// RecordGaps records all gaps in the trades of pairs on @exchange in the lookback period before @now.
+// Parts of gaps which are recorded already are skipped, such that an ongoing gap is recorded piecewise.
+func (s *TradeGapService) RecordGaps(exchange string, now time.Time) error {
+	endtime := now.Add(-s.config.Delay).Truncate(s.config.BinSize)
+	starttime := endtime.Add(-s.config.Lookback)
+	counts, err := s.datastore.GetTradeCountsInflux(exchange, starttime, endtime, s.config.BinSize)
+	if err != nil {
+		return err
+	}
+
+	for pair, pairCounts := range counts {
+		for _, gap := range DetectGaps(pairCounts, s.config.BinSize, s.config.MinExpectedTrades) {
+			recorded, err := s.relDB.GetTradeGaps(exchange, pair, gap.StartTime, gap.EndTime)
+			if err != nil {
+				return err
+			}
+			duration := gap.EndTime.Sub(gap.StartTime)
+			for _, r := range recorded {
+				if r.EndTime.After(gap.StartTime) {
+					gap.StartTime = r.EndTime
+				}
+			}
+			if !gap.EndTime.After(gap.StartTime) {
+				continue
+			}
+			gap.ExpectedNumTrades *= float64(gap.EndTime.Sub(gap.StartTime)) / float64(duration)
+			if gap.ExpectedNumTrades < s.config.MinExpectedTrades {
+				continue
+			}
+
+			gap.Exchange = exchange
+			gap.Pair = pair
+			log.Infof("detected gap of %s on %s from %v to %v with %.1f expected trades", pair, exchange, gap.StartTime, gap.EndTime, gap.ExpectedNumTrades)
+			if err := s.relDB.SetTradeGap(gap); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// BackfillGaps backfills all open gaps and merges their trades into the trades table.
+// Gaps whose backfill fails are marked as failed and not retried.
+func (s *TradeGapService) BackfillGaps(ctx context.Context) error {
+	gaps, err := s.relDB.GetTradeGapsByStatus(dia.TradeGapOpen, 100)
+	if err != nil {
+		return err
+	}
+	for _, gap := range gaps {
+		status := dia.TradeGapMerged
+		errorMessage := ""
+		if err := s.backfillGap(ctx, gap); err != nil {
+			if errors.Is(err, context.Canceled) {
+				return err
+			}
+			log.Errorf("backfill gap %d of %s on %s: %v", gap.ID, gap.Pair, gap.Exchange, err)
+			status = dia.TradeGapFailed
+			errorMessage = err.Error()
+		}
+		if err := s.relDB.UpdateTradeGapStatus(gap.ID, status, errorMessage); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// backfillGap writes the trades of @gap to the temporary table and merges them into the trades table.
+func (s *TradeGapService) backfillGap(ctx context.Context, gap dia.TradeGap) error {
+	backfill, ok := s.backfillers[gap.Exchange]
+	if !ok {
+		return fmt.Errorf("no backfiller for exchange %s", gap.Exchange)
+	}
+
+	chanTrades := make(chan *dia.Trade)
+	errs := make(chan error, 1)
+	go func() {
+		errs <- backfill(ctx, gap, chanTrades)
+		close(chanTrades)
+	}()
+	numTrades := 0
+	for t := range chanTrades {
+		s.estimateUSDPrice(t)
+		if err := s.datastore.SaveTradeInfluxToTable(t, s.config.TmpTable); err != nil {
+			log.Error("save trade: ", err)
+			continue
+		}
+		numTrades++
+	}
+	if err := s.datastore.Flush(); err != nil {
+		return err
+	}
+
+	// CopyInfluxMeasurements and DeleteInfluxMeasurement take the time range (timeInit, timeFinal].
+	timeInit := gap.StartTime.Add(-time.Nanosecond)
+	timeFinal := gap.EndTime.Add(-time.Nanosecond)
+	if err := <-errs; err != nil {
+		// Partially backfilled trades must not be merged along with the next gap.
+		if deleteErr := s.datastore.DeleteInfluxMeasurement(s.config.InfluxDB, s.config.TmpTable, timeInit, timeFinal); deleteErr != nil {
+			log.Error("delete partially backfilled trades: ", deleteErr)
+		}
+		return err
+	}
+	if err := s.relDB.UpdateTradeGapStatus(gap.ID, dia.TradeGapBackfilled, ""); err != nil {
+		return err
+	}
+
+	numCopied, err := s.datastore.CopyInfluxMeasurements(s.config.InfluxDB, s.config.InfluxDB, s.config.TmpTable, s.config.TradesTable, timeInit, timeFinal)
+	if err != nil {
+		return err
+	}
+	if err := s.datastore.DeleteInfluxMeasurement(s.config.InfluxDB, s.config.TmpTable, timeInit, timeFinal); err != nil {
+		return err
+	}
+	log.Infof("backfilled gap %d of %s on %s: merged %d of %d trades", gap.ID, gap.Pair, gap.Exchange, numCopied, numTrades)
+	return nil
+}
+
+// estimateUSDPrice sets the USD price of a verified trade from the price of its base token at the time of the trade.
+func (s *TradeGapService) estimateUSDPrice(t *dia.Trade) {
+	if !t.VerifiedPair || t.BaseToken.Address == "" {
+		return
+	}
+	price, err := s.datastore.GetAssetPriceUSD(t.BaseToken, t.Time)
+	if err != nil {
+		log.Warnf("get price of base token %s at %v: %v", t.BaseToken.Symbol, t.Time, err)
+		return
+	}
+	t.EstimatedUSDPrice = t.Price * price
+}
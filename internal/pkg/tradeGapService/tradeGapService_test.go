@@ -0,0 +1,95 @@
+package tradeGapService
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestDetectGaps(t *testing.T) {
+	starttime := time.Unix(1640995200, 0)
+	var counts []dia.TradeCount
+	for i, count := range []int{4, 4, 0, 0, 0, 4, 0, 4, 4, 0} {
+		counts = append(counts, dia.TradeCount{Time: starttime.Add(time.Duration(i) * time.Minute), Count: count})
+	}
+
+	// 2.4 trades are expected per bin, such that only the runs of 3 bins and more are gaps.
+	gaps := DetectGaps(counts, time.Minute, 5)
+	if len(gaps) != 1 {
+		t.Fatalf("expected a single gap, got %v", gaps)
+	}
+	if !gaps[0].StartTime.Equal(starttime.Add(2*time.Minute)) || !gaps[0].EndTime.Equal(starttime.Add(5*time.Minute)) {
+		t.Errorf("expected gap from minute 2 to 5, got %v", gaps[0])
+	}
+
+	if gaps = DetectGaps(counts, time.Minute, 2); len(gaps) != 3 || !gaps[2].EndTime.Equal(starttime.Add(10*time.Minute)) {
+		t.Errorf("expected 3 gaps including the last bin, got %v", gaps)
+	}
+	if gaps = DetectGaps(counts[2:5], time.Minute, 0); len(gaps) != 0 {
+		t.Errorf("expected no gaps without trades, got %v", gaps)
+	}
+}
+
+func TestTradeGapServiceBackfill(t *testing.T) {
+	datastore := models.NewMemoryDataStore()
+	relDB := models.NewMemoryRelDataStore()
+	now := time.Unix(1640995200, 0)
+	config := Config{
+		Lookback:          time.Hour,
+		BinSize:           time.Minute,
+		MinExpectedTrades: 10,
+		InfluxDB:          "dia",
+		TradesTable:       "trades",
+		TmpTable:          "tradesTmp",
+	}
+
+	// One trade per minute except for a gap from minute 20 to 40.
+	gapStart := now.Add(-40 * time.Minute)
+	gapEnd := now.Add(-20 * time.Minute)
+	for tt := now.Add(-time.Hour); tt.Before(now); tt = tt.Add(time.Minute) {
+		if !tt.Before(gapStart) && tt.Before(gapEnd) {
+			continue
+		}
+		if err := datastore.SaveTradeInflux(&dia.Trade{Pair: "XBTUSD", Source: dia.KrakenExchange, Price: 1, Time: tt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backfill := func(ctx context.Context, gap dia.TradeGap, chanTrades chan<- *dia.Trade) error {
+		for tt := gap.StartTime; tt.Before(gap.EndTime); tt = tt.Add(time.Minute) {
+			chanTrades <- &dia.Trade{Pair: gap.Pair, Source: gap.Exchange, Price: 2, Time: tt}
+		}
+		return nil
+	}
+	s := NewTradeGapService(datastore, relDB, config, map[string]Backfiller{dia.KrakenExchange: backfill})
+
+	for i := 0; i < 2; i++ {
+		if err := s.RecordGaps(dia.KrakenExchange, now); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gaps, err := relDB.GetTradeGapsByStatus(dia.TradeGapOpen, 10)
+	if err != nil || len(gaps) != 1 {
+		t.Fatalf("expected a single open gap, got %v (%v)", gaps, err)
+	}
+	if !gaps[0].StartTime.Equal(gapStart) || !gaps[0].EndTime.Equal(gapEnd) || gaps[0].Pair != "XBTUSD" {
+		t.Errorf("unexpected gap %v", gaps[0])
+	}
+
+	if err = s.BackfillGaps(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if gaps, _ = relDB.GetTradeGapsByStatus(dia.TradeGapMerged, 10); len(gaps) != 1 {
+		t.Errorf("expected merged gap, got %v", gaps)
+	}
+	trades, err := datastore.GetOldTradesFromInflux("trades", dia.KrakenExchange, false, gapStart, gapEnd)
+	if err != nil || len(trades) != 20 || trades[0].Price != 2 {
+		t.Errorf("expected 20 backfilled trades in the gap, got %d (%v)", len(trades), err)
+	}
+	if tmp, _ := datastore.GetOldTradesFromInflux("tradesTmp", "", false, gapStart, gapEnd); len(tmp) != 0 {
+		t.Errorf("expected empty temporary table, got %d trades", len(tmp))
+	}
+}
@@ -0,0 +1,125 @@
+package tradesBlockService
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// PriceCache caches USD prices of assets per time bucket. Entries expire after a TTL and the
+// least recently used entries are evicted once the cache exceeds its maximal size.
+type PriceCache struct {
+	mu         sync.Mutex
+	ttl        time.Duration
+	bucketSize time.Duration
+	maxSize    int
+	entries    map[priceCacheKey]*list.Element
+	// most recently used entries are in front
+	order *list.List
+	stats PriceCacheStats
+	now   func() time.Time
+}
+
+type priceCacheKey struct {
+	Asset  dia.Asset
+	Bucket int64
+}
+
+type priceCacheEntry struct {
+	key     priceCacheKey
+	price   float64
+	expires time.Time
+}
+
+// PriceCacheStats counts the lookups and removals of a PriceCache.
+type PriceCacheStats struct {
+	Hits        int64
+	Misses      int64
+	Expirations int64
+	Evictions   int64
+	Size        int
+}
+
+// NewPriceCache returns a cache which stores prices in buckets of size @bucketSize for at most @ttl.
+// A non-positive @bucketSize puts all prices of an asset into the same bucket, a non-positive @maxSize
+// disables eviction.
+func NewPriceCache(ttl time.Duration, bucketSize time.Duration, maxSize int) *PriceCache {
+	return &PriceCache{
+		ttl:        ttl,
+		bucketSize: bucketSize,
+		maxSize:    maxSize,
+		entries:    make(map[priceCacheKey]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+func (pc *PriceCache) key(asset dia.Asset, timestamp time.Time) priceCacheKey {
+	if pc.bucketSize <= 0 {
+		return priceCacheKey{Asset: asset}
+	}
+	return priceCacheKey{Asset: asset, Bucket: timestamp.UnixNano() / int64(pc.bucketSize)}
+}
+
+// Get returns the price of @asset in the bucket of @timestamp.
+func (pc *PriceCache) Get(asset dia.Asset, timestamp time.Time) (float64, bool) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	elem, ok := pc.entries[pc.key(asset, timestamp)]
+	if !ok {
+		pc.stats.Misses++
+		return 0, false
+	}
+	entry := elem.Value.(*priceCacheEntry)
+	if !pc.now().Before(entry.expires) {
+		pc.remove(elem)
+		pc.stats.Expirations++
+		pc.stats.Misses++
+		return 0, false
+	}
+	pc.order.MoveToFront(elem)
+	pc.stats.Hits++
+	return entry.price, true
+}
+
+// Set stores @price of @asset in the bucket of @timestamp.
+func (pc *PriceCache) Set(asset dia.Asset, timestamp time.Time, price float64) {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	key := pc.key(asset, timestamp)
+	expires := pc.now().Add(pc.ttl)
+	if elem, ok := pc.entries[key]; ok {
+		entry := elem.Value.(*priceCacheEntry)
+		entry.price = price
+		entry.expires = expires
+		pc.order.MoveToFront(elem)
+		return
+	}
+	pc.entries[key] = pc.order.PushFront(&priceCacheEntry{key: key, price: price, expires: expires})
+	for pc.maxSize > 0 && pc.order.Len() > pc.maxSize {
+		pc.remove(pc.order.Back())
+		pc.stats.Evictions++
+	}
+}
+
+func (pc *PriceCache) remove(elem *list.Element) {
+	pc.order.Remove(elem)
+	delete(pc.entries, elem.Value.(*priceCacheEntry).key)
+}
+
+// Stats returns the hit and miss counts of the cache since its creation.
+func (pc *PriceCache) Stats() PriceCacheStats {
+	pc.mu.Lock()
+	defer pc.mu.Unlock()
+	stats := pc.stats
+	stats.Size = pc.order.Len()
+	return stats
+}
+
+// logStats logs the hit and miss counts of the cache.
+func (pc *PriceCache) logStats() {
+	stats := pc.Stats()
+	log.Infof("price cache: %d hits, %d misses, %d expired, %d evicted, %d entries", stats.Hits, stats.Misses, stats.Expirations, stats.Evictions, stats.Size)
+}
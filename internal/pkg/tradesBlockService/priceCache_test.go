@@ -0,0 +1,68 @@
+package tradesBlockService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestPriceCache(t *testing.T) {
+	now := time.Unix(1651406400, 0)
+	pc := NewPriceCache(time.Minute, time.Hour, 2)
+	pc.now = func() time.Time { return now }
+
+	pc.Set(eth, now, 2000)
+	if price, ok := pc.Get(eth, now.Add(time.Minute)); !ok || price != 2000 {
+		t.Errorf("expected cached price 2000 in same bucket, got %v (%v)", price, ok)
+	}
+	if _, ok := pc.Get(eth, now.Add(time.Hour)); ok {
+		t.Error("price must not be shared across buckets")
+	}
+
+	now = now.Add(time.Minute)
+	if _, ok := pc.Get(eth, now); ok {
+		t.Error("price should have expired")
+	}
+
+	pc.Set(eth, now, 2000)
+	pc.Set(usdt, now, 1)
+	pc.Get(eth, now)
+	pc.Set(eth, now.Add(time.Hour), 2100)
+	if _, ok := pc.Get(usdt, now); ok {
+		t.Error("least recently used price should have been evicted")
+	}
+
+	stats := pc.Stats()
+	expected := PriceCacheStats{Hits: 2, Misses: 3, Expirations: 1, Evictions: 1, Size: 2}
+	if stats != expected {
+		t.Errorf("expected stats %v, got %v", expected, stats)
+	}
+}
+
+func TestTradesBlockServiceHistoricalPrices(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	for i, price := range []float64{1, 0.5} {
+		if err := ds.SetAssetPriceUSD(usdt, price, t0.Add(time.Duration(i)*dia.BlockSizeSeconds*time.Second)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{
+		PriceCache: NewPriceCache(time.Hour, dia.BlockSizeSeconds*time.Second, 10),
+	})
+
+	go func() {
+		for i := 0; i < 3; i++ {
+			trade := makeTrade(dia.KrakenExchange, 2000, t0.Add(time.Duration(i)*(dia.BlockSizeSeconds+1)*time.Second))
+			s.ProcessTrade(&trade)
+		}
+	}()
+	for _, expected := range []float64{2000, 1000} {
+		tb := <-s.Channel()
+		if price := tb.TradesBlockData.Trades[0].EstimatedUSDPrice; price != expected {
+			t.Errorf("expected estimated price %v, got %v", expected, price)
+		}
+	}
+}
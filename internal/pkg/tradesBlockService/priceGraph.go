@@ -0,0 +1,302 @@
+package tradesBlockService
+
+import (
+	"container/heap"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const (
+	defaultPriceGraphWindow = 24 * time.Hour
+	defaultPriceGraphHops   = 4
+	// Liquidity in USD of the least liquid pair of a path at which the path has confidence 0.5.
+	defaultHalfConfidenceLiquidity = 100000
+	// Confidence is multiplied by hopConfidenceDecay for every hop after the first one.
+	hopConfidenceDecay = 0.9
+)
+
+// DefaultPriceAnchors are the assets with USD quotations from which prices are derived along the graph.
+var DefaultPriceAnchors = []dia.Asset{
+	{Blockchain: dia.ETHEREUM, Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Symbol: "USDC"},
+	{Blockchain: dia.ETHEREUM, Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Symbol: "USDT"},
+	{Blockchain: dia.ETHEREUM, Address: "0x0000000000000000000000000000000000000000", Symbol: "ETH"},
+	{Blockchain: dia.BITCOIN, Address: "0x0000000000000000000000000000000000000000", Symbol: "BTC"},
+}
+
+// PriceGraphConfig configures the price derivation along the price graph.
+type PriceGraphConfig struct {
+	// Anchors are the assets whose USD price is read from their quotation.
+	Anchors []dia.Asset
+	// Window is the time range of trades from which pair prices and volumes are taken.
+	Window time.Duration
+	// MaxHops is the maximal number of pairs between an anchor and the priced asset.
+	MaxHops int
+	// HalfConfidenceLiquidity is the USD liquidity of the least liquid pair of a path at which its confidence is 0.5.
+	HalfConfidenceLiquidity float64
+	// MinConfidence is the minimal confidence of a path whose price is used.
+	MinConfidence float64
+}
+
+// DefaultPriceGraphConfig returns a config with the default anchors.
+func DefaultPriceGraphConfig() PriceGraphConfig {
+	return PriceGraphConfig{
+		Anchors:                 DefaultPriceAnchors,
+		Window:                  defaultPriceGraphWindow,
+		MaxHops:                 defaultPriceGraphHops,
+		HalfConfidenceLiquidity: defaultHalfConfidenceLiquidity,
+	}
+}
+
+// PriceGraph is a graph of assets connected by the pairs they are traded in. Assets without a USD
+// quotation are priced along the most liquid path of pairs from an anchor asset.
+// The liquidity of a pair is the traded volume in the window plus the liquidity in DEX pools.
+type PriceGraph struct {
+	config PriceGraphConfig
+	mu     sync.Mutex
+	// edges[a][b] holds the price of b in units of a, along with the volume and pool liquidity of a.
+	edges map[dia.Asset]map[dia.Asset]*priceEdge
+	// full asset information such as symbols for the assets of price paths
+	assets map[dia.Asset]dia.Asset
+}
+
+type priceEdge struct {
+	price     float64
+	priceTime time.Time
+	volumes   []timedVolume
+	// liquidity of the edge's source asset in all pools containing both assets
+	poolLiquidity float64
+}
+
+type timedVolume struct {
+	volume float64
+	time   time.Time
+}
+
+// NewPriceGraph returns an empty price graph. Zero values in @config are replaced by the defaults.
+func NewPriceGraph(config PriceGraphConfig) *PriceGraph {
+	if len(config.Anchors) == 0 {
+		config.Anchors = DefaultPriceAnchors
+	}
+	if config.Window <= 0 {
+		config.Window = defaultPriceGraphWindow
+	}
+	if config.MaxHops <= 0 {
+		config.MaxHops = defaultPriceGraphHops
+	}
+	if config.HalfConfidenceLiquidity <= 0 {
+		config.HalfConfidenceLiquidity = defaultHalfConfidenceLiquidity
+	}
+	pg := &PriceGraph{
+		config: config,
+		edges:  make(map[dia.Asset]map[dia.Asset]*priceEdge),
+		assets: make(map[dia.Asset]dia.Asset),
+	}
+	for _, anchor := range config.Anchors {
+		pg.assets[graphKey(anchor)] = anchor
+	}
+	return pg
+}
+
+// graphKey strips all fields but blockchain and address, which uniquely identify an asset.
+func graphKey(asset dia.Asset) dia.Asset {
+	return dia.Asset{Blockchain: asset.Blockchain, Address: asset.Address}
+}
+
+// edge returns the edge from @a to @b and creates it if necessary. It must be called with pg.mu held.
+func (pg *PriceGraph) edge(a dia.Asset, b dia.Asset) *priceEdge {
+	if _, ok := pg.edges[a]; !ok {
+		pg.edges[a] = make(map[dia.Asset]*priceEdge)
+	}
+	if _, ok := pg.edges[a][b]; !ok {
+		pg.edges[a][b] = &priceEdge{}
+	}
+	return pg.edges[a][b]
+}
+
+// AddTrade updates the pair of the trade @t with its price and volume.
+func (pg *PriceGraph) AddTrade(t dia.Trade) {
+	if t.Price <= 0 || t.BaseToken.Address == "" || t.QuoteToken.Address == "" {
+		return
+	}
+	base := graphKey(t.BaseToken)
+	quote := graphKey(t.QuoteToken)
+	if base == quote {
+		return
+	}
+	volume := math.Abs(t.Volume)
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	pg.assets[base] = t.BaseToken
+	pg.assets[quote] = t.QuoteToken
+	// The trade price is the price of the quote token in units of the base token.
+	pg.edge(base, quote).update(t.Price, volume*t.Price, t.Time, pg.config.Window)
+	pg.edge(quote, base).update(1/t.Price, volume, t.Time, pg.config.Window)
+}
+
+// update sets the price of the edge and adds @volume of its source asset.
+// Volumes older than @window w.r.t. @timestamp are removed.
+func (e *priceEdge) update(price float64, volume float64, timestamp time.Time, window time.Duration) {
+	if !timestamp.Before(e.priceTime) {
+		e.price = price
+		e.priceTime = timestamp
+	}
+	e.volumes = append(e.volumes, timedVolume{volume: volume, time: timestamp})
+	i := 0
+	for i < len(e.volumes) && timestamp.Sub(e.volumes[i].time) > window {
+		i++
+	}
+	e.volumes = e.volumes[i:]
+}
+
+// volume returns the volume of the edge's source asset in the window before @timestamp.
+func (e *priceEdge) volume(timestamp time.Time, window time.Duration) (volume float64) {
+	for _, v := range e.volumes {
+		if !v.time.After(timestamp) && timestamp.Sub(v.time) <= window {
+			volume += v.volume
+		}
+	}
+	return
+}
+
+// SetPools replaces the pool liquidity of all pairs by the liquidity in @pools.
+// Pools only add liquidity to pairs, their prices are taken from trades.
+func (pg *PriceGraph) SetPools(pools []dia.Pool) {
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	for _, neighbours := range pg.edges {
+		for _, e := range neighbours {
+			e.poolLiquidity = 0
+		}
+	}
+	for _, pool := range pools {
+		for i, a := range pool.Assetvolumes {
+			for j, b := range pool.Assetvolumes {
+				if i == j || a.Volume <= 0 {
+					continue
+				}
+				pg.edge(graphKey(a.Asset), graphKey(b.Asset)).poolLiquidity += a.Volume
+			}
+		}
+	}
+}
+
+// pathNode is an asset reached from an anchor in the search for the most liquid path.
+type pathNode struct {
+	asset dia.Asset
+	price float64
+	// liquidity in USD of the least liquid pair on the path
+	liquidity float64
+	hops      int
+	previous  *pathNode
+	index     int
+}
+
+// pathQueue is a priority queue of path nodes ordered by descending liquidity.
+type pathQueue []*pathNode
+
+func (pq pathQueue) Len() int           { return len(pq) }
+func (pq pathQueue) Less(i, j int) bool { return pq[i].liquidity > pq[j].liquidity }
+func (pq pathQueue) Swap(i, j int) {
+	pq[i], pq[j] = pq[j], pq[i]
+	pq[i].index = i
+	pq[j].index = j
+}
+func (pq *pathQueue) Push(x interface{}) {
+	node := x.(*pathNode)
+	node.index = len(*pq)
+	*pq = append(*pq, node)
+}
+func (pq *pathQueue) Pop() interface{} {
+	old := *pq
+	node := old[len(old)-1]
+	*pq = old[:len(old)-1]
+	return node
+}
+
+// Price returns the USD price of @asset at @timestamp along the most liquid path from an anchor, i.e. the path whose
+// least liquid pair has the highest USD liquidity. Anchor prices are obtained from @anchorPrice.
+// Only pairs with a trade in the window before @timestamp are used. Paths never pass through the @excluded assets.
+func (pg *PriceGraph) Price(asset dia.Asset, timestamp time.Time, anchorPrice func(dia.Asset, time.Time) (float64, error), excluded ...dia.Asset) (pricePath models.PricePath, ok bool) {
+	target := graphKey(asset)
+	settled := make(map[dia.Asset]bool)
+	for _, e := range excluded {
+		if key := graphKey(e); key != target {
+			settled[key] = true
+		}
+	}
+	queue := &pathQueue{}
+	for _, anchor := range pg.config.Anchors {
+		if settled[graphKey(anchor)] {
+			continue
+		}
+		price, err := anchorPrice(anchor, timestamp)
+		if err != nil || price <= 0 {
+			log.Debugf("no price for anchor %s: %v", anchor.Symbol, err)
+			continue
+		}
+		heap.Push(queue, &pathNode{asset: graphKey(anchor), price: price, liquidity: math.Inf(1)})
+	}
+
+	pg.mu.Lock()
+	defer pg.mu.Unlock()
+	for queue.Len() > 0 {
+		node := heap.Pop(queue).(*pathNode)
+		if settled[node.asset] {
+			continue
+		}
+		settled[node.asset] = true
+		if node.asset == target {
+			if node.previous == nil {
+				// Anchors are priced by their quotation.
+				return
+			}
+			pricePath = pg.pricePath(asset, node, timestamp)
+			return pricePath, pricePath.Confidence >= pg.config.MinConfidence
+		}
+		if node.hops >= pg.config.MaxHops {
+			continue
+		}
+		for neighbour, e := range pg.edges[node.asset] {
+			if settled[neighbour] || e.price <= 0 || e.priceTime.After(timestamp) || timestamp.Sub(e.priceTime) > pg.config.Window {
+				continue
+			}
+			liquidity := (e.volume(timestamp, pg.config.Window) + e.poolLiquidity) * node.price
+			if liquidity <= 0 {
+				continue
+			}
+			heap.Push(queue, &pathNode{
+				asset:     neighbour,
+				price:     node.price * e.price,
+				liquidity: math.Min(node.liquidity, liquidity),
+				hops:      node.hops + 1,
+				previous:  node,
+			})
+		}
+	}
+	return
+}
+
+// pricePath returns the price path ending in @node. It must be called with pg.mu held.
+func (pg *PriceGraph) pricePath(asset dia.Asset, node *pathNode, timestamp time.Time) models.PricePath {
+	var path []dia.Asset
+	for n := node; n != nil; n = n.previous {
+		pathAsset, ok := pg.assets[n.asset]
+		if !ok {
+			pathAsset = n.asset
+		}
+		path = append([]dia.Asset{pathAsset}, path...)
+	}
+	confidence := node.liquidity / (node.liquidity + pg.config.HalfConfidenceLiquidity) * math.Pow(hopConfidenceDecay, float64(node.hops-1))
+	return models.PricePath{
+		Asset:      asset,
+		Path:       path,
+		Price:      node.price,
+		Confidence: confidence,
+		Time:       timestamp,
+	}
+}
@@ -0,0 +1,152 @@
+package tradesBlockService
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+var (
+	weth = dia.Asset{Symbol: "WETH", Address: "0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2", Blockchain: dia.ETHEREUM}
+	tok  = dia.Asset{Symbol: "TOK", Address: "0x0000000000000000000000000000000000000001", Blockchain: dia.ETHEREUM}
+)
+
+func makePairTrade(base dia.Asset, quote dia.Asset, price float64, volume float64, timestamp time.Time) dia.Trade {
+	return dia.Trade{
+		Symbol:       quote.Symbol,
+		Pair:         quote.Symbol + "-" + base.Symbol,
+		QuoteToken:   quote,
+		BaseToken:    base,
+		Price:        price,
+		Volume:       volume,
+		Time:         timestamp,
+		Source:       dia.UniswapExchange,
+		VerifiedPair: true,
+	}
+}
+
+func TestPriceGraph(t *testing.T) {
+	t0 := time.Unix(1651406400, 0)
+	anchorPrice := func(asset dia.Asset, timestamp time.Time) (float64, error) {
+		if asset == usdt {
+			return 1, nil
+		}
+		return 0, errors.New("no quotation")
+	}
+	pg := NewPriceGraph(PriceGraphConfig{Anchors: []dia.Asset{usdt}, MaxHops: 2})
+
+	// TOK is traded against WETH, which is traded against USDT.
+	pg.AddTrade(makePairTrade(usdt, weth, 2000, 10, t0))
+	pg.AddTrade(makePairTrade(weth, tok, 0.001, 100, t0))
+
+	pricePath, ok := pg.Price(tok, t0.Add(time.Minute), anchorPrice)
+	if !ok {
+		t.Fatal("expected price along path")
+	}
+	if math.Abs(pricePath.Price-2) > 1e-9 {
+		t.Errorf("expected price 2, got %v", pricePath.Price)
+	}
+	if len(pricePath.Path) != 3 || pricePath.Path[0] != usdt || pricePath.Path[1] != weth || pricePath.Path[2] != tok {
+		t.Errorf("unexpected path %v", pricePath.Path)
+	}
+	// The least liquid pair is TOK-WETH with a volume of 0.1 WETH, i.e. 200 USD.
+	expectedConfidence := 200.0 / (200 + defaultHalfConfidenceLiquidity) * hopConfidenceDecay
+	if math.Abs(pricePath.Confidence-expectedConfidence) > 1e-9 {
+		t.Errorf("expected confidence %v, got %v", expectedConfidence, pricePath.Confidence)
+	}
+
+	// Pool liquidity makes a direct pair more liquid than the path via WETH.
+	pg.AddTrade(makePairTrade(usdt, tok, 2.5, 1, t0))
+	var pool dia.Pool
+	pool.Assetvolumes = append(pool.Assetvolumes, struct {
+		Asset  dia.Asset
+		Volume float64
+	}{Asset: usdt, Volume: 1e6}, struct {
+		Asset  dia.Asset
+		Volume float64
+	}{Asset: tok, Volume: 4e5})
+	pg.SetPools([]dia.Pool{pool})
+	pricePath, ok = pg.Price(tok, t0.Add(time.Minute), anchorPrice)
+	if !ok || pricePath.Price != 2.5 || len(pricePath.Path) != 2 {
+		t.Errorf("expected direct price 2.5, got %v along %v", pricePath.Price, pricePath.Path)
+	}
+
+	if _, ok := pg.Price(tok, t0.Add(25*time.Hour), anchorPrice); ok {
+		t.Error("pairs without trades in the window must not be used")
+	}
+	if _, ok := pg.Price(usdt, t0, anchorPrice); ok {
+		t.Error("anchors must not be priced along the graph")
+	}
+	pricePath, ok = pg.Price(tok, t0.Add(time.Minute), anchorPrice, usdt)
+	if ok {
+		t.Errorf("excluded anchor must not be used, got %v along %v", pricePath.Price, pricePath.Path)
+	}
+}
+
+func TestTradesBlockServicePriceGraph(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	if err := ds.SetAssetPriceUSD(usdt, 1, t0); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{
+		PriceGraph: NewPriceGraph(PriceGraphConfig{Anchors: []dia.Asset{usdt}}),
+	})
+
+	go func() {
+		trades := []dia.Trade{
+			makePairTrade(usdt, weth, 2000, 10, t0),
+			// WETH has no quotation and is priced along the graph.
+			makePairTrade(weth, tok, 0.001, 100, t0.Add(time.Second)),
+			makePairTrade(usdt, weth, 2000, 10, t0.Add((dia.BlockSizeSeconds+1)*time.Second)),
+		}
+		for i := range trades {
+			s.ProcessTrade(&trades[i])
+		}
+	}()
+	tb := <-s.Channel()
+	if len(tb.TradesBlockData.Trades) != 2 {
+		t.Fatalf("expected 2 trades, got %d", len(tb.TradesBlockData.Trades))
+	}
+	if price := tb.TradesBlockData.Trades[1].EstimatedUSDPrice; math.Abs(price-2) > 1e-9 {
+		t.Errorf("expected estimated price 2, got %v", price)
+	}
+}
+
+func TestTradesBlockServicePriceGraphCircular(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	if err := ds.SetAssetPriceUSD(usdt, 1, t0); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{
+		PriceGraph: NewPriceGraph(PriceGraphConfig{Anchors: []dia.Asset{usdt}}),
+	})
+
+	go func() {
+		trades := []dia.Trade{
+			makePairTrade(usdt, weth, 2000, 10, t0),
+			// USDT quoted in TOK, which has no quotation. Pricing TOK by the USDT-TOK pair would
+			// estimate the price of USDT by itself, both for the first and the following trades.
+			makePairTrade(tok, usdt, 0.5, 100, t0.Add(time.Second)),
+			makePairTrade(tok, usdt, 0.4, 100, t0.Add(2*time.Second)),
+			makePairTrade(usdt, weth, 2000, 10, t0.Add((dia.BlockSizeSeconds+1)*time.Second)),
+		}
+		for i := range trades {
+			s.ProcessTrade(&trades[i])
+		}
+	}()
+	tb := <-s.Channel()
+	for _, trade := range tb.TradesBlockData.Trades {
+		if trade.QuoteToken == usdt {
+			t.Errorf("trade %s must not be priced by its own quote token, got %v", trade.Pair, trade.EstimatedUSDPrice)
+		}
+	}
+	if len(tb.TradesBlockData.Trades) != 1 {
+		t.Fatalf("expected 1 trade, got %d", len(tb.TradesBlockData.Trades))
+	}
+}
@@ -0,0 +1,375 @@
+package tradesBlockService
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+const (
+	// Price sanity config is read from config/tradesBlockService/priceSanity.json.
+	configFilePriceSanity = "tradesBlockService/priceSanity"
+
+	// Actions on trades deviating from the reference price by more than the tolerance.
+	SanityActionFlag = "flag"
+	SanityActionDrop = "drop"
+
+	// Sources of reference prices.
+	ReferenceQuotation = "quotation"
+	ReferenceForeign   = "foreign"
+	ReferenceExchanges = "exchanges"
+
+	defaultSanityTolerance = 0.1
+	// Reference quotations are refetched once they are older than referenceCacheSeconds w.r.t. trade time.
+	referenceCacheSeconds = 60
+)
+
+// PriceSanityConfig configures the check of trades' estimated USD prices against a reference price.
+type PriceSanityConfig struct {
+	// Reference is one of "quotation", "foreign" or "exchanges".
+	Reference string `json:"Reference"`
+	// ForeignSource is the foreign scraper used as reference, such as "CoinGecko".
+	ForeignSource string `json:"ForeignSource"`
+	// Reference prices older than MaxAgeSeconds w.r.t. the trade are ignored. 0 means no limit.
+	MaxAgeSeconds int64 `json:"MaxAgeSeconds"`
+	// MinExchanges is the minimal number of other exchanges the median is computed from.
+	MinExchanges int `json:"MinExchanges"`
+	// Tolerance is the maximal relative deviation from the reference price.
+	Tolerance float64 `json:"Tolerance"`
+	// Action is either "flag" or "drop".
+	Action          string           `json:"Action"`
+	AssetTolerances []AssetTolerance `json:"AssetTolerances"`
+}
+
+// AssetTolerance overrides the default tolerance for the asset with @Address on @Blockchain.
+type AssetTolerance struct {
+	Blockchain string  `json:"Blockchain"`
+	Address    string  `json:"Address"`
+	Tolerance  float64 `json:"Tolerance"`
+}
+
+// PriceReference returns reference prices for the quote tokens of trades.
+type PriceReference interface {
+	// ReferencePrice returns the reference USD price for the quote token of @t.
+	// If @ok is false no reference is available and @t passes the check.
+	ReferencePrice(t dia.Trade) (price float64, ok bool)
+	// Update is called with every trade passing the check.
+	Update(t dia.Trade)
+}
+
+// SanityCount is the number of trades on @Pair from @Exchange which failed the price sanity check.
+type SanityCount struct {
+	Exchange string
+	Pair     string
+	Rejected int
+	Flagged  int
+}
+
+type sanityKey struct {
+	Exchange string
+	Pair     string
+}
+
+// PriceSanityCheck compares the estimated USD price of trades to a reference price.
+type PriceSanityCheck struct {
+	reference       PriceReference
+	action          string
+	tolerance       float64
+	assetTolerances map[dia.Asset]float64
+	mu              sync.Mutex
+	// counts since the start of the service and since the last finalised tradesBlock
+	counts      map[sanityKey]*SanityCount
+	blockCounts map[sanityKey]*SanityCount
+}
+
+// NewPriceSanityCheck returns a check of trades against @reference with the tolerances and action from @config.
+func NewPriceSanityCheck(reference PriceReference, config PriceSanityConfig) *PriceSanityCheck {
+	c := &PriceSanityCheck{
+		reference:       reference,
+		action:          config.Action,
+		tolerance:       config.Tolerance,
+		assetTolerances: make(map[dia.Asset]float64),
+		counts:          make(map[sanityKey]*SanityCount),
+		blockCounts:     make(map[sanityKey]*SanityCount),
+	}
+	if c.action != SanityActionDrop {
+		c.action = SanityActionFlag
+	}
+	if c.tolerance <= 0 {
+		c.tolerance = defaultSanityTolerance
+	}
+	for _, at := range config.AssetTolerances {
+		c.assetTolerances[dia.Asset{Blockchain: at.Blockchain, Address: at.Address}] = at.Tolerance
+	}
+	return c
+}
+
+// NewPriceSanityCheckFromConfig returns a check with the reference given by @config.Reference.
+// Quotations are read from @datastore at trade time if @historical is true.
+func NewPriceSanityCheckFromConfig(datastore models.Datastore, config PriceSanityConfig, historical bool) (*PriceSanityCheck, error) {
+	maxAge := time.Duration(config.MaxAgeSeconds) * time.Second
+	var reference PriceReference
+	switch config.Reference {
+	case ReferenceQuotation:
+		reference = NewQuotationReference(datastore, maxAge, historical)
+	case ReferenceForeign:
+		if config.ForeignSource == "" {
+			return nil, errors.New("no foreign source given for foreign reference")
+		}
+		reference = NewForeignReference(datastore, config.ForeignSource, maxAge)
+	case ReferenceExchanges:
+		reference = NewExchangesReference(config.MinExchanges, maxAge)
+	default:
+		return nil, errors.New("unknown reference " + config.Reference)
+	}
+	return NewPriceSanityCheck(reference, config), nil
+}
+
+// GetPriceSanityConfigFromConfig returns the price sanity config from the config file.
+func GetPriceSanityConfigFromConfig() (config PriceSanityConfig, err error) {
+	content, err := configCollectors.ReadJSONFromConfig(configFilePriceSanity)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(content, &config)
+	return
+}
+
+// toleranceFor returns the tolerance for @asset.
+func (c *PriceSanityCheck) toleranceFor(asset dia.Asset) float64 {
+	if tolerance, ok := c.assetTolerances[dia.Asset{Blockchain: asset.Blockchain, Address: asset.Address}]; ok {
+		return tolerance
+	}
+	return c.tolerance
+}
+
+// check returns false if @t deviates too much from the reference and is to be dropped.
+// With action flag, deviating trades are kept and marked through PriceFlagged.
+func (c *PriceSanityCheck) check(t *dia.Trade) bool {
+	reference, ok := c.reference.ReferencePrice(*t)
+	if !ok || reference <= 0 {
+		c.reference.Update(*t)
+		return true
+	}
+	deviation := math.Abs(t.EstimatedUSDPrice-reference) / reference
+	if deviation <= c.toleranceFor(t.QuoteToken) {
+		c.reference.Update(*t)
+		return true
+	}
+
+	log.Debugf("price %v of %s on %s deviates by %.4f from reference %v", t.EstimatedUSDPrice, t.Pair, t.Source, deviation, reference)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := sanityKey{Exchange: t.Source, Pair: t.Pair}
+	for _, counts := range []map[sanityKey]*SanityCount{c.counts, c.blockCounts} {
+		if _, ok := counts[key]; !ok {
+			counts[key] = &SanityCount{Exchange: t.Source, Pair: t.Pair}
+		}
+		if c.action == SanityActionDrop {
+			counts[key].Rejected++
+		} else {
+			counts[key].Flagged++
+		}
+	}
+	if c.action == SanityActionDrop {
+		return false
+	}
+	t.PriceFlagged = true
+	return true
+}
+
+// Counts returns the number of rejected and flagged trades per exchange and pair since the start,
+// ordered by exchange and pair.
+func (c *PriceSanityCheck) Counts() []SanityCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedCounts(c.counts)
+}
+
+// takeBlockCounts returns the counts since the last call, ordered by exchange and pair, and resets them.
+func (c *PriceSanityCheck) takeBlockCounts() []SanityCount {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	counts := sortedCounts(c.blockCounts)
+	c.blockCounts = make(map[sanityKey]*SanityCount)
+	return counts
+}
+
+func sortedCounts(m map[sanityKey]*SanityCount) (counts []SanityCount) {
+	for _, count := range m {
+		counts = append(counts, *count)
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Exchange != counts[j].Exchange {
+			return counts[i].Exchange < counts[j].Exchange
+		}
+		return counts[i].Pair < counts[j].Pair
+	})
+	return
+}
+
+// logCounts logs the counts of all pairs with failed checks.
+func (c *PriceSanityCheck) logCounts() {
+	for _, count := range c.Counts() {
+		log.Infof("price sanity check on %s -- %s: %d rejected, %d flagged", count.Exchange, count.Pair, count.Rejected, count.Flagged)
+	}
+}
+
+// timedPrice is a reference price along with the time it refers to and the trade time it was fetched at.
+type timedPrice struct {
+	Price     float64
+	Time      time.Time
+	FetchedAt time.Time
+}
+
+// cachedPrice returns the price for @key in @cache if it was fetched less than referenceCacheSeconds
+// before @t. Otherwise, the price is updated by @fetch.
+func cachedPrice(cache map[string]timedPrice, key string, t time.Time, fetch func() (float64, time.Time, error)) timedPrice {
+	if tp, ok := cache[key]; ok && t.Sub(tp.FetchedAt) < referenceCacheSeconds*time.Second && !t.Before(tp.FetchedAt) {
+		return tp
+	}
+	price, timestamp, err := fetch()
+	if err != nil {
+		log.Debugf("fetch reference price for %s: %v", key, err)
+		price = 0
+	}
+	cache[key] = timedPrice{Price: price, Time: timestamp, FetchedAt: t}
+	return cache[key]
+}
+
+// valid returns true if @tp is a price not older than @maxAge w.r.t. @t.
+func (tp timedPrice) valid(t time.Time, maxAge time.Duration) bool {
+	return tp.Price > 0 && (maxAge == 0 || t.Sub(tp.Time) <= maxAge)
+}
+
+// QuotationReference uses the latest asset quotation as reference price.
+type QuotationReference struct {
+	datastore  models.Datastore
+	maxAge     time.Duration
+	historical bool
+	cache      map[string]timedPrice
+}
+
+// NewQuotationReference returns a reference reading asset quotations from @datastore.
+// If @historical is true, the latest quotation before trade time is used.
+func NewQuotationReference(datastore models.Datastore, maxAge time.Duration, historical bool) *QuotationReference {
+	return &QuotationReference{
+		datastore:  datastore,
+		maxAge:     maxAge,
+		historical: historical,
+		cache:      make(map[string]timedPrice),
+	}
+}
+
+// ReferencePrice returns the price of the quote token's latest quotation.
+func (qr *QuotationReference) ReferencePrice(t dia.Trade) (float64, bool) {
+	tp := cachedPrice(qr.cache, t.QuoteToken.Blockchain+"-"+t.QuoteToken.Address, t.Time, func() (float64, time.Time, error) {
+		var (
+			quotation *models.AssetQuotation
+			err       error
+		)
+		if qr.historical {
+			quotation, err = qr.datastore.GetAssetQuotation(t.QuoteToken, t.Time)
+		} else {
+			quotation, err = qr.datastore.GetAssetQuotationCache(t.QuoteToken)
+		}
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		return quotation.Price, quotation.Time, nil
+	})
+	return tp.Price, tp.valid(t.Time, qr.maxAge)
+}
+
+// Update is a no-op, as quotations are computed by the filtersBlockService.
+func (qr *QuotationReference) Update(t dia.Trade) {}
+
+// ForeignReference uses quotations from a foreign scraper as reference price.
+type ForeignReference struct {
+	datastore models.Datastore
+	source    string
+	maxAge    time.Duration
+	cache     map[string]timedPrice
+}
+
+// NewForeignReference returns a reference reading quotations of the foreign scraper @source from @datastore.
+func NewForeignReference(datastore models.Datastore, source string, maxAge time.Duration) *ForeignReference {
+	return &ForeignReference{
+		datastore: datastore,
+		source:    source,
+		maxAge:    maxAge,
+		cache:     make(map[string]timedPrice),
+	}
+}
+
+// ReferencePrice returns the foreign quotation for the trade's symbol at trade time.
+func (fr *ForeignReference) ReferencePrice(t dia.Trade) (float64, bool) {
+	tp := cachedPrice(fr.cache, t.Symbol, t.Time, func() (float64, time.Time, error) {
+		quotation, err := fr.datastore.GetForeignQuotationInflux(t.Symbol, fr.source, t.Time)
+		if err != nil {
+			return 0, time.Time{}, err
+		}
+		return quotation.Price, quotation.Time, nil
+	})
+	return tp.Price, tp.valid(t.Time, fr.maxAge)
+}
+
+// Update is a no-op, as foreign quotations are stored by the foreign scrapers.
+func (fr *ForeignReference) Update(t dia.Trade) {}
+
+// ExchangesReference uses the median of the latest prices of an asset on all other exchanges as reference price.
+type ExchangesReference struct {
+	minExchanges int
+	maxAge       time.Duration
+	// latest prices per asset and exchange
+	prices map[dia.Asset]map[string]timedPrice
+}
+
+// NewExchangesReference returns a reference requiring prices on at least @minExchanges other exchanges.
+func NewExchangesReference(minExchanges int, maxAge time.Duration) *ExchangesReference {
+	if minExchanges < 1 {
+		minExchanges = 1
+	}
+	return &ExchangesReference{
+		minExchanges: minExchanges,
+		maxAge:       maxAge,
+		prices:       make(map[dia.Asset]map[string]timedPrice),
+	}
+}
+
+// ReferencePrice returns the median of the latest prices of the quote token on all exchanges but the trade's.
+func (er *ExchangesReference) ReferencePrice(t dia.Trade) (float64, bool) {
+	var prices []float64
+	for exchange, tp := range er.prices[dia.Asset{Blockchain: t.QuoteToken.Blockchain, Address: t.QuoteToken.Address}] {
+		if exchange != t.Source && tp.valid(t.Time, er.maxAge) {
+			prices = append(prices, tp.Price)
+		}
+	}
+	if len(prices) < er.minExchanges {
+		return 0, false
+	}
+	sort.Float64s(prices)
+	if len(prices)%2 == 0 {
+		return (prices[len(prices)/2-1] + prices[len(prices)/2]) / 2, true
+	}
+	return prices[len(prices)/2], true
+}
+
+// Update stores the price of @t as latest price of the quote token on the trade's exchange.
+func (er *ExchangesReference) Update(t dia.Trade) {
+	asset := dia.Asset{Blockchain: t.QuoteToken.Blockchain, Address: t.QuoteToken.Address}
+	if _, ok := er.prices[asset]; !ok {
+		er.prices[asset] = make(map[string]timedPrice)
+	}
+	if t.Time.Before(er.prices[asset][t.Source].Time) {
+		return
+	}
+	er.prices[asset][t.Source] = timedPrice{Price: t.EstimatedUSDPrice, Time: t.Time}
+}
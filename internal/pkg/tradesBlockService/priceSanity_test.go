@@ -0,0 +1,145 @@
+package tradesBlockService
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+var (
+	eth  = dia.Asset{Symbol: "ETH", Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.ETHEREUM}
+	usdt = dia.Asset{Symbol: "USDT", Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Blockchain: dia.ETHEREUM}
+)
+
+func makeTrade(exchange string, price float64, timestamp time.Time) dia.Trade {
+	return dia.Trade{
+		Symbol:            "ETH",
+		Pair:              "ETH-USDT",
+		QuoteToken:        eth,
+		BaseToken:         usdt,
+		Price:             price,
+		EstimatedUSDPrice: price,
+		Volume:            1,
+		Time:              timestamp,
+		Source:            exchange,
+		VerifiedPair:      true,
+	}
+}
+
+func TestPriceSanityCheckQuotation(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	if err := ds.SetAssetPriceUSD(eth, 2000, t0); err != nil {
+		t.Fatal(err)
+	}
+	config := PriceSanityConfig{
+		Reference:       ReferenceQuotation,
+		MaxAgeSeconds:   600,
+		Tolerance:       0.1,
+		Action:          SanityActionDrop,
+		AssetTolerances: []AssetTolerance{{Blockchain: eth.Blockchain, Address: eth.Address, Tolerance: 0.2}},
+	}
+	check, err := NewPriceSanityCheckFromConfig(ds, config, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		price    float64
+		offset   time.Duration
+		expected bool
+	}{
+		{2100, time.Minute, true},
+		{2300, time.Minute, true},
+		{2500, time.Minute, false},
+		// Quotation is too old to serve as reference.
+		{2500, time.Hour, true},
+	}
+	for i, c := range cases {
+		trade := makeTrade(dia.KrakenExchange, c.price, t0.Add(c.offset))
+		if ok := check.check(&trade); ok != c.expected {
+			t.Errorf("case %d: expected %v, got %v", i, c.expected, ok)
+		}
+	}
+	counts := check.Counts()
+	if len(counts) != 1 || counts[0].Exchange != dia.KrakenExchange || counts[0].Rejected != 1 {
+		t.Errorf("expected one rejected trade on Kraken, got %v", counts)
+	}
+}
+
+func TestPriceSanityCheckExchanges(t *testing.T) {
+	t0 := time.Unix(1651406400, 0)
+	check := NewPriceSanityCheck(NewExchangesReference(2, time.Minute), PriceSanityConfig{Tolerance: 0.05})
+
+	// Without prices on two other exchanges all trades pass.
+	for i, exchange := range []string{dia.KrakenExchange, dia.BinanceExchange} {
+		trade := makeTrade(exchange, 2000+float64(i)*10, t0)
+		if !check.check(&trade) {
+			t.Errorf("trade on %s should pass without reference", exchange)
+		}
+	}
+	trade := makeTrade(dia.UniswapExchange, 2050, t0.Add(time.Second))
+	if !check.check(&trade) || trade.PriceFlagged {
+		t.Error("trade within tolerance of median 2005 should pass")
+	}
+	// Flagged trades are kept and marked.
+	trade = makeTrade(dia.CoinBaseExchange, 2500, t0.Add(time.Second))
+	if !check.check(&trade) || !trade.PriceFlagged {
+		t.Error("flagged trade should pass and be marked")
+	}
+	counts := check.Counts()
+	if len(counts) != 1 || counts[0].Exchange != dia.CoinBaseExchange || counts[0].Flagged != 1 {
+		t.Errorf("expected one flagged trade on CoinBase, got %v", counts)
+	}
+}
+
+func TestTradesBlockServiceDropsOutliers(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	for _, asset := range []dia.Asset{eth, usdt} {
+		price := 1.0
+		if asset == eth {
+			price = 2000
+		}
+		if err := ds.SetAssetPriceUSD(asset, price, t0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check := NewPriceSanityCheck(NewQuotationReference(ds, 0, true), PriceSanityConfig{Tolerance: 0.1, Action: SanityActionDrop})
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{SanityCheck: check})
+
+	go func() {
+		for _, trade := range []dia.Trade{
+			makeTrade(dia.KrakenExchange, 2010, t0.Add(time.Second)),
+			makeTrade(dia.BinanceExchange, 3000, t0.Add(2*time.Second)),
+			makeTrade(dia.KrakenExchange, 1990, t0.Add(3*time.Second)),
+			// finalises the first block
+			makeTrade(dia.KrakenExchange, 2000, t0.Add((dia.BlockSizeSeconds+1)*time.Second)),
+		} {
+			trade := trade
+			s.ProcessTrade(&trade)
+		}
+	}()
+	tb := <-s.Channel()
+	if tb.TradesBlockData.TradesNumber != 2 {
+		t.Errorf("expected 2 trades in block, got %d", tb.TradesBlockData.TradesNumber)
+	}
+	for _, trade := range tb.TradesBlockData.Trades {
+		if trade.Source == dia.BinanceExchange {
+			t.Errorf("outlier on %s not dropped", trade.Source)
+		}
+	}
+	// The rejection is stored with the end of the finalised block.
+	counts, err := ds.GetPriceSanityInflux(dia.BinanceExchange, t0, tb.TradesBlockData.EndTime.Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(counts) != 1 || counts[0].Rejected != 1 || counts[0].Flagged != 0 || !counts[0].Time.Equal(tb.TradesBlockData.EndTime) {
+		t.Errorf("expected one rejected trade on Binance at %v, got %v", tb.TradesBlockData.EndTime, counts)
+	}
+	if len(check.takeBlockCounts()) != 0 {
+		t.Error("block counts not reset after finalising the block")
+	}
+}
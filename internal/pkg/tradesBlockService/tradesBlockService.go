@@ -12,12 +12,24 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	"github.com/ethereum/go-ethereum/common"
 	"github.com/sirupsen/logrus"
 )
 
 type nothing struct{}
 
+const (
+	// Influx table of trades written by SaveTradeInflux.
+	influxTradesTable = "trades"
+	// Retracted trades are deleted from influx in the time range of +-retractionWindow around the retraction.
+	retractionWindow = time.Hour
+)
+
+// ackedTrade is a trade together with the function acknowledging its processing.
+type ackedTrade struct {
+	trade *dia.Trade
+	ack   func()
+}
+
 func init() {
 	log = logrus.New()
 	batchTimeString = utils.Getenv("BATCH_TIME_SECONDS", "30")
@@ -26,6 +38,18 @@ func init() {
 	if err != nil {
 		log.Error("parse batchTimeString: ", err)
 	}
+	priceCacheTTLSeconds, err = strconv.Atoi(utils.Getenv("PRICE_CACHE_TTL_SECONDS", "60"))
+	if err != nil {
+		log.Error("parse PRICE_CACHE_TTL_SECONDS: ", err)
+	}
+	priceCacheBucketSeconds, err = strconv.Atoi(utils.Getenv("PRICE_CACHE_BUCKET_SECONDS", "60"))
+	if err != nil {
+		log.Error("parse PRICE_CACHE_BUCKET_SECONDS: ", err)
+	}
+	priceCacheMaxSize, err = strconv.Atoi(utils.Getenv("PRICE_CACHE_MAX_SIZE", "10000"))
+	if err != nil {
+		log.Error("parse PRICE_CACHE_MAX_SIZE: ", err)
+	}
 
 }
 
@@ -42,40 +66,84 @@ var (
 	log              *logrus.Logger
 	batchTimeString  string
 	batchTimeSeconds int
+	// Base token prices are cached per bucket of trade time.
+	priceCacheTTLSeconds    int
+	priceCacheBucketSeconds int
+	priceCacheMaxSize       int
 )
 
 type TradesBlockService struct {
 	shutdown         chan nothing
 	shutdownDone     chan nothing
-	chanTrades       chan *dia.Trade
+	chanTrades       chan ackedTrade
 	chanTradesBlock  chan *dia.TradesBlock
+	chanFlush        chan chan error
 	errorLock        sync.RWMutex
 	error            error
 	closed           bool
 	started          bool
 	BlockDuration    int64
 	currentBlock     *dia.TradesBlock
-	priceCache       map[dia.Asset]float64
+	priceCache       *PriceCache
 	datastore        models.Datastore
 	historical       bool
 	writeMeasurement string
 	batchTicker      *time.Ticker
+	sanityCheck      *PriceSanityCheck
+	// maps bridged base tokens to their canonical assets
+	assetEquivalences *dia.AssetEquivalenceTable
+	priceGraph        *PriceGraph
+	// acks of trades processed since the last finalised block
+	pendingAcks []func()
+	// acks of finalised blocks which are not acknowledged yet
+	blockAcks map[*dia.TradesBlock][]func()
+	acksLock  sync.Mutex
+}
+
+// TradesBlockServiceOptions holds optional components of a tradesBlockService. Nil fields are disabled.
+type TradesBlockServiceOptions struct {
+	// SanityCheck checks the estimated USD price of all trades before adding them to the tradesBlock.
+	SanityCheck *PriceSanityCheck
+	// AssetEquivalences maps base tokens to the canonical assets used for price estimation.
+	AssetEquivalences *dia.AssetEquivalenceTable
+	// PriceCache caches base token prices. If nil, a cache is configured through the env vars
+	// PRICE_CACHE_TTL_SECONDS, PRICE_CACHE_BUCKET_SECONDS and PRICE_CACHE_MAX_SIZE.
+	PriceCache *PriceCache
+	// PriceGraph prices base tokens without quotation along the pairs of verified trades.
+	PriceGraph *PriceGraph
 }
 
 func NewTradesBlockService(datastore models.Datastore, blockDuration int64, historical bool) *TradesBlockService {
+	return NewTradesBlockServiceWithOptions(datastore, blockDuration, historical, TradesBlockServiceOptions{})
+}
+
+// NewTradesBlockServiceWithOptions returns a tradesBlockService with the components set in @options.
+func NewTradesBlockServiceWithOptions(datastore models.Datastore, blockDuration int64, historical bool, options TradesBlockServiceOptions) *TradesBlockService {
 	s := &TradesBlockService{
-		shutdown:        make(chan nothing),
-		shutdownDone:    make(chan nothing),
-		chanTrades:      make(chan *dia.Trade),
-		chanTradesBlock: make(chan *dia.TradesBlock),
-		error:           nil,
-		started:         false,
-		currentBlock:    nil,
-		BlockDuration:   blockDuration,
-		priceCache:      make(map[dia.Asset]float64),
-		datastore:       datastore,
-		historical:      historical,
-		batchTicker:     time.NewTicker(time.Duration(batchTimeSeconds) * time.Second),
+		shutdown:          make(chan nothing),
+		shutdownDone:      make(chan nothing),
+		chanTrades:        make(chan ackedTrade),
+		chanTradesBlock:   make(chan *dia.TradesBlock),
+		chanFlush:         make(chan chan error),
+		error:             nil,
+		started:           false,
+		currentBlock:      nil,
+		BlockDuration:     blockDuration,
+		priceCache:        options.PriceCache,
+		datastore:         datastore,
+		historical:        historical,
+		batchTicker:       time.NewTicker(time.Duration(batchTimeSeconds) * time.Second),
+		sanityCheck:       options.SanityCheck,
+		assetEquivalences: options.AssetEquivalences,
+		priceGraph:        options.PriceGraph,
+		blockAcks:         make(map[*dia.TradesBlock][]func()),
+	}
+	if s.priceCache == nil {
+		s.priceCache = NewPriceCache(
+			time.Duration(priceCacheTTLSeconds)*time.Second,
+			time.Duration(priceCacheBucketSeconds)*time.Second,
+			priceCacheMaxSize,
+		)
 	}
 	if historical {
 		s.writeMeasurement = utils.Getenv("INFLUX_MEASUREMENT_WRITE", "tradesTmp")
@@ -96,18 +164,30 @@ func (s *TradesBlockService) mainLoop() {
 			s.cleanup(nil)
 			return
 		case t := <-s.chanTrades:
-			s.process(*t)
+			if t.trade != nil {
+				s.process(*t.trade)
+			}
+			if t.ack != nil {
+				s.pendingAcks = append(s.pendingAcks, t.ack)
+			}
 		case <-s.batchTicker.C:
 			err := s.datastore.Flush()
 			if err != nil {
 				log.Error("flush influx batch: ", err)
 			}
+		case errChan := <-s.chanFlush:
+			errChan <- s.datastore.Flush()
 		}
 	}
 }
 
 func (s *TradesBlockService) process(t dia.Trade) {
 
+	if t.Retracted {
+		s.retract(t)
+		return
+	}
+
 	var verifiedTrade bool
 
 	// Price estimation can only be done for verified pairs.
@@ -118,96 +198,21 @@ func (s *TradesBlockService) process(t dia.Trade) {
 			t.EstimatedUSDPrice = t.Price
 			verifiedTrade = true
 		} else {
-			// Get price of base token.
-			// This can be switched to GetAssetPriceUSD(asset, timestamp) when switching to historical scrapers.
-			// val, err := s.datastore.GetAssetPriceUSDCache(t.BaseToken)
-			var quotation *models.AssetQuotation
-			var price float64
-			var ok bool
-			var err error
-			if !s.historical {
-				// Get latest price from cache.
-				// price, err = s.datastore.GetAssetPriceUSDLatest(t.BaseToken)
-
-				basetoken := t.BaseToken
-				// Tmp solution for prices on Solana, Metis, Fantom and Telos:------
-				if basetoken.Blockchain == dia.SOLANA && t.Source == dia.SerumExchange && basetoken.Address == "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v" {
-					basetoken = dia.Asset{
-						Symbol:     "USDC",
-						Address:    "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
-						Blockchain: dia.ETHEREUM,
-					}
-				}
-				if basetoken.Blockchain == dia.METIS && (t.Source == dia.NetswapExchange || t.Source == dia.TethysExchange || t.Source == dia.HermesExchange) && basetoken.Address == "0xEA32A96608495e54156Ae48931A7c20f0dcc1a21" {
-					basetoken = dia.Asset{
-						Symbol:     "USDC",
-						Address:    "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
-						Blockchain: dia.ETHEREUM,
-					}
-				}
-				if basetoken.Blockchain == dia.FANTOM && (t.Source == dia.SpookyswapExchange || t.Source == dia.SpiritswapExchange || t.Source == dia.BeetsExchange) && basetoken.Address == "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83" {
-					basetoken = dia.Asset{
-						Symbol:     "FTM",
-						Address:    "0x0000000000000000000000000000000000000000",
-						Blockchain: dia.FANTOM,
-					}
-				}
-				if basetoken.Blockchain == dia.TELOS && (t.Source == dia.OmniDexExchange) && basetoken.Address == common.HexToAddress("0xd102ce6a4db07d247fcc28f366a623df0938ca9e").Hex() {
-					basetoken = dia.Asset{
-						Symbol:     "TLOS",
-						Address:    "0x0000000000000000000000000000000000000000",
-						Blockchain: dia.TELOS,
-					}
-				}
-				if basetoken.Blockchain == dia.EVMOS && t.Source == dia.DiffusionExchange && basetoken.Address == common.HexToAddress("0x51e44FfaD5C2B122C8b635671FCC8139dc636E82").Hex() {
-					basetoken = dia.Asset{
-						Symbol:     "USDC",
-						Address:    "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
-						Blockchain: dia.ETHEREUM,
-					}
-				}
-				// if basetoken.Blockchain == dia.ASTAR && t.Source == dia.ArthswapExchange && basetoken.Address == common.HexToAddress("0x6a2d262D56735DbA19Dd70682B39F6bE9a931D98").Hex() {
-				// 	basetoken = dia.Asset{
-				// 		Symbol:     "USDC",
-				// 		Address:    "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48",
-				// 		Blockchain: dia.ETHEREUM,
-				// 	}
-				// }
-				// if basetoken.Blockchain == dia.ASTAR && t.Source == dia.ArthswapExchange && basetoken.Address == common.HexToAddress("0x3795C36e7D12A8c252A20C5a7B455f7c57b60283").Hex() {
-				// 	basetoken = dia.Asset{
-				// 		Symbol:     "USDT",
-				// 		Address:    "0xdAC17F958D2ee523a2206206994597C13D831ec7",
-				// 		Blockchain: dia.ETHEREUM,
-				// 	}
-				// }
-
-				if _, ok = s.priceCache[basetoken]; ok {
-					price = s.priceCache[basetoken]
-				} else {
-					quotation, err = s.datastore.GetAssetQuotationCache(basetoken)
-					price = quotation.Price
-					s.priceCache[basetoken] = price
-					log.Infof("quotation for %s from redis cache: %v", basetoken.Symbol, price)
-				}
-
-			} else {
-
-				// Look for historic price of base token at trade time.
-				if _, ok = s.priceCache[t.BaseToken]; ok {
-					price = s.priceCache[t.BaseToken]
-				} else {
-					price, err = s.datastore.GetAssetPriceUSD(t.BaseToken, t.Time)
-					s.priceCache[t.BaseToken] = price
-					if t.BaseToken.Address == "0x0000000000000000000000000000000000000000" {
-						if t.BaseToken.Blockchain == "Bitcoin" {
-							log.Infof("quotation for BTC from influx: %v", price)
-						}
-						if t.BaseToken.Blockchain == "Ethereum" {
-							log.Infof("quotation for ETH from influx: %v", price)
-						}
-					}
-				}
+			// Bridged base tokens are priced by their canonical asset.
+			basetoken, _ := s.assetEquivalences.Canonical(t.BaseToken, t.Source)
 
+			// Get price of base token.
+			price, err := s.assetPrice(basetoken, t.Time)
+			if (err != nil || price <= 0) && s.priceGraph != nil {
+				// Base tokens without quotation are priced along the price graph. The trade's own quote token
+				// is excluded, as its price would otherwise be derived from itself.
+				price, err = s.graphPrice(basetoken, t.QuoteToken, t.Time)
+			}
+			// The trade is added to the graph only after it is priced, for the same reason.
+			if s.priceGraph != nil {
+				graphTrade := t
+				graphTrade.BaseToken = basetoken
+				s.priceGraph.AddTrade(graphTrade)
 			}
 			if err != nil {
 				log.Errorf("Can't find quotation for base token in trade %s: %v.\n Basetoken address -- blockchain:  %s --- %s",
@@ -234,8 +239,11 @@ func (s *TradesBlockService) process(t dia.Trade) {
 			verifiedTrade = false
 		}
 	}
-	// Comment Philipp: We could make another check here. Store CG and/or CMC quotation in redis cache
-	// and compare with estimatedUSDPrice. If deviation is too large ignore trade.
+	// Compare estimated price with a reference price such as the latest quotation,
+	// a CG/CMC quotation or the median price on other exchanges.
+	if verifiedTrade && s.sanityCheck != nil && !s.sanityCheck.check(&t) {
+		verifiedTrade = false
+	}
 	var err error
 	if !s.historical {
 		err = s.datastore.SaveTradeInflux(&t)
@@ -259,7 +267,6 @@ func (s *TradesBlockService) process(t dia.Trade) {
 		if s.currentBlock == nil || s.currentBlock.TradesBlockData.EndTime.Before(t.Time) {
 			if s.currentBlock != nil {
 				s.finaliseCurrentBlock()
-				s.priceCache = make(map[dia.Asset]float64)
 			}
 
 			b := &dia.TradesBlock{
@@ -284,9 +291,94 @@ func (s *TradesBlockService) process(t dia.Trade) {
 	}
 }
 
+// assetPrice returns the USD price of @asset from the price cache. Uncached prices are read from the latest
+// quotation in the redis cache, or from the quotation at @timestamp in historical mode.
+func (s *TradesBlockService) assetPrice(asset dia.Asset, timestamp time.Time) (float64, error) {
+	if price, ok := s.priceCache.Get(asset, timestamp); ok {
+		return price, nil
+	}
+
+	var price float64
+	if !s.historical {
+		quotation, err := s.datastore.GetAssetQuotationCache(asset)
+		if err != nil {
+			return 0, err
+		}
+		price = quotation.Price
+		log.Infof("quotation for %s from redis cache: %v", asset.Symbol, price)
+	} else {
+		var err error
+		price, err = s.datastore.GetAssetPriceUSD(asset, timestamp)
+		if err != nil {
+			return 0, err
+		}
+		if asset.Address == "0x0000000000000000000000000000000000000000" {
+			if asset.Blockchain == "Bitcoin" {
+				log.Infof("quotation for BTC from influx: %v", price)
+			}
+			if asset.Blockchain == "Ethereum" {
+				log.Infof("quotation for ETH from influx: %v", price)
+			}
+		}
+	}
+	s.priceCache.Set(asset, timestamp, price)
+	return price, nil
+}
+
+// graphPrice returns the USD price of @asset along the most liquid path of the price graph that does not pass
+// through @quotetoken. In live mode, the path is stored next to the asset's quotation.
+// The price is not cached, as it would be reused for trades quoted in an asset of its path.
+func (s *TradesBlockService) graphPrice(asset dia.Asset, quotetoken dia.Asset, timestamp time.Time) (float64, error) {
+	pricePath, ok := s.priceGraph.Price(asset, timestamp, s.assetPrice, quotetoken)
+	if !ok {
+		return 0, errors.New("no price path with sufficient confidence")
+	}
+	log.Infof("price for %s along path of %d pairs: %v with confidence %.2f", asset.Symbol, len(pricePath.Path)-1, pricePath.Price, pricePath.Confidence)
+	if !s.historical {
+		if err := s.datastore.SetPricePathCache(&pricePath); err != nil {
+			log.Errorf("set price path of %s: %v", asset.Symbol, err)
+		}
+	}
+	return pricePath.Price, nil
+}
+
+// retract removes the trade retracted by @t from the current tradesBlock and deletes it from influx.
+// Trades are identified by exchange and foreign trade ID. Trades in finalised blocks cannot be retracted anymore.
+func (s *TradesBlockService) retract(t dia.Trade) {
+	if s.currentBlock != nil {
+		var trades []dia.Trade
+		for _, trade := range s.currentBlock.TradesBlockData.Trades {
+			if trade.Source != t.Source || trade.ForeignTradeID != t.ForeignTradeID {
+				trades = append(trades, trade)
+			}
+		}
+		if len(trades) < len(s.currentBlock.TradesBlockData.Trades) {
+			log.Infof("removed retracted trade %s on %s from current block", t.ForeignTradeID, t.Source)
+		}
+		s.currentBlock.TradesBlockData.Trades = trades
+	}
+
+	// The retracted trade might still be in the influx batch.
+	err := s.datastore.Flush()
+	if err != nil {
+		log.Error("flush influx batch: ", err)
+	}
+	table := influxTradesTable
+	if s.historical {
+		table = s.writeMeasurement
+	}
+	numDeleted, err := s.datastore.DeleteTradesInflux(table, t.Source, t.ForeignTradeID, t.Time.Add(-retractionWindow), t.Time.Add(retractionWindow))
+	if err != nil {
+		log.Errorf("delete retracted trade %s on %s: %v", t.ForeignTradeID, t.Source, err)
+		return
+	}
+	log.Infof("deleted %d retracted trades %s on %s", numDeleted, t.ForeignTradeID, t.Source)
+}
+
 func (s *TradesBlockService) finaliseCurrentBlock() {
 
-	sort.Slice(s.currentBlock.TradesBlockData.Trades, func(i, j int) bool {
+	// Stable sort keeps the order of arrival for trades with equal timestamps.
+	sort.SliceStable(s.currentBlock.TradesBlockData.Trades, func(i, j int) bool {
 		return s.currentBlock.TradesBlockData.Trades[i].Time.Before(s.currentBlock.TradesBlockData.Trades[j].Time)
 	})
 
@@ -297,11 +389,105 @@ func (s *TradesBlockService) finaliseCurrentBlock() {
 	}
 	s.currentBlock.BlockHash = hash
 	s.currentBlock.TradesBlockData.TradesNumber = len(s.currentBlock.TradesBlockData.Trades)
+	if s.sanityCheck != nil {
+		s.sanityCheck.logCounts()
+		s.saveSanityCounts(s.currentBlock.TradesBlockData.EndTime)
+	}
+	s.priceCache.logStats()
+	if len(s.pendingAcks) > 0 {
+		s.acksLock.Lock()
+		s.blockAcks[s.currentBlock] = s.pendingAcks
+		s.acksLock.Unlock()
+		s.pendingAcks = nil
+	}
 	s.chanTradesBlock <- s.currentBlock
 }
 
+// saveSanityCounts adds the counts of trades which failed the price sanity check in the finalised
+// block to the influx batch, such that they are written along with the trades.
+func (s *TradesBlockService) saveSanityCounts(blockEnd time.Time) {
+	for _, count := range s.sanityCheck.takeBlockCounts() {
+		err := s.datastore.SavePriceSanityInflux(models.PriceSanityCount{
+			Exchange: count.Exchange,
+			Pair:     count.Pair,
+			Rejected: count.Rejected,
+			Flagged:  count.Flagged,
+			Time:     blockEnd,
+		})
+		if err != nil {
+			log.Errorf("save price sanity count of %s on %s: %v", count.Pair, count.Exchange, err)
+		}
+	}
+}
+
 func (s *TradesBlockService) ProcessTrade(trade *dia.Trade) {
-	s.chanTrades <- trade
+	s.chanTrades <- ackedTrade{trade: trade}
+}
+
+// ProcessTradeWithAck processes @trade like ProcessTrade. @ack is called by Acknowledge once the
+// tradesBlock containing the trade, or the next finalised block if the trade is not added to any
+// block, is acknowledged. A nil @trade is not processed, but its @ack is queued like that of a trade.
+func (s *TradesBlockService) ProcessTradeWithAck(trade *dia.Trade, ack func()) {
+	s.chanTrades <- ackedTrade{trade: trade, ack: ack}
+}
+
+// Acknowledge is called once @block received from Channel() is handled, for instance written to kafka.
+// It calls the acks of all trades processed up to and including @block.
+func (s *TradesBlockService) Acknowledge(block *dia.TradesBlock) {
+	s.acksLock.Lock()
+	acks := s.blockAcks[block]
+	delete(s.blockAcks, block)
+	s.acksLock.Unlock()
+	for _, ack := range acks {
+		ack()
+	}
+}
+
+// Flush writes the influx batch holding the trades processed so far. It must be called before
+// the offsets of a block's trades are committed, as batched trades are lost if the service stops.
+func (s *TradesBlockService) Flush() error {
+	errChan := make(chan error)
+	select {
+	case s.chanFlush <- errChan:
+		return <-errChan
+	case <-s.shutdownDone:
+		return errors.New("TradesBlockService: closed")
+	}
+}
+
+// SplitTradesBlock splits @block into blocks containing the trades with equal @partition.
+// Blocks keep begin and end time of @block and are returned in order of partition.
+func SplitTradesBlock(block *dia.TradesBlock, partition func(dia.Trade) int) []*dia.TradesBlock {
+	tradesByPartition := make(map[int][]dia.Trade)
+	var partitions []int
+	for _, t := range block.TradesBlockData.Trades {
+		p := partition(t)
+		if _, ok := tradesByPartition[p]; !ok {
+			partitions = append(partitions, p)
+		}
+		tradesByPartition[p] = append(tradesByPartition[p], t)
+	}
+	sort.Ints(partitions)
+
+	var blocks []*dia.TradesBlock
+	for _, p := range partitions {
+		b := &dia.TradesBlock{
+			TradesBlockData: dia.TradesBlockData{
+				BeginTime:    block.TradesBlockData.BeginTime,
+				EndTime:      block.TradesBlockData.EndTime,
+				TradesNumber: len(tradesByPartition[p]),
+				Trades:       tradesByPartition[p],
+			},
+		}
+		hash, err := structhash.Hash(b.TradesBlockData, 1)
+		if err != nil {
+			log.Printf("error on hash")
+			hash = "hashError"
+		}
+		b.BlockHash = hash
+		blocks = append(blocks, b)
+	}
+	return blocks
 }
 
 func (s *TradesBlockService) Close() error {
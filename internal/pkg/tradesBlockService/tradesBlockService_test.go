@@ -0,0 +1,146 @@
+package tradesBlockService
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+func TestTradesBlockServiceAcknowledge(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	if err := ds.SetAssetPriceUSD(usdt, 1, t0); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{})
+
+	acked := make(chan int, 4)
+	go func() {
+		offsets := []time.Duration{0, 10 * time.Second, (dia.BlockSizeSeconds + 1) * time.Second, (2*dia.BlockSizeSeconds + 1) * time.Second}
+		for i, offset := range offsets {
+			i := i
+			trade := makeTrade(dia.KrakenExchange, 2000, t0.Add(offset))
+			s.ProcessTradeWithAck(&trade, func() { acked <- i })
+		}
+	}()
+
+	// The trade closing a block is acknowledged with the next block.
+	for _, expected := range [][]int{{0, 1}, {2}} {
+		tb := <-s.Channel()
+		s.Acknowledge(tb)
+		for _, i := range expected {
+			if got := <-acked; got != i {
+				t.Errorf("expected ack of trade %d, got %d", i, got)
+			}
+		}
+		if len(acked) != 0 {
+			t.Errorf("unexpected ack of trade %d", <-acked)
+		}
+	}
+}
+
+// flushCountingDB counts flushes of its influx batch and fails them with err.
+type flushCountingDB struct {
+	models.Datastore
+	flushes int
+	err     error
+}
+
+func (db *flushCountingDB) Flush() error {
+	db.flushes++
+	return db.err
+}
+
+func TestTradesBlockServiceFlush(t *testing.T) {
+	ds := &flushCountingDB{Datastore: models.NewMemoryDataStore(), err: errors.New("influx down")}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{})
+	if err := s.Flush(); err != ds.err || ds.flushes != 1 {
+		t.Errorf("expected failed flush, got %v after %d flushes", err, ds.flushes)
+	}
+	ds.err = nil
+	if err := s.Flush(); err != nil {
+		t.Error(err)
+	}
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err == nil {
+		t.Error("expected error on flush of closed service")
+	}
+}
+
+func TestTradesBlockServiceRetraction(t *testing.T) {
+	ds := models.NewMemoryDataStore()
+	t0 := time.Unix(1651406400, 0)
+	if err := ds.SetAssetPriceUSD(usdt, 1, t0); err != nil {
+		t.Fatal(err)
+	}
+	s := NewTradesBlockServiceWithOptions(ds, dia.BlockSizeSeconds, true, TradesBlockServiceOptions{})
+
+	go func() {
+		for i, id := range []string{"0xa", "0xb"} {
+			trade := makeTrade(dia.UniswapExchange, 2000, t0.Add(time.Duration(i)*time.Second))
+			trade.ForeignTradeID = id
+			s.ProcessTrade(&trade)
+		}
+		retraction := makeTrade(dia.UniswapExchange, 2000, t0)
+		retraction.ForeignTradeID = "0xa"
+		retraction.Retracted = true
+		s.ProcessTrade(&retraction)
+		// Finalise the block.
+		trade := makeTrade(dia.UniswapExchange, 2000, t0.Add((dia.BlockSizeSeconds+1)*time.Second))
+		s.ProcessTrade(&trade)
+	}()
+
+	tb := <-s.Channel()
+	if len(tb.TradesBlockData.Trades) != 1 || tb.TradesBlockData.Trades[0].ForeignTradeID != "0xb" {
+		t.Errorf("expected only trade 0xb in block, got %v", tb.TradesBlockData.Trades)
+	}
+	trades, err := ds.GetOldTradesFromInflux(s.writeMeasurement, dia.UniswapExchange, true, t0, t0.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, trade := range trades {
+		if trade.ForeignTradeID == "0xa" {
+			t.Error("retracted trade is stored")
+		}
+	}
+}
+
+func TestSplitTradesBlock(t *testing.T) {
+	t0 := time.Unix(1651406400, 0)
+	btc := dia.Asset{Symbol: "BTC", Address: "0x0000000000000000000000000000000000000000", Blockchain: dia.BITCOIN}
+	block := &dia.TradesBlock{
+		TradesBlockData: dia.TradesBlockData{
+			BeginTime: t0,
+			EndTime:   t0.Add(dia.BlockSizeSeconds * time.Second),
+		},
+	}
+	for i, asset := range []dia.Asset{eth, btc, eth} {
+		trade := makeTrade(dia.KrakenExchange, 2000, t0.Add(time.Duration(i)*time.Second))
+		trade.QuoteToken = asset
+		block.TradesBlockData.Trades = append(block.TradesBlockData.Trades, trade)
+	}
+
+	blocks := SplitTradesBlock(block, func(trade dia.Trade) int {
+		if trade.QuoteToken == eth {
+			return 1
+		}
+		return 0
+	})
+	if len(blocks) != 2 {
+		t.Fatalf("expected 2 blocks, got %d", len(blocks))
+	}
+	for i, expected := range []int{1, 2} {
+		data := blocks[i].TradesBlockData
+		if data.TradesNumber != expected || len(data.Trades) != expected || !data.BeginTime.Equal(t0) {
+			t.Errorf("unexpected block %d: %v", i, data)
+		}
+	}
+	if blocks[1].TradesBlockData.Trades[1].Time != t0.Add(2*time.Second) {
+		t.Error("order of trades is not kept")
+	}
+}
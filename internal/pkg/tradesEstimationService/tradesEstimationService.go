@@ -50,17 +50,26 @@ type TradesEstimationService struct {
 	started      bool
 	priceCache   map[dia.Asset]pricetime
 	datastore    models.Datastore
+	// maps bridged base tokens to their canonical assets
+	assetEquivalences *dia.AssetEquivalenceTable
 }
 
 func NewTradesEstimationService(datastore models.Datastore) *TradesEstimationService {
+	return NewTradesEstimationServiceWithAssetEquivalences(datastore, nil)
+}
+
+// NewTradesEstimationServiceWithAssetEquivalences returns a tradesEstimationService which prices
+// base tokens by their canonical assets in @assetEquivalences. @assetEquivalences may be nil.
+func NewTradesEstimationServiceWithAssetEquivalences(datastore models.Datastore, assetEquivalences *dia.AssetEquivalenceTable) *TradesEstimationService {
 	s := &TradesEstimationService{
-		shutdown:     make(chan nothing),
-		shutdownDone: make(chan nothing),
-		chanTrades:   make(chan *dia.Trade),
-		error:        nil,
-		started:      false,
-		priceCache:   make(map[dia.Asset]pricetime),
-		datastore:    datastore,
+		shutdown:          make(chan nothing),
+		shutdownDone:      make(chan nothing),
+		chanTrades:        make(chan *dia.Trade),
+		error:             nil,
+		started:           false,
+		priceCache:        make(map[dia.Asset]pricetime),
+		datastore:         datastore,
+		assetEquivalences: assetEquivalences,
 	}
 	go s.mainLoop()
 	return s
@@ -93,14 +102,16 @@ func (s *TradesEstimationService) process(t dia.Trade) {
 			t.EstimatedUSDPrice = t.Price
 			verifiedTrade = true
 		} else {
+			// Bridged base tokens are priced by their canonical asset.
+			basetoken, _ := s.assetEquivalences.Canonical(t.BaseToken, t.Source)
 			// Check if price cache is still valid:
-			_, ok := s.priceCache[t.BaseToken]
-			if ok && t.Time.Sub(s.priceCache[t.BaseToken].Timestamp) < time.Duration(priceFrame*time.Millisecond) {
-				price = s.priceCache[t.BaseToken].Price
+			_, ok := s.priceCache[basetoken]
+			if ok && t.Time.Sub(s.priceCache[basetoken].Timestamp) < time.Duration(priceFrame*time.Millisecond) {
+				price = s.priceCache[basetoken].Price
 			} else {
 				// Look for historic price of base token at trade time...
-				price, err = s.datastore.GetAssetPriceUSD(t.BaseToken, t.Time)
-				s.priceCache[t.BaseToken] = pricetime{
+				price, err = s.datastore.GetAssetPriceUSD(basetoken, t.Time)
+				s.priceCache[basetoken] = pricetime{
 					Price:     price,
 					Timestamp: t.Time,
 				}
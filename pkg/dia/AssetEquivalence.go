@@ -0,0 +1,67 @@
+package dia
+
+import "strings"
+
+type assetEquivalenceKey struct {
+	Blockchain string
+	Address    string
+	Exchange   string
+}
+
+// AssetEquivalenceTable maps assets to their canonical assets, such as bridged tokens to the
+// token on its origin chain.
+type AssetEquivalenceTable struct {
+	equivalences []AssetEquivalence
+	canonical    map[assetEquivalenceKey]Asset
+}
+
+// NewAssetEquivalenceTable returns a table made from @equivalences.
+func NewAssetEquivalenceTable(equivalences []AssetEquivalence) *AssetEquivalenceTable {
+	aet := &AssetEquivalenceTable{
+		equivalences: equivalences,
+		canonical:    make(map[assetEquivalenceKey]Asset),
+	}
+	for _, ae := range equivalences {
+		aet.canonical[assetEquivalenceKey{
+			Blockchain: ae.Blockchain,
+			Address:    normalizeEquivalenceAddress(ae.Address),
+			Exchange:   ae.Exchange,
+		}] = ae.Canonical
+	}
+	return aet
+}
+
+// normalizeEquivalenceAddress lowercases hex addresses such that checksummed and
+// non-checksummed addresses are equivalent. Other addresses are case sensitive.
+func normalizeEquivalenceAddress(address string) string {
+	if strings.HasPrefix(address, "0x") {
+		return strings.ToLower(address)
+	}
+	return address
+}
+
+// Canonical returns the canonical asset of @asset for trades on @exchange. An equivalence for @exchange
+// takes precedence over an equivalence for all exchanges. If there is none, @asset is returned along with false.
+// A nil table has no equivalences.
+func (aet *AssetEquivalenceTable) Canonical(asset Asset, exchange string) (Asset, bool) {
+	if aet == nil {
+		return asset, false
+	}
+	key := assetEquivalenceKey{Blockchain: asset.Blockchain, Address: normalizeEquivalenceAddress(asset.Address), Exchange: exchange}
+	if canonical, ok := aet.canonical[key]; ok {
+		return canonical, true
+	}
+	key.Exchange = ""
+	if canonical, ok := aet.canonical[key]; ok {
+		return canonical, true
+	}
+	return asset, false
+}
+
+// Equivalences returns all equivalences in the table.
+func (aet *AssetEquivalenceTable) Equivalences() []AssetEquivalence {
+	if aet == nil {
+		return []AssetEquivalence{}
+	}
+	return aet.equivalences
+}
@@ -0,0 +1,51 @@
+package dia
+
+import (
+	"testing"
+)
+
+func TestAssetEquivalenceTable(t *testing.T) {
+	wftm := Asset{Symbol: "WFTM", Address: "0x21be370D5312f44cB42ce377BC9b8a0cEF1A4C83", Blockchain: FANTOM}
+	ftm := Asset{Symbol: "FTM", Address: "0x0000000000000000000000000000000000000000", Blockchain: FANTOM}
+	usdc := Asset{Symbol: "USDC", Address: "0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48", Blockchain: ETHEREUM}
+	solanaUSDC := Asset{Symbol: "USDC", Address: "EPjFWdd5AufqSSqeM2qN1xzybapC8G4wEGGkZwyTDt1v", Blockchain: SOLANA}
+
+	table := NewAssetEquivalenceTable([]AssetEquivalence{
+		{Blockchain: FANTOM, Address: "0x21be370d5312f44cb42ce377bc9b8a0cef1a4c83", Canonical: usdc},
+		{Blockchain: FANTOM, Address: wftm.Address, Exchange: SpookyswapExchange, Canonical: ftm},
+		{Blockchain: SOLANA, Address: solanaUSDC.Address, Exchange: SerumExchange, Canonical: usdc},
+	})
+
+	cases := []struct {
+		asset    Asset
+		exchange string
+		expected Asset
+		mapped   bool
+	}{
+		// Exchange specific equivalence takes precedence.
+		{wftm, SpookyswapExchange, ftm, true},
+		{wftm, SpiritswapExchange, usdc, true},
+		{solanaUSDC, SerumExchange, usdc, true},
+		// Non-hex addresses are case sensitive.
+		{Asset{Address: "epjfwdd5aufqssqem2qn1xzybapc8g4wegGkzwytdt1v", Blockchain: SOLANA}, SerumExchange, Asset{Address: "epjfwdd5aufqssqem2qn1xzybapc8g4wegGkzwytdt1v", Blockchain: SOLANA}, false},
+		{solanaUSDC, KrakenExchange, solanaUSDC, false},
+	}
+	for i, c := range cases {
+		canonical, mapped := table.Canonical(c.asset, c.exchange)
+		if canonical != c.expected || mapped != c.mapped {
+			t.Errorf("case %d: expected %v (%v), got %v (%v)", i, c.expected, c.mapped, canonical, mapped)
+		}
+	}
+
+	var empty *AssetEquivalenceTable
+	if canonical, mapped := empty.Canonical(wftm, SpookyswapExchange); mapped || canonical != wftm {
+		t.Errorf("nil table mapped %v to %v", wftm, canonical)
+	}
+
+	// Trades of bridged and canonical assets are partitioned alike.
+	bridged := Trade{QuoteToken: solanaUSDC, Source: SerumExchange}
+	canonical := Trade{QuoteToken: usdc, Source: KrakenExchange}
+	if key := string(bridged.CanonicalKafkaKey(table)); key != string(canonical.CanonicalKafkaKey(table)) || key != string(canonical.KafkaKey()) {
+		t.Errorf("expected key %s of canonical asset, got %s", canonical.KafkaKey(), key)
+	}
+}
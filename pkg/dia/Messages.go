@@ -242,8 +242,11 @@ type AggregatedVolume struct {
 	Timestamp        time.Time `json:"Timestamp"`
 }
 
+// TradesDistribution describes how evenly the trades of an asset are distributed in time.
+// Exchange is empty for the distribution of the asset's trades on all exchanges.
 type TradesDistribution struct {
 	Asset            Asset     `json:"Asset"`
+	Exchange         string    `json:"Exchange,omitempty"`
 	NumTradesTotal   int       `json:"NumTradesTotal"`
 	NumLowBins       int       `json:"NumberLowBins"`
 	Threshold        int       `json:"Threshold"`
@@ -254,6 +257,115 @@ type TradesDistribution struct {
 	Timestamp        time.Time `json:"Timestamp"`
 }
 
+// TradeCount is the number of trades in the time bin starting at Time.
+type TradeCount struct {
+	Time  time.Time `json:"Time"`
+	Count int       `json:"Count"`
+}
+
+const (
+	// TradeGapOpen is the status of a detected gap which is not backfilled yet.
+	TradeGapOpen = "open"
+	// TradeGapBackfilled is the status of a gap whose trades are written to the temporary trades table.
+	TradeGapBackfilled = "backfilled"
+	// TradeGapMerged is the status of a gap whose backfilled trades are merged into the trades table.
+	TradeGapMerged = "merged"
+	// TradeGapFailed is the status of a gap which could not be backfilled.
+	TradeGapFailed = "failed"
+)
+
+// TradeGap is a time range in which no trades of a pair were stored
+// although the pair's usual trading rate suggests otherwise.
+type TradeGap struct {
+	ID                int64     `json:"ID"`
+	Exchange          string    `json:"Exchange"`
+	Pair              string    `json:"Pair"`
+	StartTime         time.Time `json:"StartTime"`
+	EndTime           time.Time `json:"EndTime"`
+	ExpectedNumTrades float64   `json:"ExpectedNumTrades"`
+	Status            string    `json:"Status"`
+	Error             string    `json:"Error"`
+	UpdateTime        time.Time `json:"UpdateTime"`
+}
+
+const (
+	// OracleSourceAssetQuotation feeds the USD quotation of an asset.
+	OracleSourceAssetQuotation = "assetQuotation"
+	// OracleSourceFilter feeds the latest value of a filter such as MAIR120 for an asset.
+	OracleSourceFilter = "filter"
+	// OracleSourceNFTFloor feeds the floor price of an NFT collection.
+	OracleSourceNFTFloor = "nftFloor"
+	// OracleSourceIndex feeds the value of a crypto index identified by the asset's symbol.
+	OracleSourceIndex = "index"
+)
+
+// OracleFeed specifies an oracle feeder pushing values of assets to a DIAOracleV2 compatible contract.
+type OracleFeed struct {
+	Name       string `json:"Name"`
+	Blockchain string `json:"Blockchain"`
+	ChainID    int64  `json:"ChainID"`
+	NodeURL    string `json:"NodeURL"`
+	Contract   string `json:"Contract"`
+	// PrivateKeyEnv is the environment variable holding the key of the oracle updater.
+	PrivateKeyEnv string `json:"PrivateKeyEnv"`
+	// KeyFormat is the oracle key of an asset, in which {symbol}, {address} and {blockchain} are replaced by the asset's fields.
+	KeyFormat string `json:"KeyFormat"`
+	Source    string `json:"Source"`
+	// Filter is the filter of the filter source, such as MAIR120.
+	Filter string            `json:"Filter"`
+	Assets []OracleFeedAsset `json:"Assets"`
+	// Decimals is the number of decimals of the values written to the contract.
+	Decimals int `json:"Decimals"`
+	// A value is updated if it deviates by more than DeviationPermille from the last update
+	// or if the last update is older than HeartbeatSeconds.
+	DeviationPermille int `json:"DeviationPermille"`
+	HeartbeatSeconds  int `json:"HeartbeatSeconds"`
+	// FrequencySeconds is the time between two checks of all assets.
+	FrequencySeconds int `json:"FrequencySeconds"`
+	// BatchUpdates pushes all values which need an update in a check in a single transaction.
+	// It requires a DIAOracleV3 compatible contract with setMultipleValues.
+	BatchUpdates bool `json:"BatchUpdates"`
+	// Optional caps of the fees of update transactions. Feeds sharing an updater on the same node
+	// share the caps and the ConfirmationTimeoutSeconds of the first of them.
+	MaxFeePerGasGwei         float64 `json:"MaxFeePerGasGwei"`
+	MaxPriorityFeePerGasGwei float64 `json:"MaxPriorityFeePerGasGwei"`
+	// ConfirmationTimeoutSeconds is the time after which a pending update transaction is replaced.
+	ConfirmationTimeoutSeconds int `json:"ConfirmationTimeoutSeconds"`
+}
+
+// OracleFeedAsset is an asset of an oracle feed.
+type OracleFeedAsset struct {
+	Blockchain string `json:"Blockchain"`
+	Address    string `json:"Address"`
+	Symbol     string `json:"Symbol"`
+}
+
+const (
+	// OracleUpdateConfirmed is the status of an update whose transaction was mined successfully.
+	OracleUpdateConfirmed = "confirmed"
+	// OracleUpdateReverted is the status of an update whose transaction was mined but reverted.
+	OracleUpdateReverted = "reverted"
+	// OracleUpdateFailed is the status of an update whose transaction could not be sent or was not mined in time.
+	OracleUpdateFailed = "failed"
+)
+
+// OracleUpdate is a value pushed to the oracle of a feed along with the outcome of its transaction.
+type OracleUpdate struct {
+	ID         int64     `json:"ID"`
+	Feed       string    `json:"Feed"`
+	Blockchain string    `json:"Blockchain"`
+	Contract   string    `json:"Contract"`
+	Key        string    `json:"Key"`
+	Value      float64   `json:"Value"`
+	Timestamp  time.Time `json:"Timestamp"`
+	TxHash     string    `json:"TxHash"`
+	Nonce      uint64    `json:"Nonce"`
+	GasUsed    uint64    `json:"GasUsed"`
+	Status     string    `json:"Status"`
+	Error      string    `json:"Error"`
+	UpdateTime time.Time `json:"UpdateTime"`
+}
+
 type EthereumBlockData struct {
 	GasLimit    uint64             `json:"gas_limit"`
 	GasUsed     uint64             `json:"gas_used"`
@@ -282,8 +394,22 @@ type Exchange struct {
 	WsAPI         string     `json:"WsAPI"`
 	PairsAPI      string     `json:"PairsAPI"`
 	WatchdogDelay int        `json:"WatchdogDelay"`
+	// ScraperType selects a generic scraper for the exchange, such as ScraperTypeUniswapV2.
+	// Exchanges without scraper type have a dedicated scraper.
+	ScraperType string `json:"ScraperType"`
+	// InitCodeHash is the hash of the pair contract's init code, used to compute pair addresses of DEXes.
+	InitCodeHash string `json:"InitCodeHash"`
+	// WaitTime is the time in milliseconds between two requests of a DEX scraper.
+	WaitTime int `json:"WaitTime"`
+	// SwapTrades is true for exchanges whose trades are also sent with base and quote token swapped.
+	SwapTrades bool `json:"SwapTrades"`
 }
 
+const (
+	// ScraperTypeUniswapV2 denotes a fork of UniswapV2 which is scraped by the generic UniswapV2 scraper.
+	ScraperTypeUniswapV2 = "UniswapV2"
+)
+
 type Supply struct {
 	Asset             Asset
 	Supply            float64
@@ -319,6 +445,38 @@ type ChainConfig struct {
 	ChainID string `json:"ChainID"`
 }
 
+// FilterConfig activates the filter with name @Name in the filtersBlockService.
+// If Blockchain and Address are empty the config applies to all assets.
+// If Exchange is empty the config applies to all exchanges, including the
+// cross-exchange filters.
+type FilterConfig struct {
+	Name         string  `json:"Name"`
+	Blockchain   string  `json:"Blockchain"`
+	Address      string  `json:"Address"`
+	Exchange     string  `json:"Exchange"`
+	Memory       int     `json:"Memory"`
+	OutlierScale float64 `json:"OutlierScale"`
+	MinTrades    int     `json:"MinTrades"`
+	// TrustWeighted weights the trades of cross-exchange filters by the exchanges' trust scores.
+	TrustWeighted bool `json:"TrustWeighted"`
+	// MaxVolumeShare caps the share of a single exchange in cross-exchange filters if positive.
+	MaxVolumeShare float64 `json:"MaxVolumeShare"`
+	// DEX trades from pools with a USD liquidity below MinPoolLiquidityUSD are excluded,
+	// or discounted by their liquidity if DiscountIlliquid is true.
+	MinPoolLiquidityUSD float64 `json:"MinPoolLiquidityUSD"`
+	DiscountIlliquid    bool    `json:"DiscountIlliquid"`
+}
+
+// AssetEquivalence maps the asset with @Address on @Blockchain to the canonical asset @Canonical,
+// such as a bridged token to the token on its origin chain.
+// If Exchange is empty the equivalence applies to trades on all exchanges.
+type AssetEquivalence struct {
+	Blockchain string `json:"Blockchain"`
+	Address    string `json:"Address"`
+	Exchange   string `json:"Exchange"`
+	Canonical  Asset  `json:"Canonical"`
+}
+
 // Pair substitues the old dia.Pair. It includes the new asset type.
 type Pair struct {
 	QuoteToken Asset
@@ -408,7 +566,13 @@ type Trade struct {
 	ForeignTradeID    string
 	EstimatedUSDPrice float64 // will be filled by the TradesBlockService
 	Source            string
-	VerifiedPair      bool // will be filled by the pairDiscoveryService
+	VerifiedPair      bool   // will be filled by the pairDiscoveryService
+	PoolAddress       string // address of the pool a DEX trade was executed in
+	// Retracted is true if the trade with ForeignTradeID on Source was reverted by a chain reorganization.
+	// A retracted trade deletes the original trade downstream.
+	Retracted bool `json:",omitempty"`
+	// PriceFlagged is true if the estimated USD price deviates from the reference price of the price sanity check.
+	PriceFlagged bool `json:",omitempty"`
 }
 
 type ItinToken struct {
@@ -0,0 +1,152 @@
+package dia
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"time"
+)
+
+// OrderBookLevel is an aggregated price level of an order book.
+type OrderBookLevel struct {
+	Price float64
+	Size  float64
+}
+
+// OrderBook is a level 2 snapshot or diff of the spot order book of a pair on an exchange.
+// In a diff, levels with zero size are removed from the book.
+type OrderBook struct {
+	Exchange    string
+	Symbol      string
+	ForeignName string
+	Bids        []OrderBookLevel
+	Asks        []OrderBookLevel
+	Snapshot    bool
+	// Depth is the number of levels per side maintained by the exchange. If positive, the book is
+	// truncated to Depth levels after applying the update, as diffs do not delete levels out of range.
+	Depth int `json:",omitempty"`
+	Time  time.Time
+}
+
+// OrderBookMetrics summarizes an order book. Depths are in units of the quotation currency of the pair.
+type OrderBookMetrics struct {
+	Exchange       string
+	Symbol         string
+	ForeignName    string
+	MidPrice       float64
+	Spread         float64
+	SpreadRelative float64
+	DepthPercent   float64
+	BidDepth       float64
+	AskDepth       float64
+	Time           time.Time
+}
+
+// MarshalBinary -
+func (ob *OrderBook) MarshalBinary() ([]byte, error) {
+	return json.Marshal(ob)
+}
+
+// UnmarshalBinary -
+func (ob *OrderBook) UnmarshalBinary(data []byte) error {
+	if err := json.Unmarshal(data, &ob); err != nil {
+		return err
+	}
+	return nil
+}
+
+// Apply updates the order book with the snapshot or diff @update.
+// Bids are kept in descending and asks in ascending order of price.
+func (ob *OrderBook) Apply(update *OrderBook) {
+	if update.Snapshot {
+		ob.Bids = applyLevels(nil, update.Bids, true)
+		ob.Asks = applyLevels(nil, update.Asks, false)
+	} else {
+		ob.Bids = applyLevels(ob.Bids, update.Bids, true)
+		ob.Asks = applyLevels(ob.Asks, update.Asks, false)
+	}
+	if update.Depth > 0 {
+		if len(ob.Bids) > update.Depth {
+			ob.Bids = ob.Bids[:update.Depth]
+		}
+		if len(ob.Asks) > update.Depth {
+			ob.Asks = ob.Asks[:update.Depth]
+		}
+	}
+	ob.Exchange = update.Exchange
+	ob.Symbol = update.Symbol
+	ob.ForeignName = update.ForeignName
+	ob.Snapshot = true
+	ob.Time = update.Time
+}
+
+func applyLevels(levels []OrderBookLevel, updates []OrderBookLevel, descending bool) []OrderBookLevel {
+	sizes := make(map[float64]float64, len(levels)+len(updates))
+	for _, level := range levels {
+		sizes[level.Price] = level.Size
+	}
+	for _, update := range updates {
+		if update.Size == 0 {
+			delete(sizes, update.Price)
+			continue
+		}
+		sizes[update.Price] = update.Size
+	}
+	result := make([]OrderBookLevel, 0, len(sizes))
+	for price, size := range sizes {
+		result = append(result, OrderBookLevel{Price: price, Size: size})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if descending {
+			return result[i].Price > result[j].Price
+		}
+		return result[i].Price < result[j].Price
+	})
+	return result
+}
+
+// Top returns a copy of the order book restricted to the best @n levels on each side.
+func (ob *OrderBook) Top(n int) OrderBook {
+	top := *ob
+	if len(top.Bids) > n {
+		top.Bids = top.Bids[:n]
+	}
+	if len(top.Asks) > n {
+		top.Asks = top.Asks[:n]
+	}
+	top.Bids = append([]OrderBookLevel{}, top.Bids...)
+	top.Asks = append([]OrderBookLevel{}, top.Asks...)
+	return top
+}
+
+// Metrics returns mid price, spread and the bid and ask depth within @depthPercent percent of the
+// mid price of an order book with sorted levels.
+func (ob *OrderBook) Metrics(depthPercent float64) (OrderBookMetrics, error) {
+	if len(ob.Bids) == 0 || len(ob.Asks) == 0 {
+		return OrderBookMetrics{}, errors.New("order book is one-sided")
+	}
+	bestBid, bestAsk := ob.Bids[0].Price, ob.Asks[0].Price
+	metrics := OrderBookMetrics{
+		Exchange:     ob.Exchange,
+		Symbol:       ob.Symbol,
+		ForeignName:  ob.ForeignName,
+		MidPrice:     (bestBid + bestAsk) / 2,
+		Spread:       bestAsk - bestBid,
+		DepthPercent: depthPercent,
+		Time:         ob.Time,
+	}
+	metrics.SpreadRelative = metrics.Spread / metrics.MidPrice
+	for _, bid := range ob.Bids {
+		if bid.Price < metrics.MidPrice*(1-depthPercent/100) {
+			break
+		}
+		metrics.BidDepth += bid.Price * bid.Size
+	}
+	for _, ask := range ob.Asks {
+		if ask.Price > metrics.MidPrice*(1+depthPercent/100) {
+			break
+		}
+		metrics.AskDepth += ask.Price * ask.Size
+	}
+	return metrics, nil
+}
@@ -0,0 +1,78 @@
+package dia
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestOrderBookApply(t *testing.T) {
+	d := time.Date(2022, time.May, 1, 12, 0, 0, 0, time.UTC)
+	var ob OrderBook
+	ob.Apply(&OrderBook{
+		Exchange: KrakenExchange,
+		Bids:     []OrderBookLevel{{Price: 99, Size: 2}, {Price: 100, Size: 1}},
+		Asks:     []OrderBookLevel{{Price: 102, Size: 1}, {Price: 101, Size: 3}},
+		Snapshot: true,
+		Time:     d,
+	})
+	ob.Apply(&OrderBook{
+		Exchange: KrakenExchange,
+		Bids:     []OrderBookLevel{{Price: 100, Size: 0}, {Price: 98, Size: 5}},
+		Asks:     []OrderBookLevel{{Price: 101, Size: 2}},
+		Time:     d.Add(time.Second),
+	})
+
+	expectedBids := []OrderBookLevel{{Price: 99, Size: 2}, {Price: 98, Size: 5}}
+	expectedAsks := []OrderBookLevel{{Price: 101, Size: 2}, {Price: 102, Size: 1}}
+	for i := range expectedBids {
+		if len(ob.Bids) != len(expectedBids) || ob.Bids[i] != expectedBids[i] {
+			t.Fatalf("expected bids %v, got %v", expectedBids, ob.Bids)
+		}
+	}
+	for i := range expectedAsks {
+		if len(ob.Asks) != len(expectedAsks) || ob.Asks[i] != expectedAsks[i] {
+			t.Fatalf("expected asks %v, got %v", expectedAsks, ob.Asks)
+		}
+	}
+	if !ob.Time.Equal(d.Add(time.Second)) {
+		t.Errorf("expected time of last update, got %v", ob.Time)
+	}
+	if top := ob.Top(1); len(top.Bids) != 1 || len(top.Asks) != 1 || len(ob.Bids) != 2 {
+		t.Errorf("unexpected top of book %v", top)
+	}
+
+	// Levels pushed out of the exchange's depth are dropped.
+	ob.Apply(&OrderBook{
+		Exchange: KrakenExchange,
+		Bids:     []OrderBookLevel{{Price: 99.5, Size: 1}},
+		Depth:    2,
+		Time:     d.Add(2 * time.Second),
+	})
+	expectedBids = []OrderBookLevel{{Price: 99.5, Size: 1}, {Price: 99, Size: 2}}
+	if len(ob.Bids) != len(expectedBids) || ob.Bids[0] != expectedBids[0] || ob.Bids[1] != expectedBids[1] {
+		t.Errorf("expected bids %v, got %v", expectedBids, ob.Bids)
+	}
+}
+
+func TestOrderBookMetrics(t *testing.T) {
+	ob := OrderBook{
+		Bids: []OrderBookLevel{{Price: 99, Size: 1}, {Price: 98, Size: 2}, {Price: 97, Size: 4}},
+		Asks: []OrderBookLevel{{Price: 101, Size: 1}, {Price: 102, Size: 2}, {Price: 103, Size: 4}},
+	}
+	metrics, err := ob.Metrics(2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if metrics.MidPrice != 100 || metrics.Spread != 2 || metrics.SpreadRelative != 0.02 {
+		t.Errorf("unexpected mid price and spread %v", metrics)
+	}
+	// Levels at 98 and 102 are within 2% of the mid price.
+	if math.Abs(metrics.BidDepth-295) > 1e-9 || math.Abs(metrics.AskDepth-305) > 1e-9 {
+		t.Errorf("unexpected depth %v, %v", metrics.BidDepth, metrics.AskDepth)
+	}
+
+	if _, err := (&OrderBook{Bids: ob.Bids}).Metrics(2); err == nil {
+		t.Error("expected error for one-sided book")
+	}
+}
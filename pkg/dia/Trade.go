@@ -60,3 +60,16 @@ func SwapTrade(t Trade) (Trade, error) {
 
 	return t, nil
 }
+
+// KafkaKey partitions trades by quote token, such that all trades of an asset are
+// consumed by the same instance of a service.
+func (t *Trade) KafkaKey() []byte {
+	return []byte(t.QuoteToken.Blockchain + "-" + t.QuoteToken.Address)
+}
+
+// CanonicalKafkaKey partitions trades by the canonical asset of their quote token in @equivalences, such that
+// trades of bridged assets are consumed by the same instance of a service as trades of their canonical asset.
+func (t *Trade) CanonicalKafkaKey(equivalences *AssetEquivalenceTable) []byte {
+	quotetoken, _ := equivalences.Canonical(t.QuoteToken, t.Source)
+	return []byte(quotetoken.Blockchain + "-" + quotetoken.Address)
+}
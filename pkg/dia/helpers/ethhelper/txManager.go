@@ -0,0 +1,374 @@
+package ethhelper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	log "github.com/sirupsen/logrus"
+)
+
+var (
+	// ErrTxNotConfirmed is returned if no receipt of a transaction or its replacements is found in time.
+	ErrTxNotConfirmed = errors.New("transaction not confirmed")
+	// ErrTxReverted is returned along with the receipt of a reverted transaction.
+	ErrTxReverted = errors.New("transaction reverted")
+)
+
+// TxBackend is the part of an ethclient.Client used by the TxManager.
+type TxBackend interface {
+	PendingNonceAt(ctx context.Context, account common.Address) (uint64, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+	SendTransaction(ctx context.Context, tx *types.Transaction) error
+	TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error)
+}
+
+// TransactFunc returns a signed transaction created with @opts, such as a method of a contract binding.
+// The transaction must not be sent, which is ensured by opts.NoSend.
+type TransactFunc func(opts *bind.TransactOpts) (*types.Transaction, error)
+
+// TxConfig holds the fee caps and timeouts of a TxManager.
+type TxConfig struct {
+	// MaxFeePerGas caps the fee cap of EIP-1559 transactions and the gas price of legacy transactions. nil means no cap.
+	MaxFeePerGas *big.Int
+	// MaxPriorityFeePerGas caps the tip of EIP-1559 transactions. nil means no cap.
+	MaxPriorityFeePerGas *big.Int
+	// A transaction is replaced by one with fees raised by FeeBumpPercent if it is not mined within ConfirmationTimeout.
+	ConfirmationTimeout time.Duration
+	MaxReplacements     int
+	FeeBumpPercent      int64
+	PollInterval        time.Duration
+	// A transaction which cannot be sent or confirmed is retried MaxRetries times after RetryDelay.
+	MaxRetries int
+	RetryDelay time.Duration
+}
+
+// DefaultTxConfig returns a config without fee caps.
+func DefaultTxConfig() TxConfig {
+	return TxConfig{
+		ConfirmationTimeout: 2 * time.Minute,
+		MaxReplacements:     3,
+		// Nodes reject replacements whose fees are raised by less than 10%.
+		FeeBumpPercent: 20,
+		PollInterval:   2 * time.Second,
+		MaxRetries:     2,
+		RetryDelay:     10 * time.Second,
+	}
+}
+
+// txFees are the fees of an EIP-1559 transaction, or the gas price of a legacy transaction
+// on chains without base fee.
+type txFees struct {
+	gasPrice  *big.Int
+	gasFeeCap *big.Int
+	gasTipCap *big.Int
+}
+
+// TxManager sends the transactions of a single account and waits for their confirmation.
+// Nonces are tracked locally, such that several transactions can be pending at the same time.
+// A TxManager is safe for concurrent use and should be shared by everything sending from its account.
+type TxManager struct {
+	backend TxBackend
+	auth    *bind.TransactOpts
+	config  TxConfig
+
+	mu          sync.Mutex
+	nonce       uint64
+	nonceSynced bool
+	// inFlight is the number of transactions which got a nonce and did not return yet.
+	inFlight int
+	// unsent are the nonces of returned transactions which were never sent. They are handed out
+	// again before new nonces, as later nonces cannot be mined before them.
+	unsent []uint64
+}
+
+// NewTxManager returns a manager sending transactions signed by @auth through @backend.
+func NewTxManager(backend TxBackend, auth *bind.TransactOpts, config TxConfig) *TxManager {
+	return &TxManager{
+		backend: backend,
+		auth:    auth,
+		config:  config,
+	}
+}
+
+// Transact sends the transaction created by @transact and waits for its receipt. Failed attempts are
+// retried according to the config. The returned transaction is the one which was mined or else the last
+// one sent, which is nil if no transaction was sent at all.
+// A reverted transaction is returned along with its receipt and ErrTxReverted.
+func (m *TxManager) Transact(ctx context.Context, transact TransactFunc) (tx *types.Transaction, receipt *types.Receipt, err error) {
+	for attempt := 0; attempt <= m.config.MaxRetries; attempt++ {
+		if attempt > 0 {
+			log.Warnf("retry transaction of %s after error: %v", m.auth.From.Hex(), err)
+			select {
+			case <-time.After(m.config.RetryDelay):
+			case <-ctx.Done():
+				return tx, nil, ctx.Err()
+			}
+		}
+		var sent *types.Transaction
+		sent, receipt, err = m.transact(ctx, transact)
+		if sent != nil {
+			tx = sent
+		}
+		if err == nil || errors.Is(err, ErrTxReverted) || ctx.Err() != nil {
+			return
+		}
+	}
+	return
+}
+
+// transact sends a transaction with the next nonce and replaces it with higher fees until it is mined.
+func (m *TxManager) transact(ctx context.Context, transact TransactFunc) (*types.Transaction, *types.Receipt, error) {
+	nonce, err := m.nextNonce(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	var sent []*types.Transaction
+	defer func() {
+		m.releaseNonce(nonce, len(sent) > 0)
+	}()
+	fees, err := m.suggestFees(ctx)
+	if err != nil {
+		m.resyncNonce()
+		return nil, nil, err
+	}
+
+	for replacement := 0; replacement <= m.config.MaxReplacements; replacement++ {
+		if replacement > 0 {
+			bumped := m.bumpFees(fees)
+			if bumped.equal(fees) {
+				log.Warnf("fees of transaction with nonce %d of %s reached their caps", nonce, m.auth.From.Hex())
+			}
+			fees = bumped
+		}
+		if replacement == 0 || !fees.equal(feesOf(sent[len(sent)-1])) {
+			tx, err := m.send(ctx, transact, nonce, fees)
+			if err != nil {
+				if len(sent) == 0 || isNonceTooLow(err) {
+					// The nonce is not used by any of our transactions or it is used by another one.
+					m.resyncNonce()
+					return lastTx(sent), nil, err
+				}
+				// A previous transaction may still be mined.
+				log.Warnf("replace transaction %s: %v", sent[len(sent)-1].Hash().Hex(), err)
+			} else {
+				if replacement > 0 {
+					log.Infof("replace transaction %s with %s", sent[len(sent)-1].Hash().Hex(), tx.Hash().Hex())
+				}
+				sent = append(sent, tx)
+			}
+		}
+
+		tx, receipt, err := m.waitReceipt(ctx, sent)
+		if err == nil {
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return tx, receipt, ErrTxReverted
+			}
+			return tx, receipt, nil
+		}
+		if err != ErrTxNotConfirmed {
+			return lastTx(sent), nil, err
+		}
+	}
+	// Later nonces cannot be mined before this one, which is reused after the resync if the node dropped it.
+	m.resyncNonce()
+	return lastTx(sent), nil, ErrTxNotConfirmed
+}
+
+// send creates a transaction with @nonce and @fees through @transact and sends it.
+func (m *TxManager) send(ctx context.Context, transact TransactFunc, nonce uint64, fees txFees) (*types.Transaction, error) {
+	opts := *m.auth
+	opts.Context = ctx
+	opts.Nonce = new(big.Int).SetUint64(nonce)
+	opts.GasPrice = fees.gasPrice
+	opts.GasFeeCap = fees.gasFeeCap
+	opts.GasTipCap = fees.gasTipCap
+	opts.NoSend = true
+	tx, err := transact(&opts)
+	if err != nil {
+		return nil, err
+	}
+	return tx, m.backend.SendTransaction(ctx, tx)
+}
+
+// waitReceipt polls the receipts of @sent, which are transactions with the same nonce, until one of them
+// is mined or the confirmation timeout is reached.
+func (m *TxManager) waitReceipt(ctx context.Context, sent []*types.Transaction) (*types.Transaction, *types.Receipt, error) {
+	timeout := time.NewTimer(m.config.ConfirmationTimeout)
+	defer timeout.Stop()
+	ticker := time.NewTicker(m.config.PollInterval)
+	defer ticker.Stop()
+	for {
+		for _, tx := range sent {
+			receipt, err := m.backend.TransactionReceipt(ctx, tx.Hash())
+			if err == nil && receipt != nil {
+				return tx, receipt, nil
+			}
+		}
+		select {
+		case <-ticker.C:
+		case <-timeout.C:
+			return nil, nil, ErrTxNotConfirmed
+		case <-ctx.Done():
+			return nil, nil, ctx.Err()
+		}
+	}
+}
+
+// nextNonce returns the nonce of the next transaction, which must be released through releaseNonce.
+// The local nonce is synced with the pending nonce of the node on first use and after failures.
+// While other transactions are in flight, the node may not know their nonces yet, so the local nonce
+// is only moved forward and synced again with the next transaction.
+// Nonces of transactions which were released without being sent are reused first.
+func (m *TxManager) nextNonce(ctx context.Context) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if !m.nonceSynced {
+		nonce, err := m.backend.PendingNonceAt(ctx, m.auth.From)
+		if err != nil {
+			return 0, err
+		}
+		if m.inFlight == 0 || nonce > m.nonce {
+			m.nonce = nonce
+		}
+		// Unsent nonces below the pending nonce of the node were used by other transactions.
+		unsent := m.unsent[:0]
+		for _, n := range m.unsent {
+			if n >= nonce {
+				unsent = append(unsent, n)
+			}
+		}
+		m.unsent = unsent
+		m.nonceSynced = m.inFlight == 0
+	}
+	m.inFlight++
+	if len(m.unsent) > 0 {
+		lowest := 0
+		for i, n := range m.unsent {
+			if n < m.unsent[lowest] {
+				lowest = i
+			}
+		}
+		nonce := m.unsent[lowest]
+		m.unsent = append(m.unsent[:lowest], m.unsent[lowest+1:]...)
+		return nonce, nil
+	}
+	nonce := m.nonce
+	m.nonce++
+	return nonce, nil
+}
+
+// releaseNonce marks the transaction with @nonce from nextNonce as returned. If no transaction
+// was @sent with the nonce, it is reused by the next transaction. Once no transactions are in flight,
+// unsent nonces are dropped and the local nonce is synced with the node, which then knows all used nonces.
+func (m *TxManager) releaseNonce(nonce uint64, sent bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.inFlight--
+	if !sent {
+		m.unsent = append(m.unsent, nonce)
+	}
+	if m.inFlight == 0 && len(m.unsent) > 0 {
+		m.unsent = nil
+		m.nonceSynced = false
+	}
+}
+
+func (m *TxManager) resyncNonce() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nonceSynced = false
+}
+
+// suggestFees returns EIP-1559 fees with a fee cap of twice the current base fee plus the suggested tip.
+// Chains without base fee get a legacy gas price. All fees are capped according to the config.
+func (m *TxManager) suggestFees(ctx context.Context) (txFees, error) {
+	header, err := m.backend.HeaderByNumber(ctx, nil)
+	if err != nil {
+		return txFees{}, err
+	}
+	if header.BaseFee == nil {
+		gasPrice, err := m.backend.SuggestGasPrice(ctx)
+		if err != nil {
+			return txFees{}, err
+		}
+		return txFees{gasPrice: capFee(gasPrice, m.config.MaxFeePerGas)}, nil
+	}
+	tip, err := m.backend.SuggestGasTipCap(ctx)
+	if err != nil {
+		return txFees{}, err
+	}
+	tip = capFee(tip, m.config.MaxPriorityFeePerGas)
+	feeCap := new(big.Int).Add(new(big.Int).Mul(header.BaseFee, big.NewInt(2)), tip)
+	feeCap = capFee(feeCap, m.config.MaxFeePerGas)
+	return txFees{gasFeeCap: feeCap, gasTipCap: capFee(tip, feeCap)}, nil
+}
+
+// bumpFees raises all fees of @fees by FeeBumpPercent within their caps.
+func (m *TxManager) bumpFees(fees txFees) txFees {
+	bump := func(fee *big.Int, max *big.Int) *big.Int {
+		if fee == nil {
+			return nil
+		}
+		bumped := new(big.Int).Mul(fee, big.NewInt(100+m.config.FeeBumpPercent))
+		bumped.Div(bumped, big.NewInt(100))
+		if bumped.Cmp(fee) <= 0 {
+			bumped.Add(fee, big.NewInt(1))
+		}
+		return capFee(bumped, max)
+	}
+	bumped := txFees{
+		gasPrice:  bump(fees.gasPrice, m.config.MaxFeePerGas),
+		gasFeeCap: bump(fees.gasFeeCap, m.config.MaxFeePerGas),
+		gasTipCap: bump(fees.gasTipCap, m.config.MaxPriorityFeePerGas),
+	}
+	if bumped.gasFeeCap != nil {
+		bumped.gasTipCap = capFee(bumped.gasTipCap, bumped.gasFeeCap)
+	}
+	return bumped
+}
+
+func (fees txFees) equal(other txFees) bool {
+	equal := func(a *big.Int, b *big.Int) bool {
+		if a == nil || b == nil {
+			return a == b
+		}
+		return a.Cmp(b) == 0
+	}
+	return equal(fees.gasPrice, other.gasPrice) && equal(fees.gasFeeCap, other.gasFeeCap) && equal(fees.gasTipCap, other.gasTipCap)
+}
+
+// feesOf returns the fees @tx was sent with.
+func feesOf(tx *types.Transaction) txFees {
+	if tx.Type() == types.LegacyTxType {
+		return txFees{gasPrice: tx.GasPrice()}
+	}
+	return txFees{gasFeeCap: tx.GasFeeCap(), gasTipCap: tx.GasTipCap()}
+}
+
+// capFee returns the minimum of @fee and @max, where a nil @max means no cap.
+func capFee(fee *big.Int, max *big.Int) *big.Int {
+	if max != nil && fee.Cmp(max) > 0 {
+		return new(big.Int).Set(max)
+	}
+	return fee
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
+
+func lastTx(sent []*types.Transaction) *types.Transaction {
+	if len(sent) == 0 {
+		return nil
+	}
+	return sent[len(sent)-1]
+}
@@ -0,0 +1,236 @@
+package ethhelper
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// mockBackend mines every sent transaction whose fee cap reaches minFeeCap.
+type mockBackend struct {
+	mu        sync.Mutex
+	nonce     uint64
+	baseFee   *big.Int
+	tip       *big.Int
+	minFeeCap *big.Int
+	sendErrs  []error
+	revert    bool
+	sent      []*types.Transaction
+	receipts  map[common.Hash]*types.Receipt
+}
+
+func newMockBackend() *mockBackend {
+	return &mockBackend{
+		nonce:     5,
+		baseFee:   big.NewInt(100),
+		tip:       big.NewInt(10),
+		minFeeCap: big.NewInt(0),
+		receipts:  make(map[common.Hash]*types.Receipt),
+	}
+}
+
+func (b *mockBackend) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.nonce, nil
+}
+
+func (b *mockBackend) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	return &types.Header{BaseFee: b.baseFee}, nil
+}
+
+func (b *mockBackend) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(150), nil
+}
+
+func (b *mockBackend) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return b.tip, nil
+}
+
+func (b *mockBackend) SendTransaction(ctx context.Context, tx *types.Transaction) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if len(b.sendErrs) > 0 {
+		err := b.sendErrs[0]
+		b.sendErrs = b.sendErrs[1:]
+		return err
+	}
+	b.sent = append(b.sent, tx)
+	if tx.Nonce() >= b.nonce {
+		b.nonce = tx.Nonce() + 1
+	}
+	if tx.GasFeeCap().Cmp(b.minFeeCap) >= 0 {
+		status := types.ReceiptStatusSuccessful
+		if b.revert {
+			status = types.ReceiptStatusFailed
+		}
+		b.receipts[tx.Hash()] = &types.Receipt{TxHash: tx.Hash(), Status: status, GasUsed: 21000}
+	}
+	return nil
+}
+
+func (b *mockBackend) TransactionReceipt(ctx context.Context, txHash common.Hash) (*types.Receipt, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	receipt, ok := b.receipts[txHash]
+	if !ok {
+		return nil, ethereum.NotFound
+	}
+	return receipt, nil
+}
+
+func mockTransact(opts *bind.TransactOpts) (*types.Transaction, error) {
+	if opts.GasPrice != nil {
+		return types.NewTx(&types.LegacyTx{Nonce: opts.Nonce.Uint64(), GasPrice: opts.GasPrice}), nil
+	}
+	return types.NewTx(&types.DynamicFeeTx{Nonce: opts.Nonce.Uint64(), GasFeeCap: opts.GasFeeCap, GasTipCap: opts.GasTipCap}), nil
+}
+
+func testTxConfig() TxConfig {
+	config := DefaultTxConfig()
+	config.ConfirmationTimeout = 20 * time.Millisecond
+	config.PollInterval = time.Millisecond
+	config.RetryDelay = 0
+	return config
+}
+
+func TestTxManagerTransact(t *testing.T) {
+	backend := newMockBackend()
+	config := testTxConfig()
+	config.MaxFeePerGas = big.NewInt(200)
+	manager := NewTxManager(backend, &bind.TransactOpts{}, config)
+
+	for i, expectedNonce := range []uint64{5, 6} {
+		tx, receipt, err := manager.Transact(context.Background(), mockTransact)
+		if err != nil {
+			t.Fatalf("transaction %d: %v", i, err)
+		}
+		if tx.Nonce() != expectedNonce || receipt.TxHash != tx.Hash() {
+			t.Errorf("transaction %d: expected nonce %d, got %d", i, expectedNonce, tx.Nonce())
+		}
+		// 2*100+10 is capped at 200.
+		if tx.GasFeeCap().Int64() != 200 || tx.GasTipCap().Int64() != 10 {
+			t.Errorf("transaction %d: unexpected fees %v %v", i, tx.GasFeeCap(), tx.GasTipCap())
+		}
+	}
+
+	backend.baseFee = nil
+	tx, _, err := manager.Transact(context.Background(), mockTransact)
+	if err != nil || tx.Type() != types.LegacyTxType || tx.GasPrice().Int64() != 150 {
+		t.Errorf("expected legacy transaction with gas price 150, got %v (%v)", tx, err)
+	}
+}
+
+func TestTxManagerReplaceStuckTransaction(t *testing.T) {
+	backend := newMockBackend()
+	backend.minFeeCap = big.NewInt(240)
+	manager := NewTxManager(backend, &bind.TransactOpts{}, testTxConfig())
+
+	tx, receipt, err := manager.Transact(context.Background(), mockTransact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 210 is stuck and replaced by 252 with the same nonce.
+	if len(backend.sent) != 2 || backend.sent[0].Nonce() != backend.sent[1].Nonce() {
+		t.Fatalf("expected a single replacement, got %v", backend.sent)
+	}
+	if tx.GasFeeCap().Int64() != 252 || receipt.TxHash != tx.Hash() {
+		t.Errorf("expected replacement with fee cap 252, got %v", tx.GasFeeCap())
+	}
+
+	backend.minFeeCap = big.NewInt(1000)
+	_, _, err = manager.Transact(context.Background(), mockTransact)
+	if err != ErrTxNotConfirmed {
+		t.Errorf("expected %v, got %v", ErrTxNotConfirmed, err)
+	}
+}
+
+func TestTxManagerRetry(t *testing.T) {
+	backend := newMockBackend()
+	backend.sendErrs = []error{errors.New("connection refused")}
+	manager := NewTxManager(backend, &bind.TransactOpts{}, testTxConfig())
+
+	tx, _, err := manager.Transact(context.Background(), mockTransact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The nonce of the failed attempt is reused.
+	if tx.Nonce() != 5 {
+		t.Errorf("expected nonce 5, got %d", tx.Nonce())
+	}
+
+	backend.revert = true
+	_, receipt, err := manager.Transact(context.Background(), mockTransact)
+	if err != ErrTxReverted || receipt == nil || len(backend.sent) != 2 {
+		t.Errorf("expected a single reverted transaction, got %v", err)
+	}
+}
+
+func TestTxManagerResyncWithTransactionsInFlight(t *testing.T) {
+	backend := newMockBackend()
+	manager := NewTxManager(backend, &bind.TransactOpts{}, testTxConfig())
+	ctx := context.Background()
+
+	// The transaction with nonce 5 is in flight, but not sent to the node yet.
+	if nonce, err := manager.nextNonce(ctx); err != nil || nonce != 5 {
+		t.Fatalf("expected nonce 5, got %d (%v)", nonce, err)
+	}
+	// The transaction with nonce 6 is sent and fails.
+	if nonce, err := manager.nextNonce(ctx); err != nil || nonce != 6 {
+		t.Fatalf("expected nonce 6, got %d (%v)", nonce, err)
+	}
+	manager.resyncNonce()
+	manager.releaseNonce(6, true)
+
+	// The node reports nonce 5, which must not be reused while the first transaction is in flight.
+	nonce, err := manager.nextNonce(ctx)
+	if err != nil || nonce != 7 {
+		t.Fatalf("expected nonce 7, got %d (%v)", nonce, err)
+	}
+	manager.releaseNonce(7, true)
+	manager.releaseNonce(5, true)
+
+	// Without transactions in flight, the nonce is synced with the node.
+	if nonce, err := manager.nextNonce(ctx); err != nil || nonce != 5 {
+		t.Errorf("expected nonce 5, got %d (%v)", nonce, err)
+	}
+}
+
+func TestTxManagerReuseUnsentNonce(t *testing.T) {
+	backend := newMockBackend()
+	backend.sendErrs = []error{errors.New("connection refused")}
+	manager := NewTxManager(backend, &bind.TransactOpts{}, testTxConfig())
+	ctx := context.Background()
+
+	// The transaction with nonce 5 is in flight while the first attempt of the next transaction
+	// fails before anything is sent. Its nonce 6 is reused, as 7 could not be mined before 6.
+	if nonce, err := manager.nextNonce(ctx); err != nil || nonce != 5 {
+		t.Fatalf("expected nonce 5, got %d (%v)", nonce, err)
+	}
+	tx, _, err := manager.Transact(ctx, mockTransact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 6 {
+		t.Errorf("expected nonce 6, got %d", tx.Nonce())
+	}
+
+	// The transaction with nonce 5 returns without being sent, so the node still waits for nonce 5.
+	backend.nonce = 5
+	manager.releaseNonce(5, false)
+	tx, _, err = manager.Transact(ctx, mockTransact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tx.Nonce() != 5 {
+		t.Errorf("expected nonce 5, got %d", tx.Nonce())
+	}
+}
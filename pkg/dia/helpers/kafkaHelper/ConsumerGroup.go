@@ -0,0 +1,174 @@
+package kafkaHelper
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	log "github.com/sirupsen/logrus"
+)
+
+// KafkaMessageWithAKey is implemented by messages which are partitioned by key.
+type KafkaMessageWithAKey interface {
+	KafkaKey() []byte
+}
+
+// NewGroupReader returns a reader consuming all partitions of @topic assigned to it as member of
+// the consumer group @groupID. Offsets are only committed through CommitMessages, i.e. a restarted
+// service resumes at the first message that was not committed. A new consumer group starts reading
+// at the last offset of each partition.
+func NewGroupReader(topic int, groupID string) *kafka.Reader {
+	return kafka.NewReader(kafka.ReaderConfig{
+		Brokers:     KafkaConfig.KafkaUrl,
+		GroupID:     groupID,
+		Topic:       getTopic(topic),
+		MinBytes:    0,
+		MaxBytes:    10e6, // 10MB
+		StartOffset: kafka.LastOffset,
+	})
+}
+
+// NewPartitionedWriter returns a writer which assigns messages to the partitions of @topic by
+// hashing their key, such that all messages with the same key are written to the same partition.
+func NewPartitionedWriter(topic int, async bool) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers:    KafkaConfig.KafkaUrl,
+		Topic:      getTopic(topic),
+		Balancer:   &kafka.Hash{},
+		Async:      async,
+		BatchBytes: 1e9, // 1GB
+	})
+}
+
+// NewPartitionWriter returns a writer which writes messages to the partition set in the message.
+// It is used by services writing their results to the partition of the message they processed.
+func NewPartitionWriter(topic int) *kafka.Writer {
+	return kafka.NewWriter(kafka.WriterConfig{
+		Brokers: KafkaConfig.KafkaUrl,
+		Topic:   getTopic(topic),
+		Balancer: kafka.BalancerFunc(func(msg kafka.Message, partitions ...int) int {
+			return msg.Partition
+		}),
+		Async:      false,
+		BatchBytes: 1e9, // 1GB
+	})
+}
+
+// WriteMessageToPartition writes @m to @partition through the writer @w returned by NewPartitionWriter.
+func WriteMessageToPartition(w *kafka.Writer, m KafkaMessage, partition int) error {
+	value, err := m.MarshalBinary()
+	if err != nil {
+		log.Errorln("Skipping write of message ", err, m)
+		return err
+	}
+	err = w.WriteMessages(context.Background(), kafka.Message{Partition: partition, Value: value})
+	if err != nil {
+		log.Errorln("WriteMessageToPartition error:", err, "sizeMessage:", float64(len(value))/(1024.0*1024.0), "MB")
+	}
+	return err
+}
+
+// WriteMessageToPartitionWithRetryOnError writes @m to @partition through the writer @w returned by
+// NewPartitionWriter, retrying until the write succeeds.
+func WriteMessageToPartitionWithRetryOnError(w *kafka.Writer, m KafkaMessage, partition int) {
+	for {
+		err := WriteMessageToPartition(w, m, partition)
+		if err == nil {
+			return
+		}
+		log.Println("WriteMessageToPartitionWithRetryOnError retrying...")
+		time.Sleep(retryDelay)
+	}
+}
+
+// WriteMessageWithKey writes @m with @key through a writer returned by NewPartitionedWriter.
+func WriteMessageWithKey(w *kafka.Writer, m KafkaMessage, key []byte) error {
+	value, err := m.MarshalBinary()
+	if err != nil {
+		log.Errorln("Skipping write of message ", err, m)
+		return err
+	}
+	err = w.WriteMessages(context.Background(), kafka.Message{Key: key, Value: value})
+	if err != nil {
+		log.Errorln("WriteMessageWithKey error:", err, "sizeMessage:", float64(len(value))/(1024.0*1024.0), "MB")
+	}
+	return err
+}
+
+// Partitions returns the sorted IDs of all partitions of @topic.
+func Partitions(topic int) (partitions []int, err error) {
+	for _, ip := range KafkaConfig.KafkaUrl {
+		var kafkaPartitions []kafka.Partition
+		kafkaPartitions, err = kafka.LookupPartitions(context.Background(), "tcp", ip, getTopic(topic))
+		if err != nil {
+			log.Errorln("Partitions lookup error: <", err, "> ", ip, " topic:", topic)
+			continue
+		}
+		for _, p := range kafkaPartitions {
+			partitions = append(partitions, p.ID)
+		}
+		sort.Ints(partitions)
+		return
+	}
+	if err == nil {
+		err = errors.New("no kafka broker configured")
+	}
+	return
+}
+
+// PartitionForKey returns the partition out of @partitions a partitioned writer assigns to @key.
+func PartitionForKey(key []byte, partitions []int) int {
+	balancer := kafka.Hash{}
+	return balancer.Balance(kafka.Message{Key: key}, partitions...)
+}
+
+// WriteMessageWithRetryOnError writes @m to @w, retrying until the write succeeds.
+func WriteMessageWithRetryOnError(w *kafka.Writer, m KafkaMessage) {
+	for {
+		err := WriteMessage(w, m)
+		if err == nil {
+			return
+		}
+		log.Println("WriteMessageWithRetryOnError retrying...")
+		time.Sleep(retryDelay)
+	}
+}
+
+// Committer collects messages fetched from a consumer group reader and commits their offsets
+// once the results of processing them are written.
+type Committer struct {
+	reader   *kafka.Reader
+	lock     sync.Mutex
+	messages []kafka.Message
+}
+
+// NewCommitter returns a Committer for messages fetched from the consumer group reader @reader.
+func NewCommitter(reader *kafka.Reader) *Committer {
+	return &Committer{reader: reader}
+}
+
+// Add marks @m as processed. Its offset is committed with the next call of Commit.
+func (c *Committer) Add(m kafka.Message) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.messages = append(c.messages, m)
+}
+
+// Commit commits the offsets of all messages added since the last successful commit.
+func (c *Committer) Commit() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if len(c.messages) == 0 {
+		return nil
+	}
+	err := c.reader.CommitMessages(context.Background(), c.messages...)
+	if err != nil {
+		// Messages are kept and committed with the next call.
+		return err
+	}
+	c.messages = nil
+	return nil
+}
@@ -40,6 +40,8 @@ const (
 
 	TopicFiltersBlockDone = 14
 
+	TopicOrderBooks = 15
+
 	retryDelay           = 2 * time.Second
 	TopicOptionOrderBook = 13
 )
@@ -64,6 +66,7 @@ func getTopic(topic int) string {
 		6:  "tradesBlockHistorical",
 		7:  "tradesEstimation",
 		14: "filtersblockHistoricalDone",
+		15: "orderBooks",
 	}
 	result, ok := topicMap[topic]
 	if !ok {
@@ -78,9 +81,14 @@ func init() {
 
 // WithRetryOnError
 func ReadOffset(topic int) (offset int64, err error) {
+	return ReadPartitionOffset(topic, 0)
+}
+
+// ReadPartitionOffset returns the offset of the next message written to @partition of @topic.
+func ReadPartitionOffset(topic int, partition int) (offset int64, err error) {
 	for _, ip := range KafkaConfig.KafkaUrl {
 		var conn *kafka.Conn
-		conn, err = kafka.DialLeader(context.Background(), "tcp", ip, getTopic(topic), 0)
+		conn, err = kafka.DialLeader(context.Background(), "tcp", ip, getTopic(topic), partition)
 		if err != nil {
 			log.Errorln("ReadOffset conn error: <", err, "> ", ip)
 		} else {
@@ -163,6 +171,9 @@ func NewReader(topic int) *kafka.Reader {
 
 func WriteMessage(w *kafka.Writer, m KafkaMessage) error {
 	key := []byte("helloKafka")
+	if k, ok := m.(KafkaMessageWithAKey); ok {
+		key = k.KafkaKey()
+	}
 	value, err := m.MarshalBinary()
 	if err == nil && value != nil {
 		err = w.WriteMessages(context.Background(),
@@ -251,13 +262,35 @@ func GetLastElement(topic int) (interface{}, error) {
 	}
 }
 
+// GetLastElementOfPartition returns the last message in @partition of @topic.
+func GetLastElementOfPartition(topic int, partition int) (interface{}, error) {
+	offset, err := ReadPartitionOffset(topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	offset--
+	if offset < 0 {
+		return nil, io.EOF
+	}
+	e, err := GetPartitionElements(topic, partition, offset, 1)
+	if err != nil {
+		return nil, err
+	}
+	return e[0], nil
+}
+
 func GetElements(topic int, offset int64, nbElements int) ([]interface{}, error) {
+	return GetPartitionElements(topic, 0, offset, nbElements)
+}
+
+// GetPartitionElements returns @nbElements messages of @partition of @topic starting at @offset.
+func GetPartitionElements(topic int, partition int, offset int64, nbElements int) ([]interface{}, error) {
 
 	var result []interface{}
 
 	var maxOffset = offset + int64(nbElements)
 
-	conn, err := kafka.DialLeader(context.Background(), "tcp", KafkaConfig.KafkaUrl[0], getTopic(topic), 0)
+	conn, err := kafka.DialLeader(context.Background(), "tcp", KafkaConfig.KafkaUrl[0], getTopic(topic), partition)
 
 	if err != nil {
 		log.Errorln("kafka error:", err)
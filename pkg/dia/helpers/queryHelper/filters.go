@@ -151,6 +151,42 @@ func FilterVWAPIR(tradeBlocks []Block, asset dia.Asset, blockSize int) (filterPo
 	return filterPoints
 }
 
+// FilterTWAP returns the time weighted average price for each block in @tradeBlocks.
+// The last trade's price of a block is weighted until the end of the block.
+func FilterTWAP(tradeBlocks []Block, asset dia.Asset, blockSize int) (filterPoints []dia.FilterPoint) {
+	var lastfp *dia.FilterPoint
+
+	for _, block := range tradeBlocks {
+		if len(block.Trades) > 0 {
+			blockStart := time.Unix(0, block.TimeStamp)
+			twapFilter := filters.NewFilterTWAP(asset, "", blockStart, blockSize)
+
+			for _, trade := range block.Trades {
+				twapFilter.Compute(trade)
+			}
+
+			twapFilter.FinalCompute(blockStart.Add(time.Duration(blockSize) * time.Second))
+			fp := twapFilter.FilterPointForBlock()
+			if fp != nil && fp.Value > 0 {
+				fp.Time = time.Unix(block.TimeStamp/1e9, 0)
+				filterPoints = append(filterPoints, *fp)
+				lastfp = fp
+			} else {
+				if lastfp != nil {
+					lastfp.Time = time.Unix(block.TimeStamp/1e9, 0)
+					filterPoints = append(filterPoints, *lastfp)
+				}
+			}
+		} else {
+			if lastfp != nil {
+				lastfp.Time = time.Unix(block.TimeStamp/1e9, 0)
+				filterPoints = append(filterPoints, *lastfp)
+			}
+		}
+	}
+	return filterPoints
+}
+
 func FilterMEDIR(tradeBlocks []Block, asset dia.Asset, blockSize int) (filterPoints []dia.FilterPoint) {
 	var lastfp *dia.FilterPoint
 
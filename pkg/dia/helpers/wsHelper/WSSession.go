@@ -0,0 +1,352 @@
+package wsHelper
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	wsSessionMinBackoff = 1 * time.Second
+	wsSessionMaxBackoff = 2 * time.Minute
+)
+
+// ErrWSSessionClosed is returned by reads and writes on a closed WSSession.
+var ErrWSSessionClosed = errors.New("websocket session closed")
+
+// WSGap is a period during which a WSSession was disconnected, i.e. trades may be missing.
+type WSGap struct {
+	Exchange string
+	// Start is the time of the last message received before the connection dropped.
+	Start time.Time
+	// End is the time the session was connected and resubscribed again.
+	End time.Time
+	// Attempts is the number of dial attempts needed to reconnect.
+	Attempts int
+}
+
+// WSSessionConfig configures a WSSession. Only URL is required.
+type WSSessionConfig struct {
+	URL string
+	// Dial replaces dialing URL, for instance to fetch a connection token first.
+	Dial func() (*ws.Conn, error)
+	// PingInterval is the interval in which heartbeats are sent. Zero disables heartbeats.
+	PingInterval time.Duration
+	// PingMessage returns the heartbeat sent as JSON. If nil, a websocket ping frame is sent.
+	PingMessage func() interface{}
+	// PingText is sent as heartbeat in a text message if set, for APIs expecting a plain "ping".
+	PingText string
+	// ReadTimeout is the duration after which a connection without incoming messages is considered
+	// dead and reconnected. Zero disables the timeout.
+	ReadTimeout time.Duration
+	// MinBackoff and MaxBackoff bound the exponential backoff between dial attempts.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// OnGap is called after each reconnect with the period during which the session was disconnected.
+	OnGap func(WSGap)
+}
+
+// WSSession is a websocket connection which is transparently reconnected with exponential backoff
+// whenever reading fails. All subscriptions made through Subscribe are sent again after reconnecting,
+// such that all pairs of a scraper keep running if the socket drops.
+// Reads must be done by a single goroutine, writes are safe for concurrent use.
+type WSSession struct {
+	exchange string
+	config   WSSessionConfig
+
+	connLock sync.Mutex
+	conn     *ws.Conn
+	// writeLock serializes writes on conn.
+	writeLock sync.Mutex
+
+	subscriptionsLock sync.Mutex
+	subscriptionKeys  []string
+	subscriptions     map[string]interface{}
+
+	lastMessage time.Time
+	reconnects  int
+	shutdown    chan struct{}
+	closeOnce   sync.Once
+}
+
+// NewWSSession returns a WSSession for @exchange. If the first dial fails, the session is connected
+// on the first read.
+func NewWSSession(exchange string, config WSSessionConfig) *WSSession {
+	if config.MinBackoff == 0 {
+		config.MinBackoff = wsSessionMinBackoff
+	}
+	if config.MaxBackoff == 0 {
+		config.MaxBackoff = wsSessionMaxBackoff
+	}
+	s := &WSSession{
+		exchange:      exchange,
+		config:        config,
+		subscriptions: make(map[string]interface{}),
+		lastMessage:   time.Now(),
+		shutdown:      make(chan struct{}),
+	}
+	conn, err := s.dial()
+	if err != nil {
+		log.Errorf("%s websocket: dial: %v", exchange, err)
+	} else {
+		s.conn = conn
+	}
+	if config.PingInterval > 0 {
+		go s.heartbeat()
+	}
+	return s
+}
+
+func (s *WSSession) dial() (*ws.Conn, error) {
+	if s.config.Dial != nil {
+		return s.config.Dial()
+	}
+	// The default dialer has a handshake timeout, such that a reconnect cannot hang on an unresponsive server.
+	conn, _, err := ws.DefaultDialer.Dial(s.config.URL, nil)
+	return conn, err
+}
+
+// ReadMessage returns the next message. If the connection drops, it is reconnected and all
+// subscriptions are renewed before reading on. An error is only returned once the session is closed.
+func (s *WSSession) ReadMessage() (messageType int, message []byte, err error) {
+	for {
+		conn := s.currentConn()
+		if conn == nil {
+			if err = s.reconnect(); err != nil {
+				return
+			}
+			continue
+		}
+		if s.config.ReadTimeout > 0 {
+			if err = conn.SetReadDeadline(time.Now().Add(s.config.ReadTimeout)); err != nil {
+				log.Warnf("%s websocket: set read deadline: %v", s.exchange, err)
+			}
+		}
+		messageType, message, err = conn.ReadMessage()
+		if err == nil {
+			s.lastMessage = time.Now()
+			return
+		}
+		if s.isClosed() {
+			return 0, nil, ErrWSSessionClosed
+		}
+		log.Warnf("%s websocket: read: %v. reconnecting...", s.exchange, err)
+		s.dropConn(conn)
+	}
+}
+
+// ReadJSON reads the next message and unmarshals it into @v.
+func (s *WSSession) ReadJSON(v interface{}) error {
+	_, message, err := s.ReadMessage()
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(message, v)
+}
+
+// WriteJSON sends @v as JSON on the current connection.
+func (s *WSSession) WriteJSON(v interface{}) error {
+	conn := s.currentConn()
+	if conn == nil {
+		if s.isClosed() {
+			return ErrWSSessionClosed
+		}
+		return errors.New("websocket not connected")
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return conn.WriteJSON(v)
+}
+
+// WriteMessage sends @data as message of @messageType on the current connection.
+func (s *WSSession) WriteMessage(messageType int, data []byte) error {
+	conn := s.currentConn()
+	if conn == nil {
+		if s.isClosed() {
+			return ErrWSSessionClosed
+		}
+		return errors.New("websocket not connected")
+	}
+	s.writeLock.Lock()
+	defer s.writeLock.Unlock()
+	return conn.WriteMessage(messageType, data)
+}
+
+// Subscribe sends the subscription @message and registers it under @key, such that it is sent again
+// after every reconnect. If the session is disconnected, the subscription is sent once it is reconnected.
+func (s *WSSession) Subscribe(key string, message interface{}) error {
+	s.subscriptionsLock.Lock()
+	if _, ok := s.subscriptions[key]; !ok {
+		s.subscriptionKeys = append(s.subscriptionKeys, key)
+	}
+	s.subscriptions[key] = message
+	s.subscriptionsLock.Unlock()
+
+	if s.currentConn() == nil {
+		return nil
+	}
+	return s.WriteJSON(message)
+}
+
+// Unsubscribe removes the subscription with @key and sends @message, if not nil.
+func (s *WSSession) Unsubscribe(key string, message interface{}) error {
+	s.subscriptionsLock.Lock()
+	delete(s.subscriptions, key)
+	for i, k := range s.subscriptionKeys {
+		if k == key {
+			s.subscriptionKeys = append(s.subscriptionKeys[:i], s.subscriptionKeys[i+1:]...)
+			break
+		}
+	}
+	s.subscriptionsLock.Unlock()
+
+	if message == nil {
+		return nil
+	}
+	return s.WriteJSON(message)
+}
+
+// Reconnects returns the number of reconnects since the session was created.
+func (s *WSSession) Reconnects() int {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.reconnects
+}
+
+// Close closes the connection. Pending and subsequent reads return ErrWSSessionClosed.
+func (s *WSSession) Close() error {
+	var err error
+	s.closeOnce.Do(func() {
+		close(s.shutdown)
+		s.connLock.Lock()
+		conn := s.conn
+		s.conn = nil
+		s.connLock.Unlock()
+		if conn != nil {
+			err = conn.Close()
+		}
+	})
+	return err
+}
+
+func (s *WSSession) isClosed() bool {
+	select {
+	case <-s.shutdown:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *WSSession) currentConn() *ws.Conn {
+	s.connLock.Lock()
+	defer s.connLock.Unlock()
+	return s.conn
+}
+
+// dropConn closes @conn if it is still the current connection.
+func (s *WSSession) dropConn(conn *ws.Conn) {
+	s.connLock.Lock()
+	if s.conn == conn {
+		s.conn = nil
+	}
+	s.connLock.Unlock()
+	if err := conn.Close(); err != nil {
+		log.Debugf("%s websocket: close: %v", s.exchange, err)
+	}
+}
+
+// reconnect dials with exponential backoff until it succeeds or the session is closed,
+// renews all subscriptions and reports the gap.
+func (s *WSSession) reconnect() error {
+	backoff := s.config.MinBackoff
+	attempts := 0
+	for {
+		if s.isClosed() {
+			return ErrWSSessionClosed
+		}
+		attempts++
+		conn, err := s.dial()
+		if err == nil {
+			s.connLock.Lock()
+			if s.isClosed() {
+				s.connLock.Unlock()
+				if err := conn.Close(); err != nil {
+					log.Debugf("%s websocket: close: %v", s.exchange, err)
+				}
+				return ErrWSSessionClosed
+			}
+			s.conn = conn
+			s.reconnects++
+			s.connLock.Unlock()
+			s.resubscribe()
+			break
+		}
+		log.Errorf("%s websocket: dial attempt %d: %v. retry in %v", s.exchange, attempts, err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-s.shutdown:
+			return ErrWSSessionClosed
+		}
+		backoff *= 2
+		if backoff > s.config.MaxBackoff {
+			backoff = s.config.MaxBackoff
+		}
+	}
+
+	gap := WSGap{Exchange: s.exchange, Start: s.lastMessage, End: time.Now(), Attempts: attempts}
+	log.Warnf("%s websocket: reconnected after %d attempts. possible gap from %v to %v", s.exchange, attempts, gap.Start, gap.End)
+	if s.config.OnGap != nil {
+		s.config.OnGap(gap)
+	}
+	return nil
+}
+
+// resubscribe sends all registered subscriptions in the order they were made.
+func (s *WSSession) resubscribe() {
+	s.subscriptionsLock.Lock()
+	messages := make([]interface{}, 0, len(s.subscriptionKeys))
+	for _, key := range s.subscriptionKeys {
+		messages = append(messages, s.subscriptions[key])
+	}
+	s.subscriptionsLock.Unlock()
+	for _, message := range messages {
+		if err := s.WriteJSON(message); err != nil {
+			log.Errorf("%s websocket: resubscribe: %v", s.exchange, err)
+		}
+	}
+}
+
+// heartbeat sends pings every PingInterval until the session is closed.
+func (s *WSSession) heartbeat() {
+	ticker := time.NewTicker(s.config.PingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.shutdown:
+			return
+		case <-ticker.C:
+			conn := s.currentConn()
+			if conn == nil {
+				continue
+			}
+			var err error
+			if s.config.PingText != "" {
+				err = s.WriteMessage(ws.TextMessage, []byte(s.config.PingText))
+			} else if s.config.PingMessage != nil {
+				err = s.WriteJSON(s.config.PingMessage())
+			} else {
+				s.writeLock.Lock()
+				err = conn.WriteControl(ws.PingMessage, nil, time.Now().Add(s.config.PingInterval))
+				s.writeLock.Unlock()
+			}
+			if err != nil {
+				log.Warnf("%s websocket: ping: %v", s.exchange, err)
+			}
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package wsHelper
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	ws "github.com/gorilla/websocket"
+)
+
+// TestWSSessionReconnect drops the first connection after the subscription and expects the
+// session to reconnect, resubscribe and report the gap.
+func TestWSSessionReconnect(t *testing.T) {
+	subscriptions := make(chan string, 10)
+	var connections int32
+	upgrader := ws.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		connection := atomic.AddInt32(&connections, 1)
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		subscriptions <- strings.TrimSpace(string(message))
+		if connection == 1 {
+			return
+		}
+		if err := conn.WriteMessage(ws.TextMessage, []byte(`{"trade":1}`)); err != nil {
+			t.Error(err)
+		}
+		// Keep the connection open until the client closes it.
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	gaps := make(chan WSGap, 1)
+	session := NewWSSession("Test", WSSessionConfig{
+		URL:        "ws" + strings.TrimPrefix(server.URL, "http"),
+		MinBackoff: 10 * time.Millisecond,
+		OnGap:      func(gap WSGap) { gaps <- gap },
+	})
+	if err := session.Subscribe("BTC-USD", map[string]string{"subscribe": "BTC-USD"}); err != nil {
+		t.Fatal(err)
+	}
+
+	var message struct {
+		Trade int `json:"trade"`
+	}
+	if err := session.ReadJSON(&message); err != nil || message.Trade != 1 {
+		t.Fatalf("expected trade after reconnect, got %v (%v)", message, err)
+	}
+	for i := 0; i < 2; i++ {
+		if sub := <-subscriptions; sub != `{"subscribe":"BTC-USD"}` {
+			t.Errorf("unexpected subscription %s", sub)
+		}
+	}
+	if gap := <-gaps; gap.Exchange != "Test" || gap.End.Before(gap.Start) {
+		t.Errorf("unexpected gap %v", gap)
+	}
+	if session.Reconnects() != 1 || atomic.LoadInt32(&connections) != 2 {
+		t.Errorf("expected one reconnect, got %d with %d connections", session.Reconnects(), atomic.LoadInt32(&connections))
+	}
+
+	if err := session.Close(); err != nil {
+		t.Error(err)
+	}
+	if _, _, err := session.ReadMessage(); err != ErrWSSessionClosed {
+		t.Errorf("expected closed session, got %v", err)
+	}
+}
@@ -0,0 +1,49 @@
+pragma solidity 0.7.4;
+pragma abicoder v2;
+
+// DIAOracleV3 extends DIAOracleV2 by setMultipleValues, which updates any number of keys in a single transaction.
+// Values are stored as in DIAOracleV2, such that getValue is compatible.
+contract DIAOracleV3 {
+    mapping (string => uint256) public values;
+    address oracleUpdater;
+    
+    event OracleUpdate(string key, uint128 value, uint128 timestamp);
+    event UpdaterAddressChange(address newUpdater);
+    
+    constructor() {
+        oracleUpdater = msg.sender;
+    }
+    
+    function setValue(string memory key, uint128 value, uint128 timestamp) public {
+        require(msg.sender == oracleUpdater);
+        uint256 cValue = (((uint256)(value)) << 128) + timestamp;
+        values[key] = cValue;
+        emit OracleUpdate(key, value, timestamp);
+    }
+    
+    // setMultipleValues sets each key in keys to the corresponding packed value in compressedValues,
+    // which holds the value in its upper and the timestamp in its lower 128 bits.
+    function setMultipleValues(string[] memory keys, uint256[] memory compressedValues) public {
+        require(msg.sender == oracleUpdater);
+        require(keys.length == compressedValues.length);
+        
+        for (uint256 i = 0; i < keys.length; i++) {
+            uint256 cValue = compressedValues[i];
+            values[keys[i]] = cValue;
+            emit OracleUpdate(keys[i], (uint128)(cValue >> 128), (uint128)(cValue % 2**128));
+        }
+    }
+    
+    function getValue(string memory key) external view returns (uint128, uint128) {
+        uint256 cValue = values[key];
+        uint128 timestamp = (uint128)(cValue % 2**128);
+        uint128 value = (uint128)(cValue >> 128);
+        return (value, timestamp);
+    }
+    
+    function updateOracleUpdaterAddress(address newOracleUpdaterAddress) public {
+        require(msg.sender == oracleUpdater);
+        oracleUpdater = newOracleUpdaterAddress;
+        emit UpdaterAddressChange(newOracleUpdaterAddress);
+    }
+}
@@ -0,0 +1,576 @@
+// Code generated - DO NOT EDIT.
+// This file is a generated binding and any manual changes will be lost.
+
+package diaOracleServiceV3
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+
+	ethereum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/event"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var (
+	_ = errors.New
+	_ = big.NewInt
+	_ = strings.NewReader
+	_ = ethereum.NotFound
+	_ = bind.Bind
+	_ = common.Big1
+	_ = types.BloomLookup
+	_ = event.NewSubscription
+)
+
+// DIAOracleV3MetaData contains all meta data concerning the DIAOracleV3 contract.
+var DIAOracleV3MetaData = &bind.MetaData{
+	ABI: "[{\"inputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"constructor\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"string\",\"name\":\"key\",\"type\":\"string\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"value\",\"type\":\"uint128\"},{\"indexed\":false,\"internalType\":\"uint128\",\"name\":\"timestamp\",\"type\":\"uint128\"}],\"name\":\"OracleUpdate\",\"type\":\"event\"},{\"anonymous\":false,\"inputs\":[{\"indexed\":false,\"internalType\":\"address\",\"name\":\"newUpdater\",\"type\":\"address\"}],\"name\":\"UpdaterAddressChange\",\"type\":\"event\"},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"key\",\"type\":\"string\"}],\"name\":\"getValue\",\"outputs\":[{\"internalType\":\"uint128\",\"name\":\"\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"\",\"type\":\"uint128\"}],\"stateMutability\":\"view\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"string[]\",\"name\":\"keys\",\"type\":\"string[]\"},{\"internalType\":\"uint256[]\",\"name\":\"compressedValues\",\"type\":\"uint256[]\"}],\"name\":\"setMultipleValues\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"key\",\"type\":\"string\"},{\"internalType\":\"uint128\",\"name\":\"value\",\"type\":\"uint128\"},{\"internalType\":\"uint128\",\"name\":\"timestamp\",\"type\":\"uint128\"}],\"name\":\"setValue\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"address\",\"name\":\"newOracleUpdaterAddress\",\"type\":\"address\"}],\"name\":\"updateOracleUpdaterAddress\",\"outputs\":[],\"stateMutability\":\"nonpayable\",\"type\":\"function\"},{\"inputs\":[{\"internalType\":\"string\",\"name\":\"\",\"type\":\"string\"}],\"name\":\"values\",\"outputs\":[{\"internalType\":\"uint256\",\"name\":\"\",\"type\":\"uint256\"}],\"stateMutability\":\"view\",\"type\":\"function\"}]",
+}
+
+// DIAOracleV3ABI is the input ABI used to generate the binding from.
+// Deprecated: Use DIAOracleV3MetaData.ABI instead.
+var DIAOracleV3ABI = DIAOracleV3MetaData.ABI
+
+// DIAOracleV3 is an auto generated Go binding around an Ethereum contract.
+type DIAOracleV3 struct {
+	DIAOracleV3Caller     // Read-only binding to the contract
+	DIAOracleV3Transactor // Write-only binding to the contract
+	DIAOracleV3Filterer   // Log filterer for contract events
+}
+
+// DIAOracleV3Caller is an auto generated read-only Go binding around an Ethereum contract.
+type DIAOracleV3Caller struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DIAOracleV3Transactor is an auto generated write-only Go binding around an Ethereum contract.
+type DIAOracleV3Transactor struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DIAOracleV3Filterer is an auto generated log filtering Go binding around an Ethereum contract events.
+type DIAOracleV3Filterer struct {
+	contract *bind.BoundContract // Generic contract wrapper for the low level calls
+}
+
+// DIAOracleV3Session is an auto generated Go binding around an Ethereum contract,
+// with pre-set call and transact options.
+type DIAOracleV3Session struct {
+	Contract     *DIAOracleV3      // Generic contract binding to set the session for
+	CallOpts     bind.CallOpts     // Call options to use throughout this session
+	TransactOpts bind.TransactOpts // Transaction auth options to use throughout this session
+}
+
+// DIAOracleV3CallerSession is an auto generated read-only Go binding around an Ethereum contract,
+// with pre-set call options.
+type DIAOracleV3CallerSession struct {
+	Contract *DIAOracleV3Caller // Generic contract caller binding to set the session for
+	CallOpts bind.CallOpts      // Call options to use throughout this session
+}
+
+// DIAOracleV3TransactorSession is an auto generated write-only Go binding around an Ethereum contract,
+// with pre-set transact options.
+type DIAOracleV3TransactorSession struct {
+	Contract     *DIAOracleV3Transactor // Generic contract transactor binding to set the session for
+	TransactOpts bind.TransactOpts      // Transaction auth options to use throughout this session
+}
+
+// DIAOracleV3Raw is an auto generated low-level Go binding around an Ethereum contract.
+type DIAOracleV3Raw struct {
+	Contract *DIAOracleV3 // Generic contract binding to access the raw methods on
+}
+
+// DIAOracleV3CallerRaw is an auto generated low-level read-only Go binding around an Ethereum contract.
+type DIAOracleV3CallerRaw struct {
+	Contract *DIAOracleV3Caller // Generic read-only contract binding to access the raw methods on
+}
+
+// DIAOracleV3TransactorRaw is an auto generated low-level write-only Go binding around an Ethereum contract.
+type DIAOracleV3TransactorRaw struct {
+	Contract *DIAOracleV3Transactor // Generic write-only contract binding to access the raw methods on
+}
+
+// NewDIAOracleV3 creates a new instance of DIAOracleV3, bound to a specific deployed contract.
+func NewDIAOracleV3(address common.Address, backend bind.ContractBackend) (*DIAOracleV3, error) {
+	contract, err := bindDIAOracleV3(address, backend, backend, backend)
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3{DIAOracleV3Caller: DIAOracleV3Caller{contract: contract}, DIAOracleV3Transactor: DIAOracleV3Transactor{contract: contract}, DIAOracleV3Filterer: DIAOracleV3Filterer{contract: contract}}, nil
+}
+
+// NewDIAOracleV3Caller creates a new read-only instance of DIAOracleV3, bound to a specific deployed contract.
+func NewDIAOracleV3Caller(address common.Address, caller bind.ContractCaller) (*DIAOracleV3Caller, error) {
+	contract, err := bindDIAOracleV3(address, caller, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3Caller{contract: contract}, nil
+}
+
+// NewDIAOracleV3Transactor creates a new write-only instance of DIAOracleV3, bound to a specific deployed contract.
+func NewDIAOracleV3Transactor(address common.Address, transactor bind.ContractTransactor) (*DIAOracleV3Transactor, error) {
+	contract, err := bindDIAOracleV3(address, nil, transactor, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3Transactor{contract: contract}, nil
+}
+
+// NewDIAOracleV3Filterer creates a new log filterer instance of DIAOracleV3, bound to a specific deployed contract.
+func NewDIAOracleV3Filterer(address common.Address, filterer bind.ContractFilterer) (*DIAOracleV3Filterer, error) {
+	contract, err := bindDIAOracleV3(address, nil, nil, filterer)
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3Filterer{contract: contract}, nil
+}
+
+// bindDIAOracleV3 binds a generic wrapper to an already deployed contract.
+func bindDIAOracleV3(address common.Address, caller bind.ContractCaller, transactor bind.ContractTransactor, filterer bind.ContractFilterer) (*bind.BoundContract, error) {
+	parsed, err := abi.JSON(strings.NewReader(DIAOracleV3ABI))
+	if err != nil {
+		return nil, err
+	}
+	return bind.NewBoundContract(address, parsed, caller, transactor, filterer), nil
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DIAOracleV3 *DIAOracleV3Raw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DIAOracleV3.Contract.DIAOracleV3Caller.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DIAOracleV3 *DIAOracleV3Raw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.DIAOracleV3Transactor.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DIAOracleV3 *DIAOracleV3Raw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.DIAOracleV3Transactor.contract.Transact(opts, method, params...)
+}
+
+// Call invokes the (constant) contract method with params as input values and
+// sets the output to result. The result type might be a single field for simple
+// returns, a slice of interfaces for anonymous returns and a struct for named
+// returns.
+func (_DIAOracleV3 *DIAOracleV3CallerRaw) Call(opts *bind.CallOpts, result *[]interface{}, method string, params ...interface{}) error {
+	return _DIAOracleV3.Contract.contract.Call(opts, result, method, params...)
+}
+
+// Transfer initiates a plain transaction to move funds to the contract, calling
+// its default method if one is available.
+func (_DIAOracleV3 *DIAOracleV3TransactorRaw) Transfer(opts *bind.TransactOpts) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.contract.Transfer(opts)
+}
+
+// Transact invokes the (paid) contract method with params as input values.
+func (_DIAOracleV3 *DIAOracleV3TransactorRaw) Transact(opts *bind.TransactOpts, method string, params ...interface{}) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.contract.Transact(opts, method, params...)
+}
+
+// GetValue is a free data retrieval call binding the contract method 0x960384a0.
+//
+// Solidity: function getValue(string key) view returns(uint128, uint128)
+func (_DIAOracleV3 *DIAOracleV3Caller) GetValue(opts *bind.CallOpts, key string) (*big.Int, *big.Int, error) {
+	var out []interface{}
+	err := _DIAOracleV3.contract.Call(opts, &out, "getValue", key)
+
+	if err != nil {
+		return *new(*big.Int), *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+	out1 := *abi.ConvertType(out[1], new(*big.Int)).(**big.Int)
+
+	return out0, out1, err
+
+}
+
+// GetValue is a free data retrieval call binding the contract method 0x960384a0.
+//
+// Solidity: function getValue(string key) view returns(uint128, uint128)
+func (_DIAOracleV3 *DIAOracleV3Session) GetValue(key string) (*big.Int, *big.Int, error) {
+	return _DIAOracleV3.Contract.GetValue(&_DIAOracleV3.CallOpts, key)
+}
+
+// GetValue is a free data retrieval call binding the contract method 0x960384a0.
+//
+// Solidity: function getValue(string key) view returns(uint128, uint128)
+func (_DIAOracleV3 *DIAOracleV3CallerSession) GetValue(key string) (*big.Int, *big.Int, error) {
+	return _DIAOracleV3.Contract.GetValue(&_DIAOracleV3.CallOpts, key)
+}
+
+// Values is a free data retrieval call binding the contract method 0x5a9ade8b.
+//
+// Solidity: function values(string ) view returns(uint256)
+func (_DIAOracleV3 *DIAOracleV3Caller) Values(opts *bind.CallOpts, arg0 string) (*big.Int, error) {
+	var out []interface{}
+	err := _DIAOracleV3.contract.Call(opts, &out, "values", arg0)
+
+	if err != nil {
+		return *new(*big.Int), err
+	}
+
+	out0 := *abi.ConvertType(out[0], new(*big.Int)).(**big.Int)
+
+	return out0, err
+
+}
+
+// Values is a free data retrieval call binding the contract method 0x5a9ade8b.
+//
+// Solidity: function values(string ) view returns(uint256)
+func (_DIAOracleV3 *DIAOracleV3Session) Values(arg0 string) (*big.Int, error) {
+	return _DIAOracleV3.Contract.Values(&_DIAOracleV3.CallOpts, arg0)
+}
+
+// Values is a free data retrieval call binding the contract method 0x5a9ade8b.
+//
+// Solidity: function values(string ) view returns(uint256)
+func (_DIAOracleV3 *DIAOracleV3CallerSession) Values(arg0 string) (*big.Int, error) {
+	return _DIAOracleV3.Contract.Values(&_DIAOracleV3.CallOpts, arg0)
+}
+
+// SetMultipleValues is a paid mutator transaction binding the contract method 0x8d241526.
+//
+// Solidity: function setMultipleValues(string[] keys, uint256[] compressedValues) returns()
+func (_DIAOracleV3 *DIAOracleV3Transactor) SetMultipleValues(opts *bind.TransactOpts, keys []string, compressedValues []*big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.contract.Transact(opts, "setMultipleValues", keys, compressedValues)
+}
+
+// SetMultipleValues is a paid mutator transaction binding the contract method 0x8d241526.
+//
+// Solidity: function setMultipleValues(string[] keys, uint256[] compressedValues) returns()
+func (_DIAOracleV3 *DIAOracleV3Session) SetMultipleValues(keys []string, compressedValues []*big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.SetMultipleValues(&_DIAOracleV3.TransactOpts, keys, compressedValues)
+}
+
+// SetMultipleValues is a paid mutator transaction binding the contract method 0x8d241526.
+//
+// Solidity: function setMultipleValues(string[] keys, uint256[] compressedValues) returns()
+func (_DIAOracleV3 *DIAOracleV3TransactorSession) SetMultipleValues(keys []string, compressedValues []*big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.SetMultipleValues(&_DIAOracleV3.TransactOpts, keys, compressedValues)
+}
+
+// SetValue is a paid mutator transaction binding the contract method 0x7898e0c2.
+//
+// Solidity: function setValue(string key, uint128 value, uint128 timestamp) returns()
+func (_DIAOracleV3 *DIAOracleV3Transactor) SetValue(opts *bind.TransactOpts, key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.contract.Transact(opts, "setValue", key, value, timestamp)
+}
+
+// SetValue is a paid mutator transaction binding the contract method 0x7898e0c2.
+//
+// Solidity: function setValue(string key, uint128 value, uint128 timestamp) returns()
+func (_DIAOracleV3 *DIAOracleV3Session) SetValue(key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.SetValue(&_DIAOracleV3.TransactOpts, key, value, timestamp)
+}
+
+// SetValue is a paid mutator transaction binding the contract method 0x7898e0c2.
+//
+// Solidity: function setValue(string key, uint128 value, uint128 timestamp) returns()
+func (_DIAOracleV3 *DIAOracleV3TransactorSession) SetValue(key string, value *big.Int, timestamp *big.Int) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.SetValue(&_DIAOracleV3.TransactOpts, key, value, timestamp)
+}
+
+// UpdateOracleUpdaterAddress is a paid mutator transaction binding the contract method 0x6aa45efc.
+//
+// Solidity: function updateOracleUpdaterAddress(address newOracleUpdaterAddress) returns()
+func (_DIAOracleV3 *DIAOracleV3Transactor) UpdateOracleUpdaterAddress(opts *bind.TransactOpts, newOracleUpdaterAddress common.Address) (*types.Transaction, error) {
+	return _DIAOracleV3.contract.Transact(opts, "updateOracleUpdaterAddress", newOracleUpdaterAddress)
+}
+
+// UpdateOracleUpdaterAddress is a paid mutator transaction binding the contract method 0x6aa45efc.
+//
+// Solidity: function updateOracleUpdaterAddress(address newOracleUpdaterAddress) returns()
+func (_DIAOracleV3 *DIAOracleV3Session) UpdateOracleUpdaterAddress(newOracleUpdaterAddress common.Address) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.UpdateOracleUpdaterAddress(&_DIAOracleV3.TransactOpts, newOracleUpdaterAddress)
+}
+
+// UpdateOracleUpdaterAddress is a paid mutator transaction binding the contract method 0x6aa45efc.
+//
+// Solidity: function updateOracleUpdaterAddress(address newOracleUpdaterAddress) returns()
+func (_DIAOracleV3 *DIAOracleV3TransactorSession) UpdateOracleUpdaterAddress(newOracleUpdaterAddress common.Address) (*types.Transaction, error) {
+	return _DIAOracleV3.Contract.UpdateOracleUpdaterAddress(&_DIAOracleV3.TransactOpts, newOracleUpdaterAddress)
+}
+
+// DIAOracleV3OracleUpdateIterator is returned from FilterOracleUpdate and is used to iterate over the raw logs and unpacked data for OracleUpdate events raised by the DIAOracleV3 contract.
+type DIAOracleV3OracleUpdateIterator struct {
+	Event *DIAOracleV3OracleUpdate // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DIAOracleV3OracleUpdateIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DIAOracleV3OracleUpdate)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DIAOracleV3OracleUpdate)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DIAOracleV3OracleUpdateIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DIAOracleV3OracleUpdateIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DIAOracleV3OracleUpdate represents a OracleUpdate event raised by the DIAOracleV3 contract.
+type DIAOracleV3OracleUpdate struct {
+	Key       string
+	Value     *big.Int
+	Timestamp *big.Int
+	Raw       types.Log // Blockchain specific contextual infos
+}
+
+// FilterOracleUpdate is a free log retrieval operation binding the contract event 0xa7fc99ed7617309ee23f63ae90196a1e490d362e6f6a547a59bc809ee2291782.
+//
+// Solidity: event OracleUpdate(string key, uint128 value, uint128 timestamp)
+func (_DIAOracleV3 *DIAOracleV3Filterer) FilterOracleUpdate(opts *bind.FilterOpts) (*DIAOracleV3OracleUpdateIterator, error) {
+
+	logs, sub, err := _DIAOracleV3.contract.FilterLogs(opts, "OracleUpdate")
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3OracleUpdateIterator{contract: _DIAOracleV3.contract, event: "OracleUpdate", logs: logs, sub: sub}, nil
+}
+
+// WatchOracleUpdate is a free log subscription operation binding the contract event 0xa7fc99ed7617309ee23f63ae90196a1e490d362e6f6a547a59bc809ee2291782.
+//
+// Solidity: event OracleUpdate(string key, uint128 value, uint128 timestamp)
+func (_DIAOracleV3 *DIAOracleV3Filterer) WatchOracleUpdate(opts *bind.WatchOpts, sink chan<- *DIAOracleV3OracleUpdate) (event.Subscription, error) {
+
+	logs, sub, err := _DIAOracleV3.contract.WatchLogs(opts, "OracleUpdate")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DIAOracleV3OracleUpdate)
+				if err := _DIAOracleV3.contract.UnpackLog(event, "OracleUpdate", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseOracleUpdate is a log parse operation binding the contract event 0xa7fc99ed7617309ee23f63ae90196a1e490d362e6f6a547a59bc809ee2291782.
+//
+// Solidity: event OracleUpdate(string key, uint128 value, uint128 timestamp)
+func (_DIAOracleV3 *DIAOracleV3Filterer) ParseOracleUpdate(log types.Log) (*DIAOracleV3OracleUpdate, error) {
+	event := new(DIAOracleV3OracleUpdate)
+	if err := _DIAOracleV3.contract.UnpackLog(event, "OracleUpdate", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
+
+// DIAOracleV3UpdaterAddressChangeIterator is returned from FilterUpdaterAddressChange and is used to iterate over the raw logs and unpacked data for UpdaterAddressChange events raised by the DIAOracleV3 contract.
+type DIAOracleV3UpdaterAddressChangeIterator struct {
+	Event *DIAOracleV3UpdaterAddressChange // Event containing the contract specifics and raw log
+
+	contract *bind.BoundContract // Generic contract to use for unpacking event data
+	event    string              // Event name to use for unpacking event data
+
+	logs chan types.Log        // Log channel receiving the found contract events
+	sub  ethereum.Subscription // Subscription for errors, completion and termination
+	done bool                  // Whether the subscription completed delivering logs
+	fail error                 // Occurred error to stop iteration
+}
+
+// Next advances the iterator to the subsequent event, returning whether there
+// are any more events found. In case of a retrieval or parsing error, false is
+// returned and Error() can be queried for the exact failure.
+func (it *DIAOracleV3UpdaterAddressChangeIterator) Next() bool {
+	// If the iterator failed, stop iterating
+	if it.fail != nil {
+		return false
+	}
+	// If the iterator completed, deliver directly whatever's available
+	if it.done {
+		select {
+		case log := <-it.logs:
+			it.Event = new(DIAOracleV3UpdaterAddressChange)
+			if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+				it.fail = err
+				return false
+			}
+			it.Event.Raw = log
+			return true
+
+		default:
+			return false
+		}
+	}
+	// Iterator still in progress, wait for either a data or an error event
+	select {
+	case log := <-it.logs:
+		it.Event = new(DIAOracleV3UpdaterAddressChange)
+		if err := it.contract.UnpackLog(it.Event, it.event, log); err != nil {
+			it.fail = err
+			return false
+		}
+		it.Event.Raw = log
+		return true
+
+	case err := <-it.sub.Err():
+		it.done = true
+		it.fail = err
+		return it.Next()
+	}
+}
+
+// Error returns any retrieval or parsing error occurred during filtering.
+func (it *DIAOracleV3UpdaterAddressChangeIterator) Error() error {
+	return it.fail
+}
+
+// Close terminates the iteration process, releasing any pending underlying
+// resources.
+func (it *DIAOracleV3UpdaterAddressChangeIterator) Close() error {
+	it.sub.Unsubscribe()
+	return nil
+}
+
+// DIAOracleV3UpdaterAddressChange represents a UpdaterAddressChange event raised by the DIAOracleV3 contract.
+type DIAOracleV3UpdaterAddressChange struct {
+	NewUpdater common.Address
+	Raw        types.Log // Blockchain specific contextual infos
+}
+
+// FilterUpdaterAddressChange is a free log retrieval operation binding the contract event 0x121e958a4cadf7f8dadefa22cc019700365240223668418faebed197da07089f.
+//
+// Solidity: event UpdaterAddressChange(address newUpdater)
+func (_DIAOracleV3 *DIAOracleV3Filterer) FilterUpdaterAddressChange(opts *bind.FilterOpts) (*DIAOracleV3UpdaterAddressChangeIterator, error) {
+
+	logs, sub, err := _DIAOracleV3.contract.FilterLogs(opts, "UpdaterAddressChange")
+	if err != nil {
+		return nil, err
+	}
+	return &DIAOracleV3UpdaterAddressChangeIterator{contract: _DIAOracleV3.contract, event: "UpdaterAddressChange", logs: logs, sub: sub}, nil
+}
+
+// WatchUpdaterAddressChange is a free log subscription operation binding the contract event 0x121e958a4cadf7f8dadefa22cc019700365240223668418faebed197da07089f.
+//
+// Solidity: event UpdaterAddressChange(address newUpdater)
+func (_DIAOracleV3 *DIAOracleV3Filterer) WatchUpdaterAddressChange(opts *bind.WatchOpts, sink chan<- *DIAOracleV3UpdaterAddressChange) (event.Subscription, error) {
+
+	logs, sub, err := _DIAOracleV3.contract.WatchLogs(opts, "UpdaterAddressChange")
+	if err != nil {
+		return nil, err
+	}
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case log := <-logs:
+				// New log arrived, parse the event and forward to the user
+				event := new(DIAOracleV3UpdaterAddressChange)
+				if err := _DIAOracleV3.contract.UnpackLog(event, "UpdaterAddressChange", log); err != nil {
+					return err
+				}
+				event.Raw = log
+
+				select {
+				case sink <- event:
+				case err := <-sub.Err():
+					return err
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	}), nil
+}
+
+// ParseUpdaterAddressChange is a log parse operation binding the contract event 0x121e958a4cadf7f8dadefa22cc019700365240223668418faebed197da07089f.
+//
+// Solidity: event UpdaterAddressChange(address newUpdater)
+func (_DIAOracleV3 *DIAOracleV3Filterer) ParseUpdaterAddressChange(log types.Log) (*DIAOracleV3UpdaterAddressChange, error) {
+	event := new(DIAOracleV3UpdaterAddressChange)
+	if err := _DIAOracleV3.contract.UnpackLog(event, "UpdaterAddressChange", log); err != nil {
+		return nil, err
+	}
+	event.Raw = log
+	return event, nil
+}
@@ -0,0 +1,64 @@
+package bookscrapers
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+)
+
+const (
+	binanceBookURL = "wss://stream.binance.com:9443/stream"
+	// Partial book depth streams deliver snapshots of the best 20 levels every 100ms.
+	binanceBookStream = "@depth20@100ms"
+)
+
+// binanceBookAPI scrapes partial book depth streams through the combined streams endpoint.
+type binanceBookAPI struct {
+	requestID int64
+}
+
+func (api *binanceBookAPI) sessionConfig() wsHelper.WSSessionConfig {
+	// Binance sends pings, which are answered by the websocket library.
+	return wsHelper.WSSessionConfig{URL: binanceBookURL}
+}
+
+func (api *binanceBookAPI) streamID(pair dia.ExchangePair) string {
+	return strings.ToLower(pair.ForeignName) + binanceBookStream
+}
+
+func (api *binanceBookAPI) subscription(streamID string) interface{} {
+	return map[string]interface{}{
+		"method": "SUBSCRIBE",
+		"params": []string{streamID},
+		"id":     atomic.AddInt64(&api.requestID, 1),
+	}
+}
+
+func (api *binanceBookAPI) parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error) {
+	var msg struct {
+		Stream string `json:"stream"`
+		Data   struct {
+			Bids [][]interface{} `json:"bids"`
+			Asks [][]interface{} `json:"asks"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+	// Responses to subscriptions have no stream.
+	if msg.Stream == "" {
+		return nil, nil
+	}
+	bids, err := parseLevels(msg.Data.Bids)
+	if err != nil {
+		return nil, err
+	}
+	asks, err := parseLevels(msg.Data.Asks)
+	if err != nil {
+		return nil, err
+	}
+	return []*dia.OrderBook{{ForeignName: msg.Stream, Bids: bids, Asks: asks, Snapshot: true}}, nil
+}
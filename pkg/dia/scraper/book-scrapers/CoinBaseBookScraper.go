@@ -0,0 +1,80 @@
+package bookscrapers
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+)
+
+const coinbaseBookURL = "wss://ws-feed.pro.coinbase.com"
+
+// coinbaseBookAPI scrapes the level2 channel which delivers a snapshot followed by diffs.
+type coinbaseBookAPI struct{}
+
+func (api *coinbaseBookAPI) sessionConfig() wsHelper.WSSessionConfig {
+	return wsHelper.WSSessionConfig{URL: coinbaseBookURL}
+}
+
+func (api *coinbaseBookAPI) streamID(pair dia.ExchangePair) string {
+	return pair.ForeignName
+}
+
+func (api *coinbaseBookAPI) subscription(streamID string) interface{} {
+	return map[string]interface{}{
+		"type":        "subscribe",
+		"product_ids": []string{streamID},
+		"channels":    []string{"level2"},
+	}
+}
+
+func (api *coinbaseBookAPI) parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error) {
+	var msg struct {
+		Type      string          `json:"type"`
+		ProductID string          `json:"product_id"`
+		Time      string          `json:"time"`
+		Bids      [][]interface{} `json:"bids"`
+		Asks      [][]interface{} `json:"asks"`
+		// Changes are given as [side, price, size].
+		Changes [][]interface{} `json:"changes"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+
+	book := &dia.OrderBook{ForeignName: msg.ProductID}
+	var err error
+	switch msg.Type {
+	case "snapshot":
+		book.Snapshot = true
+		if book.Bids, err = parseLevels(msg.Bids); err != nil {
+			return nil, err
+		}
+		if book.Asks, err = parseLevels(msg.Asks); err != nil {
+			return nil, err
+		}
+	case "l2update":
+		for _, change := range msg.Changes {
+			if len(change) < 3 {
+				continue
+			}
+			levels, err := parseLevels([][]interface{}{change[1:]})
+			if err != nil {
+				return nil, err
+			}
+			if change[0] == "buy" {
+				book.Bids = append(book.Bids, levels...)
+			} else {
+				book.Asks = append(book.Asks, levels...)
+			}
+		}
+		if t, err := time.Parse(time.RFC3339Nano, msg.Time); err == nil {
+			book.Time = t
+		}
+	default:
+		// subscriptions, heartbeats and errors
+		return nil, nil
+	}
+	return []*dia.OrderBook{book}, nil
+}
@@ -0,0 +1,74 @@
+package bookscrapers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+)
+
+const huobiBookURL = "wss://api.huobi.pro/ws"
+
+// huobiBookAPI scrapes market depth channels which deliver snapshots of 150 levels.
+type huobiBookAPI struct{}
+
+func (api *huobiBookAPI) sessionConfig() wsHelper.WSSessionConfig {
+	// Huobi sends pings, which are answered in parse.
+	return wsHelper.WSSessionConfig{URL: huobiBookURL}
+}
+
+func (api *huobiBookAPI) streamID(pair dia.ExchangePair) string {
+	return "market." + strings.ToLower(pair.ForeignName) + ".depth.step0"
+}
+
+func (api *huobiBookAPI) subscription(streamID string) interface{} {
+	return map[string]string{
+		"sub": streamID,
+		"id":  streamID,
+	}
+}
+
+// parse decompresses the gzipped @message and answers pings.
+func (api *huobiBookAPI) parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error) {
+	reader, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return nil, err
+	}
+	decompressed, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, err
+	}
+	var msg struct {
+		Ping int64  `json:"ping"`
+		Ch   string `json:"ch"`
+		Ts   int64  `json:"ts"`
+		Tick struct {
+			Bids [][]interface{} `json:"bids"`
+			Asks [][]interface{} `json:"asks"`
+		} `json:"tick"`
+	}
+	if err = json.Unmarshal(decompressed, &msg); err != nil {
+		return nil, err
+	}
+	if msg.Ping != 0 {
+		return nil, send(map[string]int64{"pong": msg.Ping})
+	}
+	// Subscription responses have no channel.
+	if msg.Ch == "" {
+		return nil, nil
+	}
+
+	book := &dia.OrderBook{ForeignName: msg.Ch, Snapshot: true, Time: time.Unix(0, msg.Ts*int64(time.Millisecond))}
+	if book.Bids, err = parseLevels(msg.Tick.Bids); err != nil {
+		return nil, err
+	}
+	if book.Asks, err = parseLevels(msg.Tick.Asks); err != nil {
+		return nil, err
+	}
+	return []*dia.OrderBook{book}, nil
+}
@@ -0,0 +1,236 @@
+package bookscrapers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+)
+
+const (
+	krakenBookURL   = "wss://ws.kraken.com"
+	krakenBookDepth = 25
+	// Number of levels per side covered by the checksum of a book update.
+	krakenChecksumDepth = 10
+)
+
+// krakenBookAPI scrapes the book channel which delivers a snapshot followed by diffs.
+// Kraken does not delete levels which fall out of the subscribed depth, so books are truncated to
+// krakenBookDepth levels. Diffs carry a checksum of the resulting book, which is verified on a copy
+// of each book. If it does not match, the book is subscribed again in order to get a new snapshot.
+type krakenBookAPI struct {
+	// books holds the levels of each book as given by Kraken, as the checksum is computed on them.
+	books map[string]*krakenBook
+}
+
+// krakenBook is an order book whose levels are keyed by the price as given by Kraken.
+type krakenBook struct {
+	asks map[string]krakenLevel
+	bids map[string]krakenLevel
+}
+
+type krakenLevel struct {
+	price  string
+	volume string
+	value  float64
+}
+
+func (api *krakenBookAPI) sessionConfig() wsHelper.WSSessionConfig {
+	// Kraken sends heartbeats on subscribed connections.
+	return wsHelper.WSSessionConfig{URL: krakenBookURL}
+}
+
+// streamID returns the websocket notation of the pair, such as XBT/USD for the foreign name XBTUSD.
+func (api *krakenBookAPI) streamID(pair dia.ExchangePair) string {
+	if pair.Symbol != "" && strings.HasPrefix(pair.ForeignName, pair.Symbol) && len(pair.ForeignName) > len(pair.Symbol) {
+		return pair.Symbol + "/" + strings.TrimPrefix(pair.ForeignName, pair.Symbol)
+	}
+	if len(pair.ForeignName) > 3 {
+		return pair.ForeignName[:len(pair.ForeignName)-3] + "/" + pair.ForeignName[len(pair.ForeignName)-3:]
+	}
+	return pair.ForeignName
+}
+
+func (api *krakenBookAPI) subscription(streamID string) interface{} {
+	return api.event("subscribe", streamID)
+}
+
+func (api *krakenBookAPI) event(event string, streamID string) map[string]interface{} {
+	return map[string]interface{}{
+		"event": event,
+		"pair":  []string{streamID},
+		"subscription": map[string]interface{}{
+			"name":  "book",
+			"depth": krakenBookDepth,
+		},
+	}
+}
+
+// parse parses book messages of the form [channelID, {"as": [...], "bs": [...]}, "book-25", "XBT/USD"].
+// Diffs may contain separate objects for asks and bids, the last of which holds the checksum.
+func (api *krakenBookAPI) parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error) {
+	// Events such as heartbeats and subscription status are objects.
+	if len(message) == 0 || message[0] != '[' {
+		return nil, nil
+	}
+	var elements []json.RawMessage
+	if err := json.Unmarshal(message, &elements); err != nil {
+		return nil, err
+	}
+	if len(elements) < 4 {
+		return nil, errors.New("malformed book message")
+	}
+	var pair string
+	if err := json.Unmarshal(elements[len(elements)-1], &pair); err != nil {
+		return nil, err
+	}
+
+	book := &dia.OrderBook{ForeignName: pair, Depth: krakenBookDepth}
+	var rawAsks, rawBids [][]interface{}
+	var checksum string
+	for _, element := range elements[1 : len(elements)-2] {
+		var levels map[string]json.RawMessage
+		if err := json.Unmarshal(element, &levels); err != nil {
+			return nil, err
+		}
+		for key, raw := range levels {
+			var side *[]dia.OrderBookLevel
+			var rawSide *[][]interface{}
+			switch key {
+			case "as":
+				book.Snapshot = true
+				side, rawSide = &book.Asks, &rawAsks
+			case "bs":
+				book.Snapshot = true
+				side, rawSide = &book.Bids, &rawBids
+			case "a":
+				side, rawSide = &book.Asks, &rawAsks
+			case "b":
+				side, rawSide = &book.Bids, &rawBids
+			case "c":
+				if err := json.Unmarshal(raw, &checksum); err != nil {
+					return nil, err
+				}
+				continue
+			default:
+				continue
+			}
+			var entries [][]interface{}
+			if err := json.Unmarshal(raw, &entries); err != nil {
+				return nil, err
+			}
+			parsed, err := parseLevels(entries)
+			if err != nil {
+				return nil, err
+			}
+			*side = append(*side, parsed...)
+			*rawSide = append(*rawSide, entries...)
+		}
+	}
+
+	if !api.apply(pair, book.Snapshot, rawAsks, rawBids, checksum) {
+		// The diff is dropped, as the book is replaced by the snapshot following the new subscription.
+		if err := send(api.event("unsubscribe", pair)); err != nil {
+			return nil, err
+		}
+		if err := send(api.subscription(pair)); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("checksum mismatch on order book %s. subscribed again", pair)
+	}
+	return []*dia.OrderBook{book}, nil
+}
+
+// apply applies a snapshot or diff to the copy of the book of @pair and verifies the resulting book
+// against @checksum. It returns false if the checksum does not match, in which case the copy is
+// dropped until the next snapshot. Diffs of books without snapshot are not verified.
+func (api *krakenBookAPI) apply(pair string, snapshot bool, asks [][]interface{}, bids [][]interface{}, checksum string) bool {
+	if api.books == nil {
+		api.books = make(map[string]*krakenBook)
+	}
+	book, ok := api.books[pair]
+	if snapshot || !ok {
+		if !snapshot {
+			return true
+		}
+		book = &krakenBook{asks: make(map[string]krakenLevel), bids: make(map[string]krakenLevel)}
+		api.books[pair] = book
+	}
+	book.update(book.asks, asks, false)
+	book.update(book.bids, bids, true)
+	if checksum == "" || book.checksum() == checksum {
+		return true
+	}
+	delete(api.books, pair)
+	return false
+}
+
+// update applies the raw @entries to @levels and drops all levels out of krakenBookDepth.
+func (book *krakenBook) update(levels map[string]krakenLevel, entries [][]interface{}, descending bool) {
+	for _, entry := range entries {
+		if len(entry) < 2 {
+			continue
+		}
+		price, ok := entry[0].(string)
+		if !ok {
+			continue
+		}
+		volume, ok := entry[1].(string)
+		if !ok {
+			continue
+		}
+		value, err := strconv.ParseFloat(price, 64)
+		if err != nil {
+			continue
+		}
+		if size, err := strconv.ParseFloat(volume, 64); err == nil && size == 0 {
+			delete(levels, price)
+			continue
+		}
+		levels[price] = krakenLevel{price: price, volume: volume, value: value}
+	}
+	sorted := sortKrakenLevels(levels, descending)
+	for _, level := range sorted[min(len(sorted), krakenBookDepth):] {
+		delete(levels, level.price)
+	}
+}
+
+// checksum returns the CRC32 checksum of the best krakenChecksumDepth asks followed by the best bids,
+// where each level is given by price and volume without decimal point and leading zeros.
+func (book *krakenBook) checksum() string {
+	var buf strings.Builder
+	for _, side := range [][]krakenLevel{sortKrakenLevels(book.asks, false), sortKrakenLevels(book.bids, true)} {
+		for _, level := range side[:min(len(side), krakenChecksumDepth)] {
+			buf.WriteString(strings.TrimLeft(strings.Replace(level.price, ".", "", 1), "0"))
+			buf.WriteString(strings.TrimLeft(strings.Replace(level.volume, ".", "", 1), "0"))
+		}
+	}
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(buf.String()))), 10)
+}
+
+func sortKrakenLevels(levels map[string]krakenLevel, descending bool) []krakenLevel {
+	sorted := make([]krakenLevel, 0, len(levels))
+	for _, level := range levels {
+		sorted = append(sorted, level)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if descending {
+			return sorted[i].value > sorted[j].value
+		}
+		return sorted[i].value < sorted[j].value
+	})
+	return sorted
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,79 @@
+package bookscrapers
+
+import (
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+)
+
+const (
+	okexBookURL = "wss://ws.okex.com:8443/ws/v5/public"
+	// OKEx closes connections without messages for 30 seconds unless they send a ping.
+	okexBookPingInterval = 20 * time.Second
+)
+
+// okexBookAPI scrapes the books channel which delivers a snapshot of 400 levels followed by diffs.
+type okexBookAPI struct{}
+
+func (api *okexBookAPI) sessionConfig() wsHelper.WSSessionConfig {
+	return wsHelper.WSSessionConfig{
+		URL:          okexBookURL,
+		PingInterval: okexBookPingInterval,
+		PingText:     "ping",
+	}
+}
+
+func (api *okexBookAPI) streamID(pair dia.ExchangePair) string {
+	return pair.ForeignName
+}
+
+func (api *okexBookAPI) subscription(streamID string) interface{} {
+	return map[string]interface{}{
+		"op": "subscribe",
+		"args": []map[string]string{
+			{"channel": "books", "instId": streamID},
+		},
+	}
+}
+
+func (api *okexBookAPI) parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error) {
+	if string(message) == "pong" {
+		return nil, nil
+	}
+	var msg struct {
+		Arg struct {
+			Channel string `json:"channel"`
+			InstID  string `json:"instId"`
+		} `json:"arg"`
+		Action string `json:"action"`
+		Data   []struct {
+			Bids [][]interface{} `json:"bids"`
+			Asks [][]interface{} `json:"asks"`
+			Ts   string          `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return nil, err
+	}
+
+	// Events such as subscription responses have no data.
+	var books []*dia.OrderBook
+	for _, data := range msg.Data {
+		book := &dia.OrderBook{ForeignName: msg.Arg.InstID, Snapshot: msg.Action == "snapshot"}
+		var err error
+		if book.Bids, err = parseLevels(data.Bids); err != nil {
+			return nil, err
+		}
+		if book.Asks, err = parseLevels(data.Asks); err != nil {
+			return nil, err
+		}
+		if ts, err := strconv.ParseInt(data.Ts, 10, 64); err == nil {
+			book.Time = time.Unix(0, ts*int64(time.Millisecond))
+		}
+		books = append(books, book)
+	}
+	return books, nil
+}
@@ -0,0 +1,239 @@
+package bookscrapers
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+	"github.com/sirupsen/logrus"
+)
+
+var log = logrus.New()
+
+// empty type used for signaling
+type nothing struct{}
+
+// BookScraper provides common methods needed to get level 2 spot order book data from
+// exchange APIs. Scrapers deliver snapshots as well as diffs, which are applied to the latest
+// snapshot by means of dia.OrderBook.Apply.
+type BookScraper interface {
+	io.Closer
+	// ScrapeBook subscribes to the order book of @pair.
+	ScrapeBook(pair dia.ExchangePair) error
+	// Channel returns a channel that can be used to receive order book snapshots and diffs
+	Channel() chan *dia.OrderBook
+	// Error returns an error when the channel Channel() is closed
+	// and nil otherwise
+	Error() error
+}
+
+// New returns a BookScraper for @exchange which is scraping as soon as it is created.
+func New(exchange string) (BookScraper, error) {
+	var api bookAPI
+	switch exchange {
+	case dia.BinanceExchange:
+		api = &binanceBookAPI{}
+	case dia.KrakenExchange:
+		api = &krakenBookAPI{}
+	case dia.CoinBaseExchange:
+		api = &coinbaseBookAPI{}
+	case dia.OKExExchange:
+		api = &okexBookAPI{}
+	case dia.HuobiExchange:
+		api = &huobiBookAPI{}
+	default:
+		return nil, errors.New("no book scraper for exchange " + exchange)
+	}
+	return newWebsocketBookScraper(exchange, api)
+}
+
+// bookAPI implements the exchange specific parts of a websocket order book scraper.
+// @send writes a JSON message to the websocket connection.
+type bookAPI interface {
+	// sessionConfig returns the url of the websocket API and its heartbeat settings.
+	sessionConfig() wsHelper.WSSessionConfig
+	// streamID returns the identifier of the order book of @pair in messages of the API.
+	streamID(pair dia.ExchangePair) string
+	// subscription returns the message subscribing to the order book with @streamID.
+	// It is sent again after each reconnect, upon which exchanges send a new snapshot.
+	subscription(streamID string) interface{}
+	// parse returns the order books contained in @message with ForeignName set to their streamID.
+	// Messages which require a reply, such as pings, are answered through @send.
+	parse(send func(interface{}) error, message []byte) ([]*dia.OrderBook, error)
+}
+
+// websocketBookScraper runs a bookAPI on a single websocket session, which is reconnected
+// if the connection drops.
+type websocketBookScraper struct {
+	api          bookAPI
+	exchangeName string
+	wsConn       *wsHelper.WSSession
+	// pairs maps the streamIDs of subscribed order books to their pairs.
+	pairs     map[string]dia.ExchangePair
+	pairsLock sync.RWMutex
+	chanBooks chan *dia.OrderBook
+	// signaling channels for shutdown
+	shutdown     chan nothing
+	shutdownDone chan nothing
+	// error handling; to read error or closed, first acquire read lock
+	// only cleanup method should hold write lock
+	errorLock sync.RWMutex
+	error     error
+	closed    bool
+}
+
+func newWebsocketBookScraper(exchange string, api bookAPI) (*websocketBookScraper, error) {
+	s := &websocketBookScraper{
+		api:          api,
+		exchangeName: exchange,
+		wsConn:       wsHelper.NewWSSession(exchange, api.sessionConfig()),
+		pairs:        make(map[string]dia.ExchangePair),
+		chanBooks:    make(chan *dia.OrderBook),
+		shutdown:     make(chan nothing),
+		shutdownDone: make(chan nothing),
+	}
+	go s.mainLoop()
+	return s, nil
+}
+
+// mainLoop runs in a goroutine until the book scraper is closed.
+func (s *websocketBookScraper) mainLoop() {
+	var err error
+	for {
+		var message []byte
+		// Reads only fail once the session is closed.
+		_, message, err = s.wsConn.ReadMessage()
+		if err != nil {
+			if errors.Is(err, wsHelper.ErrWSSessionClosed) {
+				err = nil
+			} else {
+				log.Errorf("%s book scraper: read message: %v", s.exchangeName, err)
+			}
+			break
+		}
+		books, parseErr := s.api.parse(s.send, message)
+		if parseErr != nil {
+			log.Errorf("%s book scraper: parse message: %v", s.exchangeName, parseErr)
+			continue
+		}
+		for _, book := range books {
+			s.pairsLock.RLock()
+			pair, ok := s.pairs[book.ForeignName]
+			s.pairsLock.RUnlock()
+			if !ok {
+				log.Warnf("%s book scraper: unknown order book %s", s.exchangeName, book.ForeignName)
+				continue
+			}
+			book.Exchange = s.exchangeName
+			book.ForeignName = pair.ForeignName
+			book.Symbol = pair.Symbol
+			if book.Time.IsZero() {
+				book.Time = time.Now()
+			}
+			select {
+			case s.chanBooks <- book:
+			case <-s.shutdown:
+				s.cleanup(nil)
+				return
+			}
+		}
+	}
+	s.cleanup(err)
+}
+
+// send writes @v as JSON to the websocket connection.
+func (s *websocketBookScraper) send(v interface{}) error {
+	return s.wsConn.WriteJSON(v)
+}
+
+// ScrapeBook subscribes to the order book of @pair.
+func (s *websocketBookScraper) ScrapeBook(pair dia.ExchangePair) error {
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	if s.closed {
+		return errors.New("book scraper is closed")
+	}
+	streamID := s.api.streamID(pair)
+	s.pairsLock.Lock()
+	s.pairs[streamID] = pair
+	s.pairsLock.Unlock()
+	return s.wsConn.Subscribe(streamID, s.api.subscription(streamID))
+}
+
+// Channel returns a channel that can be used to receive order book snapshots and diffs.
+func (s *websocketBookScraper) Channel() chan *dia.OrderBook {
+	return s.chanBooks
+}
+
+// Error returns an error when the channel Channel() is closed and nil otherwise.
+func (s *websocketBookScraper) Error() error {
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	return s.error
+}
+
+// must only be called from mainLoop
+func (s *websocketBookScraper) cleanup(err error) {
+	s.errorLock.Lock()
+	defer s.errorLock.Unlock()
+	if err != nil {
+		s.error = err
+	}
+	s.closed = true
+	close(s.chanBooks)
+	close(s.shutdownDone) // signal that shutdown is complete
+}
+
+// Close closes the websocket connection and the channel Channel().
+func (s *websocketBookScraper) Close() error {
+	s.errorLock.RLock()
+	closed := s.closed
+	s.errorLock.RUnlock()
+	if closed {
+		return errors.New("book scraper: already closed")
+	}
+	close(s.shutdown)
+	err := s.wsConn.Close()
+	if err != nil {
+		log.Error(err)
+	}
+	<-s.shutdownDone
+	s.errorLock.RLock()
+	defer s.errorLock.RUnlock()
+	return s.error
+}
+
+// parseLevels parses price levels given as [price, size, ...] arrays of strings or numbers.
+func parseLevels(raw [][]interface{}) ([]dia.OrderBookLevel, error) {
+	levels := make([]dia.OrderBookLevel, 0, len(raw))
+	for _, entry := range raw {
+		if len(entry) < 2 {
+			return nil, errors.New("malformed price level")
+		}
+		price, err := parseNumber(entry[0])
+		if err != nil {
+			return nil, err
+		}
+		size, err := parseNumber(entry[1])
+		if err != nil {
+			return nil, err
+		}
+		levels = append(levels, dia.OrderBookLevel{Price: price, Size: size})
+	}
+	return levels, nil
+}
+
+func parseNumber(v interface{}) (float64, error) {
+	switch n := v.(type) {
+	case string:
+		return strconv.ParseFloat(n, 64)
+	case float64:
+		return n, nil
+	default:
+		return 0, errors.New("malformed number")
+	}
+}
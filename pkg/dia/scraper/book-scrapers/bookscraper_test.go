@@ -0,0 +1,219 @@
+package bookscrapers
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+func noSend(v interface{}) error {
+	return nil
+}
+
+func TestParseBookMessages(t *testing.T) {
+	cases := []struct {
+		api      bookAPI
+		message  string
+		id       string
+		snapshot bool
+		bids     []dia.OrderBookLevel
+		asks     []dia.OrderBookLevel
+	}{
+		{
+			api:      &binanceBookAPI{},
+			message:  `{"stream":"btcusdt@depth20@100ms","data":{"lastUpdateId":160,"bids":[["0.0024","10"]],"asks":[["0.0026","100"]]}}`,
+			id:       "btcusdt@depth20@100ms",
+			snapshot: true,
+			bids:     []dia.OrderBookLevel{{Price: 0.0024, Size: 10}},
+			asks:     []dia.OrderBookLevel{{Price: 0.0026, Size: 100}},
+		},
+		{
+			api:      &krakenBookAPI{},
+			message:  `[0,{"as":[["5541.30000","2.50700000","1534614248.123678"]],"bs":[["5541.20000","1.52900000","1534614248.765567"]]},"book-25","XBT/USD"]`,
+			id:       "XBT/USD",
+			snapshot: true,
+			bids:     []dia.OrderBookLevel{{Price: 5541.2, Size: 1.529}},
+			asks:     []dia.OrderBookLevel{{Price: 5541.3, Size: 2.507}},
+		},
+		{
+			api:     &krakenBookAPI{},
+			message: `[1234,{"a":[["5541.30000","0.00000000","1534614335.345903"]]},{"b":[["5541.20000","1.00000000","1534614335.345903","r"]],"c":"974942666"},"book-25","XBT/USD"]`,
+			id:      "XBT/USD",
+			bids:    []dia.OrderBookLevel{{Price: 5541.2, Size: 1}},
+			asks:    []dia.OrderBookLevel{{Price: 5541.3, Size: 0}},
+		},
+		{
+			api:     &coinbaseBookAPI{},
+			message: `{"type":"l2update","product_id":"BTC-USD","time":"2019-08-14T20:42:27.265Z","changes":[["buy","10101.80000000","0.162567"],["sell","10102.00000000","0"]]}`,
+			id:      "BTC-USD",
+			bids:    []dia.OrderBookLevel{{Price: 10101.8, Size: 0.162567}},
+			asks:    []dia.OrderBookLevel{{Price: 10102, Size: 0}},
+		},
+		{
+			api:      &okexBookAPI{},
+			message:  `{"arg":{"channel":"books","instId":"BTC-USDT"},"action":"snapshot","data":[{"asks":[["8476.98","415","0","13"]],"bids":[["8476.97","256","0","12"]],"ts":"1597026383085","checksum":-855196043}]}`,
+			id:       "BTC-USDT",
+			snapshot: true,
+			bids:     []dia.OrderBookLevel{{Price: 8476.97, Size: 256}},
+			asks:     []dia.OrderBookLevel{{Price: 8476.98, Size: 415}},
+		},
+	}
+	for i, c := range cases {
+		books, err := c.api.parse(noSend, []byte(c.message))
+		if err != nil {
+			t.Fatalf("case %d: %v", i, err)
+		}
+		if len(books) != 1 {
+			t.Fatalf("case %d: expected one book, got %d", i, len(books))
+		}
+		book := books[0]
+		if book.ForeignName != c.id || book.Snapshot != c.snapshot {
+			t.Errorf("case %d: unexpected book %v", i, book)
+		}
+		if !equalLevels(book.Bids, c.bids) || !equalLevels(book.Asks, c.asks) {
+			t.Errorf("case %d: unexpected levels %v %v", i, book.Bids, book.Asks)
+		}
+	}
+}
+
+func TestParseBookEvents(t *testing.T) {
+	cases := []struct {
+		api     bookAPI
+		message string
+	}{
+		{&binanceBookAPI{}, `{"result":null,"id":1}`},
+		{&krakenBookAPI{}, `{"event":"heartbeat"}`},
+		{&coinbaseBookAPI{}, `{"type":"subscriptions","channels":[{"name":"level2","product_ids":["BTC-USD"]}]}`},
+		{&okexBookAPI{}, `{"event":"subscribe","arg":{"channel":"books","instId":"BTC-USDT"}}`},
+	}
+	for i, c := range cases {
+		books, err := c.api.parse(noSend, []byte(c.message))
+		if err != nil || len(books) != 0 {
+			t.Errorf("case %d: expected no books, got %v (%v)", i, books, err)
+		}
+	}
+}
+
+func TestParseHuobiBookMessages(t *testing.T) {
+	api := &huobiBookAPI{}
+	var sent interface{}
+	send := func(v interface{}) error {
+		sent = v
+		return nil
+	}
+
+	books, err := api.parse(send, gzipMessage(t, `{"ping":1492420473027}`))
+	if err != nil || len(books) != 0 {
+		t.Fatalf("expected no books, got %v (%v)", books, err)
+	}
+	if pong, ok := sent.(map[string]int64); !ok || pong["pong"] != 1492420473027 {
+		t.Errorf("expected pong, got %v", sent)
+	}
+
+	books, err = api.parse(send, gzipMessage(t, `{"ch":"market.btcusdt.depth.step0","ts":1489474082831,"tick":{"bids":[[9999.3900,0.0098]],"asks":[[10010.9800,0.0100]]}}`))
+	if err != nil || len(books) != 1 {
+		t.Fatalf("expected one book, got %v (%v)", books, err)
+	}
+	if books[0].ForeignName != api.streamID(dia.ExchangePair{ForeignName: "BTCUSDT"}) || !books[0].Snapshot || books[0].Time.Unix() != 1489474082 {
+		t.Errorf("unexpected book %v", books[0])
+	}
+	if !equalLevels(books[0].Bids, []dia.OrderBookLevel{{Price: 9999.39, Size: 0.0098}}) {
+		t.Errorf("unexpected bids %v", books[0].Bids)
+	}
+}
+
+func TestKrakenStreamID(t *testing.T) {
+	api := &krakenBookAPI{}
+	for _, c := range []struct {
+		pair     dia.ExchangePair
+		expected string
+	}{
+		{dia.ExchangePair{Symbol: "ADA", ForeignName: "ADAUSD"}, "ADA/USD"},
+		{dia.ExchangePair{Symbol: "BTC", ForeignName: "XBTUSD"}, "XBT/USD"},
+	} {
+		if id := api.streamID(c.pair); id != c.expected {
+			t.Errorf("expected %s, got %s", c.expected, id)
+		}
+	}
+}
+
+func TestKrakenBookChecksum(t *testing.T) {
+	api := &krakenBookAPI{}
+	var sent []interface{}
+	send := func(v interface{}) error {
+		sent = append(sent, v)
+		return nil
+	}
+
+	snapshot := `[0,{"as":[["5541.30000","2.50700000","1534614248.123678"],["5542.50000","0.40100000","1534614248.456738"]],"bs":[["5541.20000","1.52900000","1534614248.765567"],["5539.90000","0.30000000","1534614241.769870"]]},"book-25","XBT/USD"]`
+	if books, err := api.parse(send, []byte(snapshot)); err != nil || len(books) != 1 || books[0].Depth != krakenBookDepth {
+		t.Fatalf("expected snapshot, got %v (%v)", books, err)
+	}
+	diff := `[0,{"b":[["5541.20000","1.00000000","1534614335.345903","r"]],"c":"1446648424"},"book-25","XBT/USD"]`
+	if books, err := api.parse(send, []byte(diff)); err != nil || len(books) != 1 {
+		t.Fatalf("expected diff with valid checksum, got %v (%v)", books, err)
+	}
+	if len(sent) != 0 {
+		t.Fatalf("expected no messages, got %v", sent)
+	}
+
+	diff = `[0,{"a":[["5541.30000","1.00000000","1534614335.345903"]],"c":"1446648424"},"book-25","XBT/USD"]`
+	if books, err := api.parse(send, []byte(diff)); err == nil || len(books) != 0 {
+		t.Fatalf("expected checksum mismatch, got %v (%v)", books, err)
+	}
+	if len(sent) != 2 || sent[0].(map[string]interface{})["event"] != "unsubscribe" || sent[1].(map[string]interface{})["event"] != "subscribe" {
+		t.Errorf("expected new subscription, got %v", sent)
+	}
+}
+
+func TestKrakenBookTruncation(t *testing.T) {
+	api := &krakenBookAPI{}
+	var bids []string
+	for i := 0; i < krakenBookDepth; i++ {
+		bids = append(bids, `["`+strconv.Itoa(5000-i)+`.00000","1.00000000","1534614248.765567"]`)
+	}
+	snapshot := `[0,{"as":[["5001.00000","1.00000000","1534614248.123678"]],"bs":[` + strings.Join(bids, ",") + `]},"book-25","XBT/USD"]`
+	if _, err := api.parse(noSend, []byte(snapshot)); err != nil {
+		t.Fatal(err)
+	}
+	diff := `[0,{"b":[["5000.50000","1.00000000","1534614335.345903"]]},"book-25","XBT/USD"]`
+	if _, err := api.parse(noSend, []byte(diff)); err != nil {
+		t.Fatal(err)
+	}
+	book := api.books["XBT/USD"]
+	if _, ok := book.bids["4976.00000"]; len(book.bids) != krakenBookDepth || ok {
+		t.Errorf("expected worst bid to be dropped, got %d bids", len(book.bids))
+	}
+}
+
+func equalLevels(a, b []dia.OrderBookLevel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func gzipMessage(t *testing.T, message string) []byte {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if !json.Valid([]byte(message)) {
+		t.Fatalf("invalid message %s", message)
+	}
+	if _, err := w.Write([]byte(message)); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
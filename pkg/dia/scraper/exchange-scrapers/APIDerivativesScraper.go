@@ -3,8 +3,8 @@ package scrapers
 import (
 	"sync"
 
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	"github.com/diadata-org/diadata/pkg/model"
-	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
@@ -24,7 +24,7 @@ type DeribitScraper struct {
 	WaitGroup    *sync.WaitGroup
 	Logger       *zap.SugaredLogger
 	DataStore    *models.DB
-	WsConnection *websocket.Conn
+	WsConnection *wsHelper.WSSession
 
 	// required for deribit to:
 	// 1. authenticate (trades is a private channel)
@@ -2,6 +2,7 @@ package scrapers
 
 import (
 	"io"
+	"strings"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
@@ -28,7 +29,7 @@ var evmID map[string]string
 
 func init() {
 
-	relDB, err := models.NewRelDataStore()
+	relDB, err := models.NewRelDataStoreFromEnv()
 	if err != nil {
 		log.Fatal("get rel datastore: ", err)
 	}
@@ -101,9 +102,33 @@ type PairScraper interface {
 	Pair() dia.ExchangePair
 }
 
+// PairUnsubscriber is implemented by PairScrapers which can stop the trades of their pair.
+// Closing any other PairScraper only marks it as closed while the trades of the pair keep coming in.
+type PairUnsubscriber interface {
+	PairScraper
+	// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+	Unsubscribe() error
+}
+
+// getChainConfig returns the chain config of the EVM blockchain with name @blockchainName.
+// The chain IDs of EVM blockchains are prefixed by the name of the Ethereum blockchain, such as Ethereum56.
+func getChainConfig(blockchainName string) (dia.ChainConfig, bool) {
+	blockchain, ok := blockchains[blockchainName]
+	if !ok {
+		return dia.ChainConfig{}, false
+	}
+	chainConfig, ok := chainConfigs[strings.TrimPrefix(blockchain.ChainID, dia.ETHEREUM)]
+	return chainConfig, ok
+}
+
 // NewAPIScraper returns an API scraper for @exchange. If scrape==true it actually does
 // scraping. Otherwise can be used for pairdiscovery.
 func NewAPIScraper(exchange string, scrape bool, key string, secret string, relDB *models.RelDB) APIScraper {
+	// Exchanges with a generic scraper type are configured entirely by the exchange table.
+	if ex, ok := Exchanges[exchange]; ok && ex.ScraperType == dia.ScraperTypeUniswapV2 {
+		return NewUniswapScraper(ex, scrape)
+	}
+
 	switch exchange {
 	case dia.BinanceExchange:
 		return NewBinanceScraper(key, secret, Exchanges[dia.BinanceExchange], scrape, relDB)
@@ -145,12 +170,6 @@ func NewAPIScraper(exchange string, scrape bool, key string, secret string, relD
 		return NewQuoineScraper(Exchanges[dia.QuoineExchange], scrape, relDB)
 	case dia.BancorExchange:
 		return NewBancorScraper(Exchanges[dia.BancorExchange], scrape)
-	case dia.UniswapExchange:
-		return NewUniswapScraper(Exchanges[dia.UniswapExchange], scrape)
-	case dia.PanCakeSwap:
-		return NewUniswapScraper(Exchanges[dia.PanCakeSwap], scrape)
-	case dia.SushiSwapExchange:
-		return NewUniswapScraper(Exchanges[dia.SushiSwapExchange], scrape)
 	case dia.LoopringExchange:
 		return NewLoopringScraper(Exchanges[dia.LoopringExchange], scrape, relDB)
 	case dia.CurveFIExchange:
@@ -177,55 +196,17 @@ func NewAPIScraper(exchange string, scrape bool, key string, secret string, relD
 		return NewSTEXScraper(Exchanges[dia.STEXExchange], scrape, relDB)
 	case dia.UniswapExchangeV3:
 		return NewUniswapV3Scraper(Exchanges[dia.UniswapExchangeV3], scrape)
-	case dia.DfynNetwork:
-		return NewUniswapScraper(Exchanges[dia.DfynNetwork], scrape)
-	case dia.UbeswapExchange:
-		return NewUniswapScraper(Exchanges[dia.UbeswapExchange], scrape)
-	case dia.SushiSwapExchangePolygon:
-		return NewUniswapScraper(Exchanges[dia.SushiSwapExchangePolygon], scrape)
 
 	case dia.UniswapExchangeV3Polygon:
 		return NewUniswapV3Scraper(Exchanges[dia.UniswapExchangeV3Polygon], scrape)
-	case dia.HuckleberryExchange:
-		return NewUniswapScraper(Exchanges[dia.HuckleberryExchange], scrape)
-	case dia.TraderJoeExchange:
-		return NewUniswapScraper(Exchanges[dia.TraderJoeExchange], scrape)
-	case dia.PangolinExchange:
-		return NewUniswapScraper(Exchanges[dia.PangolinExchange], scrape)
-	case dia.SpookyswapExchange:
-		return NewUniswapScraper(Exchanges[dia.SpookyswapExchange], scrape)
-	case dia.QuickswapExchange:
-		return NewUniswapScraper(Exchanges[dia.QuickswapExchange], scrape)
-	case dia.SpiritswapExchange:
-		return NewUniswapScraper(Exchanges[dia.SpiritswapExchange], scrape)
-	case dia.SolarbeamExchange:
-		return NewUniswapScraper(Exchanges[dia.SolarbeamExchange], scrape)
-	case dia.TrisolarisExchange:
-		return NewUniswapScraper(Exchanges[dia.TrisolarisExchange], scrape)
 	case dia.ByBitExchange:
 		return NewByBitScraper(Exchanges[dia.ByBitExchange], scrape, relDB)
 	case dia.SerumExchange:
 		return NewSerumScraper(Exchanges[dia.SerumExchange], scrape)
 	case dia.AnyswapExchange:
 		return NewAnyswapScraper(Exchanges[dia.AnyswapExchange], scrape, relDB)
-	case dia.NetswapExchange:
-		return NewUniswapScraper(Exchanges[dia.NetswapExchange], scrape)
 	case dia.BitMexExchange:
 		return NewBitMexScraper(Exchanges[dia.BitMexExchange], scrape, relDB)
-	case dia.TethysExchange:
-		return NewUniswapScraper(Exchanges[dia.TethysExchange], scrape)
-	case dia.HermesExchange:
-		return NewUniswapScraper(Exchanges[dia.HermesExchange], scrape)
-	case dia.OmniDexExchange:
-		return NewUniswapScraper(Exchanges[dia.OmniDexExchange], scrape)
-	case dia.DiffusionExchange:
-		return NewUniswapScraper(Exchanges[dia.DiffusionExchange], scrape)
-	case dia.ApeswapExchange:
-		return NewUniswapScraper(Exchanges[dia.ApeswapExchange], scrape)
-	case dia.BiswapExchange:
-		return NewUniswapScraper(Exchanges[dia.BiswapExchange], scrape)
-	case dia.ArthswapExchange:
-		return NewUniswapScraper(Exchanges[dia.ArthswapExchange], scrape)
 		// case dia.FinageForex:
 		// 	return NewFinageForexScraper(Exchanges[dia.FinageForex], scrape, relDB, key, secret)
 
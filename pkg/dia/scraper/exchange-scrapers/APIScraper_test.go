@@ -0,0 +1,12 @@
+package scrapers
+
+import (
+	"os"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// The package's init loads exchanges and chains from the relational datastore. Package-level
+// variables are initialized before init runs, so this selects the in-memory datastore for all
+// tests of the package, which then run without postgres.
+var _ = os.Setenv("DATASTORE_BACKEND", models.DatastoreBackendMemory)
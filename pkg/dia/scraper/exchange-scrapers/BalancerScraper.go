@@ -70,6 +70,8 @@ type BalancerScraper struct {
 	RestClient  *ethclient.Client
 	resubscribe chan string
 	pools       map[string]struct{}
+	// holds trades until their swap logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 func NewBalancerScraper(exchange dia.Exchange, scrape bool) *BalancerScraper {
@@ -98,6 +100,7 @@ func NewBalancerScraper(exchange dia.Exchange, scrape bool) *BalancerScraper {
 	scraper.RestClient = restClient
 
 	if scrape {
+		scraper.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, wsClient, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 	return scraper
@@ -265,7 +268,7 @@ func (scraper *BalancerScraper) subscribeToNewSwaps(poolToSub string) (err error
 					QuoteToken:     scraper.balancerTokensMap[vLog.TokenOut.Hex()],
 					VerifiedPair:   true,
 				}
-				pairScraper.parent.chanTrades <- trade
+				scraper.reorgBuffer.Add(trade, vLog.Raw)
 				fmt.Println("got trade: ", trade)
 
 			}
@@ -74,6 +74,8 @@ type BalancerV2Scraper struct {
 
 	tokensMap    map[string]dia.Asset
 	cachedAssets sync.Map // map[string]dia.Asset
+	// holds trades until their swap logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 // NewBalancerV2Scraper returns a Balancer V2 scraper
@@ -115,6 +117,7 @@ func NewBalancerV2Scraper(exchange dia.Exchange, scrape bool) *BalancerV2Scraper
 	scraper.rl = ratelimit.New(balancerV2RateLimitPerSec)
 
 	if scrape {
+		scraper.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, ws, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 
@@ -228,6 +231,8 @@ func (s *BalancerV2Scraper) mainLoop() {
 				BaseToken:      assetIn,
 				QuoteToken:     assetOut,
 				VerifiedPair:   true,
+				// The first 20 bytes of a Balancer V2 pool ID are the pool's address.
+				PoolAddress: common.BytesToAddress(event.PoolId[:20]).Hex(),
 			}
 			switch {
 			case utils.Contains(reverseBasetokensBalancer, trade.BaseToken.Address):
@@ -244,11 +249,8 @@ func (s *BalancerV2Scraper) mainLoop() {
 				}
 			}
 
-			select {
-			case <-s.shutdown:
-			case s.chanTrades <- trade:
-				log.Info("got trade: ", trade)
-			}
+			log.Info("got trade: ", trade)
+			s.reorgBuffer.Add(trade, event.Raw)
 		}
 	}
 }
@@ -102,18 +102,21 @@ type BancorScraper struct {
 	pairScrapers   map[string]*BancorPairScraper
 	productPairIds map[string]int
 	chanTrades     chan *dia.Trade
+	// holds trades until their conversion logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 func NewBancorScraper(exchange dia.Exchange, scrape bool) *BancorScraper {
 	var wsClient, restClient *ethclient.Client
 	var err error
 
-	restClient, err = ethclient.Dial(utils.Getenv("ETH_URI_REST", restDialEth))
+	restDial, wsDial := chainDials(exchange)
+	restClient, err = ethclient.Dial(utils.Getenv("ETH_URI_REST", restDial))
 	if err != nil {
 		log.Fatal(err)
 	}
 
-	wsClient, err = ethclient.Dial(utils.Getenv("ETH_URI_WS", wsDialEth))
+	wsClient, err = ethclient.Dial(utils.Getenv("ETH_URI_WS", wsDial))
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -131,6 +134,7 @@ func NewBancorScraper(exchange dia.Exchange, scrape bool) *BancorScraper {
 	}
 
 	if scrape {
+		scraper.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, wsClient, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 	return scraper
@@ -178,7 +182,7 @@ func (scraper *BancorScraper) mainLoop() {
 			}
 
 			log.Info("Got Trade: ", trade)
-			scraper.chanTrades <- trade
+			scraper.reorgBuffer.Add(trade, revRawSwap.Raw)
 
 		}
 	}()
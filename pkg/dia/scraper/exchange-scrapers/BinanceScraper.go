@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -280,3 +281,90 @@ func (ps *BinancePairScraper) Error() error {
 func (ps *BinancePairScraper) Pair() dia.ExchangePair {
 	return ps.pair
 }
+
+const binanceAggTradesLimit = 1000
+
+type binanceAggTrade struct {
+	ID           int64  `json:"a"`
+	Price        string `json:"p"`
+	Quantity     string `json:"q"`
+	Time         int64  `json:"T"`
+	IsBuyerMaker bool   `json:"m"`
+}
+
+// FetchTradesPage returns up to 1000 aggregated trades of @pair in [@starttime, @endtime) through Binance's
+// aggTrades endpoint. Binance only allows time windows of one hour, so the first trade is searched hour by
+// hour with cursors "time:<timestamp in ms>". Afterwards, trades are paged by id with cursors "id:<next id>".
+func (s *BinanceScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) (trades []dia.Trade, next string, done bool, err error) {
+	end := endtime.UnixNano() / 1e6
+	url := "https://api.binance.com/api/v3/aggTrades?symbol=" + pair.ForeignName + "&limit=" + strconv.Itoa(binanceAggTradesLimit)
+	var windowEnd int64
+	if strings.HasPrefix(cursor, "id:") {
+		url += "&fromId=" + strings.TrimPrefix(cursor, "id:")
+	} else {
+		windowStart := starttime.UnixNano() / 1e6
+		if strings.HasPrefix(cursor, "time:") {
+			windowStart, err = strconv.ParseInt(strings.TrimPrefix(cursor, "time:"), 10, 64)
+			if err != nil {
+				return
+			}
+		}
+		windowEnd = windowStart + time.Hour.Milliseconds()
+		if windowEnd > end {
+			windowEnd = end
+		}
+		// endTime is inclusive.
+		url += "&startTime=" + strconv.FormatInt(windowStart, 10) + "&endTime=" + strconv.FormatInt(windowEnd-1, 10)
+	}
+
+	data, _, err := utils.GetRequest(url)
+	if err != nil {
+		return
+	}
+	var response []binanceAggTrade
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return
+	}
+	if len(response) == 0 {
+		if windowEnd == 0 || windowEnd >= end {
+			done = true
+			return
+		}
+		next = "time:" + strconv.FormatInt(windowEnd, 10)
+		return
+	}
+
+	for _, aggTrade := range response {
+		if aggTrade.Time >= end {
+			done = true
+			break
+		}
+		price, err := strconv.ParseFloat(aggTrade.Price, 64)
+		if err != nil {
+			log.Error("parse price: ", err)
+			continue
+		}
+		volume, err := strconv.ParseFloat(aggTrade.Quantity, 64)
+		if err != nil {
+			log.Error("parse quantity: ", err)
+			continue
+		}
+		// Same sign convention as the websocket scraper.
+		if !aggTrade.IsBuyerMaker {
+			volume = -volume
+		}
+		trades = append(trades, dia.Trade{
+			Price:          price,
+			Volume:         volume,
+			Time:           time.Unix(aggTrade.Time/1000, (aggTrade.Time%1000)*int64(time.Millisecond)),
+			ForeignTradeID: strconv.FormatInt(aggTrade.ID, 16),
+		})
+	}
+	if windowEnd == 0 && len(response) < binanceAggTradesLimit {
+		// All trades up to now are fetched.
+		done = true
+	}
+	next = "id:" + strconv.FormatInt(response[len(response)-1].ID+1, 10)
+	return
+}
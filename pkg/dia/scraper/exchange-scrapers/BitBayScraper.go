@@ -8,9 +8,8 @@ import (
 	"sync"
 	"time"
 
-	ws "github.com/gorilla/websocket"
-
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 )
@@ -42,7 +41,7 @@ type BitBayTrade struct {
 type BitBayScraper struct {
 	// control flag for main loop
 	run      bool
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 
 	// signaling channels for session initialization and finishing
 	shutdown     chan nothing
@@ -75,13 +74,7 @@ func NewBitBayScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 		db:           relDB,
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(BitBaySocketURL, nil)
-	if err != nil {
-		println(err.Error())
-	}
-
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: BitBaySocketURL, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -90,6 +83,14 @@ func NewBitBayScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *BitBayScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 func (s *BitBayScraper) getMarkets() (markets []string) {
 	var bbm BitBayMarkets
 	b, _, err := utils.GetRequest("https://api.zonda.exchange/rest/trading/ticker")
@@ -134,7 +135,7 @@ func (s *BitBayScraper) subscribe() {
 
 		log.Println("subscribing", a)
 
-		if err := s.wsClient.WriteJSON(a); err != nil {
+		if err := s.wsClient.Subscribe(market, a); err != nil {
 			log.Println(err.Error())
 		}
 
@@ -159,7 +160,7 @@ func (s *BitBayScraper) mainLoop() {
 
 		if s.error = s.wsClient.ReadJSON(&response); s.error != nil {
 			log.Error("ws connection error: ", s.error.Error())
-			s.subscribe()
+			break
 		}
 
 		//b,_ := json.Marshal(message)
@@ -253,6 +254,9 @@ func (s *BitBayScraper) Close() error {
 		return errors.New(s.exchangeName + "Scraper: Already closed")
 	}
 	s.run = false
+	if err := s.wsClient.Close(); err != nil {
+		log.Error(err)
+	}
 	<-s.shutdownDone
 	s.errorLock.RLock()
 	defer s.errorLock.RUnlock()
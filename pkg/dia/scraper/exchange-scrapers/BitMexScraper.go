@@ -9,10 +9,10 @@ import (
 	"sync"
 	"time"
 
-	ws "github.com/gorilla/websocket"
 	"go.uber.org/ratelimit"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 )
@@ -27,14 +27,11 @@ const (
 	// bitMexTaskMaxRetry is a max retry value used when retrying subscribe/unsubscribe trades
 	bitMexTaskMaxRetry = 20
 
-	// bitMexConnMaxRetry is a max retry value used when retrying to create a new connection
-	bitMexConnMaxRetry = 50
-
 	// bitMexRateLimitError is a rate limit error code
 	bitMexRateLimitError = 429
 
-	// bitMexPingInterval is the number of seconds between ping messages
-	bitMexPingInterval = 25
+	// bitMexPingInterval is the interval between ping messages
+	bitMexPingInterval = 25 * time.Second
 )
 
 // bitMexWSTask is a websocket task tracking subscription/unsubscription
@@ -94,7 +91,7 @@ type bitMexInstrument struct {
 
 // BitMexScraper is a scraper for bitmex.com
 type BitMexScraper struct {
-	ws *ws.Conn
+	ws *wsHelper.WSSession
 	rl ratelimit.Limiter
 
 	// signaling channels for session initialization and finishing
@@ -112,38 +109,30 @@ type BitMexScraper struct {
 	consecutiveErrCount int
 
 	// used to keep track of trading pairs that we subscribed to
-	pairScrapers    sync.Map
-	exchangeName    string
-	chanTrades      chan *dia.Trade
-	db              *models.RelDB
-	tasks           sync.Map
-	pingTicker      *time.Ticker
-	stopPingRoutine chan bool
-
-	// used to handle connection retry
-	connMutex      sync.RWMutex
-	connRetryCount int
+	pairScrapers sync.Map
+	exchangeName string
+	chanTrades   chan *dia.Trade
+	db           *models.RelDB
+	tasks        sync.Map
 }
 
 // NewBitMexScraper returns a new BitMex scraper
 func NewBitMexScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *BitMexScraper {
 	s := &BitMexScraper{
-		shutdown:        make(chan nothing),
-		shutdownDone:    make(chan nothing),
-		exchangeName:    exchange.Name,
-		err:             nil,
-		chanTrades:      make(chan *dia.Trade),
-		db:              relDB,
-		pingTicker:      time.NewTicker(bitMexPingInterval * time.Second),
-		stopPingRoutine: make(chan bool),
-	}
-
-	if err := s.newConn(); err != nil {
-		log.Error(err)
-
-		return nil
+		shutdown:     make(chan nothing),
+		shutdownDone: make(chan nothing),
+		exchangeName: exchange.Name,
+		err:          nil,
+		chanTrades:   make(chan *dia.Trade),
+		db:           relDB,
 	}
 
+	s.ws = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{
+		URL:          bitMexWSEndpoint,
+		PingInterval: bitMexPingInterval,
+		PingText:     "ping",
+		OnGap:        recordWSGaps(relDB, s.pairs),
+	})
 	s.rl = ratelimit.New(bitMexWSRateLimitPerSec)
 
 	if scrape {
@@ -162,6 +151,10 @@ func (s *BitMexScraper) Close() error {
 	s.signalShutdown.Do(func() {
 		close(s.shutdown)
 	})
+	// Unblock the read of the main loop.
+	if err := s.ws.Close(); err != nil {
+		log.Warn("BitMexScraper: close websocket: ", err)
+	}
 
 	<-s.shutdownDone
 
@@ -239,24 +232,9 @@ func (s *BitMexScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 	return ps, nil
 }
 
-func (s *BitMexScraper) startPing() {
-	for {
-		select {
-		case <-s.stopPingRoutine:
-			return
-		case <-s.pingTicker.C:
-			s.ping()
-
-		default:
-		}
-	}
-}
-
 func (s *BitMexScraper) mainLoop() {
 	defer s.cleanup()
 
-	go s.startPing()
-
 	for {
 		select {
 		case <-s.shutdown:
@@ -265,21 +243,14 @@ func (s *BitMexScraper) mainLoop() {
 		default:
 		}
 
-		_, msg, err := s.wsConn().ReadMessage()
+		// The session reconnects and resubscribes by itself, so errors only occur once it is closed.
+		_, msg, err := s.ws.ReadMessage()
 		if err != nil {
-
-			log.Warnf("BitMexScraper: Creating a new connection caused by err=%s", err.Error())
-
-			if retryErr := s.retryConnection(); retryErr != nil {
-				s.setError(retryErr)
-				log.Errorf("BitMexScraper: Shutting down main loop after retrying to create a new connection, err=%s", retryErr.Error())
-				return
+			if !errors.Is(err, wsHelper.ErrWSSessionClosed) {
+				s.setError(err)
 			}
-
-			log.Info("BitMexScraper: Successfully created a new connection")
-
-			continue
-
+			log.Errorf("BitMexScraper: Shutting down main loop, err=%s", err.Error())
+			return
 		}
 
 		if string(msg) == "pong" {
@@ -384,36 +355,17 @@ func (s *BitMexScraper) handleTrades(tradesWsResponse bitMexSubscriptionResult)
 
 }
 
-func (s *BitMexScraper) newConn() error {
-	conn, _, err := ws.DefaultDialer.Dial(bitMexWSEndpoint, nil)
-	if err != nil {
-		return err
-	}
-
-	defer s.connMutex.Unlock()
-	s.connMutex.Lock()
-	s.ws = conn
-
-	return nil
-}
-
-func (s *BitMexScraper) wsConn() *ws.Conn {
-	defer s.connMutex.RUnlock()
-	s.connMutex.RLock()
-
-	return s.ws
-}
-
-func (s *BitMexScraper) ping() error {
-	s.rl.Take()
-
-	return s.wsConn().WriteMessage(ws.TextMessage, []byte("ping"))
+// pairs returns the foreign names of all subscribed pairs.
+func (s *BitMexScraper) pairs() (pairs []string) {
+	s.pairScrapers.Range(func(key, value interface{}) bool {
+		pairs = append(pairs, value.(dia.ExchangePair).ForeignName)
+		return true
+	})
+	return
 }
 
 func (s *BitMexScraper) cleanup() {
-	s.pingTicker.Stop()
-	s.stopPingRoutine <- true
-	if err := s.wsConn().Close(); err != nil {
+	if err := s.ws.Close(); err != nil {
 		s.setError(err)
 	}
 
@@ -479,8 +431,9 @@ func (s *BitMexScraper) getTaskID(task bitMexWSTask) string {
 func (s *BitMexScraper) unsubscribe(pairs []dia.ExchangePair) error {
 	channels := make([]string, len(pairs))
 	for idx, pair := range pairs {
-		channels[idx] = "trade." + pair.ForeignName
-		s.pairScrapers.Delete(pair.ForeignName)
+		bitMexInstrumentSymbol := strings.Replace(pair.ForeignName, "_", "", 1)
+		channels[idx] = "trade:" + bitMexInstrumentSymbol
+		s.pairScrapers.Delete(bitMexInstrumentSymbol)
 	}
 
 	task := bitMexWSTask{
@@ -494,31 +447,6 @@ func (s *BitMexScraper) unsubscribe(pairs []dia.ExchangePair) error {
 	return s.send(taskID, task)
 }
 
-func (s *BitMexScraper) retryConnection() error {
-	s.connRetryCount += 1
-	if s.connRetryCount > bitMexConnMaxRetry {
-		return errors.New("BitMexPairScraper: Reached max retry connection")
-	}
-	if err := s.wsConn().Close(); err != nil {
-		return err
-	}
-	if err := s.newConn(); err != nil {
-		return err
-	}
-
-	var pairs []dia.ExchangePair
-	s.pairScrapers.Range(func(key, value interface{}) bool {
-		pair := value.(dia.ExchangePair)
-		pairs = append(pairs, pair)
-		return true
-	})
-	if err := s.subscribe(pairs); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (s *BitMexScraper) retryTask(taskID string) error {
 	val, ok := s.tasks.Load(taskID)
 	if !ok {
@@ -537,13 +465,21 @@ func (s *BitMexScraper) retryTask(taskID string) error {
 	return s.send(taskID, task)
 }
 
+// send sends @task. Subscriptions are registered with the session, such that they are renewed after reconnecting.
 func (s *BitMexScraper) send(taskID string, task bitMexWSTask) error {
 	s.rl.Take()
 
-	return s.wsConn().WriteJSON(&bitMexWSRequest{
+	request := &bitMexWSRequest{
 		Op:   task.Op,
 		Args: task.Args,
-	})
+	}
+	switch task.Op {
+	case "subscribe":
+		return s.ws.Subscribe(strings.Join(task.Args, ","), request)
+	case "unsubscribe":
+		return s.ws.Unsubscribe(strings.Join(task.Args, ","), request)
+	}
+	return s.ws.WriteJSON(request)
 }
 
 // BitMexPairScraper implements PairScraper for BitMex
@@ -580,3 +516,8 @@ func (p *BitMexPairScraper) Close() error {
 
 	return nil
 }
+
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (p *BitMexPairScraper) Unsubscribe() error {
+	return p.Close()
+}
@@ -2,6 +2,7 @@ package scrapers
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"strconv"
 	"strings"
@@ -310,6 +311,11 @@ func (ps *BitfinexPairScraper) Close() error {
 	return err
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *BitfinexPairScraper) Unsubscribe() error {
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (ps *BitfinexScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
@@ -328,3 +334,64 @@ func (ps *BitfinexPairScraper) Error() error {
 func (ps *BitfinexPairScraper) Pair() dia.ExchangePair {
 	return ps.pair
 }
+
+const bitfinexHistoryLimit = 10000
+
+// FetchTradesPage returns up to 10000 trades of @pair in [@starttime, @endtime) through Bitfinex's public
+// trades history endpoint. The cursor is "<timestamp in ms>:<trade id>" of the last trade fetched so far.
+func (s *BitfinexScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) (trades []dia.Trade, next string, done bool, err error) {
+	start := starttime.UnixNano() / 1e6
+	var lastID int64
+	if cursor != "" {
+		parts := strings.Split(cursor, ":")
+		if len(parts) != 2 {
+			err = errors.New("malformed cursor " + cursor)
+			return
+		}
+		if start, err = strconv.ParseInt(parts[0], 10, 64); err != nil {
+			return
+		}
+		if lastID, err = strconv.ParseInt(parts[1], 10, 64); err != nil {
+			return
+		}
+	}
+	// Both start and end are inclusive.
+	url := "https://api-pub.bitfinex.com/v2/trades/t" + pair.ForeignName + "/hist?sort=1" +
+		"&limit=" + strconv.Itoa(bitfinexHistoryLimit) +
+		"&start=" + strconv.FormatInt(start, 10) +
+		"&end=" + strconv.FormatInt(endtime.UnixNano()/1e6-1, 10)
+	data, _, err := utils.GetRequest(url)
+	if err != nil {
+		return
+	}
+	// Each trade is given as [ID, MTS, AMOUNT, PRICE] with negative amounts for sells.
+	var response [][]float64
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return
+	}
+	done = len(response) < bitfinexHistoryLimit
+	next = cursor
+	for _, trade := range response {
+		if len(trade) < 4 {
+			continue
+		}
+		id, mts := int64(trade[0]), int64(trade[1])
+		// Trades at the timestamp of the cursor were partly fetched with the previous page.
+		if mts == start && id <= lastID {
+			continue
+		}
+		trades = append(trades, dia.Trade{
+			Price:          trade[3],
+			Volume:         trade[2],
+			Time:           time.Unix(mts/1000, (mts%1000)*int64(time.Millisecond)),
+			ForeignTradeID: strconv.FormatInt(id, 16),
+		})
+		next = strconv.FormatInt(mts, 10) + ":" + strconv.FormatInt(id, 10)
+	}
+	if next == cursor && !done {
+		// A full page of trades at a single timestamp cannot be paged through.
+		err = errors.New("no progress at cursor " + cursor)
+	}
+	return
+}
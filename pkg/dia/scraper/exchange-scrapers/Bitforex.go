@@ -9,9 +9,9 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 const (
@@ -22,9 +22,7 @@ const (
 	bitForexPongMessage         = "pong_p"
 	bitForexInitialTradeReqSize = 10
 	bitForexWSBucketSize        = 50
-
-	// bitForexConnMaxRetry is a max retry value used when retrying to create a new connection
-	bitForexConnMaxRetry = 50
+	bitForexPingInterval        = 15 * time.Second
 )
 
 // bitForexWSRequest is a websocket request
@@ -76,7 +74,7 @@ type bitForexSymbol struct {
 
 // BitforexScraper is a scraper for Crypto.com
 type BitforexScraper struct {
-	ws *ws.Conn
+	ws *wsHelper.WSSession
 
 	// signaling channels for session initialization and finishing
 	shutdown           chan nothing
@@ -96,10 +94,6 @@ type BitforexScraper struct {
 	exchangeName string
 	chanTrades   chan *dia.Trade
 	db           *models.RelDB
-
-	// used to handle connection retry
-	connMutex      sync.Mutex
-	connRetryCount int
 }
 
 // NewBitforexScraper returns a new Crypto.com scraper
@@ -113,11 +107,12 @@ func NewBitforexScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB)
 		db:           relDB,
 	}
 
-	if err := s.newConn(); err != nil {
-		log.Error("s.newConn()", err)
-
-		return nil
-	}
+	s.ws = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{
+		URL:          bitForexWSEndpoint,
+		PingInterval: bitForexPingInterval,
+		PingText:     bitForexPingMessage,
+		OnGap:        recordWSGaps(relDB, s.pairs),
+	})
 
 	if scrape {
 		go s.mainLoop()
@@ -135,6 +130,10 @@ func (s *BitforexScraper) Close() error {
 	s.signalShutdown.Do(func() {
 		close(s.shutdown)
 	})
+	// Unblock the read of the main loop.
+	if err := s.ws.Close(); err != nil {
+		log.Warn("BitforexScraper: close websocket: ", err)
+	}
 
 	<-s.shutdownDone
 
@@ -213,8 +212,6 @@ func (s *BitforexScraper) mainLoop() {
 		})
 	}()
 
-	go s.ping()
-
 	for {
 		select {
 		case <-s.shutdown:
@@ -223,20 +220,15 @@ func (s *BitforexScraper) mainLoop() {
 		default:
 		}
 
-		_, msg, err := s.wsConn().ReadMessage()
+		// The session reconnects and resubscribes by itself, so errors only occur once it is closed.
+		_, msg, err := s.ws.ReadMessage()
 		if err != nil {
-			log.Warnf("BitforexScraper: Creating a new connection caused by err=%s", err.Error())
-
-			if retryErr := s.retryConnection(); retryErr != nil {
-				s.setError(retryErr)
-				log.Errorf("BitforexScraper: Shutting down main loop after retrying to create a new connection, err=%s", retryErr.Error())
-
-				return
+			if !errors.Is(err, wsHelper.ErrWSSessionClosed) {
+				s.setError(err)
 			}
+			log.Errorf("BitforexScraper: Shutting down main loop, err=%s", err.Error())
 
-			log.Info("BitforexScraper: Successfully created a new connection")
-
-			continue
+			return
 		}
 
 		if string(msg) == bitForexPongMessage {
@@ -317,44 +309,17 @@ func (s *BitforexScraper) extractSymbol(symbol string) (baseCurrency, foreignNam
 	return baseCurrency, foreignName
 }
 
-func (s *BitforexScraper) newConn() error {
-	conn, _, err := ws.DefaultDialer.Dial(bitForexWSEndpoint, nil)
-	if err != nil {
-		return err
-	}
-
-	defer s.connMutex.Unlock()
-	s.connMutex.Lock()
-	s.ws = conn
-
-	return nil
-}
-
-func (s *BitforexScraper) wsConn() *ws.Conn {
-	s.connMutex.Lock()
-	defer s.connMutex.Unlock()
-
-	return s.ws
-}
-
-func (s *BitforexScraper) ping() {
-	t := time.NewTicker(time.Duration(15) * time.Second)
-	for {
-		select {
-		case <-s.shutdown:
-			log.Info("BitforexScraper: Shutting down ping loop")
-
-			return
-		case <-t.C:
-			if err := s.sendMessage([]byte(bitForexPingMessage)); err != nil {
-				log.Warningf("BitforexScraper: Sending pings fail %s", err.Error())
-			}
-		}
-	}
+// pairs returns the foreign names of all subscribed pairs.
+func (s *BitforexScraper) pairs() (pairs []string) {
+	s.pairScrapers.Range(func(key, value interface{}) bool {
+		pairs = append(pairs, key.(string))
+		return true
+	})
+	return
 }
 
 func (s *BitforexScraper) cleanup() {
-	if err := s.wsConn().Close(); err != nil {
+	if err := s.ws.Close(); err != nil {
 		s.setError(err)
 	}
 
@@ -393,21 +358,25 @@ func (s *BitforexScraper) close() {
 	s.closed = true
 }
 
+// subscribe subscribes to the trades of each of @pairs separately, such that the session renews
+// the subscriptions after reconnecting.
 func (s *BitforexScraper) subscribe(pairs []dia.ExchangePair) error {
-	requests := make([]bitForexWSRequest, 0, len(pairs))
 	for _, pair := range pairs {
-		requests = append(requests, bitForexWSRequest{
+		request := bitForexWSRequest{
 			Type:  "subHq",
 			Event: "trade",
 			Param: bitForexWSRequestParam{
 				BusinessType: s.toBitforexSymbol(pair.ForeignName),
 				Size:         bitForexInitialTradeReqSize,
 			},
-		})
+		}
 		s.pairScrapers.Store(pair.ForeignName, pair)
+		if err := s.ws.Subscribe(pair.ForeignName, []bitForexWSRequest{request}); err != nil {
+			return err
+		}
 	}
 
-	return s.send(requests)
+	return nil
 }
 
 func (s *BitforexScraper) unsubscribe(pairs []dia.ExchangePair) error {
@@ -422,36 +391,14 @@ func (s *BitforexScraper) unsubscribe(pairs []dia.ExchangePair) error {
 			},
 		})
 		s.pairScrapers.Delete(pair.ForeignName)
+		if err := s.ws.Unsubscribe(pair.ForeignName, nil); err != nil {
+			return err
+		}
 	}
 
 	return s.send(requests)
 }
 
-func (s *BitforexScraper) retryConnection() error {
-	s.connRetryCount += 1
-	if s.connRetryCount > bitForexConnMaxRetry {
-		return errors.New("BitforexPairScraper: Reached max retry connection")
-	}
-	if err := s.wsConn().Close(); err != nil {
-		return err
-	}
-	if err := s.newConn(); err != nil {
-		return err
-	}
-
-	var pairs []dia.ExchangePair
-	s.pairScrapers.Range(func(key, value interface{}) bool {
-		pair := value.(dia.ExchangePair)
-		pairs = append(pairs, pair)
-		return true
-	})
-	if err := s.subscribe(pairs); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 // divideIntoBuckets divides a []bitForexWSRequest slice into multiple buckets
 func (s *BitforexScraper) divideIntoBuckets(requests []bitForexWSRequest, bucketSize int) [][]bitForexWSRequest {
 	var bucket []bitForexWSRequest
@@ -477,7 +424,7 @@ func (s *BitforexScraper) divideIntoBuckets(requests []bitForexWSRequest, bucket
 func (s *BitforexScraper) send(requests []bitForexWSRequest) error {
 	buckets := s.divideIntoBuckets(requests, bitForexWSBucketSize)
 	for _, bucket := range buckets {
-		err := s.wsConn().WriteJSON(bucket)
+		err := s.ws.WriteJSON(bucket)
 		if err != nil {
 			return err
 		}
@@ -486,10 +433,6 @@ func (s *BitforexScraper) send(requests []bitForexWSRequest) error {
 	return nil
 }
 
-func (s *BitforexScraper) sendMessage(msg []byte) error {
-	return s.wsConn().WriteMessage(ws.TextMessage, msg)
-}
-
 // BitforexPairScraper implements PairScraper for Crypto.com
 type BitforexPairScraper struct {
 	parent *BitforexScraper
@@ -524,3 +467,8 @@ func (p *BitforexPairScraper) Close() error {
 
 	return nil
 }
+
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (p *BitforexPairScraper) Unsubscribe() error {
+	return p.Close()
+}
@@ -11,9 +11,8 @@ import (
 	"sync"
 	"time"
 
-	ws "github.com/gorilla/websocket"
-
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 )
@@ -67,7 +66,7 @@ type BitMaxScraper struct {
 	pairScrapers           map[string]*BitMaxPairScraper // dia.Pair -> BitMaxPairScraper
 	exchangeName           string
 	chanTrades             chan *dia.Trade
-	wsClient               *ws.Conn
+	wsClient               *wsHelper.WSSession
 	currencySymbolName     map[string]string
 	isTickerMapInitialised bool
 	db                     *models.RelDB
@@ -89,19 +88,21 @@ func NewBitMaxScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 	}
 
 	// establish connection in the background
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(bitmaxSocketURL, nil)
-	if err != nil {
-		println(err.Error())
-	}
-
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: bitmaxSocketURL, OnGap: recordWSGaps(relDB, s.pairs)})
 	if scrape {
 		go s.mainLoop()
 	}
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *BitMaxScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 type BitMaxTradeResponse struct {
 	M      string `json:"m"`
 	Symbol string `json:"symbol"`
@@ -213,6 +214,9 @@ func (s *BitMaxScraper) Close() error {
 		return errors.New("BitMaxScraper: Already closed")
 	}
 	close(s.shutdown)
+	if err := s.wsClient.Close(); err != nil {
+		log.Error(err)
+	}
 	<-s.shutdownDone
 	s.errorLock.RLock()
 	defer s.errorLock.RUnlock()
@@ -245,7 +249,7 @@ func (s *BitMaxScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 		Ch: "trades:" + pair.ForeignName,
 		ID: fmt.Sprint(time.Now().Unix()),
 	}
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
 		log.Error("write pair sub: ", err.Error())
 	}
 	log.Info("Subscribed to get trades for ", pair.ForeignName)
@@ -310,6 +314,19 @@ func (ps *BitMaxPairScraper) Close() error {
 	return err
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *BitMaxPairScraper) Unsubscribe() error {
+	unsubscribe := &BitMaxRequest{
+		Op: "unsub",
+		Ch: "trades:" + ps.pair.ForeignName,
+		ID: fmt.Sprint(time.Now().Unix()),
+	}
+	if err := ps.parent.wsClient.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (ps *BitMaxScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
@@ -7,9 +7,8 @@ import (
 	"sync"
 	"time"
 
-	ws "github.com/gorilla/websocket"
-
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
@@ -76,7 +75,7 @@ type ByBitSubscribe struct {
 type ByBitScraper struct {
 	// control flag for main loop
 	run      bool
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 
 	// signaling channels for session initialization and finishing
 	shutdown     chan nothing
@@ -130,14 +129,7 @@ func NewByBitScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *B
 	*/
 
 	// Create the ws connection
-	var wsDialer ws.Dialer
-
-	SwConn, _, err := wsDialer.Dial(ByBitSocketURL, nil)
-	if err != nil {
-		println(err.Error())
-	}
-
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: ByBitSocketURL, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -146,6 +138,14 @@ func NewByBitScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *B
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *ByBitScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 func (s *ByBitScraper) getMarkets() (markets []string) {
 	var bbm ByBitMarketsResponse
 	b, _, err := utils.GetRequest("https://api.bybit.com/v2/public/symbols")
@@ -180,7 +180,7 @@ func (s *ByBitScraper) subscribe() {
 		Args: []string{"trade.*"},
 	}
 	log.Println("subscribing", a)
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe("trade.*", a); err != nil {
 		log.Println(err.Error())
 	}
 }
@@ -200,7 +200,7 @@ func (s *ByBitScraper) mainLoop() {
 		message := &ByBitTradeResponse{}
 		if err = s.wsClient.ReadJSON(&message); err != nil {
 			log.Error("ws connection error: ", err.Error())
-			s.subscribe()
+			break
 		}
 		// the topic format is something like trade.BTCUSD
 		log.Info("got topic: ", message.Topic)
@@ -278,6 +278,9 @@ func (s *ByBitScraper) Close() error {
 		return errors.New(s.exchangeName + "Scraper: Already closed")
 	}
 	s.run = false
+	if err := s.wsClient.Close(); err != nil {
+		log.Error(err)
+	}
 	<-s.shutdownDone
 	s.errorLock.RLock()
 	defer s.errorLock.RUnlock()
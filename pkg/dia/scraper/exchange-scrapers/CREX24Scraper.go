@@ -279,6 +279,11 @@ func (ps *CREX24PairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *CREX24PairScraper) Unsubscribe() error {
+	return ps.Close()
+}
+
 func (ps *CREX24PairScraper) Error() error {
 	if ps.parent.closed {
 		return errors.New("scraper has been closed")
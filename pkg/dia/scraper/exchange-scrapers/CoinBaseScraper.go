@@ -4,15 +4,23 @@ import (
 	"encoding/json"
 	"errors"
 	"strconv"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 	gdax "github.com/preichenberger/go-coinbasepro/v2"
 )
 
+const (
+	coinbaseTradesLimit = 1000
+	// Prefix of the cursors of FetchTradesPage while seeking the first trade of a time range.
+	coinbaseSeekCursor = "seek:"
+)
+
 type CoinBaseScraper struct {
 	// signaling channels
 	shutdown     chan nothing
@@ -23,7 +31,7 @@ type CoinBaseScraper struct {
 	error        error
 	closed       bool
 	pairScrapers map[string]*CoinBasePairScraper // pc.ExchangePair -> pairScraperSet
-	wsConn       *ws.Conn
+	wsConn       *wsHelper.WSSession
 	exchangeName string
 	chanTrades   chan *dia.Trade
 	db           *models.RelDB
@@ -50,18 +58,21 @@ func NewCoinBaseScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB)
 		chanTrades:   make(chan *dia.Trade),
 		db:           relDB,
 	}
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial("wss://ws-feed.pro.coinbase.com", nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsConn = SwConn
+	s.wsConn = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: "wss://ws-feed.pro.coinbase.com", OnGap: recordWSGaps(relDB, s.pairs)})
 	if scrape {
 		go s.mainLoop()
 	}
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *CoinBaseScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // mainLoop runs in a goroutine until channel s is closed.
 func (s *CoinBaseScraper) mainLoop() {
 	var err error
@@ -253,7 +264,7 @@ func (s *CoinBaseScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error)
 			},
 		},
 	}
-	if err := s.wsConn.WriteJSON(subscribe); err != nil {
+	if err := s.wsConn.Subscribe(pair.ForeignName, subscribe); err != nil {
 		println(err.Error())
 	}
 
@@ -270,6 +281,31 @@ func (ps *CoinBasePairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *CoinBasePairScraper) Unsubscribe() error {
+	unsubscribe := gdax.Message{
+		Type: "unsubscribe",
+		Channels: []gdax.MessageChannel{
+			{
+				Name: ChannelHeartbeat,
+				ProductIds: []string{
+					ps.pair.ForeignName,
+				},
+			},
+			{
+				Name: ChannelTicker,
+				ProductIds: []string{
+					ps.pair.ForeignName,
+				},
+			},
+		},
+	}
+	if err := ps.parent.wsConn.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Error returns an error when the channel Channel() is closed
 // and nil otherwise
 func (ps *CoinBasePairScraper) Error() error {
@@ -283,3 +319,114 @@ func (ps *CoinBasePairScraper) Error() error {
 func (ps *CoinBasePairScraper) Pair() dia.ExchangePair {
 	return ps.pair
 }
+
+// FetchTradesPage returns up to 1000 trades of @pair in [@starttime, @endtime) through Coinbase's trades endpoint.
+// Coinbase pages backwards in time, starting with the most recent trades. As trade ids are consecutive per
+// product, trades are nevertheless returned oldest first: Pages are first fetched backwards without returning
+// trades until the first trade id of the time range is found. The cursor is "seek:" followed by the oldest
+// trade id fetched so far during this phase. Afterwards, the cursor is the id of the next trade to be returned.
+func (s *CoinBaseScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) (trades []dia.Trade, next string, done bool, err error) {
+	if cursor == "" || strings.HasPrefix(cursor, coinbaseSeekCursor) {
+		next, done, err = s.seekTradeID(pair, starttime, strings.TrimPrefix(cursor, coinbaseSeekCursor))
+		return
+	}
+	tradeID, err := strconv.Atoi(cursor)
+	if err != nil {
+		return
+	}
+	// Returns the trades with ids in [tradeID, tradeID+coinbaseTradesLimit), latest first.
+	response, err := s.fetchTrades(pair, strconv.Itoa(tradeID+coinbaseTradesLimit))
+	if err != nil {
+		return
+	}
+	if len(response) < coinbaseTradesLimit {
+		done = true
+	}
+	for i := len(response) - 1; i >= 0; i-- {
+		trade := response[i]
+		if trade.TradeID < tradeID {
+			continue
+		}
+		timestamp := trade.Time.Time()
+		if timestamp.Before(starttime) {
+			continue
+		}
+		if !timestamp.Before(endtime) {
+			done = true
+			break
+		}
+		price, err := strconv.ParseFloat(trade.Price, 64)
+		if err != nil {
+			log.Error("parse price: ", err)
+			continue
+		}
+		volume, err := strconv.ParseFloat(trade.Size, 64)
+		if err != nil {
+			log.Error("parse size: ", err)
+			continue
+		}
+		// The side is the one of the maker order, i.e. the taker sold if it is buy.
+		if trade.Side == "buy" {
+			volume = -volume
+		}
+		trades = append(trades, dia.Trade{
+			Price:          price,
+			Volume:         volume,
+			Time:           timestamp,
+			ForeignTradeID: strconv.FormatInt(int64(trade.TradeID), 16),
+		})
+	}
+	next = strconv.Itoa(tradeID + coinbaseTradesLimit)
+	return
+}
+
+// seekTradeID fetches the page of trades of @pair preceding the trade id @after, or the latest page if @after
+// is empty. It returns the cursor of the first trade at or after @starttime if the page contains it, and the
+// seek cursor of the preceding page otherwise. @done is true if there are no trades after @starttime.
+func (s *CoinBaseScraper) seekTradeID(pair dia.ExchangePair, starttime time.Time, after string) (next string, done bool, err error) {
+	response, err := s.fetchTrades(pair, after)
+	if err != nil {
+		return
+	}
+	if len(response) == 0 {
+		// The first trade of the product is reached.
+		if after == "" {
+			done = true
+			return
+		}
+		next = after
+		return
+	}
+	oldest := response[len(response)-1]
+	if !oldest.Time.Time().Before(starttime) {
+		next = coinbaseSeekCursor + strconv.Itoa(oldest.TradeID)
+		return
+	}
+	// Trades are returned latest first, so the first trade of the time range follows the last one before it.
+	for i := len(response) - 1; i >= 0; i-- {
+		if !response[i].Time.Time().Before(starttime) {
+			next = strconv.Itoa(response[i].TradeID)
+			return
+		}
+	}
+	if after == "" {
+		done = true
+		return
+	}
+	next = after
+	return
+}
+
+// fetchTrades returns up to coinbaseTradesLimit trades of @pair preceding the trade id @after, latest first.
+func (s *CoinBaseScraper) fetchTrades(pair dia.ExchangePair, after string) (response []gdax.Trade, err error) {
+	url := "https://api.pro.coinbase.com/products/" + pair.ForeignName + "/trades?limit=" + strconv.Itoa(coinbaseTradesLimit)
+	if after != "" {
+		url += "&after=" + after
+	}
+	data, _, err := utils.GetRequest(url)
+	if err != nil {
+		return
+	}
+	err = json.Unmarshal(data, &response)
+	return
+}
@@ -14,6 +14,7 @@ import (
 	"go.uber.org/ratelimit"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 )
@@ -29,9 +30,6 @@ const (
 	// cryptoDotComTaskMaxRetry is a max retry value used when retrying subscribe/unsubscribe trades.
 	cryptoDotComTaskMaxRetry = 20
 
-	// cryptoDotComConnMaxRetry is a max retry value used when retrying to create a new connection.
-	cryptoDotComConnMaxRetry = 50
-
 	// cryptoDotComRateLimitError is a rate limit error code.
 	cryptoDotComRateLimitError = 10006
 
@@ -124,7 +122,7 @@ type cryptoDotComInstrumentResponse struct {
 
 // CryptoDotComScraper is a scraper for Crypto.com
 type CryptoDotComScraper struct {
-	ws *ws.Conn
+	ws *wsHelper.WSSession
 	rl ratelimit.Limiter
 
 	// signaling channels for session initialization and finishing
@@ -148,10 +146,6 @@ type CryptoDotComScraper struct {
 	db           *models.RelDB
 	taskCount    int32
 	tasks        sync.Map
-
-	// used to handle connection retry
-	connMutex      sync.RWMutex
-	connRetryCount int
 }
 
 // NewCryptoDotComScraper returns a new Crypto.com scraper
@@ -165,12 +159,10 @@ func NewCryptoDotComScraper(exchange dia.Exchange, scrape bool, relDB *models.Re
 		db:           relDB,
 	}
 
-	if err := s.newConn(); err != nil {
-		log.Error(err)
-
-		return nil
-	}
-
+	s.ws = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{
+		Dial:  s.dial,
+		OnGap: recordWSGaps(relDB, s.pairs),
+	})
 	s.rl = ratelimit.New(cryptoDotComWSRateLimitPerSec)
 
 	if scrape {
@@ -189,6 +181,10 @@ func (s *CryptoDotComScraper) Close() error {
 	s.signalShutdown.Do(func() {
 		close(s.shutdown)
 	})
+	// Unblock the read of the main loop.
+	if err := s.ws.Close(); err != nil {
+		log.Warn("CryptoDotComScraper: close websocket: ", err)
+	}
 
 	<-s.shutdownDone
 
@@ -263,19 +259,21 @@ func (s *CryptoDotComScraper) mainLoop() {
 		select {
 		case <-s.shutdown:
 			log.Println("CryptoDotComScraper: Shutting down main loop")
+			return
 		default:
 		}
 
 		var res cryptoDotComWSResponse
-		if err := s.wsConn().ReadJSON(&res); err != nil {
-			log.Warnf("CryptoDotComScraper: Creating a new connection caused by err=%s", err.Error())
-
-			if retryErr := s.retryConnection(); retryErr != nil {
-				s.setError(retryErr)
-				log.Errorf("CryptoDotComScraper: Shutting down main loop after retrying to create a new connection, err=%s", retryErr.Error())
+		// The session reconnects and resubscribes by itself, so errors only occur once it is closed.
+		if _, msg, err := s.ws.ReadMessage(); err != nil {
+			if !errors.Is(err, wsHelper.ErrWSSessionClosed) {
+				s.setError(err)
 			}
-
-			log.Info("CryptoDotComScraper: Successfully created a new connection")
+			log.Errorf("CryptoDotComScraper: Shutting down main loop, err=%s", err.Error())
+			return
+		} else if err := json.Unmarshal(msg, &res); err != nil {
+			log.Warnf("CryptoDotComScraper: Skipping malformed message, err=%s", err.Error())
+			continue
 		}
 		if res.Code == cryptoDotComRateLimitError {
 			time.Sleep(time.Duration(cryptoDotComBackoffSeconds) * time.Second)
@@ -351,10 +349,10 @@ func (s *CryptoDotComScraper) mainLoop() {
 	}
 }
 
-func (s *CryptoDotComScraper) newConn() error {
+func (s *CryptoDotComScraper) dial() (*ws.Conn, error) {
 	conn, _, err := ws.DefaultDialer.Dial(cryptoDotComWSEndpoint, nil)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	// Crypto.com recommends adding a 1-second sleep after establishing the websocket connection, and before requests are sent
@@ -362,31 +360,29 @@ func (s *CryptoDotComScraper) newConn() error {
 	// https://exchange-docs.crypto.com/spot/index.html?javascript#websocket-subscriptions
 	time.Sleep(time.Duration(cryptoDotComBackoffSeconds) * time.Second)
 
-	defer s.connMutex.Unlock()
-	s.connMutex.Lock()
-	s.ws = conn
-
-	return nil
+	return conn, nil
 }
 
-func (s *CryptoDotComScraper) wsConn() *ws.Conn {
-	defer s.connMutex.RUnlock()
-	s.connMutex.RLock()
-
-	return s.ws
+// pairs returns the foreign names of all subscribed pairs.
+func (s *CryptoDotComScraper) pairs() (pairs []string) {
+	s.pairScrapers.Range(func(key, value interface{}) bool {
+		pairs = append(pairs, key.(string))
+		return true
+	})
+	return
 }
 
 func (s *CryptoDotComScraper) ping(id int) error {
 	s.rl.Take()
 
-	return s.wsConn().WriteJSON(&cryptoDotComWSRequest{
+	return s.ws.WriteJSON(&cryptoDotComWSRequest{
 		ID:     id,
 		Method: "public/respond-heartbeat",
 	})
 }
 
 func (s *CryptoDotComScraper) cleanup() {
-	if err := s.wsConn().Close(); err != nil {
+	if err := s.ws.Close(); err != nil {
 		s.setError(err)
 	}
 
@@ -465,31 +461,6 @@ func (s *CryptoDotComScraper) unsubscribe(pairs []dia.ExchangePair) error {
 	return s.send(taskID, task)
 }
 
-func (s *CryptoDotComScraper) retryConnection() error {
-	s.connRetryCount += 1
-	if s.connRetryCount > cryptoDotComConnMaxRetry {
-		return errors.New("CryptoDotComPairScraper: Reached max retry connection")
-	}
-	if err := s.wsConn().Close(); err != nil {
-		return err
-	}
-	if err := s.newConn(); err != nil {
-		return err
-	}
-
-	var pairs []dia.ExchangePair
-	s.pairScrapers.Range(func(key, value interface{}) bool {
-		pair := value.(dia.ExchangePair)
-		pairs = append(pairs, pair)
-		return true
-	})
-	if err := s.subscribe(pairs); err != nil {
-		return err
-	}
-
-	return nil
-}
-
 func (s *CryptoDotComScraper) retryTask(taskID int) error {
 	val, ok := s.tasks.Load(taskID)
 	if !ok {
@@ -508,15 +479,23 @@ func (s *CryptoDotComScraper) retryTask(taskID int) error {
 	return s.send(taskID, task)
 }
 
+// send sends @task. Subscriptions are registered with the session, such that they are renewed after reconnecting.
 func (s *CryptoDotComScraper) send(taskID int, task cryptoDotComWSTask) error {
 	s.rl.Take()
 
-	return s.wsConn().WriteJSON(&cryptoDotComWSRequest{
+	request := &cryptoDotComWSRequest{
 		ID:     taskID,
 		Method: task.Method,
 		Params: task.Params,
 		Nonce:  time.Now().UnixNano() / 1000,
-	})
+	}
+	switch task.Method {
+	case "subscribe":
+		return s.ws.Subscribe(strings.Join(task.Params.Channels, ","), request)
+	case "unsubscribe":
+		return s.ws.Unsubscribe(strings.Join(task.Params.Channels, ","), request)
+	}
+	return s.ws.WriteJSON(request)
 }
 
 // CryptoDotComPairScraper implements PairScraper for Crypto.com
@@ -553,3 +532,8 @@ func (p *CryptoDotComPairScraper) Close() error {
 
 	return nil
 }
+
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (p *CryptoDotComPairScraper) Unsubscribe() error {
+	return p.Close()
+}
@@ -93,6 +93,8 @@ type CurveFIScraper struct {
 	resubscribe chan string
 	pools       *Pools
 	contract    common.Address
+	// holds trades until their swap logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 func NewCurveFIScraper(exchange dia.Exchange, scrape bool) *CurveFIScraper {
@@ -154,6 +156,7 @@ func NewCurveFIScraper(exchange dia.Exchange, scrape bool) *CurveFIScraper {
 	log.Infof("loaded cryptoswap pools. Now %v pools.", len(scraper.pools.pools))
 
 	if scrape {
+		scraper.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, wsClient, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 	return scraper
@@ -272,10 +275,11 @@ func (scraper *CurveFIScraper) processSwap(pool string, swp *curvepool.Curvepool
 		ForeignTradeID: swp.Raw.TxHash.Hex() + "-" + fmt.Sprint(swp.Raw.Index),
 		Source:         scraper.exchangeName,
 		VerifiedPair:   true,
+		PoolAddress:    pool,
 	}
 	// log.Infof("Got Trade in pool %s:\n %v", pool, trade)
 
-	scraper.chanTrades <- trade
+	scraper.reorgBuffer.Add(trade, swp.Raw)
 
 }
 
@@ -332,3 +332,8 @@ func (p *FTXPairScraper) Close() error {
 
 	return nil
 }
+
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (p *FTXPairScraper) Unsubscribe() error {
+	return p.Close()
+}
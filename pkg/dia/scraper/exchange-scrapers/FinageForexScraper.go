@@ -9,9 +9,9 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	"github.com/gorilla/websocket"
 )
 
 type FinageWSMessage struct {
@@ -43,9 +43,11 @@ type FinageForexScraper struct {
 	ticker       *time.Ticker
 	datastore    *models.RelDB
 	chanTrades   chan *dia.Trade
-	wsConn       *websocket.Conn
+	wsConn       *wsHelper.WSSession
 	exchangeName string
 	apiKey       string
+	// tradePairs holds the pairs of the subscribed symbols as given in trades.
+	tradePairs []string
 }
 
 // SpawnECBScraper returns a new ECBScraper initialized with default values.
@@ -53,13 +55,7 @@ type FinageForexScraper struct {
 func NewFinageForexScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB, finageAPIkey string, finageWebsocketKey string) *FinageForexScraper {
 	var finage = "wss://w29hxx2ndd.finage.ws:8001/?token=" + finageWebsocketKey
 
-	c, _, err := websocket.DefaultDialer.Dial(finage, nil)
-	if err != nil {
-		log.Fatal("dial:", err)
-	}
-
 	s := &FinageForexScraper{
-		wsConn:       c,
 		shutdown:     make(chan nothing),
 		exchangeName: exchange.Name,
 		shutdownDone: make(chan nothing),
@@ -70,6 +66,10 @@ func NewFinageForexScraper(exchange dia.Exchange, scrape bool, relDB *models.Rel
 		datastore:    relDB,
 		apiKey:       finageAPIkey,
 	}
+	s.wsConn = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{
+		URL:   finage,
+		OnGap: recordWSGaps(relDB, func() []string { return s.tradePairs }),
+	})
 
 	log.Info("Scraper is built and initiated")
 	if scrape {
@@ -92,9 +92,11 @@ func (s *FinageForexScraper) subscribe() error {
 
 	for _, ps := range pairs {
 		pairTosubscribe = pairTosubscribe + "," + ps.ForeignName
+		s.tradePairs = append(s.tradePairs, strings.Replace(ps.ForeignName, "/", "-", 1))
 	}
 	log.Infoln("pairTosubscribe", pairTosubscribe)
-	return s.wsConn.WriteJSON(FinageWSMessage{Action: "subscribe", Symbols: pairTosubscribe})
+	// The session sends the subscription again after reconnecting.
+	return s.wsConn.Subscribe("trades", FinageWSMessage{Action: "subscribe", Symbols: pairTosubscribe})
 
 }
 
@@ -121,10 +123,11 @@ func (s *FinageForexScraper) Update() error {
 
 	go func() {
 		for {
+			// The session reconnects and resubscribes by itself, so errors only occur once it is closed.
 			_, message, err := s.wsConn.ReadMessage()
 			if err != nil {
-				//s.subscribe()
 				log.Println("err", err)
+				return
 			}
 
 			var ftrade FinageTrade
@@ -190,6 +193,9 @@ func (s *FinageForexScraper) Close() error {
 		return errors.New("FinageForexScraper: Already closed")
 	}
 	close(s.shutdown)
+	if err := s.wsConn.Close(); err != nil {
+		log.Warn("FinageForexScraper: close websocket: ", err)
+	}
 	<-s.shutdownDone
 	s.errorLock.RLock()
 	defer s.errorLock.RUnlock()
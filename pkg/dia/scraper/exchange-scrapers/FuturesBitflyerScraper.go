@@ -10,9 +10,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	zap "go.uber.org/zap"
-
-	"github.com/gorilla/websocket"
 )
 
 const scrapeDataSaveLocationBitflyer = ""
@@ -47,8 +46,9 @@ func NewBitflyerFuturesScraper(markets []string) FuturesScraper {
 	return scraper
 }
 
-func (s *BitflyerScraper) send(message *map[string]interface{}, market string, websocketConn *websocket.Conn) error {
-	err := websocketConn.WriteJSON(*message)
+// subscribe sends the subscription @message for @market. It is renewed by the session after reconnecting.
+func (s *BitflyerScraper) subscribe(message *map[string]interface{}, market string, websocketConn *wsHelper.WSSession) error {
+	err := websocketConn.Subscribe(market, *message)
 	if err != nil {
 		return err
 	}
@@ -67,18 +67,13 @@ func (s *BitflyerScraper) Authenticate(market string, connection interface{}) er
 // to gorilla websocket here; However, to make FuturesScraper more ubiquituous, we need an interface here.
 func (s *BitflyerScraper) ScraperClose(market string, connection interface{}) error {
 	switch c := connection.(type) {
-	case *websocket.Conn:
+	case *wsHelper.WSSession:
 		// unsubscribe from the channel
-		err := s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, c)
+		err := c.Unsubscribe(market, &map[string]interface{}{"jsonrpc": "2.0", "method": "unsubscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}})
 		if err != nil {
 			s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
-			return err
 		}
 		// close the websocket connection
-		err = s.write(websocket.CloseMessage, []byte{}, c)
-		if err != nil {
-			return err
-		}
 		err = c.Close()
 		if err != nil {
 			return err
@@ -93,101 +88,42 @@ func (s *BitflyerScraper) ScraperClose(market string, connection interface{}) er
 
 // Scrape starts a websocket scraper for market
 func (s *BitflyerScraper) Scrape(market string) {
+	u := url.URL{Scheme: "wss", Host: "ws.lightstream.bitflyer.com", Path: "/json-rpc"}
+	s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
+	// the session reconnects and renews the subscription on its own and pings the server every 15 seconds.
+	ws := wsHelper.NewWSSession("Bitflyer", wsHelper.WSSessionConfig{URL: u.String(), PingInterval: 15 * time.Second})
+
 	// this block is for listening to sigterms and interupts
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	userCancelled := make(chan bool, 1)
 	go func() {
 		sig := <-sigs
 		fmt.Println(sig)
-		userCancelled <- true
+		s.Logger.Infof("received interrupt, gracefully shutting down")
+		err := s.ScraperClose(market, ws)
+		if err != nil {
+			log.Error(err)
+		}
+		os.Exit(0)
 	}()
 
-	for {
-		// immediately invoked function expression for easy clenup with defer
-		func() {
-			u := url.URL{Scheme: "wss", Host: "ws.lightstream.bitflyer.com", Path: "/json-rpc"}
-			s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
-			ws, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
-			if err != nil {
-				s.Logger.Errorf("could not dial Bitflyer websocket: %s", err)
-				time.Sleep(time.Duration(retryIn) * time.Second)
-				return
-			}
-			defer func() {
-				err = resp.Body.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			defer func() {
-				err = ws.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			defer func() {
-				err = s.ScraperClose(market, ws)
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-
-			ws.SetPongHandler(func(appData string) error {
-				s.Logger.Debugf("received a pong frame")
-				return nil
-			})
-			err = s.send(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, ws)
-			if err != nil {
-				s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
-				return
-			}
-			tick := time.NewTicker(15 * time.Second)
-			defer tick.Stop()
-			go func() {
-				for range tick.C {
-					err := s.write(websocket.PingMessage, []byte{}, ws)
-					if err != nil {
-						s.Logger.Errorf("error experienced pinging coinflex, err: %s", err)
-						return
-					}
-					s.Logger.Debugf("pinged the coinflex server. market: [%s]", market)
-				}
-			}()
-			for {
-				select {
-				case <-userCancelled:
-					s.Logger.Infof("received interrupt, gracefully shutting down")
-					err := s.ScraperClose(market, ws)
-					if err != nil {
-						log.Error(err)
-					}
-					os.Exit(0)
-				default:
-					_, message, err := ws.ReadMessage()
-					if err != nil {
-						s.Logger.Errorf("repeated read error, restarting")
-						return
-					}
-					s.Logger.Debugf("received new message: %s, saving new message", message)
-					_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationBitflyer+s.Writer.GetWriteFileName("Bitflyer", market))
-					if err != nil {
-						s.Logger.Errorf("could not write to file, err: %s", err)
-						return
-					}
-				}
-			}
-		}()
-	}
-}
-
-// write's primary purpose is to write a ping frame op code to keep the websocket connection alive
-func (s *BitflyerScraper) write(mt int, payload []byte, ws *websocket.Conn) error {
-	err := ws.SetWriteDeadline(time.Now().Add(15 * time.Second))
+	err := s.subscribe(&map[string]interface{}{"jsonrpc": "2.0", "method": "subscribe", "params": &map[string]interface{}{"channel": "lightning_ticker_" + market}}, market, ws)
 	if err != nil {
-		return err
+		s.Logger.Errorf("could not send a channel subscription message, err: %s", err)
+	}
+	for {
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			// ReadMessage only fails once the session is closed.
+			s.Logger.Infof("bitflyer session on [%s] closed", market)
+			return
+		}
+		s.Logger.Debugf("received new message: %s, saving new message", message)
+		_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationBitflyer+s.Writer.GetWriteFileName("Bitflyer", market))
+		if err != nil {
+			s.Logger.Errorf("could not write to file, err: %s", err)
+		}
 	}
-	return ws.WriteMessage(mt, payload)
 }
 
 // ScrapeMarkets - will scrape the markets specified during instantiation
@@ -12,8 +12,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
@@ -100,8 +100,9 @@ func NewCoinflexFuturesScraper(markets []string) FuturesScraper {
 	return scraper
 }
 
-func (s *CoinflexFuturesScraper) send(message *map[string]interface{}, market string, websocketConn *websocket.Conn) error {
-	err := websocketConn.WriteJSON(*message)
+// subscribe sends the subscription @message for @market. It is renewed by the session after reconnecting.
+func (s *CoinflexFuturesScraper) subscribe(message *map[string]interface{}, market string, websocketConn *wsHelper.WSSession) error {
+	err := websocketConn.Subscribe(market, *message)
 	if err != nil {
 		return err
 	}
@@ -120,8 +121,9 @@ func (s *CoinflexFuturesScraper) Authenticate(market string, connection interfac
 // to gorilla websocket here; However, to make FuturesScraper more ubiquituous, we need an interface here.
 func (s *CoinflexFuturesScraper) ScraperClose(market string, connection interface{}) error {
 	switch c := connection.(type) {
-	case *websocket.Conn:
-		err := s.write(websocket.CloseMessage, []byte{}, c)
+	case *wsHelper.WSSession:
+		// Coinflex has no unsubscribe message, the subscription ends with the connection.
+		err := c.Unsubscribe(market, nil)
 		if err != nil {
 			return err
 		}
@@ -155,118 +157,52 @@ func (s *CoinflexFuturesScraper) Scrape(market string) {
 		return
 	}
 
+	u := url.URL{Scheme: "wss", Host: "api.coinflex.com", Path: "/v1"}
+	s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
+	// every 45 seconds we have to ping Coinflex. Coinflex do not have the heartbeat channel, so the
+	// session sends ping frames every 30 seconds, also while ReadMessage is blocking.
+	ws := wsHelper.NewWSSession("Coinflex", wsHelper.WSSessionConfig{URL: u.String(), PingInterval: 30 * time.Second})
+
 	// this block is for listening to sigterms and interupts
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	userCancelled := make(chan bool, 1)
 	go func() {
 		sig := <-sigs
 		fmt.Println(sig)
-		userCancelled <- true
+		s.Logger.Infof("received interrupt, gracefully shutting down")
+		err := s.ScraperClose(market, ws)
+		if err != nil {
+			log.Error(err)
+		}
+		os.Exit(0)
 	}()
 
+	err = s.subscribe(&map[string]interface{}{"base": baseID, "counter": quoteID, "watch": true, "method": "WatchOrders"}, market, ws)
+	if err != nil {
+		s.Logger.Errorf("could not send a channel subscription message, err: %s", err)
+	}
 	for {
-		// immediately invoked function expression for easy clenup with defer
-		func() {
-			u := url.URL{Scheme: "wss", Host: "api.coinflex.com", Path: "/v1"}
-			s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
-			ws, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
-			if err != nil {
-				s.Logger.Errorf("dial: %s", err)
-				time.Sleep(time.Duration(retryIn) * time.Second)
-				return
-			}
-			defer func() {
-				err = resp.Body.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			defer func() {
-				err = ws.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			defer func() {
-				err = s.ScraperClose(market, ws)
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			// to let you know that the websocket connection is alive. Coinflex do not have the heartbeat channel
-			// and they send you frame pong messages. Thus, this handler.
-			ws.SetPongHandler(func(appData string) error {
-				s.Logger.Debugf("received a pong frame")
-				return nil
-			})
-			err = s.send(&map[string]interface{}{"base": baseID, "counter": quoteID, "watch": true, "method": "WatchOrders"}, market, ws)
-			if err != nil {
-				s.Logger.Errorf("could not send a channel subscription message. retrying")
-				return
-			}
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			// ReadMessage only fails once the session is closed.
+			s.Logger.Infof("coinflex session on [%s] closed", market)
+			return
+		}
+		msg := ordersMatchedCoinflex{}
+		err = json.Unmarshal(message, &msg)
+		if err != nil {
+			s.Logger.Errorf("could not unmarshal coinflex message on [%s], err: %s", market, err)
+			continue
+		}
+		s.Logger.Debugf("received a message: %s", message)
+		if msg.Notice == "OrdersMatched" {
+			s.Logger.Debugf("received new match message on [%s]: %s", market, message)
+			_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market))
 			if err != nil {
-				s.Logger.Errorf("could not send an initial ping message. retrying")
-				return
+				s.Logger.Errorf("could not save to file: %s, on market: [%s], err: %s", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market), market, err)
 			}
-			tick := time.NewTicker(30 * time.Second) // every 45 seconds we have to ping Coinflex. we also have a 15 second write limit of the ping frame (thus, 30 seconds here)
-			defer tick.Stop()
-			// we require a separate goroutine for ticker, because ReadMessage is blocking
-			// and we may fail sending ping before we get any message on a market, thus
-			// forcing Coinflex to close our websocket out.
-			go func() {
-				for range tick.C {
-					err := s.write(websocket.PingMessage, []byte{}, ws)
-					if err != nil {
-						s.Logger.Errorf("error experienced pinging coinflex, err: %s", err)
-						return
-					}
-					s.Logger.Debugf("pinged the coinflex server. market: [%s]", market)
-				}
-			}()
-			for {
-				select {
-				case <-userCancelled:
-					s.Logger.Infof("received interrupt, gracefully shutting down")
-					err := s.ScraperClose(market, ws)
-					if err != nil {
-						log.Error(err)
-					}
-					os.Exit(0)
-				default:
-					_, message, err := ws.ReadMessage()
-					msg := ordersMatchedCoinflex{}
-					if err != nil {
-						s.Logger.Errorf("problem reading coinflex on [%s], err: %s", market, err)
-						return
-					}
-					err = json.Unmarshal(message, &msg)
-					if err != nil {
-						s.Logger.Errorf("could not unmarshal coinflex message on [%s], err: %s", market, err)
-						return
-					}
-					s.Logger.Debugf("received a message: %s", message)
-					if msg.Notice == "OrdersMatched" {
-						s.Logger.Debugf("received new match message on [%s]: %s", market, message)
-						_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market))
-						if err != nil {
-							s.Logger.Errorf("could not save to file: %s, on market: [%s], err: %s", scrapeDataSaveLocationCoinflex+s.Writer.GetWriteFileName("coinflex", market), market, err)
-							return
-						}
-					}
-				}
-			}
-		}()
-	}
-}
-
-// write's primary purpose is to write a ping frame op code to keep the websocket connection alive
-func (s *CoinflexFuturesScraper) write(mt int, payload []byte, ws *websocket.Conn) error {
-	err := ws.SetWriteDeadline(time.Now().Add(15 * time.Second))
-	if err != nil {
-		return err
+		}
 	}
-	return ws.WriteMessage(mt, payload)
 }
 
 // ScrapeMarkets - will scrape the markets specified during instantiation
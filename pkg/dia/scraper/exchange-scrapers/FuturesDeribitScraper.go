@@ -7,13 +7,15 @@ import (
 	"sync"
 	"time"
 
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
 // const scrapeDataSaveLocationDeribit = ""
 
+const deribitWSEndpoint = "wss://www.deribit.com/ws/api/v2/"
+
 type deribitRefreshMessage struct {
 	Result struct {
 		RefreshToken string `json:"refresh_token"`
@@ -68,24 +70,22 @@ func NewDeribitFuturesScraper(markets []string, accessKey string, accessSecret s
 		// expiry is 900 seconds
 		RefreshTokenEvery: 800,
 		MarketKind:        DeribitFuture, // DO NOT change this.
+		WsConnection:      wsHelper.NewWSSession("Deribit", wsHelper.WSSessionConfig{URL: deribitWSEndpoint}),
 	}
 
 	return &scraper
 }
 
-func (s *DeribitScraper) send(message *map[string]interface{}, websocketConn *websocket.Conn) error {
-	err := websocketConn.WriteJSON(*message)
-	if err != nil {
-		return err
-	}
-	return nil
+// subscribe sends the subscription @message for @market. It is renewed by the session after reconnecting.
+func (s *DeribitScraper) subscribe(market string, message *map[string]interface{}, websocketConn *wsHelper.WSSession) error {
+	return websocketConn.Subscribe(market, *message)
 }
 
 // ScraperClose - responsible for closing out the scraper for a market
 func (s *DeribitScraper) ScraperClose(market string, websocketConnection interface{}) error {
 	switch c := websocketConnection.(type) {
-	case *websocket.Conn:
-		err := c.WriteJSON(map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
+	case *wsHelper.WSSession:
+		err := c.Unsubscribe(market, map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
 		if err != nil {
 			return err
 		}
@@ -93,7 +93,7 @@ func (s *DeribitScraper) ScraperClose(market string, websocketConnection interfa
 		time.Sleep(time.Duration(retryIn) * time.Second)
 		return nil
 	default:
-		return fmt.Errorf("unknown connection type, expected gorilla/websocket, got: %T", c)
+		return fmt.Errorf("unknown connection type, expected wsHelper.WSSession, got: %T", c)
 	}
 }
 
@@ -124,9 +124,9 @@ func (s *DeribitScraper) Scrape(market string) {
 
 	switch s.MarketKind {
 	case DeribitFuture:
-		err = s.send(futureRequest, s.WsConnection)
+		err = s.subscribe(market, futureRequest, s.WsConnection)
 	case DeribitOption:
-		err = s.send(optionRequest, s.WsConnection)
+		err = s.subscribe(market, optionRequest, s.WsConnection)
 	default:
 		panic("unknown market kind")
 	}
@@ -11,8 +11,8 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	"github.com/gorilla/websocket"
 	zap "go.uber.org/zap"
 )
 
@@ -58,8 +58,9 @@ func NewFTXFuturesScraper(markets []string) FuturesScraper {
 	return scraper
 }
 
-func (s *FTXFuturesScraper) send(message *map[string]string, market string, websocketConn *websocket.Conn) error {
-	err := websocketConn.WriteJSON(*message)
+// subscribe sends the subscription @message for @market. It is renewed by the session after reconnecting.
+func (s *FTXFuturesScraper) subscribe(message *map[string]string, market string, websocketConn *wsHelper.WSSession) error {
+	err := websocketConn.Subscribe(market, *message)
 	if err != nil {
 		return err
 	}
@@ -76,10 +77,10 @@ func (s *FTXFuturesScraper) Authenticate(market string, connection interface{})
 // to gorilla websocket here; However, to make FuturesScraper more ubiquituous, we need an interface here.
 func (s *FTXFuturesScraper) ScraperClose(market string, connection interface{}) error {
 	switch c := connection.(type) {
-	case *websocket.Conn:
-		err := c.WriteJSON(map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
+	case *wsHelper.WSSession:
+		err := c.Unsubscribe(market, map[string]string{"op": "unsubscribe", "channel": "trades", "market": market})
 		if err != nil {
-			return err
+			s.Logger.Errorf("could not send a channel unsubscription message, err: %s", err)
 		}
 		err = c.Close()
 		if err != nil {
@@ -89,7 +90,7 @@ func (s *FTXFuturesScraper) ScraperClose(market string, connection interface{})
 		time.Sleep(time.Duration(retryIn) * time.Second)
 		return nil
 	default:
-		return fmt.Errorf("unknown connection type, expected gorilla/websocket, got: %T", connection)
+		return fmt.Errorf("unknown connection type, expected wsHelper.WSSession, got: %T", connection)
 	}
 }
 
@@ -97,103 +98,55 @@ func (s *FTXFuturesScraper) ScraperClose(market string, connection interface{})
 func (s *FTXFuturesScraper) Scrape(market string) {
 	s.validateMarket(market)
 
+	u := url.URL{Scheme: "wss", Host: "ftx.com", Path: "/ws"}
+	s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
+	// every 15 seconds we have to ping FTX. The session sends the ping on its own, also while
+	// ReadMessage is blocking, and renews the subscription after reconnecting.
+	ws := wsHelper.NewWSSession("FTX", wsHelper.WSSessionConfig{
+		URL:          u.String(),
+		PingInterval: 15 * time.Second,
+		PingMessage:  func() interface{} { return map[string]string{"op": "ping"} },
+	})
+
 	// this block is for listening to sigterms and interupts
 	sigs := make(chan os.Signal, 1)
 	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
-	userCancelled := make(chan bool, 1)
 	go func() {
 		sig := <-sigs
 		fmt.Println(sig)
-		userCancelled <- true
+		s.Logger.Infof("received interrupt, gracefully shutting down")
+		err := s.ScraperClose(market, ws)
+		if err != nil {
+			log.Error(err)
+		}
+		os.Exit(0)
 	}()
 
+	err := s.subscribe(&map[string]string{"market": market, "channel": "trades", "op": "subscribe"}, market, ws)
+	if err != nil {
+		s.Logger.Errorf("could not send a channel subscription message, err: %s", err)
+	}
 	for {
-		// immediately invoked function expression for easy clenup with defer
-		func() {
-			u := url.URL{Scheme: "wss", Host: "ftx.com", Path: "/ws"}
-			s.Logger.Debugf("connecting to [%s], market: [%s]", u.String(), market)
-			ws, resp, err := websocket.DefaultDialer.Dial(u.String(), nil)
-			if err != nil {
-				s.Logger.Errorf("could not dial ftx websocket: %s", err)
-				time.Sleep(time.Duration(retryIn) * time.Second)
-				return
-			}
-			defer func() {
-				err = resp.Body.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-			defer func() {
-				err = ws.Close()
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-
-			defer func() {
-				err = s.ScraperClose(market, ws)
-				if err != nil {
-					log.Error(err)
-				}
-			}()
-
-			err = s.send(&map[string]string{"market": market, "channel": "trades", "op": "subscribe"}, market, ws)
-			if err != nil {
-				s.Logger.Errorf("could not send a channel subscription message. retrying, err: %s", err)
-				return
-			}
-			err = s.send(&map[string]string{"op": "ping"}, market, ws)
+		_, message, err := ws.ReadMessage()
+		if err != nil {
+			// ReadMessage only fails once the session is closed.
+			s.Logger.Infof("ftx session on [%s] closed", market)
+			return
+		}
+		decodedMsg := tradeMessageFTX{}
+		err = json.Unmarshal(message, &decodedMsg)
+		if err != nil {
+			s.Logger.Errorf("could not unmarshal ftx message on [%s], err: %s", market, err)
+			continue
+		}
+		s.Logger.Debugf("received new message: %s", message)
+		if decodedMsg.Type != "subscribed" && decodedMsg.Type != "pong" && decodedMsg.Type != "unsubscribed" {
+			s.Logger.Debugf("saving new message on [%s]", market)
+			_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationFTX+s.Writer.GetWriteFileName("ftx", market))
 			if err != nil {
-				s.Logger.Errorf("could not send an initial ping message. retrying, err: %s", err)
-				return
+				s.Logger.Errorf("could not write to file, err: %s", err)
 			}
-			tick := time.NewTicker(15 * time.Second) // every 15 seconds we have to ping FTX
-			defer tick.Stop()
-			// we require a separate goroutine for ticker, because ReadMessage is blocking
-			// and we may fail sending ping before we get any message on a market, thus
-			// forcing FTX to close our websocket out.
-			go func() {
-				for range tick.C {
-					err := s.send(&map[string]string{"op": "ping"}, market, ws)
-					if err != nil {
-						log.Error(err)
-					}
-				}
-			}()
-			for {
-				select {
-				case <-userCancelled:
-					s.Logger.Infof("received interrupt, gracefully shutting down")
-					err := s.ScraperClose(market, ws)
-					if err != nil {
-						log.Error(err)
-					}
-					os.Exit(0)
-				default:
-					_, message, err := ws.ReadMessage()
-					decodedMsg := tradeMessageFTX{}
-					if err != nil {
-						s.Logger.Errorf("problem reading ftx on [%s], err: %s", market, err)
-						return
-					}
-					err = json.Unmarshal(message, &decodedMsg)
-					if err != nil {
-						s.Logger.Errorf("could not unmarshal ftx message on [%s], err: %s", market, err)
-						return
-					}
-					s.Logger.Debugf("received new message: %s", message)
-					if decodedMsg.Type != "subscribed" && decodedMsg.Type != "pong" && decodedMsg.Type != "unsubscribed" {
-						s.Logger.Debugf("saving new message on [%s]", market)
-						_, err = s.Writer.Write(string(message)+"\n", scrapeDataSaveLocationFTX+s.Writer.GetWriteFileName("ftx", market))
-						if err != nil {
-							s.Logger.Errorf("could not write to file, err: %s", err)
-							return
-						}
-					}
-				}
-			}
-		}()
+		}
 	}
 }
 
@@ -10,9 +10,9 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 var _GateIOsocketurl string = "wss://api.gateio.ws/ws/v4/"
@@ -55,7 +55,7 @@ type SubscribeGate struct {
 }
 
 type GateIOScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	//initDone     chan nothing
 	shutdown     chan nothing
@@ -88,12 +88,7 @@ func NewGateIOScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 		isTickerMapInitialised: false,
 		db:                     relDB,
 	}
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_GateIOsocketurl, nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: _GateIOsocketurl, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -101,6 +96,14 @@ func NewGateIOScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *GateIOScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 type GateIPPairResponse []GateIOPair
 
 type GateIOPair struct {
@@ -156,7 +159,7 @@ func (s *GateIOScraper) mainLoop() {
 			Payload: []string{v.ID},
 		}
 		log.Infof("Subscribed for Pair %v", v.ID)
-		if err = s.wsClient.WriteJSON(a); err != nil {
+		if err = s.wsClient.Subscribe(v.ID, a); err != nil {
 			log.Error(err.Error())
 		}
 	}
@@ -0,0 +1,144 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/jackc/pgx/v4"
+)
+
+const (
+	// Delay between two requests of a backfill, in order to respect the exchanges' rate limits.
+	historicalPageDelay = 250 * time.Millisecond
+	// Number of retries of a failed page request before a backfill is aborted.
+	historicalMaxRetries = 5
+)
+
+// HistoricalScraper is implemented by APIScrapers which can fetch past trades through
+// the exchange's REST API. It is used to backfill gaps in the trades of CEX feeds.
+type HistoricalScraper interface {
+	// FetchTradesPage returns the next page of trades of @pair in the time range [@starttime, @endtime).
+	// @cursor is empty for the first page and the cursor returned with the previous page afterwards.
+	// Trades are ordered by time within a page. @done is true if there are no more pages in the time range.
+	FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) (trades []dia.Trade, next string, done bool, err error)
+}
+
+// HistoricalScraperState is the checkpoint of the backfill of a single pair.
+// It is stored after each page through RelDB.SetScraperState.
+type HistoricalScraperState struct {
+	StartTime time.Time `json:"start_time"`
+	EndTime   time.Time `json:"end_time"`
+	Cursor    string    `json:"cursor"`
+	Done      bool      `json:"done"`
+}
+
+func historicalScraperName(exchange string, pair dia.ExchangePair) string {
+	return "history_" + exchange + "_" + pair.ForeignName
+}
+
+// recordWSGaps returns an OnGap handler of a WSSession which records the gap for each pair returned
+// by @pairs in @relDB, such that the trades missed while reconnecting are backfilled by the tradeGapService.
+func recordWSGaps(relDB models.RelDatastore, pairs func() []string) func(wsHelper.WSGap) {
+	return func(gap wsHelper.WSGap) {
+		for _, pair := range pairs() {
+			err := relDB.SetTradeGap(dia.TradeGap{
+				Exchange:  gap.Exchange,
+				Pair:      pair,
+				StartTime: gap.Start,
+				EndTime:   gap.End,
+			})
+			if err != nil {
+				log.Errorf("record gap of %s on %s: %v", pair, gap.Exchange, err)
+			}
+		}
+	}
+}
+
+// BackfillTrades sends all trades of @pair on @exchange in [@starttime, @endtime) to @chanTrades.
+// Progress is checkpointed in @relDB after each page, such that an interrupted backfill of the same
+// time range resumes from the last checkpoint. A completed backfill of the same time range is skipped.
+func BackfillTrades(ctx context.Context, hs HistoricalScraper, exchange string, pair dia.ExchangePair, starttime time.Time, endtime time.Time, relDB models.RelDatastore, chanTrades chan<- *dia.Trade) error {
+	name := historicalScraperName(exchange, pair)
+	var state HistoricalScraperState
+	err := relDB.GetScraperState(ctx, name, &state)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if !state.StartTime.Equal(starttime) || !state.EndTime.Equal(endtime) {
+		state = HistoricalScraperState{StartTime: starttime, EndTime: endtime}
+	} else if state.Done {
+		log.Infof("backfill of %s on %s from %v to %v already done", pair.ForeignName, exchange, starttime, endtime)
+		return nil
+	} else if state.Cursor != "" {
+		log.Infof("resume backfill of %s on %s at cursor %s", pair.ForeignName, exchange, state.Cursor)
+	}
+
+	// Trades of pairs missing in the cache keep the tokens set by @hs, as is the case for DEX pairs.
+	exchangepair, cacheErr := relDB.GetExchangePairCache(exchange, pair.ForeignName)
+	if cacheErr != nil {
+		log.Warnf("get exchangepair %s from cache: %v", pair.ForeignName, cacheErr)
+	}
+
+	for !state.Done {
+		trades, next, done, err := fetchTradesPageWithRetry(ctx, hs, pair, state)
+		if err != nil {
+			return err
+		}
+		for i := range trades {
+			t := trades[i]
+			if pair.Symbol != "" {
+				t.Symbol = pair.Symbol
+			}
+			t.Pair = pair.ForeignName
+			t.Source = exchange
+			if cacheErr == nil {
+				t.VerifiedPair = exchangepair.Verified
+				t.BaseToken = exchangepair.UnderlyingPair.BaseToken
+				t.QuoteToken = exchangepair.UnderlyingPair.QuoteToken
+			}
+			select {
+			case chanTrades <- &t:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+		state.Cursor = next
+		state.Done = done
+		if err := relDB.SetScraperState(ctx, name, &state); err != nil {
+			return err
+		}
+		log.Infof("backfilled %d trades of %s on %s. next cursor: %s", len(trades), pair.ForeignName, exchange, next)
+
+		if !state.Done {
+			select {
+			case <-time.After(historicalPageDelay):
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		}
+	}
+	return nil
+}
+
+func fetchTradesPageWithRetry(ctx context.Context, hs HistoricalScraper, pair dia.ExchangePair, state HistoricalScraperState) (trades []dia.Trade, next string, done bool, err error) {
+	backoff := time.Second
+	for i := 0; i < historicalMaxRetries; i++ {
+		trades, next, done, err = hs.FetchTradesPage(pair, state.StartTime, state.EndTime, state.Cursor)
+		if err == nil {
+			return
+		}
+		log.Warnf("fetch trades of %s at cursor %s: %v. retry in %v", pair.ForeignName, state.Cursor, err, backoff)
+		select {
+		case <-time.After(backoff):
+		case <-ctx.Done():
+			err = ctx.Err()
+			return
+		}
+		backoff *= 2
+	}
+	return
+}
@@ -0,0 +1,103 @@
+package scrapers
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
+	models "github.com/diadata-org/diadata/pkg/model"
+)
+
+// pageScraper returns @trades in pages of two trades, using the index of the next trade as cursor.
+type pageScraper struct {
+	trades []dia.Trade
+	calls  int
+}
+
+func (ps *pageScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) ([]dia.Trade, string, bool, error) {
+	ps.calls++
+	index := 0
+	if cursor != "" {
+		index, _ = strconv.Atoi(cursor)
+	}
+	end := index + 2
+	if end >= len(ps.trades) {
+		return ps.trades[index:], "", true, nil
+	}
+	return ps.trades[index:end], strconv.Itoa(end), false, nil
+}
+
+func TestBackfillTradesResume(t *testing.T) {
+	starttime := time.Unix(1640995200, 0)
+	endtime := starttime.Add(time.Hour)
+	pair := dia.ExchangePair{Symbol: "BTC", ForeignName: "BTCUSDT", Exchange: dia.BinanceExchange}
+	hs := &pageScraper{}
+	for i := 0; i < 5; i++ {
+		hs.trades = append(hs.trades, dia.Trade{Price: float64(i), Time: starttime.Add(time.Duration(i) * time.Minute)})
+	}
+	relDB := models.NewMemoryRelDataStore()
+
+	// Interrupt the backfill after the first page.
+	ctx, cancel := context.WithCancel(context.Background())
+	chanTrades := make(chan *dia.Trade)
+	errs := make(chan error)
+	go func() {
+		errs <- BackfillTrades(ctx, hs, dia.BinanceExchange, pair, starttime, endtime, relDB, chanTrades)
+	}()
+	for i := 0; i < 2; i++ {
+		trade := <-chanTrades
+		if trade.Price != float64(i) || trade.Source != dia.BinanceExchange || trade.Pair != "BTCUSDT" {
+			t.Errorf("unexpected trade %v", trade)
+		}
+	}
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Fatalf("expected canceled backfill, got %v", err)
+	}
+
+	// The second backfill resumes after the first page.
+	chanTrades = make(chan *dia.Trade, 10)
+	if err := BackfillTrades(context.Background(), hs, dia.BinanceExchange, pair, starttime, endtime, relDB, chanTrades); err != nil {
+		t.Fatal(err)
+	}
+	close(chanTrades)
+	var prices []float64
+	for trade := range chanTrades {
+		prices = append(prices, trade.Price)
+	}
+	if len(prices) != 3 || prices[0] != 2 || prices[2] != 4 {
+		t.Errorf("expected trades 2 to 4, got %v", prices)
+	}
+
+	// A completed backfill is not repeated.
+	calls := hs.calls
+	if err := BackfillTrades(context.Background(), hs, dia.BinanceExchange, pair, starttime, endtime, relDB, make(chan *dia.Trade)); err != nil {
+		t.Fatal(err)
+	}
+	if hs.calls != calls {
+		t.Error("expected completed backfill to be skipped")
+	}
+}
+
+func TestRecordWSGaps(t *testing.T) {
+	relDB := models.NewMemoryRelDataStore()
+	start := time.Unix(1640995200, 0)
+	gap := wsHelper.WSGap{Exchange: dia.KrakenExchange, Start: start, End: start.Add(time.Minute), Attempts: 2}
+	recordWSGaps(relDB, func() []string { return []string{"XBTUSD", "ETHUSD"} })(gap)
+
+	gaps, err := relDB.GetTradeGapsByStatus(dia.TradeGapOpen, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(gaps) != 2 {
+		t.Fatalf("expected a gap per pair, got %v", gaps)
+	}
+	for _, g := range gaps {
+		if g.Exchange != dia.KrakenExchange || !g.StartTime.Equal(gap.Start) || !g.EndTime.Equal(gap.End) {
+			t.Errorf("unexpected gap %v", g)
+		}
+	}
+}
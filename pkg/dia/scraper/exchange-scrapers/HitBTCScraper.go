@@ -11,9 +11,9 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 var _socketurl string = "wss://api.hitbtc.com/api/2/ws"
@@ -43,7 +43,7 @@ type HitBTCAsset struct {
 }
 
 type HitBTCScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	shutdown     chan nothing
 	shutdownDone chan nothing
@@ -72,18 +72,21 @@ func NewHitBTCScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 		db:           relDB,
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_socketurl, nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: _socketurl, OnGap: recordWSGaps(relDB, s.pairs)})
 	if scrape {
 		go s.mainLoop()
 	}
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *HitBTCScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // runs in a goroutine until s is closed
 func (s *HitBTCScraper) mainLoop() {
 	var err error
@@ -212,7 +215,7 @@ func (s *HitBTCScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 		Id: int(time.Now().Unix()) * 1000,
 	}
 
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
 		fmt.Println(err.Error())
 	}
 
@@ -296,6 +299,21 @@ func (ps *HitBTCPairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *HitBTCPairScraper) Unsubscribe() error {
+	unsubscribe := &Event{
+		Method: "unsubscribeTrades",
+		Params: map[string]interface{}{
+			"symbol": ps.pair.ForeignName,
+		},
+		Id: int(time.Now().Unix()) * 1000,
+	}
+	if err := ps.parent.wsClient.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (ps *HitBTCScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
@@ -1,6 +1,7 @@
 package scrapers
 
 import (
+	"bytes"
 	"compress/gzip"
 	"encoding/json"
 	"errors"
@@ -12,17 +13,18 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 var _HuobiSocketurl string = "wss://api.huobi.pro/ws"
 
 type EventType struct {
-	Sub  string `json:"sub,omitempty"`
-	Id   string `json:"id,omitempty"`
-	Pong int    `json:"pong,omitempty"`
+	Sub   string `json:"sub,omitempty"`
+	Unsub string `json:"unsub,omitempty"`
+	Id    string `json:"id,omitempty"`
+	Pong  int    `json:"pong,omitempty"`
 }
 
 type ResponseType struct {
@@ -67,7 +69,7 @@ type HuobiCurrency struct {
 }
 
 type HuobiScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	//TODO: Channel not used. Consider removing or refactoring
 	shutdown     chan nothing
@@ -97,12 +99,7 @@ func NewHuobiScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *H
 		db:           relDB,
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_HuobiSocketurl, nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: _HuobiSocketurl, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -110,21 +107,28 @@ func NewHuobiScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *H
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *HuobiScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // runs in a goroutine until s is closed
 func (s *HuobiScraper) mainLoop() {
 	for {
 		message := &ResponseType{}
-		_, testRead, err := s.wsClient.NextReader()
+		_, testRead, err := s.wsClient.ReadMessage()
 
 		if err != nil {
-			// Conn errors are non-recoverable.
-			// Terminate the routine if theres any error
+			// The session reconnects by itself, so errors only occur once it is closed.
 			fmt.Println(err.Error())
 			break
 		} else {
 
 			//It has to gzip response data
-			reader, _ := gzip.NewReader(testRead)
+			reader, _ := gzip.NewReader(bytes.NewReader(testRead))
 			jsonBase := json.NewDecoder(reader)
 			err := jsonBase.Decode(message)
 			if err != nil {
@@ -272,7 +276,7 @@ func (s *HuobiScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 		Sub: "market." + strings.ToLower(pair.ForeignName) + ".trade.detail",
 		Id:  "id1",
 	}
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
 		fmt.Println(err.Error())
 	}
 	return ps, nil
@@ -349,6 +353,18 @@ func (ps *HuobiPairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *HuobiPairScraper) Unsubscribe() error {
+	unsubscribe := &EventType{
+		Unsub: "market." + strings.ToLower(ps.pair.ForeignName) + ".trade.detail",
+		Id:    "id1",
+	}
+	if err := ps.parent.wsClient.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (ps *HuobiScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
@@ -1,21 +1,34 @@
 package scrapers
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"math"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	krakenapi "github.com/beldur/kraken-go-api-client"
 	"github.com/diadata-org/diadata/pkg/dia"
 	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/diadata-org/diadata/pkg/utils"
 )
 
 const (
 	krakenRefreshDelay = time.Second * 30 * 1
+	krakenTradesURL    = "https://api.kraken.com/0/public/Trades"
 )
 
+// krakenTrade is a public trade as returned by Kraken's Trades endpoint.
+type krakenTrade struct {
+	Price  float64
+	Volume float64
+	Time   time.Time
+	Sell   bool
+	ID     int64
+}
+
 type KrakenScraper struct {
 	// signaling channels
 	shutdown     chan nothing
@@ -26,7 +39,6 @@ type KrakenScraper struct {
 	error        error
 	closed       bool
 	pairScrapers map[string]*KrakenPairScraper // pc.ExchangePair -> pairScraperSet
-	api          *krakenapi.KrakenApi
 	ticker       *time.Ticker
 	exchangeName string
 	chanTrades   chan *dia.Trade
@@ -40,7 +52,6 @@ func NewKrakenScraper(key string, secret string, exchange dia.Exchange, scrape b
 		shutdown:     make(chan nothing),
 		shutdownDone: make(chan nothing),
 		pairScrapers: make(map[string]*KrakenPairScraper),
-		api:          krakenapi.New(key, secret),
 		ticker:       time.NewTicker(krakenRefreshDelay),
 		exchangeName: exchange.Name,
 		error:        nil,
@@ -203,8 +214,8 @@ func (ps *KrakenPairScraper) Pair() dia.ExchangePair {
 	return ps.pair
 }
 
-func NewTrade(pair dia.ExchangePair, info krakenapi.TradeInfo, foreignTradeID string, relDB *models.RelDB) *dia.Trade {
-	volume := info.VolumeFloat
+func NewTrade(pair dia.ExchangePair, info krakenTrade, relDB *models.RelDB) *dia.Trade {
+	volume := info.Volume
 	if info.Sell {
 		volume = -volume
 	}
@@ -214,11 +225,11 @@ func NewTrade(pair dia.ExchangePair, info krakenapi.TradeInfo, foreignTradeID st
 	}
 	t := &dia.Trade{
 		Pair:           pair.ForeignName,
-		Price:          info.PriceFloat,
+		Price:          info.Price,
 		Symbol:         pair.Symbol,
 		Volume:         volume,
-		Time:           time.Unix(info.Time, 0),
-		ForeignTradeID: foreignTradeID,
+		Time:           info.Time,
+		ForeignTradeID: krakenTradeID(info),
 		Source:         dia.KrakenExchange,
 		VerifiedPair:   exchangepair.Verified,
 		BaseToken:      exchangepair.UnderlyingPair.BaseToken,
@@ -234,22 +245,153 @@ func (s *KrakenScraper) Update() {
 
 	for _, ps := range s.pairScrapers {
 
-		r, err := s.api.Trades(ps.pair.ForeignName, ps.lastRecord)
+		trades, last, err := fetchKrakenTrades(ps.pair.ForeignName, ps.lastRecord)
 
 		if err != nil {
 			log.Printf("err on collect trades %v %v", err, ps.pair.ForeignName)
 			time.Sleep(1 * time.Minute)
 		} else {
-			if r != nil {
-				ps.lastRecord = r.Last
-				for _, ti := range r.Trades {
-					// p, _ := s.NormalizePair(ps.pair)
-					t := NewTrade(ps.pair, ti, strconv.FormatInt(r.Last, 16), s.db)
-					ps.parent.chanTrades <- t
-				}
-			} else {
-				log.Printf("r nil")
+			ps.lastRecord = last
+			for _, ti := range trades {
+				// p, _ := s.NormalizePair(ps.pair)
+				t := NewTrade(ps.pair, ti, s.db)
+				ps.parent.chanTrades <- t
 			}
 		}
 	}
 }
+
+// FetchTradesPage returns up to 1000 trades of @pair in [@starttime, @endtime) through Kraken's Trades endpoint.
+// The cursor is Kraken's "since" parameter, a unix timestamp in nanoseconds.
+func (s *KrakenScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) (trades []dia.Trade, next string, done bool, err error) {
+	since := starttime.UnixNano()
+	if cursor != "" {
+		since, err = strconv.ParseInt(cursor, 10, 64)
+		if err != nil {
+			return
+		}
+	}
+	response, last, err := fetchKrakenTrades(pair.ForeignName, since)
+	if err != nil {
+		return
+	}
+	if len(response) == 0 || last <= since {
+		done = true
+		return
+	}
+	for _, info := range response {
+		if !info.Time.Before(endtime) {
+			done = true
+			break
+		}
+		volume := info.Volume
+		if info.Sell {
+			volume = -volume
+		}
+		trades = append(trades, dia.Trade{
+			Price:          info.Price,
+			Volume:         volume,
+			Time:           info.Time,
+			ForeignTradeID: krakenTradeID(info),
+		})
+	}
+	next = strconv.FormatInt(last, 10)
+	return
+}
+
+// krakenTradeID returns the foreign trade id of @trade. It is Kraken's trade id if given and the
+// timestamp in nanoseconds otherwise, such that live and backfilled trades get the same id.
+func krakenTradeID(trade krakenTrade) string {
+	if trade.ID > 0 {
+		return strconv.FormatInt(trade.ID, 16)
+	}
+	return strconv.FormatInt(trade.Time.UnixNano(), 16)
+}
+
+// fetchKrakenTrades returns the trades of @pair since @since, a unix timestamp in nanoseconds, together with
+// the since parameter of the next request. Unlike the kraken-go-api-client, it keeps sub-second timestamps and trade ids.
+func fetchKrakenTrades(pair string, since int64) (trades []krakenTrade, last int64, err error) {
+	url := krakenTradesURL + "?pair=" + pair
+	if since > 0 {
+		url += "&since=" + strconv.FormatInt(since, 10)
+	}
+	data, _, err := utils.GetRequest(url)
+	if err != nil {
+		return
+	}
+	var response struct {
+		Error  []string                   `json:"error"`
+		Result map[string]json.RawMessage `json:"result"`
+	}
+	err = json.Unmarshal(data, &response)
+	if err != nil {
+		return
+	}
+	if len(response.Error) > 0 {
+		err = errors.New(strings.Join(response.Error, ", "))
+		return
+	}
+	// The trades are keyed by Kraken's name of the pair, which may differ from @pair.
+	for key, raw := range response.Result {
+		if key == "last" {
+			var lastString string
+			err = json.Unmarshal(raw, &lastString)
+			if err != nil {
+				return
+			}
+			last, err = strconv.ParseInt(lastString, 10, 64)
+			if err != nil {
+				return
+			}
+			continue
+		}
+		var entries [][]interface{}
+		err = json.Unmarshal(raw, &entries)
+		if err != nil {
+			return
+		}
+		for _, entry := range entries {
+			trade, parseErr := parseKrakenTrade(entry)
+			if parseErr != nil {
+				log.Error("parse kraken trade: ", parseErr)
+				continue
+			}
+			trades = append(trades, trade)
+		}
+	}
+	return
+}
+
+// parseKrakenTrade parses a trade of the form [price, volume, time, side, type, misc, trade id].
+// The trade id is missing in responses of older API versions.
+func parseKrakenTrade(entry []interface{}) (trade krakenTrade, err error) {
+	if len(entry) < 4 {
+		err = fmt.Errorf("unexpected trade %v", entry)
+		return
+	}
+	priceString, _ := entry[0].(string)
+	trade.Price, err = strconv.ParseFloat(priceString, 64)
+	if err != nil {
+		return
+	}
+	volumeString, _ := entry[1].(string)
+	trade.Volume, err = strconv.ParseFloat(volumeString, 64)
+	if err != nil {
+		return
+	}
+	timestamp, ok := entry[2].(float64)
+	if !ok {
+		err = fmt.Errorf("unexpected timestamp %v", entry[2])
+		return
+	}
+	seconds := math.Floor(timestamp)
+	// Kraken's timestamps have a precision of 100 microseconds.
+	trade.Time = time.Unix(int64(seconds), int64(math.Round((timestamp-seconds)*1e6))*1e3)
+	trade.Sell = entry[3] == "s"
+	if len(entry) > 6 {
+		if id, ok := entry[6].(float64); ok {
+			trade.ID = int64(id)
+		}
+	}
+	return
+}
@@ -51,6 +51,8 @@ type KyberScraper struct {
 	RestClient  *ethclient.Client
 	resubscribe chan nothing
 	tokens      map[string]dia.Asset
+	// holds trades until their trade logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 func NewKyberScraper(exchange dia.Exchange, scrape bool) *KyberScraper {
@@ -80,6 +82,8 @@ func NewKyberScraper(exchange dia.Exchange, scrape bool) *KyberScraper {
 	time.Sleep(5 * time.Second)
 
 	if scrape {
+		// Kyber is only scraped on Ethereum.
+		scraper.reorgBuffer = newReorgBuffer(dia.ETHEREUM, wsClient, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 	return scraper
@@ -206,8 +210,8 @@ func (scraper *KyberScraper) subscribeToTrades() error {
 	return err
 }
 
-func (scraper *KyberScraper) processTrade(trade *kyber.KyberExecuteTrade) {
-	symbol, foreignName, volume, price, token0, token1, err := scraper.getTradeDataKyber(trade)
+func (scraper *KyberScraper) processTrade(executeTrade *kyber.KyberExecuteTrade) {
+	symbol, foreignName, volume, price, token0, token1, err := scraper.getTradeDataKyber(executeTrade)
 	timestamp := time.Now().Unix()
 	if err != nil {
 		log.Error(err)
@@ -220,13 +224,13 @@ func (scraper *KyberScraper) processTrade(trade *kyber.KyberExecuteTrade) {
 				Price:          price,
 				Volume:         volume,
 				Time:           time.Unix(timestamp, 0),
-				ForeignTradeID: "",
+				ForeignTradeID: executeTrade.Raw.TxHash.Hex() + "-" + fmt.Sprint(executeTrade.Raw.Index),
 				Source:         scraper.exchangeName,
 				BaseToken:      token1,
 				QuoteToken:     token0,
 				VerifiedPair:   true,
 			}
-			pairScraper.parent.chanTrades <- trade
+			scraper.reorgBuffer.Add(trade, executeTrade.Raw)
 			fmt.Println("got trade: ", trade)
 		}
 	}
@@ -9,9 +9,9 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 var _LBankSocketurl string = "wss://api.lbkex.com/ws/V2/"
@@ -45,7 +45,7 @@ type PongMessage struct {
 }
 
 type LBankScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	shutdown     chan nothing
 	shutdownDone chan nothing
@@ -74,12 +74,7 @@ func NewLBankScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *L
 		db:           relDB,
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_LBankSocketurl, nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: _LBankSocketurl, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -87,6 +82,14 @@ func NewLBankScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *L
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *LBankScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // runs in a goroutine until s is closed
 func (s *LBankScraper) mainLoop() {
 	var err error
@@ -250,7 +253,7 @@ func (s *LBankScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 		Subscribe: "trade",
 		Pair:      strings.ToLower(pair.ForeignName),
 	}
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
 		log.Error("ScrapePair" + err.Error())
 	}
 	return ps, nil
@@ -309,6 +312,19 @@ func (ps *LBankPairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *LBankPairScraper) Unsubscribe() error {
+	unsubscribe := &SubscribeLBank{
+		Action:    "unsubscribe",
+		Subscribe: "trade",
+		Pair:      strings.ToLower(ps.pair.ForeignName),
+	}
+	if err := ps.parent.wsClient.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (ps *LBankScraper) Channel() chan *dia.Trade {
 	return ps.chanTrades
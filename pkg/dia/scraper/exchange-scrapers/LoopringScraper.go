@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	ws "github.com/gorilla/websocket"
@@ -61,7 +62,7 @@ type LoopringMarket struct {
 }
 
 type LoopringScraper struct {
-	wsClient      *ws.Conn
+	wsClient      *wsHelper.WSSession
 	decimalsAsset map[string]float64
 	// signaling channels for session initialization and finishing
 	//TODO: Channel not used. Consider removing or refactoring
@@ -132,23 +133,21 @@ func NewLoopringScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB)
 		db:            relDB,
 	}
 
-	key, err := getAPIKey()
-	if err != nil {
-		log.Fatal("get api key: ", err)
-	}
-	s.wsURL = _LoopringSocketurl + "?wsApiKey=" + key
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(s.wsURL, nil)
-	if err != nil {
-		log.Error("Error connecting to ws: ", err.Error())
-	}
-
-	s.wsClient = SwConn
+	// The api key is fetched again on each reconnect.
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{Dial: s.dial, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	go s.mainLoop()
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *LoopringScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // runs in a goroutine until s is closed
 func (s *LoopringScraper) mainLoop() {
 
@@ -162,8 +161,7 @@ func (s *LoopringScraper) mainLoop() {
 		messageType, message, err := s.wsClient.ReadMessage()
 		if err != nil {
 			log.Error("reading websocket message: ", err)
-			s.reconnectToWS()
-			s.subscribeToALL()
+			break
 		}
 
 		err = json.Unmarshal(message, &makemap)
@@ -218,6 +216,15 @@ func (s *LoopringScraper) mainLoop() {
 			}
 		}
 	}
+	s.cleanup()
+}
+
+// must only be called from mainLoop
+func (s *LoopringScraper) cleanup() {
+	s.errorLock.Lock()
+	defer s.errorLock.Unlock()
+	s.closed = true
+	close(s.shutdownDone) // signal that shutdown is complete
 }
 
 func (s *LoopringScraper) subscribeToALL() {
@@ -239,7 +246,7 @@ func (s *LoopringScraper) subscribeToALL() {
 		Sequence: 1000,
 		Topics:   topics,
 	}
-	if err := s.wsClient.WriteJSON(wr); err != nil {
+	if err := s.wsClient.Subscribe("trade", wr); err != nil {
 		log.Error(err)
 	}
 
@@ -254,23 +261,17 @@ func (s *LoopringScraper) Pong(messageType int) error {
 	return nil
 }
 
-func (s *LoopringScraper) reconnectToWS() {
-
-	log.Info("Reconnecting ws")
+// dial connects to the websocket API with a fresh api key.
+func (s *LoopringScraper) dial() (*ws.Conn, error) {
 	key, err := getAPIKey()
 	if err != nil {
-		log.Fatal("fetching api key: ", err)
+		return nil, err
 	}
-
 	s.wsURL = _LoopringSocketurl + "?wsApiKey=" + key
 
 	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(s.wsURL, nil)
-
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	conn, _, err := wsDialer.Dial(s.wsURL, nil)
+	return conn, err
 }
 
 func getAPIKey() (string, error) {
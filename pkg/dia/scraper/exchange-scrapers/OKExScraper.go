@@ -13,6 +13,7 @@ import (
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	utils "github.com/diadata-org/diadata/pkg/utils"
 	ws "github.com/gorilla/websocket"
@@ -20,6 +21,8 @@ import (
 
 var _OKExSocketURL = "wss://ws.okex.com:8443/ws/v5/public"
 
+const okexPingInterval = 20 * time.Second
+
 //var _OKExSocketURL = url.URL{Scheme: "wss", Host: "real.okex.com:10441", Path: "/ws/v1", RawQuery: "compress=true"}
 
 type Response struct {
@@ -41,7 +44,7 @@ type OKEXArgs struct {
 }
 
 type OKExScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	run          bool
 	shutdown     chan nothing
@@ -71,30 +74,25 @@ func NewOKExScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *OK
 		db:           relDB,
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_OKExSocketURL, nil)
-	if err != nil {
-		log.Error("dial:", err)
-	}
-
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{
+		URL: _OKExSocketURL,
+		// OKEx closes connections without messages for 30 seconds.
+		PingInterval: okexPingInterval,
+		PingText:     "ping",
+		OnGap:        recordWSGaps(relDB, s.pairs),
+	})
 	if scrape {
 		go s.mainLoop()
 	}
 	return s
 }
 
-// Useful to reconnect to ws when the connection is down
-func (s *OKExScraper) reconnectToWS() {
-
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(_OKExSocketURL, nil)
-
-	if err != nil {
-		log.Error("dial:", err)
+// pairs returns the foreign names of all scraped pairs.
+func (s *OKExScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
 	}
-
-	s.wsClient = SwConn
+	return
 }
 
 type OKEXMarket struct {
@@ -127,39 +125,6 @@ type AllOKEXMarketResponse struct {
 	Msg  string       `json:"msg"`
 }
 
-// Subscribe again to all channels
-func (s *OKExScraper) subscribeToALL() {
-
-	var (
-		resp     AllOKEXMarketResponse
-		allPairs []OKEXArgs
-	)
-
-	b, _, err := utils.GetRequest("https://aws.okex.com/api/v5/public/instruments?instType=SPOT")
-	if err != nil {
-		log.Errorln("Error getting OKex market", err)
-	}
-
-	err = json.Unmarshal(b, &resp)
-	if err != nil {
-		log.Errorln("Error Unmarshalling OKex market json", err)
-	}
-
-	for _, v := range resp.Data {
-		allPairs = append(allPairs, OKEXArgs{Channel: "trades", InstID: v.InstID})
-	}
-
-	a := &Subscribe{
-		OP:   "subscribe",
-		Args: allPairs,
-	}
-
-	if err := s.wsClient.WriteJSON(a); err != nil {
-		log.Errorln(err.Error())
-	}
-
-}
-
 type OKEXWSResponse struct {
 	Arg struct {
 		Channel string `json:"channel"`
@@ -183,12 +148,15 @@ func (s *OKExScraper) mainLoop() {
 		var message OKEXWSResponse
 		messageType, messageTemp, err := s.wsClient.ReadMessage()
 		if err != nil {
-			log.Warning("reconnect the scraping to ws, ", err, ":", message)
-			s.reconnectToWS()
-			s.subscribeToALL()
+			// The session reconnects and resubscribes by itself, so errors only occur once it is closed.
+			log.Warning("read from ws: ", err)
+			break
 		} else {
 			switch messageType {
 			case ws.TextMessage:
+				if string(messageTemp) == "pong" {
+					continue
+				}
 				// no need uncompressed
 				err := json.Unmarshal(messageTemp, &message)
 				if err != nil {
@@ -318,15 +286,13 @@ func (s *OKExScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 
 	s.pairScrapers[pair.ForeignName] = ps
 
-	//a := &Subscribe{
-	//	OP:      "addChannel",
-	//	Args: "ok_sub_spot_" + strings.ToLower(pair.ForeignName) + "_deals",
-	//}
-	//
-	//subByteString := `{"channel":` + `"` + a.Channel + `"` + `,"event":` + `"` + a.Event + `"}`
-	//if err := s.wsClient.WriteMessage(ws.TextMessage, []byte(subByteString)); err != nil {
-	//	fmt.Println(err.Error())
-	//}
+	a := &Subscribe{
+		OP:   "subscribe",
+		Args: []OKEXArgs{{Channel: "trades", InstID: pair.ForeignName}},
+	}
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
+		log.Errorln(err.Error())
+	}
 
 	return ps, nil
 }
@@ -425,6 +391,18 @@ func (ps *OKExPairScraper) Close() error {
 	return nil
 }
 
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (ps *OKExPairScraper) Unsubscribe() error {
+	unsubscribe := &Subscribe{
+		OP:   "unsubscribe",
+		Args: []OKEXArgs{{Channel: "trades", InstID: ps.pair.ForeignName}},
+	}
+	if err := ps.parent.wsClient.Unsubscribe(ps.pair.ForeignName, unsubscribe); err != nil {
+		return err
+	}
+	return ps.Close()
+}
+
 // Channel returns a channel that can be used to receive trades
 func (s *OKExScraper) Channel() chan *dia.Trade {
 	return s.chanTrades
@@ -2,16 +2,16 @@ package scrapers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/url"
 	"strings"
 	"sync"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 )
 
@@ -30,7 +30,7 @@ type AllDeribitOptionsScrapers struct {
 	accessKey         string
 	accessSecret      string
 	owg               *sync.WaitGroup
-	WsConnection      *websocket.Conn
+	WsConnection      *wsHelper.WSSession
 	refreshToken      string
 	RefreshTokenEvery int16
 }
@@ -63,14 +63,8 @@ func NewAllDeribitOptionsScrapers(owg *sync.WaitGroup, markets []string, accessK
 	if err != nil {
 		return result
 	}
-	u := url.URL{Scheme: "wss", Host: "www.deribit.com", Path: "/ws/api/v2/"}
-	log.Debugf("connecting to [%s]", u.String())
-	ws, _, err := websocket.DefaultDialer.Dial(u.String(), nil)
-	if err != nil {
-		log.Errorf("could not dial the websocket: %s", err)
-		time.Sleep(time.Duration(retryIn) * time.Second)
-		return result
-	}
+	log.Debugf("connecting to [%s]", deribitWSEndpoint)
+	ws := wsHelper.NewWSSession("Deribit", wsHelper.WSSessionConfig{URL: deribitWSEndpoint})
 	result.WsConnection = ws
 	result.collectMetaEvery = 6 // hours
 	for _, market := range markets {
@@ -86,7 +80,7 @@ func NewAllDeribitOptionsScrapers(owg *sync.WaitGroup, markets []string, accessK
 }
 
 // NewDeribitOptionsScraper - returns an instance of an options scraper.
-func NewDeribitOptionsScraper(ds *models.DB, owg *sync.WaitGroup, market string, accessKey string, accessSecret string, ws *websocket.Conn) DeribitOptionsScraper {
+func NewDeribitOptionsScraper(ds *models.DB, owg *sync.WaitGroup, market string, accessKey string, accessSecret string, ws *wsHelper.WSSession) DeribitOptionsScraper {
 	wg := sync.WaitGroup{}
 	logger := zap.NewExample().Sugar()
 	optionsScraper := DeribitOptionsScraper{}
@@ -117,7 +111,7 @@ func NewDeribitOptionsScraper(ds *models.DB, owg *sync.WaitGroup, market string,
 	return optionsScraper
 }
 
-func (s *AllDeribitOptionsScrapers) send(message *map[string]interface{}, websocketConn *websocket.Conn) error {
+func (s *AllDeribitOptionsScrapers) send(message *map[string]interface{}, websocketConn *wsHelper.WSSession) error {
 	err := websocketConn.WriteJSON(*message)
 	if err != nil {
 		return err
@@ -182,7 +176,9 @@ func (s *AllDeribitOptionsScrapers) ScrapeMarkets() {
 	go s.refreshWsToken()
 	go func() {
 		for {
-			s.handleWsMessage()
+			if err := s.handleWsMessage(); errors.Is(err, wsHelper.ErrWSSessionClosed) {
+				return
+			}
 		}
 	}()
 	for {
@@ -194,11 +190,12 @@ func (s *AllDeribitOptionsScrapers) ScrapeMarkets() {
 	}
 }
 
-func (s *AllDeribitOptionsScrapers) handleWsMessage() {
+// handleWsMessage handles the next message. It only returns an error once the session is closed.
+func (s *AllDeribitOptionsScrapers) handleWsMessage() error {
 	_, message, err := s.WsConnection.ReadMessage() // this code is blocking. that is why we need big sleep time in the refreshToken goroutine
 	if err != nil {
 		log.Errorf("problem reading deribit, err: %s", err)
-		return
+		return err
 	}
 	strMessage := string(message)
 	log.Debugf("received new message: %v", strMessage)
@@ -208,7 +205,7 @@ func (s *AllDeribitOptionsScrapers) handleWsMessage() {
 		err = json.Unmarshal(message, &decodedMsg)
 		if err != nil {
 			log.Errorf("problem unmarshalling the message: %s, err: %s", message, err)
-			return
+			return nil
 		}
 		log.Debugf("obtained a new refresh token, updating '%s'", decodedMsg.Result.RefreshToken)
 		s.refreshToken = decodedMsg.Result.RefreshToken
@@ -217,7 +214,7 @@ func (s *AllDeribitOptionsScrapers) handleWsMessage() {
 		err = json.Unmarshal(message, &decodedMsg)
 		if err != nil {
 			log.Errorf("problem unmarshalling the message: %s, err: %s", message, err)
-			return
+			return nil
 		}
 		if decodedMsg.Error.Message != "" {
 			if decodedMsg.Error.Code == 13009 {
@@ -232,12 +229,12 @@ func (s *AllDeribitOptionsScrapers) handleWsMessage() {
 		err = json.Unmarshal(message, &parsedResult)
 		if err != nil {
 			log.Errorf("problem unmarshalling the message: %s, err: %s", message, err)
-			return
+			return nil
 		}
 		if len(parsedResult.Params.Data.Bids) == 0 ||
 			len(parsedResult.Params.Data.Asks) == 0 {
 			log.Errorf("No bid or ask in message %s", message)
-			return
+			return nil
 		}
 		orderbookEntry := dia.OptionOrderbookDatum{
 			InstrumentName: parsedResult.Params.Data.InstrumentName,
@@ -251,7 +248,7 @@ func (s *AllDeribitOptionsScrapers) handleWsMessage() {
 		err := s.ds.SaveOptionOrderbookDatumInflux(orderbookEntry)
 		if err != nil {
 			log.Errorf("Error writing into influxdb: %s", err)
-			return
+			return nil
 		}
 		log.Debug("Write msg to db: ", orderbookEntry)
 	} else {
@@ -259,13 +256,16 @@ func (s *AllDeribitOptionsScrapers) handleWsMessage() {
 		//log.Debugf("saving new message on [%s]", market)
 		log.Debugf(strMessage)
 	}
+	return nil
 }
 
-// Authenticate - authenticates with your access key and access secret to retrieve the trade details
+// Authenticate - authenticates with your access key and access secret to retrieve the trade details.
+// The authentication is registered as the first subscription, such that it is renewed before all
+// market subscriptions after reconnecting.
 func (s *AllDeribitOptionsScrapers) Authenticate(websocketConnection interface{}) error {
 	switch c := websocketConnection.(type) {
-	case *websocket.Conn:
-		err := s.send(&map[string]interface{}{
+	case *wsHelper.WSSession:
+		return c.Subscribe("auth", map[string]interface{}{
 			"method": "public/auth",
 			"params": &map[string]string{
 				"grant_type":    "client_credentials",
@@ -273,15 +273,14 @@ func (s *AllDeribitOptionsScrapers) Authenticate(websocketConnection interface{}
 				"client_secret": s.accessSecret,
 			},
 			"jsonrpc": "2.0",
-		}, c)
-		return err
+		})
 	default:
-		return fmt.Errorf("unknown connection type, expected gorilla/websocket, got: %T", c)
+		return fmt.Errorf("unknown connection type, expected wsHelper.WSSession, got: %T", c)
 	}
 }
 
 // when we authenticate, we get back a refresh token that we use to keep alive our websocket connection
-func (s *AllDeribitOptionsScrapers) handleRefreshToken(previousToken string, websocketConn *websocket.Conn) (bool, error) {
+func (s *AllDeribitOptionsScrapers) handleRefreshToken(previousToken string, websocketConn *wsHelper.WSSession) (bool, error) {
 	if previousToken == "" {
 		return false, nil
 	}
@@ -10,10 +10,10 @@ import (
 
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 )
 
 var pingPeriod = 60*time.Second*2 - 1
@@ -76,7 +76,7 @@ const (
 )
 
 type QuoineScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 
 	exchangeName string
 
@@ -115,14 +115,7 @@ func NewQuoineScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 		log.Error(err)
 	}
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(LiquidSocketURL, nil)
-
-	if err != nil {
-		println(err.Error())
-	}
-
-	scraper.wsClient = SwConn
+	scraper.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: LiquidSocketURL, OnGap: recordWSGaps(relDB, scraper.pairs)})
 	if err != nil {
 		log.Error("Couldn't obtain Quoine product ids:", err)
 	}
@@ -134,6 +127,14 @@ func NewQuoineScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *
 	return scraper
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (scraper *QuoineScraper) pairs() (pairs []string) {
+	for _, pairScraper := range scraper.pairScrapers {
+		pairs = append(pairs, pairScraper.pair.ForeignName)
+	}
+	return
+}
+
 func (scraper *QuoineScraper) sendPing() {
 	ticker := time.NewTicker(pingPeriod)
 	defer ticker.Stop()
@@ -171,6 +172,9 @@ func (scraper *QuoineScraper) mainLoop() {
 		var message LiquidResponse
 
 		err := scraper.wsClient.ReadJSON(&message)
+		if err == wsHelper.ErrWSSessionClosed {
+			break
+		}
 		if err != nil {
 			log.Errorln("Error reading JSON", err)
 		}
@@ -215,6 +219,7 @@ func (scraper *QuoineScraper) mainLoop() {
 		}
 
 	}
+	close(scraper.shutdownDone)
 }
 
 func (s *QuoineScraper) NormalizePair(pair dia.ExchangePair) (dia.ExchangePair, error) {
@@ -314,7 +319,7 @@ func (scraper *QuoineScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, er
 		Data:  channel,
 	}
 
-	if err := scraper.wsClient.WriteJSON(a); err != nil {
+	if err := scraper.wsClient.Subscribe(channelName, a); err != nil {
 		log.Errorln(err.Error())
 	}
 	scraper.pairScrapers[channelName] = pairScraper
@@ -340,6 +345,9 @@ func (scraper *QuoineScraper) Close() error {
 	}
 
 	close(scraper.shutdown)
+	if err := scraper.wsClient.Close(); err != nil {
+		log.Error(err)
+	}
 	<-scraper.shutdownDone
 	return nil
 }
@@ -371,3 +379,16 @@ func (pairScraper *QuoinePairScraper) Close() error {
 	pairScraper.closed = true
 	return nil
 }
+
+// Unsubscribe unsubscribes the pair on the exchange and closes the PairScraper.
+func (pairScraper *QuoinePairScraper) Unsubscribe() error {
+	channelName := "executions_cash_" + strings.ToLower(pairScraper.pair.ForeignName)
+	unsubscribe := &LiquidSubscribe{
+		Event: "pusher:unsubscribe",
+		Data:  LiquidChannel{Channel: channelName},
+	}
+	if err := pairScraper.parent.wsClient.Unsubscribe(channelName, unsubscribe); err != nil {
+		return err
+	}
+	return pairScraper.Close()
+}
@@ -0,0 +1,205 @@
+package scrapers
+
+import (
+	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+// reorgConfirmations is the number of blocks on top of the block of a swap log after which
+// its trade is emitted. It can be overwritten by the env var <BLOCKCHAIN>_CONFIRMATIONS.
+var reorgConfirmations = map[string]uint64{
+	dia.ETHEREUM:          3,
+	dia.BINANCESMARTCHAIN: 15,
+	dia.POLYGON:           64,
+	dia.FANTOM:            1,
+	dia.AVALANCHE:         1,
+	dia.ARBITRUM:          1,
+}
+
+const (
+	defaultReorgConfirmations = 5
+	// Number of blocks for which emitted trades are kept after their confirmation,
+	// such that deeper reorgs retract the trade as it was emitted.
+	reorgRetentionBlocks  = 128
+	reorgResubscribeDelay = 10 * time.Second
+)
+
+// reorgBuffer holds the trades of on-chain scrapers until their log has enough confirmations.
+// Trades of logs which are removed by a reorg before confirmation are dropped. If a log is removed
+// after its trade was emitted, a retraction of the trade is sent instead.
+type reorgBuffer struct {
+	blockchain string
+	depth      uint64
+	chanTrades chan *dia.Trade
+	shutdown   chan nothing
+
+	mu   sync.Mutex
+	head uint64
+	// pending trades and emitted trades indexed by tx hash and log index
+	pending map[string]bufferedTrade
+	emitted map[string]bufferedTrade
+}
+
+type bufferedTrade struct {
+	trade       *dia.Trade
+	blockNumber uint64
+	index       uint
+}
+
+// newReorgBuffer returns a reorgBuffer sending confirmed trades to @chanTrades until @shutdown is closed.
+// The confirmation depth of @blockchain is counted on the new heads received through @client.
+func newReorgBuffer(blockchain string, client *ethclient.Client, chanTrades chan *dia.Trade, shutdown chan nothing) *reorgBuffer {
+	b := makeReorgBuffer(blockchain, reorgDepth(blockchain), chanTrades, shutdown)
+	log.Infof("emit trades on %s after %d confirmations", blockchain, b.depth)
+	if b.depth > 0 && client != nil {
+		go b.watchHeads(client)
+	}
+	return b
+}
+
+func makeReorgBuffer(blockchain string, depth uint64, chanTrades chan *dia.Trade, shutdown chan nothing) *reorgBuffer {
+	return &reorgBuffer{
+		blockchain: blockchain,
+		depth:      depth,
+		chanTrades: chanTrades,
+		shutdown:   shutdown,
+		pending:    make(map[string]bufferedTrade),
+		emitted:    make(map[string]bufferedTrade),
+	}
+}
+
+// reorgDepth returns the confirmation depth of @blockchain.
+func reorgDepth(blockchain string) uint64 {
+	depth, ok := reorgConfirmations[blockchain]
+	if !ok {
+		depth = defaultReorgConfirmations
+	}
+	if depthString := os.Getenv(strings.ToUpper(blockchain) + "_CONFIRMATIONS"); depthString != "" {
+		d, err := strconv.ParseUint(depthString, 10, 64)
+		if err != nil {
+			log.Errorf("parse confirmations of %s: %v", blockchain, err)
+		} else {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// Add buffers the trade @t of the swap log @vLog. If @vLog is removed, the buffered trade is dropped,
+// or, if it was emitted already, a retraction of the trade is sent.
+func (b *reorgBuffer) Add(t *dia.Trade, vLog types.Log) {
+	key := vLog.TxHash.Hex() + "-" + strconv.FormatUint(uint64(vLog.Index), 10)
+	var trades []*dia.Trade
+
+	b.mu.Lock()
+	if vLog.Removed {
+		if _, ok := b.pending[key]; ok {
+			delete(b.pending, key)
+			log.Infof("drop trade %s on %s: log removed from block %d", t.ForeignTradeID, t.Source, vLog.BlockNumber)
+		} else {
+			retraction := *t
+			if emitted, ok := b.emitted[key]; ok {
+				retraction = *emitted.trade
+				delete(b.emitted, key)
+			}
+			retraction.Retracted = true
+			log.Warnf("retract trade %s on %s: log removed from block %d", t.ForeignTradeID, t.Source, vLog.BlockNumber)
+			trades = append(trades, &retraction)
+		}
+	} else {
+		b.pending[key] = bufferedTrade{trade: t, blockNumber: vLog.BlockNumber, index: vLog.Index}
+		trades = b.release(vLog.BlockNumber)
+	}
+	b.mu.Unlock()
+	b.send(trades)
+}
+
+// setHead emits all trades which are confirmed at block @head.
+func (b *reorgBuffer) setHead(head uint64) {
+	b.mu.Lock()
+	trades := b.release(head)
+	b.mu.Unlock()
+	b.send(trades)
+}
+
+func (b *reorgBuffer) send(trades []*dia.Trade) {
+	for _, trade := range trades {
+		select {
+		case b.chanTrades <- trade:
+		case <-b.shutdown:
+			return
+		}
+	}
+}
+
+// release returns the confirmed trades ordered by block and log index once the chain reached @head.
+// It must be called with b.mu held.
+func (b *reorgBuffer) release(head uint64) []*dia.Trade {
+	if head > b.head {
+		b.head = head
+	}
+	var confirmed []bufferedTrade
+	for key, bt := range b.pending {
+		if bt.blockNumber+b.depth <= b.head {
+			confirmed = append(confirmed, bt)
+			b.emitted[key] = bt
+			delete(b.pending, key)
+		}
+	}
+	for key, bt := range b.emitted {
+		if bt.blockNumber+b.depth+reorgRetentionBlocks < b.head {
+			delete(b.emitted, key)
+		}
+	}
+
+	sort.Slice(confirmed, func(i, j int) bool {
+		if confirmed[i].blockNumber != confirmed[j].blockNumber {
+			return confirmed[i].blockNumber < confirmed[j].blockNumber
+		}
+		return confirmed[i].index < confirmed[j].index
+	})
+	trades := make([]*dia.Trade, len(confirmed))
+	for i := range confirmed {
+		trades[i] = confirmed[i].trade
+	}
+	return trades
+}
+
+// watchHeads updates the head of the buffer on each new block until the buffer is shut down.
+func (b *reorgBuffer) watchHeads(client *ethclient.Client) {
+	for {
+		heads := make(chan *types.Header)
+		sub, err := client.SubscribeNewHead(context.Background(), heads)
+		if err != nil {
+			log.Errorf("subscribe to new heads on %s: %v", b.blockchain, err)
+		} else {
+			subscribed := true
+			for subscribed {
+				select {
+				case header := <-heads:
+					b.setHead(header.Number.Uint64())
+				case err := <-sub.Err():
+					log.Errorf("new heads subscription on %s: %v", b.blockchain, err)
+					subscribed = false
+				case <-b.shutdown:
+					sub.Unsubscribe()
+					return
+				}
+			}
+		}
+		select {
+		case <-time.After(reorgResubscribeDelay):
+		case <-b.shutdown:
+			return
+		}
+	}
+}
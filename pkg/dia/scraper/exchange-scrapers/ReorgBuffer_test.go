@@ -0,0 +1,54 @@
+package scrapers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestReorgBuffer(t *testing.T) {
+	chanTrades := make(chan *dia.Trade, 10)
+	b := makeReorgBuffer(dia.ETHEREUM, 2, chanTrades, nil)
+	t0 := time.Unix(1651406400, 0)
+
+	logA := types.Log{TxHash: common.HexToHash("0xa"), BlockNumber: 10}
+	logB := types.Log{TxHash: common.HexToHash("0xb"), BlockNumber: 11, Index: 1}
+	b.Add(&dia.Trade{ForeignTradeID: "a", Time: t0}, logA)
+	b.Add(&dia.Trade{ForeignTradeID: "b", Time: t0}, logB)
+	if len(chanTrades) != 0 {
+		t.Fatal("expected trades to be buffered until confirmation")
+	}
+
+	b.setHead(12)
+	if len(chanTrades) != 1 {
+		t.Fatalf("expected 1 confirmed trade, got %d", len(chanTrades))
+	}
+	if trade := <-chanTrades; trade.ForeignTradeID != "a" || trade.Retracted {
+		t.Errorf("unexpected confirmed trade %v", trade)
+	}
+
+	// A log removed before confirmation is dropped.
+	logB.Removed = true
+	b.Add(&dia.Trade{ForeignTradeID: "b", Time: t0.Add(time.Minute)}, logB)
+	if len(chanTrades) != 0 {
+		t.Fatal("expected unconfirmed trade to be dropped")
+	}
+
+	// A log removed after confirmation retracts the emitted trade.
+	logA.Removed = true
+	b.Add(&dia.Trade{ForeignTradeID: "a", Time: t0.Add(time.Minute)}, logA)
+	if len(chanTrades) != 1 {
+		t.Fatalf("expected retraction, got %d trades", len(chanTrades))
+	}
+	if trade := <-chanTrades; trade.ForeignTradeID != "a" || !trade.Retracted || !trade.Time.Equal(t0) {
+		t.Errorf("unexpected retraction %v", trade)
+	}
+
+	b.setHead(20)
+	if len(chanTrades) != 0 {
+		t.Errorf("unexpected trade after reorg: %v", <-chanTrades)
+	}
+}
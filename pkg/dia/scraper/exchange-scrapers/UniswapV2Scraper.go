@@ -1,6 +1,7 @@
 package scrapers
 
 import (
+	"bytes"
 	"encoding/json"
 	"errors"
 	"io/ioutil"
@@ -20,70 +21,21 @@ import (
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
 var (
-	exchangeFactoryContractAddress = "0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f"
-	reverseBasetokens              *[]string
-	reverseQuotetokens             *[]string
-	mainBaseAssets                 = []string{
+	reverseBasetokens  *[]string
+	reverseQuotetokens *[]string
+	mainBaseAssets     = []string{
 		"0xdAC17F958D2ee523a2206206994597C13D831ec7",
 	}
 )
 
 const (
-	restDialEth = "http://159.69.120.42:8545/"
-	wsDialEth   = "ws://159.69.120.42:8546/"
-
-	restDialBSC = ""
-	wsDialBSC   = ""
-
-	restDialPolygon = ""
-	wsDialPolygon   = ""
-
-	restDialCelo = ""
-	wsDialCelo   = ""
-
-	restDialFantom = ""
-	wsDialFantom   = ""
-
-	restDialMoonriver = ""
-	wsDialMoonriver   = ""
-
-	restDialAurora = ""
-	wsDialAurora   = ""
-
-	restDialMetis = ""
-	wsDialMetis   = ""
-
-	restDialAvalanche = ""
-	wsDialAvalanche   = ""
-
-	restDialTelos = ""
-	wsDialTelos   = ""
-
-	restDialEvmos = ""
-	wsDialEvmos   = ""
-
-	restDialAstar = ""
-	wsDialAstar   = ""
-
-	uniswapWaitMilliseconds     = "25"
-	sushiswapWaitMilliseconds   = "100"
-	pancakeswapWaitMilliseconds = "200"
-	dfynWaitMilliseconds        = "100"
-	quickswapWaitMilliseconds   = "200"
-	ubeswapWaitMilliseconds     = "200"
-	spookyswapWaitMilliseconds  = "200"
-	solarbeamWaitMilliseconds   = "400"
-	trisolarisWaitMilliseconds  = "200"
-	metisWaitMilliseconds       = "200"
-	moonriverWaitMilliseconds   = "500"
-	avalancheWaitMilliseconds   = "200"
-	telosWaitMilliseconds       = "400"
-	evmosWaitMilliseconds       = "400"
-	astarWaitMilliseconds       = "1000"
+	// Wait time of UniswapV2 forks without wait time in the exchange table.
+	uniswapDefaultWaitMilliseconds = 500
 )
 
 type UniswapToken struct {
@@ -130,93 +82,49 @@ type UniswapScraper struct {
 	waitTime     int
 	// If true, only pairs given in config file are scraped. Default is false.
 	listenByAddress bool
+	// Factory contract and init code hash of the pair contract of the UniswapV2 fork.
+	factoryContractAddress common.Address
+	initCodeHash           common.Hash
+	// holds trades until their swap logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
-// NewUniswapScraper returns a new UniswapScraper for the given pair
+// NewUniswapScraper returns a new UniswapScraper for the UniswapV2 fork @exchange.
+// The scraper is configured entirely by the exchange table: @exchange.Contract is the factory contract,
+// and the RPC endpoints are taken from the chain config of the exchange's blockchain.
+// Pools are scraped by address if a file uniswap/subscribe_pools/<exchange>.json exists.
 func NewUniswapScraper(exchange dia.Exchange, scrape bool) *UniswapScraper {
 	log.Info("NewUniswapScraper: ", exchange.Name)
-	var s *UniswapScraper
-	var listenByAddress bool
-	exchangeFactoryContractAddress = exchange.Contract
-
-	switch exchange.Name {
-	case dia.UniswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialEth, wsDialEth, uniswapWaitMilliseconds)
-	case dia.SushiSwapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialEth, wsDialEth, sushiswapWaitMilliseconds)
-	case dia.PanCakeSwap:
-		listenByAddress = true
-		s = makeUniswapScraper(exchange, listenByAddress, restDialBSC, wsDialBSC, pancakeswapWaitMilliseconds)
-	case dia.DfynNetwork:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialPolygon, wsDialPolygon, dfynWaitMilliseconds)
-	case dia.QuickswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialPolygon, wsDialPolygon, quickswapWaitMilliseconds)
-	case dia.UbeswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialCelo, wsDialCelo, ubeswapWaitMilliseconds)
-	case dia.SpookyswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialFantom, wsDialFantom, spookyswapWaitMilliseconds)
-	case dia.SpiritswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialFantom, wsDialFantom, spookyswapWaitMilliseconds)
-	case dia.SolarbeamExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialMoonriver, wsDialMoonriver, solarbeamWaitMilliseconds)
-	case dia.TrisolarisExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialAurora, wsDialAurora, trisolarisWaitMilliseconds)
-	case dia.NetswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialMetis, wsDialMetis, metisWaitMilliseconds)
-	case dia.SushiSwapExchangePolygon:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialPolygon, wsDialPolygon, metisWaitMilliseconds)
-	case dia.SushiSwapExchangeFantom:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialFantom, wsDialFantom, metisWaitMilliseconds)
-	case dia.HuckleberryExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialMoonriver, wsDialMoonriver, moonriverWaitMilliseconds)
-	case dia.TraderJoeExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialAvalanche, wsDialAvalanche, avalancheWaitMilliseconds)
-	case dia.PangolinExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialAvalanche, wsDialAvalanche, avalancheWaitMilliseconds)
-	case dia.TethysExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialMetis, wsDialMetis, metisWaitMilliseconds)
-	case dia.HermesExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialMetis, wsDialMetis, metisWaitMilliseconds)
-	case dia.OmniDexExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialTelos, wsDialTelos, telosWaitMilliseconds)
-	case dia.DiffusionExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialEvmos, wsDialEvmos, evmosWaitMilliseconds)
-	case dia.ApeswapExchange:
-		listenByAddress = true
-		s = makeUniswapScraper(exchange, listenByAddress, restDialBSC, wsDialBSC, pancakeswapWaitMilliseconds)
-	case dia.BiswapExchange:
-		listenByAddress = true
-		s = makeUniswapScraper(exchange, listenByAddress, restDialBSC, wsDialBSC, pancakeswapWaitMilliseconds)
-	case dia.ArthswapExchange:
-		listenByAddress = false
-		s = makeUniswapScraper(exchange, listenByAddress, restDialAstar, wsDialAstar, astarWaitMilliseconds)
+
+	restDial, wsDial := chainDials(exchange)
+
+	waitMilliseconds := uniswapDefaultWaitMilliseconds
+	if exchange.WaitTime > 0 {
+		waitMilliseconds = exchange.WaitTime
 	}
 
+	_, err := os.Stat(configCollectors.ConfigFileConnectors("uniswap/subscribe_pools/"+exchange.Name, ".json"))
+	listenByAddress := err == nil
+
+	s := makeUniswapScraper(exchange, listenByAddress, restDial, wsDial, strconv.Itoa(waitMilliseconds))
+
 	if scrape {
+		s.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, s.WsClient, s.chanTrades, s.shutdown)
 		go s.mainLoop()
 	}
 	return s
 }
 
+// chainDials returns the RPC endpoints of the blockchain of @exchange from its chain config.
+func chainDials(exchange dia.Exchange) (restDial string, wsDial string) {
+	chainConfig, ok := getChainConfig(exchange.BlockChain.Name)
+	if !ok {
+		log.Warnf("no chain config for blockchain %s of %s", exchange.BlockChain.Name, exchange.Name)
+		return
+	}
+	return chainConfig.RestURL, chainConfig.WSURL
+}
+
 // makeUniswapScraper returns a uniswap scraper as used in NewUniswapScraper.
 func makeUniswapScraper(exchange dia.Exchange, listenByAddress bool, restDial string, wsDial string, waitMilliseconds string) *UniswapScraper {
 	var restClient, wsClient *ethclient.Client
@@ -238,20 +146,22 @@ func makeUniswapScraper(exchange dia.Exchange, listenByAddress bool, restDial st
 	waitTime, err = strconv.Atoi(waitTimeString)
 	if err != nil {
 		log.Error("could not parse wait time: ", err)
-		waitTime = 500
+		waitTime = uniswapDefaultWaitMilliseconds
 	}
 
 	s = &UniswapScraper{
-		WsClient:        wsClient,
-		RestClient:      restClient,
-		shutdown:        make(chan nothing),
-		shutdownDone:    make(chan nothing),
-		pairScrapers:    make(map[string]*UniswapPairScraper),
-		exchangeName:    exchange.Name,
-		error:           nil,
-		chanTrades:      make(chan *dia.Trade),
-		waitTime:        waitTime,
-		listenByAddress: listenByAddress,
+		WsClient:               wsClient,
+		RestClient:             restClient,
+		shutdown:               make(chan nothing),
+		shutdownDone:           make(chan nothing),
+		pairScrapers:           make(map[string]*UniswapPairScraper),
+		exchangeName:           exchange.Name,
+		error:                  nil,
+		chanTrades:             make(chan *dia.Trade),
+		waitTime:               waitTime,
+		listenByAddress:        listenByAddress,
+		factoryContractAddress: common.HexToAddress(exchange.Contract),
+		initCodeHash:           common.HexToHash(exchange.InitCodeHash),
 	}
 	return s
 }
@@ -279,7 +189,7 @@ func (s *UniswapScraper) mainLoop() {
 	if s.listenByAddress {
 
 		// Collect all pair addresses from json file.
-		pairAddresses, err := getAddressesFromConfig("uniswap/subscribe_pools/" + s.exchangeName)
+		pairAddresses, err := getAddressesFromConfig("uniswap/subscribe_pools/"+s.exchangeName, s.PairAddress)
 		if err != nil {
 			log.Error("fetch pool addresses from config file: ", err)
 		}
@@ -399,6 +309,7 @@ func (s *UniswapScraper) ListenToPair(i int, address common.Address, byAddress b
 					ForeignTradeID: swap.ID,
 					Source:         s.exchangeName,
 					VerifiedPair:   true,
+					PoolAddress:    pair.Address.Hex(),
 				}
 
 				// TO DO: Refactor approach for reversing pairs.
@@ -441,7 +352,7 @@ func (s *UniswapScraper) ListenToPair(i int, address common.Address, byAddress b
 					log.Infof("Got trade at time %v - symbol: %s, pair: %s, price: %v, volume:%v", t.Time, t.Symbol, t.Pair, t.Price, t.Volume)
 					// log.Infof("Base token info --- Symbol: %s - Address: %s - Blockchain: %s ", t.BaseToken.Symbol, t.BaseToken.Address, t.BaseToken.Blockchain)
 					// log.Info("----------------")
-					s.chanTrades <- t
+					s.reorgBuffer.Add(t, rawSwap.Raw)
 				}
 			}
 		}
@@ -513,7 +424,9 @@ func getReverseTokensFromConfig(filename string) (*[]string, error) {
 }
 
 // getAddressesFromConfig returns a list of Uniswap pool addresses taken from a config file.
-func getAddressesFromConfig(filename string) (pairAddresses []common.Address, err error) {
+// Pools can be given by the addresses of their tokens instead of their own address. These are
+// resolved through @pairAddress, and skipped if @pairAddress is nil.
+func getAddressesFromConfig(filename string, pairAddress func(tokenA common.Address, tokenB common.Address) (common.Address, error)) (pairAddresses []common.Address, err error) {
 
 	// Load file and read data
 	filehandle := configCollectors.ConfigFileConnectors(filename, ".json")
@@ -537,6 +450,8 @@ func getAddressesFromConfig(filename string) (pairAddresses []common.Address, er
 	type scrapedPair struct {
 		Address     string `json:"Address"`
 		ForeignName string `json:"ForeignName"`
+		Token0      string `json:"Token0"`
+		Token1      string `json:"Token1"`
 	}
 	type scrapedPairList struct {
 		AllPairs []scrapedPair `json:"Pools"`
@@ -549,6 +464,19 @@ func getAddressesFromConfig(filename string) (pairAddresses []common.Address, er
 
 	// Extract addresses
 	for _, token := range allPairs.AllPairs {
+		if token.Address == "" && token.Token0 != "" && token.Token1 != "" {
+			if pairAddress == nil {
+				log.Warnf("skip pool %s given by its tokens", token.ForeignName)
+				continue
+			}
+			address, err := pairAddress(common.HexToAddress(token.Token0), common.HexToAddress(token.Token1))
+			if err != nil {
+				log.Errorf("get address of pool %s: %v", token.ForeignName, err)
+				continue
+			}
+			pairAddresses = append(pairAddresses, address)
+			continue
+		}
 		pairAddresses = append(pairAddresses, common.HexToAddress(token.Address))
 	}
 
@@ -647,7 +575,7 @@ func (s *UniswapScraper) GetAllPairs() ([]UniswapPair, error) {
 	time.Sleep(20 * time.Millisecond)
 	connection := s.RestClient
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), connection)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, connection)
 	if err != nil {
 		log.Error(err)
 	}
@@ -698,7 +626,7 @@ func (up *UniswapPair) normalizeUniPair() {
 // GetPairByID returns the UniswapPair with the integer id @num
 func (s *UniswapScraper) GetPairByID(num int64) (UniswapPair, error) {
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), s.RestClient)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, s.RestClient)
 	if err != nil {
 		log.Error(err)
 		return UniswapPair{}, err
@@ -792,6 +720,37 @@ func (s *UniswapScraper) GetPairByAddress(pairAddress common.Address) (pair Unis
 	return pair, nil
 }
 
+// PairAddress returns the address of the pair contract of @tokenA and @tokenB.
+// It is computed from the init code hash of the exchange if available. Otherwise, the factory contract is queried.
+func (s *UniswapScraper) PairAddress(tokenA common.Address, tokenB common.Address) (common.Address, error) {
+	if s.initCodeHash != (common.Hash{}) {
+		return uniswapV2PairAddress(s.factoryContractAddress, s.initCodeHash, tokenA, tokenB), nil
+	}
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, s.RestClient)
+	if err != nil {
+		return common.Address{}, err
+	}
+	pairAddress, err := contract.GetPair(&bind.CallOpts{}, tokenA, tokenB)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if pairAddress == (common.Address{}) {
+		return common.Address{}, errors.New("pair does not exist")
+	}
+	return pairAddress, nil
+}
+
+// uniswapV2PairAddress returns the CREATE2 address of the pair contract of @tokenA and @tokenB
+// deployed by @factory, where @initCodeHash is the keccak256 hash of the pair's init code.
+func uniswapV2PairAddress(factory common.Address, initCodeHash common.Hash, tokenA common.Address, tokenB common.Address) common.Address {
+	token0, token1 := tokenA, tokenB
+	if bytes.Compare(token0.Bytes(), token1.Bytes()) > 0 {
+		token0, token1 = token1, token0
+	}
+	salt := crypto.Keccak256Hash(token0.Bytes(), token1.Bytes())
+	return crypto.CreateAddress2(factory, salt, initCodeHash.Bytes())
+}
+
 // GetDecimals returns the decimals of the token with address @tokenAddress
 func (s *UniswapScraper) GetDecimals(tokenAddress common.Address) (decimals uint8, err error) {
 
@@ -823,7 +782,7 @@ func (s *UniswapScraper) GetName(tokenAddress common.Address) (name string, err
 func (s *UniswapScraper) getNumPairs() (int, error) {
 
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), s.RestClient)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, s.RestClient)
 	if err != nil {
 		log.Error(err)
 	}
@@ -3,8 +3,10 @@ package scrapers
 import (
 	"context"
 	"errors"
+	"fmt"
 	"math"
 	"math/big"
+	"os"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,6 +15,7 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswap"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/configCollectors"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 	"github.com/ethereum/go-ethereum"
@@ -45,7 +48,8 @@ type UniswapHistoryScraper struct {
 	pairAddresses []common.Address
 	db            *models.RelDB
 	// If true, only pairs given in config file are scraped. Default is false.
-	listenByAddress bool
+	listenByAddress        bool
+	factoryContractAddress common.Address
 }
 
 const (
@@ -56,28 +60,24 @@ const (
 	uniswapHistoryWaitMilliseconds = "1000"
 )
 
-// NewUniswapScraper returns a new UniswapScraper for the given pair
+// NewUniswapHistoryScraper returns a new UniswapHistoryScraper for the UniswapV2 fork @exchange.
+// As NewUniswapScraper, it is configured by the exchange table and the chain config of the exchange's blockchain.
+// Pools are scraped by address if a file uniswap/subscribe_pools/<exchange>History.json exists.
 func NewUniswapHistoryScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *UniswapHistoryScraper {
 	log.Info("NewUniswapHistoryScraper: ", exchange.Name)
-	var s *UniswapHistoryScraper
-	var listenByAddress bool
-	exchangeFactoryContractAddress = exchange.Contract
-
-	switch exchange.Name {
-	case dia.UniswapExchange:
-		listenByAddress = true
-		s = makeUniswapHistoryScraper(exchange, listenByAddress, restDialEth, wsDialEth, uniswapHistoryWaitMilliseconds)
-	case dia.SushiSwapExchange:
-		listenByAddress = false
-		s = makeUniswapHistoryScraper(exchange, listenByAddress, restDialEth, wsDialEth, sushiswapWaitMilliseconds)
-	case dia.PanCakeSwap:
-		listenByAddress = true
-		s = makeUniswapHistoryScraper(exchange, listenByAddress, restDialBSC, wsDialBSC, pancakeswapWaitMilliseconds)
-	case dia.DfynNetwork:
-		listenByAddress = false
-		s = makeUniswapHistoryScraper(exchange, listenByAddress, restDialPolygon, wsDialPolygon, dfynWaitMilliseconds)
+
+	restDial, wsDial := chainDials(exchange)
+
+	waitMilliseconds := uniswapHistoryWaitMilliseconds
+	if exchange.WaitTime > 0 {
+		waitMilliseconds = strconv.Itoa(exchange.WaitTime)
 	}
 
+	_, err := os.Stat(configCollectors.ConfigFileConnectors("uniswap/subscribe_pools/"+exchange.Name+"History", ".json"))
+	listenByAddress := err == nil
+
+	s := makeUniswapHistoryScraper(exchange, scrape, listenByAddress, restDial, wsDial, waitMilliseconds)
+
 	if scrape {
 		go s.mainLoop()
 	}
@@ -85,7 +85,7 @@ func NewUniswapHistoryScraper(exchange dia.Exchange, scrape bool, relDB *models.
 }
 
 // makeUniswapScraper returns a uniswap scraper as used in NewUniswapScraper.
-func makeUniswapHistoryScraper(exchange dia.Exchange, listenByAddress bool, restDial string, wsDial string, waitMilliseconds string) *UniswapHistoryScraper {
+func makeUniswapHistoryScraper(exchange dia.Exchange, scrape bool, listenByAddress bool, restDial string, wsDial string, waitMilliseconds string) *UniswapHistoryScraper {
 	var restClient, wsClient *ethclient.Client
 	var err error
 	var s *UniswapHistoryScraper
@@ -108,30 +108,35 @@ func makeUniswapHistoryScraper(exchange dia.Exchange, listenByAddress bool, rest
 		waitTime = 500
 	}
 
-	startblockstring := utils.Getenv("FIRST_BLOCK", "")
-	startblock, err := strconv.ParseUint(startblockstring, 10, 64)
-	if err != nil {
-		log.Fatal("parse startblock: ", err)
-	}
-	finalblockstring := utils.Getenv("FINAL_BLOCK", "")
-	finalblock, err := strconv.ParseUint(finalblockstring, 10, 64)
-	if err != nil {
-		log.Fatal("parse final block: ", err)
+	// The block range is only needed for scraping. Backfills through FetchTradesPage are given a time range.
+	var startblock, finalblock uint64
+	if scrape {
+		startblockstring := utils.Getenv("FIRST_BLOCK", "")
+		startblock, err = strconv.ParseUint(startblockstring, 10, 64)
+		if err != nil {
+			log.Fatal("parse startblock: ", err)
+		}
+		finalblockstring := utils.Getenv("FINAL_BLOCK", "")
+		finalblock, err = strconv.ParseUint(finalblockstring, 10, 64)
+		if err != nil {
+			log.Fatal("parse final block: ", err)
+		}
 	}
 
 	s = &UniswapHistoryScraper{
-		WsClient:        wsClient,
-		RestClient:      restClient,
-		shutdown:        make(chan nothing),
-		shutdownDone:    make(chan nothing),
-		pairScrapers:    make(map[string]*UniswapHistoryPairScraper),
-		exchangeName:    exchange.Name,
-		error:           nil,
-		chanTrades:      make(chan *dia.Trade),
-		waitTime:        waitTime,
-		listenByAddress: listenByAddress,
-		genesisBlock:    uint64(startblock),
-		finalBlock:      uint64(finalblock),
+		WsClient:               wsClient,
+		RestClient:             restClient,
+		shutdown:               make(chan nothing),
+		shutdownDone:           make(chan nothing),
+		pairScrapers:           make(map[string]*UniswapHistoryPairScraper),
+		exchangeName:           exchange.Name,
+		error:                  nil,
+		chanTrades:             make(chan *dia.Trade),
+		waitTime:               waitTime,
+		listenByAddress:        listenByAddress,
+		genesisBlock:           uint64(startblock),
+		finalBlock:             uint64(finalblock),
+		factoryContractAddress: common.HexToAddress(exchange.Contract),
 	}
 	return s
 }
@@ -142,7 +147,7 @@ func (s *UniswapHistoryScraper) loadPairMap() {
 	if s.listenByAddress {
 
 		// Collect all pair addresses from json file.
-		pairAddresses, err := getAddressesFromConfig("uniswap/subscribe_pools/" + s.exchangeName + "History")
+		pairAddresses, err := getAddressesFromConfig("uniswap/subscribe_pools/"+s.exchangeName+"History", nil)
 		if err != nil {
 			log.Error("fetch pool addresses from config file: ", err)
 		}
@@ -216,9 +221,8 @@ func (s *UniswapHistoryScraper) loadPairMap() {
 	s.pairmap = pairmap
 }
 
-// runs in a goroutine until s is closed
-func (s *UniswapHistoryScraper) mainLoop() {
-
+// loadPairs loads the tokens for which pairs are reversed and all pairs into the pair map.
+func (s *UniswapHistoryScraper) loadPairs() {
 	// Import tokens which appear as base token and we need a quotation for
 	var err error
 	reverseBasetokens, err = getReverseTokensFromConfig("uniswap/reverse_tokens/" + s.exchangeName + "Basetoken")
@@ -227,10 +231,6 @@ func (s *UniswapHistoryScraper) mainLoop() {
 	}
 	log.Info("reverse pairs with following base tokens: ", reverseBasetokens)
 
-	// wait for all pairs have added into s.PairScrapers
-	time.Sleep(4 * time.Second)
-	s.run = true
-
 	// load all pairs into and from pair map.
 	s.loadPairMap()
 	var addresses []common.Address
@@ -245,6 +245,16 @@ func (s *UniswapHistoryScraper) mainLoop() {
 	} else {
 		log.Infof("%d pairs loaded.", len(addresses))
 	}
+}
+
+// runs in a goroutine until s is closed
+func (s *UniswapHistoryScraper) mainLoop() {
+
+	// wait for all pairs have added into s.PairScrapers
+	time.Sleep(4 * time.Second)
+	s.run = true
+
+	s.loadPairs()
 
 	// latestBlock, err := s.RestClient.BlockByNumber(context.Background(), nil)
 	// if err != nil {
@@ -311,7 +321,7 @@ func (s *UniswapHistoryScraper) mainLoop() {
 	// wg.Wait()
 }
 
-func (s *UniswapHistoryScraper) fetchSwaps(startblock uint64, endblock uint64) error {
+func (s *UniswapHistoryScraper) swapTrades(startblock uint64, endblock uint64) (trades []dia.Trade, err error) {
 	log.Infof("get swaps from block %d to block %d.", startblock, endblock)
 	hashSwap := common.HexToHash("0xd78ad95fa46c994b6551d0da85fc275fe613ce37657fb8d5e3d130840159d822")
 	topics := make([][]common.Hash, 1)
@@ -327,7 +337,7 @@ func (s *UniswapHistoryScraper) fetchSwaps(startblock uint64, endblock uint64) e
 	t := time.Now()
 	logs, err := s.RestClient.FilterLogs(context.Background(), config)
 	if err != nil {
-		return err
+		return nil, err
 	}
 	log.Info("time passed for filter logs: ", time.Since(t))
 	for _, logg := range logs {
@@ -401,7 +411,7 @@ func (s *UniswapHistoryScraper) fetchSwaps(startblock uint64, endblock uint64) e
 		}
 		if price > 0 {
 			log.Infof("Got trade at time %v - symbol: %s, pair: %s, price: %v, volume:%v", t.Time, t.Symbol, t.Pair, t.Price, t.Volume)
-			s.chanTrades <- t
+			trades = append(trades, *t)
 		}
 		if price == 0 {
 			log.Info("Got zero trade: ", t)
@@ -410,8 +420,85 @@ func (s *UniswapHistoryScraper) fetchSwaps(startblock uint64, endblock uint64) e
 	}
 
 	log.Info("number of swaps: ", len(logs))
+	return trades, nil
+
+}
+
+// fetchSwaps sends all swaps in the block range [@startblock, @endblock] to the trades channel.
+func (s *UniswapHistoryScraper) fetchSwaps(startblock uint64, endblock uint64) error {
+	trades, err := s.swapTrades(startblock, endblock)
+	if err != nil {
+		return err
+	}
+	for i := range trades {
+		s.chanTrades <- &trades[i]
+	}
 	return nil
+}
 
+// FetchTradesPage returns the swaps of @pair in the time range [@starttime, @endtime) in pages of filterQueryBlockNums blocks.
+// Swaps are fetched from all pools of the pair map and filtered by the pair's foreign name.
+// The cursor consists of the next and the final block of the time range, separated by a colon.
+func (s *UniswapHistoryScraper) FetchTradesPage(pair dia.ExchangePair, starttime time.Time, endtime time.Time, cursor string) ([]dia.Trade, string, bool, error) {
+	if s.pairmap == nil {
+		s.loadPairs()
+	}
+
+	var nextBlock, finalBlock uint64
+	var err error
+	if cursor == "" {
+		nextBlock, err = s.blockAtTime(starttime)
+		if err != nil {
+			return nil, "", false, err
+		}
+		finalBlock, err = s.blockAtTime(endtime)
+		if err != nil {
+			return nil, "", false, err
+		}
+	} else if _, err = fmt.Sscanf(cursor, "%d:%d", &nextBlock, &finalBlock); err != nil {
+		return nil, "", false, fmt.Errorf("parse cursor %s: %v", cursor, err)
+	}
+	if nextBlock >= finalBlock {
+		return nil, "", true, nil
+	}
+
+	endBlock := nextBlock + filterQueryBlockNums
+	if endBlock > finalBlock {
+		endBlock = finalBlock
+	}
+	swaps, err := s.swapTrades(nextBlock, endBlock-1)
+	if err != nil {
+		return nil, "", false, err
+	}
+	var trades []dia.Trade
+	for _, t := range swaps {
+		if t.Pair == pair.ForeignName && !t.Time.Before(starttime) && t.Time.Before(endtime) {
+			trades = append(trades, t)
+		}
+	}
+	return trades, fmt.Sprintf("%d:%d", endBlock, finalBlock), endBlock == finalBlock, nil
+}
+
+// blockAtTime returns the number of the first block with a timestamp not before @t.
+func (s *UniswapHistoryScraper) blockAtTime(t time.Time) (uint64, error) {
+	header, err := s.RestClient.HeaderByNumber(context.Background(), nil)
+	if err != nil {
+		return 0, err
+	}
+	low, high := uint64(0), header.Number.Uint64()+1
+	for low < high {
+		mid := (low + high) / 2
+		header, err = s.RestClient.HeaderByNumber(context.Background(), new(big.Int).SetUint64(mid))
+		if err != nil {
+			return 0, err
+		}
+		if header.Time < uint64(t.Unix()) {
+			low = mid + 1
+		} else {
+			high = mid
+		}
+	}
+	return low, nil
 }
 
 // normalizeUniswapSwap takes a swap as returned by the swap contract's channel and converts it to a UniswapSwap type
@@ -487,7 +574,7 @@ func (s *UniswapHistoryScraper) GetAllPairs() ([]UniswapPair, error) {
 	time.Sleep(20 * time.Millisecond)
 	connection := s.RestClient
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), connection)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, connection)
 	if err != nil {
 		log.Error(err)
 	}
@@ -523,7 +610,7 @@ func (up *UniswapHistoryScraper) NormalizePair(pair dia.ExchangePair) (dia.Excha
 func (s *UniswapHistoryScraper) GetPairByID(num int64) (UniswapPair, error) {
 	log.Info("Get pair ID: ", num)
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), s.RestClient)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, s.RestClient)
 	if err != nil {
 		log.Error(err)
 		return UniswapPair{}, err
@@ -648,7 +735,7 @@ func (s *UniswapHistoryScraper) GetName(tokenAddress common.Address) (name strin
 func (s *UniswapHistoryScraper) getNumPairs() (int, error) {
 
 	var contract *uniswap.IUniswapV2FactoryCaller
-	contract, err := uniswap.NewIUniswapV2FactoryCaller(common.HexToAddress(exchangeFactoryContractAddress), s.RestClient)
+	contract, err := uniswap.NewIUniswapV2FactoryCaller(s.factoryContractAddress, s.RestClient)
 	if err != nil {
 		log.Error(err)
 	}
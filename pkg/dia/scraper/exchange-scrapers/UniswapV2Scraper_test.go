@@ -0,0 +1,23 @@
+package scrapers
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestUniswapV2PairAddress(t *testing.T) {
+	factory := common.HexToAddress("0x5C69bEe701ef814a2B6a3EDD4B1652CB9cc5aA6f")
+	initCodeHash := common.HexToHash("0x96e8ac4277198ff8b6f785478aa9a39f403cb768dd02cbee326c3e7da348845f")
+	usdc := common.HexToAddress("0xA0b86991c6218b36c1d19D4a2e9Eb0cE3606eB48")
+	weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	expected := common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+
+	if address := uniswapV2PairAddress(factory, initCodeHash, usdc, weth); address != expected {
+		t.Errorf("expected USDC-WETH pair %s, got %s", expected.Hex(), address.Hex())
+	}
+	// The pair address does not depend on the order of the tokens.
+	if address := uniswapV2PairAddress(factory, initCodeHash, weth, usdc); address != expected {
+		t.Errorf("expected WETH-USDC pair %s, got %s", expected.Hex(), address.Hex())
+	}
+}
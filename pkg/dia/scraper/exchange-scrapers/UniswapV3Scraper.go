@@ -53,6 +53,8 @@ type UniswapV3Scraper struct {
 	listenByAddress        bool
 	chanTrades             chan *dia.Trade
 	factoryContractAddress common.Address
+	// holds trades until their swap logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 // NewUniswapV3Scraper returns a new UniswapV3Scraper
@@ -70,6 +72,7 @@ func NewUniswapV3Scraper(exchange dia.Exchange, scrape bool) *UniswapV3Scraper {
 	}
 
 	if scrape {
+		s.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, s.WsClient, s.chanTrades, s.shutdown)
 		go s.mainLoop()
 	}
 	return s
@@ -204,6 +207,7 @@ func (s *UniswapV3Scraper) mainLoop() {
 						ForeignTradeID: swap.ID,
 						Source:         s.exchangeName,
 						VerifiedPair:   true,
+						PoolAddress:    pair.Address.Hex(),
 					}
 
 					switch {
@@ -222,7 +226,7 @@ func (s *UniswapV3Scraper) mainLoop() {
 					}
 					if price > 0 {
 						log.Info("Got trade: ", t)
-						s.chanTrades <- t
+						s.reorgBuffer.Add(t, rawSwap.Raw)
 					}
 				}
 			}
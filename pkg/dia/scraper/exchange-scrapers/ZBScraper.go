@@ -9,9 +9,9 @@ import (
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/wsHelper"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
-	ws "github.com/gorilla/websocket"
 )
 
 var ZBSocketURL string = "wss://api.zb.live/websocket"
@@ -35,7 +35,7 @@ type ZBTradeResponse struct {
 }
 
 type ZBScraper struct {
-	wsClient *ws.Conn
+	wsClient *wsHelper.WSSession
 	// signaling channels for session initialization and finishing
 	//initDone     chan nothing
 	shutdown     chan nothing
@@ -67,12 +67,7 @@ func NewZBScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *ZBSc
 
 	ZBWsURL := utils.Getenv("ZB_WS_URL", ZBSocketURL)
 
-	var wsDialer ws.Dialer
-	SwConn, _, err := wsDialer.Dial(ZBWsURL, nil)
-	if err != nil {
-		println(err.Error())
-	}
-	s.wsClient = SwConn
+	s.wsClient = wsHelper.NewWSSession(exchange.Name, wsHelper.WSSessionConfig{URL: ZBWsURL, OnGap: recordWSGaps(relDB, s.pairs)})
 
 	if scrape {
 		go s.mainLoop()
@@ -80,6 +75,14 @@ func NewZBScraper(exchange dia.Exchange, scrape bool, relDB *models.RelDB) *ZBSc
 	return s
 }
 
+// pairs returns the foreign names of all scraped pairs.
+func (s *ZBScraper) pairs() (pairs []string) {
+	for foreignName := range s.pairScrapers {
+		pairs = append(pairs, foreignName)
+	}
+	return
+}
+
 // runs in a goroutine until s is closed
 func (s *ZBScraper) mainLoop() {
 
@@ -209,7 +212,7 @@ func (s *ZBScraper) ScrapePair(pair dia.ExchangePair) (PairScraper, error) {
 		Channel: pair.ForeignName + "_trades",
 	}
 
-	if err := s.wsClient.WriteJSON(a); err != nil {
+	if err := s.wsClient.Subscribe(pair.ForeignName, a); err != nil {
 		fmt.Println(err.Error())
 	}
 
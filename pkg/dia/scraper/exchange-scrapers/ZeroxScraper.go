@@ -54,6 +54,8 @@ type ZeroxScraper struct {
 	RestClient  *ethclient.Client
 	resubscribe chan nothing
 	tokens      map[string]dia.Asset
+	// holds trades until their fill logs are confirmed
+	reorgBuffer *reorgBuffer
 }
 
 func NewZeroxScraper(exchange dia.Exchange, scrape bool) *ZeroxScraper {
@@ -82,6 +84,7 @@ func NewZeroxScraper(exchange dia.Exchange, scrape bool) *ZeroxScraper {
 	scraper.loadTokens()
 
 	if scrape {
+		scraper.reorgBuffer = newReorgBuffer(exchange.BlockChain.Name, wsClient, scraper.chanTrades, scraper.shutdown)
 		go scraper.mainLoop()
 	}
 	return scraper
@@ -222,8 +225,8 @@ func (scraper *ZeroxScraper) subscribeToTrades() error {
 	return err
 }
 
-func (scraper *ZeroxScraper) processTrade(trade *zerox.ZeroxFill) {
-	token0, token1, symbol, foreignName, volume, price, err := scraper.getFillDataZerox(trade)
+func (scraper *ZeroxScraper) processTrade(fill *zerox.ZeroxFill) {
+	token0, token1, symbol, foreignName, volume, price, err := scraper.getFillDataZerox(fill)
 	timestamp := time.Now().Unix()
 	if err != nil {
 		log.Error(err)
@@ -236,13 +239,13 @@ func (scraper *ZeroxScraper) processTrade(trade *zerox.ZeroxFill) {
 				Price:          price,
 				Volume:         volume,
 				Time:           time.Unix(timestamp, 0),
-				ForeignTradeID: "",
+				ForeignTradeID: fill.Raw.TxHash.Hex() + "-" + fmt.Sprint(fill.Raw.Index),
 				Source:         scraper.exchangeName,
 				BaseToken:      token1,
 				QuoteToken:     token0,
 				VerifiedPair:   true,
 			}
-			pairScraper.parent.chanTrades <- trade
+			scraper.reorgBuffer.Add(trade, fill.Raw)
 			fmt.Println("got trade: ", trade)
 		}
 	}
@@ -7,12 +7,16 @@ import (
 	"strings"
 	"time"
 
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 
 	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
 	balancervault "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/balancerv2/vault"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 
 	"github.com/diadata-org/diadata/pkg/dia"
@@ -25,6 +29,8 @@ const (
 
 type BalancerV2Scraper struct {
 	RestClient             *ethclient.Client
+	WsClient               *ethclient.Client
+	relDB                  models.RelDatastore
 	poolChannel            chan dia.Pool
 	doneChannel            chan bool
 	blockchain             string
@@ -34,24 +40,32 @@ type BalancerV2Scraper struct {
 	cachedAssets           map[string]dia.Asset
 }
 
-// NewBalancerV2Scraper returns a Balancer V2 scraper
-func NewBalancerV2Scraper(exchange dia.Exchange) *BalancerV2Scraper {
+// NewBalancerV2Scraper returns a Balancer V2 scraper. After an initial snapshot, pools are updated
+// on each Swap and PoolBalanceChanged event of the vault.
+func NewBalancerV2Scraper(exchange dia.Exchange, relDB models.RelDatastore) *BalancerV2Scraper {
 	var (
 		restClient  *ethclient.Client
+		wsClient    *ethclient.Client
 		err         error
 		poolChannel = make(chan dia.Pool)
 		doneChannel = make(chan bool)
 		scraper     *BalancerV2Scraper
 	)
 
-	log.Infof("Init rest client for %s.", exchange.BlockChain.Name)
+	log.Infof("Init rest and ws client for %s.", exchange.BlockChain.Name)
 	restClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_REST", balancerV2RestDial))
 	if err != nil {
 		log.Fatal("init rest client: ", err)
 	}
+	wsClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_WS", ""))
+	if err != nil {
+		log.Fatal("init ws client: ", err)
+	}
 
 	scraper = &BalancerV2Scraper{
 		RestClient:    restClient,
+		WsClient:      wsClient,
+		relDB:         relDB,
 		poolChannel:   poolChannel,
 		doneChannel:   doneChannel,
 		blockchain:    exchange.BlockChain.Name,
@@ -68,12 +82,43 @@ func NewBalancerV2Scraper(exchange dia.Exchange) *BalancerV2Scraper {
 	}
 
 	go func() {
-		scraper.fetchPools()
+		scraper.watchPools()
 	}()
 
 	return scraper
 }
 
+// watchPools takes a snapshot of all pools if the scraper runs for the first time and afterwards
+// sends an update of a pool into the pool channel whenever its balances change in the vault.
+func (scraper *BalancerV2Scraper) watchPools() {
+	vaultABI, err := abi.JSON(strings.NewReader(balancervault.BalancerVaultABI))
+	if err != nil {
+		log.Fatal("parse vault abi: ", err)
+	}
+	query := ethereum.FilterQuery{
+		Addresses: []common.Address{common.HexToAddress(scraper.vaultContract)},
+		Topics:    [][]common.Hash{{vaultABI.Events["Swap"].ID, vaultABI.Events["PoolBalanceChanged"].ID}},
+	}
+	watcher := newPoolWatcher(scraper.exchangeName, scraper.RestClient, scraper.WsClient, scraper.relDB, query, balancerV2FilterPageSize, scraper.handleLog)
+	if err := watcher.run(scraper.fetchPools); err != nil {
+		log.Fatal("watch pools: ", err)
+	}
+}
+
+// handleLog sends the pool whose balances are changed by the vault event @vLog into the pool channel.
+// The pool id is the first indexed argument of both Swap and PoolBalanceChanged.
+func (scraper *BalancerV2Scraper) handleLog(vLog types.Log) {
+	if len(vLog.Topics) < 2 {
+		return
+	}
+	pool, err := scraper.getPool(vLog.Topics[1])
+	if err != nil {
+		log.Errorf("get pool %s: %v", vLog.Topics[1].Hex(), err)
+		return
+	}
+	scraper.poolChannel <- pool
+}
+
 // fetchPools collects all available pools and sends them into the pool channel.
 func (scraper *BalancerV2Scraper) fetchPools() {
 	events, err := scraper.allRegisteredPools()
@@ -81,27 +126,31 @@ func (scraper *BalancerV2Scraper) fetchPools() {
 		log.Fatal("fetch all registered pools: ", err)
 	}
 
-	caller, err := balancervault.NewBalancerVaultCaller(common.HexToAddress(scraper.vaultContract), scraper.RestClient)
-	if err != nil {
-		log.Fatal("make balancer vault caller: ", err)
-	}
-
 	for _, evt := range events {
-		poolTokens, err := caller.GetPoolTokens(&bind.CallOpts{}, evt.PoolId)
+		pool, err := scraper.getPool(evt.PoolId)
 		if err != nil {
 			log.Warn("get pool tokens: ", err)
 		}
-		assetvolumes := scraper.extractPoolInfo(poolTokens)
-		pool := dia.Pool{
-			Exchange:     dia.Exchange{Name: scraper.exchangeName},
-			Blockchain:   dia.BlockChain{Name: scraper.blockchain},
-			Address:      evt.PoolAddress.String(),
-			Assetvolumes: assetvolumes,
-			Time:         time.Now(),
-		}
 		scraper.poolChannel <- pool
 	}
-	scraper.doneChannel <- true
+}
+
+// getPool returns the pool with @poolID and its current balances in the vault.
+func (scraper *BalancerV2Scraper) getPool(poolID [32]byte) (dia.Pool, error) {
+	caller, err := balancervault.NewBalancerVaultCaller(common.HexToAddress(scraper.vaultContract), scraper.RestClient)
+	if err != nil {
+		return dia.Pool{}, err
+	}
+	poolTokens, err := caller.GetPoolTokens(&bind.CallOpts{}, poolID)
+	// The first 20 bytes of a pool id are the address of the pool.
+	pool := dia.Pool{
+		Exchange:     dia.Exchange{Name: scraper.exchangeName},
+		Blockchain:   dia.BlockChain{Name: scraper.blockchain},
+		Address:      common.BytesToAddress(poolID[:20]).String(),
+		Assetvolumes: scraper.extractPoolInfo(poolTokens),
+		Time:         time.Now(),
+	}
+	return pool, err
 }
 
 // allRegisteredPools returns a slice of all pool creation events.
@@ -1,6 +1,7 @@
 package liquidityscrapers
 
 import (
+	"fmt"
 	"math"
 	"math/big"
 	"strings"
@@ -9,11 +10,15 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi"
 	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi/token"
 	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefimeta"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -21,113 +26,172 @@ const (
 	curveFiMetaPoolsFactory = "0xB9fC157394Af804a3578134A6585C0dc9cc990d4"
 	curveFiCryptoswapPools  = "0x8F942C20D02bEfc377D41445793068908E2250D0"
 	curveRestDial           = ""
+	curveFilterPageSize     = 2000
 )
 
 type CurveFIScraper struct {
 	RestClient   *ethclient.Client
+	WsClient     *ethclient.Client
+	relDB        models.RelDatastore
 	poolChannel  chan dia.Pool
 	doneChannel  chan bool
 	blockchain   string
 	exchangeName string
 	poolAddrs    []string
+	// factory or registry of each pool
+	pools map[common.Address]common.Address
 }
 
-func NewCurveFIScraper(exchange dia.Exchange) *CurveFIScraper {
+// NewCurveFIScraper returns a Curve scraper. After an initial snapshot, pools are updated on each
+// of their events changing the balances, i.e. exchanges and liquidity additions or removals.
+func NewCurveFIScraper(exchange dia.Exchange, relDB models.RelDatastore) *CurveFIScraper {
 	var (
 		restClient  *ethclient.Client
+		wsClient    *ethclient.Client
 		err         error
 		poolChannel = make(chan dia.Pool)
 		doneChannel = make(chan bool)
 		scraper     *CurveFIScraper
 	)
 
-	log.Infof("Init rest client for %s.", exchange.BlockChain.Name)
+	log.Infof("Init rest and ws client for %s.", exchange.BlockChain.Name)
 	restClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_REST", curveRestDial))
 	if err != nil {
 		log.Fatal("init rest client: ", err)
 	}
+	wsClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_WS", ""))
+	if err != nil {
+		log.Fatal("init ws client: ", err)
+	}
 
 	scraper = &CurveFIScraper{
 		RestClient:   restClient,
+		WsClient:     wsClient,
+		relDB:        relDB,
 		poolChannel:  poolChannel,
 		doneChannel:  doneChannel,
 		blockchain:   exchange.BlockChain.Name,
 		exchangeName: exchange.Name,
 		poolAddrs:    []string{curveFiMetaPoolsFactory, curveFiCryptoswapPools, exchange.Contract},
+		pools:        make(map[common.Address]common.Address),
 	}
 
 	go func() {
-		for _, address := range scraper.poolAddrs {
-			scraper.fetchPools(common.HexToAddress(address))
-		}
-		scraper.doneChannel <- true
+		scraper.watchPools()
 	}()
 
 	return scraper
 }
 
-// fetchPools collects all available pools and sends them into the pool channel.
-func (scraper *CurveFIScraper) fetchPools(factoryAddress common.Address) {
-	var pool dia.Pool
-
-	if factoryAddress == common.HexToAddress(curveFiMetaPoolsFactory) || factoryAddress == common.HexToAddress("0xF18056Bbd320E96A48e3Fbf8bC061322531aac99") {
-		log.Info("load meta pools...")
-		var (
-			contract  *curvefimeta.CurvefimetaCaller
-			poolCount *big.Int
-			err       error
+// curveLiquidityEvents returns the topics of all pool events which change the pool balances.
+// Signatures differ between plain, meta and crypto pools and with the number of coins.
+func curveLiquidityEvents() (topics []common.Hash) {
+	signatures := []string{
+		"TokenExchange(address,int128,uint256,int128,uint256)",
+		"TokenExchange(address,uint256,uint256,uint256,uint256)",
+		"TokenExchangeUnderlying(address,int128,uint256,int128,uint256)",
+		"RemoveLiquidityOne(address,uint256,uint256)",
+		"RemoveLiquidityOne(address,uint256,uint256,uint256)",
+		"RemoveLiquidityOne(address,uint256,uint256,uint256,uint256)",
+	}
+	for n := 2; n <= 4; n++ {
+		signatures = append(signatures,
+			fmt.Sprintf("AddLiquidity(address,uint256[%d],uint256[%d],uint256,uint256)", n, n),
+			fmt.Sprintf("AddLiquidity(address,uint256[%d],uint256,uint256)", n),
+			fmt.Sprintf("RemoveLiquidity(address,uint256[%d],uint256[%d],uint256)", n, n),
+			fmt.Sprintf("RemoveLiquidity(address,uint256[%d],uint256)", n),
+			fmt.Sprintf("RemoveLiquidityImbalance(address,uint256[%d],uint256[%d],uint256,uint256)", n, n),
 		)
+	}
+	for _, signature := range signatures {
+		topics = append(topics, crypto.Keccak256Hash([]byte(signature)))
+	}
+	return
+}
+
+// watchPools takes a snapshot of all pools if the scraper runs for the first time and afterwards
+// sends an update of a pool into the pool channel on each event changing its balances.
+// Pools added after the start of the scraper are watched from the next start on.
+func (scraper *CurveFIScraper) watchPools() {
+	var addresses []common.Address
+	for _, address := range scraper.poolAddrs {
+		factoryAddress := common.HexToAddress(address)
+		for _, poolAddress := range scraper.poolAddresses(factoryAddress) {
+			if _, ok := scraper.pools[poolAddress]; !ok {
+				addresses = append(addresses, poolAddress)
+			}
+			scraper.pools[poolAddress] = factoryAddress
+		}
+	}
+	log.Infof("watch %d pools", len(addresses))
+
+	query := ethereum.FilterQuery{
+		Addresses: addresses,
+		Topics:    [][]common.Hash{curveLiquidityEvents()},
+	}
+	watcher := newPoolWatcher(scraper.exchangeName, scraper.RestClient, scraper.WsClient, scraper.relDB, query, curveFilterPageSize, scraper.handleLog)
+	if err := watcher.run(scraper.fetchPools); err != nil {
+		log.Fatal("watch pools: ", err)
+	}
+}
+
+// handleLog sends the pool which emitted @vLog with its current balances into the pool channel.
+func (scraper *CurveFIScraper) handleLog(vLog types.Log) {
+	factoryAddress, ok := scraper.pools[vLog.Address]
+	if !ok {
+		return
+	}
+	scraper.poolChannel <- scraper.loadPoolData(vLog.Address, factoryAddress)
+}
+
+// poolAddresses returns the addresses of all pools listed by the factory or registry at @factoryAddress.
+func (scraper *CurveFIScraper) poolAddresses(factoryAddress common.Address) (addresses []common.Address) {
+	var (
+		poolCount *big.Int
+		poolList  func(opts *bind.CallOpts, arg0 *big.Int) (common.Address, error)
+	)
 
-		contract, err = curvefimeta.NewCurvefimetaCaller(factoryAddress, scraper.RestClient)
+	if factoryAddress == common.HexToAddress(curveFiMetaPoolsFactory) || factoryAddress == common.HexToAddress("0xF18056Bbd320E96A48e3Fbf8bC061322531aac99") {
+		contract, err := curvefimeta.NewCurvefimetaCaller(factoryAddress, scraper.RestClient)
 		if err != nil {
 			log.Error("NewCurvefiCaller: ", err)
+			return
 		}
 		poolCount, err = contract.PoolCount(&bind.CallOpts{})
 		if err != nil {
 			log.Error("PoolCount: ", err)
+			return
 		}
-
-		for i := 0; i < int(poolCount.Int64()); i++ {
-			poolAddress, err := contract.PoolList(&bind.CallOpts{}, big.NewInt(int64(i)))
-			if err != nil {
-				log.Error("PoolList: ", err)
-			}
-
-			pool = scraper.loadPoolData(poolAddress, factoryAddress)
-			if err != nil {
-				log.Info("load pool data: ", err)
-			}
-			scraper.poolChannel <- pool
-		}
+		poolList = contract.PoolList
 	} else {
-		log.Info("load factory pools...")
-		var (
-			contract  *curvefi.CurvefiCaller
-			poolCount *big.Int
-			err       error
-		)
-
-		contract, err = curvefi.NewCurvefiCaller(factoryAddress, scraper.RestClient)
+		contract, err := curvefi.NewCurvefiCaller(factoryAddress, scraper.RestClient)
 		if err != nil {
 			log.Error("NewCurvefiCaller: ", err)
+			return
 		}
 		poolCount, err = contract.PoolCount(&bind.CallOpts{})
 		if err != nil {
 			log.Error("PoolCount: ", err)
+			return
 		}
+		poolList = contract.PoolList
+	}
 
-		for i := 0; i < int(poolCount.Int64()); i++ {
-			poolAddress, err := contract.PoolList(&bind.CallOpts{}, big.NewInt(int64(i)))
-			if err != nil {
-				log.Error("PoolList: ", err)
-			}
-
-			pool = scraper.loadPoolData(poolAddress, factoryAddress)
-			if err != nil {
-				log.Info("load pool data: ", err)
-			}
-			scraper.poolChannel <- pool
+	for i := 0; i < int(poolCount.Int64()); i++ {
+		poolAddress, err := poolList(&bind.CallOpts{}, big.NewInt(int64(i)))
+		if err != nil {
+			log.Error("PoolList: ", err)
+			continue
 		}
+		addresses = append(addresses, poolAddress)
+	}
+	return
+}
+
+// fetchPools sends all pools with their current balances into the pool channel.
+func (scraper *CurveFIScraper) fetchPools() {
+	for poolAddress, factoryAddress := range scraper.pools {
+		scraper.poolChannel <- scraper.loadPoolData(poolAddress, factoryAddress)
 	}
 }
 
@@ -0,0 +1,163 @@
+package liquidityscrapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"time"
+
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/jackc/pgx/v4"
+)
+
+const poolWatcherRetryDelay = 10 * time.Second
+
+// errCheckpoint is returned if the checkpoint of a pool watcher cannot be stored.
+var errCheckpoint = errors.New("store checkpoint")
+
+// LiquidityScraperState is the checkpoint of an event-driven liquidity scraper.
+// It is stored through RelDB.SetScraperState after each processed block range.
+type LiquidityScraperState struct {
+	LastBlock uint64 `json:"last_block"`
+}
+
+// poolWatcher keeps the pools of an exchange up to date by processing the logs of events which change
+// the pools' liquidity. Logs removed by a reorg are skipped, as the liquidity is updated again with the next
+// event of the same pool on the canonical chain.
+type poolWatcher struct {
+	name       string
+	restClient *ethclient.Client
+	wsClient   *ethclient.Client
+	relDB      models.RelDatastore
+	query      ethereum.FilterQuery
+	// Number of blocks per log filter request when catching up with the chain.
+	pageSize  uint64
+	handleLog func(types.Log)
+	lastBlock uint64
+}
+
+func liquidityScraperName(exchange string) string {
+	return "liquidity_" + exchange
+}
+
+// newPoolWatcher returns a watcher passing all logs matching @query to @handleLog.
+func newPoolWatcher(exchange string, restClient *ethclient.Client, wsClient *ethclient.Client, relDB models.RelDatastore, query ethereum.FilterQuery, pageSize uint64, handleLog func(types.Log)) *poolWatcher {
+	return &poolWatcher{
+		name:       liquidityScraperName(exchange),
+		restClient: restClient,
+		wsClient:   wsClient,
+		relDB:      relDB,
+		query:      query,
+		pageSize:   pageSize,
+		handleLog:  handleLog,
+	}
+}
+
+// run takes an initial snapshot of all pools through @snapshot, unless a checkpoint of a previous run exists.
+// Afterwards, logs are processed from the block of the snapshot or the checkpoint on, such that changes during
+// a long snapshot are not lost. run only returns if the checkpoint cannot be read or written.
+func (w *poolWatcher) run(snapshot func()) error {
+	var state LiquidityScraperState
+	err := w.relDB.GetScraperState(context.Background(), w.name, &state)
+	switch {
+	case err == nil:
+		w.lastBlock = state.LastBlock
+		log.Infof("resume %s after block %d", w.name, w.lastBlock)
+	case errors.Is(err, pgx.ErrNoRows):
+		head, err := w.restClient.BlockNumber(context.Background())
+		if err != nil {
+			return err
+		}
+		snapshot()
+		if err := w.setLastBlock(head); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	for {
+		err := w.watch()
+		if errors.Is(err, errCheckpoint) {
+			return err
+		}
+		log.Errorf("watch logs of %s: %v. retry in %v", w.name, err, poolWatcherRetryDelay)
+		time.Sleep(poolWatcherRetryDelay)
+	}
+}
+
+// watch subscribes to new logs, processes all logs since the last checkpoint and then the logs of the subscription.
+// The subscription is set up first, such that no logs are missed in between.
+func (w *poolWatcher) watch() error {
+	logs := make(chan types.Log)
+	sub, err := w.wsClient.SubscribeFilterLogs(context.Background(), w.query, logs)
+	if err != nil {
+		return err
+	}
+	defer sub.Unsubscribe()
+
+	if err := w.catchUp(); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case vLog := <-logs:
+			if vLog.Removed || vLog.BlockNumber <= w.lastBlock {
+				continue
+			}
+			// Logs arrive ordered by block, so all previous blocks are processed.
+			if vLog.BlockNumber-1 > w.lastBlock {
+				if err := w.setLastBlock(vLog.BlockNumber - 1); err != nil {
+					return err
+				}
+			}
+			w.handleLog(vLog)
+		case err := <-sub.Err():
+			return err
+		}
+	}
+}
+
+// catchUp processes the logs from the block after the checkpoint up to the current block.
+func (w *poolWatcher) catchUp() error {
+	head, err := w.restClient.BlockNumber(context.Background())
+	if err != nil {
+		return err
+	}
+	for start := w.lastBlock + 1; start <= head; start += w.pageSize {
+		end := start + w.pageSize - 1
+		if end > head {
+			end = head
+		}
+		query := w.query
+		query.FromBlock = new(big.Int).SetUint64(start)
+		query.ToBlock = new(big.Int).SetUint64(end)
+		logs, err := w.restClient.FilterLogs(context.Background(), query)
+		if err != nil {
+			return err
+		}
+		log.Infof("%s: process %d logs in blocks %d - %d", w.name, len(logs), start, end)
+		for _, vLog := range logs {
+			if !vLog.Removed {
+				w.handleLog(vLog)
+			}
+		}
+		if err := w.setLastBlock(end); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (w *poolWatcher) setLastBlock(block uint64) error {
+	if err := w.relDB.SetScraperState(context.Background(), w.name, &LiquidityScraperState{LastBlock: block}); err != nil {
+		return fmt.Errorf("%w: %v", errCheckpoint, err)
+	}
+	w.lastBlock = block
+	return nil
+}
@@ -0,0 +1,395 @@
+package liquidityscrapers
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/big"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	balancervault "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/balancerv2/vault"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/curvefi/token"
+	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswap"
+	models "github.com/diadata-org/diadata/pkg/model"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// testChain serves the eth API needed by the liquidity scrapers from synthetic logs and mocked contract calls.
+type testChain struct {
+	mu    sync.Mutex
+	head  uint64
+	logs  []types.Log
+	calls map[string][]byte
+	// Logs sent on newLogs are pushed to the logs subscription.
+	newLogs chan types.Log
+}
+
+type testFilterArgs struct {
+	FromBlock string           `json:"fromBlock"`
+	ToBlock   string           `json:"toBlock"`
+	Addresses []common.Address `json:"address"`
+	Topics    [][]common.Hash  `json:"topics"`
+}
+
+type testCallArgs struct {
+	To   common.Address `json:"to"`
+	Data hexutil.Bytes  `json:"data"`
+}
+
+// newTestChain returns the chain and a client connected to it in-process.
+func newTestChain(t *testing.T, head uint64, logs []types.Log) (*testChain, *ethclient.Client) {
+	chain := &testChain{head: head, logs: logs, calls: make(map[string][]byte), newLogs: make(chan types.Log)}
+	server := rpc.NewServer()
+	if err := server.RegisterName("eth", chain); err != nil {
+		t.Fatal(err)
+	}
+	return chain, ethclient.NewClient(rpc.DialInProc(server))
+}
+
+// mockCall makes calls of @method with @args on the contract at @to return @outputs.
+func (chain *testChain) mockCall(t *testing.T, to common.Address, contractABI string, method string, args []interface{}, outputs ...interface{}) {
+	parsed, err := abi.JSON(strings.NewReader(contractABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := parsed.Pack(method, args...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, err := parsed.Methods[method].Outputs.Pack(outputs...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	chain.calls[to.Hex()+hexutil.Encode(data)] = output
+}
+
+func (chain *testChain) BlockNumber() hexutil.Uint64 {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	return hexutil.Uint64(chain.head)
+}
+
+func (chain *testChain) Call(args testCallArgs, block string) (hexutil.Bytes, error) {
+	chain.mu.Lock()
+	defer chain.mu.Unlock()
+	output, ok := chain.calls[args.To.Hex()+hexutil.Encode(args.Data)]
+	if !ok {
+		return nil, errors.New("execution reverted")
+	}
+	return output, nil
+}
+
+func (chain *testChain) GetLogs(args testFilterArgs) ([]types.Log, error) {
+	from, err := hexutil.DecodeUint64(args.FromBlock)
+	if err != nil {
+		return nil, err
+	}
+	to, err := hexutil.DecodeUint64(args.ToBlock)
+	if err != nil {
+		return nil, err
+	}
+	logs := []types.Log{}
+	for _, vLog := range chain.logs {
+		if vLog.BlockNumber >= from && vLog.BlockNumber <= to && args.matches(vLog) {
+			logs = append(logs, vLog)
+		}
+	}
+	return logs, nil
+}
+
+func (chain *testChain) Logs(ctx context.Context, args testFilterArgs) (*rpc.Subscription, error) {
+	notifier, ok := rpc.NotifierFromContext(ctx)
+	if !ok {
+		return nil, rpc.ErrNotificationsUnsupported
+	}
+	sub := notifier.CreateSubscription()
+	go func() {
+		for {
+			select {
+			case vLog := <-chain.newLogs:
+				if err := notifier.Notify(sub.ID, vLog); err != nil {
+					return
+				}
+			case <-sub.Err():
+				return
+			}
+		}
+	}()
+	return sub, nil
+}
+
+func (args testFilterArgs) matches(vLog types.Log) bool {
+	if len(args.Addresses) > 0 {
+		found := false
+		for _, address := range args.Addresses {
+			found = found || address == vLog.Address
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(args.Topics) > 0 && len(args.Topics[0]) > 0 {
+		found := false
+		for _, topic := range args.Topics[0] {
+			found = found || (len(vLog.Topics) > 0 && topic == vLog.Topics[0])
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// checkpointedRelDB returns a relational datastore in which the liquidity scraper of @exchange
+// has processed all blocks up to @lastBlock.
+func checkpointedRelDB(t *testing.T, exchange string, lastBlock uint64) *models.MemoryRelDB {
+	relDB := models.NewMemoryRelDataStore()
+	err := relDB.SetScraperState(context.Background(), liquidityScraperName(exchange), &LiquidityScraperState{LastBlock: lastBlock})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return relDB
+}
+
+func lastBlock(t *testing.T, relDB models.RelDatastore, exchange string) uint64 {
+	var state LiquidityScraperState
+	if err := relDB.GetScraperState(context.Background(), liquidityScraperName(exchange), &state); err != nil {
+		t.Fatal(err)
+	}
+	return state.LastBlock
+}
+
+func receivePool(t *testing.T, poolChannel chan dia.Pool) dia.Pool {
+	select {
+	case pool := <-poolChannel:
+		return pool
+	case <-time.After(5 * time.Second):
+		t.Fatal("no pool received")
+	}
+	return dia.Pool{}
+}
+
+// checkVolumes checks the asset volumes of @pool against @volumes.
+func checkVolumes(t *testing.T, pool dia.Pool, volumes ...float64) {
+	if len(pool.Assetvolumes) != len(volumes) {
+		t.Fatalf("expected %d asset volumes in pool %s, got %v", len(volumes), pool.Address, pool.Assetvolumes)
+	}
+	for i, volume := range volumes {
+		if math.Abs(pool.Assetvolumes[i].Volume-volume) > 1e-9*volume {
+			t.Errorf("expected volume %v of %s in pool %s, got %v", volume, pool.Assetvolumes[i].Asset.Symbol, pool.Address, pool.Assetvolumes[i].Volume)
+		}
+	}
+}
+
+func tokenAmount(amount int64, decimals int) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil))
+}
+
+func TestPoolWatcherUniswapV2Sync(t *testing.T) {
+	const exchange = dia.UniswapExchange
+	pairABI, err := abi.JSON(strings.NewReader(uniswap.IUniswapV2PairABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pairAddress := common.HexToAddress("0xB4e16d0168e52d35CaCD2c6185b44281Ec28C9Dc")
+	foreignAddress := common.HexToAddress("0x000000000000000000000000000000000000dEaD")
+	syncLog := func(address common.Address, block uint64, removed bool, reserve0 *big.Int, reserve1 *big.Int) types.Log {
+		data, err := pairABI.Events["Sync"].Inputs.NonIndexed().Pack(reserve0, reserve1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.Log{Address: address, Topics: []common.Hash{pairABI.Events["Sync"].ID}, Data: data, BlockNumber: block, Removed: removed}
+	}
+
+	chain, client := newTestChain(t, 10, []types.Log{
+		// Already processed before the checkpoint.
+		syncLog(pairAddress, 4, false, tokenAmount(1, 18), tokenAmount(1, 6)),
+		syncLog(pairAddress, 5, false, tokenAmount(2, 18), tokenAmount(3000, 6)),
+		// Sync events of pairs without factory are ignored.
+		syncLog(foreignAddress, 6, false, tokenAmount(1, 18), tokenAmount(1, 18)),
+		syncLog(pairAddress, 7, true, tokenAmount(9, 18), tokenAmount(9, 6)),
+		syncLog(pairAddress, 9, false, tokenAmount(4, 18), tokenAmount(6000, 6)),
+	})
+	pairFilterer, err := uniswap.NewIUniswapV2PairFilterer(common.Address{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	relDB := checkpointedRelDB(t, exchange, 4)
+	us := &UniswapScraper{
+		RestClient:   client,
+		WsClient:     client,
+		relDB:        relDB,
+		poolChannel:  make(chan dia.Pool),
+		exchangeName: exchange,
+		pools: map[common.Address]dia.Pool{
+			pairAddress: {
+				Exchange: dia.Exchange{Name: exchange},
+				Address:  pairAddress.Hex(),
+				Assetvolumes: []struct {
+					Asset  dia.Asset
+					Volume float64
+				}{
+					{Asset: dia.Asset{Symbol: "WETH", Decimals: 18}},
+					{Asset: dia.Asset{Symbol: "USDC", Decimals: 6}},
+				},
+			},
+		},
+		foreignPairs: make(map[common.Address]struct{}),
+		pairFilterer: pairFilterer,
+	}
+	go us.watchPools()
+
+	checkVolumes(t, receivePool(t, us.poolChannel), 2, 3000)
+	checkVolumes(t, receivePool(t, us.poolChannel), 4, 6000)
+
+	// Logs of the subscription up to the checkpoint and removed logs are skipped.
+	chain.newLogs <- syncLog(pairAddress, 10, false, tokenAmount(9, 18), tokenAmount(9, 6))
+	chain.newLogs <- syncLog(pairAddress, 11, true, tokenAmount(9, 18), tokenAmount(9, 6))
+	chain.newLogs <- syncLog(pairAddress, 12, false, tokenAmount(5, 18), tokenAmount(10000, 6))
+	checkVolumes(t, receivePool(t, us.poolChannel), 5, 10000)
+	if block := lastBlock(t, relDB, exchange); block != 11 {
+		t.Errorf("expected checkpoint at block 11, got %d", block)
+	}
+	// The cached pool is not modified by updates.
+	if us.pools[pairAddress].Assetvolumes[0].Volume != 0 {
+		t.Errorf("cached pool was modified: %v", us.pools[pairAddress])
+	}
+}
+
+func TestPoolWatcherCurveTokenExchange(t *testing.T) {
+	const exchange = dia.CurveFIExchange
+	registry := common.HexToAddress("0x90E00ACe148ca3b23Ac1bC8C240C2a7Dd9c2d7f5")
+	poolAddress := common.HexToAddress("0xDC24316b9AE028F1497c275EB9192a3Ea0f67022")
+	otherPool := common.HexToAddress("0xbEbc44782C7dB0a1A60Cb6fe97d0b483032FF1C7")
+	coin := common.HexToAddress("0xae7ab96520DE3A18E5e111B5EaAb095312D7fE84")
+	ether := common.HexToAddress("0xEeeeeEeeeEeEeeEeEeEeeEEEeeeeEeeeeeeeEEeE")
+	tokenExchange := crypto.Keccak256Hash([]byte("TokenExchange(address,int128,uint256,int128,uint256)"))
+	exchangeLog := func(address common.Address, block uint64) types.Log {
+		return types.Log{Address: address, Topics: []common.Hash{tokenExchange}, BlockNumber: block}
+	}
+
+	chain, client := newTestChain(t, 10, []types.Log{
+		exchangeLog(poolAddress, 6),
+		// Logs of pools which are not in the registry are not requested.
+		exchangeLog(otherPool, 7),
+	})
+	chain.mockCall(t, registry, curvefi.CurvefiABI, "pool_count", nil, big.NewInt(1))
+	chain.mockCall(t, registry, curvefi.CurvefiABI, "pool_list", []interface{}{big.NewInt(0)}, poolAddress)
+	chain.mockCall(t, registry, curvefi.CurvefiABI, "get_coins", []interface{}{poolAddress}, [8]common.Address{coin, ether})
+	mockBalances := func(balance0 *big.Int, balance1 *big.Int) {
+		balances := [8]*big.Int{balance0, balance1}
+		for i := 2; i < len(balances); i++ {
+			balances[i] = big.NewInt(0)
+		}
+		chain.mockCall(t, registry, curvefi.CurvefiABI, "get_balances", []interface{}{poolAddress}, balances)
+	}
+	mockBalances(tokenAmount(100, 18), tokenAmount(120, 18))
+	chain.mockCall(t, coin, token.TokenABI, "symbol", nil, "stETH")
+	chain.mockCall(t, coin, token.TokenABI, "name", nil, "Liquid staked Ether 2.0")
+	chain.mockCall(t, coin, token.TokenABI, "decimals", nil, big.NewInt(18))
+
+	relDB := checkpointedRelDB(t, exchange, 4)
+	scraper := &CurveFIScraper{
+		RestClient:   client,
+		WsClient:     client,
+		relDB:        relDB,
+		poolChannel:  make(chan dia.Pool),
+		blockchain:   dia.ETHEREUM,
+		exchangeName: exchange,
+		poolAddrs:    []string{registry.Hex()},
+		pools:        make(map[common.Address]common.Address),
+	}
+	go scraper.watchPools()
+
+	pool := receivePool(t, scraper.poolChannel)
+	if pool.Address != poolAddress.Hex() || pool.Assetvolumes[0].Asset.Symbol != "stETH" || pool.Assetvolumes[1].Asset.Symbol != "ETH" {
+		t.Errorf("unexpected pool %v", pool)
+	}
+	checkVolumes(t, pool, 100, 120)
+
+	mockBalances(tokenAmount(90, 18), tokenAmount(130, 18))
+	chain.newLogs <- exchangeLog(poolAddress, 11)
+	checkVolumes(t, receivePool(t, scraper.poolChannel), 90, 130)
+	if block := lastBlock(t, relDB, exchange); block != 10 {
+		t.Errorf("expected checkpoint at block 10, got %d", block)
+	}
+}
+
+func TestPoolWatcherBalancerV2Swap(t *testing.T) {
+	const exchange = dia.BalancerV2Exchange
+	vaultABI, err := abi.JSON(strings.NewReader(balancervault.BalancerVaultABI))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vault := common.HexToAddress("0xBA12222222228d8Ba445958a75a0704d566BF2C8")
+	poolAddress := common.HexToAddress("0x5c6Ee304399DBdB9C8Ef030aB642B10820DB8F56")
+	var poolID common.Hash
+	copy(poolID[:], poolAddress.Bytes())
+	poolID[31] = 1
+	bal := common.HexToAddress("0xba100000625a3754423978a60c9317c58a424e3D")
+	weth := common.HexToAddress("0xC02aaA39b223FE8D0A0e5C4F27eAD9083C756Cc2")
+	swapLog := func(block uint64) types.Log {
+		data, err := vaultABI.Events["Swap"].Inputs.NonIndexed().Pack(tokenAmount(1, 18), tokenAmount(2, 15))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return types.Log{
+			Address:     vault,
+			Topics:      []common.Hash{vaultABI.Events["Swap"].ID, poolID, bal.Hash(), weth.Hash()},
+			Data:        data,
+			BlockNumber: block,
+		}
+	}
+
+	chain, client := newTestChain(t, 10, []types.Log{
+		// Logs without pool id are ignored.
+		{Address: vault, Topics: []common.Hash{vaultABI.Events["Swap"].ID}, BlockNumber: 5},
+		swapLog(6),
+	})
+	mockPoolTokens := func(balanceBAL *big.Int, balanceWETH *big.Int) {
+		chain.mockCall(t, vault, balancervault.BalancerVaultABI, "getPoolTokens", []interface{}{poolID}, []common.Address{bal, weth}, []*big.Int{balanceBAL, balanceWETH}, big.NewInt(6))
+	}
+	mockPoolTokens(tokenAmount(8000, 18), tokenAmount(50, 18))
+
+	relDB := checkpointedRelDB(t, exchange, 4)
+	scraper := &BalancerV2Scraper{
+		RestClient:    client,
+		WsClient:      client,
+		relDB:         relDB,
+		poolChannel:   make(chan dia.Pool),
+		blockchain:    dia.ETHEREUM,
+		exchangeName:  exchange,
+		vaultContract: vault.Hex(),
+		cachedAssets: map[string]dia.Asset{
+			bal.Hex():  {Symbol: "BAL", Address: bal.Hex(), Decimals: 18, Blockchain: dia.ETHEREUM},
+			weth.Hex(): {Symbol: "WETH", Address: weth.Hex(), Decimals: 18, Blockchain: dia.ETHEREUM},
+		},
+	}
+	go scraper.watchPools()
+
+	pool := receivePool(t, scraper.poolChannel)
+	if pool.Address != poolAddress.Hex() {
+		t.Errorf("expected pool %s, got %s", poolAddress.Hex(), pool.Address)
+	}
+	checkVolumes(t, pool, 8000, 50)
+
+	mockPoolTokens(tokenAmount(7000, 18), tokenAmount(60, 18))
+	chain.newLogs <- types.Log{Address: vault, Topics: []common.Hash{vaultABI.Events["PoolBalanceChanged"].ID, poolID, bal.Hash()}, BlockNumber: 12}
+	checkVolumes(t, receivePool(t, scraper.poolChannel), 7000, 60)
+	if block := lastBlock(t, relDB, exchange); block != 11 {
+		t.Errorf("expected checkpoint at block 11, got %d", block)
+	}
+}
@@ -3,6 +3,7 @@ package liquidityscrapers
 import (
 	"github.com/diadata-org/diadata/pkg/dia"
 	scrapers "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/sirupsen/logrus"
 )
 
@@ -23,60 +24,65 @@ func init() {
 }
 
 // NewLiquidityScraper returns a liquidity scraper for @source.
-func NewLiquidityScraper(source string) LiquidityScraper {
+// Event-driven scrapers store their last processed block in @relDB.
+func NewLiquidityScraper(source string, relDB models.RelDatastore) LiquidityScraper {
 	switch source {
 	case dia.UniswapExchange:
-		return NewUniswapScraper(exchanges[dia.UniswapExchange])
+		return NewUniswapScraper(exchanges[dia.UniswapExchange], relDB)
 	case dia.SushiSwapExchange:
-		return NewUniswapScraper(exchanges[dia.SushiSwapExchange])
+		return NewUniswapScraper(exchanges[dia.SushiSwapExchange], relDB)
 	case dia.PanCakeSwap:
-		return NewUniswapScraper(exchanges[dia.PanCakeSwap])
+		return NewUniswapScraper(exchanges[dia.PanCakeSwap], relDB)
 	case dia.DfynNetwork:
-		return NewUniswapScraper(exchanges[dia.DfynNetwork])
+		return NewUniswapScraper(exchanges[dia.DfynNetwork], relDB)
 	case dia.QuickswapExchange:
-		return NewUniswapScraper(exchanges[dia.QuickswapExchange])
+		return NewUniswapScraper(exchanges[dia.QuickswapExchange], relDB)
 	case dia.UbeswapExchange:
-		return NewUniswapScraper(exchanges[dia.UbeswapExchange])
+		return NewUniswapScraper(exchanges[dia.UbeswapExchange], relDB)
 	case dia.SpookyswapExchange:
-		return NewUniswapScraper(exchanges[dia.SpookyswapExchange])
+		return NewUniswapScraper(exchanges[dia.SpookyswapExchange], relDB)
 	case dia.SpiritswapExchange:
-		return NewUniswapScraper(exchanges[dia.SpiritswapExchange])
+		return NewUniswapScraper(exchanges[dia.SpiritswapExchange], relDB)
 	case dia.SolarbeamExchange:
-		return NewUniswapScraper(exchanges[dia.SolarbeamExchange])
+		return NewUniswapScraper(exchanges[dia.SolarbeamExchange], relDB)
 	case dia.TrisolarisExchange:
-		return NewUniswapScraper(exchanges[dia.TrisolarisExchange])
+		return NewUniswapScraper(exchanges[dia.TrisolarisExchange], relDB)
 	case dia.NetswapExchange:
-		return NewUniswapScraper(exchanges[dia.NetswapExchange])
+		return NewUniswapScraper(exchanges[dia.NetswapExchange], relDB)
 	case dia.SushiSwapExchangePolygon:
-		return NewUniswapScraper(exchanges[dia.SushiSwapExchangePolygon])
+		return NewUniswapScraper(exchanges[dia.SushiSwapExchangePolygon], relDB)
 	case dia.SushiSwapExchangeFantom:
-		return NewUniswapScraper(exchanges[dia.SushiSwapExchangeFantom])
+		return NewUniswapScraper(exchanges[dia.SushiSwapExchangeFantom], relDB)
 	case dia.HuckleberryExchange:
-		return NewUniswapScraper(exchanges[dia.HuckleberryExchange])
+		return NewUniswapScraper(exchanges[dia.HuckleberryExchange], relDB)
 	case dia.TraderJoeExchange:
-		return NewUniswapScraper(exchanges[dia.TraderJoeExchange])
+		return NewUniswapScraper(exchanges[dia.TraderJoeExchange], relDB)
 	case dia.PangolinExchange:
-		return NewUniswapScraper(exchanges[dia.PangolinExchange])
+		return NewUniswapScraper(exchanges[dia.PangolinExchange], relDB)
 	case dia.TethysExchange:
-		return NewUniswapScraper(exchanges[dia.TethysExchange])
+		return NewUniswapScraper(exchanges[dia.TethysExchange], relDB)
 	case dia.HermesExchange:
-		return NewUniswapScraper(exchanges[dia.HermesExchange])
+		return NewUniswapScraper(exchanges[dia.HermesExchange], relDB)
 	case dia.OmniDexExchange:
-		return NewUniswapScraper(exchanges[dia.OmniDexExchange])
+		return NewUniswapScraper(exchanges[dia.OmniDexExchange], relDB)
 	case dia.DiffusionExchange:
-		return NewUniswapScraper(exchanges[dia.DiffusionExchange])
+		return NewUniswapScraper(exchanges[dia.DiffusionExchange], relDB)
 	case dia.ApeswapExchange:
-		return NewUniswapScraper(exchanges[dia.ApeswapExchange])
+		return NewUniswapScraper(exchanges[dia.ApeswapExchange], relDB)
 	case dia.BiswapExchange:
-		return NewUniswapScraper(exchanges[dia.BiswapExchange])
+		return NewUniswapScraper(exchanges[dia.BiswapExchange], relDB)
 	case dia.ArthswapExchange:
-		return NewUniswapScraper(exchanges[dia.ArthswapExchange])
+		return NewUniswapScraper(exchanges[dia.ArthswapExchange], relDB)
+	case dia.UniswapExchangeV3:
+		return NewUniswapV3Scraper(exchanges[dia.UniswapExchangeV3])
+	case dia.UniswapExchangeV3Polygon:
+		return NewUniswapV3Scraper(exchanges[dia.UniswapExchangeV3Polygon])
 	case dia.CurveFIExchange:
-		return NewCurveFIScraper(exchanges[dia.CurveFIExchange])
+		return NewCurveFIScraper(exchanges[dia.CurveFIExchange], relDB)
 	case dia.BalancerV2Exchange:
-		return NewBalancerV2Scraper(exchanges[dia.BalancerV2Exchange])
+		return NewBalancerV2Scraper(exchanges[dia.BalancerV2Exchange], relDB)
 	case dia.BeetsExchange:
-		return NewBalancerV2Scraper(exchanges[dia.BeetsExchange])
+		return NewBalancerV2Scraper(exchanges[dia.BeetsExchange], relDB)
 	default:
 		return nil
 	}
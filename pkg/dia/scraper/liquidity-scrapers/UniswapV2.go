@@ -10,9 +10,13 @@ import (
 	"github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswap"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/accounts/abi/bind"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
 )
 
@@ -52,91 +56,110 @@ const (
 	telosWaitMilliseconds       = "400"
 	evmosWaitMilliseconds       = "400"
 	astarWaitMilliseconds       = "1000"
+
+	// The Sync event is filtered by topic only, as it is emitted by all pairs.
+	uniswapFilterPageSize = 100
 )
 
 type UniswapScraper struct {
 	RestClient   *ethclient.Client
+	WsClient     *ethclient.Client
+	relDB        models.RelDatastore
 	poolChannel  chan dia.Pool
 	doneChannel  chan bool
 	blockchain   string
 	waitTime     int
 	exchangeName string
+	// pools of the exchange by address and addresses of pairs deployed by other factories
+	pools        map[common.Address]dia.Pool
+	foreignPairs map[common.Address]struct{}
+	pairFilterer *uniswap.IUniswapV2PairFilterer
 }
 
 var exchangeFactoryContractAddress string
 
-func NewUniswapScraper(exchange dia.Exchange) (us *UniswapScraper) {
+// NewUniswapScraper returns a liquidity scraper which takes a snapshot of all pools of @exchange
+// and afterwards updates the reserves of a pool on each of its Sync events.
+func NewUniswapScraper(exchange dia.Exchange, relDB models.RelDatastore) (us *UniswapScraper) {
 
 	switch exchange.Name {
 	case dia.UniswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialEthereum, uniswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialEthereum, uniswapWaitMilliseconds)
 	case dia.SushiSwapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialEthereum, sushiswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialEthereum, sushiswapWaitMilliseconds)
 	case dia.SushiSwapExchangePolygon:
-		us = makeUniswapPoolScraper(exchange, restDialPolygon, sushiswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialPolygon, sushiswapWaitMilliseconds)
 	case dia.SushiSwapExchangeFantom:
-		us = makeUniswapPoolScraper(exchange, restDialFantom, sushiswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialFantom, sushiswapWaitMilliseconds)
 	case dia.PanCakeSwap:
-		us = makeUniswapPoolScraper(exchange, restDialBSC, pancakeswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialBSC, pancakeswapWaitMilliseconds)
 	case dia.DfynNetwork:
-		us = makeUniswapPoolScraper(exchange, restDialPolygon, dfynWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialPolygon, dfynWaitMilliseconds)
 	case dia.QuickswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialPolygon, dfynWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialPolygon, dfynWaitMilliseconds)
 	case dia.UbeswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialCelo, ubeswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialCelo, ubeswapWaitMilliseconds)
 	case dia.SpookyswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialFantom, spookyswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialFantom, spookyswapWaitMilliseconds)
 	case dia.SpiritswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialFantom, spiritswapWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialFantom, spiritswapWaitMilliseconds)
 	case dia.SolarbeamExchange:
-		us = makeUniswapPoolScraper(exchange, restDialMoonriver, solarbeamWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialMoonriver, solarbeamWaitMilliseconds)
 	case dia.TrisolarisExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAurora, trisolarisWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAurora, trisolarisWaitMilliseconds)
 	case dia.NetswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialMetis, metisWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialMetis, metisWaitMilliseconds)
 	case dia.HuckleberryExchange:
-		us = makeUniswapPoolScraper(exchange, restDialMoonriver, moonriverWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialMoonriver, moonriverWaitMilliseconds)
 	case dia.TraderJoeExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAvalanche, avalancheWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAvalanche, avalancheWaitMilliseconds)
 	case dia.PangolinExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAvalanche, avalancheWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAvalanche, avalancheWaitMilliseconds)
 	case dia.TethysExchange:
-		us = makeUniswapPoolScraper(exchange, restDialMetis, metisWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialMetis, metisWaitMilliseconds)
 	case dia.HermesExchange:
-		us = makeUniswapPoolScraper(exchange, restDialMetis, metisWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialMetis, metisWaitMilliseconds)
 	case dia.OmniDexExchange:
-		us = makeUniswapPoolScraper(exchange, restDialTelos, telosWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialTelos, telosWaitMilliseconds)
 	case dia.DiffusionExchange:
-		us = makeUniswapPoolScraper(exchange, restDialEvmos, evmosWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialEvmos, evmosWaitMilliseconds)
 	case dia.ArthswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAstar, astarWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAstar, astarWaitMilliseconds)
 	case dia.ApeswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAstar, astarWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAstar, astarWaitMilliseconds)
 	case dia.BiswapExchange:
-		us = makeUniswapPoolScraper(exchange, restDialAstar, astarWaitMilliseconds)
+		us = makeUniswapPoolScraper(exchange, relDB, restDialAstar, astarWaitMilliseconds)
 	}
 
 	exchangeFactoryContractAddress = exchange.Contract
 
 	go func() {
-		us.fetchPools()
+		us.watchPools()
 	}()
 	return us
 
 }
 
 // makeUniswapPoolScraper returns an asset source as used in NewUniswapAssetSource.
-func makeUniswapPoolScraper(exchange dia.Exchange, restDial string, waitMilliseconds string) *UniswapScraper {
-	var restClient *ethclient.Client
+func makeUniswapPoolScraper(exchange dia.Exchange, relDB models.RelDatastore, restDial string, waitMilliseconds string) *UniswapScraper {
+	var restClient, wsClient *ethclient.Client
 	var err error
 	var poolChannel = make(chan dia.Pool)
 	var doneChannel = make(chan bool)
 	var us *UniswapScraper
-	log.Infof("Init rest client for %s.", exchange.BlockChain.Name)
+	log.Infof("Init rest and ws client for %s.", exchange.BlockChain.Name)
 	restClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_REST", restDial))
 	if err != nil {
 		log.Fatal("init rest client: ", err)
 	}
+	wsClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_WS", ""))
+	if err != nil {
+		log.Fatal("init ws client: ", err)
+	}
+	pairFilterer, err := uniswap.NewIUniswapV2PairFilterer(common.Address{}, nil)
+	if err != nil {
+		log.Fatal("init pair filterer: ", err)
+	}
 	var waitTime int
 	waitTimeString := utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_WAIT_TIME", waitMilliseconds)
 	waitTime, err = strconv.Atoi(waitTimeString)
@@ -146,15 +169,88 @@ func makeUniswapPoolScraper(exchange dia.Exchange, restDial string, waitMillisec
 	}
 	us = &UniswapScraper{
 		RestClient:   restClient,
+		WsClient:     wsClient,
+		relDB:        relDB,
 		poolChannel:  poolChannel,
 		doneChannel:  doneChannel,
 		blockchain:   exchange.BlockChain.Name,
 		waitTime:     waitTime,
 		exchangeName: exchange.Name,
+		pools:        make(map[common.Address]dia.Pool),
+		foreignPairs: make(map[common.Address]struct{}),
+		pairFilterer: pairFilterer,
 	}
 	return us
 }
 
+// watchPools takes a snapshot of all pools if the scraper runs for the first time and
+// afterwards sends an update of a pool into the pool channel on each of its Sync events.
+func (us *UniswapScraper) watchPools() {
+	syncEvent, err := abi.JSON(strings.NewReader(uniswap.IUniswapV2PairABI))
+	if err != nil {
+		log.Fatal("parse pair abi: ", err)
+	}
+	query := ethereum.FilterQuery{Topics: [][]common.Hash{{syncEvent.Events["Sync"].ID}}}
+	watcher := newPoolWatcher(us.exchangeName, us.RestClient, us.WsClient, us.relDB, query, uniswapFilterPageSize, us.handleSync)
+	if err := watcher.run(us.fetchPools); err != nil {
+		log.Fatal("watch pools: ", err)
+	}
+}
+
+// handleSync sends the pool of the Sync event @vLog with the updated reserves into the pool channel.
+func (us *UniswapScraper) handleSync(vLog types.Log) {
+	pool, ok := us.poolByAddress(vLog.Address)
+	if !ok {
+		return
+	}
+	sync, err := us.pairFilterer.ParseSync(vLog)
+	if err != nil {
+		log.Errorf("parse sync event of %s: %v", vLog.Address.Hex(), err)
+		return
+	}
+	reserves := []*big.Int{sync.Reserve0, sync.Reserve1}
+
+	// The cached pool must not be modified, so the asset volumes are copied.
+	update := pool
+	update.Assetvolumes = nil
+	for i, av := range pool.Assetvolumes {
+		av.Volume, _ = new(big.Float).Quo(big.NewFloat(0).SetInt(reserves[i]), new(big.Float).SetFloat64(math.Pow10(int(av.Asset.Decimals)))).Float64()
+		update.Assetvolumes = append(update.Assetvolumes, av)
+	}
+	update.Time = time.Now()
+	us.poolChannel <- update
+}
+
+// poolByAddress returns the cached pool at @pairAddress. Pairs unknown to the scraper are fetched,
+// if they are deployed by the exchange's factory, as is the case for pairs created after the snapshot.
+func (us *UniswapScraper) poolByAddress(pairAddress common.Address) (dia.Pool, bool) {
+	if pool, ok := us.pools[pairAddress]; ok {
+		return pool, true
+	}
+	if _, ok := us.foreignPairs[pairAddress]; ok {
+		return dia.Pool{}, false
+	}
+
+	pairContract, err := uniswap.NewIUniswapV2PairCaller(pairAddress, us.RestClient)
+	if err != nil {
+		log.Error(err)
+		return dia.Pool{}, false
+	}
+	factory, err := pairContract.Factory(&bind.CallOpts{})
+	if err != nil || factory != common.HexToAddress(exchangeFactoryContractAddress) {
+		// Contracts without factory emit Sync events as well.
+		us.foreignPairs[pairAddress] = struct{}{}
+		return dia.Pool{}, false
+	}
+	pool, err := us.GetPoolByAddress(pairAddress)
+	if err != nil {
+		log.Errorf("get pool %s: %v", pairAddress.Hex(), err)
+		return dia.Pool{}, false
+	}
+	us.pools[pairAddress] = pool
+	return pool, true
+}
+
 // fetchPools iterates through all (Uniswap) pools and sends them into the pool channel.
 func (us *UniswapScraper) fetchPools() {
 
@@ -169,11 +265,12 @@ func (us *UniswapScraper) fetchPools() {
 		pool, err := us.GetPoolByID(int64(numPairs - 1 - i))
 		if err != nil {
 			log.Errorln("Error getting pair with ID ", numPairs-1-i)
+			continue
 		}
 		log.Info("found pool: ", pool)
+		us.pools[common.HexToAddress(pool.Address)] = pool
 		us.poolChannel <- pool
 	}
-	us.doneChannel <- true
 }
 
 // GetPoolByID returns the Uniswap Pool with the integer id @num.
@@ -0,0 +1,384 @@
+package liquidityscrapers
+
+import (
+	"context"
+	"math"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers/ethhelper"
+	uniswapcontractv3 "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswapv3"
+	UniswapV3Pair "github.com/diadata-org/diadata/pkg/dia/scraper/exchange-scrapers/uniswapv3/uniswapV3Pair"
+	"github.com/diadata-org/diadata/pkg/utils"
+)
+
+const (
+	uniswapV3FilterPageSize      = 10000
+	uniswapV3RestDial            = ""
+	uniswapV3DefaultWaitTime     = 200
+	uniswapV3DefaultPriceRange   = "0.02"
+	uniswapV3TickBase            = 1.0001
+	uniswapV3TicksPerBitmapWord  = 256
+	uniswapV3MaxInitializedTicks = 1000
+	uniswapV3MinTick             = -887272
+	uniswapV3MaxTick             = 887272
+)
+
+type UniswapV3Scraper struct {
+	RestClient      *ethclient.Client
+	poolChannel     chan dia.Pool
+	doneChannel     chan bool
+	blockchain      string
+	exchangeName    string
+	factoryContract common.Address
+	startBlock      uint64
+	waitTime        int
+	// Liquidity is counted in the price range [(1-priceRange)*price, (1+priceRange)*price].
+	priceRange   float64
+	cachedAssets map[string]dia.Asset
+}
+
+// tickLiquidity is the change of active liquidity when the price crosses an initialized tick upwards.
+type tickLiquidity struct {
+	tick         int
+	liquidityNet float64
+}
+
+// NewUniswapV3Scraper returns a liquidity scraper for Uniswap V3 pools.
+// The price range around the current price in which liquidity is counted can be set
+// through the env var UNISWAPV3_PRICE_RANGE as a fraction of the price.
+func NewUniswapV3Scraper(exchange dia.Exchange) *UniswapV3Scraper {
+	var (
+		restClient  *ethclient.Client
+		err         error
+		poolChannel = make(chan dia.Pool)
+		doneChannel = make(chan bool)
+		scraper     *UniswapV3Scraper
+	)
+
+	log.Infof("Init rest client for %s.", exchange.BlockChain.Name)
+	restClient, err = ethclient.Dial(utils.Getenv(strings.ToUpper(exchange.BlockChain.Name)+"_URI_REST", uniswapV3RestDial))
+	if err != nil {
+		log.Fatal("init rest client: ", err)
+	}
+
+	priceRange, err := strconv.ParseFloat(utils.Getenv("UNISWAPV3_PRICE_RANGE", uniswapV3DefaultPriceRange), 64)
+	if err != nil || priceRange <= 0 || priceRange >= 1 {
+		log.Errorf("invalid price range %v. Use default %s", priceRange, uniswapV3DefaultPriceRange)
+		priceRange, _ = strconv.ParseFloat(uniswapV3DefaultPriceRange, 64)
+	}
+
+	waitTime := exchange.WaitTime
+	if waitTime == 0 {
+		waitTime = uniswapV3DefaultWaitTime
+	}
+
+	scraper = &UniswapV3Scraper{
+		RestClient:      restClient,
+		poolChannel:     poolChannel,
+		doneChannel:     doneChannel,
+		blockchain:      exchange.BlockChain.Name,
+		exchangeName:    exchange.Name,
+		factoryContract: common.HexToAddress(exchange.Contract),
+		waitTime:        waitTime,
+		priceRange:      priceRange,
+		cachedAssets:    make(map[string]dia.Asset),
+	}
+
+	switch exchange.Name {
+	case dia.UniswapExchangeV3:
+		scraper.startBlock = 12369621
+	case dia.UniswapExchangeV3Polygon:
+		scraper.startBlock = 22757913
+	}
+
+	go func() {
+		scraper.fetchPools()
+	}()
+
+	return scraper
+}
+
+// fetchPools collects all pools created by the factory and sends them into the pool channel.
+func (scraper *UniswapV3Scraper) fetchPools() {
+	events, err := scraper.allPoolCreations()
+	if err != nil {
+		log.Fatal("fetch all created pools: ", err)
+	}
+	log.Info("Found ", len(events), " pools")
+
+	for _, evt := range events {
+		time.Sleep(time.Duration(scraper.waitTime) * time.Millisecond)
+		pool, err := scraper.GetPool(evt)
+		if err != nil {
+			log.Errorf("get pool %s: %v", evt.Pool.Hex(), err)
+			continue
+		}
+		log.Info("found pool: ", pool)
+		scraper.poolChannel <- pool
+	}
+	scraper.doneChannel <- true
+}
+
+// allPoolCreations returns the pool creation events of the factory contract.
+func (scraper *UniswapV3Scraper) allPoolCreations() ([]*uniswapcontractv3.UniswapV3PoolCreated, error) {
+	var (
+		startBlock = scraper.startBlock
+		endBlock   = startBlock + uniswapV3FilterPageSize
+		events     []*uniswapcontractv3.UniswapV3PoolCreated
+	)
+
+	filterer, err := uniswapcontractv3.NewUniswapV3Filterer(scraper.factoryContract, scraper.RestClient)
+	if err != nil {
+		return nil, err
+	}
+
+	currBlock, err := scraper.RestClient.BlockNumber(context.Background())
+	if err != nil {
+		return nil, err
+	}
+
+	for startBlock <= currBlock {
+		if endBlock > currBlock {
+			endBlock = currBlock
+		}
+		log.Infof("startblock - endblock: %v --- %v ", startBlock, endBlock)
+
+		it, err := filterer.FilterPoolCreated(&bind.FilterOpts{
+			Start: startBlock,
+			End:   &endBlock,
+		}, nil, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+		for it.Next() {
+			events = append(events, it.Event)
+		}
+		if err := it.Close(); err != nil {
+			log.Warn("closing iterator: ", err)
+		}
+
+		startBlock = endBlock + 1
+		endBlock += uniswapV3FilterPageSize
+	}
+
+	return events, nil
+}
+
+// GetPool returns the pool of the creation event @evt. The asset volumes of the pool are the effective
+// reserves of both tokens, i.e. the amounts which can be swapped before the price leaves the price range.
+func (scraper *UniswapV3Scraper) GetPool(evt *uniswapcontractv3.UniswapV3PoolCreated) (pool dia.Pool, err error) {
+	token0, err := scraper.assetFromToken(evt.Token0)
+	if err != nil {
+		return
+	}
+	token1, err := scraper.assetFromToken(evt.Token1)
+	if err != nil {
+		return
+	}
+
+	amount0, amount1, err := scraper.effectiveReserves(evt.Pool, int(evt.TickSpacing.Int64()))
+	if err != nil {
+		return
+	}
+
+	pool = dia.Pool{
+		Exchange:   dia.Exchange{Name: scraper.exchangeName},
+		Blockchain: dia.BlockChain{Name: scraper.blockchain},
+		Address:    evt.Pool.Hex(),
+		Time:       time.Now(),
+	}
+	pool.Assetvolumes = append(pool.Assetvolumes, struct {
+		Asset  dia.Asset
+		Volume float64
+	}{
+		Asset:  token0,
+		Volume: amount0 / math.Pow10(int(token0.Decimals)),
+	})
+	pool.Assetvolumes = append(pool.Assetvolumes, struct {
+		Asset  dia.Asset
+		Volume float64
+	}{
+		Asset:  token1,
+		Volume: amount1 / math.Pow10(int(token1.Decimals)),
+	})
+	return
+}
+
+// effectiveReserves returns the raw amounts of token0 and token1 held by the pool at @poolAddress
+// in the price range around its current price.
+func (scraper *UniswapV3Scraper) effectiveReserves(poolAddress common.Address, tickSpacing int) (amount0 float64, amount1 float64, err error) {
+	caller, err := UniswapV3Pair.NewUniswapV3PairCaller(poolAddress, scraper.RestClient)
+	if err != nil {
+		return
+	}
+	slot0, err := caller.Slot0(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+	if slot0.SqrtPriceX96.Sign() == 0 {
+		// Pool is not initialized yet.
+		return
+	}
+	liquidity, err := caller.Liquidity(&bind.CallOpts{})
+	if err != nil {
+		return
+	}
+
+	sqrtPrice, _ := new(big.Float).Quo(new(big.Float).SetInt(slot0.SqrtPriceX96), new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96))).Float64()
+	sqrtPriceLower := sqrtPrice * math.Sqrt(1-scraper.priceRange)
+	sqrtPriceUpper := sqrtPrice * math.Sqrt(1+scraper.priceRange)
+
+	ticks, err := scraper.initializedTicks(caller, sqrtPriceToTick(sqrtPriceLower), sqrtPriceToTick(sqrtPriceUpper)+1, tickSpacing)
+	if err != nil {
+		return
+	}
+	activeLiquidity, _ := new(big.Float).SetInt(liquidity).Float64()
+
+	amount0, amount1 = reservesInRange(sqrtPrice, int(slot0.Tick.Int64()), activeLiquidity, ticks, sqrtPriceLower, sqrtPriceUpper)
+	return
+}
+
+// initializedTicks returns the initialized ticks of the pool in [@tickLower, @tickUpper] ordered by tick.
+// Initialized ticks are looked up in the tick bitmap of the pool, which holds one bit per multiple of @tickSpacing.
+func (scraper *UniswapV3Scraper) initializedTicks(caller *UniswapV3Pair.UniswapV3PairCaller, tickLower int, tickUpper int, tickSpacing int) (ticks []tickLiquidity, err error) {
+	if tickSpacing <= 0 {
+		return
+	}
+	wordLower, _ := tickBitmapPosition(tickLower, tickSpacing)
+	wordUpper, _ := tickBitmapPosition(tickUpper, tickSpacing)
+
+	for word := wordLower; word <= wordUpper; word++ {
+		var bitmap *big.Int
+		bitmap, err = caller.TickBitmap(&bind.CallOpts{}, int16(word))
+		if err != nil {
+			return
+		}
+		for bit := 0; bit < uniswapV3TicksPerBitmapWord; bit++ {
+			if bitmap.Bit(bit) == 0 {
+				continue
+			}
+			tick := (word*uniswapV3TicksPerBitmapWord + bit) * tickSpacing
+			if tick < tickLower || tick > tickUpper {
+				continue
+			}
+			if len(ticks) >= uniswapV3MaxInitializedTicks {
+				log.Warnf("more than %d initialized ticks in price range. Skip remaining ticks.", uniswapV3MaxInitializedTicks)
+				return
+			}
+			info, err := caller.Ticks(&bind.CallOpts{}, big.NewInt(int64(tick)))
+			if err != nil {
+				return nil, err
+			}
+			liquidityNet, _ := new(big.Float).SetInt(info.LiquidityNet).Float64()
+			ticks = append(ticks, tickLiquidity{tick: tick, liquidityNet: liquidityNet})
+		}
+	}
+	return
+}
+
+// reservesInRange returns the amounts of token0 and token1 which are swapped when the price moves
+// from @sqrtPrice to @sqrtPriceUpper and @sqrtPriceLower respectively. @liquidity is the active
+// liquidity at @currentTick and @ticks are the initialized ticks in the range.
+// All prices are square roots of raw token1 per raw token0 amounts.
+func reservesInRange(sqrtPrice float64, currentTick int, liquidity float64, ticks []tickLiquidity, sqrtPriceLower float64, sqrtPriceUpper float64) (amount0 float64, amount1 float64) {
+	sort.Slice(ticks, func(i, j int) bool { return ticks[i].tick < ticks[j].tick })
+
+	// Token0 is sold to swappers when the price moves up. Active liquidity changes by
+	// liquidityNet when an initialized tick above the current tick is crossed.
+	l := liquidity
+	s := sqrtPrice
+	for _, t := range ticks {
+		if t.tick <= currentTick {
+			continue
+		}
+		sqrtPriceTick := tickToSqrtPrice(t.tick)
+		if sqrtPriceTick >= sqrtPriceUpper {
+			break
+		}
+		amount0 += l * (1/s - 1/sqrtPriceTick)
+		s = sqrtPriceTick
+		l += t.liquidityNet
+	}
+	if sqrtPriceUpper > s {
+		amount0 += l * (1/s - 1/sqrtPriceUpper)
+	}
+
+	// Token1 is sold to swappers when the price moves down. Crossing an initialized tick
+	// at or below the current tick downwards removes its liquidityNet.
+	l = liquidity
+	s = sqrtPrice
+	for i := len(ticks) - 1; i >= 0; i-- {
+		t := ticks[i]
+		if t.tick > currentTick {
+			continue
+		}
+		sqrtPriceTick := tickToSqrtPrice(t.tick)
+		if sqrtPriceTick <= sqrtPriceLower {
+			break
+		}
+		amount1 += l * (s - sqrtPriceTick)
+		s = sqrtPriceTick
+		l -= t.liquidityNet
+	}
+	if sqrtPriceLower < s {
+		amount1 += l * (s - sqrtPriceLower)
+	}
+	return
+}
+
+// tickToSqrtPrice returns the square root of the price at @tick.
+func tickToSqrtPrice(tick int) float64 {
+	return math.Pow(uniswapV3TickBase, float64(tick)/2)
+}
+
+// sqrtPriceToTick returns the greatest tick whose price is at most the square of @sqrtPrice.
+func sqrtPriceToTick(sqrtPrice float64) int {
+	tick := int(math.Floor(2 * math.Log(sqrtPrice) / math.Log(uniswapV3TickBase)))
+	if tick < uniswapV3MinTick {
+		return uniswapV3MinTick
+	}
+	if tick > uniswapV3MaxTick {
+		return uniswapV3MaxTick
+	}
+	return tick
+}
+
+// tickBitmapPosition returns the word and bit of @tick in the tick bitmap of a pool.
+func tickBitmapPosition(tick int, tickSpacing int) (word int, bit int) {
+	compressed := tick / tickSpacing
+	if tick < 0 && tick%tickSpacing != 0 {
+		compressed--
+	}
+	return compressed >> 8, compressed & 0xff
+}
+
+// assetFromToken returns the dia.Asset corresponding to an address on the underlying scraper's blockchain.
+func (scraper *UniswapV3Scraper) assetFromToken(token common.Address) (dia.Asset, error) {
+	cached, ok := scraper.cachedAssets[token.Hex()]
+	if ok {
+		return cached, nil
+	}
+	asset, err := ethhelper.ETHAddressToAsset(token, scraper.RestClient, scraper.blockchain)
+	if err != nil {
+		return dia.Asset{}, err
+	}
+	scraper.cachedAssets[token.Hex()] = asset
+	return asset, nil
+}
+
+func (scraper *UniswapV3Scraper) Pool() chan dia.Pool {
+	return scraper.poolChannel
+}
+
+func (scraper *UniswapV3Scraper) Done() chan bool {
+	return scraper.doneChannel
+}
@@ -0,0 +1,148 @@
+package liquidityscrapers
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+// The exchange scrapers imported by this package load their exchanges from the relational datastore
+// on init, so the tests are run with DATASTORE_BACKEND=memory when no postgres is available.
+
+// sqrtPriceFromX96 converts a sqrtPriceX96 value as returned by slot0 of a pool.
+func sqrtPriceFromX96(t *testing.T, sqrtPriceX96 string) float64 {
+	x, ok := new(big.Float).SetString(sqrtPriceX96)
+	if !ok {
+		t.Fatalf("parse %s", sqrtPriceX96)
+	}
+	sqrtPrice, _ := new(big.Float).Quo(x, new(big.Float).SetInt(new(big.Int).Lsh(big.NewInt(1), 96))).Float64()
+	return sqrtPrice
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(math.Abs(a), math.Abs(b))
+}
+
+func TestSqrtPriceToTick(t *testing.T) {
+	cases := []struct {
+		name      string
+		sqrtPrice float64
+		tick      int
+	}{
+		{"price one", 1, 0},
+		// USDC-WETH pool with 1 ETH at around 1700 USDC.
+		{"USDC-WETH", sqrtPriceFromX96(t, "2018382873588440326581633304624437"), 202919},
+		// The same price seen from the inverted pair lies just below tick -202919.
+		{"WETH-USDC", 1 / sqrtPriceFromX96(t, "2018382873588440326581633304624437"), -202920},
+		{"just above tick 1", tickToSqrtPrice(1) * (1 + 1e-9), 1},
+		{"just below tick 1", tickToSqrtPrice(1) * (1 - 1e-9), 0},
+		{"just above tick -1", tickToSqrtPrice(-1) * (1 + 1e-9), -1},
+		{"just below tick -1", tickToSqrtPrice(-1) * (1 - 1e-9), -2},
+		{"below min tick", 1e-300, uniswapV3MinTick},
+		{"above max tick", 1e300, uniswapV3MaxTick},
+	}
+	for _, c := range cases {
+		if tick := sqrtPriceToTick(c.sqrtPrice); tick != c.tick {
+			t.Errorf("%s: expected tick %d, got %d", c.name, c.tick, tick)
+		}
+	}
+}
+
+func TestTickBitmapPosition(t *testing.T) {
+	cases := []struct {
+		tick        int
+		tickSpacing int
+		word        int
+		bit         int
+	}{
+		{0, 60, 0, 0},
+		{60, 60, 0, 1},
+		// Ticks are rounded down to the next multiple of the spacing.
+		{59, 60, 0, 0},
+		{-30, 60, -1, 255},
+		{-60, 60, -1, 255},
+		{-61, 60, -1, 254},
+		// Word boundaries.
+		{255 * 60, 60, 0, 255},
+		{256 * 60, 60, 1, 0},
+		{-256 * 60, 60, -1, 0},
+		{-256*60 - 1, 60, -2, 255},
+		{-1, 1, -1, 255},
+		{-256, 1, -1, 0},
+		{-257, 1, -2, 255},
+		{uniswapV3MinTick, 1, -3466, 24},
+		{uniswapV3MaxTick, 1, 3465, 232},
+	}
+	for _, c := range cases {
+		word, bit := tickBitmapPosition(c.tick, c.tickSpacing)
+		if word != c.word || bit != c.bit {
+			t.Errorf("tick %d with spacing %d: expected word %d and bit %d, got %d and %d", c.tick, c.tickSpacing, c.word, c.bit, word, bit)
+		}
+	}
+}
+
+func TestReservesInRange(t *testing.T) {
+	const liquidity = 1e18
+	sqrtPrice := tickToSqrtPrice(-1000)
+	sqrtPriceLower := sqrtPrice * math.Sqrt(0.98)
+	sqrtPriceUpper := sqrtPrice * math.Sqrt(1.02)
+	// Initialized ticks inside the price range of ±2% around tick -1000.
+	sqrtPriceAbove := tickToSqrtPrice(-900)
+	sqrtPriceBelow := tickToSqrtPrice(-1100)
+
+	cases := []struct {
+		name    string
+		tick    int
+		ticks   []tickLiquidity
+		amount0 float64
+		amount1 float64
+	}{
+		{
+			name:    "no initialized ticks",
+			tick:    -1000,
+			amount0: liquidity * (1/sqrtPrice - 1/sqrtPriceUpper),
+			amount1: liquidity * (sqrtPrice - sqrtPriceLower),
+		},
+		{
+			name:    "position ends above the current tick",
+			tick:    -1000,
+			ticks:   []tickLiquidity{{tick: -900, liquidityNet: -liquidity / 2}},
+			amount0: liquidity*(1/sqrtPrice-1/sqrtPriceAbove) + liquidity/2*(1/sqrtPriceAbove-1/sqrtPriceUpper),
+			amount1: liquidity * (sqrtPrice - sqrtPriceLower),
+		},
+		{
+			name:    "position starts below the current tick",
+			tick:    -1000,
+			ticks:   []tickLiquidity{{tick: -1100, liquidityNet: liquidity / 2}},
+			amount0: liquidity * (1/sqrtPrice - 1/sqrtPriceUpper),
+			amount1: liquidity*(sqrtPrice-sqrtPriceBelow) + liquidity/2*(sqrtPriceBelow-sqrtPriceLower),
+		},
+		{
+			// A tick at the current tick has already been crossed upwards, so its liquidityNet
+			// is removed when the price moves down and it is ignored when the price moves up.
+			name:    "position starts at the current tick",
+			tick:    -1000,
+			ticks:   []tickLiquidity{{tick: -1000, liquidityNet: liquidity / 2}},
+			amount0: liquidity * (1/sqrtPrice - 1/sqrtPriceUpper),
+			amount1: liquidity / 2 * (sqrtPrice - sqrtPriceLower),
+		},
+		{
+			name: "unsorted ticks on both sides and outside the range",
+			tick: -1000,
+			ticks: []tickLiquidity{
+				{tick: 0, liquidityNet: -liquidity},
+				{tick: -900, liquidityNet: -liquidity / 2},
+				{tick: -2000, liquidityNet: liquidity},
+				{tick: -1100, liquidityNet: liquidity / 4},
+			},
+			amount0: liquidity*(1/sqrtPrice-1/sqrtPriceAbove) + liquidity/2*(1/sqrtPriceAbove-1/sqrtPriceUpper),
+			amount1: liquidity*(sqrtPrice-sqrtPriceBelow) + liquidity*3/4*(sqrtPriceBelow-sqrtPriceLower),
+		},
+	}
+	for _, c := range cases {
+		amount0, amount1 := reservesInRange(sqrtPrice, c.tick, liquidity, c.ticks, sqrtPriceLower, sqrtPriceUpper)
+		if !almostEqual(amount0, c.amount0) || !almostEqual(amount1, c.amount1) {
+			t.Errorf("%s: expected amounts %v and %v, got %v and %v", c.name, c.amount0, c.amount1, amount0, amount1)
+		}
+	}
+}
@@ -272,6 +272,10 @@ func (r *DiaResolver) GetChart(ctx context.Context, args struct {
 		{
 			filterPoints = queryhelper.FilterMEDIR(tradeBlocks, asset, int(blockSizeSeconds))
 		}
+	case "twap":
+		{
+			filterPoints = queryhelper.FilterTWAP(tradeBlocks, asset, int(blockSizeSeconds))
+		}
 	case "vol":
 		{
 			filterPoints = queryhelper.FilterVOL(tradeBlocks, asset, int(blockSizeSeconds))
@@ -14,6 +14,7 @@ import (
 	"github.com/diadata-org/diadata/internal/pkg/indexCalculationService"
 
 	"github.com/diadata-org/diadata/pkg/dia"
+	queryhelper "github.com/diadata-org/diadata/pkg/dia/helpers/queryHelper"
 	"github.com/diadata-org/diadata/pkg/http/restApi"
 	models "github.com/diadata-org/diadata/pkg/model"
 	"github.com/diadata-org/diadata/pkg/utils"
@@ -27,7 +28,7 @@ var DECIMALS_CACHE = make(map[dia.Asset]uint8)
 
 type Env struct {
 	DataStore models.Datastore
-	RelDB     models.RelDB
+	RelDB     models.RelDatastore
 }
 
 // PostSupply deprecated? TO DO
@@ -108,6 +109,21 @@ func (env *Env) SetQuotation(c *gin.Context) {
 	}
 }
 
+// PostPairsReload makes all collectors of @exchange diff their running pair scrapers
+// against the pairs in the database immediately.
+func (env *Env) PostPairsReload(c *gin.Context) {
+	exchange := c.Param("exchange")
+	if _, err := env.RelDB.GetExchange(exchange); err != nil {
+		restApi.SendError(c, http.StatusNotFound, errors.New("unknown exchange "+exchange))
+		return
+	}
+	if err := env.DataStore.PublishPairsReload(exchange); err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, exchange)
+}
+
 // GetAssetQuotation returns quotation of asset with highest market cap among
 // all assets with symbol ticker @symbol.
 func (env *Env) GetAssetQuotation(c *gin.Context) {
@@ -154,6 +170,13 @@ func (env *Env) GetAssetQuotation(c *gin.Context) {
 	quotationExtended.Time = quotation.Time
 	quotationExtended.Source = quotation.Source
 
+	// Assets priced along the price graph come with their path from a USD anchor.
+	pricePath, err := env.DataStore.GetPricePathCache(asset)
+	if err == nil {
+		quotationExtended.PricePath = pricePath.Path
+		quotationExtended.PathConfidence = pricePath.Confidence
+	}
+
 	c.JSON(http.StatusOK, quotationExtended)
 
 }
@@ -234,7 +257,7 @@ func (env *Env) GetPaxgQuotationGrams(c *gin.Context) {
 // GetSupply returns latest supply of token with @symbol
 func (env *Env) GetSupply(c *gin.Context) {
 	symbol := c.Param("symbol")
-	s, err := env.DataStore.GetLatestSupply(symbol, &env.RelDB)
+	s, err := env.DataStore.GetLatestSupply(symbol, env.RelDB)
 	if err != nil {
 		if errors.Is(err, redis.Nil) {
 			restApi.SendError(c, http.StatusNotFound, err)
@@ -319,7 +342,7 @@ func (env *Env) GetSupplies(c *gin.Context) {
 		endtime = time.Unix(endtimeInt, 0)
 	}
 
-	s, err := env.DataStore.GetSupply(symbol, starttime, endtime, &env.RelDB)
+	s, err := env.DataStore.GetSupply(symbol, starttime, endtime, env.RelDB)
 	if len(s) == 0 {
 		c.JSON(http.StatusOK, make([]string, 0))
 		return
@@ -446,6 +469,8 @@ func (env *Env) GetExchanges(c *gin.Context) {
 }
 
 // GetAssetChartPoints queries for filter points of asset given by address and blockchain.
+// For @filter twap, the time weighted average price is computed from raw trades in windows
+// of size given by the query parameter blockDuration in seconds.
 func (env *Env) GetAssetChartPoints(c *gin.Context) {
 	filter := c.Param("filter")
 	blockchain := c.Param("blockchain")
@@ -493,6 +518,11 @@ func (env *Env) GetAssetChartPoints(c *gin.Context) {
 		endtime = time.Unix(endtimeInt, 0)
 	}
 
+	if filter == "twap" {
+		env.getAssetTWAPPoints(c, address, blockchain, exchange, starttime, endtime)
+		return
+	}
+
 	p, err := env.DataStore.GetFilterPointsAsset(filter, exchange, address, blockchain, starttime, endtime)
 	if err != nil {
 		restApi.SendError(c, http.StatusInternalServerError, err)
@@ -501,6 +531,48 @@ func (env *Env) GetAssetChartPoints(c *gin.Context) {
 	}
 }
 
+// getAssetTWAPPoints computes TWAP filter points of the asset given by address and blockchain from raw trades.
+func (env *Env) getAssetTWAPPoints(c *gin.Context, address string, blockchain string, exchange string, starttime time.Time, endtime time.Time) {
+	blockDuration := int64(dia.BlockSizeSeconds)
+	if blockDurationStr := c.Query("blockDuration"); blockDurationStr != "" {
+		var err error
+		blockDuration, err = strconv.ParseInt(blockDurationStr, 10, 64)
+		if err != nil || blockDuration <= 0 {
+			restApi.SendError(c, http.StatusBadRequest, errors.New("blockDuration must be a positive integer"))
+			return
+		}
+	}
+	// As in GraphQL GetChart, at most 1000 windows are computed.
+	if endtime.After(time.Now()) {
+		endtime = time.Now()
+	}
+	maxStarttime := endtime.Add(-time.Duration(blockDuration*1000) * time.Second)
+	if starttime.Before(maxStarttime) {
+		starttime = maxStarttime
+	}
+
+	asset, err := env.RelDB.GetAsset(address, blockchain)
+	if err != nil {
+		restApi.SendError(c, http.StatusNotFound, err)
+		return
+	}
+	var exchanges []string
+	if exchange != "" {
+		exchanges = append(exchanges, exchange)
+	}
+	trades, err := env.DataStore.GetTradesByExchanges(asset, exchanges, starttime, endtime)
+	if err != nil && !errors.Is(err, models.ErrNoTradesFound) {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	if len(trades) == 0 {
+		c.JSON(http.StatusOK, []dia.FilterPoint{})
+		return
+	}
+	tradeBlocks := queryhelper.NewBlockGenerator(trades).GenerateSize(blockDuration)
+	c.JSON(http.StatusOK, queryhelper.FilterTWAP(tradeBlocks, asset, int(blockDuration)))
+}
+
 // GetChartPoints godoc
 // @Param   scale      query   string     false       "scale 5m 30m 1h 4h 1d 1w"
 func (env *Env) GetChartPoints(c *gin.Context) {
@@ -1609,7 +1681,7 @@ func (env *Env) GetLastTrades(c *gin.Context) {
 	symbol := c.Param("symbol")
 
 	// First get asset with @symbol with largest market cap.
-	topAsset, err := env.DataStore.GetTopAssetByVolume(symbol, &env.RelDB)
+	topAsset, err := env.DataStore.GetTopAssetByVolume(symbol, env.RelDB)
 	if err != nil {
 		restApi.SendError(c, http.StatusNotFound, err)
 	}
@@ -1794,6 +1866,51 @@ func (env *Env) GetAllBlockchains(c *gin.Context) {
 	}
 }
 
+// GetAssetEquivalences returns all asset equivalences used for mapping bridged assets to their canonical assets.
+func (env *Env) GetAssetEquivalences(c *gin.Context) {
+	equivalences, err := env.RelDB.GetAllAssetEquivalences()
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+	} else {
+		c.JSON(http.StatusOK, equivalences)
+	}
+}
+
+// GetOrderBookMetrics returns mid price, spread and the depth within ±depthPercent percent of the mid price
+// of the order book of @pair on @exchange. The latest order book stored within 10 minutes before
+// the optional unix @timestamp is used.
+func (env *Env) GetOrderBookMetrics(c *gin.Context) {
+	exchange := c.Param("exchange")
+	pair := c.Param("pair")
+
+	depthPercent, err := strconv.ParseFloat(c.DefaultQuery("depthPercent", "2"), 64)
+	if err != nil || depthPercent <= 0 {
+		restApi.SendError(c, http.StatusNotAcceptable, errors.New("invalid depthPercent"))
+		return
+	}
+	timestamp := time.Now()
+	if timestampStr := c.Query("timestamp"); timestampStr != "" {
+		timestampInt, err := strconv.ParseInt(timestampStr, 10, 64)
+		if err != nil {
+			restApi.SendError(c, http.StatusNotAcceptable, err)
+			return
+		}
+		timestamp = time.Unix(timestampInt, 0)
+	}
+
+	orderBooks, err := env.DataStore.GetOrderBookInflux(exchange, pair, timestamp.Add(-10*time.Minute), timestamp.Add(time.Second))
+	if err != nil || len(orderBooks) == 0 {
+		restApi.SendError(c, http.StatusNotFound, errors.New("no order book found"))
+		return
+	}
+	metrics, err := orderBooks[0].Metrics(depthPercent)
+	if err != nil {
+		restApi.SendError(c, http.StatusInternalServerError, err)
+		return
+	}
+	c.JSON(http.StatusOK, metrics)
+}
+
 // -----------------------------------------------------------------------------
 // NFT
 // -----------------------------------------------------------------------------
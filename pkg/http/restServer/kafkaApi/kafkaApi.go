@@ -20,8 +20,9 @@ var (
 // @hello
 // returns some kafka messages
 type resultApi struct {
-	Offset   int64         `json:"offset"`
-	Messages []interface{} `json:"messages"`
+	Partition int           `json:"partition"`
+	Offset    int64         `json:"offset"`
+	Messages  []interface{} `json:"messages"`
 }
 
 type RestApi struct {
@@ -47,9 +48,16 @@ func getOffset(c *gin.Context) int64 {
 	return int64(offset)
 }
 
+// getPartition returns the partition given by the query parameter partition, which defaults to 0.
+// Partitioned topics, such as filtersBlock written by several filtersBlockServices, hold complete blocks per partition.
+func getPartition(c *gin.Context) int {
+	partition, _ := strconv.Atoi(c.Query("partition"))
+	return partition
+}
+
 func Process(c *gin.Context, topic int) {
 	elements, _ := strconv.Atoi(c.Query("elements"))
-	result, err := Apis[topic].Get(getOffset(c), elements)
+	result, err := Apis[topic].GetFromPartition(getPartition(c), getOffset(c), elements)
 	if err == nil {
 		c.JSON(http.StatusOK, result)
 	} else {
@@ -69,14 +77,19 @@ func Process(c *gin.Context, topic int) {
 // @Router /testapi/get-string-by-int/{some_id} [get]
 
 func (s *RestApi) Get(offset int64, elements int) (map[string]interface{}, error) {
+	return s.GetFromPartition(0, offset, elements)
+}
+
+// GetFromPartition returns up to @elements messages of @partition starting at @offset.
+func (s *RestApi) GetFromPartition(partition int, offset int64, elements int) (map[string]interface{}, error) {
 
 	if (elements == 0) || (elements > 100) {
 		elements = 100
 	}
 
-	result := &resultApi{}
+	result := &resultApi{Partition: partition}
 
-	maxOffset, err := kafkaHelper.ReadOffset(s.topic)
+	maxOffset, err := kafkaHelper.ReadPartitionOffset(s.topic, partition)
 
 	if err != nil {
 		return nil, err
@@ -98,7 +111,7 @@ func (s *RestApi) Get(offset int64, elements int) (map[string]interface{}, error
 	}
 	log.Printf("Get: maxOffset %v offset:%v nbElements:%v ", maxOffset, offset, nbElements)
 
-	element, err := kafkaHelper.GetElements(s.topic, offset, nbElements)
+	element, err := kafkaHelper.GetPartitionElements(s.topic, partition, offset, nbElements)
 
 	if err != nil {
 		return nil, err
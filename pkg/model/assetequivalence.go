@@ -0,0 +1,52 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// SetAssetEquivalence stores @equivalence in postgres. An existing equivalence for the same
+// asset and exchange is overwritten.
+func (rdb *RelDB) SetAssetEquivalence(equivalence dia.AssetEquivalence) error {
+	query := fmt.Sprintf(`INSERT INTO %s (blockchain,address,exchange,canonical_symbol,canonical_blockchain,canonical_address) VALUES ($1,$2,$3,$4,$5,$6)
+	ON CONFLICT (blockchain,address,exchange)
+	DO UPDATE SET canonical_symbol=EXCLUDED.canonical_symbol,canonical_blockchain=EXCLUDED.canonical_blockchain,canonical_address=EXCLUDED.canonical_address`, assetEquivalenceTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query,
+		equivalence.Blockchain,
+		equivalence.Address,
+		equivalence.Exchange,
+		equivalence.Canonical.Symbol,
+		equivalence.Canonical.Blockchain,
+		equivalence.Canonical.Address,
+	)
+	return err
+}
+
+// GetAllAssetEquivalences returns all asset equivalences stored in postgres.
+func (rdb *RelDB) GetAllAssetEquivalences() (equivalences []dia.AssetEquivalence, err error) {
+	query := fmt.Sprintf("SELECT blockchain,address,exchange,canonical_symbol,canonical_blockchain,canonical_address FROM %s ORDER BY blockchain,address,exchange", assetEquivalenceTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var equivalence dia.AssetEquivalence
+		err = rows.Scan(
+			&equivalence.Blockchain,
+			&equivalence.Address,
+			&equivalence.Exchange,
+			&equivalence.Canonical.Symbol,
+			&equivalence.Canonical.Blockchain,
+			&equivalence.Canonical.Address,
+		)
+		if err != nil {
+			return
+		}
+		equivalences = append(equivalences, equivalence)
+	}
+	return
+}
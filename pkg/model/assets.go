@@ -500,6 +500,27 @@ func (rdb *RelDB) GetExchangePairSymbols(exchange string) (pairs []dia.ExchangeP
 	return
 }
 
+// GetVerifiedExchangePairSymbols returns symbol and foreign name of all verified pairs on @exchange.
+func (rdb *RelDB) GetVerifiedExchangePairSymbols(exchange string) (pairs []dia.ExchangePair, err error) {
+	query := fmt.Sprintf("SELECT symbol,foreignname FROM %s WHERE exchange=$1 AND verified=true", exchangepairTable)
+	var rows pgx.Rows
+	rows, err = rdb.postgresClient.Query(context.Background(), query, exchange)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		pair := dia.ExchangePair{Exchange: exchange, Verified: true}
+		err = rows.Scan(&pair.Symbol, &pair.ForeignName)
+		if err != nil {
+			return
+		}
+		pairs = append(pairs, pair)
+	}
+	return
+}
+
 // SetExchangePair adds @pair to exchangepair table.
 // If cache==true, it is also cached into redis
 func (rdb *RelDB) SetExchangePair(exchange string, pair dia.ExchangePair, cache bool) error {
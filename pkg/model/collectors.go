@@ -0,0 +1,44 @@
+package models
+
+import (
+	"errors"
+	"strconv"
+	"time"
+)
+
+func getKeyPairsReload(exchange string) string {
+	return "dia_pairsReload_" + exchange
+}
+
+// PublishPairsReload asks all collectors of @exchange to reload their pairs immediately.
+func (datastore *DB) PublishPairsReload(exchange string) error {
+	if datastore.redisClient == nil {
+		return errors.New("no redis client")
+	}
+	return datastore.redisClient.Publish(getKeyPairsReload(exchange), strconv.FormatInt(time.Now().Unix(), 10)).Err()
+}
+
+// SubscribePairsReload returns a channel which receives a value whenever a reload of the pairs
+// on @exchange is requested through PublishPairsReload.
+func (datastore *DB) SubscribePairsReload(exchange string) (<-chan struct{}, error) {
+	if datastore.redisClient == nil {
+		return nil, errors.New("no redis client")
+	}
+	pubsub := datastore.redisClient.Subscribe(getKeyPairsReload(exchange))
+	// Wait for the subscription to be confirmed, such that no request is missed afterwards.
+	if _, err := pubsub.Receive(); err != nil {
+		return nil, err
+	}
+	reload := make(chan struct{}, 1)
+	go func() {
+		for range pubsub.Channel() {
+			// Requests arriving during a pending reload are merged into it.
+			select {
+			case reload <- struct{}{}:
+			default:
+			}
+		}
+		close(reload)
+	}()
+	return reload, nil
+}
@@ -29,9 +29,9 @@ type Datastore interface {
 	SetBatchFiatPriceInflux(fqs []*FiatQuotation) error
 	SetSingleFiatPriceRedis(fiatQuotation *FiatQuotation) error
 
-	GetLatestSupply(string, *RelDB) (*dia.Supply, error)
+	GetLatestSupply(string, RelDatastore) (*dia.Supply, error)
 	GetSupplyCache(asset dia.Asset) (dia.Supply, error)
-	GetSupply(string, time.Time, time.Time, *RelDB) ([]dia.Supply, error)
+	GetSupply(string, time.Time, time.Time, RelDatastore) ([]dia.Supply, error)
 	SetSupply(supply *dia.Supply) error
 	GetSupplyInflux(dia.Asset, time.Time, time.Time) ([]dia.Supply, error)
 
@@ -59,6 +59,8 @@ type Datastore interface {
 	GetOldTradesFromInflux(table string, exchange string, verified bool, timeInit, timeFinal time.Time) ([]dia.Trade, error)
 	CopyInfluxMeasurements(dbOrigin string, dbDestination string, tableOrigin string, tableDestination string, timeInit time.Time, timeFinal time.Time) (int64, error)
 	DeleteInfluxMeasurement(dbName string, tableName string, timeInit time.Time, timeFinal time.Time) error
+	GetTradeCountsInflux(exchange string, starttime time.Time, endtime time.Time, binSize time.Duration) (map[string][]dia.TradeCount, error)
+	DeleteTradesInflux(table string, exchange string, foreignTradeID string, starttime time.Time, endtime time.Time) (int, error)
 
 	Flush() error
 	ExecuteRedisPipe() error
@@ -93,14 +95,26 @@ type Datastore interface {
 	AddAssetQuotationsToBatch(quotations []*AssetQuotation) error
 	SetAssetQuotationCache(quotation *AssetQuotation, check bool) (bool, error)
 	GetAssetQuotationCache(asset dia.Asset) (*AssetQuotation, error)
+	SetPricePathCache(pricePath *PricePath) error
+	GetPricePathCache(asset dia.Asset) (*PricePath, error)
 	GetAssetPriceUSDCache(asset dia.Asset) (price float64, err error)
-	GetTopAssetByMcap(symbol string, relDB *RelDB) (dia.Asset, error)
-	GetTopAssetByVolume(symbol string, relDB *RelDB) (topAsset dia.Asset, err error)
+	GetTopAssetByMcap(symbol string, relDB RelDatastore) (dia.Asset, error)
+	GetTopAssetByVolume(symbol string, relDB RelDatastore) (topAsset dia.Asset, err error)
 	GetAssetsWithVOLInflux(timeInit time.Time) ([]dia.Asset, error)
 
 	// DEX Pool  methods
 	SavePoolInflux(p dia.Pool) error
 	GetPoolInflux(poolAddress string, starttime time.Time, endtime time.Time) ([]dia.Pool, error)
+	SavePriceSanityInflux(count PriceSanityCount) error
+	GetPriceSanityInflux(exchange string, starttime time.Time, endtime time.Time) ([]PriceSanityCount, error)
+
+	// Spot order book methods
+	SaveOrderBookInflux(ob dia.OrderBook) error
+	GetOrderBookInflux(exchange string, foreignName string, starttime time.Time, endtime time.Time) ([]dia.OrderBook, error)
+
+	// Collector control methods
+	PublishPairsReload(exchange string) error
+	SubscribePairsReload(exchange string) (<-chan struct{}, error)
 
 	// Market Measures
 	GetAssetsMarketCap(asset dia.Asset) (float64, error)
@@ -202,6 +216,7 @@ const (
 	influxDbDefiRateTable                = "defiRate"
 	influxDbDefiStateTable               = "defiState"
 	influxDbDEXPoolTable                 = "DEXPools"
+	influxDbOrderBookTable               = "orderBooks"
 	influxDbCryptoIndexTable             = "cryptoindex"
 	influxDbCryptoIndexConstituentsTable = "cryptoindexconstituents"
 	influxDbGithubCommitTable            = "githubcommits"
@@ -209,6 +224,7 @@ const (
 	influxDBAssetQuotationsTable         = "assetQuotations"
 	influxDbBenchmarkedIndexTableName    = "benchmarkedIndexValues"
 	influxDbVwapFireflyTable             = "vwapFirefly"
+	influxDbPriceSanityTable             = "priceSanity"
 
 	influxDBDefaultURL = "http://influxdb:8086"
 )
@@ -371,6 +387,9 @@ func (datastore *DB) SaveTradeInfluxToTable(t *dia.Trade, table string) error {
 		"estimatedUSDPrice": t.EstimatedUSDPrice,
 		"foreignTradeID":    t.ForeignTradeID,
 	}
+	if t.PriceFlagged {
+		fields["priceFlagged"] = true
+	}
 
 	pt, err := clientInfluxdb.NewPoint(table, tags, fields, t.Time)
 	if err != nil {
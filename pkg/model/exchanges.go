@@ -89,9 +89,9 @@ func (datastore *DB) GetAvailablePairs(exchange string) ([]dia.ExchangePair, err
 }
 
 func (rdb *RelDB) SetExchange(exchange dia.Exchange) (err error) {
-	fields := fmt.Sprintf("INSERT INTO %s (name,centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay) VALUES ", exchangeTable)
-	values := "($1,$2,$3,NULLIF($4,''),$5,NULLIF($6,''),NULLIF($7,''),NULLIF($8,''),$9)"
-	conflict := " ON CONFLICT (name) DO UPDATE SET contract=NULLIF($4,''),rest_api=$6,ws_api=$7,pairs_api=$8,watchdog_delay=$9"
+	fields := fmt.Sprintf("INSERT INTO %s (name,centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay,scraper_type,init_code_hash,wait_time,swap_trades) VALUES ", exchangeTable)
+	values := "($1,$2,$3,NULLIF($4,''),$5,NULLIF($6,''),NULLIF($7,''),NULLIF($8,''),$9,NULLIF($10,''),NULLIF($11,''),$12,$13)"
+	conflict := " ON CONFLICT (name) DO UPDATE SET contract=NULLIF($4,''),rest_api=$6,ws_api=$7,pairs_api=$8,watchdog_delay=$9,scraper_type=NULLIF($10,''),init_code_hash=NULLIF($11,''),wait_time=$12,swap_trades=$13"
 
 	query := fields + values + conflict
 	_, err = rdb.postgresClient.Exec(context.Background(), query,
@@ -104,6 +104,10 @@ func (rdb *RelDB) SetExchange(exchange dia.Exchange) (err error) {
 		exchange.WsAPI,
 		exchange.PairsAPI,
 		exchange.WatchdogDelay,
+		exchange.ScraperType,
+		exchange.InitCodeHash,
+		exchange.WaitTime,
+		exchange.SwapTrades,
 	)
 	if err != nil {
 		return err
@@ -112,12 +116,16 @@ func (rdb *RelDB) SetExchange(exchange dia.Exchange) (err error) {
 }
 
 func (rdb *RelDB) GetExchange(name string) (exchange dia.Exchange, err error) {
-	query := fmt.Sprintf("SELECT centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay FROM %s WHERE name=$1", exchangeTable)
+	query := fmt.Sprintf("SELECT centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay,scraper_type,init_code_hash,wait_time,swap_trades FROM %s WHERE name=$1", exchangeTable)
 	var contract sql.NullString
 	var blockchainName sql.NullString
 	var restAPI sql.NullString
 	var wsAPI sql.NullString
 	var pairsAPI sql.NullString
+	var scraperType sql.NullString
+	var initCodeHash sql.NullString
+	var waitTime sql.NullInt64
+	var swapTrades sql.NullBool
 	err = rdb.postgresClient.QueryRow(context.Background(), query, name).Scan(
 		&exchange.Centralized,
 		&exchange.Bridge,
@@ -127,6 +135,10 @@ func (rdb *RelDB) GetExchange(name string) (exchange dia.Exchange, err error) {
 		&wsAPI,
 		&pairsAPI,
 		&exchange.WatchdogDelay,
+		&scraperType,
+		&initCodeHash,
+		&waitTime,
+		&swapTrades,
 	)
 	if err != nil {
 		return
@@ -146,13 +158,23 @@ func (rdb *RelDB) GetExchange(name string) (exchange dia.Exchange, err error) {
 	if pairsAPI.Valid {
 		exchange.PairsAPI = pairsAPI.String
 	}
+	if scraperType.Valid {
+		exchange.ScraperType = scraperType.String
+	}
+	if initCodeHash.Valid {
+		exchange.InitCodeHash = initCodeHash.String
+	}
+	if waitTime.Valid {
+		exchange.WaitTime = int(waitTime.Int64)
+	}
+	exchange.SwapTrades = swapTrades.Valid && swapTrades.Bool
 	exchange.Name = name
 	return
 }
 
 // GetAllExchanges returns all exchanges existent in the exchange table.
 func (rdb *RelDB) GetAllExchanges() (exchanges []dia.Exchange, err error) {
-	query := fmt.Sprintf("SELECT name,centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay FROM %s", exchangeTable)
+	query := fmt.Sprintf("SELECT name,centralized,bridge,contract,blockchain,rest_api,ws_api,pairs_api,watchdog_delay,scraper_type,init_code_hash,wait_time,swap_trades FROM %s", exchangeTable)
 	rows, err := rdb.postgresClient.Query(context.Background(), query)
 	if err != nil {
 		return []dia.Exchange{}, err
@@ -166,6 +188,10 @@ func (rdb *RelDB) GetAllExchanges() (exchanges []dia.Exchange, err error) {
 		var restAPI sql.NullString
 		var wsAPI sql.NullString
 		var pairsAPI sql.NullString
+		var scraperType sql.NullString
+		var initCodeHash sql.NullString
+		var waitTime sql.NullInt64
+		var swapTrades sql.NullBool
 		err := rows.Scan(
 			&exchange.Name,
 			&exchange.Centralized,
@@ -176,6 +202,10 @@ func (rdb *RelDB) GetAllExchanges() (exchanges []dia.Exchange, err error) {
 			&wsAPI,
 			&pairsAPI,
 			&exchange.WatchdogDelay,
+			&scraperType,
+			&initCodeHash,
+			&waitTime,
+			&swapTrades,
 		)
 		if err != nil {
 			return []dia.Exchange{}, err
@@ -195,6 +225,16 @@ func (rdb *RelDB) GetAllExchanges() (exchanges []dia.Exchange, err error) {
 		if pairsAPI.Valid {
 			exchange.PairsAPI = pairsAPI.String
 		}
+		if scraperType.Valid {
+			exchange.ScraperType = scraperType.String
+		}
+		if initCodeHash.Valid {
+			exchange.InitCodeHash = initCodeHash.String
+		}
+		if waitTime.Valid {
+			exchange.WaitTime = int(waitTime.Int64)
+		}
+		exchange.SwapTrades = swapTrades.Valid && swapTrades.Bool
 		exchanges = append(exchanges, exchange)
 	}
 
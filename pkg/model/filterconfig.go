@@ -0,0 +1,62 @@
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+// SetFilterConfig stores a filter config in postgres. An existing config for the same
+// filter name, asset and exchange is overwritten.
+func (rdb *RelDB) SetFilterConfig(filterConfig dia.FilterConfig) error {
+	query := fmt.Sprintf(`INSERT INTO %s (name,blockchain,address,exchange,memory,outlier_scale,min_trades,trust_weighted,max_volume_share,min_pool_liquidity_usd,discount_illiquid) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11)
+	ON CONFLICT (name,blockchain,address,exchange)
+	DO UPDATE SET memory=EXCLUDED.memory,outlier_scale=EXCLUDED.outlier_scale,min_trades=EXCLUDED.min_trades,trust_weighted=EXCLUDED.trust_weighted,max_volume_share=EXCLUDED.max_volume_share,min_pool_liquidity_usd=EXCLUDED.min_pool_liquidity_usd,discount_illiquid=EXCLUDED.discount_illiquid`, filterconfigTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query,
+		filterConfig.Name,
+		filterConfig.Blockchain,
+		filterConfig.Address,
+		filterConfig.Exchange,
+		filterConfig.Memory,
+		filterConfig.OutlierScale,
+		filterConfig.MinTrades,
+		filterConfig.TrustWeighted,
+		filterConfig.MaxVolumeShare,
+		filterConfig.MinPoolLiquidityUSD,
+		filterConfig.DiscountIlliquid,
+	)
+	return err
+}
+
+// GetAllFilterConfigs returns all filter configs stored in postgres.
+func (rdb *RelDB) GetAllFilterConfigs() (filterConfigs []dia.FilterConfig, err error) {
+	query := fmt.Sprintf("SELECT name,blockchain,address,exchange,memory,outlier_scale,min_trades,trust_weighted,max_volume_share,min_pool_liquidity_usd,discount_illiquid FROM %s", filterconfigTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var filterConfig dia.FilterConfig
+		err = rows.Scan(
+			&filterConfig.Name,
+			&filterConfig.Blockchain,
+			&filterConfig.Address,
+			&filterConfig.Exchange,
+			&filterConfig.Memory,
+			&filterConfig.OutlierScale,
+			&filterConfig.MinTrades,
+			&filterConfig.TrustWeighted,
+			&filterConfig.MaxVolumeShare,
+			&filterConfig.MinPoolLiquidityUSD,
+			&filterConfig.DiscountIlliquid,
+		)
+		if err != nil {
+			return
+		}
+		filterConfigs = append(filterConfigs, filterConfig)
+	}
+	return
+}
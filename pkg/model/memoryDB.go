@@ -0,0 +1,1126 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/utils"
+	"github.com/go-redis/redis"
+	influxModels "github.com/influxdata/influxdb1-client/models"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// MemoryDB is an in-memory implementation of Datastore. It is meant for tests and local runs
+// without redis and influx. Influx measurements and redis keys are kept in maps and slices,
+// all of which are guarded by a single mutex.
+// Lookups for cached values return redis.Nil if the value is missing, just like DB does.
+type MemoryDB struct {
+	mu sync.RWMutex
+
+	// influx measurements
+	trades            map[string][]dia.Trade
+	filters           []memoryFilterPoint
+	assetQuotations   map[string][]AssetQuotation
+	supplies          map[string][]dia.Supply
+	pools             []dia.Pool
+	priceSanity       []PriceSanityCount
+	orderBooks        []dia.OrderBook
+	fiatQuotations    []FiatQuotation
+	cvi               map[string][]dia.CviDataPoint
+	defiRates         []dia.DefiRate
+	defiStates        []dia.DefiProtocolState
+	foreignQuotations []ForeignQuotation
+	vwapFirefly       map[string][]memoryTimedValue
+	cryptoIndices     []CryptoIndex
+	constituents      []memoryIndexConstituent
+	benchmarkedIndex  []memoryBenchmarkedIndexValue
+	commits           []GithubCommit
+	stockQuotations   []StockQuotation
+
+	// redis keys
+	assetQuotationCache  map[string]AssetQuotation
+	pricePathCache       map[string]PricePath
+	supplyCache          map[string]dia.Supply
+	lastTradeTimes       map[string]time.Time
+	availablePairs       map[string][]dia.ExchangePair
+	quotations           map[string]Quotation
+	quotationsEUR        map[string]Quotation
+	diaTotalSupply       *float64
+	diaCirculatingSupply *float64
+	currencyChange       *Change
+	optionMeta           map[string]map[string]dia.OptionMeta
+	interestRates        map[string][]InterestRate
+	itinTokens           map[string]dia.ItinToken
+	defiProtocols        map[string]dia.DefiProtocol
+
+	// redis channels
+	pairsReloads map[string][]chan struct{}
+}
+
+type memoryFilterPoint struct {
+	Name     string
+	Asset    dia.Asset
+	Exchange string
+	Value    float64
+	Time     time.Time
+}
+
+type memoryTimedValue struct {
+	Value float64
+	Time  time.Time
+}
+
+type memoryIndexConstituent struct {
+	Constituent CryptoIndexConstituent
+	Index       dia.Asset
+	Time        time.Time
+}
+
+type memoryBenchmarkedIndexValue struct {
+	Tags   map[string]string
+	Fields map[string]interface{}
+	Time   time.Time
+}
+
+// NewMemoryDataStore returns an empty in-memory datastore.
+func NewMemoryDataStore() *MemoryDB {
+	return &MemoryDB{
+		trades:              make(map[string][]dia.Trade),
+		assetQuotations:     make(map[string][]AssetQuotation),
+		supplies:            make(map[string][]dia.Supply),
+		cvi:                 make(map[string][]dia.CviDataPoint),
+		vwapFirefly:         make(map[string][]memoryTimedValue),
+		assetQuotationCache: make(map[string]AssetQuotation),
+		pricePathCache:      make(map[string]PricePath),
+		supplyCache:         make(map[string]dia.Supply),
+		lastTradeTimes:      make(map[string]time.Time),
+		availablePairs:      make(map[string][]dia.ExchangePair),
+		quotations:          make(map[string]Quotation),
+		quotationsEUR:       make(map[string]Quotation),
+		optionMeta:          make(map[string]map[string]dia.OptionMeta),
+		interestRates:       make(map[string][]InterestRate),
+		itinTokens:          make(map[string]dia.ItinToken),
+		defiProtocols:       make(map[string]dia.DefiProtocol),
+		pairsReloads:        make(map[string][]chan struct{}),
+	}
+}
+
+// DatastoreBackendMemory is the value of the environment variable DATASTORE_BACKEND
+// selecting the in-memory datastores.
+const DatastoreBackendMemory = "memory"
+
+var (
+	sharedMemoryDB    *MemoryDB
+	sharedMemoryRelDB *MemoryRelDB
+	sharedMemoryOnce  sync.Once
+)
+
+// sharedMemoryDataStores returns the in-memory datastores shared by all callers in the process,
+// such that data written by one service is visible to all others, as with redis, influx and postgres.
+func sharedMemoryDataStores() (*MemoryDB, *MemoryRelDB) {
+	sharedMemoryOnce.Do(func() {
+		sharedMemoryDB = NewMemoryDataStore()
+		sharedMemoryRelDB = NewMemoryRelDataStore()
+	})
+	return sharedMemoryDB, sharedMemoryRelDB
+}
+
+// NewDataStoreFromEnv returns the shared in-memory datastore if DATASTORE_BACKEND is set to "memory"
+// and a datastore with redis and influx clients otherwise.
+func NewDataStoreFromEnv() (Datastore, error) {
+	if utils.Getenv("DATASTORE_BACKEND", "") == DatastoreBackendMemory {
+		ds, _ := sharedMemoryDataStores()
+		return ds, nil
+	}
+	return NewDataStore()
+}
+
+// NewRelDataStoreFromEnv returns the shared in-memory relational datastore if DATASTORE_BACKEND
+// is set to "memory" and a datastore with postgres client and redis cache otherwise.
+func NewRelDataStoreFromEnv() (RelDatastore, error) {
+	if utils.Getenv("DATASTORE_BACKEND", "") == DatastoreBackendMemory {
+		_, rdb := sharedMemoryDataStores()
+		return rdb, nil
+	}
+	return NewRelDataStore()
+}
+
+// assetIdentifier returns the key under which data on @asset is stored.
+func assetIdentifier(asset dia.Asset) string {
+	return asset.Blockchain + "_" + asset.Address
+}
+
+// influxTime formats @t the way influx returns timestamps.
+func influxTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339Nano)
+}
+
+// SetInfluxClient is a no-op.
+func (mdb *MemoryDB) SetInfluxClient(url string) {}
+
+// Flush is a no-op, as all writes are immediate.
+func (mdb *MemoryDB) Flush() error {
+	return nil
+}
+
+// ExecuteRedisPipe is a no-op, as all writes are immediate.
+func (mdb *MemoryDB) ExecuteRedisPipe() error {
+	return nil
+}
+
+// FlushRedisPipe is a no-op, as all writes are immediate.
+func (mdb *MemoryDB) FlushRedisPipe() error {
+	return nil
+}
+
+// ------------------------------------------------------------------------------
+// TRADES
+// ------------------------------------------------------------------------------
+
+// SaveTradeInflux stores @t in the trades table.
+func (mdb *MemoryDB) SaveTradeInflux(t *dia.Trade) error {
+	return mdb.SaveTradeInfluxToTable(t, influxDbTradesTable)
+}
+
+// SaveTradeInfluxToTable stores @t in @table. Trades in a table are kept sorted by time.
+func (mdb *MemoryDB) SaveTradeInfluxToTable(t *dia.Trade, table string) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.insertTrade(table, *t)
+	return nil
+}
+
+// insertTrade must be called with the write lock held.
+func (mdb *MemoryDB) insertTrade(table string, t dia.Trade) {
+	trades := mdb.trades[table]
+	index := sort.Search(len(trades), func(i int) bool {
+		return trades[i].Time.After(t.Time)
+	})
+	trades = append(trades, dia.Trade{})
+	copy(trades[index+1:], trades[index:])
+	trades[index] = t
+	mdb.trades[table] = trades
+}
+
+// selectTrades returns all trades from @table for which @match is true, ordered by time.
+func (mdb *MemoryDB) selectTrades(table string, match func(t *dia.Trade) bool) (trades []dia.Trade) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	for i := range mdb.trades[table] {
+		if match(&mdb.trades[table][i]) {
+			trades = append(trades, mdb.trades[table][i])
+		}
+	}
+	return
+}
+
+// GetTradeInflux returns the latest trade of @asset on @exchange in the time-range [endtime-window, endtime).
+func (mdb *MemoryDB) GetTradeInflux(asset dia.Asset, exchange string, endtime time.Time, window time.Duration) (*dia.Trade, error) {
+	starttime := endtime.Add(-window)
+	trades := mdb.selectTrades(influxDbTradesTable, func(t *dia.Trade) bool {
+		return assetIdentifier(t.QuoteToken) == assetIdentifier(asset) &&
+			(exchange == "" || t.Source == exchange) &&
+			!t.Time.Before(starttime) && t.Time.Before(endtime)
+	})
+	if len(trades) == 0 {
+		return &dia.Trade{}, errors.New("no trade in time range")
+	}
+	return &trades[len(trades)-1], nil
+}
+
+// GetOldTradesFromInflux returns all trades from @table in the time-range [timeInit, timeFinal), ordered by time.
+// @verified is ignored, as trades are always returned with all fields set.
+func (mdb *MemoryDB) GetOldTradesFromInflux(table string, exchange string, verified bool, timeInit, timeFinal time.Time) ([]dia.Trade, error) {
+	trades := mdb.selectTrades(table, func(t *dia.Trade) bool {
+		return (exchange == "" || t.Source == exchange) && !t.Time.Before(timeInit) && t.Time.Before(timeFinal)
+	})
+	if len(trades) == 0 {
+		return []dia.Trade{}, errors.New("no trades in time range")
+	}
+	return trades, nil
+}
+
+// GetTradesByExchanges returns all trades of @asset on @exchanges in the time-range [startTime, endTime].
+func (mdb *MemoryDB) GetTradesByExchanges(asset dia.Asset, exchanges []string, startTime, endTime time.Time) ([]dia.Trade, error) {
+	return mdb.GetTradesByExchangesFull(asset, exchanges, false, startTime, endTime)
+}
+
+// GetTradesByExchangesFull returns all trades of @asset on @exchanges in the time-range [startTime, endTime].
+// If @exchanges is empty, trades from all exchanges are returned.
+func (mdb *MemoryDB) GetTradesByExchangesFull(asset dia.Asset, exchanges []string, returnBasetoken bool, startTime, endTime time.Time) ([]dia.Trade, error) {
+	trades := mdb.selectTrades(influxDbTradesTable, func(t *dia.Trade) bool {
+		return assetIdentifier(t.QuoteToken) == assetIdentifier(asset) &&
+			(len(exchanges) == 0 || utils.Contains(&exchanges, t.Source)) &&
+			t.EstimatedUSDPrice > 0 &&
+			!t.Time.Before(startTime) && !t.Time.After(endTime)
+	})
+	if len(trades) == 0 {
+		return nil, ErrNoTradesFound
+	}
+	return trades, nil
+}
+
+// GetTradesByExchangesBatched returns all trades of @asset on @exchanges in the time-ranges (startTimes[i], endTimes[i]].
+func (mdb *MemoryDB) GetTradesByExchangesBatched(asset dia.Asset, exchanges []string, startTimes, endTimes []time.Time) ([]dia.Trade, error) {
+	return mdb.GetTradesByExchangesBatchedFull(asset, exchanges, false, startTimes, endTimes)
+}
+
+// GetTradesByExchangesBatchedFull returns all trades of @asset on @exchanges in the time-ranges (startTimes[i], endTimes[i]].
+func (mdb *MemoryDB) GetTradesByExchangesBatchedFull(asset dia.Asset, exchanges []string, returnBasetoken bool, startTimes, endTimes []time.Time) ([]dia.Trade, error) {
+	if len(startTimes) != len(endTimes) {
+		return []dia.Trade{}, errors.New("number of start times must equal number of end times.")
+	}
+	var trades []dia.Trade
+	for i := range startTimes {
+		trades = append(trades, mdb.selectTrades(influxDbTradesTable, func(t *dia.Trade) bool {
+			return assetIdentifier(t.QuoteToken) == assetIdentifier(asset) &&
+				(len(exchanges) == 0 || utils.Contains(&exchanges, t.Source)) &&
+				t.EstimatedUSDPrice > 0 &&
+				t.Time.After(startTimes[i]) && !t.Time.After(endTimes[i])
+		})...)
+	}
+	if len(trades) == 0 {
+		return nil, ErrNoTradesFound
+	}
+	return trades, nil
+}
+
+// GetAllTrades returns at most @maxTrades trades with timestamp > @t.
+func (mdb *MemoryDB) GetAllTrades(t time.Time, maxTrades int) ([]dia.Trade, error) {
+	trades := mdb.selectTrades(influxDbTradesTable, func(trade *dia.Trade) bool {
+		return trade.Time.After(t)
+	})
+	if len(trades) > maxTrades {
+		trades = trades[:maxTrades]
+	}
+	return trades, nil
+}
+
+// GetLastTrades returns the last @maxTrades of @asset on @exchange from the past 30 days, latest first.
+// If exchange is empty string it returns trades from all exchanges.
+func (mdb *MemoryDB) GetLastTrades(asset dia.Asset, exchange string, maxTrades int, fullAsset bool) ([]dia.Trade, error) {
+	now := time.Now()
+	trades := mdb.selectTrades(influxDbTradesTable, func(t *dia.Trade) bool {
+		return assetIdentifier(t.QuoteToken) == assetIdentifier(asset) &&
+			(exchange == "" || t.Source == exchange) &&
+			t.EstimatedUSDPrice > 0 &&
+			t.Time.Before(now) && t.Time.After(now.AddDate(0, 0, -30))
+	})
+	if len(trades) == 0 {
+		return nil, errors.New("Empty response for " + asset.Symbol + " on " + exchange)
+	}
+	var r []dia.Trade
+	for i := len(trades) - 1; i >= 0 && len(r) < maxTrades; i-- {
+		r = append(r, trades[i])
+	}
+	return r, nil
+}
+
+// GetFirstTradeDate returns the timestamp of the first trade in @table.
+func (mdb *MemoryDB) GetFirstTradeDate(table string) (time.Time, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	if len(mdb.trades[table]) == 0 {
+		return time.Time{}, errors.New("no trade found")
+	}
+	return mdb.trades[table][0].Time, nil
+}
+
+// CopyInfluxMeasurements copies all trades from @tableOrigin to @tableDestination in the time-range (timeInit, timeFinal].
+// Database names are ignored, as there is only one in-memory database.
+func (mdb *MemoryDB) CopyInfluxMeasurements(dbOrigin string, dbDestination string, tableOrigin string, tableDestination string, timeInit time.Time, timeFinal time.Time) (int64, error) {
+	trades := mdb.selectTrades(tableOrigin, func(t *dia.Trade) bool {
+		return t.Time.After(timeInit) && !t.Time.After(timeFinal)
+	})
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	for _, t := range trades {
+		mdb.insertTrade(tableDestination, t)
+	}
+	return int64(len(trades)), nil
+}
+
+// DeleteInfluxMeasurement deletes all trades from @tableName in the time-range (timeInit, timeFinal].
+func (mdb *MemoryDB) DeleteInfluxMeasurement(dbName string, tableName string, timeInit time.Time, timeFinal time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	var trades []dia.Trade
+	for _, t := range mdb.trades[tableName] {
+		if !t.Time.After(timeInit) || t.Time.After(timeFinal) {
+			trades = append(trades, t)
+		}
+	}
+	mdb.trades[tableName] = trades
+	return nil
+}
+
+// GetTradeCountsInflux returns the number of trades per pair on @exchange in bins of size @binSize
+// in the time-range [@starttime, @endtime). The bins are aligned to multiples of @binSize.
+// Pairs without trades in the time-range are omitted.
+func (mdb *MemoryDB) GetTradeCountsInflux(exchange string, starttime time.Time, endtime time.Time, binSize time.Duration) (map[string][]dia.TradeCount, error) {
+	trades := mdb.selectTrades(influxDbTradesTable, func(t *dia.Trade) bool {
+		return t.Source == exchange && !t.Time.Before(starttime) && t.Time.Before(endtime)
+	})
+	firstBin := time.Unix(0, starttime.UnixNano()-starttime.UnixNano()%int64(binSize)).UTC()
+	numBins := int((endtime.Sub(firstBin) + binSize - 1) / binSize)
+	counts := make(map[string][]dia.TradeCount)
+	for _, t := range trades {
+		if _, ok := counts[t.Pair]; !ok {
+			counts[t.Pair] = make([]dia.TradeCount, numBins)
+			for i := range counts[t.Pair] {
+				counts[t.Pair][i].Time = firstBin.Add(time.Duration(i) * binSize)
+			}
+		}
+		counts[t.Pair][int(t.Time.Sub(firstBin)/binSize)].Count++
+	}
+	return counts, nil
+}
+
+// DeleteTradesInflux deletes all trades with @foreignTradeID on @exchange in the time-range [@starttime, @endtime] from @table.
+func (mdb *MemoryDB) DeleteTradesInflux(table string, exchange string, foreignTradeID string, starttime time.Time, endtime time.Time) (int, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	var trades []dia.Trade
+	for _, t := range mdb.trades[table] {
+		if t.Source == exchange && t.ForeignTradeID == foreignTradeID && !t.Time.Before(starttime) && !t.Time.After(endtime) {
+			continue
+		}
+		trades = append(trades, t)
+	}
+	numDeleted := len(mdb.trades[table]) - len(trades)
+	mdb.trades[table] = trades
+	return numDeleted, nil
+}
+
+// GetLastTradeTimeForExchange returns the time of the last trade of @asset on @exchange.
+func (mdb *MemoryDB) GetLastTradeTimeForExchange(asset dia.Asset, exchange string) (*time.Time, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	t, ok := mdb.lastTradeTimes[getKeyLastTradeTimeForExchange(asset, exchange)]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return &t, nil
+}
+
+// SetLastTradeTimeForExchange stores the time of the last trade of @asset on @exchange.
+func (mdb *MemoryDB) SetLastTradeTimeForExchange(asset dia.Asset, exchange string, t time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.lastTradeTimes[getKeyLastTradeTimeForExchange(asset, exchange)] = time.Unix(t.Unix(), 0)
+	return nil
+}
+
+// SetAvailablePairs stores @pairs of @exchange.
+func (mdb *MemoryDB) SetAvailablePairs(exchange string, pairs []dia.ExchangePair) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.availablePairs[exchange] = append([]dia.ExchangePair{}, pairs...)
+	return nil
+}
+
+// GetAvailablePairs returns the pairs of @exchange stored by SetAvailablePairs.
+func (mdb *MemoryDB) GetAvailablePairs(exchange string) ([]dia.ExchangePair, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	pairs, ok := mdb.availablePairs[exchange]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return append([]dia.ExchangePair{}, pairs...), nil
+}
+
+// ------------------------------------------------------------------------------
+// FILTERS
+// ------------------------------------------------------------------------------
+
+// SetFilter stores a filter point.
+func (mdb *MemoryDB) SetFilter(filter string, asset dia.Asset, exchange string, value float64, t time.Time) error {
+	return mdb.SaveFilterInflux(filter, asset, exchange, value, t)
+}
+
+// SaveFilterInflux stores a filter point.
+func (mdb *MemoryDB) SaveFilterInflux(filter string, asset dia.Asset, exchange string, value float64, t time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.filters = append(mdb.filters, memoryFilterPoint{
+		Name:     filter,
+		Asset:    asset,
+		Exchange: exchange,
+		Value:    value,
+		Time:     t,
+	})
+	return nil
+}
+
+// selectFilterPoints returns all filter points for which @match is true, latest first.
+func (mdb *MemoryDB) selectFilterPoints(match func(fp *memoryFilterPoint) bool) (filterPoints []memoryFilterPoint) {
+	mdb.mu.RLock()
+	for i := range mdb.filters {
+		if match(&mdb.filters[i]) {
+			filterPoints = append(filterPoints, mdb.filters[i])
+		}
+	}
+	mdb.mu.RUnlock()
+	sort.SliceStable(filterPoints, func(i, j int) bool {
+		return filterPoints[i].Time.After(filterPoints[j].Time)
+	})
+	return
+}
+
+// GetFilterPointsAsset returns the filter points of the asset given by @address and @blockchain
+// on @exchange in the time-range (starttime, endtime], in the format returned by influx.
+func (mdb *MemoryDB) GetFilterPointsAsset(filter string, exchange string, address string, blockchain string, starttime time.Time, endtime time.Time) (*Points, error) {
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == filter && fp.Exchange == exchange && fp.Asset.Address == address && fp.Asset.Blockchain == blockchain &&
+			fp.Time.After(starttime) && !fp.Time.After(endtime)
+	})
+	row := influxModels.Row{
+		Name:    influxDbFiltersTable,
+		Columns: []string{"time", "address", "blockchain", "exchange", "filter", "symbol", "value"},
+	}
+	for _, fp := range filterPoints {
+		row.Values = append(row.Values, []interface{}{
+			influxTime(fp.Time),
+			fp.Asset.Address,
+			fp.Asset.Blockchain,
+			fp.Exchange,
+			fp.Name,
+			fp.Asset.Symbol,
+			json.Number(strconv.FormatFloat(fp.Value, 'f', -1, 64)),
+		})
+	}
+	return &Points{DataPoints: influxResult(row)}, nil
+}
+
+// GetFilterPoints returns filter points from either a specific exchange or all exchanges in the
+// time-range (starttime, endtime), in the format returned by influx.
+// @symbol is mapped to the asset with the largest volume. @scale is ignored, i.e. raw filter points are returned.
+func (mdb *MemoryDB) GetFilterPoints(filter string, exchange string, symbol string, scale string, starttime time.Time, endtime time.Time) (*Points, error) {
+	topAsset, err := mdb.GetTopAssetByVolume(symbol, nil)
+	if err != nil {
+		return &Points{}, err
+	}
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == filter && fp.Exchange == exchange && assetIdentifier(fp.Asset) == assetIdentifier(topAsset) &&
+			fp.Time.After(starttime) && fp.Time.Before(endtime)
+	})
+	row := influxModels.Row{
+		Name:    influxDbFiltersTable,
+		Columns: []string{"time", "exchange", "filter", "symbol", "value"},
+	}
+	for _, fp := range filterPoints {
+		row.Values = append(row.Values, []interface{}{
+			influxTime(fp.Time),
+			fp.Exchange,
+			fp.Name,
+			fp.Asset.Symbol,
+			json.Number(strconv.FormatFloat(fp.Value, 'f', -1, 64)),
+		})
+	}
+	return &Points{DataPoints: influxResult(row)}, nil
+}
+
+// influxResult wraps @row into a query result. As influx, it omits the series if @row has no values.
+func influxResult(row influxModels.Row) []clientInfluxdb.Result {
+	if len(row.Values) == 0 {
+		return []clientInfluxdb.Result{{}}
+	}
+	return []clientInfluxdb.Result{{Series: []influxModels.Row{row}}}
+}
+
+// GetLastPriceBefore returns the first value of @filter for @asset on @exchange after @timestamp.
+// The naming and behaviour follow DB.GetLastPriceBefore.
+func (mdb *MemoryDB) GetLastPriceBefore(asset dia.Asset, filter string, exchange string, timestamp time.Time) (Price, error) {
+	now := time.Now()
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == filter && fp.Exchange == exchange && assetIdentifier(fp.Asset) == assetIdentifier(asset) &&
+			fp.Time.After(timestamp) && fp.Time.Before(now)
+	})
+	price := Price{Symbol: asset.Symbol, Name: asset.Name}
+	if len(filterPoints) > 0 {
+		first := filterPoints[len(filterPoints)-1]
+		price.Price = first.Value
+		price.Time = first.Time
+	}
+	return price, nil
+}
+
+// GetSymbols returns the symbols of all assets with filter points of dia.FilterKing on @exchange.
+// If @exchange is empty, filter points across all exchanges are considered.
+func (mdb *MemoryDB) GetSymbols(exchange string) ([]string, error) {
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == dia.FilterKing && fp.Exchange == exchange
+	})
+	var symbols []string
+	for _, fp := range filterPoints {
+		if !utils.Contains(&symbols, fp.Asset.Symbol) {
+			symbols = append(symbols, fp.Asset.Symbol)
+		}
+	}
+	return symbols, nil
+}
+
+// ------------------------------------------------------------------------------
+// VOLUMES
+// ------------------------------------------------------------------------------
+
+// sumFilterPoints returns the sum of all values of @filter for @asset on @exchange in the time-range (starttime, endtime).
+// @ok is false if there is no such filter point.
+func (mdb *MemoryDB) sumFilterPoints(asset dia.Asset, exchange string, filter string, starttime time.Time, endtime time.Time) (sum float64, ok bool) {
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == filter && fp.Exchange == exchange && assetIdentifier(fp.Asset) == assetIdentifier(asset) &&
+			fp.Time.After(starttime) && fp.Time.Before(endtime)
+	})
+	for _, fp := range filterPoints {
+		sum += fp.Value
+	}
+	return sum, len(filterPoints) > 0
+}
+
+// GetVolume returns the 24h trading volume of @asset across exchanges.
+func (mdb *MemoryDB) GetVolume(asset dia.Asset) (*float64, error) {
+	return mdb.Sum24HoursInflux(asset, "", volumeKey)
+}
+
+// Sum24HoursInflux returns the 24h volume of @asset on @exchange using the filter @filter.
+func (mdb *MemoryDB) Sum24HoursInflux(asset dia.Asset, exchange string, filter string) (*float64, error) {
+	now := time.Now()
+	sum, ok := mdb.sumFilterPoints(asset, exchange, filter, now.AddDate(0, 0, -1), now)
+	if !ok {
+		return nil, errors.New("empty response in Sum24HoursInflux")
+	}
+	return &sum, nil
+}
+
+// Get24HVolumePerExchange returns the volumes of @asset from the last 100 days, grouped by exchange.
+func (mdb *MemoryDB) Get24HVolumePerExchange(asset dia.Asset) ([]dia.ExchangeVolume, error) {
+	now := time.Now()
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == "VOL120" && assetIdentifier(fp.Asset) == assetIdentifier(asset) &&
+			fp.Time.After(now.AddDate(0, 0, -100)) && fp.Time.Before(now)
+	})
+	var exchangeVolumes []dia.ExchangeVolume
+	indices := make(map[string]int)
+	for _, fp := range filterPoints {
+		i, ok := indices[fp.Exchange]
+		if !ok {
+			i = len(exchangeVolumes)
+			indices[fp.Exchange] = i
+			exchangeVolumes = append(exchangeVolumes, dia.ExchangeVolume{Exchange: fp.Exchange})
+		}
+		exchangeVolumes[i].Volume += fp.Value
+	}
+	sort.Slice(exchangeVolumes, func(i, j int) bool {
+		return exchangeVolumes[i].Exchange < exchangeVolumes[j].Exchange
+	})
+	return exchangeVolumes, nil
+}
+
+// Sum24HoursExchange returns 0, as does DB.Sum24HoursExchange.
+func (mdb *MemoryDB) Sum24HoursExchange(exchange string) (float64, error) {
+	return 0, nil
+}
+
+// GetVolumeInflux returns the trade volume of @asset in the time range @starttime - @endtime.
+// If one of the times is zero, the volume of the last 24h is returned.
+func (mdb *MemoryDB) GetVolumeInflux(asset dia.Asset, starttime time.Time, endtime time.Time) (float64, error) {
+	if starttime.IsZero() || endtime.IsZero() {
+		endtime = time.Now()
+		starttime = endtime.AddDate(0, 0, -1)
+	}
+	volume, ok := mdb.sumFilterPoints(asset, "", "VOL120", starttime, endtime)
+	if !ok {
+		return 0, errors.New("parsing volume value from database")
+	}
+	return volume, nil
+}
+
+// ------------------------------------------------------------------------------
+// ASSET QUOTATIONS
+// ------------------------------------------------------------------------------
+
+// SetAssetPriceUSD stores the price of @asset.
+func (mdb *MemoryDB) SetAssetPriceUSD(asset dia.Asset, price float64, timestamp time.Time) error {
+	return mdb.SetAssetQuotation(&AssetQuotation{
+		Asset:  asset,
+		Price:  price,
+		Source: dia.Diadata,
+		Time:   timestamp,
+	})
+}
+
+// GetAssetPriceUSDLatest returns the latest price of @asset.
+func (mdb *MemoryDB) GetAssetPriceUSDLatest(asset dia.Asset) (price float64, err error) {
+	quotation, err := mdb.GetAssetQuotationLatest(asset)
+	if err != nil {
+		return
+	}
+	price = quotation.Price
+	return
+}
+
+// GetAssetPriceUSD returns the latest USD price of @asset before @timestamp.
+func (mdb *MemoryDB) GetAssetPriceUSD(asset dia.Asset, timestamp time.Time) (price float64, err error) {
+	quotation, err := mdb.GetAssetQuotation(asset, timestamp)
+	if err != nil {
+		return
+	}
+	price = quotation.Price
+	return
+}
+
+// AddAssetQuotationsToBatch stores @quotations without updating the cache.
+func (mdb *MemoryDB) AddAssetQuotationsToBatch(quotations []*AssetQuotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	for _, quotation := range quotations {
+		mdb.insertAssetQuotation(*quotation)
+	}
+	return nil
+}
+
+// insertAssetQuotation must be called with the write lock held.
+func (mdb *MemoryDB) insertAssetQuotation(quotation AssetQuotation) {
+	key := assetIdentifier(quotation.Asset)
+	quotations := mdb.assetQuotations[key]
+	index := sort.Search(len(quotations), func(i int) bool {
+		return quotations[i].Time.After(quotation.Time)
+	})
+	quotations = append(quotations, AssetQuotation{})
+	copy(quotations[index+1:], quotations[index:])
+	quotations[index] = quotation
+	mdb.assetQuotations[key] = quotations
+}
+
+// SetAssetQuotation stores @quotation and writes it to the cache.
+func (mdb *MemoryDB) SetAssetQuotation(quotation *AssetQuotation) error {
+	mdb.mu.Lock()
+	mdb.insertAssetQuotation(*quotation)
+	mdb.mu.Unlock()
+	_, err := mdb.SetAssetQuotationCache(quotation, false)
+	return err
+}
+
+// GetAssetQuotationLatest returns the latest full quotation for @asset.
+func (mdb *MemoryDB) GetAssetQuotationLatest(asset dia.Asset) (*AssetQuotation, error) {
+	quotation, err := mdb.GetAssetQuotationCache(asset)
+	if err == nil {
+		return quotation, nil
+	}
+	return mdb.GetAssetQuotation(asset, time.Now())
+}
+
+// GetAssetQuotation returns the latest full quotation for @asset at or before @timestamp.
+func (mdb *MemoryDB) GetAssetQuotation(asset dia.Asset, timestamp time.Time) (*AssetQuotation, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	quotations := mdb.assetQuotations[assetIdentifier(asset)]
+	index := sort.Search(len(quotations), func(i int) bool {
+		return quotations[i].Time.After(timestamp)
+	})
+	if index == 0 {
+		return &AssetQuotation{}, errors.New("no assetQuotation in influx")
+	}
+	quotation := quotations[index-1]
+	return &quotation, nil
+}
+
+// SetAssetQuotationCache stores @quotation in the cache.
+// If @check is true, it is only stored if there is no more recent quotation in the cache.
+func (mdb *MemoryDB) SetAssetQuotationCache(quotation *AssetQuotation, check bool) (bool, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	key := assetIdentifier(quotation.Asset)
+	if check {
+		if cachestate, ok := mdb.assetQuotationCache[key]; ok && quotation.Time.Before(cachestate.Time) {
+			return false, nil
+		}
+	}
+	mdb.assetQuotationCache[key] = *quotation
+	return true, nil
+}
+
+// GetAssetQuotationCache returns the latest quotation for @asset from the cache.
+func (mdb *MemoryDB) GetAssetQuotationCache(asset dia.Asset) (*AssetQuotation, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	quotation, ok := mdb.assetQuotationCache[assetIdentifier(asset)]
+	if !ok {
+		return &AssetQuotation{}, redis.Nil
+	}
+	return &quotation, nil
+}
+
+// SetPricePathCache stores the price path of an asset in the cache.
+func (mdb *MemoryDB) SetPricePathCache(pricePath *PricePath) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.pricePathCache[assetIdentifier(pricePath.Asset)] = *pricePath
+	return nil
+}
+
+// GetPricePathCache returns the latest price path of @asset from the cache.
+func (mdb *MemoryDB) GetPricePathCache(asset dia.Asset) (*PricePath, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	pricePath, ok := mdb.pricePathCache[assetIdentifier(asset)]
+	if !ok {
+		return &PricePath{}, redis.Nil
+	}
+	return &pricePath, nil
+}
+
+// GetAssetPriceUSDCache returns the latest price of @asset from the cache.
+func (mdb *MemoryDB) GetAssetPriceUSDCache(asset dia.Asset) (price float64, err error) {
+	quotation, err := mdb.GetAssetQuotationCache(asset)
+	if err != nil {
+		return
+	}
+	price = quotation.Price
+	return
+}
+
+// GetSortedAssetQuotations returns quotations for all assets in @assets, sorted by 24h volume
+// in descending order.
+func (mdb *MemoryDB) GetSortedAssetQuotations(assets []dia.Asset) ([]AssetQuotation, error) {
+	var quotations []AssetQuotation
+	var volumes []float64
+	for _, asset := range assets {
+		quotation, err := mdb.GetAssetQuotationLatest(asset)
+		if err != nil {
+			continue
+		}
+		volume, err := mdb.GetVolume(asset)
+		if err != nil {
+			continue
+		}
+		quotations = append(quotations, *quotation)
+		volumes = append(volumes, *volume)
+	}
+	if len(quotations) == 0 {
+		return quotations, errors.New("no quotations available")
+	}
+
+	var quotationsSorted []AssetQuotation
+	volumesSorted := utils.NewFloat64Slice(sort.Float64Slice(volumes))
+	sort.Sort(volumesSorted)
+	for _, ind := range volumesSorted.Ind() {
+		quotationsSorted = append([]AssetQuotation{quotations[ind]}, quotationsSorted...)
+	}
+	return quotationsSorted, nil
+}
+
+// GetAssetsMarketCap returns the actual market cap of @asset.
+func (mdb *MemoryDB) GetAssetsMarketCap(asset dia.Asset) (float64, error) {
+	price, err := mdb.GetAssetPriceUSDLatest(asset)
+	if err != nil {
+		return 0, err
+	}
+	supply, err := mdb.GetSupplyCache(asset)
+	if err != nil {
+		return 0, err
+	}
+	return price * supply.CirculatingSupply, nil
+}
+
+// assetsBySymbol returns all assets with @symbol for which trades, filter points, quotations or supplies are stored.
+func (mdb *MemoryDB) assetsBySymbol(symbol string) (assets []dia.Asset) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	seen := make(map[string]struct{})
+	add := func(asset dia.Asset) {
+		if asset.Symbol != symbol {
+			return
+		}
+		if _, ok := seen[assetIdentifier(asset)]; !ok {
+			seen[assetIdentifier(asset)] = struct{}{}
+			assets = append(assets, asset)
+		}
+	}
+	for _, fp := range mdb.filters {
+		add(fp.Asset)
+	}
+	for _, quotations := range mdb.assetQuotations {
+		for _, quotation := range quotations {
+			add(quotation.Asset)
+		}
+	}
+	for _, supplies := range mdb.supplies {
+		for _, supply := range supplies {
+			add(supply.Asset)
+		}
+	}
+	for _, trades := range mdb.trades {
+		for _, trade := range trades {
+			add(trade.QuoteToken)
+		}
+	}
+	return
+}
+
+// GetTopAssetByVolume returns the asset with highest volume among all assets with symbol @symbol.
+// Assets are taken from the in-memory data, so @relDB is not used and may be nil.
+func (mdb *MemoryDB) GetTopAssetByVolume(symbol string, relDB RelDatastore) (topAsset dia.Asset, err error) {
+	assets := mdb.assetsBySymbol(symbol)
+	if len(assets) == 0 {
+		err = errors.New("no matching asset")
+		return
+	}
+	var volume float64
+	for _, asset := range assets {
+		value, err := mdb.GetVolume(asset)
+		if err != nil {
+			continue
+		}
+		if *value > volume {
+			volume = *value
+			topAsset = asset
+		}
+	}
+	if volume == 0 {
+		err = errors.New("no quotation for symbol")
+	}
+	return
+}
+
+// GetTopAssetByMcap returns the asset with highest market cap among all assets with symbol @symbol.
+// Assets are taken from the in-memory data, so @relDB is not used and may be nil.
+func (mdb *MemoryDB) GetTopAssetByMcap(symbol string, relDB RelDatastore) (topAsset dia.Asset, err error) {
+	assets := mdb.assetsBySymbol(symbol)
+	if len(assets) == 0 {
+		err = errors.New("no matching asset")
+		return
+	}
+	var mcap float64
+	for _, asset := range assets {
+		value, err := mdb.GetAssetsMarketCap(asset)
+		if err != nil {
+			continue
+		}
+		if value > mcap {
+			mcap = value
+			topAsset = asset
+		}
+	}
+	if mcap == 0 {
+		err = errors.New("no quotation for symbol")
+	}
+	return
+}
+
+// GetAssetsWithVOLInflux returns all assets with volume filter points after @timeInit.
+func (mdb *MemoryDB) GetAssetsWithVOLInflux(timeInit time.Time) ([]dia.Asset, error) {
+	filterPoints := mdb.selectFilterPoints(func(fp *memoryFilterPoint) bool {
+		return fp.Name == "VOL120" && fp.Exchange == "" && fp.Time.After(timeInit)
+	})
+	var assets []dia.Asset
+	seen := make(map[string]struct{})
+	for _, fp := range filterPoints {
+		if _, ok := seen[assetIdentifier(fp.Asset)]; !ok {
+			seen[assetIdentifier(fp.Asset)] = struct{}{}
+			assets = append(assets, fp.Asset)
+		}
+	}
+	return assets, nil
+}
+
+// ------------------------------------------------------------------------------
+// POOLS
+// ------------------------------------------------------------------------------
+
+// SavePoolInflux stores the state of the DEX pool @p.
+func (mdb *MemoryDB) SavePoolInflux(p dia.Pool) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.pools = append(mdb.pools, p)
+	return nil
+}
+
+// GetPoolInflux returns all states of the pool with @poolAddress in the time-range [starttime, endtime), latest first.
+func (mdb *MemoryDB) GetPoolInflux(poolAddress string, starttime time.Time, endtime time.Time) ([]dia.Pool, error) {
+	mdb.mu.RLock()
+	pools := []dia.Pool{}
+	for _, pool := range mdb.pools {
+		if pool.Address == poolAddress && !pool.Time.Before(starttime) && pool.Time.Before(endtime) {
+			pools = append(pools, pool)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(pools) == 0 {
+		return pools, errors.New("parsing pool from database")
+	}
+	sort.SliceStable(pools, func(i, j int) bool {
+		return pools[i].Time.After(pools[j].Time)
+	})
+	return pools, nil
+}
+
+// SavePriceSanityInflux stores the price sanity @count.
+func (mdb *MemoryDB) SavePriceSanityInflux(count PriceSanityCount) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.priceSanity = append(mdb.priceSanity, count)
+	return nil
+}
+
+// GetPriceSanityInflux returns the price sanity counts of @exchange in the time-range [starttime, endtime), latest first.
+// Counts of all exchanges are returned if @exchange is empty.
+func (mdb *MemoryDB) GetPriceSanityInflux(exchange string, starttime time.Time, endtime time.Time) ([]PriceSanityCount, error) {
+	mdb.mu.RLock()
+	counts := []PriceSanityCount{}
+	for _, count := range mdb.priceSanity {
+		if (exchange == "" || count.Exchange == exchange) && !count.Time.Before(starttime) && count.Time.Before(endtime) {
+			counts = append(counts, count)
+		}
+	}
+	mdb.mu.RUnlock()
+	sort.SliceStable(counts, func(i, j int) bool {
+		return counts[i].Time.After(counts[j].Time)
+	})
+	return counts, nil
+}
+
+// ------------------------------------------------------------------------------
+// ORDER BOOKS
+// ------------------------------------------------------------------------------
+
+// SaveOrderBookInflux stores the order book @ob.
+func (mdb *MemoryDB) SaveOrderBookInflux(ob dia.OrderBook) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.orderBooks = append(mdb.orderBooks, ob)
+	return nil
+}
+
+// GetOrderBookInflux returns the latest order book of @foreignName on @exchange in the time-range [starttime, endtime).
+func (mdb *MemoryDB) GetOrderBookInflux(exchange string, foreignName string, starttime time.Time, endtime time.Time) ([]dia.OrderBook, error) {
+	mdb.mu.RLock()
+	orderBooks := []dia.OrderBook{}
+	for _, ob := range mdb.orderBooks {
+		if ob.Exchange == exchange && ob.ForeignName == foreignName && !ob.Time.Before(starttime) && ob.Time.Before(endtime) {
+			orderBooks = append(orderBooks, ob)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(orderBooks) == 0 {
+		return orderBooks, errors.New("no order book found")
+	}
+	sort.SliceStable(orderBooks, func(i, j int) bool {
+		return orderBooks[i].Time.After(orderBooks[j].Time)
+	})
+	return orderBooks[:1], nil
+}
+
+// ------------------------------------------------------------------------------
+// SUPPLIES
+// ------------------------------------------------------------------------------
+
+// SetSupply stores @supply and writes it to the cache.
+func (mdb *MemoryDB) SetSupply(supply *dia.Supply) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	key := assetIdentifier(supply.Asset)
+	mdb.supplyCache[key] = *supply
+	mdb.supplies[key] = append(mdb.supplies[key], *supply)
+	return nil
+}
+
+// GetSupplyCache returns the latest supply of @asset from the cache.
+func (mdb *MemoryDB) GetSupplyCache(asset dia.Asset) (dia.Supply, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	supply, ok := mdb.supplyCache[assetIdentifier(asset)]
+	if !ok {
+		return dia.Supply{}, redis.Nil
+	}
+	return supply, nil
+}
+
+// GetSupplyInflux returns the supplies of @asset in the time-range (starttime, endtime), latest first.
+// If one of the times is zero, only the latest supply is returned.
+func (mdb *MemoryDB) GetSupplyInflux(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.Supply, error) {
+	latest := starttime.IsZero() || endtime.IsZero()
+	if latest {
+		starttime = time.Time{}
+		endtime = time.Now()
+	}
+	mdb.mu.RLock()
+	var supplies []dia.Supply
+	for _, supply := range mdb.supplies[assetIdentifier(asset)] {
+		if supply.Time.After(starttime) && supply.Time.Before(endtime) {
+			supplies = append(supplies, supply)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(supplies) == 0 {
+		return supplies, errors.New("parsing supply value from database")
+	}
+	sort.SliceStable(supplies, func(i, j int) bool {
+		return supplies[i].Time.After(supplies[j].Time)
+	})
+	if latest {
+		supplies = supplies[:1]
+	}
+	return supplies, nil
+}
+
+// GetSupply returns the supplies of the asset with @symbol and largest volume in the given time-range.
+// @relDB is not used and may be nil.
+func (mdb *MemoryDB) GetSupply(symbol string, starttime, endtime time.Time, relDB RelDatastore) ([]dia.Supply, error) {
+	topAsset, err := mdb.GetTopAssetByVolume(symbol, relDB)
+	if err != nil {
+		return []dia.Supply{}, err
+	}
+	return mdb.GetSupplyInflux(topAsset, starttime, endtime)
+}
+
+// GetLatestSupply returns the latest supply of the asset with @symbol and largest volume.
+func (mdb *MemoryDB) GetLatestSupply(symbol string, relDB RelDatastore) (*dia.Supply, error) {
+	supplies, err := mdb.GetSupply(symbol, time.Time{}, time.Time{}, relDB)
+	if err != nil {
+		return &dia.Supply{}, err
+	}
+	return &supplies[0], nil
+}
+
+// SetDiaTotalSupply stores the total supply of DIA.
+func (mdb *MemoryDB) SetDiaTotalSupply(totalSupply float64) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.diaTotalSupply = &totalSupply
+	return nil
+}
+
+// GetDiaTotalSupply returns the total supply of DIA.
+func (mdb *MemoryDB) GetDiaTotalSupply() (float64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	if mdb.diaTotalSupply == nil {
+		return 0, redis.Nil
+	}
+	return *mdb.diaTotalSupply, nil
+}
+
+// SetDiaCirculatingSupply stores the circulating supply of DIA.
+func (mdb *MemoryDB) SetDiaCirculatingSupply(circulatingSupply float64) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.diaCirculatingSupply = &circulatingSupply
+	return nil
+}
+
+// GetDiaCirculatingSupply returns the circulating supply of DIA.
+func (mdb *MemoryDB) GetDiaCirculatingSupply() (float64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	if mdb.diaCirculatingSupply == nil {
+		return 0, redis.Nil
+	}
+	return *mdb.diaCirculatingSupply, nil
+}
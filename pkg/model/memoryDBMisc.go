@@ -0,0 +1,887 @@
+package models
+
+import (
+	"errors"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/diadata-org/diadata/pkg/dia/helpers"
+	"github.com/go-redis/redis"
+)
+
+// errNotSupportedInMemory is returned by methods relying on computations only available in influx or redis.
+var errNotSupportedInMemory = errors.New("not supported by in-memory datastore")
+
+// ------------------------------------------------------------------------------
+// QUOTATIONS (Deprecating)
+// ------------------------------------------------------------------------------
+
+// SetPriceUSD stores the USD quotation of @symbol.
+func (mdb *MemoryDB) SetPriceUSD(symbol string, price float64) error {
+	return mdb.SetQuotation(&Quotation{
+		Symbol: symbol,
+		Name:   helpers.NameForSymbol(symbol),
+		Price:  price,
+		Source: dia.Diadata,
+		Time:   time.Now(),
+	})
+}
+
+// SetPriceEUR stores the EUR quotation of @symbol.
+func (mdb *MemoryDB) SetPriceEUR(symbol string, price float64) error {
+	return mdb.SetQuotationEUR(&Quotation{
+		Symbol: symbol,
+		Name:   helpers.NameForSymbol(symbol),
+		Price:  price,
+		Source: dia.Diadata,
+		Time:   time.Now(),
+	})
+}
+
+// GetPriceUSD returns the USD price of @symbol.
+func (mdb *MemoryDB) GetPriceUSD(symbol string) (float64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	quotation, ok := mdb.quotations[symbol]
+	if !ok {
+		return 0, redis.Nil
+	}
+	return quotation.Price, nil
+}
+
+// GetQuotation returns the USD quotation of @symbol.
+func (mdb *MemoryDB) GetQuotation(symbol string) (*Quotation, error) {
+	mdb.mu.RLock()
+	quotation, ok := mdb.quotations[symbol]
+	mdb.mu.RUnlock()
+	if !ok {
+		return nil, redis.Nil
+	}
+	quotation.Name = helpers.NameForSymbol(symbol)
+	itin, err := mdb.GetItinBySymbol(symbol)
+	if err != nil {
+		quotation.ITIN = "undefined"
+	} else {
+		quotation.ITIN = itin.Itin
+	}
+	return &quotation, nil
+}
+
+// SetQuotation stores the USD quotation @quotation.
+func (mdb *MemoryDB) SetQuotation(quotation *Quotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.quotations[quotation.Symbol] = *quotation
+	return nil
+}
+
+// SetQuotationEUR stores the EUR quotation @quotation.
+func (mdb *MemoryDB) SetQuotationEUR(quotation *Quotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.quotationsEUR[quotation.Symbol] = *quotation
+	return nil
+}
+
+// SetBatchFiatPriceInflux stores @fiatQuotations.
+func (mdb *MemoryDB) SetBatchFiatPriceInflux(fiatQuotations []*FiatQuotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	for _, fq := range fiatQuotations {
+		mdb.fiatQuotations = append(mdb.fiatQuotations, *fq)
+	}
+	return nil
+}
+
+// SetSingleFiatPriceRedis stores @fiatQuotation as quotation of its quote currency.
+func (mdb *MemoryDB) SetSingleFiatPriceRedis(fiatQuotation *FiatQuotation) error {
+	return mdb.SetQuotation(&Quotation{
+		Symbol: fiatQuotation.QuoteCurrency,
+		Price:  fiatQuotation.Price,
+		Source: fiatQuotation.Source,
+		Time:   fiatQuotation.Time,
+	})
+}
+
+// GetPaxgQuotationOunces returns the quotation of PAXG.
+func (mdb *MemoryDB) GetPaxgQuotationOunces() (*Quotation, error) {
+	return mdb.GetQuotation("PAXG")
+}
+
+// GetPaxgQuotationGrams returns the quotation of PAXG per gram.
+func (mdb *MemoryDB) GetPaxgQuotationGrams() (*Quotation, error) {
+	q, err := mdb.GetQuotation("PAXG")
+	if err != nil {
+		return nil, err
+	}
+	q.Symbol = q.Symbol + "-gram"
+	q.Name = q.Name + "-gram"
+	q.Price = q.Price / 31.1034768
+	if q.PriceYesterday != nil {
+		priceYesterday := *q.PriceYesterday / 31.1034768
+		q.PriceYesterday = &priceYesterday
+	}
+	return q, nil
+}
+
+// SetCurrencyChange stores @cc.
+func (mdb *MemoryDB) SetCurrencyChange(cc *Change) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	change := *cc
+	mdb.currencyChange = &change
+	return nil
+}
+
+// GetCurrencyChange returns the currency change stored by SetCurrencyChange.
+func (mdb *MemoryDB) GetCurrencyChange() (*Change, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	if mdb.currencyChange == nil {
+		return nil, redis.Nil
+	}
+	change := *mdb.currencyChange
+	return &change, nil
+}
+
+// ------------------------------------------------------------------------------
+// OPTIONS AND CVI
+// ------------------------------------------------------------------------------
+
+// SetOptionMeta adds @optionMeta to the set of options of its base currency.
+func (mdb *MemoryDB) SetOptionMeta(optionMeta *dia.OptionMeta) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	if _, ok := mdb.optionMeta[optionMeta.BaseCurrency]; !ok {
+		mdb.optionMeta[optionMeta.BaseCurrency] = make(map[string]dia.OptionMeta)
+	}
+	mdb.optionMeta[optionMeta.BaseCurrency][optionMeta.InstrumentName] = *optionMeta
+	return nil
+}
+
+// GetOptionMeta returns all options with @baseCurrency, ordered by instrument name.
+func (mdb *MemoryDB) GetOptionMeta(baseCurrency string) ([]dia.OptionMeta, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var result []dia.OptionMeta
+	for _, optionMeta := range mdb.optionMeta[baseCurrency] {
+		result = append(result, optionMeta)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].InstrumentName < result[j].InstrumentName
+	})
+	return result, nil
+}
+
+// SaveCVIInflux stores a CVI value.
+func (mdb *MemoryDB) SaveCVIInflux(cviValue float64, observationTime time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.cvi[influxDbCVITable] = append(mdb.cvi[influxDbCVITable], dia.CviDataPoint{Timestamp: observationTime, Value: cviValue})
+	return nil
+}
+
+// GetCVIInflux returns the CVI values in the time-range (starttime, endtime). As in DB, the symbol ETH
+// refers to a separate table which is not written by SaveCVIInflux.
+func (mdb *MemoryDB) GetCVIInflux(starttime time.Time, endtime time.Time, symbol string) ([]dia.CviDataPoint, error) {
+	table := influxDbCVITable
+	if symbol == "ETH" {
+		table = influxDbETHCVITable
+	}
+	mdb.mu.RLock()
+	retval := []dia.CviDataPoint{}
+	for _, point := range mdb.cvi[table] {
+		if point.Timestamp.After(starttime) && point.Timestamp.Before(endtime) {
+			retval = append(retval, point)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(retval) == 0 {
+		return retval, errors.New("parsing CVI value from database")
+	}
+	sort.SliceStable(retval, func(i, j int) bool {
+		return retval[i].Timestamp.Before(retval[j].Timestamp)
+	})
+	return retval, nil
+}
+
+// ------------------------------------------------------------------------------
+// INTEREST RATES
+// ------------------------------------------------------------------------------
+
+// SetInterestRate stores @ir. A rate with the same symbol and effective date is overwritten.
+func (mdb *MemoryDB) SetInterestRate(ir *InterestRate) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	rates := mdb.interestRates[ir.Symbol]
+	index := sort.Search(len(rates), func(i int) bool {
+		return !rates[i].EffectiveDate.Before(ir.EffectiveDate)
+	})
+	if index < len(rates) && rates[index].EffectiveDate.Equal(ir.EffectiveDate) {
+		rates[index] = *ir
+		return nil
+	}
+	rates = append(rates, InterestRate{})
+	copy(rates[index+1:], rates[index:])
+	rates[index] = *ir
+	mdb.interestRates[ir.Symbol] = rates
+	return nil
+}
+
+// GetInterestRate returns the interest rate value for the last effective date before or at @date.
+// If @date is an empty string it returns the latest rate.
+// @date is a string in the format yyyy-mm-dd.
+func (mdb *MemoryDB) GetInterestRate(symbol, date string) (*InterestRate, error) {
+	if date == "" {
+		date = time.Now().Format("2006-01-02")
+	}
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return &InterestRate{}, err
+	}
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	rates := mdb.interestRates[symbol]
+	index := sort.Search(len(rates), func(i int) bool {
+		return rates[i].EffectiveDate.After(day)
+	})
+	if index == 0 {
+		return &InterestRate{}, redis.Nil
+	}
+	ir := rates[index-1]
+	return &ir, nil
+}
+
+// GetInterestRateRange returns the interest rate values with effective date in [dateInit, dateFinal].
+// @dateInit and @dateFinal are strings in the format yyyy-mm-dd.
+func (mdb *MemoryDB) GetInterestRateRange(symbol, dateInit, dateFinal string) ([]*InterestRate, error) {
+	timeInit, err := time.Parse("2006-01-02", dateInit)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	timeFinal, err := time.Parse("2006-01-02", dateFinal)
+	if err != nil {
+		return []*InterestRate{}, err
+	}
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	allValues := []*InterestRate{}
+	for i := range mdb.interestRates[symbol] {
+		ir := mdb.interestRates[symbol][i]
+		if !ir.EffectiveDate.Before(timeInit) && !ir.EffectiveDate.After(timeFinal) {
+			allValues = append(allValues, &ir)
+		}
+	}
+	return allValues, nil
+}
+
+// GetRatesMeta returns all available rate symbols along with their first effective date and issuer.
+func (mdb *MemoryDB) GetRatesMeta() (RatesMeta []InterestRateMeta, err error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	for symbol, rates := range mdb.interestRates {
+		if len(rates) == 0 {
+			continue
+		}
+		decimals := 8
+		switch symbol {
+		case "SONIA":
+			decimals = 4
+		case "SOFR":
+			decimals = 2
+		case "SOFR30", "SOFR90", "SOFR180":
+			decimals = 5
+		case "ESTER":
+			decimals = 3
+		}
+		RatesMeta = append(RatesMeta, InterestRateMeta{symbol, rates[0].EffectiveDate, decimals, rates[0].Source})
+	}
+	sort.Slice(RatesMeta, func(i, j int) bool {
+		return RatesMeta[i].Symbol < RatesMeta[j].Symbol
+	})
+	return
+}
+
+// GetCompoundedIndex is not supported by MemoryDB.
+func (mdb *MemoryDB) GetCompoundedIndex(symbol string, date time.Time, daysPerYear int, rounding int) (*InterestRate, error) {
+	return &InterestRate{}, errNotSupportedInMemory
+}
+
+// GetCompoundedIndexRange is not supported by MemoryDB.
+func (mdb *MemoryDB) GetCompoundedIndexRange(symbol string, dateInit, dateFinal time.Time, daysPerYear int, rounding int) ([]*InterestRate, error) {
+	return []*InterestRate{}, errNotSupportedInMemory
+}
+
+// GetCompoundedAvg is not supported by MemoryDB.
+func (mdb *MemoryDB) GetCompoundedAvg(symbol string, date time.Time, calDays, daysPerYear int, rounding int) (*InterestRate, error) {
+	return &InterestRate{}, errNotSupportedInMemory
+}
+
+// GetCompoundedAvgRange is not supported by MemoryDB.
+func (mdb *MemoryDB) GetCompoundedAvgRange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int) ([]*InterestRate, error) {
+	return []*InterestRate{}, errNotSupportedInMemory
+}
+
+// GetCompoundedAvgDIARange is not supported by MemoryDB.
+func (mdb *MemoryDB) GetCompoundedAvgDIARange(symbol string, dateInit, dateFinal time.Time, calDays, daysPerYear int, rounding int) ([]*InterestRate, error) {
+	return []*InterestRate{}, errNotSupportedInMemory
+}
+
+// ------------------------------------------------------------------------------
+// ITIN AND DEFI
+// ------------------------------------------------------------------------------
+
+// SetItinData stores @token.
+func (mdb *MemoryDB) SetItinData(token dia.ItinToken) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.itinTokens[token.Symbol] = token
+	return nil
+}
+
+// GetItinBySymbol returns the itin token with @symbol.
+func (mdb *MemoryDB) GetItinBySymbol(symbol string) (dia.ItinToken, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	token, ok := mdb.itinTokens[symbol]
+	if !ok {
+		return dia.ItinToken{}, redis.Nil
+	}
+	return token, nil
+}
+
+// SetDefiProtocol stores @protocol.
+func (mdb *MemoryDB) SetDefiProtocol(protocol dia.DefiProtocol) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.defiProtocols[protocol.Name] = protocol
+	return nil
+}
+
+// GetDefiProtocol returns the defi protocol with @name.
+func (mdb *MemoryDB) GetDefiProtocol(name string) (dia.DefiProtocol, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	protocol, ok := mdb.defiProtocols[name]
+	if !ok {
+		return dia.DefiProtocol{}, redis.Nil
+	}
+	return protocol, nil
+}
+
+// GetDefiProtocols returns all defi protocols, ordered by name.
+func (mdb *MemoryDB) GetDefiProtocols() ([]dia.DefiProtocol, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	allProtocols := []dia.DefiProtocol{}
+	for _, protocol := range mdb.defiProtocols {
+		allProtocols = append(allProtocols, protocol)
+	}
+	sort.Slice(allProtocols, func(i, j int) bool {
+		return allProtocols[i].Name < allProtocols[j].Name
+	})
+	return allProtocols, nil
+}
+
+// SetDefiRateInflux stores @rate.
+func (mdb *MemoryDB) SetDefiRateInflux(rate *dia.DefiRate) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.defiRates = append(mdb.defiRates, *rate)
+	return nil
+}
+
+// GetDefiRateInflux returns the rates of @asset on @protocol in the time-range (starttime, endtime).
+func (mdb *MemoryDB) GetDefiRateInflux(starttime time.Time, endtime time.Time, asset string, protocol string) ([]dia.DefiRate, error) {
+	mdb.mu.RLock()
+	retval := []dia.DefiRate{}
+	for _, rate := range mdb.defiRates {
+		if rate.Asset == asset && rate.Protocol == protocol && rate.Timestamp.After(starttime) && rate.Timestamp.Before(endtime) {
+			retval = append(retval, rate)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(retval) == 0 {
+		return retval, errors.New("parsing defi lending rate from database")
+	}
+	sort.SliceStable(retval, func(i, j int) bool {
+		return retval[i].Timestamp.Before(retval[j].Timestamp)
+	})
+	return retval, nil
+}
+
+// SetDefiStateInflux stores @state.
+func (mdb *MemoryDB) SetDefiStateInflux(state *dia.DefiProtocolState) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.defiStates = append(mdb.defiStates, *state)
+	return nil
+}
+
+// GetDefiStateInflux returns the states of @protocol in the time-range (starttime, endtime).
+func (mdb *MemoryDB) GetDefiStateInflux(starttime time.Time, endtime time.Time, protocol string) (retval []dia.DefiProtocolState, err error) {
+	mdb.mu.RLock()
+	for _, state := range mdb.defiStates {
+		if state.Protocol.Name == protocol && state.Timestamp.After(starttime) && state.Timestamp.Before(endtime) {
+			retval = append(retval, state)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(retval) == 0 {
+		err = errors.New("parsing defi lending state from database")
+		return
+	}
+	sort.SliceStable(retval, func(i, j int) bool {
+		return retval[i].Timestamp.Before(retval[j].Timestamp)
+	})
+	return
+}
+
+// ------------------------------------------------------------------------------
+// FOREIGN QUOTATIONS
+// ------------------------------------------------------------------------------
+
+// SaveForeignQuotationInflux stores @fq.
+func (mdb *MemoryDB) SaveForeignQuotationInflux(fq ForeignQuotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.foreignQuotations = append(mdb.foreignQuotations, fq)
+	return nil
+}
+
+// GetForeignQuotationInflux returns the last quotation of @symbol on @source before @timestamp.
+func (mdb *MemoryDB) GetForeignQuotationInflux(symbol, source string, timestamp time.Time) (ForeignQuotation, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var retval ForeignQuotation
+	for _, fq := range mdb.foreignQuotations {
+		if fq.Symbol == symbol && fq.Source == source && fq.Time.Before(timestamp) && !fq.Time.Before(retval.Time) {
+			retval = fq
+		}
+	}
+	return retval, nil
+}
+
+// GetForeignPriceYesterday returns the average price of @symbol on @source from yesterday.
+func (mdb *MemoryDB) GetForeignPriceYesterday(symbol, source string) (float64, error) {
+	now := time.Now()
+	timeFinal := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location()).Add(-time.Second)
+	timeInit := timeFinal.Add(-24 * time.Hour)
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var price float64
+	var numPrices int
+	for _, fq := range mdb.foreignQuotations {
+		if fq.Symbol == symbol && fq.Source == source && fq.Time.After(timeInit) && fq.Time.Before(timeFinal) {
+			price += fq.Price
+			numPrices++
+		}
+	}
+	if numPrices == 0 {
+		return 0, errors.New("no data available from yesterday")
+	}
+	return price / float64(numPrices), nil
+}
+
+// GetForeignSymbolsInflux returns all symbols quoted by @source during the last 7 days, along with their ITIN.
+func (mdb *MemoryDB) GetForeignSymbolsInflux(source string) (symbols []SymbolShort, err error) {
+	mdb.mu.RLock()
+	var symsUnique []string
+	set := make(map[string]struct{})
+	for _, fq := range mdb.foreignQuotations {
+		if fq.Source != source || fq.Time.Before(time.Now().AddDate(0, 0, -7)) {
+			continue
+		}
+		if _, ok := set[fq.Symbol]; !ok {
+			symsUnique = append(symsUnique, fq.Symbol)
+			set[fq.Symbol] = struct{}{}
+		}
+	}
+	mdb.mu.RUnlock()
+	for _, sym := range symsUnique {
+		symbol := SymbolShort{Symbol: sym}
+		itin, err := mdb.GetItinBySymbol(sym)
+		if err != nil {
+			symbol.ITIN = "undefined"
+		} else {
+			symbol.ITIN = itin.Itin
+		}
+		symbols = append(symbols, symbol)
+	}
+	return
+}
+
+// SetVWAPFirefly stores a VWAP value of @foreignName.
+func (mdb *MemoryDB) SetVWAPFirefly(foreignName string, value float64, timestamp time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.vwapFirefly[foreignName] = append(mdb.vwapFirefly[foreignName], memoryTimedValue{Value: value, Time: timestamp})
+	return nil
+}
+
+// GetVWAPFirefly returns the VWAP values of @foreignName in the time-range (starttime, endtime], latest first.
+func (mdb *MemoryDB) GetVWAPFirefly(foreignName string, starttime time.Time, endtime time.Time) (values []float64, timestamps []time.Time, err error) {
+	mdb.mu.RLock()
+	var points []memoryTimedValue
+	for _, point := range mdb.vwapFirefly[foreignName] {
+		if point.Time.After(starttime) && !point.Time.After(endtime) {
+			points = append(points, point)
+		}
+	}
+	mdb.mu.RUnlock()
+	if len(points) == 0 {
+		err = errors.New("no data available in given time range")
+		return
+	}
+	sort.SliceStable(points, func(i, j int) bool {
+		return points[i].Time.After(points[j].Time)
+	})
+	for _, point := range points {
+		values = append(values, point.Value)
+		timestamps = append(timestamps, point.Time)
+	}
+	return
+}
+
+// ------------------------------------------------------------------------------
+// CRYPTO INDICES
+// ------------------------------------------------------------------------------
+
+// selectCryptoIndices returns at most @maxResults indices with @symbol in the time-range (starttime, endtime], latest first.
+// If @maxResults is not positive, all indices in the time-range are returned.
+func (mdb *MemoryDB) selectCryptoIndices(starttime time.Time, endtime time.Time, symbol string, maxResults int) (indices []CryptoIndex) {
+	mdb.mu.RLock()
+	for _, index := range mdb.cryptoIndices {
+		if index.Asset.Symbol == symbol && index.CalculationTime.After(starttime) && !index.CalculationTime.After(endtime) {
+			indices = append(indices, index)
+		}
+	}
+	mdb.mu.RUnlock()
+	sort.SliceStable(indices, func(i, j int) bool {
+		return indices[i].CalculationTime.After(indices[j].CalculationTime)
+	})
+	if maxResults > 0 && len(indices) > maxResults {
+		indices = indices[:maxResults]
+	}
+	return
+}
+
+// SetCryptoIndex stores @index.
+func (mdb *MemoryDB) SetCryptoIndex(index *CryptoIndex) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.cryptoIndices = append(mdb.cryptoIndices, *index)
+	return nil
+}
+
+// GetCryptoIndexTime returns the latest recorded timestamp in the range (@starttime, @endtime].
+func (mdb *MemoryDB) GetCryptoIndexTime(starttime, endtime time.Time, symbol string) (time.Time, error) {
+	indices := mdb.selectCryptoIndices(starttime, endtime, symbol, 1)
+	if len(indices) == 0 {
+		return time.Time{}, errors.New("no index in given time-range")
+	}
+	return indices[0].CalculationTime, nil
+}
+
+// GetCryptoIndex returns the crypto indices with @symbol in the range (@starttime, @endtime], latest first.
+func (mdb *MemoryDB) GetCryptoIndex(starttime time.Time, endtime time.Time, symbol string, maxResults int) ([]CryptoIndex, error) {
+	return mdb.selectCryptoIndices(starttime, endtime, symbol, maxResults), nil
+}
+
+// GetCryptoIndexValues returns the crypto indices with @symbol in the range (@starttime, @endtime] without constituents.
+func (mdb *MemoryDB) GetCryptoIndexValues(starttime time.Time, endtime time.Time, symbol string, maxResults int) ([]CryptoIndex, error) {
+	indices := mdb.selectCryptoIndices(starttime, endtime, symbol, maxResults)
+	for i := range indices {
+		indices[i].Constituents = nil
+	}
+	return indices, nil
+}
+
+// GetCryptoIndexValuesSpaced returns the latest index value in each interval of length @frequency
+// in the range (@starttime, @endtime]. @frequency is given in influx notation such as 5m or 1d.
+func (mdb *MemoryDB) GetCryptoIndexValuesSpaced(starttime time.Time, endtime time.Time, symbol string, frequency string) ([]CryptoIndex, error) {
+	if len(frequency) < 2 {
+		return []CryptoIndex{}, errors.New("invalid frequency " + frequency)
+	}
+	d, err := strconv.Atoi(frequency[:len(frequency)-1])
+	if err != nil {
+		return []CryptoIndex{}, err
+	}
+	var addDuration time.Duration
+	switch frequency[len(frequency)-1:] {
+	case "d":
+		addDuration = time.Duration(d) * 24 * time.Hour
+	case "h":
+		addDuration = time.Duration(d) * time.Hour
+	case "m":
+		addDuration = time.Duration(d) * time.Minute
+	case "s":
+		addDuration = time.Duration(d) * time.Second
+	}
+	if addDuration <= 0 {
+		return []CryptoIndex{}, errors.New("invalid frequency " + frequency)
+	}
+
+	var indices []CryptoIndex
+	for t := starttime; t.Before(endtime); t = t.Add(addDuration) {
+		intervalEnd := t.Add(addDuration)
+		if intervalEnd.After(endtime) {
+			intervalEnd = endtime
+		}
+		values, _ := mdb.GetCryptoIndexValues(t, intervalEnd, symbol, 1)
+		indices = append(indices, values...)
+	}
+	return indices, nil
+}
+
+// SetCryptoIndexConstituent stores @constituent of @index at @timestamp.
+func (mdb *MemoryDB) SetCryptoIndexConstituent(constituent *CryptoIndexConstituent, index dia.Asset, timestamp time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.constituents = append(mdb.constituents, memoryIndexConstituent{Constituent: *constituent, Index: index, Time: timestamp})
+	return nil
+}
+
+// GetCryptoIndexConstituentPrice returns the price of the latest constituent with @symbol during the 24h before @date.
+func (mdb *MemoryDB) GetCryptoIndexConstituentPrice(symbol string, date time.Time) (float64, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var price float64
+	var latest time.Time
+	for _, c := range mdb.constituents {
+		if c.Constituent.Asset.Symbol == symbol && c.Time.After(date.Add(-24*time.Hour)) && !c.Time.After(date) && !c.Time.Before(latest) {
+			price = c.Constituent.Price
+			latest = c.Time
+		}
+	}
+	return price, nil
+}
+
+// GetCryptoIndexConstituents returns the latest constituent corresponding to @asset in the index with @indexSymbol
+// in the time-range (starttime, endtime), along with its prices from yesterday and last week.
+func (mdb *MemoryDB) GetCryptoIndexConstituents(starttime time.Time, endtime time.Time, asset dia.Asset, indexSymbol string) ([]CryptoIndexConstituent, error) {
+	var retval []CryptoIndexConstituent
+	var latest *memoryIndexConstituent
+	mdb.mu.RLock()
+	for i, c := range mdb.constituents {
+		if c.Index.Symbol == indexSymbol && assetIdentifier(c.Constituent.Asset) == assetIdentifier(asset) &&
+			c.Time.After(starttime) && c.Time.Before(endtime) && (latest == nil || !c.Time.Before(latest.Time)) {
+			latest = &mdb.constituents[i]
+		}
+	}
+	mdb.mu.RUnlock()
+	if latest == nil {
+		return retval, nil
+	}
+	currentConstituent := latest.Constituent
+	priceYesterday, err := mdb.GetLastPriceBefore(currentConstituent.Asset, "MAIR120", "", endtime.AddDate(0, 0, -1))
+	if err == nil {
+		currentConstituent.PriceYesterday = priceYesterday.Price
+	}
+	priceYesterweek, err := mdb.GetLastPriceBefore(currentConstituent.Asset, "MAIR120", "", endtime.AddDate(0, 0, -7))
+	if err == nil {
+		currentConstituent.PriceYesterweek = priceYesterweek.Price
+	}
+	return append(retval, currentConstituent), nil
+}
+
+// GetIndexPrice returns the last price of index represented by @asset with respect to the
+// time-range [time-window, time).
+func (mdb *MemoryDB) GetIndexPrice(asset dia.Asset, time time.Time, window time.Duration) (*dia.Trade, error) {
+	if asset.Address != "" && asset.Blockchain != "" {
+		return mdb.GetTradeInflux(asset, "", time, window)
+	}
+	return nil, errors.New("asset's address or blockchain missing")
+}
+
+// GetCurrentIndexCompositionForIndex returns the current constituents of @index.
+func (mdb *MemoryDB) GetCurrentIndexCompositionForIndex(index dia.Asset) []CryptoIndexConstituent {
+	var constituents []CryptoIndexConstituent
+	cryptoIndex, _ := mdb.GetCryptoIndex(time.Now().Add(-24*time.Hour), time.Now(), index.Symbol, 1)
+	if len(cryptoIndex) == 0 {
+		return constituents
+	}
+	for _, constituent := range cryptoIndex[0].Constituents {
+		curr, _ := mdb.GetCryptoIndexConstituents(time.Now().Add(-24*time.Hour), time.Now(), constituent.Asset, index.Symbol)
+		constituents = append(constituents, curr...)
+	}
+	return constituents
+}
+
+// IndexValueCalculation returns the index @indexAsset with value @indexValue and @currentConstituents.
+func (mdb *MemoryDB) IndexValueCalculation(currentConstituents []CryptoIndexConstituent, indexAsset dia.Asset, indexValue float64) CryptoIndex {
+	index := CryptoIndex{
+		Asset:           indexAsset,
+		Value:           indexValue,
+		CalculationTime: time.Now(),
+		Constituents:    currentConstituents,
+	}
+	if trade, err := mdb.GetIndexPrice(indexAsset, time.Now(), 7*24*time.Hour); err == nil {
+		index.Price = trade.EstimatedUSDPrice
+	}
+	if supply, err := mdb.GetSupplyCache(indexAsset); err == nil {
+		index.CirculatingSupply = supply.CirculatingSupply
+	}
+	if currCryptoIndex, _ := mdb.GetCryptoIndex(time.Now().Add(-24*time.Hour), time.Now(), indexAsset.Symbol, 1); len(currCryptoIndex) > 0 {
+		index.Divisor = currCryptoIndex[0].Divisor
+	}
+	return index
+}
+
+// UpdateConstituentsMarketData updates prices, supplies and percentages of @currentConstituents of @index.
+func (mdb *MemoryDB) UpdateConstituentsMarketData(index string, currentConstituents *[]CryptoIndexConstituent) error {
+	for i, c := range *currentConstituents {
+		currSupply, err := mdb.GetSupplyCache(c.Asset)
+		if err != nil {
+			return err
+		}
+		currLastTrade, err := mdb.GetLastTrades(c.Asset, "", 1, false)
+		if err != nil {
+			return err
+		}
+		(*currentConstituents)[i].Price = currLastTrade[0].EstimatedUSDPrice
+		(*currentConstituents)[i].CirculatingSupply = currSupply.CirculatingSupply
+	}
+
+	currIndexValue := GetIndexValue(index, *currentConstituents)
+	for i := range *currentConstituents {
+		c := &(*currentConstituents)[i]
+		if index == "SCIFI" {
+			c.Percentage = (c.Price * c.CirculatingSupply * c.CappingFactor) / currIndexValue
+		} else {
+			c.Percentage = (c.Price * c.NumBaseTokens * 1e-16) / currIndexValue
+		}
+	}
+	return nil
+}
+
+// SaveIndexEngineTimeInflux stores a benchmarked index value.
+func (mdb *MemoryDB) SaveIndexEngineTimeInflux(tags map[string]string, fields map[string]interface{}, timestamp time.Time) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.benchmarkedIndex = append(mdb.benchmarkedIndex, memoryBenchmarkedIndexValue{Tags: tags, Fields: fields, Time: timestamp})
+	return nil
+}
+
+// GetBenchmarkedIndexValuesInflux returns the values of the benchmarked index @symbol in the time-range (starttime, endtime), latest first.
+func (mdb *MemoryDB) GetBenchmarkedIndexValuesInflux(symbol string, starttime time.Time, endtime time.Time) (BenchmarkedIndex, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var retval BenchmarkedIndex
+	for _, v := range mdb.benchmarkedIndex {
+		if v.Tags["name"] != symbol || !v.Time.After(starttime) || !v.Time.Before(endtime) {
+			continue
+		}
+		value, _ := v.Fields["value"].(string)
+		retval.Name = symbol
+		retval.Values = append(retval.Values, BenchmarkedIndexValue{CalculationTime: v.Time, Value: value})
+	}
+	sort.SliceStable(retval.Values, func(i, j int) bool {
+		return retval.Values[i].CalculationTime.After(retval.Values[j].CalculationTime)
+	})
+	return retval, nil
+}
+
+// ------------------------------------------------------------------------------
+// GITHUB AND STOCKS
+// ------------------------------------------------------------------------------
+
+// SetCommit stores @commit.
+func (mdb *MemoryDB) SetCommit(commit GithubCommit) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.commits = append(mdb.commits, commit)
+	return nil
+}
+
+// GetCommitByDate returns the latest commit from @repository of github user @user before @date.
+func (mdb *MemoryDB) GetCommitByDate(user, repository string, date time.Time) (GithubCommit, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	var commit GithubCommit
+	for _, c := range mdb.commits {
+		if c.User == user && c.Repository == repository && c.Timestamp.Before(date) && !c.Timestamp.Before(commit.Timestamp) {
+			commit = c
+		}
+	}
+	return commit, nil
+}
+
+// GetCommitByHash returns the commit from @repository of github user @user with hash @hash.
+func (mdb *MemoryDB) GetCommitByHash(user, repository, hash string) (GithubCommit, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	for _, c := range mdb.commits {
+		if c.User == user && c.Repository == repository && c.Hash == hash {
+			return c, nil
+		}
+	}
+	return GithubCommit{}, nil
+}
+
+// GetLatestCommit returns the latest commit from @repository of github user @user.
+func (mdb *MemoryDB) GetLatestCommit(user, repository string) (GithubCommit, error) {
+	return mdb.GetCommitByDate(user, repository, time.Now())
+}
+
+// SetStockQuotation stores @sq.
+func (mdb *MemoryDB) SetStockQuotation(sq StockQuotation) error {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	mdb.stockQuotations = append(mdb.stockQuotations, sq)
+	return nil
+}
+
+// GetStockQuotation returns the quotations of @symbol on @source in the time-range (timeInit, timeFinal], latest first.
+func (mdb *MemoryDB) GetStockQuotation(source string, symbol string, timeInit time.Time, timeFinal time.Time) ([]StockQuotation, error) {
+	mdb.mu.RLock()
+	stockQuotations := []StockQuotation{}
+	for _, sq := range mdb.stockQuotations {
+		if sq.Source == source && sq.Symbol == symbol && sq.Time.After(timeInit) && !sq.Time.After(timeFinal) {
+			stockQuotations = append(stockQuotations, sq)
+		}
+	}
+	mdb.mu.RUnlock()
+	sort.SliceStable(stockQuotations, func(i, j int) bool {
+		return stockQuotations[i].Time.After(stockQuotations[j].Time)
+	})
+	return stockQuotations, nil
+}
+
+// GetStockSymbols returns all stocks quoted during the last 7 days, mapped to their source.
+func (mdb *MemoryDB) GetStockSymbols() (map[Stock]string, error) {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	allStocks := make(map[Stock]string)
+	for _, sq := range mdb.stockQuotations {
+		if sq.Time.After(time.Now().AddDate(0, 0, -7)) {
+			allStocks[Stock{Symbol: sq.Symbol, Name: sq.Name, ISIN: sq.ISIN}] = sq.Source
+		}
+	}
+	return allStocks, nil
+}
+
+// PublishPairsReload asks all subscribers for @exchange to reload their pairs.
+func (mdb *MemoryDB) PublishPairsReload(exchange string) error {
+	mdb.mu.RLock()
+	defer mdb.mu.RUnlock()
+	for _, reload := range mdb.pairsReloads[exchange] {
+		select {
+		case reload <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// SubscribePairsReload returns a channel which receives a value whenever a reload of the pairs
+// on @exchange is requested through PublishPairsReload.
+func (mdb *MemoryDB) SubscribePairsReload(exchange string) (<-chan struct{}, error) {
+	mdb.mu.Lock()
+	defer mdb.mu.Unlock()
+	reload := make(chan struct{}, 1)
+	mdb.pairsReloads[exchange] = append(mdb.pairsReloads[exchange], reload)
+	return reload, nil
+}
@@ -0,0 +1,128 @@
+package models
+
+import (
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/go-redis/redis"
+)
+
+var (
+	_ Datastore    = (*MemoryDB)(nil)
+	_ RelDatastore = (*MemoryRelDB)(nil)
+)
+
+var (
+	memoryETH  = dia.Asset{Symbol: "ETH", Name: "Ether", Address: "0x0000000000000000000000000000000000000000", Decimals: 18, Blockchain: dia.ETHEREUM}
+	memoryUSDT = dia.Asset{Symbol: "USDT", Name: "Tether", Address: "0xdAC17F958D2ee523a2206206994597C13D831ec7", Decimals: 6, Blockchain: dia.ETHEREUM}
+)
+
+func TestMemoryDBTrades(t *testing.T) {
+	mdb := NewMemoryDataStore()
+	now := time.Now().Add(-time.Hour)
+	for i, price := range []float64{3, 1, 2} {
+		err := mdb.SaveTradeInflux(&dia.Trade{
+			Symbol:            "ETH",
+			Pair:              "ETH-USDT",
+			QuoteToken:        memoryETH,
+			BaseToken:         memoryUSDT,
+			Price:             price,
+			EstimatedUSDPrice: price,
+			Volume:            1,
+			Time:              now.Add(time.Duration(price) * time.Minute),
+			Source:            dia.UniswapExchange,
+		})
+		if err != nil {
+			t.Fatalf("save trade %d: %v", i, err)
+		}
+	}
+
+	trades, err := mdb.GetLastTrades(memoryETH, dia.UniswapExchange, 2, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trades) != 2 || trades[0].Price != 3 || trades[1].Price != 2 {
+		t.Errorf("expected latest trades with prices 3 and 2, got %v", trades)
+	}
+	if _, err = mdb.GetLastTrades(memoryUSDT, "", 2, false); err == nil {
+		t.Error("expected error for asset without trades")
+	}
+
+	binStart := now.Truncate(time.Hour)
+	counts, err := mdb.GetTradeCountsInflux(dia.UniswapExchange, binStart, binStart.Add(2*time.Hour), time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	total := 0
+	for _, tc := range counts["ETH-USDT"] {
+		total += tc.Count
+	}
+	if len(counts) != 1 || len(counts["ETH-USDT"]) != 2 || total != 3 {
+		t.Errorf("expected 3 trades of ETH-USDT in 2 bins, got %v", counts)
+	}
+}
+
+func TestMemoryDBFilters(t *testing.T) {
+	mdb := NewMemoryDataStore()
+	t0 := time.Now().Add(-time.Hour)
+	for _, value := range []float64{1, 2, 3} {
+		err := mdb.SaveFilterInflux(dia.FilterKing, memoryETH, "", value, t0.Add(time.Duration(value)*time.Minute))
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	price, err := mdb.GetLastPriceBefore(memoryETH, dia.FilterKing, "", t0.Add(90*time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if price.Price != 2 {
+		t.Errorf("expected price 2, got %v", price.Price)
+	}
+}
+
+func TestMemoryDBAssetQuotations(t *testing.T) {
+	mdb := NewMemoryDataStore()
+	t0 := time.Now().Add(-time.Hour)
+
+	if _, err := mdb.GetAssetQuotationCache(memoryETH); err != redis.Nil {
+		t.Errorf("expected redis.Nil on cache miss, got %v", err)
+	}
+	for _, price := range []float64{3, 1, 2} {
+		if err := mdb.SetAssetPriceUSD(memoryETH, price, t0.Add(time.Duration(price)*time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	price, err := mdb.GetAssetPriceUSD(memoryETH, t0.Add(150*time.Second))
+	if err != nil || price != 2 {
+		t.Errorf("expected price 2, got %v (%v)", price, err)
+	}
+	// As with redis, the cache holds the last quotation written.
+	price, err = mdb.GetAssetPriceUSDLatest(memoryETH)
+	if err != nil || price != 2 {
+		t.Errorf("expected cached price 2, got %v (%v)", price, err)
+	}
+}
+
+func TestMemoryDBPairsReload(t *testing.T) {
+	mdb := NewMemoryDataStore()
+	reload, err := mdb.SubscribePairsReload(dia.KrakenExchange)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Pending requests are merged.
+	for i := 0; i < 2; i++ {
+		if err := mdb.PublishPairsReload(dia.KrakenExchange); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mdb.PublishPairsReload(dia.BinanceExchange); err != nil {
+		t.Fatal(err)
+	}
+	<-reload
+	select {
+	case <-reload:
+		t.Error("expected a single pending reload")
+	default:
+	}
+}
@@ -0,0 +1,1160 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/go-redis/redis"
+	"github.com/jackc/pgx/v4"
+)
+
+// errDuplicateKey mimics the error returned by postgres on unique constraint violations.
+var errDuplicateKey = errors.New("duplicate key value violates unique constraint")
+
+// MemoryRelDB is an in-memory implementation of RelDatastore. It is meant for tests and local runs
+// without postgres and redis. Each postgres table is kept in a slice or map guarded by a single mutex.
+// Lookups of missing rows return pgx.ErrNoRows and cache misses return redis.Nil, just like RelDB does.
+type MemoryRelDB struct {
+	mu       sync.RWMutex
+	pagesize uint32
+	lastID   uint64
+
+	assets              []memoryAsset
+	exchangeSymbols     []memoryExchangeSymbol
+	exchangePairs       []memoryExchangePair
+	exchanges           map[string]dia.Exchange
+	pools               []memoryPool
+	blockchains         map[string]memoryBlockchain
+	chainConfigs        []dia.ChainConfig
+	filterConfigs       []dia.FilterConfig
+	assetEquivalences   []dia.AssetEquivalence
+	assetVolumes        map[string]float64
+	aggregatedVolumes   []dia.AggregatedVolume
+	tradesDistributions []dia.TradesDistribution
+	tradeGaps           []dia.TradeGap
+	scrapers            map[string]memoryScraper
+	oracleFeeds         []dia.OracleFeed
+	oracleUpdates       []dia.OracleUpdate
+	blockData           []dia.BlockData
+
+	nftCategories []string
+	nftClasses    []memoryNFTClass
+	nfts          []memoryNFT
+	nftTrades     map[string][]memoryNFTTrade
+	nftBids       []memoryNFTBid
+	nftOffers     []memoryNFTOffer
+
+	// caching layer
+	assetCache        map[string]dia.Asset
+	exchangePairCache map[string]dia.ExchangePair
+}
+
+type memoryAsset struct {
+	ID    string
+	Asset dia.Asset
+}
+
+type memoryExchangeSymbol struct {
+	Exchange string
+	Symbol   string
+	Verified bool
+	AssetID  string
+}
+
+type memoryExchangePair struct {
+	Pair         dia.ExchangePair
+	QuotetokenID string
+	BasetokenID  string
+}
+
+type memoryPool struct {
+	Exchange   string
+	Blockchain string
+	Address    string
+	Liquidity  map[string]float64
+}
+
+type memoryBlockchain struct {
+	Blockchain    dia.BlockChain
+	NativetokenID string
+}
+
+type memoryScraper struct {
+	Config []byte
+	State  []byte
+}
+
+// NewMemoryRelDataStore returns an empty in-memory relational datastore.
+func NewMemoryRelDataStore() *MemoryRelDB {
+	return &MemoryRelDB{
+		pagesize:          32,
+		exchanges:         make(map[string]dia.Exchange),
+		blockchains:       make(map[string]memoryBlockchain),
+		assetVolumes:      make(map[string]float64),
+		scrapers:          make(map[string]memoryScraper),
+		nftTrades:         make(map[string][]memoryNFTTrade),
+		assetCache:        make(map[string]dia.Asset),
+		exchangePairCache: make(map[string]dia.ExchangePair),
+	}
+}
+
+// newID returns a unique identifier in uuid format. It must be called with the write lock held.
+func (mrdb *MemoryRelDB) newID() string {
+	mrdb.lastID++
+	return fmt.Sprintf("00000000-0000-0000-0000-%012d", mrdb.lastID)
+}
+
+// assetIndex returns the index of the asset with @address on @blockchain in the asset table or -1.
+// It must be called with the lock held.
+func (mrdb *MemoryRelDB) assetIndex(address string, blockchain string) int {
+	for i, a := range mrdb.assets {
+		if a.Asset.Address == address && a.Asset.Blockchain == blockchain {
+			return i
+		}
+	}
+	return -1
+}
+
+// assetByID returns the asset with @ID. It must be called with the lock held.
+func (mrdb *MemoryRelDB) assetByID(ID string) (dia.Asset, bool) {
+	for _, a := range mrdb.assets {
+		if a.ID == ID {
+			return a.Asset, true
+		}
+	}
+	return dia.Asset{}, false
+}
+
+// assetID returns the ID of @asset or the empty string. It must be called with the lock held.
+func (mrdb *MemoryRelDB) assetID(asset dia.Asset) string {
+	if i := mrdb.assetIndex(asset.Address, asset.Blockchain); i >= 0 {
+		return mrdb.assets[i].ID
+	}
+	return ""
+}
+
+// selectAssets returns all assets for which @match is true.
+func (mrdb *MemoryRelDB) selectAssets(match func(asset *dia.Asset) bool) (assets []dia.Asset) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for i := range mrdb.assets {
+		if match(&mrdb.assets[i].Asset) {
+			assets = append(assets, mrdb.assets[i].Asset)
+		}
+	}
+	return
+}
+
+// ------------------------------------------------------------------------------
+// ASSETS
+// ------------------------------------------------------------------------------
+
+// SetAsset stores @asset. As in postgres, (address,blockchain) must be unique.
+func (mrdb *MemoryRelDB) SetAsset(asset dia.Asset) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	if mrdb.assetIndex(asset.Address, asset.Blockchain) >= 0 {
+		return errDuplicateKey
+	}
+	mrdb.assets = append(mrdb.assets, memoryAsset{ID: mrdb.newID(), Asset: asset})
+	return nil
+}
+
+// GetAsset returns the asset with @address on @blockchain.
+func (mrdb *MemoryRelDB) GetAsset(address, blockchain string) (dia.Asset, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	i := mrdb.assetIndex(address, blockchain)
+	if i < 0 {
+		return dia.Asset{}, pgx.ErrNoRows
+	}
+	return mrdb.assets[i].Asset, nil
+}
+
+// GetAssetByID returns the asset with @assetID.
+func (mrdb *MemoryRelDB) GetAssetByID(assetID string) (dia.Asset, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	asset, ok := mrdb.assetByID(assetID)
+	if !ok {
+		return dia.Asset{}, pgx.ErrNoRows
+	}
+	return asset, nil
+}
+
+// GetAssetID returns the unique identifier of @asset, if the entry exists.
+func (mrdb *MemoryRelDB) GetAssetID(asset dia.Asset) (string, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	ID := mrdb.assetID(asset)
+	if ID == "" {
+		return "", pgx.ErrNoRows
+	}
+	return ID, nil
+}
+
+// GetAssetsBySymbolName returns all assets with @symbol and @name. Empty arguments are ignored,
+// but at least one of them has to be non-empty.
+func (mrdb *MemoryRelDB) GetAssetsBySymbolName(symbol, name string) ([]dia.Asset, error) {
+	return mrdb.selectAssets(func(asset *dia.Asset) bool {
+		if name == "" {
+			return asset.Symbol == symbol
+		}
+		if symbol == "" {
+			return asset.Name == name
+		}
+		return asset.Symbol == symbol && asset.Name == name
+	}), nil
+}
+
+// GetAllAssets returns all assets on @blockchain.
+func (mrdb *MemoryRelDB) GetAllAssets(blockchain string) ([]dia.Asset, error) {
+	return mrdb.selectAssets(func(asset *dia.Asset) bool {
+		return asset.Blockchain == blockchain
+	}), nil
+}
+
+// GetFiatAssetBySymbol returns the fiat asset with @symbol.
+func (mrdb *MemoryRelDB) GetFiatAssetBySymbol(symbol string) (dia.Asset, error) {
+	assets := mrdb.selectAssets(func(asset *dia.Asset) bool {
+		return asset.Symbol == symbol && asset.Blockchain == dia.FIAT
+	})
+	if len(assets) == 0 {
+		return dia.Asset{}, pgx.ErrNoRows
+	}
+	return assets[0], nil
+}
+
+// IdentifyAsset returns all assets which match the non-null fields in @asset.
+func (mrdb *MemoryRelDB) IdentifyAsset(asset dia.Asset) ([]dia.Asset, error) {
+	return mrdb.selectAssets(func(a *dia.Asset) bool {
+		return (asset.Symbol == "" || a.Symbol == asset.Symbol) &&
+			(asset.Name == "" || a.Name == asset.Name) &&
+			(asset.Address == "" || a.Address == common.HexToAddress(asset.Address).Hex()) &&
+			(asset.Decimals == 0 || a.Decimals == asset.Decimals) &&
+			(asset.Blockchain == "" || a.Blockchain == asset.Blockchain)
+	}), nil
+}
+
+// GetPage returns assets per page number. @hasNextPage is true iff there is a non-empty next page.
+func (mrdb *MemoryRelDB) GetPage(pageNumber uint32) (assets []dia.Asset, hasNextPage bool, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	skip := int(mrdb.pagesize * pageNumber)
+	for i := skip; i < len(mrdb.assets) && i < skip+int(mrdb.pagesize); i++ {
+		assets = append(assets, mrdb.assets[i].Asset)
+	}
+	hasNextPage = len(mrdb.assets) > skip+int(mrdb.pagesize)
+	return
+}
+
+// Count returns the number of stored assets.
+func (mrdb *MemoryRelDB) Count() (uint32, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	return uint32(len(mrdb.assets)), nil
+}
+
+// SetAssetVolume24H stores the 24h volume of @asset. @asset must exist.
+func (mrdb *MemoryRelDB) SetAssetVolume24H(asset dia.Asset, volume float64) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	ID := mrdb.assetID(asset)
+	if ID == "" {
+		return errors.New("asset not found")
+	}
+	mrdb.assetVolumes[ID] = volume
+	return nil
+}
+
+// GetAssetVolume24H returns the 24h volume of @asset.
+func (mrdb *MemoryRelDB) GetAssetVolume24H(asset dia.Asset) (float64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	volume, ok := mrdb.assetVolumes[mrdb.assetID(asset)]
+	if !ok {
+		return 0, pgx.ErrNoRows
+	}
+	return volume, nil
+}
+
+// GetAssetsWithVOL returns the first @numAssets assets with a 24h volume, sorted by volume in descending order.
+// If @numAssets==0, all assets are returned.
+// If @substring is not the empty string, results are filtered by the symbol beginning with @substring (case insensitive).
+func (mrdb *MemoryRelDB) GetAssetsWithVOL(numAssets int64, substring string) ([]dia.Asset, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	var volumeSortedAssets []memoryAsset
+	for _, a := range mrdb.assets {
+		if _, ok := mrdb.assetVolumes[a.ID]; !ok {
+			continue
+		}
+		if substring != "" && !strings.HasPrefix(strings.ToLower(a.Asset.Symbol), strings.ToLower(substring)) {
+			continue
+		}
+		volumeSortedAssets = append(volumeSortedAssets, a)
+	}
+	sort.SliceStable(volumeSortedAssets, func(i, j int) bool {
+		return mrdb.assetVolumes[volumeSortedAssets[i].ID] > mrdb.assetVolumes[volumeSortedAssets[j].ID]
+	})
+	if numAssets > 0 && int64(len(volumeSortedAssets)) > numAssets {
+		volumeSortedAssets = volumeSortedAssets[:numAssets]
+	}
+	var assets []dia.Asset
+	for _, a := range volumeSortedAssets {
+		assets = append(assets, a.Asset)
+	}
+	return assets, nil
+}
+
+// GetAssets returns all assets which share the symbol ticker @symbol.
+func (mrdb *MemoryRelDB) GetAssets(symbol string) ([]dia.Asset, error) {
+	return mrdb.selectAssets(func(asset *dia.Asset) bool {
+		return asset.Symbol == symbol
+	}), nil
+}
+
+// GetAssetExchange returns all exchanges on which @symbol is mapped to an asset.
+func (mrdb *MemoryRelDB) GetAssetExchange(symbol string) (exchanges []string, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, es := range mrdb.exchangeSymbols {
+		if es.Symbol != symbol {
+			continue
+		}
+		if _, ok := mrdb.assetByID(es.AssetID); ok {
+			exchanges = append(exchanges, es.Exchange)
+		}
+	}
+	return
+}
+
+// GetTopAssetByVolume returns all assets with @symbol and a 24h volume, sorted by volume in descending order.
+func (mrdb *MemoryRelDB) GetTopAssetByVolume(symbol string) ([]dia.Asset, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	var volumeSortedAssets []memoryAsset
+	for _, a := range mrdb.assets {
+		if _, ok := mrdb.assetVolumes[a.ID]; ok && a.Asset.Symbol == symbol {
+			volumeSortedAssets = append(volumeSortedAssets, a)
+		}
+	}
+	sort.SliceStable(volumeSortedAssets, func(i, j int) bool {
+		return mrdb.assetVolumes[volumeSortedAssets[i].ID] > mrdb.assetVolumes[volumeSortedAssets[j].ID]
+	})
+	var assets []dia.Asset
+	for _, a := range volumeSortedAssets {
+		assets = append(assets, a.Asset)
+	}
+	return assets, nil
+}
+
+// SetAggregatedVolume stores @aggVol.
+func (mrdb *MemoryRelDB) SetAggregatedVolume(aggVol dia.AggregatedVolume) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	mrdb.aggregatedVolumes = append(mrdb.aggregatedVolumes, aggVol)
+	return nil
+}
+
+// selectAggregatedVolumes returns all aggregated volumes with quotetoken @asset in the time-range (starttime, endtime],
+// latest first.
+func (mrdb *MemoryRelDB) selectAggregatedVolumes(asset dia.Asset, starttime time.Time, endtime time.Time) (aggVolumes []dia.AggregatedVolume) {
+	mrdb.mu.RLock()
+	for _, aggVol := range mrdb.aggregatedVolumes {
+		if aggVol.Pair.QuoteToken.Address == asset.Address && aggVol.Pair.QuoteToken.Blockchain == asset.Blockchain &&
+			aggVol.Timestamp.After(starttime) && !aggVol.Timestamp.After(endtime) {
+			aggVolumes = append(aggVolumes, aggVol)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(aggVolumes, func(i, j int) bool {
+		return aggVolumes[i].Timestamp.After(aggVolumes[j].Timestamp)
+	})
+	return
+}
+
+// GetAggregatedVolumes returns all aggregated volumes of @asset in the time-range (starttime, endtime].
+func (mrdb *MemoryRelDB) GetAggregatedVolumes(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.AggregatedVolume, error) {
+	return mrdb.selectAggregatedVolumes(asset, starttime, endtime), nil
+}
+
+// GetAggVolumesByExchange returns the aggregated volumes of @asset in the time-range (starttime, endtime],
+// summed up per exchange and grouped by compute time.
+func (mrdb *MemoryRelDB) GetAggVolumesByExchange(asset dia.Asset, starttime time.Time, endtime time.Time) (exchVolumes []dia.ExchangeVolumesList, err error) {
+	for _, aggVol := range mrdb.selectAggregatedVolumes(asset, starttime, endtime) {
+		if len(exchVolumes) == 0 || aggVol.Timestamp.Before(exchVolumes[len(exchVolumes)-1].Timestamp) {
+			exchVolumes = append(exchVolumes, dia.ExchangeVolumesList{Timestamp: aggVol.Timestamp})
+		}
+		last := &exchVolumes[len(exchVolumes)-1]
+		var found bool
+		for i := range last.Volumes {
+			if last.Volumes[i].Exchange == aggVol.Exchange {
+				last.Volumes[i].Volume += aggVol.Volume
+				found = true
+			}
+		}
+		if !found {
+			last.Volumes = append(last.Volumes, dia.ExchangeVolume{Exchange: aggVol.Exchange, Volume: aggVol.Volume})
+		}
+	}
+	return
+}
+
+// GetAggVolumesByPair returns the aggregated volumes of @asset in the time-range (starttime, endtime],
+// summed up per pair and grouped by compute time.
+func (mrdb *MemoryRelDB) GetAggVolumesByPair(asset dia.Asset, starttime time.Time, endtime time.Time) (allPairVolumes []dia.PairVolumesList, err error) {
+	for _, aggVol := range mrdb.selectAggregatedVolumes(asset, starttime, endtime) {
+		if len(allPairVolumes) == 0 || aggVol.Timestamp.Before(allPairVolumes[len(allPairVolumes)-1].Timestamp) {
+			allPairVolumes = append(allPairVolumes, dia.PairVolumesList{Timestamp: aggVol.Timestamp})
+		}
+		pair := dia.Pair{QuoteToken: asset, BaseToken: aggVol.Pair.BaseToken}
+		last := &allPairVolumes[len(allPairVolumes)-1]
+		var found bool
+		for i := range last.Volumes {
+			if last.Volumes[i].Pair == pair {
+				last.Volumes[i].Volume += aggVol.Volume
+				found = true
+			}
+		}
+		if !found {
+			last.Volumes = append(last.Volumes, dia.PairVolume{Pair: pair, Volume: aggVol.Volume})
+		}
+	}
+	return
+}
+
+// SetTradesDistribution stores @tradesDist.
+func (mrdb *MemoryRelDB) SetTradesDistribution(tradesDist dia.TradesDistribution) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	mrdb.tradesDistributions = append(mrdb.tradesDistributions, tradesDist)
+	return nil
+}
+
+// GetTradesDistribution returns all trades distributions of @asset in the time-range (starttime, endtime], latest first.
+func (mrdb *MemoryRelDB) GetTradesDistribution(asset dia.Asset, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution, err error) {
+	return mrdb.getTradesDistributions(asset, false, starttime, endtime), nil
+}
+
+// GetTradesDistributionPerExchange returns all trades distributions of @asset on single exchanges
+// in the time-range (starttime, endtime], latest first.
+func (mrdb *MemoryRelDB) GetTradesDistributionPerExchange(asset dia.Asset, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution, err error) {
+	return mrdb.getTradesDistributions(asset, true, starttime, endtime), nil
+}
+
+func (mrdb *MemoryRelDB) getTradesDistributions(asset dia.Asset, perExchange bool, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution) {
+	mrdb.mu.RLock()
+	for _, td := range mrdb.tradesDistributions {
+		if td.Asset.Address == asset.Address && td.Asset.Blockchain == asset.Blockchain && (td.Exchange != "") == perExchange &&
+			td.Timestamp.After(starttime) && !td.Timestamp.After(endtime) {
+			tradesDistributions = append(tradesDistributions, td)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(tradesDistributions, func(i, j int) bool {
+		return tradesDistributions[i].Timestamp.After(tradesDistributions[j].Timestamp)
+	})
+	return
+}
+
+// SetTradeGap stores @gap with status open, unless a gap of the same pair with the same starttime exists.
+func (mrdb *MemoryRelDB) SetTradeGap(gap dia.TradeGap) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for _, g := range mrdb.tradeGaps {
+		if g.Exchange == gap.Exchange && g.Pair == gap.Pair && g.StartTime.Equal(gap.StartTime) {
+			return nil
+		}
+	}
+	gap.ID = int64(len(mrdb.tradeGaps) + 1)
+	gap.Status = dia.TradeGapOpen
+	gap.Error = ""
+	gap.UpdateTime = time.Now()
+	mrdb.tradeGaps = append(mrdb.tradeGaps, gap)
+	return nil
+}
+
+// GetTradeGaps returns all gaps of @pair on @exchange which overlap with the time-range [@starttime, @endtime), ordered by starttime.
+// If @pair is empty, the gaps of all pairs on @exchange are returned.
+func (mrdb *MemoryRelDB) GetTradeGaps(exchange string, pair string, starttime time.Time, endtime time.Time) (gaps []dia.TradeGap, err error) {
+	mrdb.mu.RLock()
+	for _, g := range mrdb.tradeGaps {
+		if g.Exchange == exchange && (pair == "" || g.Pair == pair) && g.StartTime.Before(endtime) && g.EndTime.After(starttime) {
+			gaps = append(gaps, g)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(gaps, func(i, j int) bool {
+		return gaps[i].StartTime.Before(gaps[j].StartTime)
+	})
+	return
+}
+
+// GetTradeGapsByStatus returns at most @limit gaps with @status, oldest first.
+func (mrdb *MemoryRelDB) GetTradeGapsByStatus(status string, limit int) (gaps []dia.TradeGap, err error) {
+	mrdb.mu.RLock()
+	for _, g := range mrdb.tradeGaps {
+		if g.Status == status {
+			gaps = append(gaps, g)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(gaps, func(i, j int) bool {
+		return gaps[i].StartTime.Before(gaps[j].StartTime)
+	})
+	if len(gaps) > limit {
+		gaps = gaps[:limit]
+	}
+	return
+}
+
+// UpdateTradeGapStatus sets the status of the gap with @id to @status. @errorMessage is stored along with failed gaps.
+func (mrdb *MemoryRelDB) UpdateTradeGapStatus(id int64, status string, errorMessage string) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for i := range mrdb.tradeGaps {
+		if mrdb.tradeGaps[i].ID == id {
+			mrdb.tradeGaps[i].Status = status
+			mrdb.tradeGaps[i].Error = errorMessage
+			mrdb.tradeGaps[i].UpdateTime = time.Now()
+			return nil
+		}
+	}
+	return pgx.ErrNoRows
+}
+
+// ------------------------------------------------------------------------------
+// EXCHANGE SYMBOLS AND PAIRS
+// ------------------------------------------------------------------------------
+
+// SetExchangeSymbol stores @symbol on @exchange if not yet stored.
+func (mrdb *MemoryRelDB) SetExchangeSymbol(exchange string, symbol string) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for _, es := range mrdb.exchangeSymbols {
+		if es.Exchange == exchange && es.Symbol == symbol {
+			return nil
+		}
+	}
+	mrdb.exchangeSymbols = append(mrdb.exchangeSymbols, memoryExchangeSymbol{Exchange: exchange, Symbol: symbol})
+	return nil
+}
+
+// GetExchangeSymbols returns all symbols traded on @exchange.
+// If @exchange is the empty string, all symbols are returned.
+// If @substring is not the empty string, all symbols that begin with @substring (case insensitive) are returned.
+func (mrdb *MemoryRelDB) GetExchangeSymbols(exchange string, substring string) (symbols []string, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, es := range mrdb.exchangeSymbols {
+		if exchange != "" && es.Exchange != exchange {
+			continue
+		}
+		if substring != "" && !strings.HasPrefix(strings.ToLower(es.Symbol), strings.ToLower(substring)) {
+			continue
+		}
+		symbols = append(symbols, es.Symbol)
+	}
+	return
+}
+
+// GetUnverifiedExchangeSymbols returns all symbols from @exchange which haven't been verified yet, in ascending order.
+func (mrdb *MemoryRelDB) GetUnverifiedExchangeSymbols(exchange string) (symbols []string, err error) {
+	mrdb.mu.RLock()
+	for _, es := range mrdb.exchangeSymbols {
+		if es.Exchange == exchange && !es.Verified {
+			symbols = append(symbols, es.Symbol)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.Strings(symbols)
+	return
+}
+
+// VerifyExchangeSymbol verifies @symbol on @exchange and maps it to @assetID.
+// It returns true if symbol,exchange is present and succesfully updated.
+func (mrdb *MemoryRelDB) VerifyExchangeSymbol(exchange string, symbol string, assetID string) (bool, error) {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for i, es := range mrdb.exchangeSymbols {
+		if es.Exchange == exchange && es.Symbol == symbol {
+			mrdb.exchangeSymbols[i].Verified = true
+			mrdb.exchangeSymbols[i].AssetID = assetID
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetExchangeSymbolAssetID returns the ID of the asset associated to @symbol on @exchange
+// and whether the symbol is verified.
+func (mrdb *MemoryRelDB) GetExchangeSymbolAssetID(exchange string, symbol string) (string, bool, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, es := range mrdb.exchangeSymbols {
+		if es.Exchange == exchange && es.Symbol == symbol {
+			return es.AssetID, es.Verified, nil
+		}
+	}
+	return "", false, pgx.ErrNoRows
+}
+
+// SetExchangePair stores @pair on @exchange. Underlying assets are only linked if they exist.
+// If @cache is true, the pair is also cached.
+func (mrdb *MemoryRelDB) SetExchangePair(exchange string, pair dia.ExchangePair, cache bool) error {
+	mrdb.mu.Lock()
+	index := -1
+	for i, ep := range mrdb.exchangePairs {
+		if ep.Pair.Exchange == exchange && ep.Pair.ForeignName == pair.ForeignName {
+			if ep.Pair.Symbol != pair.Symbol {
+				mrdb.mu.Unlock()
+				return errDuplicateKey
+			}
+			index = i
+		}
+	}
+	if index < 0 {
+		index = len(mrdb.exchangePairs)
+		mrdb.exchangePairs = append(mrdb.exchangePairs, memoryExchangePair{
+			Pair: dia.ExchangePair{Symbol: pair.Symbol, ForeignName: pair.ForeignName, Exchange: exchange},
+		})
+	}
+	ep := &mrdb.exchangePairs[index]
+	if ID := mrdb.assetID(pair.UnderlyingPair.BaseToken); ID != "" {
+		ep.BasetokenID = ID
+	}
+	if ID := mrdb.assetID(pair.UnderlyingPair.QuoteToken); ID != "" {
+		ep.QuotetokenID = ID
+	}
+	ep.Pair.Verified = pair.Verified
+	mrdb.mu.Unlock()
+
+	if cache {
+		return mrdb.SetExchangePairCache(exchange, pair)
+	}
+	return nil
+}
+
+// GetExchangePair returns the exchange pair given by @exchange and @foreignname along with its underlying pair.
+func (mrdb *MemoryRelDB) GetExchangePair(exchange string, foreignname string) (dia.ExchangePair, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, ep := range mrdb.exchangePairs {
+		if ep.Pair.Exchange != exchange || ep.Pair.ForeignName != foreignname {
+			continue
+		}
+		exchangepair := ep.Pair
+		if ep.QuotetokenID != "" {
+			exchangepair.UnderlyingPair.QuoteToken, _ = mrdb.assetByID(ep.QuotetokenID)
+		}
+		if ep.BasetokenID != "" {
+			exchangepair.UnderlyingPair.BaseToken, _ = mrdb.assetByID(ep.BasetokenID)
+		}
+		return exchangepair, nil
+	}
+	return dia.ExchangePair{}, pgx.ErrNoRows
+}
+
+// GetExchangePairSymbols returns symbol and foreign name of all pairs on @exchange.
+func (mrdb *MemoryRelDB) GetExchangePairSymbols(exchange string) (pairs []dia.ExchangePair, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, ep := range mrdb.exchangePairs {
+		if ep.Pair.Exchange == exchange {
+			pairs = append(pairs, dia.ExchangePair{Symbol: ep.Pair.Symbol, ForeignName: ep.Pair.ForeignName, Exchange: exchange})
+		}
+	}
+	return
+}
+
+// GetVerifiedExchangePairSymbols returns symbol and foreign name of all verified pairs on @exchange.
+func (mrdb *MemoryRelDB) GetVerifiedExchangePairSymbols(exchange string) (pairs []dia.ExchangePair, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, ep := range mrdb.exchangePairs {
+		if ep.Pair.Exchange == exchange && ep.Pair.Verified {
+			pairs = append(pairs, dia.ExchangePair{Symbol: ep.Pair.Symbol, ForeignName: ep.Pair.ForeignName, Exchange: exchange, Verified: true})
+		}
+	}
+	return
+}
+
+// GetPairs returns all exchangepairs on @exchange.
+func (mrdb *MemoryRelDB) GetPairs(exchange string) ([]dia.ExchangePair, error) {
+	return mrdb.GetExchangePairSymbols(exchange)
+}
+
+// ------------------------------------------------------------------------------
+// EXCHANGES, POOLS AND BLOCKCHAINS
+// ------------------------------------------------------------------------------
+
+// SetExchange stores @exchange. An existing exchange with the same name is overwritten.
+func (mrdb *MemoryRelDB) SetExchange(exchange dia.Exchange) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	exchange.BlockChain = dia.BlockChain{Name: exchange.BlockChain.Name}
+	mrdb.exchanges[exchange.Name] = exchange
+	return nil
+}
+
+// GetExchange returns the exchange with @name.
+func (mrdb *MemoryRelDB) GetExchange(name string) (dia.Exchange, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	exchange, ok := mrdb.exchanges[name]
+	if !ok {
+		return dia.Exchange{}, pgx.ErrNoRows
+	}
+	return exchange, nil
+}
+
+// GetAllExchanges returns all exchanges, ordered by name.
+func (mrdb *MemoryRelDB) GetAllExchanges() ([]dia.Exchange, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	var exchanges []dia.Exchange
+	for _, exchange := range mrdb.exchanges {
+		exchanges = append(exchanges, exchange)
+	}
+	sort.Slice(exchanges, func(i, j int) bool {
+		return exchanges[i].Name < exchanges[j].Name
+	})
+	return exchanges, nil
+}
+
+// GetExchangeNames returns the names of all exchanges in ascending order.
+func (mrdb *MemoryRelDB) GetExchangeNames() (allExchanges []string, err error) {
+	exchanges, err := mrdb.GetAllExchanges()
+	if err != nil {
+		return
+	}
+	for _, exchange := range exchanges {
+		allExchanges = append(allExchanges, exchange.Name)
+	}
+	return
+}
+
+// SetPool stores @pool along with the liquidity of its assets. All assets must exist.
+// The liquidity of an existing pool is updated.
+func (mrdb *MemoryRelDB) SetPool(pool dia.Pool) error {
+	if len(pool.Assetvolumes) < 2 {
+		return errors.New("not enough asset data on pool")
+	}
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	index := -1
+	for i, p := range mrdb.pools {
+		if p.Blockchain == pool.Blockchain.Name && p.Address == pool.Address {
+			index = i
+		}
+	}
+	if index < 0 {
+		index = len(mrdb.pools)
+		mrdb.pools = append(mrdb.pools, memoryPool{
+			Exchange:   pool.Exchange.Name,
+			Blockchain: pool.Blockchain.Name,
+			Address:    pool.Address,
+			Liquidity:  make(map[string]float64),
+		})
+	}
+	for _, av := range pool.Assetvolumes {
+		ID := mrdb.assetID(av.Asset)
+		if ID == "" {
+			return fmt.Errorf("asset %s on %s not found", av.Asset.Address, av.Asset.Blockchain)
+		}
+		mrdb.pools[index].Liquidity[ID] = av.Volume
+	}
+	return nil
+}
+
+// GetAllPoolAddrsExchange returns all pool addresses available for @exchange.
+func (mrdb *MemoryRelDB) GetAllPoolAddrsExchange(exchange string) (addresses []string, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, p := range mrdb.pools {
+		if p.Exchange == exchange {
+			addresses = append(addresses, p.Address)
+		}
+	}
+	return
+}
+
+// GetAllPools returns all pools along with the liquidity of their assets.
+func (mrdb *MemoryRelDB) GetAllPools() (pools []dia.Pool, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, p := range mrdb.pools {
+		pool := dia.Pool{
+			Exchange:   dia.Exchange{Name: p.Exchange},
+			Blockchain: dia.BlockChain{Name: p.Blockchain},
+			Address:    p.Address,
+		}
+		for i := range mrdb.assets {
+			if liquidity, ok := p.Liquidity[mrdb.assets[i].ID]; ok {
+				pool.Assetvolumes = append(pool.Assetvolumes, struct {
+					Asset  dia.Asset
+					Volume float64
+				}{Asset: mrdb.assets[i].Asset, Volume: liquidity})
+			}
+		}
+		pools = append(pools, pool)
+	}
+	return
+}
+
+// SetBlockchain stores @blockchain. Its native token is linked if it exists in the asset table.
+func (mrdb *MemoryRelDB) SetBlockchain(blockchain dia.BlockChain) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	mrdb.blockchains[blockchain.Name] = memoryBlockchain{
+		Blockchain:    blockchain,
+		NativetokenID: mrdb.assetID(dia.Asset{Address: blockchain.NativeToken.Address, Blockchain: blockchain.Name}),
+	}
+	return nil
+}
+
+// GetBlockchain returns the blockchain with @name. As in postgres, its native token must exist.
+func (mrdb *MemoryRelDB) GetBlockchain(name string) (dia.BlockChain, error) {
+	blockchains, _ := mrdb.GetAllBlockchains(false)
+	for _, blockchain := range blockchains {
+		if blockchain.Name == name && blockchain.NativeToken.Symbol != "" {
+			mrdb.mu.RLock()
+			nativeToken, _ := mrdb.assetByID(mrdb.blockchains[name].NativetokenID)
+			mrdb.mu.RUnlock()
+			blockchain.NativeToken = dia.Asset{Address: nativeToken.Address, Symbol: nativeToken.Symbol}
+			return blockchain, nil
+		}
+	}
+	return dia.BlockChain{}, pgx.ErrNoRows
+}
+
+// GetAllBlockchains returns all blockchains, ordered by name.
+// If @fullAsset is true it returns the complete native token as asset, otherwise only its symbol.
+func (mrdb *MemoryRelDB) GetAllBlockchains(fullAsset bool) ([]dia.BlockChain, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	var blockchains []dia.BlockChain
+	for _, b := range mrdb.blockchains {
+		blockchain := dia.BlockChain{
+			Name:                  b.Blockchain.Name,
+			GenesisDate:           b.Blockchain.GenesisDate,
+			VerificationMechanism: b.Blockchain.VerificationMechanism,
+			ChainID:               b.Blockchain.ChainID,
+		}
+		nativeToken, _ := mrdb.assetByID(b.NativetokenID)
+		blockchain.NativeToken.Symbol = nativeToken.Symbol
+		if fullAsset {
+			blockchain.NativeToken = nativeToken
+			blockchain.NativeToken.Blockchain = blockchain.Name
+		}
+		blockchains = append(blockchains, blockchain)
+	}
+	sort.Slice(blockchains, func(i, j int) bool {
+		return blockchains[i].Name < blockchains[j].Name
+	})
+	return blockchains, nil
+}
+
+// GetAllAssetsBlockchains returns all blockchain names existent in the asset table in ascending order.
+func (mrdb *MemoryRelDB) GetAllAssetsBlockchains() ([]string, error) {
+	mrdb.mu.RLock()
+	set := make(map[string]struct{})
+	var blockchains []string
+	for _, a := range mrdb.assets {
+		if _, ok := set[a.Asset.Blockchain]; !ok {
+			set[a.Asset.Blockchain] = struct{}{}
+			blockchains = append(blockchains, a.Asset.Blockchain)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.Strings(blockchains)
+	return blockchains, nil
+}
+
+// SetChainConfig stores @chainconfig. As in postgres, the chain ID must be unique.
+func (mrdb *MemoryRelDB) SetChainConfig(chainconfig dia.ChainConfig) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for _, c := range mrdb.chainConfigs {
+		if c.ChainID == chainconfig.ChainID {
+			return errDuplicateKey
+		}
+	}
+	mrdb.chainConfigs = append(mrdb.chainConfigs, chainconfig)
+	return nil
+}
+
+// GetAllChainConfig returns all chain configs.
+func (mrdb *MemoryRelDB) GetAllChainConfig() ([]dia.ChainConfig, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	return append([]dia.ChainConfig{}, mrdb.chainConfigs...), nil
+}
+
+// SetFilterConfig stores a filter config. An existing config for the same
+// filter name, asset and exchange is overwritten.
+func (mrdb *MemoryRelDB) SetFilterConfig(filterConfig dia.FilterConfig) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for i, fc := range mrdb.filterConfigs {
+		if fc.Name == filterConfig.Name && fc.Blockchain == filterConfig.Blockchain && fc.Address == filterConfig.Address && fc.Exchange == filterConfig.Exchange {
+			mrdb.filterConfigs[i] = filterConfig
+			return nil
+		}
+	}
+	mrdb.filterConfigs = append(mrdb.filterConfigs, filterConfig)
+	return nil
+}
+
+// GetAllFilterConfigs returns all filter configs.
+func (mrdb *MemoryRelDB) GetAllFilterConfigs() ([]dia.FilterConfig, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	return append([]dia.FilterConfig{}, mrdb.filterConfigs...), nil
+}
+
+// SetAssetEquivalence stores @equivalence. An existing equivalence for the same asset and exchange is overwritten.
+func (mrdb *MemoryRelDB) SetAssetEquivalence(equivalence dia.AssetEquivalence) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	equivalence.Canonical = dia.Asset{
+		Symbol:     equivalence.Canonical.Symbol,
+		Blockchain: equivalence.Canonical.Blockchain,
+		Address:    equivalence.Canonical.Address,
+	}
+	for i, ae := range mrdb.assetEquivalences {
+		if ae.Blockchain == equivalence.Blockchain && ae.Address == equivalence.Address && ae.Exchange == equivalence.Exchange {
+			mrdb.assetEquivalences[i] = equivalence
+			return nil
+		}
+	}
+	mrdb.assetEquivalences = append(mrdb.assetEquivalences, equivalence)
+	return nil
+}
+
+// GetAllAssetEquivalences returns all asset equivalences ordered by blockchain, address and exchange.
+func (mrdb *MemoryRelDB) GetAllAssetEquivalences() ([]dia.AssetEquivalence, error) {
+	mrdb.mu.RLock()
+	equivalences := append([]dia.AssetEquivalence{}, mrdb.assetEquivalences...)
+	mrdb.mu.RUnlock()
+	sort.Slice(equivalences, func(i, j int) bool {
+		if equivalences[i].Blockchain != equivalences[j].Blockchain {
+			return equivalences[i].Blockchain < equivalences[j].Blockchain
+		}
+		if equivalences[i].Address != equivalences[j].Address {
+			return equivalences[i].Address < equivalences[j].Address
+		}
+		return equivalences[i].Exchange < equivalences[j].Exchange
+	})
+	return equivalences, nil
+}
+
+// ------------------------------------------------------------------------------
+// CACHING LAYER
+// ------------------------------------------------------------------------------
+
+// SetAssetCache caches @asset by its ID. As a consequence, @asset is only cached iff it is stored.
+func (mrdb *MemoryRelDB) SetAssetCache(asset dia.Asset) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	ID := mrdb.assetID(asset)
+	if ID == "" {
+		return pgx.ErrNoRows
+	}
+	mrdb.assetCache[ID] = asset
+	return nil
+}
+
+// GetAssetCache returns the cached asset with @assetID.
+func (mrdb *MemoryRelDB) GetAssetCache(assetID string) (dia.Asset, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	asset, ok := mrdb.assetCache[assetID]
+	if !ok {
+		return dia.Asset{}, redis.Nil
+	}
+	return asset, nil
+}
+
+// CountCache returns the number of cached assets.
+func (mrdb *MemoryRelDB) CountCache() (uint32, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	return uint32(len(mrdb.assetCache)), nil
+}
+
+// SetExchangePairCache caches @pair on @exchange.
+func (mrdb *MemoryRelDB) SetExchangePairCache(exchange string, pair dia.ExchangePair) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	mrdb.exchangePairCache[keyExchangePairCache+exchange+"_"+pair.ForeignName] = pair
+	return nil
+}
+
+// GetExchangePairCache returns the cached exchange pair by @exchange and @foreignName.
+func (mrdb *MemoryRelDB) GetExchangePairCache(exchange string, foreignName string) (dia.ExchangePair, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	pair, ok := mrdb.exchangePairCache[keyExchangePairCache+exchange+"_"+foreignName]
+	if !ok {
+		return dia.ExchangePair{}, redis.Nil
+	}
+	return pair, nil
+}
+
+// ------------------------------------------------------------------------------
+// SCRAPERS AND BLOCK DATA
+// ------------------------------------------------------------------------------
+
+// GetKeys is not supported by MemoryRelDB, as there is no information schema.
+func (mrdb *MemoryRelDB) GetKeys(table string) ([]string, error) {
+	return []string{}, errNotSupportedInMemory
+}
+
+// GetScraperState decodes the state of @scraperName into @state, which must be a pointer.
+func (mrdb *MemoryRelDB) GetScraperState(ctx context.Context, scraperName string, state ScraperState) error {
+	mrdb.mu.RLock()
+	scraper, ok := mrdb.scrapers[scraperName]
+	mrdb.mu.RUnlock()
+	if !ok || scraper.State == nil {
+		return pgx.ErrNoRows
+	}
+	return json.Unmarshal(scraper.State, state)
+}
+
+// SetScraperState stores the JSON encoded @state of @scraperName.
+func (mrdb *MemoryRelDB) SetScraperState(ctx context.Context, scraperName string, state ScraperState) error {
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	scraper := mrdb.scrapers[scraperName]
+	scraper.State = data
+	mrdb.scrapers[scraperName] = scraper
+	return nil
+}
+
+// GetScraperConfig decodes the config of @scraperName into @config, which must be a pointer.
+func (mrdb *MemoryRelDB) GetScraperConfig(ctx context.Context, scraperName string, config ScraperConfig) error {
+	mrdb.mu.RLock()
+	scraper, ok := mrdb.scrapers[scraperName]
+	mrdb.mu.RUnlock()
+	if !ok || scraper.Config == nil {
+		return pgx.ErrNoRows
+	}
+	return json.Unmarshal(scraper.Config, config)
+}
+
+// SetScraperConfig stores the JSON encoded @config of @scraperName.
+func (mrdb *MemoryRelDB) SetScraperConfig(ctx context.Context, scraperName string, config ScraperConfig) error {
+	data, err := json.Marshal(config)
+	if err != nil {
+		return err
+	}
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	scraper := mrdb.scrapers[scraperName]
+	scraper.Config = data
+	mrdb.scrapers[scraperName] = scraper
+	return nil
+}
+
+// SetOracleFeed stores @feed, replacing an existing feed with the same name.
+func (mrdb *MemoryRelDB) SetOracleFeed(feed dia.OracleFeed) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for i := range mrdb.oracleFeeds {
+		if mrdb.oracleFeeds[i].Name == feed.Name {
+			mrdb.oracleFeeds[i] = feed
+			return nil
+		}
+	}
+	mrdb.oracleFeeds = append(mrdb.oracleFeeds, feed)
+	return nil
+}
+
+// GetOracleFeeds returns all oracle feeds ordered by name.
+func (mrdb *MemoryRelDB) GetOracleFeeds() ([]dia.OracleFeed, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	feeds := append([]dia.OracleFeed{}, mrdb.oracleFeeds...)
+	sort.Slice(feeds, func(i, j int) bool { return feeds[i].Name < feeds[j].Name })
+	return feeds, nil
+}
+
+// SetOracleUpdate logs @update with the next ID.
+func (mrdb *MemoryRelDB) SetOracleUpdate(update dia.OracleUpdate) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	update.ID = int64(len(mrdb.oracleUpdates) + 1)
+	update.UpdateTime = time.Now()
+	mrdb.oracleUpdates = append(mrdb.oracleUpdates, update)
+	return nil
+}
+
+// GetOracleUpdates returns all updates of @feed with timestamps in [@starttime, @endtime), ordered by timestamp.
+func (mrdb *MemoryRelDB) GetOracleUpdates(feed string, starttime time.Time, endtime time.Time) (updates []dia.OracleUpdate, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, update := range mrdb.oracleUpdates {
+		if update.Feed == feed && !update.Timestamp.Before(starttime) && update.Timestamp.Before(endtime) {
+			updates = append(updates, update)
+		}
+	}
+	sort.SliceStable(updates, func(i, j int) bool { return updates[i].Timestamp.Before(updates[j].Timestamp) })
+	return
+}
+
+// SetBlockData stores @blockdata. As in postgres, (blockchain,blocknumber) must be unique.
+func (mrdb *MemoryRelDB) SetBlockData(blockdata dia.BlockData) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for _, bd := range mrdb.blockData {
+		if bd.BlockchainName == blockdata.BlockchainName && bd.BlockNumber == blockdata.BlockNumber {
+			return errDuplicateKey
+		}
+	}
+	mrdb.blockData = append(mrdb.blockData, blockdata)
+	return nil
+}
+
+// GetBlockData returns information on the block with @blocknumber on @blockchain.
+func (mrdb *MemoryRelDB) GetBlockData(blockchain string, blocknumber int64) (dia.BlockData, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, bd := range mrdb.blockData {
+		if bd.BlockchainName == blockchain && bd.BlockNumber == blocknumber {
+			return bd, nil
+		}
+	}
+	return dia.BlockData{}, pgx.ErrNoRows
+}
+
+// GetLastBlockBlockscraper returns the last scraped block on @blockchain.
+func (mrdb *MemoryRelDB) GetLastBlockBlockscraper(blockchain string) (int64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	var blockNumber int64
+	var found bool
+	for _, bd := range mrdb.blockData {
+		if bd.BlockchainName == blockchain && (!found || bd.BlockNumber > blockNumber) {
+			blockNumber = bd.BlockNumber
+			found = true
+		}
+	}
+	if !found {
+		return 0, pgx.ErrNoRows
+	}
+	return blockNumber, nil
+}
@@ -0,0 +1,519 @@
+package models
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/jackc/pgx/v4"
+)
+
+type memoryNFTClass struct {
+	ID       string
+	NFTClass dia.NFTClass
+}
+
+type memoryNFT struct {
+	ID      string
+	ClassID string
+	NFT     dia.NFT
+}
+
+type memoryNFTTrade struct {
+	ClassID string
+	NFTID   string
+	Trade   dia.NFTTrade
+}
+
+type memoryNFTBid struct {
+	ClassID string
+	NFTID   string
+	Bid     dia.NFTBid
+}
+
+type memoryNFTOffer struct {
+	ClassID string
+	NFTID   string
+	Offer   dia.NFTOffer
+}
+
+// nftClassID returns the ID of the nft class with @address on @blockchain or the empty string.
+// It must be called with the lock held.
+func (mrdb *MemoryRelDB) nftClassID(address string, blockchain string) string {
+	for _, c := range mrdb.nftClasses {
+		if c.NFTClass.Address == address && c.NFTClass.Blockchain == blockchain {
+			return c.ID
+		}
+	}
+	return ""
+}
+
+// nftID returns the ID of the nft with @tokenID in the class with @address on @blockchain or the empty string.
+// It must be called with the lock held.
+func (mrdb *MemoryRelDB) nftID(address string, blockchain string, tokenID string) string {
+	classID := mrdb.nftClassID(address, blockchain)
+	if classID == "" {
+		return ""
+	}
+	for _, n := range mrdb.nfts {
+		if n.ClassID == classID && n.NFT.TokenID == tokenID {
+			return n.ID
+		}
+	}
+	return ""
+}
+
+// AddNFTCategory adds @category to the available NFT categories. In postgres, categories are
+// populated on initialisation of the database.
+func (mrdb *MemoryRelDB) AddNFTCategory(category string) {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	mrdb.nftCategories = append(mrdb.nftCategories, category)
+}
+
+// SetNFTClass stores @nftClass. As in postgres, (address,blockchain) must be unique.
+func (mrdb *MemoryRelDB) SetNFTClass(nftClass dia.NFTClass) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	if mrdb.nftClassID(nftClass.Address, nftClass.Blockchain) != "" {
+		return errDuplicateKey
+	}
+	mrdb.nftClasses = append(mrdb.nftClasses, memoryNFTClass{ID: mrdb.newID(), NFTClass: nftClass})
+	return nil
+}
+
+// GetNFTClass returns the nft class with @address on @blockchain.
+func (mrdb *MemoryRelDB) GetNFTClass(address string, blockchain string) (dia.NFTClass, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, c := range mrdb.nftClasses {
+		if c.NFTClass.Address == address && c.NFTClass.Blockchain == blockchain {
+			return c.NFTClass, nil
+		}
+	}
+	return dia.NFTClass{}, pgx.ErrNoRows
+}
+
+// GetNFTClassID returns the ID of the nft class with @address on @blockchain.
+func (mrdb *MemoryRelDB) GetNFTClassID(address string, blockchain string) (string, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	ID := mrdb.nftClassID(address, blockchain)
+	if ID == "" {
+		return "", pgx.ErrNoRows
+	}
+	return ID, nil
+}
+
+// GetNFTClassByID returns the nft class with @id.
+func (mrdb *MemoryRelDB) GetNFTClassByID(id string) (dia.NFTClass, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for _, c := range mrdb.nftClasses {
+		if c.ID == id {
+			return c.NFTClass, nil
+		}
+	}
+	return dia.NFTClass{}, pgx.ErrNoRows
+}
+
+// GetAllNFTClasses returns all NFT classes on @blockchain, ordered by name in descending order.
+func (mrdb *MemoryRelDB) GetAllNFTClasses(blockchain string) (nftClasses []dia.NFTClass, err error) {
+	mrdb.mu.RLock()
+	for _, c := range mrdb.nftClasses {
+		if c.NFTClass.Blockchain == blockchain {
+			nftClasses = append(nftClasses, c.NFTClass)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(nftClasses, func(i, j int) bool {
+		return nftClasses[i].Name > nftClasses[j].Name
+	})
+	return
+}
+
+// GetNFTClasses returns @limit NFT classes with @offset.
+func (mrdb *MemoryRelDB) GetNFTClasses(limit, offset uint64) (nftClasses []dia.NFTClass, err error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	for i := offset; i < uint64(len(mrdb.nftClasses)) && i < offset+limit; i++ {
+		nftClasses = append(nftClasses, mrdb.nftClasses[i].NFTClass)
+	}
+	return
+}
+
+// UpdateNFTClassCategory sets the category of the nft class with @nftclassID to @category.
+// It returns true if the class exists.
+func (mrdb *MemoryRelDB) UpdateNFTClassCategory(nftclassID string, category string) (bool, error) {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	for i, c := range mrdb.nftClasses {
+		if c.ID == nftclassID {
+			mrdb.nftClasses[i].NFTClass.Category = category
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// GetNFTCategories returns all available NFT categories.
+func (mrdb *MemoryRelDB) GetNFTCategories() ([]string, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	return append([]string{}, mrdb.nftCategories...), nil
+}
+
+// SetNFT stores @nft. Its nft class must exist.
+func (mrdb *MemoryRelDB) SetNFT(nft dia.NFT) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	classID := mrdb.nftClassID(nft.NFTClass.Address, nft.NFTClass.Blockchain)
+	if classID == "" {
+		return pgx.ErrNoRows
+	}
+	if mrdb.nftID(nft.NFTClass.Address, nft.NFTClass.Blockchain, nft.TokenID) != "" {
+		return errDuplicateKey
+	}
+	mrdb.nfts = append(mrdb.nfts, memoryNFT{ID: mrdb.newID(), ClassID: classID, NFT: nft})
+	return nil
+}
+
+// GetNFT returns the nft with @tokenID in the class with @address on @blockchain.
+func (mrdb *MemoryRelDB) GetNFT(address string, blockchain string, tokenID string) (dia.NFT, error) {
+	if blockchain == dia.ETHEREUM {
+		address = common.HexToAddress(address).Hex()
+	}
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	ID := mrdb.nftID(address, blockchain, tokenID)
+	for _, n := range mrdb.nfts {
+		if ID != "" && n.ID == ID {
+			nft := n.NFT
+			for _, c := range mrdb.nftClasses {
+				if c.ID == n.ClassID {
+					nft.NFTClass = c.NFTClass
+				}
+			}
+			return nft, nil
+		}
+	}
+	return dia.NFT{}, pgx.ErrNoRows
+}
+
+// GetNFTID returns the ID of the nft with @tokenID in the class with @address on @blockchain.
+func (mrdb *MemoryRelDB) GetNFTID(address string, blockchain string, tokenID string) (string, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	ID := mrdb.nftID(address, blockchain, tokenID)
+	if ID == "" {
+		return "", pgx.ErrNoRows
+	}
+	return ID, nil
+}
+
+// GetLastBlockheightTopshot returns the block number stored in the attributes of the latest topshot nft.
+func (mrdb *MemoryRelDB) GetLastBlockheightTopshot(upperBound time.Time) (uint64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	classID := mrdb.nftClassID("0x0b2a3299cc857e29", dia.FLOW)
+	var last *dia.NFT
+	for i, n := range mrdb.nfts {
+		if classID != "" && n.ClassID == classID && (last == nil || n.NFT.CreationTime.After(last.CreationTime)) {
+			last = &mrdb.nfts[i].NFT
+		}
+	}
+	if last == nil {
+		return 0, pgx.ErrNoRows
+	}
+	blocknumber, ok := last.Attributes["blocknumber"].(float64)
+	if !ok {
+		return 0, errors.New("no blocknumber in attributes")
+	}
+	return uint64(blocknumber), nil
+}
+
+// SetNFTTrade is a wrapper for SetNFTTradeToTable that stores @trade into the main nfttrade table.
+func (mrdb *MemoryRelDB) SetNFTTrade(trade dia.NFTTrade) error {
+	return mrdb.SetNFTTradeToTable(trade, NfttradeCurrTable)
+}
+
+// SetNFTTradeToTable stores @trade into @table. The traded nft must exist.
+func (mrdb *MemoryRelDB) SetNFTTradeToTable(trade dia.NFTTrade, table string) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	nftID := mrdb.nftID(trade.NFT.NFTClass.Address, trade.NFT.NFTClass.Blockchain, trade.NFT.TokenID)
+	if nftID == "" {
+		return pgx.ErrNoRows
+	}
+	mrdb.nftTrades[table] = append(mrdb.nftTrades[table], memoryNFTTrade{
+		ClassID: mrdb.nftClassID(trade.NFT.NFTClass.Address, trade.NFT.NFTClass.Blockchain),
+		NFTID:   nftID,
+		Trade:   trade,
+	})
+	return nil
+}
+
+// GetLastBlockNFTTrade returns the last blocknumber that was scraped for trades in @nftclass.
+func (mrdb *MemoryRelDB) GetLastBlockNFTTrade(nftclass dia.NFTClass) (uint64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	classID := mrdb.nftClassID(nftclass.Address, nftclass.Blockchain)
+	var blocknumber uint64
+	var found bool
+	for _, t := range mrdb.nftTrades[NfttradeCurrTable] {
+		if classID != "" && t.ClassID == classID && (!found || t.Trade.BlockNumber > blocknumber) {
+			blocknumber = t.Trade.BlockNumber
+			found = true
+		}
+	}
+	if !found {
+		return 0, pgx.ErrNoRows
+	}
+	return blocknumber, nil
+}
+
+// GetNFTTradesFromTable returns all trades from @table done on the nft given by @address, @blockchain and @tokenID
+// in the time-range (starttime, endtime), latest first.
+func (mrdb *MemoryRelDB) GetNFTTradesFromTable(address string, blockchain string, tokenID string, starttime time.Time, endtime time.Time, table string) (trades []dia.NFTTrade, err error) {
+	mrdb.mu.RLock()
+	nftID := mrdb.nftID(address, blockchain, tokenID)
+	if nftID == "" {
+		mrdb.mu.RUnlock()
+		return nil, pgx.ErrNoRows
+	}
+	for _, t := range mrdb.nftTrades[table] {
+		if t.NFTID == nftID && t.Trade.Timestamp.After(starttime) && t.Trade.Timestamp.Before(endtime) {
+			trades = append(trades, t.Trade)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(trades, func(i, j int) bool {
+		return trades[i].Timestamp.After(trades[j].Timestamp)
+	})
+	return
+}
+
+// GetNFTTrades returns all trades done on the nft given by @address, @blockchain and @tokenID.
+func (mrdb *MemoryRelDB) GetNFTTrades(address string, blockchain string, tokenID string) ([]dia.NFTTrade, error) {
+	return mrdb.GetNFTTradesFromTable(address, blockchain, tokenID, time.Time{}, time.Now(), NfttradeCurrTable)
+}
+
+// GetNFTFloor returns the floor price of @nftclass in the window of length @floorWindowSeconds ending at @timestamp.
+func (mrdb *MemoryRelDB) GetNFTFloor(nftclass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration) (float64, error) {
+	mrdb.mu.RLock()
+	classID := mrdb.nftClassID(nftclass.Address, nftclass.Blockchain)
+	var floorPrice *big.Int
+	for _, t := range mrdb.nftTrades[NfttradeCurrTable] {
+		if classID == "" || t.ClassID != classID || t.Trade.Price == nil || t.Trade.Price.Sign() <= 0 {
+			continue
+		}
+		if t.Trade.Timestamp.Unix() > timestamp.Unix() || t.Trade.Timestamp.Unix() <= timestamp.Add(-floorWindowSeconds).Unix() {
+			continue
+		}
+		if floorPrice == nil || t.Trade.Price.Cmp(floorPrice) < 0 {
+			floorPrice = t.Trade.Price
+		}
+	}
+	mrdb.mu.RUnlock()
+	if floorPrice == nil {
+		return 0, errors.New("no result in given time-range")
+	}
+	floor, _ := new(big.Float).Quo(new(big.Float).SetInt(floorPrice), new(big.Float).SetFloat64(math.Pow10(18))).Float64()
+	return floor, nil
+}
+
+// GetNFTFloorRecursive returns the floor price of @nftClass. If necessary, it iterates back in time until it finds a floor price.
+func (mrdb *MemoryRelDB) GetNFTFloorRecursive(nftClass dia.NFTClass, timestamp time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floor float64, err error) {
+	for count := 0; count < stepBackLimit; count++ {
+		floor, err = mrdb.GetNFTFloor(nftClass, timestamp, floorWindowSeconds)
+		if err == nil || !strings.Contains(err.Error(), "no result") {
+			return
+		}
+		timestamp = timestamp.Add(-floorWindowSeconds)
+	}
+	return
+}
+
+// GetNFTFloorRange returns a slice of floor prices in the given time range @starttime -- @endtime.
+func (mrdb *MemoryRelDB) GetNFTFloorRange(nftClass dia.NFTClass, starttime time.Time, endtime time.Time, floorWindowSeconds time.Duration, stepBackLimit int) (floorPrices []float64, err error) {
+	// Find initial floor price by going back in time if necessary.
+	floor, err := mrdb.GetNFTFloorRecursive(nftClass, starttime, floorWindowSeconds, stepBackLimit)
+	if err != nil {
+		if strings.Contains(err.Error(), "no result") {
+			log.Warn("could not find initial floor price.")
+		} else {
+			return
+		}
+	}
+	floorPrices = append(floorPrices, floor)
+	starttime = starttime.Add(floorWindowSeconds)
+
+	// Continue filling floor prices. If none is found add the last one.
+	for starttime.Before(endtime) {
+		floor, err := mrdb.GetNFTFloor(nftClass, starttime, floorWindowSeconds)
+		if err != nil {
+			floorPrices = append(floorPrices, floorPrices[len(floorPrices)-1])
+		} else {
+			floorPrices = append(floorPrices, floor)
+		}
+		starttime = starttime.Add(floorWindowSeconds)
+	}
+	return
+}
+
+// SetNFTBid stores @bid. The nft must exist.
+func (mrdb *MemoryRelDB) SetNFTBid(bid dia.NFTBid) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	nftID := mrdb.nftID(bid.NFT.NFTClass.Address, bid.NFT.NFTClass.Blockchain, bid.NFT.TokenID)
+	if nftID == "" {
+		return pgx.ErrNoRows
+	}
+	mrdb.nftBids = append(mrdb.nftBids, memoryNFTBid{
+		ClassID: mrdb.nftClassID(bid.NFT.NFTClass.Address, bid.NFT.NFTClass.Blockchain),
+		NFTID:   nftID,
+		Bid:     bid,
+	})
+	return nil
+}
+
+// GetNFTBids returns all bids done on the nft given by @address, @blockchain and @tokenID, latest first.
+func (mrdb *MemoryRelDB) GetNFTBids(address string, blockchain string, tokenID string) (bids []dia.NFTBid, err error) {
+	mrdb.mu.RLock()
+	nftID := mrdb.nftID(address, blockchain, tokenID)
+	for _, b := range mrdb.nftBids {
+		if nftID != "" && b.NFTID == nftID {
+			bids = append(bids, b.Bid)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(bids, func(i, j int) bool {
+		return bids[i].Timestamp.After(bids[j].Timestamp)
+	})
+	return
+}
+
+// GetLastNFTBid returns the last bid on the nft with @address and @tokenID.
+// Here, 'last' refers to the largest block position in the largest block number
+// smaller or equal than @blockNumber.
+func (mrdb *MemoryRelDB) GetLastNFTBid(address string, blockchain string, tokenID string, blockNumber uint64, blockPosition uint) (dia.NFTBid, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	nftID := mrdb.nftID(address, blockchain, tokenID)
+	var last *dia.NFTBid
+	for i, b := range mrdb.nftBids {
+		if nftID == "" || b.NFTID != nftID || b.Bid.BlockNumber > blockNumber {
+			continue
+		}
+		if last == nil || b.Bid.BlockNumber > last.BlockNumber ||
+			(b.Bid.BlockNumber == last.BlockNumber && b.Bid.BlockPosition > last.BlockPosition) {
+			last = &mrdb.nftBids[i].Bid
+		}
+	}
+	if last == nil {
+		return dia.NFTBid{}, pgx.ErrNoRows
+	}
+	return *last, nil
+}
+
+// GetLastBlockNFTBid returns the last blocknumber that was scraped for bids in @nftclass.
+func (mrdb *MemoryRelDB) GetLastBlockNFTBid(nftclass dia.NFTClass) (uint64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	classID := mrdb.nftClassID(nftclass.Address, nftclass.Blockchain)
+	var blocknumbers []uint64
+	for _, b := range mrdb.nftBids {
+		if classID != "" && b.ClassID == classID {
+			blocknumbers = append(blocknumbers, b.Bid.BlockNumber)
+		}
+	}
+	return maxBlocknumber(blocknumbers)
+}
+
+// SetNFTOffer stores @offer. The nft must exist.
+func (mrdb *MemoryRelDB) SetNFTOffer(offer dia.NFTOffer) error {
+	mrdb.mu.Lock()
+	defer mrdb.mu.Unlock()
+	nftID := mrdb.nftID(offer.NFT.NFTClass.Address, offer.NFT.NFTClass.Blockchain, offer.NFT.TokenID)
+	if nftID == "" {
+		return pgx.ErrNoRows
+	}
+	mrdb.nftOffers = append(mrdb.nftOffers, memoryNFTOffer{
+		ClassID: mrdb.nftClassID(offer.NFT.NFTClass.Address, offer.NFT.NFTClass.Blockchain),
+		NFTID:   nftID,
+		Offer:   offer,
+	})
+	return nil
+}
+
+// GetNFTOffers returns all offers done on the nft given by @address, @blockchain and @tokenID, latest first.
+func (mrdb *MemoryRelDB) GetNFTOffers(address string, blockchain string, tokenID string) (offers []dia.NFTOffer, err error) {
+	mrdb.mu.RLock()
+	nftID := mrdb.nftID(address, blockchain, tokenID)
+	for _, o := range mrdb.nftOffers {
+		if nftID != "" && o.NFTID == nftID {
+			offers = append(offers, o.Offer)
+		}
+	}
+	mrdb.mu.RUnlock()
+	sort.SliceStable(offers, func(i, j int) bool {
+		return offers[i].Timestamp.After(offers[j].Timestamp)
+	})
+	return
+}
+
+// GetLastNFTOffer returns the last offer on the nft with @address and @tokenID.
+// Here, 'last' refers to the largest block position in the largest block number
+// smaller or equal than @blockNumber.
+func (mrdb *MemoryRelDB) GetLastNFTOffer(address string, blockchain string, tokenID string, blockNumber uint64, blockPosition uint) (dia.NFTOffer, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	nftID := mrdb.nftID(address, blockchain, tokenID)
+	var last *dia.NFTOffer
+	for i, o := range mrdb.nftOffers {
+		if nftID == "" || o.NFTID != nftID || o.Offer.BlockNumber > blockNumber {
+			continue
+		}
+		if last == nil || o.Offer.BlockNumber > last.BlockNumber ||
+			(o.Offer.BlockNumber == last.BlockNumber && o.Offer.BlockPosition > last.BlockPosition) {
+			last = &mrdb.nftOffers[i].Offer
+		}
+	}
+	if last == nil {
+		return dia.NFTOffer{}, pgx.ErrNoRows
+	}
+	return *last, nil
+}
+
+// GetLastBlockNFTOffer returns the last blocknumber that was scraped for offers in @nftclass.
+func (mrdb *MemoryRelDB) GetLastBlockNFTOffer(nftclass dia.NFTClass) (uint64, error) {
+	mrdb.mu.RLock()
+	defer mrdb.mu.RUnlock()
+	classID := mrdb.nftClassID(nftclass.Address, nftclass.Blockchain)
+	var blocknumbers []uint64
+	for _, o := range mrdb.nftOffers {
+		if classID != "" && o.ClassID == classID {
+			blocknumbers = append(blocknumbers, o.Offer.BlockNumber)
+		}
+	}
+	return maxBlocknumber(blocknumbers)
+}
+
+// maxBlocknumber returns the largest element of @blocknumbers or pgx.ErrNoRows if it is empty.
+func maxBlocknumber(blocknumbers []uint64) (uint64, error) {
+	if len(blocknumbers) == 0 {
+		return 0, pgx.ErrNoRows
+	}
+	max := blocknumbers[0]
+	for _, b := range blocknumbers[1:] {
+		if b > max {
+			max = b
+		}
+	}
+	return max, nil
+}
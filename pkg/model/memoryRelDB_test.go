@@ -0,0 +1,217 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+func TestMemoryRelDBAssets(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	if err := mrdb.SetAsset(memoryETH); err != nil {
+		t.Fatal(err)
+	}
+	if err := mrdb.SetAsset(memoryETH); err == nil {
+		t.Error("expected error on duplicate asset")
+	}
+	if _, err := mrdb.GetAsset(memoryUSDT.Address, memoryUSDT.Blockchain); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows for missing asset, got %v", err)
+	}
+	ID, err := mrdb.GetAssetID(memoryETH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	asset, err := mrdb.GetAssetByID(ID)
+	if err != nil || asset != memoryETH {
+		t.Errorf("expected %v, got %v (%v)", memoryETH, asset, err)
+	}
+}
+
+func TestMemoryRelDBAssetsBySymbol(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	bridgedETH := dia.Asset{Symbol: "ETH", Name: "Ether", Address: "0x2170Ed0880ac9A755fd29B2688956BD959F933F8", Decimals: 18, Blockchain: dia.BINANCESMARTCHAIN}
+	for _, asset := range []dia.Asset{memoryETH, bridgedETH, memoryUSDT} {
+		if err := mrdb.SetAsset(asset); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := mrdb.SetAssetVolume24H(memoryETH, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := mrdb.SetAssetVolume24H(bridgedETH, 1000); err != nil {
+		t.Fatal(err)
+	}
+
+	assets, err := mrdb.GetAssets("ETH")
+	if err != nil || len(assets) != 2 {
+		t.Errorf("expected both ETH assets, got %v (%v)", assets, err)
+	}
+	assets, err = mrdb.GetTopAssetByVolume("ETH")
+	if err != nil || len(assets) != 2 || assets[0] != bridgedETH {
+		t.Errorf("expected %v first, got %v (%v)", bridgedETH, assets, err)
+	}
+
+	ID, err := mrdb.GetAssetID(memoryETH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, exchange := range []string{dia.UniswapExchange, dia.BinanceExchange} {
+		if err := mrdb.SetExchangeSymbol(exchange, "ETH"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := mrdb.VerifyExchangeSymbol(dia.UniswapExchange, "ETH", ID); err != nil {
+		t.Fatal(err)
+	}
+	exchanges, err := mrdb.GetAssetExchange("ETH")
+	if err != nil || len(exchanges) != 1 || exchanges[0] != dia.UniswapExchange {
+		t.Errorf("expected only the mapped exchange %s, got %v (%v)", dia.UniswapExchange, exchanges, err)
+	}
+}
+
+func TestMemoryRelDBExchangePairs(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	if err := mrdb.SetAsset(memoryETH); err != nil {
+		t.Fatal(err)
+	}
+	pair := dia.ExchangePair{
+		Symbol:         "ETH",
+		ForeignName:    "ETH-USDT",
+		Exchange:       dia.UniswapExchange,
+		Verified:       true,
+		UnderlyingPair: dia.Pair{QuoteToken: memoryETH, BaseToken: memoryUSDT},
+	}
+	if err := mrdb.SetExchangePair(dia.UniswapExchange, pair, true); err != nil {
+		t.Fatal(err)
+	}
+
+	stored, err := mrdb.GetExchangePair(dia.UniswapExchange, "ETH-USDT")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !stored.Verified || stored.UnderlyingPair.QuoteToken != memoryETH {
+		t.Errorf("unexpected pair %v", stored)
+	}
+	// USDT is not in the asset table, so it cannot be linked.
+	if stored.UnderlyingPair.BaseToken != (dia.Asset{}) {
+		t.Errorf("expected empty basetoken, got %v", stored.UnderlyingPair.BaseToken)
+	}
+	cached, err := mrdb.GetExchangePairCache(dia.UniswapExchange, "ETH-USDT")
+	if err != nil || cached.UnderlyingPair.BaseToken != memoryUSDT {
+		t.Errorf("expected cached pair %v, got %v (%v)", pair, cached, err)
+	}
+
+	unverified := dia.ExchangePair{Symbol: "ETH", ForeignName: "ETH-DAI", Exchange: dia.UniswapExchange}
+	if err := mrdb.SetExchangePair(dia.UniswapExchange, unverified, false); err != nil {
+		t.Fatal(err)
+	}
+	verified, err := mrdb.GetVerifiedExchangePairSymbols(dia.UniswapExchange)
+	if err != nil || len(verified) != 1 || verified[0].ForeignName != "ETH-USDT" {
+		t.Errorf("expected only verified pair ETH-USDT, got %v (%v)", verified, err)
+	}
+}
+
+func TestMemoryRelDBScraperState(t *testing.T) {
+	type state struct {
+		LastBlock uint64
+	}
+	mrdb := NewMemoryRelDataStore()
+	var s state
+	if err := mrdb.GetScraperState(context.Background(), "scraper", &s); !errors.Is(err, pgx.ErrNoRows) {
+		t.Errorf("expected pgx.ErrNoRows for missing state, got %v", err)
+	}
+	if err := mrdb.SetScraperState(context.Background(), "scraper", &state{LastBlock: 42}); err != nil {
+		t.Fatal(err)
+	}
+	if err := mrdb.GetScraperState(context.Background(), "scraper", &s); err != nil || s.LastBlock != 42 {
+		t.Errorf("expected last block 42, got %d (%v)", s.LastBlock, err)
+	}
+}
+
+func TestMemoryRelDBOracleFeeds(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	for _, feed := range []dia.OracleFeed{
+		{Name: "mai", Source: dia.OracleSourceAssetQuotation, DeviationPermille: 10},
+		{Name: "astar", Source: dia.OracleSourceAssetQuotation},
+		{Name: "mai", Source: dia.OracleSourceAssetQuotation, DeviationPermille: 5},
+	} {
+		if err := mrdb.SetOracleFeed(feed); err != nil {
+			t.Fatal(err)
+		}
+	}
+	feeds, err := mrdb.GetOracleFeeds()
+	if err != nil || len(feeds) != 2 {
+		t.Fatalf("expected 2 feeds, got %v (%v)", feeds, err)
+	}
+	if feeds[0].Name != "astar" || feeds[1].DeviationPermille != 5 {
+		t.Errorf("expected feeds ordered by name with replaced spec, got %v", feeds)
+	}
+}
+
+func TestMemoryRelDBTradeGaps(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	starttime := time.Unix(1640995200, 0)
+	gap := dia.TradeGap{Exchange: dia.KrakenExchange, Pair: "XBTUSD", StartTime: starttime, EndTime: starttime.Add(time.Hour)}
+	for i := 0; i < 2; i++ {
+		if err := mrdb.SetTradeGap(gap); err != nil {
+			t.Fatal(err)
+		}
+	}
+	gaps, err := mrdb.GetTradeGapsByStatus(dia.TradeGapOpen, 10)
+	if err != nil || len(gaps) != 1 {
+		t.Fatalf("expected a single open gap, got %v (%v)", gaps, err)
+	}
+	if err = mrdb.UpdateTradeGapStatus(gaps[0].ID, dia.TradeGapMerged, ""); err != nil {
+		t.Fatal(err)
+	}
+	if gaps, _ = mrdb.GetTradeGapsByStatus(dia.TradeGapOpen, 10); len(gaps) != 0 {
+		t.Errorf("expected no open gaps, got %v", gaps)
+	}
+	if gaps, _ = mrdb.GetTradeGaps(dia.KrakenExchange, "", starttime.Add(30*time.Minute), starttime.Add(2*time.Hour)); len(gaps) != 1 || gaps[0].Status != dia.TradeGapMerged {
+		t.Errorf("expected merged gap overlapping the time range, got %v", gaps)
+	}
+	if gaps, _ = mrdb.GetTradeGaps(dia.KrakenExchange, "XBTUSD", starttime.Add(time.Hour), starttime.Add(2*time.Hour)); len(gaps) != 0 {
+		t.Errorf("expected no gap after the time range, got %v", gaps)
+	}
+}
+
+func TestMemoryRelDBNFTFloor(t *testing.T) {
+	mrdb := NewMemoryRelDataStore()
+	class := dia.NFTClass{Address: "0xBC4CA0EdA7647A8aB7C2061c2E118A18a936f13D", Symbol: "BAYC", Name: "BoredApeYachtClub", Blockchain: dia.ETHEREUM}
+	if err := mrdb.SetNFTClass(class); err != nil {
+		t.Fatal(err)
+	}
+	nft := dia.NFT{NFTClass: class, TokenID: "1"}
+	if err := mrdb.SetNFT(nft); err != nil {
+		t.Fatal(err)
+	}
+
+	t0 := time.Unix(1651406400, 0)
+	for i, price := range []int64{0, 3, 2} {
+		err := mrdb.SetNFTTrade(dia.NFTTrade{
+			NFT:       nft,
+			Price:     new(big.Int).Mul(big.NewInt(price), big.NewInt(1e18)),
+			Timestamp: t0.Add(time.Duration(i) * time.Minute),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	floor, err := mrdb.GetNFTFloor(class, t0.Add(5*time.Minute), 10*time.Minute)
+	if err != nil || floor != 2 {
+		t.Errorf("expected floor 2, got %v (%v)", floor, err)
+	}
+	if _, err = mrdb.GetNFTFloor(class, t0.Add(time.Hour), 10*time.Minute); err == nil {
+		t.Error("expected error for time-range without trades")
+	}
+	floor, err = mrdb.GetNFTFloorRecursive(class, t0.Add(time.Hour), 10*time.Minute, 10)
+	if err != nil || floor != 2 {
+		t.Errorf("expected recursive floor 2, got %v (%v)", floor, err)
+	}
+}
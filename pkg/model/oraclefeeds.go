@@ -0,0 +1,121 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+)
+
+const oracleUpdateColumns = "oracleupdate_id,feed,blockchain,contract,oracle_key,value,timestamp,tx_hash,nonce,gas_used,status,error,update_time"
+
+// SetOracleFeed stores the specification of @feed as an active feed. An existing feed with the same name is replaced.
+func (rdb *RelDB) SetOracleFeed(feed dia.OracleFeed) error {
+	config, err := json.Marshal(feed)
+	if err != nil {
+		return err
+	}
+	query := fmt.Sprintf("INSERT INTO %s (name,config,active) VALUES ($1,$2,true) ON CONFLICT (name) DO UPDATE SET config=EXCLUDED.config,active=true", oracleFeedTable)
+	_, err = rdb.postgresClient.Exec(context.Background(), query, feed.Name, config)
+	return err
+}
+
+// GetOracleFeeds returns the specifications of all active oracle feeds.
+func (rdb *RelDB) GetOracleFeeds() (feeds []dia.OracleFeed, err error) {
+	query := fmt.Sprintf("SELECT config FROM %s WHERE active=true ORDER BY name", oracleFeedTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			config []byte
+			feed   dia.OracleFeed
+		)
+		err = rows.Scan(&config)
+		if err != nil {
+			return
+		}
+		err = json.Unmarshal(config, &feed)
+		if err != nil {
+			return
+		}
+		feeds = append(feeds, feed)
+	}
+	err = rows.Err()
+	return
+}
+
+// SetOracleUpdate logs @update of an oracle feed along with the outcome of its transaction.
+func (rdb *RelDB) SetOracleUpdate(update dia.OracleUpdate) error {
+	query := fmt.Sprintf("INSERT INTO %s (feed,blockchain,contract,oracle_key,value,timestamp,tx_hash,nonce,gas_used,status,error,update_time) VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12)", oracleUpdateTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query,
+		update.Feed,
+		update.Blockchain,
+		update.Contract,
+		update.Key,
+		update.Value,
+		update.Timestamp,
+		update.TxHash,
+		update.Nonce,
+		update.GasUsed,
+		update.Status,
+		update.Error,
+		time.Now(),
+	)
+	return err
+}
+
+// GetOracleUpdates returns all updates of @feed with timestamps in [@starttime, @endtime), ordered by timestamp.
+func (rdb *RelDB) GetOracleUpdates(feed string, starttime time.Time, endtime time.Time) (updates []dia.OracleUpdate, err error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE feed=$1 AND timestamp>=$2 AND timestamp<$3 ORDER BY timestamp", oracleUpdateColumns, oracleUpdateTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, feed, starttime, endtime)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			update     dia.OracleUpdate
+			blockchain sql.NullString
+			txHash     sql.NullString
+			nonce      sql.NullInt64
+			gasUsed    sql.NullInt64
+			errMessage sql.NullString
+			updateTime sql.NullTime
+		)
+		err = rows.Scan(
+			&update.ID,
+			&update.Feed,
+			&blockchain,
+			&update.Contract,
+			&update.Key,
+			&update.Value,
+			&update.Timestamp,
+			&txHash,
+			&nonce,
+			&gasUsed,
+			&update.Status,
+			&errMessage,
+			&updateTime,
+		)
+		if err != nil {
+			return
+		}
+		update.Blockchain = blockchain.String
+		update.TxHash = txHash.String
+		update.Nonce = uint64(nonce.Int64)
+		update.GasUsed = uint64(gasUsed.Int64)
+		update.Error = errMessage.String
+		update.UpdateTime = updateTime.Time
+		updates = append(updates, update)
+	}
+	err = rows.Err()
+	return
+}
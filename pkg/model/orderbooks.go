@@ -0,0 +1,88 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// SaveOrderBookInflux stores the levels of the order book @ob in influx.
+// Only snapshots should be saved, as the levels of a diff cannot be restored without its predecessors.
+func (datastore *DB) SaveOrderBookInflux(ob dia.OrderBook) error {
+
+	bidsEncoded, err := json.Marshal(ob.Bids)
+	if err != nil {
+		log.Error("marshal bids: ", err)
+	}
+	asksEncoded, err := json.Marshal(ob.Asks)
+	if err != nil {
+		log.Error("marshal asks: ", err)
+	}
+
+	tags := map[string]string{
+		"exchange":    ob.Exchange,
+		"foreignName": ob.ForeignName,
+		"symbol":      ob.Symbol,
+	}
+	fields := map[string]interface{}{
+		"bids": string(bidsEncoded),
+		"asks": string(asksEncoded),
+	}
+
+	pt, err := clientInfluxdb.NewPoint(influxDbOrderBookTable, tags, fields, ob.Time)
+	if err != nil {
+		log.Errorln("NewOrderBookInflux:", err)
+	} else {
+		datastore.addPoint(pt)
+	}
+
+	err = datastore.WriteBatchInflux()
+	if err != nil {
+		log.Errorln("Write influx batch: ", err)
+	}
+
+	return err
+}
+
+// GetOrderBookInflux returns the latest order book of the pair with @foreignName on @exchange in the time-range [starttime, endtime).
+func (datastore *DB) GetOrderBookInflux(exchange string, foreignName string, starttime time.Time, endtime time.Time) ([]dia.OrderBook, error) {
+
+	orderBooks := []dia.OrderBook{}
+	queryString := "SELECT \"symbol\",bids,asks FROM %s WHERE exchange='%s' AND foreignName='%s' AND time >= %d AND time < %d ORDER BY DESC LIMIT 1"
+	q := fmt.Sprintf(queryString, influxDbOrderBookTable, exchange, foreignName, starttime.UnixNano(), endtime.UnixNano())
+
+	res, err := queryInfluxDB(datastore.influxClient, q)
+	if err != nil {
+		return orderBooks, err
+	}
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for i := 0; i < len(res[0].Series[0].Values); i++ {
+			ob := dia.OrderBook{
+				Exchange:    exchange,
+				ForeignName: foreignName,
+				Snapshot:    true,
+			}
+			ob.Time, err = time.Parse(time.RFC3339, res[0].Series[0].Values[i][0].(string))
+			if err != nil {
+				return orderBooks, err
+			}
+			if symbol, ok := res[0].Series[0].Values[i][1].(string); ok {
+				ob.Symbol = symbol
+			}
+			if err := json.Unmarshal([]byte(res[0].Series[0].Values[i][2].(string)), &ob.Bids); err != nil {
+				log.Error("unmarshal bids: ", err)
+			}
+			if err := json.Unmarshal([]byte(res[0].Series[0].Values[i][3].(string)), &ob.Asks); err != nil {
+				log.Error("unmarshal asks: ", err)
+			}
+			orderBooks = append(orderBooks, ob)
+		}
+	} else {
+		return orderBooks, errors.New("parsing order book from database")
+	}
+	return orderBooks, nil
+}
@@ -2,9 +2,11 @@ package models
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -157,3 +159,61 @@ func (rdb *RelDB) GetAllPoolAddrsExchange(exchange string) (addresses []string,
 	}
 	return
 }
+
+// GetAllPools returns all pools along with the liquidity of their assets.
+func (rdb *RelDB) GetAllPools() (pools []dia.Pool, err error) {
+	var rows pgx.Rows
+	query := fmt.Sprintf(`
+		SELECT p.exchange,p.blockchain,p.address,a.symbol,a.name,a.address,a.decimals,a.blockchain,pa.liquidity
+		FROM %s p
+		INNER JOIN %s pa ON p.pool_id=pa.pool_id
+		INNER JOIN %s a ON pa.asset_id=a.asset_id
+		ORDER BY p.blockchain,p.address`,
+		poolTable,
+		poolassetTable,
+		assetTable,
+	)
+	rows, err = rdb.postgresClient.Query(context.Background(), query)
+	if err != nil {
+		return
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			pool      dia.Pool
+			asset     dia.Asset
+			decimals  string
+			liquidity sql.NullFloat64
+		)
+		err = rows.Scan(
+			&pool.Exchange.Name,
+			&pool.Blockchain.Name,
+			&pool.Address,
+			&asset.Symbol,
+			&asset.Name,
+			&asset.Address,
+			&decimals,
+			&asset.Blockchain,
+			&liquidity,
+		)
+		if err != nil {
+			return
+		}
+		decimalsInt, err := strconv.Atoi(decimals)
+		if err != nil {
+			log.Warnf("parse decimals of %s: %v", asset.Address, err)
+		}
+		asset.Decimals = uint8(decimalsInt)
+
+		if n := len(pools); n == 0 || pools[n-1].Address != pool.Address || pools[n-1].Blockchain.Name != pool.Blockchain.Name {
+			pools = append(pools, pool)
+		}
+		pools[len(pools)-1].Assetvolumes = append(pools[len(pools)-1].Assetvolumes, struct {
+			Asset  dia.Asset
+			Volume float64
+		}{Asset: asset, Volume: liquidity.Float64})
+	}
+	err = rows.Err()
+	return
+}
@@ -0,0 +1,80 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	clientInfluxdb "github.com/influxdata/influxdb1-client/v2"
+)
+
+// PriceSanityCount is the number of trades on @Pair from @Exchange which failed the price sanity check
+// of the tradesBlockService in the tradesBlock ending at @Time.
+type PriceSanityCount struct {
+	Exchange string
+	Pair     string
+	Rejected int
+	Flagged  int
+	Time     time.Time
+}
+
+// SavePriceSanityInflux adds @count to the influx batch.
+func (datastore *DB) SavePriceSanityInflux(count PriceSanityCount) error {
+	tags := map[string]string{
+		"exchange": count.Exchange,
+		"pair":     count.Pair,
+	}
+	fields := map[string]interface{}{
+		"rejected": count.Rejected,
+		"flagged":  count.Flagged,
+	}
+	pt, err := clientInfluxdb.NewPoint(influxDbPriceSanityTable, tags, fields, count.Time)
+	if err != nil {
+		log.Errorln("new price sanity influx:", err)
+	} else {
+		datastore.addPoint(pt)
+	}
+	return err
+}
+
+// GetPriceSanityInflux returns the price sanity counts of @exchange in the time-range [starttime, endtime), latest first.
+// Counts of all exchanges are returned if @exchange is empty.
+func (datastore *DB) GetPriceSanityInflux(exchange string, starttime time.Time, endtime time.Time) ([]PriceSanityCount, error) {
+	counts := []PriceSanityCount{}
+	var q string
+	if exchange == "" {
+		queryString := "SELECT \"exchange\",\"pair\",rejected,flagged FROM %s WHERE time >= %d AND time < %d ORDER BY DESC"
+		q = fmt.Sprintf(queryString, influxDbPriceSanityTable, starttime.UnixNano(), endtime.UnixNano())
+	} else {
+		queryString := "SELECT \"exchange\",\"pair\",rejected,flagged FROM %s WHERE exchange='%s' AND time >= %d AND time < %d ORDER BY DESC"
+		q = fmt.Sprintf(queryString, influxDbPriceSanityTable, exchange, starttime.UnixNano(), endtime.UnixNano())
+	}
+
+	res, err := queryInfluxDB(datastore.influxClient, q)
+	if err != nil {
+		return counts, err
+	}
+	if len(res) > 0 && len(res[0].Series) > 0 {
+		for _, row := range res[0].Series[0].Values {
+			var count PriceSanityCount
+			count.Time, err = time.Parse(time.RFC3339, row[0].(string))
+			if err != nil {
+				return counts, err
+			}
+			count.Exchange = row[1].(string)
+			count.Pair = row[2].(string)
+			rejected, err := row[3].(json.Number).Int64()
+			if err != nil {
+				return counts, err
+			}
+			flagged, err := row[4].(json.Number).Int64()
+			if err != nil {
+				return counts, err
+			}
+			count.Rejected = int(rejected)
+			count.Flagged = int(flagged)
+			counts = append(counts, count)
+		}
+	}
+	return counts, nil
+}
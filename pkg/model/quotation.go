@@ -39,6 +39,10 @@ func getKeyAssetQuotation(blockchain, address string) string {
 	return "dia_assetquotation_USD_" + blockchain + "_" + address
 }
 
+func getKeyPricePath(blockchain, address string) string {
+	return "dia_pricepath_USD_" + blockchain + "_" + address
+}
+
 // ------------------------------------------------------------------------------
 // ASSET EXCHANGE RATES (WIP)
 // ------------------------------------------------------------------------------
@@ -208,6 +212,21 @@ func (datastore *DB) GetAssetQuotationCache(asset dia.Asset) (*AssetQuotation, e
 	return quotation, nil
 }
 
+// SetPricePathCache stores the price path of an asset priced through the price graph in the redis cache.
+// It is written directly rather than through the redis pipe, which is only executed by the filtersBlockService.
+func (datastore *DB) SetPricePathCache(pricePath *PricePath) error {
+	key := getKeyPricePath(pricePath.Asset.Blockchain, pricePath.Asset.Address)
+	return datastore.redisClient.Set(key, pricePath, TimeOutAssetQuotation).Err()
+}
+
+// GetPricePathCache returns the latest price path of @asset from the redis cache.
+func (datastore *DB) GetPricePathCache(asset dia.Asset) (*PricePath, error) {
+	key := getKeyPricePath(asset.Blockchain, asset.Address)
+	pricePath := &PricePath{}
+	err := datastore.redisClient.Get(key).Scan(pricePath)
+	return pricePath, err
+}
+
 // GetAssetPriceUSDCache returns the latest price of @asset from the cache.
 func (datastore *DB) GetAssetPriceUSDCache(asset dia.Asset) (price float64, err error) {
 	quotation, err := datastore.GetAssetQuotationCache(asset)
@@ -273,7 +292,7 @@ func (datastore *DB) GetAssetsMarketCap(asset dia.Asset) (float64, error) {
 
 // GetTopAssetByVolume returns the asset with highest volume among all assets with symbol @symbol.
 // This method allows us to use all API endpoints called on a symbol.
-func (datastore *DB) GetTopAssetByVolume(symbol string, relDB *RelDB) (topAsset dia.Asset, err error) {
+func (datastore *DB) GetTopAssetByVolume(symbol string, relDB RelDatastore) (topAsset dia.Asset, err error) {
 	assets, err := relDB.GetAssets(symbol)
 	if err != nil {
 		return
@@ -307,7 +326,7 @@ func (datastore *DB) GetTopAssetByVolume(symbol string, relDB *RelDB) (topAsset
 }
 
 // GetTopAssetByMcap returns the asset with highest market cap among all assets with symbol @symbol.
-func (datastore *DB) GetTopAssetByMcap(symbol string, relDB *RelDB) (topAsset dia.Asset, err error) {
+func (datastore *DB) GetTopAssetByMcap(symbol string, relDB RelDatastore) (topAsset dia.Asset, err error) {
 	assets, err := relDB.GetAssets(symbol)
 	if err != nil {
 		return
@@ -31,17 +31,26 @@ type RelDatastore interface {
 	SetAssetVolume24H(asset dia.Asset, volume float64) error
 	GetAssetVolume24H(asset dia.Asset) (float64, error)
 	GetAssetsWithVOL(numAssets int64, substring string) ([]dia.Asset, error)
+	GetAssets(symbol string) ([]dia.Asset, error)
+	GetAssetExchange(symbol string) ([]string, error)
+	GetTopAssetByVolume(symbol string) ([]dia.Asset, error)
 	SetAggregatedVolume(aggVol dia.AggregatedVolume) error
 	GetAggregatedVolumes(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.AggregatedVolume, error)
 	GetAggVolumesByExchange(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.ExchangeVolumesList, error)
 	GetAggVolumesByPair(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.PairVolumesList, error)
 	SetTradesDistribution(tradesDist dia.TradesDistribution) error
 	GetTradesDistribution(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.TradesDistribution, error)
+	GetTradesDistributionPerExchange(asset dia.Asset, starttime time.Time, endtime time.Time) ([]dia.TradesDistribution, error)
+	SetTradeGap(gap dia.TradeGap) error
+	GetTradeGaps(exchange string, pair string, starttime time.Time, endtime time.Time) ([]dia.TradeGap, error)
+	GetTradeGapsByStatus(status string, limit int) ([]dia.TradeGap, error)
+	UpdateTradeGapStatus(id int64, status string, errorMessage string) error
 
 	// --------------- asset methods for exchanges ---------------
 	SetExchangePair(exchange string, pair dia.ExchangePair, cache bool) error
 	GetExchangePair(exchange string, foreignname string) (exchangepair dia.ExchangePair, err error)
 	GetExchangePairSymbols(exchange string) ([]dia.ExchangePair, error)
+	GetVerifiedExchangePairSymbols(exchange string) ([]dia.ExchangePair, error)
 	GetPairs(exchange string) ([]dia.ExchangePair, error)
 	SetExchangeSymbol(exchange string, symbol string) error
 	GetExchangeSymbols(exchange string, substring string) ([]string, error)
@@ -58,12 +67,23 @@ type RelDatastore interface {
 	// ----------------- pool methods -------------------
 	SetPool(pool dia.Pool) error
 	GetAllPoolAddrsExchange(exchange string) ([]string, error)
+	GetAllPools() ([]dia.Pool, error)
 
 	// ----------------- blockchain methods -------------------
 	SetBlockchain(blockchain dia.BlockChain) error
 	GetBlockchain(name string) (dia.BlockChain, error)
 	GetAllAssetsBlockchains() ([]string, error)
 	GetAllBlockchains(fullAsset bool) ([]dia.BlockChain, error)
+	SetChainConfig(chainconfig dia.ChainConfig) error
+	GetAllChainConfig() ([]dia.ChainConfig, error)
+
+	// ----------------- filter config methods -------------------
+	SetFilterConfig(filterConfig dia.FilterConfig) error
+	GetAllFilterConfigs() ([]dia.FilterConfig, error)
+
+	// ----------------- asset equivalence methods -------------------
+	SetAssetEquivalence(equivalence dia.AssetEquivalence) error
+	GetAllAssetEquivalences() ([]dia.AssetEquivalence, error)
 
 	// ------ Caching ------
 	SetAssetCache(asset dia.Asset) error
@@ -116,6 +136,12 @@ type RelDatastore interface {
 	GetScraperConfig(ctx context.Context, scraperName string, config ScraperConfig) error
 	SetScraperConfig(ctx context.Context, scraperName string, config ScraperConfig) error
 
+	// Oracle feeds
+	SetOracleFeed(feed dia.OracleFeed) error
+	GetOracleFeeds() ([]dia.OracleFeed, error)
+	SetOracleUpdate(update dia.OracleUpdate) error
+	GetOracleUpdates(feed string, starttime time.Time, endtime time.Time) ([]dia.OracleUpdate, error)
+
 	// Blockchain data
 	SetBlockData(dia.BlockData) error
 	GetBlockData(blockchain string, blocknumber int64) (dia.BlockData, error)
@@ -137,6 +163,11 @@ const (
 	assetVolumeTable        = "assetvolume"
 	aggregatedVolumeTable   = "aggregatedvolume"
 	tradesDistributionTable = "tradesdistribution"
+	tradeGapTable           = "tradegap"
+	filterconfigTable       = "filterconfig"
+	assetEquivalenceTable   = "assetequivalence"
+	oracleFeedTable         = "oraclefeed"
+	oracleUpdateTable       = "oracleupdate"
 
 	// cache keys
 	keyAssetCache        = "dia_asset_"
@@ -21,7 +21,7 @@ func getKeyDiaCirculatingSupply() string {
 	return "dia_diaCirculatingSupply"
 }
 
-func (datastore *DB) GetLatestSupply(symbol string, relDB *RelDB) (*dia.Supply, error) {
+func (datastore *DB) GetLatestSupply(symbol string, relDB RelDatastore) (*dia.Supply, error) {
 	val, err := datastore.GetSupply(symbol, time.Time{}, time.Time{}, relDB)
 	if err != nil {
 		log.Error(err)
@@ -30,7 +30,7 @@ func (datastore *DB) GetLatestSupply(symbol string, relDB *RelDB) (*dia.Supply,
 	return &val[0], err
 }
 
-func (datastore *DB) GetSupply(symbol string, starttime, endtime time.Time, relDB *RelDB) ([]dia.Supply, error) {
+func (datastore *DB) GetSupply(symbol string, starttime, endtime time.Time, relDB RelDatastore) ([]dia.Supply, error) {
 
 	// First get asset with @symbol with largest market cap.
 	topAsset, err := relDB.GetTopAssetByVolume(symbol)
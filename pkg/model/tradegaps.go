@@ -0,0 +1,95 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/diadata-org/diadata/pkg/dia"
+	"github.com/jackc/pgx/v4"
+)
+
+const tradeGapColumns = "tradegap_id,exchange,pair,starttime,endtime,expected_num_trades,status,error,update_time"
+
+// SetTradeGap stores @gap in postgres with status open, unless a gap of the same pair with the same starttime exists.
+func (rdb *RelDB) SetTradeGap(gap dia.TradeGap) error {
+	query := fmt.Sprintf("INSERT INTO %s (exchange,pair,starttime,endtime,expected_num_trades,status,update_time) VALUES ($1,$2,$3,$4,$5,$6,$7) ON CONFLICT (exchange,pair,starttime) DO NOTHING", tradeGapTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query,
+		gap.Exchange,
+		gap.Pair,
+		gap.StartTime,
+		gap.EndTime,
+		gap.ExpectedNumTrades,
+		dia.TradeGapOpen,
+		time.Now(),
+	)
+	return err
+}
+
+// GetTradeGaps returns all gaps of @pair on @exchange which overlap with the time-range [@starttime, @endtime), ordered by starttime.
+// If @pair is empty, the gaps of all pairs on @exchange are returned.
+func (rdb *RelDB) GetTradeGaps(exchange string, pair string, starttime time.Time, endtime time.Time) ([]dia.TradeGap, error) {
+	var (
+		rows pgx.Rows
+		err  error
+	)
+	if pair == "" {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE exchange=$1 AND starttime<$2 AND endtime>$3 ORDER BY starttime", tradeGapColumns, tradeGapTable)
+		rows, err = rdb.postgresClient.Query(context.Background(), query, exchange, endtime, starttime)
+	} else {
+		query := fmt.Sprintf("SELECT %s FROM %s WHERE exchange=$1 AND pair=$2 AND starttime<$3 AND endtime>$4 ORDER BY starttime", tradeGapColumns, tradeGapTable)
+		rows, err = rdb.postgresClient.Query(context.Background(), query, exchange, pair, endtime, starttime)
+	}
+	if err != nil {
+		return []dia.TradeGap{}, err
+	}
+	return scanTradeGaps(rows)
+}
+
+// GetTradeGapsByStatus returns at most @limit gaps with @status, oldest first.
+func (rdb *RelDB) GetTradeGapsByStatus(status string, limit int) ([]dia.TradeGap, error) {
+	query := fmt.Sprintf("SELECT %s FROM %s WHERE status=$1 ORDER BY starttime LIMIT $2", tradeGapColumns, tradeGapTable)
+	rows, err := rdb.postgresClient.Query(context.Background(), query, status, limit)
+	if err != nil {
+		return []dia.TradeGap{}, err
+	}
+	return scanTradeGaps(rows)
+}
+
+// UpdateTradeGapStatus sets the status of the gap with @id to @status. @errorMessage is stored along with failed gaps.
+func (rdb *RelDB) UpdateTradeGapStatus(id int64, status string, errorMessage string) error {
+	query := fmt.Sprintf("UPDATE %s SET status=$1,error=$2,update_time=$3 WHERE tradegap_id=$4", tradeGapTable)
+	_, err := rdb.postgresClient.Exec(context.Background(), query, status, errorMessage, time.Now(), id)
+	return err
+}
+
+func scanTradeGaps(rows pgx.Rows) (gaps []dia.TradeGap, err error) {
+	defer rows.Close()
+	for rows.Next() {
+		var (
+			gap        dia.TradeGap
+			errMessage sql.NullString
+			updateTime sql.NullTime
+		)
+		err = rows.Scan(
+			&gap.ID,
+			&gap.Exchange,
+			&gap.Pair,
+			&gap.StartTime,
+			&gap.EndTime,
+			&gap.ExpectedNumTrades,
+			&gap.Status,
+			&errMessage,
+			&updateTime,
+		)
+		if err != nil {
+			return
+		}
+		gap.Error = errMessage.String
+		gap.UpdateTime = updateTime.Time
+		gaps = append(gaps, gap)
+	}
+	err = rows.Err()
+	return
+}
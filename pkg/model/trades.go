@@ -4,12 +4,16 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
 	"github.com/diadata-org/diadata/pkg/dia"
 )
 
+// ErrNoTradesFound is returned by the trade getters if there are no trades in the requested time range.
+var ErrNoTradesFound = errors.New("no trades found")
+
 // parseTrade parses a trade as retreived from influx. If fullAsset=true blockchain and address of
 // the corresponding asset is returned as well.
 func parseTrade(row []interface{}, fullBasetoken bool) *dia.Trade {
@@ -126,7 +130,7 @@ func (datastore *DB) GetTradesByExchangesFull(asset dia.Asset, exchanges []strin
 			}
 		}
 	} else {
-		return nil, fmt.Errorf("no trades found")
+		return nil, ErrNoTradesFound
 	}
 	return r, nil
 }
@@ -176,7 +180,7 @@ func (datastore *DB) GetTradesByExchangesBatchedFull(asset dia.Asset, exchanges
 		}
 	} else {
 		log.Errorf("Empty response GetTradesByExchangesBatched for %s \n", asset.Symbol)
-		return nil, fmt.Errorf("no trades found")
+		return nil, ErrNoTradesFound
 	}
 
 	return r, nil
@@ -246,3 +250,71 @@ func (datastore *DB) GetLastTrades(asset dia.Asset, exchange string, maxTrades i
 	}
 	return r, nil
 }
+
+// GetTradeCountsInflux returns the number of trades per pair on @exchange in bins of size @binSize
+// in the time-range [@starttime, @endtime). The bins are aligned to multiples of @binSize.
+func (datastore *DB) GetTradeCountsInflux(exchange string, starttime time.Time, endtime time.Time, binSize time.Duration) (map[string][]dia.TradeCount, error) {
+	counts := make(map[string][]dia.TradeCount)
+	q := fmt.Sprintf("SELECT COUNT(price) FROM %s WHERE exchange='%s' AND time>=%d AND time<%d GROUP BY time(%ds),\"pair\" fill(0)",
+		influxDbTradesTable, exchange, starttime.UnixNano(), endtime.UnixNano(), int64(binSize.Seconds()))
+	res, err := queryInfluxDB(datastore.influxClient, q)
+	if err != nil {
+		return counts, err
+	}
+	if len(res) == 0 {
+		return counts, nil
+	}
+	for _, series := range res[0].Series {
+		pair := series.Tags["pair"]
+		for _, row := range series.Values {
+			var tc dia.TradeCount
+			tc.Time, err = time.Parse(time.RFC3339, row[0].(string))
+			if err != nil {
+				return counts, err
+			}
+			count, err := row[1].(json.Number).Int64()
+			if err != nil {
+				return counts, err
+			}
+			tc.Count = int(count)
+			counts[pair] = append(counts[pair], tc)
+		}
+	}
+	return counts, nil
+}
+
+// DeleteTradesInflux deletes all trades with @foreignTradeID on @exchange in the time-range [@starttime, @endtime] from @table.
+// It returns the number of deleted trades.
+func (datastore *DB) DeleteTradesInflux(table string, exchange string, foreignTradeID string, starttime time.Time, endtime time.Time) (int, error) {
+	// Influx only deletes by tags and time. Hence, trades are selected by their foreignTradeID field first.
+	q := fmt.Sprintf("SELECT price FROM %s WHERE exchange='%s' AND foreignTradeID='%s' AND time>=%d AND time<=%d GROUP BY *",
+		table, exchange, foreignTradeID, starttime.UnixNano(), endtime.UnixNano())
+	res, err := queryInfluxDB(datastore.influxClient, q)
+	if err != nil {
+		return 0, err
+	}
+	if len(res) == 0 {
+		return 0, nil
+	}
+
+	numDeleted := 0
+	for _, series := range res[0].Series {
+		var conditions []string
+		for tag, value := range series.Tags {
+			conditions = append(conditions, fmt.Sprintf("\"%s\"='%s'", tag, value))
+		}
+		sort.Strings(conditions)
+		for _, row := range series.Values {
+			t, err := time.Parse(time.RFC3339, row[0].(string))
+			if err != nil {
+				return numDeleted, err
+			}
+			q := fmt.Sprintf("DELETE FROM %s WHERE %s AND time=%d", table, strings.Join(conditions, " AND "), t.UnixNano())
+			if _, err := queryInfluxDB(datastore.influxClient, q); err != nil {
+				return numDeleted, err
+			}
+			numDeleted++
+		}
+	}
+	return numDeleted, nil
+}
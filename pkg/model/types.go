@@ -102,6 +102,26 @@ func (aq *AssetQuotation) UnmarshalBinary(data []byte) error {
 	return nil
 }
 
+// PricePath is the derivation of the USD price of an asset without a USD quotation from the price of
+// an anchor asset. Path starts with the anchor and ends with the asset. Confidence is in [0,1].
+type PricePath struct {
+	Asset      dia.Asset
+	Path       []dia.Asset
+	Price      float64
+	Confidence float64
+	Time       time.Time
+}
+
+// MarshalBinary for price paths
+func (pp *PricePath) MarshalBinary() ([]byte, error) {
+	return json.Marshal(pp)
+}
+
+// UnmarshalBinary for price paths
+func (pp *PricePath) UnmarshalBinary(data []byte) error {
+	return json.Unmarshal(data, pp)
+}
+
 type AssetQuotationFull struct {
 	Symbol             string
 	Name               string
@@ -112,6 +132,9 @@ type AssetQuotationFull struct {
 	VolumeYesterdayUSD float64
 	Time               time.Time
 	Source             string
+	// Path of assets along which trades of the asset were priced, if it was priced through the price graph.
+	PricePath      []dia.Asset `json:",omitempty"`
+	PathConfidence float64     `json:",omitempty"`
 }
 
 // MarshalBinary for quotations
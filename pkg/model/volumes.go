@@ -399,11 +399,12 @@ func (rdb *RelDB) GetAggVolumesByPair(asset dia.Asset, starttime time.Time, endt
 // SetTradesDistribution sets the trades distribution parameter for @asset in postgres.
 func (rdb *RelDB) SetTradesDistribution(tradesDist dia.TradesDistribution) error {
 	assetQuery := fmt.Sprintf("(SELECT asset_id FROM %s WHERE blockchain=$1 and address=$2)", assetTable)
-	query := fmt.Sprintf("INSERT INTO %s (asset_id,num_trades_total,num_low_bins,threshold,size_bin_seconds,avg_num_per_bin,std_deviation,time_range_seconds,compute_time) VALUES(%s,$3,$4,$5,$6,$7,$8,$9,$10);", tradesDistributionTable, assetQuery)
+	query := fmt.Sprintf("INSERT INTO %s (asset_id,exchange,num_trades_total,num_low_bins,threshold,size_bin_seconds,avg_num_per_bin,std_deviation,time_range_seconds,compute_time) VALUES(%s,$3,$4,$5,$6,$7,$8,$9,$10,$11);", tradesDistributionTable, assetQuery)
 
 	_, err := rdb.postgresClient.Exec(context.Background(), query,
 		tradesDist.Asset.Blockchain,
 		tradesDist.Asset.Address,
+		tradesDist.Exchange,
 		tradesDist.NumTradesTotal,
 		tradesDist.NumLowBins,
 		tradesDist.Threshold,
@@ -421,13 +422,25 @@ func (rdb *RelDB) SetTradesDistribution(tradesDist dia.TradesDistribution) error
 
 // GetTradesDistribution returns all trades distribution parameters in the time-range @starttime - @endtime.
 func (rdb *RelDB) GetTradesDistribution(asset dia.Asset, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution, err error) {
-	valuesQuery := "a.num_trades_total,a.num_low_bins,a.threshold,a.size_bin_seconds,a.avg_num_per_bin,a.std_deviation,a.time_range_seconds,a.compute_time"
+	return rdb.getTradesDistributions(asset, "a.exchange=''", starttime, endtime)
+}
+
+// GetTradesDistributionPerExchange returns the trades distribution parameters of all exchanges
+// trading @asset in the time-range @starttime - @endtime, latest first.
+func (rdb *RelDB) GetTradesDistributionPerExchange(asset dia.Asset, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution, err error) {
+	return rdb.getTradesDistributions(asset, "a.exchange<>''", starttime, endtime)
+}
+
+// getTradesDistributions returns the trades distributions of @asset matching @exchangeCondition.
+func (rdb *RelDB) getTradesDistributions(asset dia.Asset, exchangeCondition string, starttime time.Time, endtime time.Time) (tradesDistributions []dia.TradesDistribution, err error) {
+	valuesQuery := "a.exchange,a.num_trades_total,a.num_low_bins,a.threshold,a.size_bin_seconds,a.avg_num_per_bin,a.std_deviation,a.time_range_seconds,a.compute_time"
 	pairQuery := "b.address,b.blockchain,b.name,b.symbol,b.decimals"
-	query := fmt.Sprintf("SELECT %s,%s FROM %s a INNER JOIN %s b ON a.asset_id=b.asset_id WHERE b.address=$1 AND b.blockchain=$2 AND compute_time>$3 and compute_time<=$4 oRDER BY compute_time DESC",
+	query := fmt.Sprintf("SELECT %s,%s FROM %s a INNER JOIN %s b ON a.asset_id=b.asset_id WHERE b.address=$1 AND b.blockchain=$2 AND %s AND compute_time>$3 and compute_time<=$4 oRDER BY compute_time DESC",
 		valuesQuery,
 		pairQuery,
 		tradesDistributionTable,
 		assetTable,
+		exchangeCondition,
 	)
 
 	var rows pgx.Rows
@@ -446,6 +459,7 @@ func (rdb *RelDB) GetTradesDistribution(asset dia.Asset, starttime time.Time, en
 		//a.time_range_seconds,a.compute_time
 		var tradesDist dia.TradesDistribution
 		err = rows.Scan(
+			&tradesDist.Exchange,
 			&tradesDist.NumTradesTotal,
 			&tradesDist.NumLowBins,
 			&tradesDist.Threshold,